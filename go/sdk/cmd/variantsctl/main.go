@@ -0,0 +1,284 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+// Command variantsctl is a tool for authors and operators of variant
+// servers.
+//
+// Usage:
+//
+//	variantsctl lint [flags] <source>
+//	variantsctl compat [flags] <endpoint> <variant>
+//
+// The lint command checks variant descriptions against the guidance of the
+// variants extension (see variants.LintVariants) and prints the issues
+// found. The source is an MCP endpoint URL, whose variants are read from
+// the initialize response, or the path of a manifest file as served by
+// variants.NewManifestHandler, or - for standard input:
+//
+//	variantsctl lint http://localhost:8080/mcp
+//	curl -s http://localhost:8080/variants/manifest | variantsctl lint -
+//
+// It exits with status 1 if issues were found, and 2 on errors.
+//
+// The compat command connects to an MCP endpoint and compares the tools of
+// a variant with those of its replacement, named in the variant's
+// deprecation info or by the -replacement flag (see variants.CompareTools):
+//
+//	variantsctl compat http://localhost:8080/mcp trading-v1
+//	variantsctl compat -json -replacement trading-v3 http://localhost:8080/mcp trading-v1
+//
+// Tool translations configured on the server are not visible to clients,
+// so translated tools are reported as missing; run
+// Server.CompatibilityReport on the server for a report including them.
+// It exits with status 1 if the replacement is incompatible, and 2 on
+// errors.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+)
+
+const usage = `usage: variantsctl <command> [flags] [args]
+
+commands:
+  lint    check variant descriptions against the extension's guidance
+  compat  compare a variant's tools with those of its replacement
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "lint":
+		os.Exit(lint(args, os.Stdin, os.Stdout, os.Stderr))
+	case "compat":
+		os.Exit(compat(args, os.Stdout, os.Stderr))
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "variantsctl: unknown command %q\n\n%s", cmd, usage)
+		os.Exit(2)
+	}
+}
+
+// lint runs the lint command and returns its exit status.
+func lint(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		opts    variants.LintOptions
+		disable string
+		asJSON  bool
+		timeout time.Duration
+	)
+	fs.IntVar(&opts.MinDescriptionLength, "min", 0, "minimum description length in characters (default 40)")
+	fs.IntVar(&opts.MaxDescriptionLength, "max", 0, "maximum description length in characters (default 400)")
+	fs.StringVar(&disable, "disable", "", "comma-separated rules not to check, e.g. noTradeoffs")
+	fs.BoolVar(&asJSON, "json", false, "print the issues as a JSON array")
+	fs.DurationVar(&timeout, "timeout", 10*time.Second, "timeout for connecting to an MCP endpoint")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: variantsctl lint [flags] <MCP endpoint URL | manifest file | ->")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	if disable != "" {
+		opts.Disable = strings.Split(disable, ",")
+	}
+
+	vs, err := loadVariants(fs.Arg(0), stdin, timeout)
+	if err != nil {
+		fmt.Fprintf(stderr, "variantsctl: %v\n", err)
+		return 2
+	}
+	issues := variants.LintVariants(vs, &opts)
+	if asJSON {
+		if issues == nil {
+			issues = []variants.LintIssue{}
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(issues); err != nil {
+			fmt.Fprintf(stderr, "variantsctl: %v\n", err)
+			return 2
+		}
+	} else {
+		for _, issue := range issues {
+			fmt.Fprintf(stdout, "%s: %s: %s\n", issue.VariantID, issue.Rule, issue.Detail)
+		}
+	}
+	if len(issues) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// loadVariants reads the variants of a lint source.
+func loadVariants(source string, stdin io.Reader, timeout time.Duration) ([]variants.ServerVariant, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return endpointVariants(source, timeout)
+	}
+	var r io.Reader = stdin
+	if source != "-" {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	var m variants.Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	if len(m.Variants) == 0 {
+		return nil, errors.New("manifest lists no variants")
+	}
+	out := make([]variants.ServerVariant, len(m.Variants))
+	for i, v := range m.Variants {
+		out[i] = v.ServerVariant
+	}
+	return out, nil
+}
+
+// endpointVariants connects to an MCP endpoint and returns the variants it
+// advertises.
+func endpointVariants(endpoint string, timeout time.Duration) ([]variants.ServerVariant, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	client := variants.NewClient(&mcp.Implementation{Name: "variantsctl", Version: "v1.0.0"}, nil)
+	cs, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: endpoint, MaxRetries: -1}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cs.Close()
+	vs := cs.Variants()
+	if len(vs) == 0 {
+		return nil, fmt.Errorf("%s advertises no variants", endpoint)
+	}
+	return vs, nil
+}
+
+// compat runs the compat command and returns its exit status.
+func compat(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("compat", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var (
+		replacement string
+		asJSON      bool
+		timeout     time.Duration
+	)
+	fs.StringVar(&replacement, "replacement", "", "variant to compare against (default from the variant's deprecation info)")
+	fs.BoolVar(&asJSON, "json", false, "print the report as JSON")
+	fs.DurationVar(&timeout, "timeout", 10*time.Second, "timeout for the comparison")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: variantsctl compat [flags] <MCP endpoint URL> <variant>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	r, err := compareEndpoint(fs.Arg(0), fs.Arg(1), replacement, timeout)
+	if err != nil {
+		fmt.Fprintf(stderr, "variantsctl: %v\n", err)
+		return 2
+	}
+	if asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r); err != nil {
+			fmt.Fprintf(stderr, "variantsctl: %v\n", err)
+			return 2
+		}
+	} else {
+		fmt.Fprintf(stdout, "%s -> %s\n", r.Variant, r.Replacement)
+		for _, tc := range r.Tools {
+			fmt.Fprintf(stdout, "  %s: %s\n", tc.Name, tc.Status)
+			for _, c := range tc.Changes {
+				breaking := ""
+				if c.Breaking {
+					breaking = " (breaking)"
+				}
+				fmt.Fprintf(stdout, "    %s %s %s%s\n", c.Schema, c.Property, c.Kind, breaking)
+			}
+		}
+		for _, name := range r.AddedTools {
+			fmt.Fprintf(stdout, "  %s: added\n", name)
+		}
+	}
+	if !r.Compatible {
+		return 1
+	}
+	return 0
+}
+
+// compareEndpoint connects to an MCP endpoint and compares the tools of the
+// given variant with those of its replacement. If replacement is empty,
+// the variant's deprecation info names it.
+func compareEndpoint(endpoint, variantID, replacement string, timeout time.Duration) (*variants.CompatibilityReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	client := variants.NewClient(&mcp.Implementation{Name: "variantsctl", Version: "v1.0.0"}, nil)
+	cs, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: endpoint, MaxRetries: -1}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cs.Close()
+
+	if replacement == "" {
+		for _, v := range cs.Variants() {
+			if v.ID == variantID && v.DeprecationInfo != nil {
+				replacement = v.DeprecationInfo.Replacement
+			}
+		}
+		if replacement == "" {
+			return nil, fmt.Errorf("variant %q names no replacement; use -replacement", variantID)
+		}
+	}
+	oldTools, err := variantTools(ctx, cs, variantID)
+	if err != nil {
+		return nil, err
+	}
+	newTools, err := variantTools(ctx, cs, replacement)
+	if err != nil {
+		return nil, err
+	}
+	return variants.CompareTools(variantID, replacement, oldTools, newTools), nil
+}
+
+// variantTools lists the tools of a variant of a session.
+func variantTools(ctx context.Context, cs *variants.ClientSession, variantID string) ([]*mcp.Tool, error) {
+	if err := cs.SelectVariant(ctx, variantID); err != nil {
+		return nil, err
+	}
+	tools, err := cs.Tools(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing tools of variant %q: %w", variantID, err)
+	}
+	return tools, nil
+}
@@ -0,0 +1,219 @@
+// Example: Scale benchmark — a synthetic server with hundreds of variants.
+// Registers N in-memory variants and measures, for each configuration:
+//
+//   - initialize latency, from client connect to initialize result;
+//   - heap per open session, including its connections to variants;
+//   - ranking cost, per RankedVariants call for the clients' hints.
+//
+// Configurations:
+//   - eager: every session connects to every variant at initialize
+//   - lazy: sessions connect to their 4 first-ranked variants, others on
+//     first use (SessionLimits.MaxConnectionsPerSession)
+//   - cached: ranking results are cached per hints (WithRankingCache)
+//   - lazy+cached: both
+//
+// Run:
+//
+//	go run ./examples/benchmark/scale -variants 100,1000 -sessions 50
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+)
+
+// families is the number of distinct modelFamily hints among variants and
+// clients.
+const families = 10
+
+// config is a server configuration under test.
+type config struct {
+	name      string
+	lazy      bool
+	cacheSize int
+}
+
+var configs = []config{
+	{name: "eager"},
+	{name: "lazy", lazy: true},
+	{name: "cached", cacheSize: families},
+	{name: "lazy+cached", lazy: true, cacheSize: families},
+}
+
+// result holds the measurements of one run.
+type result struct {
+	initMean, initP95 time.Duration
+	heapPerSession    uint64
+	rankCost          time.Duration
+}
+
+func main() {
+	variantCounts := flag.String("variants", "100,1000", "comma-separated variant counts")
+	sessions := flag.Int("sessions", 50, "sessions opened per run")
+	rankCalls := flag.Int("rank-calls", 2000, "RankedVariants calls per run")
+	flag.Parse()
+
+	var counts []int
+	for _, s := range strings.Split(*variantCounts, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || n < 1 {
+			log.Fatalf("invalid variant count %q", s)
+		}
+		counts = append(counts, n)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "variants\tconfig\tinit mean\tinit p95\theap/session\trank/call\t")
+	for _, n := range counts {
+		for _, c := range configs {
+			r, err := run(n, c, *sessions, *rankCalls)
+			if err != nil {
+				log.Fatalf("%d variants, %s: %v", n, c.name, err)
+			}
+			fmt.Fprintf(w, "%d\t%s\t%v\t%v\t%s\t%v\t\n", n, c.name,
+				r.initMean.Round(time.Microsecond), r.initP95.Round(time.Microsecond),
+				formatBytes(r.heapPerSession), r.rankCost.Round(10*time.Nanosecond))
+		}
+	}
+	w.Flush()
+}
+
+// newServer returns a variant server with n synthetic variants, each with
+// a few tools and hints from one of the model families.
+func newServer(n int, c config) *variants.Server {
+	vs := variants.NewServer(&mcp.Implementation{Name: "scale", Version: "v1.0.0"})
+	for i := range n {
+		inner := mcp.NewServer(&mcp.Implementation{Name: "scale", Version: "v1.0.0"}, nil)
+		for _, name := range []string{"search", "fetch", "summarize"} {
+			mcp.AddTool(inner, &mcp.Tool{Name: name, Description: fmt.Sprintf("%s for variant %d", name, i)}, noop)
+		}
+		vs.WithVariant(variants.ServerVariant{
+			ID:          fmt.Sprintf("variant-%04d", i),
+			Description: fmt.Sprintf("Synthetic variant %d", i),
+			Hints:       map[string]string{"modelFamily": family(i), "contextSize": []string{"compact", "standard", "verbose"}[i%3]},
+			Status:      variants.Stable,
+		}, inner, i)
+	}
+	if c.lazy {
+		vs.WithSessionLimits(variants.SessionLimits{MaxConnectionsPerSession: 4})
+	}
+	if c.cacheSize > 0 {
+		vs.WithRankingCache(c.cacheSize)
+	}
+	return vs
+}
+
+// run measures one configuration over n variants.
+func run(n int, c config, sessions, rankCalls int) (*result, error) {
+	vs := newServer(n, c)
+	defer vs.Close()
+	router, err := vs.NewRouter(nil)
+	if err != nil {
+		return nil, err
+	}
+	front := mcp.NewServer(&mcp.Implementation{Name: "scale", Version: "v1.0.0"}, nil)
+	if err := router.Install(front); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	before := liveHeap()
+
+	latencies := make([]time.Duration, 0, sessions)
+	var open []*variants.ClientSession
+	defer func() {
+		for _, cs := range open {
+			cs.Close()
+		}
+	}()
+	for i := range sessions {
+		hints, err := clientHints(i)
+		if err != nil {
+			return nil, err
+		}
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		if _, err := front.Connect(ctx, serverTransport, nil); err != nil {
+			return nil, err
+		}
+		client := variants.NewClient(&mcp.Implementation{Name: "scale-client", Version: "v1.0.0"}, &variants.ClientOptions{Hints: hints})
+		start := time.Now()
+		cs, err := client.Connect(ctx, clientTransport, nil)
+		if err != nil {
+			return nil, err
+		}
+		latencies = append(latencies, time.Since(start))
+		open = append(open, cs)
+	}
+
+	r := &result{}
+	if after := liveHeap(); after > before {
+		r.heapPerSession = (after - before) / uint64(sessions)
+	}
+	slices.Sort(latencies)
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+	r.initMean = total / time.Duration(len(latencies))
+	r.initP95 = latencies[len(latencies)*95/100]
+
+	hints := make([]variants.VariantHints, families)
+	for i := range hints {
+		if hints[i], err = clientHints(i); err != nil {
+			return nil, err
+		}
+	}
+	start := time.Now()
+	for i := range rankCalls {
+		vs.RankedVariants(ctx, hints[i%families])
+	}
+	r.rankCost = time.Since(start) / time.Duration(rankCalls)
+	return r, nil
+}
+
+// liveHeap returns the bytes of live heap objects, once the goroutines of
+// sessions closed by a previous run have exited.
+func liveHeap() uint64 {
+	time.Sleep(50 * time.Millisecond)
+	runtime.GC()
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+// clientHints returns// clientHints returns the hints of the i'th client.
+func clientHints(i int) (variants.VariantHints, error) {
+	return variants.NewHintsBuilder().WithModelFamily(family(i)).Build()
+}
+
+func family(i int) string {
+	return fmt.Sprintf("family-%d", i%families)
+}
+
+func noop(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+	return &mcp.CallToolResult{}, nil, nil
+}
+
+func formatBytes(n uint64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,121 @@
+// Example: Product Docs — variants that differ in resources rather than
+// tools. A documentation server exposes its manuals as resources; variants
+// trade completeness for context budget.
+//
+// Capability demonstrated: Variant-scoped resources, resource templates and
+// subscriptions (resources/list, resources/read,
+// resources/templates/list, resources/subscribe).
+//
+// Variants:
+//   - full: Complete manuals, plus a template for individual sections
+//   - summaries: Condensed manuals, plus a template for key points
+//
+// Both variants serve the same manual URIs (docs://manuals/{topic}), so a
+// client can switch variants without rewriting URIs; the content differs.
+// Subscribers are notified when a manual is revised.
+//
+// Run:
+//
+//	go run ./examples/server/docs
+//
+// Then connect any MCP client to http://localhost:8080.
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+)
+
+func main() {
+	// Full variant: complete manuals and per-section access for agents
+	// with large context windows.
+	fullServer := newDocsServer()
+	for _, m := range manuals {
+		fullServer.AddResource(&mcp.Resource{
+			URI:         manualURI(m.Topic),
+			Name:        m.Topic,
+			Title:       m.Title,
+			Description: "Complete manual: " + m.Title,
+			MIMEType:    "text/markdown",
+		}, readFullManual)
+	}
+	fullServer.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "docs://manuals/{topic}/sections/{section}",
+		Name:        "manual-section",
+		Title:       "Manual section",
+		Description: "A single section of a manual, by topic and section slug (e.g. docs://manuals/deployment/sections/rollback).",
+		MIMEType:    "text/markdown",
+	}, readSection)
+
+	// Summaries variant: condensed manuals and key points for agents with
+	// limited context budgets.
+	summariesServer := newDocsServer()
+	for _, m := range manuals {
+		summariesServer.AddResource(&mcp.Resource{
+			URI:         manualURI(m.Topic),
+			Name:        m.Topic,
+			Title:       m.Title,
+			Description: "Summary of " + m.Title,
+			MIMEType:    "text/markdown",
+		}, readSummary)
+	}
+	summariesServer.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: "docs://manuals/{topic}/key-points",
+		Name:        "manual-key-points",
+		Title:       "Manual key points",
+		Description: "The key points of a manual as a bullet list.",
+		MIMEType:    "text/markdown",
+	}, readKeyPoints)
+
+	vs := variants.NewServer(&mcp.Implementation{Name: "product-docs", Version: "v1.0.0"}).
+		WithVariant(variants.ServerVariant{
+			ID:          "full",
+			Description: "Complete product manuals with per-section access. Best for agents with large context windows that need exact details.",
+			Hints:       map[string]string{"contextSize": "verbose"},
+			Status:      variants.Stable,
+		}, fullServer, 0).
+		WithVariant(variants.ServerVariant{
+			ID:          "summaries",
+			Description: "Condensed manuals and key points. Best for agents with limited context budgets that need orientation rather than detail.",
+			Hints:       map[string]string{"contextSize": "compact"},
+			Status:      variants.Stable,
+		}, summariesServer, 1)
+
+	// Simulate documentation revisions: every 30 seconds a manual is
+	// revised in both variants, notifying subscribers of either.
+	go func() {
+		for i := 0; ; i++ {
+			time.Sleep(30 * time.Second)
+			m := manuals[i%len(manuals)]
+			m.revise()
+			params := &mcp.ResourceUpdatedNotificationParams{URI: manualURI(m.Topic)}
+			fullServer.ResourceUpdated(context.Background(), params)
+			summariesServer.ResourceUpdated(context.Background(), params)
+		}
+	}()
+
+	handler := variants.NewStreamableHTTPHandler(vs, nil)
+
+	log.Println("Listening on :8080")
+	log.Fatal(http.ListenAndServe(":8080", handler))
+}
+
+// newDocsServer returns a server supporting resource subscriptions. The
+// SDK keeps track of subscribers; the handlers only validate the URI.
+func newDocsServer() *mcp.Server {
+	return mcp.NewServer(&mcp.Implementation{Name: "product-docs", Version: "v1.0.0"}, &mcp.ServerOptions{
+		SubscribeHandler: func(_ context.Context, req *mcp.SubscribeRequest) error {
+			if _, ok := manualByURI(req.Params.URI); !ok {
+				return mcp.ResourceNotFoundError(req.Params.URI)
+			}
+			return nil
+		},
+		UnsubscribeHandler: func(context.Context, *mcp.UnsubscribeRequest) error { return nil },
+	})
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type section struct {
+	Slug  string
+	Title string
+	Body  string
+}
+
+type manual struct {
+	Topic     string
+	Title     string
+	Summary   string
+	KeyPoints []string
+	Sections  []section
+
+	mu       sync.Mutex
+	revision int
+}
+
+// revise records a new revision of the manual.
+func (m *manual) revise() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.revision++
+}
+
+func (m *manual) header() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return fmt.Sprintf("# %s\n\n_Revision %d_\n\n", m.Title, m.revision+1)
+}
+
+var manuals = []*manual{
+	{
+		Topic:   "getting-started",
+		Title:   "Getting Started",
+		Summary: "Install the CLI, authenticate with an API key, and create your first project.",
+		KeyPoints: []string{
+			"Install with `brew install acme` or download a release binary.",
+			"Run `acme login` with an API key from the dashboard.",
+			"Create a project with `acme init`.",
+		},
+		Sections: []section{
+			{"installation", "Installation", "Install the CLI with Homebrew (`brew install acme`) or download a release binary for your platform from the releases page. Verify the installation with `acme version`."},
+			{"authentication", "Authentication", "Create an API key in the dashboard under Settings → API keys, then run `acme login` and paste the key. Keys are stored in the system keychain. Use `ACME_API_KEY` in CI."},
+			{"first-project", "Your first project", "Run `acme init` in an empty directory. The wizard asks for a project name and region and writes `acme.yaml`. Commit the file to version control."},
+		},
+	},
+	{
+		Topic:   "deployment",
+		Title:   "Deployment Guide",
+		Summary: "Deploy with `acme deploy`; roll back with `acme rollback`. Deployments are blue-green with automatic health checks.",
+		KeyPoints: []string{
+			"`acme deploy` builds and ships the current commit.",
+			"Traffic shifts only after health checks pass.",
+			"`acme rollback` restores the previous release in seconds.",
+		},
+		Sections: []section{
+			{"deploying", "Deploying", "Run `acme deploy` from the project root. The current commit is built remotely and deployed to a new environment alongside the live one."},
+			{"health-checks", "Health checks", "Traffic is shifted to the new environment once `/healthz` returns 200 for 30 consecutive seconds. Configure the path and duration in `acme.yaml`."},
+			{"rollback", "Rollback", "Run `acme rollback` to route traffic back to the previous environment. Previous environments are kept for 24 hours."},
+		},
+	},
+	{
+		Topic:   "api-reference",
+		Title:   "API Reference",
+		Summary: "REST API at https://api.acme.example/v1 with bearer-token auth; resources are projects, deployments, and keys.",
+		KeyPoints: []string{
+			"Base URL: https://api.acme.example/v1.",
+			"Authenticate with `Authorization: Bearer <key>`.",
+			"Paginate with `page_token`; responses are JSON.",
+		},
+		Sections: []section{
+			{"authentication", "Authentication", "Send `Authorization: Bearer <key>` with every request. Requests without a valid key fail with 401."},
+			{"projects", "Projects", "`GET /projects` lists projects; `POST /projects` creates one from a name and region; `DELETE /projects/{id}` deletes one and all its deployments."},
+			{"pagination", "Pagination", "List endpoints return at most 100 items and a `next_page_token`; pass it as `page_token` to fetch the next page."},
+		},
+	},
+}
+
+func manualURI(topic string) string {
+	return "docs://manuals/" + topic
+}
+
+func manualByURI(uri string) (*manual, bool) {
+	for _, m := range manuals {
+		if manualURI(m.Topic) == uri {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+func markdown(uri, text string) *mcp.ReadResourceResult {
+	return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{
+		{URI: uri, MIMEType: "text/markdown", Text: text},
+	}}
+}
+
+func readFullManual(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	m, ok := manualByURI(req.Params.URI)
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+	var b strings.Builder
+	b.WriteString(m.header())
+	for _, s := range m.Sections {
+		fmt.Fprintf(&b, "## %s\n\n%s\n\n", s.Title, s.Body)
+	}
+	return markdown(req.Params.URI, b.String()), nil
+}
+
+func readSummary(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	m, ok := manualByURI(req.Params.URI)
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+	return markdown(req.Params.URI, m.header()+m.Summary+"\n"), nil
+}
+
+// readSection serves docs://manuals/{topic}/sections/{section}.
+func readSection(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	rest, ok := strings.CutPrefix(req.Params.URI, "docs://manuals/")
+	topic, slug, ok2 := strings.Cut(rest, "/sections/")
+	if m, found := manualByURI(manualURI(topic)); ok && ok2 && found {
+		for _, s := range m.Sections {
+			if s.Slug == slug {
+				return markdown(req.Params.URI, fmt.Sprintf("## %s\n\n%s\n", s.Title, s.Body)), nil
+			}
+		}
+	}
+	return nil, mcp.ResourceNotFoundError(req.Params.URI)
+}
+
+// readKeyPoints serves docs://manuals/{topic}/key-points.
+func readKeyPoints(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	topic, ok := strings.CutSuffix(strings.TrimPrefix(req.Params.URI, "docs://manuals/"), "/key-points")
+	m, found := manualByURI(manualURI(topic))
+	if !ok || !found {
+		return nil, mcp.ResourceNotFoundError(req.Params.URI)
+	}
+	var b strings.Builder
+	for _, p := range m.KeyPoints {
+		fmt.Fprintf(&b, "- %s\n", p)
+	}
+	return markdown(req.Params.URI, b.String()), nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// backendServers are the variant servers of the example, keyed by variant
+// ID. In production each would be a separate code base owned by its team;
+// the gateway only knows their endpoints.
+var backendServers = map[string]func() *mcp.Server{
+	"support":   newSupportServer,
+	"analytics": newAnalyticsServer,
+}
+
+// Support team: order lookups and refunds for customer-facing agents.
+
+type orderInput struct {
+	OrderID string `json:"order_id" jsonschema:"the order ID, e.g. A-1001"`
+}
+
+type orderOutput struct {
+	OrderID string  `json:"order_id"`
+	Status  string  `json:"status"`
+	Total   float64 `json:"total"`
+}
+
+type refundOutput struct {
+	OrderID  string `json:"order_id"`
+	Refunded bool   `json:"refunded"`
+}
+
+func newSupportServer() *mcp.Server {
+	s := mcp.NewServer(&mcp.Implementation{Name: "support-variant", Version: "v1.4.0"}, nil)
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "lookup_order",
+		Description: "Look up an order's status and total.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(_ context.Context, _ *mcp.CallToolRequest, in orderInput) (*mcp.CallToolResult, orderOutput, error) {
+		return nil, orderOutput{OrderID: in.OrderID, Status: "shipped", Total: 42.50}, nil
+	})
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "refund_order",
+		Description: "Refund an order in full.",
+	}, func(_ context.Context, _ *mcp.CallToolRequest, in orderInput) (*mcp.CallToolResult, refundOutput, error) {
+		return nil, refundOutput{OrderID: in.OrderID, Refunded: true}, nil
+	})
+	return s
+}
+
+// Analytics team: read-only reporting for internal agents.
+
+type reportInput struct {
+	Period string `json:"period" jsonschema:"the reporting period, e.g. 2025-Q3"`
+}
+
+func newAnalyticsServer() *mcp.Server {
+	s := mcp.NewServer(&mcp.Implementation{Name: "analytics-variant", Version: "v0.9.0"}, nil)
+	mcp.AddTool(s, &mcp.Tool{
+		Name:        "revenue_report",
+		Description: "Summarize revenue for a period.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(_ context.Context, _ *mcp.CallToolRequest, in reportInput) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Revenue for %s: $1.2M (+8%% over the previous period).", in.Period)}},
+		}, nil, nil
+	})
+	return s
+}
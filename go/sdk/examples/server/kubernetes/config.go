@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+)
+
+// config is the gateway's variant configuration, mounted from a ConfigMap
+// (see deploy/helm/variants-gateway/templates/configmap.yaml).
+type config struct {
+	DefaultVariant string          `json:"defaultVariant,omitempty"`
+	Variants       []variantConfig `json:"variants"`
+}
+
+// variantConfig configures one team's variant.
+type variantConfig struct {
+	ID          string                 `json:"id"`
+	Description string                 `json:"description"`
+	Status      variants.VariantStatus `json:"status,omitempty"`
+	Hints       map[string]string      `json:"hints,omitempty"`
+	Priority    int                    `json:"priority"`
+
+	// Endpoint is the URL of the variant's MCP endpoint. Empty means the
+	// variant's Service is found as described in endpoint.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Enabled takes the variant out of rotation if false. Nil means true.
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// loadConfig reads and validates the configuration at path. It also
+// returns the file's contents, to detect changes.
+func loadConfig(path string) (*config, []byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg, err := parseConfig(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, raw, nil
+}
+
+func parseConfig(raw []byte) (*config, error) {
+	var cfg config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, err
+	}
+	if len(cfg.Variants) == 0 {
+		return nil, fmt.Errorf("no variants configured")
+	}
+	seen := make(map[string]bool)
+	for _, v := range cfg.Variants {
+		if v.ID == "" {
+			return nil, fmt.Errorf("variant without id")
+		}
+		if seen[v.ID] {
+			return nil, fmt.Errorf("duplicate variant %q", v.ID)
+		}
+		seen[v.ID] = true
+	}
+	return &cfg, nil
+}
+
+func (v variantConfig) serverVariant() variants.ServerVariant {
+	status := v.Status
+	if status == "" {
+		status = variants.Stable
+	}
+	return variants.ServerVariant{ID: v.ID, Description: v.Description, Status: status, Hints: v.Hints}
+}
+
+func (v variantConfig) enabled() bool {
+	return v.Enabled == nil || *v.Enabled
+}
+
+// endpoint returns the URL of the variant's MCP endpoint, in order of
+// precedence:
+//
+//   - the VARIANT_<ID>_ENDPOINT environment variable, e.g. to point a
+//     gateway at another namespace without editing the ConfigMap;
+//   - the configured endpoint;
+//   - the Service environment variables Kubernetes injects for a Service
+//     named variant-<id> in the gateway's namespace;
+//   - the Service's cluster DNS name, http://variant-<id>/mcp.
+func (v variantConfig) endpoint() string {
+	name := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(v.ID))
+	if e := os.Getenv("VARIANT_" + name + "_ENDPOINT"); e != "" {
+		return e
+	}
+	if v.Endpoint != "" {
+		return v.Endpoint
+	}
+	host, port := os.Getenv("VARIANT_"+name+"_SERVICE_HOST"), os.Getenv("VARIANT_"+name+"_SERVICE_PORT")
+	if host != "" && port != "" {
+		return "http://" + host + ":" + port + "/mcp"
+	}
+	return "http://variant-" + v.ID + "/mcp"
+}
+
+// rolloutChanges describes the differences from cfg to next that cannot
+// be applied while serving: variants are registered when the gateway
+// starts.
+func (cfg *config) rolloutChanges(next *config) []string {
+	var msgs []string
+	old := make(map[string]variantConfig, len(cfg.Variants))
+	for _, v := range cfg.Variants {
+		old[v.ID] = v
+	}
+	for _, v := range next.Variants {
+		o, ok := old[v.ID]
+		delete(old, v.ID)
+		switch {
+		case !ok:
+			msgs = append(msgs, fmt.Sprintf("variant %s added", v.ID))
+		case o.endpoint() != v.endpoint():
+			msgs = append(msgs, fmt.Sprintf("endpoint of variant %s changed", v.ID))
+		case o.Description != v.Description || o.Priority != v.Priority || !maps.Equal(o.Hints, v.Hints):
+			msgs = append(msgs, fmt.Sprintf("metadata of variant %s changed", v.ID))
+		}
+	}
+	for id := range old {
+		msgs = append(msgs, fmt.Sprintf("variant %s removed", id))
+	}
+	if cfg.DefaultVariant != next.DefaultVariant {
+		msgs = append(msgs, "default variant changed")
+	}
+	return msgs
+}
+
+// watchConfig polls the configuration file and calls apply with each
+// valid new version. Kubernetes updates a mounted ConfigMap by swapping a
+// symlink, which inotify-style watches on the file itself miss, so the
+// contents are compared instead.
+func watchConfig(ctx context.Context, path string, last []byte, interval time.Duration, apply func(*config)) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		raw, err := os.ReadFile(path)
+		if err != nil || bytes.Equal(raw, last) {
+			continue
+		}
+		last = raw
+		cfg, err := parseConfig(raw)
+		if err != nil {
+			log.Printf("config: ignoring invalid update: %v", err)
+			continue
+		}
+		log.Printf("config: reloaded %s", path)
+		apply(cfg)
+	}
+}
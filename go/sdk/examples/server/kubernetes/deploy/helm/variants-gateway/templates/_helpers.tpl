@@ -0,0 +1,21 @@
+{{- define "variants-gateway.labels" -}}
+app.kubernetes.io/name: variants-gateway
+app.kubernetes.io/instance: {{ .Release.Name }}
+{{- end }}
+
+{{/* The gateway's variant configuration, as read by the example. */}}
+{{- define "variants-gateway.config" -}}
+{{- $variants := list }}
+{{- range .Values.variants }}
+{{- $v := dict "id" .id "description" .description "status" .status "priority" .priority "enabled" .enabled }}
+{{- with .hints }}{{ $_ := set $v "hints" . }}{{ end }}
+{{- with .endpoint }}{{ $_ := set $v "endpoint" . }}{{ end }}
+{{- $variants = append $variants $v }}
+{{- end }}
+{{- dict "defaultVariant" .Values.defaultVariant "variants" $variants | toPrettyJson }}
+{{- end }}
+
+{{/* Structural configuration: changing it rolls the gateway out. */}}
+{{- define "variants-gateway.structure" -}}
+{{- range .Values.variants }}{{ .id }}={{ .endpoint }},{{ .priority }},{{ .description }},{{ toJson .hints }};{{ end }}{{ .Values.defaultVariant }}
+{{- end }}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+)
+
+// Reasons for taking a variant out of rotation. Variants held out for other
+// reasons, such as a backend that is not ready yet at startup, are left to
+// the variants server.
+const (
+	reasonDisabled  = "disabled by configuration"
+	reasonUnhealthy = "health check failed"
+)
+
+// rotation decides which variants are in rotation from the configuration
+// and the health checks.
+type rotation struct {
+	vs *variants.Server
+
+	mu        sync.Mutex
+	ids       []string
+	disabled  map[string]bool
+	unhealthy map[string]error
+}
+
+func newRotation(vs *variants.Server) *rotation {
+	r := &rotation{vs: vs, disabled: map[string]bool{}, unhealthy: map[string]error{}}
+	for v := range vs.AllVariants() {
+		r.ids = append(r.ids, v.ID)
+	}
+	return r
+}
+
+// applyConfig applies the parts of cfg that can change while serving:
+// variant statuses, and whether variants are enabled.
+func (r *rotation) applyConfig(cfg *config) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range cfg.Variants {
+		if !slices.Contains(r.ids, v.ID) {
+			continue
+		}
+		if err := r.vs.SetVariantStatus(v.ID, v.serverVariant().Status); err != nil {
+			log.Printf("config: %v", err)
+		}
+		r.disabled[v.ID] = !v.enabled()
+		r.updateLocked(v.ID)
+	}
+}
+
+// updateLocked puts a variant in or out of rotation.
+func (r *rotation) updateLocked(id string) {
+	available, reason := r.vs.VariantAvailability(id)
+	if !available && reason != reasonDisabled && !strings.HasPrefix(reason, reasonUnhealthy) {
+		return
+	}
+	switch err := r.unhealthy[id]; {
+	case r.disabled[id]:
+		reason, available = reasonDisabled, false
+	case err != nil:
+		reason, available = fmt.Sprintf("%s: %v", reasonUnhealthy, err), false
+	default:
+		reason, available = "", true
+	}
+	if err := r.vs.SetVariantAvailability(id, available, reason); err != nil {
+		log.Printf("rotation: %v", err)
+	}
+}
+
+// healthChecks pings each variant's backend every interval, and takes
+// those that fail out of rotation until they recover.
+func (r *rotation) healthChecks(ctx context.Context, cfg *config, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		for _, v := range cfg.Variants {
+			err := ping(ctx, v.endpoint(), interval/2)
+			r.mu.Lock()
+			if (err == nil) != (r.unhealthy[v.ID] == nil) {
+				log.Printf("health: variant %s: %v", v.ID, healthState(err))
+			}
+			if err != nil {
+				r.unhealthy[v.ID] = err
+			} else {
+				delete(r.unhealthy, v.ID)
+			}
+			r.updateLocked(v.ID)
+			r.mu.Unlock()
+		}
+	}
+}
+
+// ready serves the gateway's readiness probe: it is ready while at least
+// one variant is in rotation.
+func (r *rotation) ready(w http.ResponseWriter, _ *http.Request) {
+	for _, id := range r.ids {
+		if available, _ := r.vs.VariantAvailability(id); available {
+			w.Write([]byte("ok\n"))
+			return
+		}
+	}
+	http.Error(w, "no variant in rotation", http.StatusServiceUnavailable)
+}
+
+// ping connects to an MCP endpoint and pings it.
+func ping(ctx context.Context, endpoint string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	client := mcp.NewClient(&mcp.Implementation{Name: "variants-gateway-health", Version: "v1.0.0"}, nil)
+	cs, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: endpoint, MaxRetries: -1}, nil)
+	if err != nil {
+		return err
+	}
+	defer cs.Close()
+	return cs.Ping(ctx, nil)
+}
+
+func healthState(err error) string {
+	if err != nil {
+		return "unhealthy: " + err.Error()
+	}
+	return "healthy"
+}
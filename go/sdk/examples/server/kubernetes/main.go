@@ -0,0 +1,164 @@
+// Example: Kubernetes — the intended production topology for multi-team
+// variant ownership. Each variant is served by its own Deployment and
+// Service, owned and released by the team that builds it; a horizontally
+// scaled gateway discovers them through configuration and routes clients
+// to them as remote variants.
+//
+// Capability demonstrated: Remote variants (WithRemoteVariant), startup in
+// the background while backends come up (WithStartupPolicy), health checks
+// that take failing backends out of rotation (SetVariantAvailability),
+// hot reload of the variant configuration from a ConfigMap
+// (SetVariantStatus), and shared state across gateway replicas
+// (WithSharedStore).
+//
+// The same binary runs both roles:
+//
+//	variants-k8s -role=backend -variant=support    # a team's variant server
+//	variants-k8s -role=gateway                     # the variant gateway
+//
+// Run locally:
+//
+//	go run ./examples/server/kubernetes -role=backend -variant=support -addr=:8081 &
+//	go run ./examples/server/kubernetes -role=backend -variant=analytics -addr=:8082 &
+//	VARIANT_SUPPORT_ENDPOINT=http://localhost:8081/mcp \
+//	VARIANT_ANALYTICS_ENDPOINT=http://localhost:8082/mcp \
+//	go run ./examples/server/kubernetes -role=gateway \
+//	    -config=examples/server/kubernetes/deploy/variants.json
+//
+// Then connect any MCP client to http://localhost:8080/mcp. See README.md
+// for deploying to a cluster with the Helm chart in deploy/helm.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants/redisstore"
+)
+
+func main() {
+	role := flag.String("role", "gateway", "gateway or backend")
+	variant := flag.String("variant", "", "variant to serve, with -role=backend")
+	addr := flag.String("addr", ":8080", "listen address")
+	configPath := flag.String("config", envOr("VARIANTS_CONFIG", "/etc/variants/variants.json"), "variant configuration, with -role=gateway")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var handler http.Handler
+	switch *role {
+	case "backend":
+		server, ok := backendServers[*variant]
+		if !ok {
+			log.Fatalf("unknown variant %q", *variant)
+		}
+		handler = backendHandler(server())
+	case "gateway":
+		h, err := gatewayHandler(ctx, *configPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		handler = h
+	default:
+		log.Fatalf("unknown role %q", *role)
+	}
+
+	srv := &http.Server{Addr: *addr, Handler: handler}
+	go func() {
+		<-ctx.Done()
+		// Kubernetes sends SIGTERM and stops routing to the pod; let
+		// in-flight requests finish.
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	log.Printf("%s listening on %s", *role, *addr)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+}
+
+// gatewayHandler builds the variant gateway from the configuration at
+// path, and starts its health checks and configuration watcher.
+func gatewayHandler(ctx context.Context, path string) (http.Handler, error) {
+	cfg, raw, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	vs := variants.NewServer(&mcp.Implementation{Name: "variants-gateway", Version: "v1.0.0"}).
+		// Teams deploy independently, so a backend may not be ready when the
+		// gateway starts. Serve the others meanwhile.
+		WithStartupPolicy(variants.StartupPolicy{Timeout: 30 * time.Second, Background: true}).
+		WithRetryPolicy(variants.BackendRemote, variants.RetryPolicy{MaxAttempts: 3}).
+		WithAvailabilityReporting(true)
+	for _, v := range cfg.Variants {
+		endpoint := v.endpoint()
+		log.Printf("variant %s: %s", v.ID, endpoint)
+		vs.WithRemoteVariant(v.serverVariant(), endpoint, v.Priority)
+	}
+	if cfg.DefaultVariant != "" {
+		vs.WithDefaultVariant(cfg.DefaultVariant)
+	}
+
+	// Gateway replicas are stateless; with Redis, session pins and cached
+	// rankings are shared between them.
+	if addr := os.Getenv("REDIS_ADDR"); addr != "" {
+		vs.WithSharedStore(redisstore.New(addr, &redisstore.Options{Password: os.Getenv("REDIS_PASSWORD")}), nil).
+			WithRankingCache(256)
+	}
+
+	rot := newRotation(vs)
+	rot.applyConfig(cfg)
+	go rot.healthChecks(ctx, cfg, envDuration("HEALTH_CHECK_INTERVAL", 10*time.Second))
+	go watchConfig(ctx, path, raw, envDuration("CONFIG_RELOAD_INTERVAL", 5*time.Second), func(next *config) {
+		for _, msg := range cfg.rolloutChanges(next) {
+			log.Printf("config: %s; takes effect on the next rollout", msg)
+		}
+		rot.applyConfig(next)
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", variants.NewStreamableHTTPHandler(vs, &mcp.StreamableHTTPOptions{Stateless: true}))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/readyz", rot.ready)
+	return mux, nil
+}
+
+// backendHandler serves a variant's MCP server, with a liveness endpoint
+// for its pods.
+func backendHandler(server *mcp.Server) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	return mux
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
@@ -92,23 +92,24 @@ func main() {
 		Description: "Get historical OHLCV price data for a symbol over a given period",
 	}, getHistoricalData)
 
+	apiGeneration := variants.CustomHint("com.example", "apiGeneration")
 	vs := variants.NewServer(&mcp.Implementation{Name: "trading-platform", Version: "v2.0.0"}).
 		WithVariant(variants.ServerVariant{
 			ID:          "v2-stable",
 			Description: "Production trading API (v2). Full order management with market/limit orders, real-time quotes, portfolio tracking, and order cancellation.",
-			Hints:       map[string]string{"com.example/apiGeneration": "v2", "contextSize": "standard"},
+			Hints:       map[string]string{apiGeneration: "v2", variants.HintContextSize: "standard"},
 			Status:      variants.Stable,
 		}, v2Server, 0).
 		WithVariant(variants.ServerVariant{
 			ID:          "v3-preview",
 			Description: "Next-generation trading API (v3 preview). Adds stop/stop-limit orders, streaming quotes, margin data, and custom order tags. May change without notice.",
-			Hints:       map[string]string{"com.example/apiGeneration": "v3", "contextSize": "standard"},
+			Hints:       map[string]string{apiGeneration: "v3", variants.HintContextSize: "standard"},
 			Status:      variants.Experimental,
 		}, v3Server, 1).
 		WithVariant(variants.ServerVariant{
 			ID:          "v1-legacy",
 			Description: "Legacy trading API (v1). Provides basic trade, quote, and balance operations. Scheduled for removal — migrate to v2-stable.",
-			Hints:       map[string]string{"com.example/apiGeneration": "v1", "contextSize": "compact"},
+			Hints:       map[string]string{apiGeneration: "v1", variants.HintContextSize: "compact"},
 			Status:      variants.Deprecated,
 			DeprecationInfo: &variants.DeprecationInfo{
 				Message:     "v1 API is deprecated. Migrate to v2-stable for improved order types, multi-symbol quotes, and portfolio tracking.",
@@ -119,7 +120,7 @@ func main() {
 		WithVariant(variants.ServerVariant{
 			ID:          "analysis-only",
 			Description: "Read-only analytics variant. Provides market data, portfolio viewing, and historical data without any order placement or modification capabilities.",
-			Hints:       map[string]string{"com.example/apiGeneration": "v2", "useCase": "planning", "contextSize": "standard"},
+			Hints:       map[string]string{apiGeneration: "v2", variants.HintUseCase: "planning", variants.HintContextSize: "standard"},
 			Status:      variants.Stable,
 		}, analysisServer, 1)
 
@@ -0,0 +1,98 @@
+// Example: Coding Tutor — variants that differ in prompts rather than tools.
+// A tutoring server exposes prompt templates phrased for the learner's
+// level; variants swap the whole prompt catalog.
+//
+// Capability demonstrated: Variant-scoped prompts and argument completion
+// (prompts/list, prompts/get, completion/complete).
+//
+// Variants:
+//   - novice: Step-by-step prompts with plain-language phrasing
+//   - expert: Terse prompts that assume background knowledge
+//
+// Both variants offer an "explain" prompt with a "topic" argument, phrased
+// differently; each also offers prompts of its own ("walkthrough" for
+// novices, "review" and "compare" for experts). Topic completion is
+// scoped to the variant's curriculum.
+//
+// Run:
+//
+//	go run ./examples/server/tutor
+//
+// Then connect any MCP client to http://localhost:8080.
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+)
+
+func main() {
+	// Novice variant: beginner-friendly phrasing and guided exercises.
+	noviceServer := mcp.NewServer(&mcp.Implementation{Name: "coding-tutor", Version: "v1.0.0"}, &mcp.ServerOptions{
+		CompletionHandler: completeTopic(noviceTopics),
+	})
+	noviceServer.AddPrompt(&mcp.Prompt{
+		Name:        "explain",
+		Title:       "Explain a concept",
+		Description: "Explain a programming concept from scratch, with an everyday analogy and a small example.",
+		Arguments:   []*mcp.PromptArgument{topicArg},
+	}, promptHandler(noviceExplain))
+	noviceServer.AddPrompt(&mcp.Prompt{
+		Name:        "walkthrough",
+		Title:       "Guided exercise",
+		Description: "Walk through a short exercise on a topic one step at a time, checking understanding after each step.",
+		Arguments:   []*mcp.PromptArgument{topicArg},
+	}, promptHandler(noviceWalkthrough))
+
+	// Expert variant: terse phrasing, trade-offs and internals.
+	expertServer := mcp.NewServer(&mcp.Implementation{Name: "coding-tutor", Version: "v1.0.0"}, &mcp.ServerOptions{
+		CompletionHandler: completeTopic(expertTopics),
+	})
+	expertServer.AddPrompt(&mcp.Prompt{
+		Name:        "explain",
+		Title:       "Explain a concept",
+		Description: "Explain a concept's semantics, implementation details and pitfalls.",
+		Arguments:   []*mcp.PromptArgument{topicArg},
+	}, promptHandler(expertExplain))
+	expertServer.AddPrompt(&mcp.Prompt{
+		Name:        "review",
+		Title:       "Code review",
+		Description: "Review code for correctness, performance and idiomatic style.",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "code", Description: "The code to review", Required: true},
+			topicArg,
+		},
+	}, reviewHandler)
+	expertServer.AddPrompt(&mcp.Prompt{
+		Name:        "compare",
+		Title:       "Compare approaches",
+		Description: "Compare two approaches by trade-offs, with a recommendation.",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "a", Description: "First approach", Required: true},
+			{Name: "b", Description: "Second approach", Required: true},
+		},
+	}, compareHandler)
+
+	vs := variants.NewServer(&mcp.Implementation{Name: "coding-tutor", Version: "v1.0.0"}).
+		WithVariant(variants.ServerVariant{
+			ID:          "novice",
+			Description: "Beginner-friendly prompts with analogies and guided exercises. Best for learners new to programming.",
+			Hints:       map[string]string{"audience": "novice"},
+			Status:      variants.Stable,
+		}, noviceServer, 0).
+		WithVariant(variants.ServerVariant{
+			ID:          "expert",
+			Description: "Terse prompts covering internals, trade-offs and code review. Best for experienced developers.",
+			Hints:       map[string]string{"audience": "expert"},
+			Status:      variants.Stable,
+		}, expertServer, 1)
+
+	handler := variants.NewStreamableHTTPHandler(vs, nil)
+
+	log.Println("Listening on :8080")
+	log.Fatal(http.ListenAndServe(":8080", handler))
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var topicArg = &mcp.PromptArgument{
+	Name:        "topic",
+	Description: "The concept to cover, e.g. \"recursion\"",
+	Required:    true,
+}
+
+var (
+	noviceTopics = []string{"variables", "loops", "functions", "recursion", "lists", "dictionaries"}
+	expertTopics = []string{"closures", "concurrency", "generics", "garbage-collection", "memory-model", "recursion", "tail-calls"}
+)
+
+// completeTopic returns a completion handler suggesting the topics with the
+// typed prefix for the "topic" argument of any prompt.
+func completeTopic(topics []string) func(context.Context, *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+	return func(_ context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+		values := []string{}
+		if req.Params.Ref.Type == "ref/prompt" && req.Params.Argument.Name == "topic" {
+			for _, t := range topics {
+				if strings.HasPrefix(t, strings.ToLower(req.Params.Argument.Value)) {
+					values = append(values, t)
+				}
+			}
+		}
+		slices.Sort(values)
+		return &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{
+			Values: values,
+			Total:  len(values),
+		}}, nil
+	}
+}
+
+// promptHandler returns a prompt handler rendering a single user message
+// from the "topic" argument.
+func promptHandler(render func(topic string) string) mcp.PromptHandler {
+	return func(_ context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		topic := req.Params.Arguments["topic"]
+		if topic == "" {
+			return nil, fmt.Errorf("missing required argument %q", "topic")
+		}
+		return userMessage(render(topic)), nil
+	}
+}
+
+func userMessage(text string) *mcp.GetPromptResult {
+	return &mcp.GetPromptResult{Messages: []*mcp.PromptMessage{
+		{Role: "user", Content: &mcp.TextContent{Text: text}},
+	}}
+}
+
+func noviceExplain(topic string) string {
+	return fmt.Sprintf("I'm new to programming. Please explain %s in plain language. "+
+		"Start with an everyday analogy, then show one short example and explain it line by line. "+
+		"Avoid jargon, or define it when you use it.", topic)
+}
+
+func noviceWalkthrough(topic string) string {
+	return fmt.Sprintf("Guide me through a small exercise about %s. "+
+		"Give me one step at a time and wait for my answer before moving on. "+
+		"If I get something wrong, give me a hint rather than the solution.", topic)
+}
+
+func expertExplain(topic string) string {
+	return fmt.Sprintf("Explain %s: precise semantics, how it is typically implemented, "+
+		"performance characteristics, and common pitfalls. Be concise; skip the basics.", topic)
+}
+
+func reviewHandler(_ context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	code := req.Params.Arguments["code"]
+	if code == "" {
+		return nil, fmt.Errorf("missing required argument %q", "code")
+	}
+	focus := ""
+	if topic := req.Params.Arguments["topic"]; topic != "" {
+		focus = fmt.Sprintf(" Pay particular attention to %s.", topic)
+	}
+	return userMessage(fmt.Sprintf("Review this code for correctness, performance and idiomatic style. "+
+		"List issues by severity with suggested fixes.%s\n\n```\n%s\n```", focus, code)), nil
+}
+
+func compareHandler(_ context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+	a, b := req.Params.Arguments["a"], req.Params.Arguments["b"]
+	if a == "" || b == "" {
+		return nil, fmt.Errorf("missing required arguments %q and %q", "a", "b")
+	}
+	return userMessage(fmt.Sprintf("Compare %s and %s: trade-offs in complexity, performance, "+
+		"and maintainability, then recommend one and say when the other is preferable.", a, b)), nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"maps"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// WithActiveVariantMeta stamps the ID of the variant that served a request
+// into the _meta of its successful result, under the same key clients use
+// to select a variant:
+//
+//	{"_meta": {"io.modelcontextprotocol/server-variant": "coding"}, "tools": [...]}
+//
+// This applies to lists, tools/call, resources/read, prompts/get and
+// completion/complete, so that clients and traces always know which variant
+// answered, as they do from the activeVariant of errors. Translated tool
+// calls carry the variant of the replacement tool. Fan-out results name
+// each variant in their content and structured results instead.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithActiveVariantMeta(enabled bool) *Server {
+	s.activeVariantMeta = enabled
+	return s
+}
+
+// stampActiveVariant sets the variant in result's _meta if enabled (see
+// WithActiveVariantMeta). The _meta map is copied, since it may be shared
+// with the variant's server.
+func (s *Server) stampActiveVariant(result mcp.Result, variantID string) {
+	if !s.activeVariantMeta || isNilInterface(result) {
+		return
+	}
+	meta := maps.Clone(result.GetMeta())
+	if meta == nil {
+		meta = make(map[string]any)
+	}
+	meta[metaKeyVariant] = variantID
+	result.SetMeta(meta)
+}
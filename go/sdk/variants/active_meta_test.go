@@ -0,0 +1,54 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActiveVariantMeta(t *testing.T) {
+	session := connectTestClient(t, newTestVariantServer().WithActiveVariantMeta(true), nil)
+	ctx := context.Background()
+
+	tools, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "coding", tools.Meta[metaKeyVariant])
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      mcp.Meta{metaKeyVariant: "compact"},
+		Name:      "summarize",
+		Arguments: map[string]any{"text": "x"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "compact", res.Meta[metaKeyVariant])
+}
+
+func TestActiveVariantMeta_Resources(t *testing.T) {
+	vs := NewServer(&mcp.Implementation{Name: "docs", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "full"}, newDocsServer("full"), 0).
+		WithVariant(ServerVariant{ID: "summaries"}, newDocsServer("summaries"), 1).
+		WithActiveVariantMeta(true)
+	session := connectTestClient(t, vs, nil)
+
+	res, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{
+		Meta: mcp.Meta{metaKeyVariant: "summaries"},
+		URI:  "docs://guide",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "summaries", res.Meta[metaKeyVariant])
+}
+
+func TestActiveVariantMeta_Disabled(t *testing.T) {
+	session := connectTestClient(t, newTestVariantServer(), nil)
+
+	tools, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	assert.NotContains(t, tools.Meta, metaKeyVariant)
+}
@@ -0,0 +1,277 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+)
+
+// AdminVariant describes a variant in the responses of the admin API (see
+// NewAdminHandler).
+type AdminVariant struct {
+	// ServerVariant is the variant as registered, with any status set by
+	// SetVariantStatus. It is serialized as in a [VariantManifest].
+	ServerVariant
+
+	Availability Availability      `json:"availability"`
+	Stats        AdminVariantStats `json:"stats"`
+
+	// Warmup is the status of the warmup of the variant's connections, if
+	// configured with Server.WithWarmup.
+	Warmup *WarmupStatus `json:"warmup,omitempty"`
+}
+
+// MarshalJSON flattens the embedded variant's priority and Extra entries
+// into the variant's object, as for VariantManifest.
+func (v AdminVariant) MarshalJSON() ([]byte, error) {
+	type plain AdminVariant
+	return marshalFlattened(plain(v), v.ServerVariant)
+}
+
+// AdminVariantStats are the runtime statistics of a variant in the admin
+// API.
+type AdminVariantStats struct {
+	// Sessions is the number of stateful sessions whose default variant
+	// is the variant.
+	Sessions int `json:"sessions"`
+
+	// PinnedSessions is the number of stateful sessions pinned to the
+	// variant (see Server.PinSession).
+	PinnedSessions int `json:"pinnedSessions"`
+
+	// Connections is the number of stateful sessions connected to the
+	// variant.
+	Connections int `json:"connections"`
+
+	// Pool holds the statistics of the variant's connection pool in
+	// stateless mode (see Server.WithStatelessPool).
+	Pool *PoolStats `json:"pool,omitempty"`
+}
+
+// NewAdminHandler returns an [http.Handler] serving a JSON API for runtime
+// control of the server by operators, akin to Envoy's admin interface:
+//
+//	GET    /variants                    list variants with availability and stats
+//	GET    /variants/{id}               get a variant
+//	PUT    /variants/{id}/availability  {"available": false, "reason": "..."}
+//	PUT    /variants/{id}/degraded      {"degraded": true, "reason": "..."}
+//	PUT    /variants/{id}/status        {"status": "deprecated"}
+//	POST   /variants/{id}/drain         close the sessions defaulting to the variant
+//	GET    /sessions                    list stateful sessions
+//	GET    /sessions/{id}               get a session
+//	DELETE /sessions/{id}               close a session
+//	PUT    /sessions/{id}/pin           {"variant": "..."}; an empty variant unpins
+//
+// Changes respond with the updated variant or session; draining responds
+// with {"closed": n} and requires the application/json content type, as a
+// guard against cross-site requests. The API is unauthenticated: serve it
+// on a separate, private listener rather than next to the MCP endpoint:
+//
+//	go http.ListenAndServe("localhost:9901", variants.NewAdminHandler(vs))
+//
+// Mount it under a prefix with [http.StripPrefix].
+func NewAdminHandler(vs *Server) http.Handler {
+	if vs == nil {
+		panic("variants: nil Server")
+	}
+	mux := http.NewServeMux()
+	// variant registers a handler for a route of a registered variant.
+	variant := func(pattern string, h func(w http.ResponseWriter, r *http.Request, id string)) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			id := r.PathValue("id")
+			if !vs.hasVariant(id) {
+				http.Error(w, "unknown variant", http.StatusNotFound)
+				return
+			}
+			h(w, r, id)
+		})
+	}
+	mux.HandleFunc("GET /variants", func(w http.ResponseWriter, r *http.Request) {
+		writeAdminJSON(w, vs.adminVariants())
+	})
+	variant("GET /variants/{id}", func(w http.ResponseWriter, r *http.Request, id string) {
+		vs.writeAdminVariant(w, id, nil)
+	})
+	variant("PUT /variants/{id}/availability", func(w http.ResponseWriter, r *http.Request, id string) {
+		var body struct {
+			Available *bool  `json:"available"`
+			Reason    string `json:"reason"`
+		}
+		if !readAdminJSON(w, r, &body) {
+			return
+		}
+		if body.Available == nil {
+			http.Error(w, `missing "available"`, http.StatusBadRequest)
+			return
+		}
+		vs.writeAdminVariant(w, id, vs.SetVariantAvailability(id, *body.Available, body.Reason))
+	})
+	variant("PUT /variants/{id}/degraded", func(w http.ResponseWriter, r *http.Request, id string) {
+		var body struct {
+			Degraded *bool  `json:"degraded"`
+			Reason   string `json:"reason"`
+		}
+		if !readAdminJSON(w, r, &body) {
+			return
+		}
+		if body.Degraded == nil {
+			http.Error(w, `missing "degraded"`, http.StatusBadRequest)
+			return
+		}
+		vs.writeAdminVariant(w, id, vs.SetVariantDegraded(id, *body.Degraded, body.Reason))
+	})
+	variant("PUT /variants/{id}/status", func(w http.ResponseWriter, r *http.Request, id string) {
+		var body struct {
+			Status VariantStatus `json:"status"`
+		}
+		if !readAdminJSON(w, r, &body) {
+			return
+		}
+		vs.writeAdminVariant(w, id, vs.SetVariantStatus(id, body.Status))
+	})
+	variant("POST /variants/{id}/drain", func(w http.ResponseWriter, r *http.Request, id string) {
+		// Draining takes no body. Requiring a JSON content type, which
+		// browsers only send cross-site after a CORS preflight, keeps
+		// pages from draining variants (CSRF).
+		if !isJSONRequest(r) {
+			http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+			return
+		}
+		closed := 0
+		for _, info := range vs.Sessions() {
+			if info.DefaultVariant == id && vs.CloseSession(info.ID) == nil {
+				closed++
+			}
+		}
+		writeAdminJSON(w, map[string]int{"closed": closed})
+	})
+	mux.HandleFunc("GET /sessions", func(w http.ResponseWriter, r *http.Request) {
+		sessions := vs.Sessions()
+		if sessions == nil {
+			sessions = []SessionInfo{}
+		}
+		writeAdminJSON(w, sessions)
+	})
+	mux.HandleFunc("GET /sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		vs.writeAdminSession(w, r.PathValue("id"), nil)
+	})
+	mux.HandleFunc("DELETE /sessions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if err := vs.CloseSession(r.PathValue("id")); err != nil {
+			writeAdminError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("PUT /sessions/{id}/pin", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variant string `json:"variant"`
+		}
+		if !readAdminJSON(w, r, &body) {
+			return
+		}
+		id := r.PathValue("id")
+		vs.writeAdminSession(w, id, vs.PinSession(id, body.Variant))
+	})
+	return mux
+}
+
+// adminVariants describes the registered variants, in registration order.
+func (s *Server) adminVariants() []AdminVariant {
+	stats := make(map[string]*AdminVariantStats, len(s.variants))
+	for _, v := range s.variants {
+		stats[v.variant.ID] = &AdminVariantStats{}
+	}
+	for _, info := range s.Sessions() {
+		if st := stats[info.DefaultVariant]; st != nil {
+			st.Sessions++
+		}
+		if st := stats[info.PinnedVariant]; st != nil {
+			st.PinnedSessions++
+		}
+		for _, c := range info.Connections {
+			if c.Connected {
+				stats[c.VariantID].Connections++
+			}
+		}
+	}
+	out := make([]AdminVariant, 0, len(s.variants))
+	for _, v := range s.Variants() {
+		st := stats[v.ID]
+		if p, ok := s.PoolStats(v.ID); ok {
+			st.Pool = &p
+		}
+		av := AdminVariant{ServerVariant: v, Availability: s.availability(v.ID), Stats: *st}
+		if w, ok := s.WarmupStatus(v.ID); ok {
+			av.Warmup = &w
+		}
+		out = append(out, av)
+	}
+	return out
+}
+
+// writeAdminVariant responds with the registered variant with the given ID,
+// or with err if non-nil.
+func (s *Server) writeAdminVariant(w http.ResponseWriter, id string, err error) {
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	writeAdminJSON(w, s.adminVariants()[s.variantIndex[id]])
+}
+
+// writeAdminSession responds with the session with the given ID, or with
+// err if non-nil.
+func (s *Server) writeAdminSession(w http.ResponseWriter, id string, err error) {
+	if err == nil {
+		var info SessionInfo
+		if info, err = s.Session(id); err == nil {
+			writeAdminJSON(w, info)
+			return
+		}
+	}
+	writeAdminError(w, err)
+}
+
+// writeAdminError responds with err: 404 for unknown sessions, else 400,
+// e.g. for an invalid status or pin to an unknown variant.
+func writeAdminError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, ErrUnknownSession) {
+		status = http.StatusNotFound
+	}
+	http.Error(w, err.Error(), status)
+}
+
+// readAdminJSON decodes the request body into v, responding with an error
+// and returning false if it is malformed.
+func readAdminJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// isJSONRequest reports whether r declares a JSON body.
+func isJSONRequest(r *http.Request) bool {
+	mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mt == "application/json"
+}
+
+// writeAdminJSON responds with v as indented JSON.
+func writeAdminJSON(w http.ResponseWriter, v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(append(data, '\n'))
+}
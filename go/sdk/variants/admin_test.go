@@ -0,0 +1,144 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// adminRequest sends a request to the admin API, returning the status code
+// and the body.
+func adminRequest(t *testing.T, url, method, path, body string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(data)
+}
+
+func TestAdminHandler(t *testing.T) {
+	vs := newTestVariantServer()
+	mcpSrv := httptest.NewServer(NewStreamableHTTPHandler(vs, nil))
+	t.Cleanup(mcpSrv.Close)
+	t.Cleanup(func() { vs.Close() })
+	admin := httptest.NewServer(NewAdminHandler(vs))
+	t.Cleanup(admin.Close)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{Endpoint: mcpSrv.URL}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() })
+
+	code, body := adminRequest(t, admin.URL, "GET", "/variants", "")
+	require.Equal(t, http.StatusOK, code)
+	var variants []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(body), &variants))
+	require.Len(t, variants, 2)
+	assert.Equal(t, "coding", variants[0]["id"])
+	assert.Equal(t, map[string]any{"status": "available"}, variants[0]["availability"])
+	assert.Equal(t, map[string]any{"sessions": 1.0, "pinnedSessions": 0.0, "connections": 1.0}, variants[0]["stats"])
+	assert.Equal(t, 1.0, variants[1]["priority"])
+
+	code, body = adminRequest(t, admin.URL, "PUT", "/variants/compact/availability", `{"available": false, "reason": "maintenance"}`)
+	require.Equal(t, http.StatusOK, code, body)
+	assert.JSONEq(t, `{"status": "unavailable", "reason": "maintenance"}`, string(mustField(t, body, "availability")))
+	available, reason := vs.VariantAvailability("compact")
+	assert.False(t, available)
+	assert.Equal(t, "maintenance", reason)
+
+	code, body = adminRequest(t, admin.URL, "PUT", "/variants/compact/availability", `{"available": true}`)
+	require.Equal(t, http.StatusOK, code, body)
+	code, body = adminRequest(t, admin.URL, "PUT", "/variants/coding/degraded", `{"degraded": true, "reason": "slow"}`)
+	require.Equal(t, http.StatusOK, code, body)
+	assert.JSONEq(t, `{"status": "degraded", "reason": "slow"}`, string(mustField(t, body, "availability")))
+
+	code, body = adminRequest(t, admin.URL, "PUT", "/variants/compact/status", `{"status": "deprecated"}`)
+	require.Equal(t, http.StatusOK, code, body)
+	assert.Equal(t, `"deprecated"`, string(mustField(t, body, "status")))
+	code, _ = adminRequest(t, admin.URL, "PUT", "/variants/compact/status", `{"status": "retired"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = adminRequest(t, admin.URL, "GET", "/variants/nope", "")
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = adminRequest(t, admin.URL, "PUT", "/variants/coding/availability", `{}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = adminRequest(t, admin.URL, "PUT", "/variants/coding/availability", `{"available": true, "bogus": 1}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// Sessions.
+	code, body = adminRequest(t, admin.URL, "GET", "/sessions", "")
+	require.Equal(t, http.StatusOK, code)
+	var sessions []SessionInfo
+	require.NoError(t, json.Unmarshal([]byte(body), &sessions))
+	require.Len(t, sessions, 1)
+	assert.Equal(t, session.ID(), sessions[0].ID)
+
+	code, body = adminRequest(t, admin.URL, "PUT", "/sessions/"+session.ID()+"/pin", `{"variant": "compact"}`)
+	require.Equal(t, http.StatusOK, code, body)
+	assert.Equal(t, `"compact"`, string(mustField(t, body, "pinnedVariant")))
+	code, _ = adminRequest(t, admin.URL, "PUT", "/sessions/"+session.ID()+"/pin", `{"variant": "nope"}`)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = adminRequest(t, admin.URL, "GET", "/sessions/nope", "")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// Draining requires a JSON content type, which cross-site form posts
+	// cannot send.
+	resp, err := http.Post(admin.URL+"/variants/compact/drain", "application/x-www-form-urlencoded", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnsupportedMediaType, resp.StatusCode)
+	assert.Len(t, vs.Sessions(), 1)
+
+	// Draining closes the sessions defaulting to the variant.
+	code, body = adminRequest(t, admin.URL, "POST", "/variants/coding/drain", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"closed": 0}`, body)
+	code, body = adminRequest(t, admin.URL, "POST", "/variants/compact/drain", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"closed": 1}`, body)
+	assert.Eventually(t, func() bool { return len(vs.Sessions()) == 0 }, time.Second, 10*time.Millisecond)
+
+	code, _ = adminRequest(t, admin.URL, "DELETE", "/sessions/"+session.ID(), "")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+// mustField returns a top-level field of a JSON object.
+func mustField(t *testing.T, body, field string) json.RawMessage {
+	t.Helper()
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal([]byte(body), &fields))
+	return fields[field]
+}
+
+func TestAdminVariant_MarshalJSON(t *testing.T) {
+	v := AdminVariant{
+		ServerVariant: ServerVariant{ID: "coding", Extra: map[string]any{"example.com/tier": "pro"}, priority: 2},
+		Availability:  Availability{Status: "available"},
+	}
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, "coding", fields["id"])
+	assert.Equal(t, 2.0, fields["priority"])
+	assert.Equal(t, "pro", fields["example.com/tier"])
+	assert.Equal(t, map[string]any{"status": "available"}, fields["availability"])
+}
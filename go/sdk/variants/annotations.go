@@ -0,0 +1,71 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// AnnotationOverride forces tool annotations for all tools of a variant.
+// Nil fields leave the annotation as the variant's server reports it.
+type AnnotationOverride struct {
+	ReadOnlyHint    *bool
+	DestructiveHint *bool
+	IdempotentHint  *bool
+	OpenWorldHint   *bool
+}
+
+// WithAnnotationOverride overrides the annotations of every tool listed by
+// the given variant, e.g. to force readOnlyHint on all tools of an
+// analysis-only variant whose server does not annotate them. Annotations
+// of other variants' tools are passed through unchanged. A later override
+// for the same variant replaces the earlier one.
+//
+// The variant must be registered by the time the server is served.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithAnnotationOverride(variantID string, o AnnotationOverride) *Server {
+	if s.annotationOverrides == nil {
+		s.annotationOverrides = make(map[string]AnnotationOverride)
+	}
+	s.annotationOverrides[variantID] = o
+	return s
+}
+
+// validateAnnotationOverrides checks that overrides name registered
+// variants.
+func (s *Server) validateAnnotationOverrides() error {
+	for id := range s.annotationOverrides {
+		if !s.hasVariant(id) {
+			return fmt.Errorf("variants: annotation override for unregistered variant %q", id)
+		}
+	}
+	return nil
+}
+
+// apply returns a copy of ann with the override applied. ann may be nil.
+func (o AnnotationOverride) apply(ann *mcp.ToolAnnotations) *mcp.ToolAnnotations {
+	var out mcp.ToolAnnotations
+	if ann != nil {
+		out = *ann
+	}
+	if o.ReadOnlyHint != nil {
+		out.ReadOnlyHint = *o.ReadOnlyHint
+	}
+	if o.DestructiveHint != nil {
+		v := *o.DestructiveHint
+		out.DestructiveHint = &v
+	}
+	if o.IdempotentHint != nil {
+		out.IdempotentHint = *o.IdempotentHint
+	}
+	if o.OpenWorldHint != nil {
+		v := *o.OpenWorldHint
+		out.OpenWorldHint = &v
+	}
+	return &out
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolAnnotations(t *testing.T) {
+	yes, no := true, false
+	newInner := func() *mcp.Server {
+		s := mcp.NewServer(&mcp.Implementation{Name: "inner", Version: "v1.0.0"}, nil)
+		mcp.AddTool(s, &mcp.Tool{Name: "delete", Annotations: &mcp.ToolAnnotations{
+			DestructiveHint: &yes,
+			IdempotentHint:  true,
+			Title:           "Delete",
+		}}, lookup)
+		mcp.AddTool(s, &mcp.Tool{Name: "lookup"}, lookup)
+		return s
+	}
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "full"}, newInner(), 0).
+		WithVariant(ServerVariant{ID: "analysis"}, newInner(), 1).
+		WithAnnotationOverride("analysis", AnnotationOverride{ReadOnlyHint: &yes, DestructiveHint: &no})
+	session := connectTestClient(t, vs, nil)
+
+	annotations := func(variant string) map[string]*mcp.ToolAnnotations {
+		res, err := session.ListTools(context.Background(), &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: variant}})
+		require.NoError(t, err)
+		m := map[string]*mcp.ToolAnnotations{}
+		for _, tool := range res.Tools {
+			m[tool.Name] = tool.Annotations
+		}
+		return m
+	}
+
+	t.Run("passed through", func(t *testing.T) {
+		got := annotations("full")
+		assert.Equal(t, &mcp.ToolAnnotations{DestructiveHint: &yes, IdempotentHint: true, Title: "Delete"}, got["delete"])
+		assert.Nil(t, got["lookup"])
+	})
+
+	t.Run("overridden", func(t *testing.T) {
+		got := annotations("analysis")
+		assert.Equal(t, &mcp.ToolAnnotations{ReadOnlyHint: true, DestructiveHint: &no, IdempotentHint: true, Title: "Delete"}, got["delete"])
+		assert.Equal(t, &mcp.ToolAnnotations{ReadOnlyHint: true, DestructiveHint: &no}, got["lookup"])
+
+		// Overrides do not affect other variants.
+		assert.Equal(t, &mcp.ToolAnnotations{DestructiveHint: &yes, IdempotentHint: true, Title: "Delete"}, annotations("full")["delete"])
+	})
+}
+
+func TestWithAnnotationOverride_Unregistered(t *testing.T) {
+	yes := true
+	vs := newTestVariantServer().WithAnnotationOverride("missing", AnnotationOverride{ReadOnlyHint: &yes})
+	_, err := vs.NewRouter(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"missing"`)
+}
@@ -0,0 +1,49 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Attach exposes the variants of vs on an existing server, for servers
+// configured with their own options and middleware that cannot be replaced
+// by the front server of [Server.Run]. It creates a router for vs (see
+// [Server.NewRouter]; opts may be nil) and installs it in existing (see
+// [VariantRouter.Install]): initialize results carry the variants
+// extension, and variant-scoped requests are routed to the variants.
+//
+// Since existing's capabilities were fixed when it was created, Attach also
+// adds the variants' capabilities to its initialize results. Tools, prompts
+// and resources registered on existing itself are no longer listed or
+// reachable, as the variants answer those requests.
+//
+// Middleware added to existing after Attach sees requests before the
+// variants extension does. Closing vs detaches the variants.
+func Attach(existing *mcp.Server, vs *Server, opts *RouterOptions) (*VariantRouter, error) {
+	r, err := vs.NewRouter(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Install(existing); err != nil {
+		return nil, err
+	}
+	existing.AddReceivingMiddleware(r.advertiseMiddleware)
+	return r, nil
+}
+
+// advertiseMiddleware adds the variants' capabilities to initialize
+// results, for servers created without them.
+func (r *VariantRouter) advertiseMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		result, err := next(ctx, method, req)
+		if res, ok := result.(*mcp.InitializeResult); ok && err == nil && res != nil {
+			res.Capabilities = unionCapabilities([]*mcp.ServerCapabilities{res.Capabilities, r.caps})
+		}
+		return result, err
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttach(t *testing.T) {
+	existing := mcp.NewServer(&mcp.Implementation{Name: "existing", Version: "1.0.0"}, &mcp.ServerOptions{
+		Instructions: "Use the variants.",
+	})
+	var seen atomic.Int32
+	existing.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			seen.Add(1)
+			return next(ctx, method, req)
+		}
+	})
+	vs := newTestVariantServer()
+	_, err := Attach(existing, vs, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { vs.Close() })
+
+	// A server may back several routers, so it can be attached twice.
+	_, err = Attach(mcp.NewServer(&mcp.Implementation{Name: "other"}, nil), vs, nil)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	st, ct := mcp.NewInMemoryTransports()
+	ss, err := existing.Connect(ctx, st, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { ss.Close() })
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(ctx, ct, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() })
+
+	init := session.InitializeResult()
+	assert.Equal(t, "existing", init.ServerInfo.Name)
+	assert.Equal(t, "Use the variants.", init.Instructions)
+	require.NotNil(t, init.Capabilities.Tools)
+	assert.Contains(t, init.Capabilities.Experimental, extensionID)
+
+	tools, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"analyze_code", "refactor"}, toolNames(tools.Tools))
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      mcp.Meta{metaKeyVariant: "compact"},
+		Name:      "summarize",
+		Arguments: map[string]any{"text": "x"},
+	})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+	assert.Positive(t, seen.Load())
+}
@@ -0,0 +1,232 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// CodeVariantUnavailable is the JSON-RPC error code returned when a request
+// targets a variant that has been taken out of rotation with
+// [Server.SetVariantAvailability]. The condition is temporary, so clients
+// may retry later or select another variant.
+const CodeVariantUnavailable int64 = -32050
+
+// SetVariantAvailability takes a registered variant out of rotation
+// (available = false) or returns it to rotation, e.g. during backend
+// maintenance, without unregistering it. While unavailable, the variant is
+// hidden from ranking and requests selecting it are rejected with a
+// [CodeVariantUnavailable] error carrying reason. Requests without a variant
+// selection fall back to the next available variant.
+//
+// Sessions of clients declaring the variants extension are sent their
+// re-ranked variants payload when the availability changes, as for hint
+// updates.
+//
+// It is safe to call concurrently with request handling. It returns an error
+// if no variant with the given ID is registered.
+func (s *Server) SetVariantAvailability(id string, available bool, reason string) error {
+	if !s.hasVariant(id) {
+		return fmt.Errorf("variants: unknown variant %q", id)
+	}
+	s.setAvailability(id, available, reason, nil)
+	return nil
+}
+
+// setAvailability records a variant's availability and, if it changed,
+// notifies sessions (see notifyCatalogChanged). If ifReason is non-nil, the
+// availability is only changed while the variant is out of rotation for
+// that reason.
+func (s *Server) setAvailability(id string, available bool, reason string, ifReason *string) {
+	// Copy on write, so that the request path reads availability without
+	// locking.
+	s.mu.Lock()
+	old := s.unavailableVariants()
+	oldReason, unavailable := old[id]
+	if ifReason != nil && (!unavailable || oldReason != *ifReason) ||
+		available == !unavailable && (available || oldReason == reason) {
+		s.mu.Unlock()
+		return
+	}
+	m := make(map[string]string, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	if available {
+		delete(m, id)
+	} else {
+		m[id] = reason
+	}
+	s.unavailable.Store(&m)
+	s.mu.Unlock()
+	s.rankCache.invalidate()
+	s.payloads.invalidate()
+	s.notifyCatalogChanged()
+}
+
+// VariantAvailability reports whether the variant with the given ID is in
+// rotation and, if not, the reason given to SetVariantAvailability.
+func (s *Server) VariantAvailability(id string) (available bool, reason string) {
+	reason, unavailable := s.unavailableVariants()[id]
+	return !unavailable, reason
+}
+
+// AvailabilityStatus describes a variant's health as reported to clients
+// with Server.WithAvailabilityReporting.
+type AvailabilityStatus string
+
+const (
+	// AvailabilityAvailable indicates a variant in rotation and healthy.
+	AvailabilityAvailable AvailabilityStatus = "available"
+	// AvailabilityDegraded indicates a variant in rotation whose backend is
+	// impaired, e.g. slow or partially failing (see SetVariantDegraded).
+	AvailabilityDegraded AvailabilityStatus = "degraded"
+	// AvailabilityUnavailable indicates a variant out of rotation (see
+	// SetVariantAvailability).
+	AvailabilityUnavailable AvailabilityStatus = "unavailable"
+)
+
+// Availability is a variant's health as reported to clients in the
+// "availability" field of availableVariants.
+type Availability struct {
+	Status AvailabilityStatus `json:"status"`
+	Reason string             `json:"reason,omitempty"`
+}
+
+// SetVariantDegraded marks a registered variant as degraded (or healthy
+// again), e.g. while its backend is slow or partially failing. Degraded
+// variants stay in rotation and are ranked as usual; the state is only
+// reported to clients, so that they can prefer healthy variants (see
+// WithAvailabilityReporting), and sessions are notified as for
+// SetVariantAvailability. Out of rotation, a variant is reported as
+// unavailable regardless.
+//
+// It is safe to call concurrently with request handling. It returns an error
+// if no variant with the given ID is registered.
+func (s *Server) SetVariantDegraded(id string, degraded bool, reason string) error {
+	if !s.hasVariant(id) {
+		return fmt.Errorf("variants: unknown variant %q", id)
+	}
+	s.mu.Lock()
+	old := s.degradedVariants()
+	if oldReason, ok := old[id]; degraded == ok && (!degraded || oldReason == reason) {
+		s.mu.Unlock()
+		return nil
+	}
+	m := make(map[string]string, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	if degraded {
+		m[id] = reason
+	} else {
+		delete(m, id)
+	}
+	s.degraded.Store(&m)
+	s.mu.Unlock()
+	if s.reportAvailability {
+		s.notifyCatalogChanged()
+	}
+	return nil
+}
+
+// WithAvailabilityReporting enables or disables the "availability" field of
+// each entry of availableVariants, an [Availability] object describing the
+// variant's health. When enabled, variants out of rotation are listed as
+// well, after the ranked variants and with status "unavailable", so that
+// clients know which variants to avoid rather than discovering it through
+// failed calls. Disabled by default.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithAvailabilityReporting(enabled bool) *Server {
+	s.reportAvailability = enabled
+	return s
+}
+
+// availability returns the health of the variant with the given ID.
+func (s *Server) availability(id string) Availability {
+	if reason, ok := s.unavailableVariants()[id]; ok {
+		return Availability{Status: AvailabilityUnavailable, Reason: reason}
+	}
+	if reason, ok := s.degradedVariants()[id]; ok {
+		return Availability{Status: AvailabilityDegraded, Reason: reason}
+	}
+	return Availability{Status: AvailabilityAvailable}
+}
+
+// unavailableVariantsList returns the registered variants out of rotation
+// in priority order.
+func (s *Server) unavailableVariantsList() []ServerVariant {
+	unavailable := s.unavailableVariants()
+	if len(unavailable) == 0 {
+		return nil
+	}
+	var out []ServerVariant
+	for _, id := range s.defaultOrder() {
+		if _, ok := unavailable[id]; ok {
+			v, _ := s.lookupVariant(id)
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// degradedVariants returns the reasons of degraded variants, keyed by
+// variant ID. The map must not be modified.
+func (s *Server) degradedVariants() map[string]string {
+	if m := s.degraded.Load(); m != nil {
+		return *m
+	}
+	return nil
+}
+
+// unavailableVariants returns the reasons of variants out of rotation, keyed
+// by variant ID. The map must not be modified.
+func (s *Server) unavailableVariants() map[string]string {
+	if m := s.unavailable.Load(); m != nil {
+		return *m
+	}
+	return nil
+}
+
+// isAvailable reports whether the variant with the given ID is in rotation.
+func (s *Server) isAvailable(id string) bool {
+	available, _ := s.VariantAvailability(id)
+	return available
+}
+
+// filterAvailable removes variants that are out of rotation, in place.
+func (s *Server) filterAvailable(vs []ServerVariant) []ServerVariant {
+	unavailable := s.unavailableVariants()
+	if len(unavailable) == 0 {
+		return vs
+	}
+	out := vs[:0]
+	for _, v := range vs {
+		if _, ok := unavailable[v.ID]; !ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// unavailableVariantError returns the retriable error for a request that
+// selected a variant that is out of rotation.
+func (s *Server) unavailableVariantError(id string) error {
+	_, reason := s.VariantAvailability(id)
+	dataJSON, _ := json.Marshal(map[string]any{
+		"requestedVariant": id,
+		"reason":           reason,
+		"retriable":        true,
+	})
+	return &jsonrpc.Error{
+		Code:    CodeVariantUnavailable,
+		Message: "Server variant temporarily unavailable",
+		Data:    json.RawMessage(dataJSON),
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetVariantAvailability(t *testing.T) {
+	vs := newTestVariantServer()
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	require.Error(t, vs.SetVariantAvailability("nonexistent", false, ""))
+	require.NoError(t, vs.SetVariantAvailability("coding", false, "backend upgrade"))
+
+	available, reason := vs.VariantAvailability("coding")
+	assert.False(t, available)
+	assert.Equal(t, "backend upgrade", reason)
+
+	// Hidden from ranking.
+	ranked := vs.RankedVariants(ctx, VariantHints{})
+	require.Len(t, ranked, 1)
+	assert.Equal(t, "compact", ranked[0].ID)
+
+	// Explicit selection is rejected with a retriable error.
+	_, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: "coding"}})
+	require.Error(t, err)
+	var jErr *jsonrpc.Error
+	require.True(t, errors.As(err, &jErr))
+	assert.Equal(t, CodeVariantUnavailable, jErr.Code)
+	assert.JSONEq(t, `{"requestedVariant":"coding","reason":"backend upgrade","retriable":true}`, string(jErr.Data))
+
+	// Requests without a selection fall back to the next available variant.
+	tools, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.Contains(t, toolNames(tools.Tools), "summarize")
+
+	// Back in rotation.
+	require.NoError(t, vs.SetVariantAvailability("coding", true, ""))
+	tools, err = session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.Contains(t, toolNames(tools.Tools), "analyze_code")
+	assert.Len(t, vs.RankedVariants(ctx, VariantHints{}), 2)
+}
+
+// TestAvailabilityReporting verifies that availableVariants carries each
+// variant's health when enabled, listing variants out of rotation last.
+func TestAvailabilityReporting(t *testing.T) {
+	type variantPayload struct {
+		ID           string        `json:"id"`
+		Availability *Availability `json:"availability"`
+	}
+	type payload struct {
+		AvailableVariants []variantPayload `json:"availableVariants"`
+		DefaultVariant    string           `json:"defaultVariant"`
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		vs := newTestVariantServer()
+		require.NoError(t, vs.SetVariantAvailability("coding", false, "backend upgrade"))
+		var p payload
+		initExtension(t, connectTestClient(t, vs, nil), &p)
+		require.Len(t, p.AvailableVariants, 1)
+		assert.Nil(t, p.AvailableVariants[0].Availability)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		vs := newTestVariantServer().WithAvailabilityReporting(true)
+		require.NoError(t, vs.SetVariantAvailability("coding", false, "backend upgrade"))
+		require.NoError(t, vs.SetVariantDegraded("compact", true, "high latency"))
+		require.Error(t, vs.SetVariantDegraded("nonexistent", true, ""))
+
+		var p payload
+		initExtension(t, connectTestClient(t, vs, nil), &p)
+		assert.Equal(t, []variantPayload{
+			{ID: "compact", Availability: &Availability{Status: AvailabilityDegraded, Reason: "high latency"}},
+			{ID: "coding", Availability: &Availability{Status: AvailabilityUnavailable, Reason: "backend upgrade"}},
+		}, p.AvailableVariants)
+		assert.Equal(t, "compact", p.DefaultVariant)
+	})
+}
+
+// TestSetVariantDegraded_Notification verifies that variant-aware sessions
+// are notified of health changes when availability is reported.
+func TestSetVariantDegraded_Notification(t *testing.T) {
+	vs := newTestVariantServer().WithAvailabilityReporting(true)
+	updates := make(chan *mcp.ToolListChangedRequest, 1)
+	opts := hintsClientOptions(map[string]any{HintContextSize: "coding"})
+	opts.ToolListChangedHandler = func(_ context.Context, req *mcp.ToolListChangedRequest) {
+		updates <- req
+	}
+	connectTestClient(t, vs, opts)
+
+	require.NoError(t, vs.SetVariantDegraded("coding", true, "high latency"))
+	select {
+	case req := <-updates:
+		data, err := json.Marshal(req.Params.Meta[extensionID])
+		require.NoError(t, err)
+		var p struct {
+			AvailableVariants []struct {
+				ID           string       `json:"id"`
+				Availability Availability `json:"availability"`
+			} `json:"availableVariants"`
+		}
+		require.NoError(t, json.Unmarshal(data, &p))
+		require.Len(t, p.AvailableVariants, 2)
+		assert.Equal(t, "coding", p.AvailableVariants[0].ID)
+		assert.Equal(t, Availability{Status: AvailabilityDegraded, Reason: "high latency"}, p.AvailableVariants[0].Availability)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for variants update notification")
+	}
+
+	available, _ := vs.VariantAvailability("coding")
+	assert.True(t, available)
+}
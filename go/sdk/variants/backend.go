@@ -6,25 +6,39 @@ package variants
 
 import (
 	"context"
+	"maps"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // backend abstracts how a variant connects to its backing MCP server.
-// The in-memory implementation lives in this file; future implementations
-// (HTTP, remote) will satisfy the same interface.
+// The in-memory implementation lives in this file; remote.go implements
+// remote variants.
 type backend interface {
 	// connect creates a connection to the backing server and returns an
 	// innerConnection for dispatching requests. frontSession may be nil
 	// in stateless mode.
 	connect(ctx context.Context, variant ServerVariant, frontSession *mcp.ServerSession) (*innerConnection, error)
 
-	// capabilities performs an ephemeral connect to discover the server's
-	// advertised capabilities, then tears down the probe connection.
-	capabilities(ctx context.Context) (*mcp.ServerCapabilities, error)
+	// probe performs an ephemeral connect to discover the server's
+	// initialize result (capabilities, instructions), then tears down the
+	// probe connection.
+	probe(ctx context.Context) (*mcp.InitializeResult, error)
+
+	// tools performs an ephemeral connect to list the server's tools, then
+	// tears down the connection.
+	tools(ctx context.Context) ([]*mcp.Tool, error)
+
+	// watch connects a long-lived client to the backing server, which
+	// calls changed when the server reports a tools, prompts or resources
+	// list change. stop closes the client.
+	watch(ctx context.Context, changed func()) (cs *mcp.ClientSession, stop func(), err error)
 
 	// close releases any resources held by the backend.
 	close() error
+
+	// kind reports the backend's kind.
+	kind() BackendKind
 }
 
 // inMemoryBackend connects to a co-located *mcp.Server via in-memory
@@ -35,16 +49,19 @@ type backend interface {
 // covers notifications (progress, log) and server-to-client requests
 // (Elicit, CreateMessage, ListRoots) during request handling.
 //
-// Limitation: async notifications (e.g. tool/resource/prompt
-// list-changed) triggered outside request handling use
-// context.Background() and lack the front session in the context, so
-// the middleware falls through and these notifications are dropped.
-// In practice this is acceptable because inner servers are typically
-// statically configured (tools registered at startup).
+// Async notifications triggered outside request handling lack the front
+// session in the context, so the middleware falls through and they reach
+// the proxy client of the inner connection. Resource updates for
+// subscriptions are forwarded from there to the front session (see
+// forwardResourceUpdated); other async notifications (e.g. tool/resource/
+// prompt list-changed) are dropped. In practice this is acceptable because
+// inner servers are typically statically configured (tools registered at
+// startup).
 type inMemoryBackend struct {
 	variantID        string
 	server           *mcp.Server
 	mcpMethodHandler mcp.MethodHandler
+	vs               *Server
 }
 
 // sessionSwappedRequest wraps an existing mcp.Request but returns a
@@ -68,14 +85,31 @@ func (r *sessionSwappedRequest) GetSession() mcp.Session { return r.session }
 func sendingRedirectMiddleware(variantID string, vs *Server) mcp.Middleware {
 	return func(next mcp.MethodHandler) mcp.MethodHandler {
 		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "notifications/prompts/list_changed" && vs.completions != nil {
+				vs.completions.invalidate(variantID)
+			}
 			frontSession, _ := ctx.Value(frontSessionKeyType{}).(*mcp.ServerSession)
+			if params, ok := req.GetParams().(*mcp.LoggingMessageParams); ok && params != nil {
+				forward, report := vs.admitLog(frontSession, variantID, params)
+				if !forward {
+					return nil, nil
+				}
+				if report != nil && vs.frontSendingHandler != nil {
+					_, _ = vs.frontSendingHandler(ctx, method, &mcp.ServerRequest[*mcp.LoggingMessageParams]{Session: frontSession, Params: report})
+				}
+			}
 			if frontSession == nil || vs.frontSendingHandler == nil {
 				return next(ctx, method, req)
 			}
-			return vs.frontSendingHandler(ctx, method, &sessionSwappedRequest{
-				Request: req,
-				session: frontSession,
-			})
+			swapped := &sessionSwappedRequest{Request: req, session: frontSession}
+			if params, ok := req.GetParams().(*mcp.ProgressNotificationParams); ok && params != nil && vs.progressThrottle != nil {
+				ctx := context.WithoutCancel(ctx)
+				vs.progressThrottle.forward(frontSession, params, func() {
+					_, _ = vs.frontSendingHandler(ctx, method, swapped)
+				})
+				return nil, nil
+			}
+			return vs.frontSendingHandler(ctx, method, swapped)
 		}
 	}
 }
@@ -89,6 +123,7 @@ func newInMemoryBackend(server *mcp.Server, variantID string, vs *Server) *inMem
 		variantID:        variantID,
 		server:           server,
 		mcpMethodHandler: captureReceivingMethodHandler(server),
+		vs:               vs,
 	}
 }
 
@@ -136,13 +171,19 @@ func (b *inMemoryBackend) connect(ctx context.Context, variant ServerVariant, fr
 	nopSampling := func(context.Context, *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
 		return nil, nil
 	}
+	opts := &mcp.ClientOptions{
+		ElicitationHandler:   nopElicit,
+		CreateMessageHandler: nopSampling,
+	}
+	if frontSession != nil {
+		opts.ResourceUpdatedHandler = func(ctx context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			b.vs.forwardResourceUpdated(ctx, frontSession, b.variantID, req.Params)
+		}
+	}
 	client := mcp.NewClient(&mcp.Implementation{
 		Name:    "variant-proxy-client",
 		Version: "1.0.0",
-	}, &mcp.ClientOptions{
-		ElicitationHandler:   nopElicit,
-		CreateMessageHandler: nopSampling,
-	})
+	}, opts)
 
 	clientSession, err := client.Connect(ctx, clientSideTransport, nil)
 	if err != nil {
@@ -150,13 +191,14 @@ func (b *inMemoryBackend) connect(ctx context.Context, variant ServerVariant, fr
 		return nil, err
 	}
 
-	// Set the inner session's log level to the lowest so that
-	// ServerSession.Log() does not short-circuit before reaching the
-	// sending middleware. The actual log-level filtering is performed by
-	// the front-facing session when the middleware redirects the
-	// notification. Errors are ignored: if the inner server does not
-	// advertise the Logging capability the call simply fails harmlessly.
-	_ = clientSession.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "debug"})
+	// Set the inner session's log level to the lowest forwarded (see
+	// WithVariantLogLevel) so that ServerSession.Log() does not
+	// short-circuit before reaching the sending middleware. The actual
+	// log-level filtering is performed by the front-facing session when the
+	// middleware redirects the notification. Errors are ignored: if the
+	// inner server does not advertise the Logging capability the call
+	// simply fails harmlessly.
+	_ = clientSession.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: b.vs.backendLogLevel(b.variantID)})
 
 	return &innerConnection{
 		backendSession: &backendSession{
@@ -171,33 +213,103 @@ func (b *inMemoryBackend) connect(ctx context.Context, variant ServerVariant, fr
 	}, nil
 }
 
-// capabilities performs an ephemeral in-memory connect to discover the
-// server's advertised capabilities.
-func (b *inMemoryBackend) capabilities(ctx context.Context) (*mcp.ServerCapabilities, error) {
+// forwardResourceUpdated sends a resource update of the variant to the
+// front session, which subscribed to the resource through the router. The
+// variant ID is set in _meta, since resource URIs are variant-scoped, and
+// the URI is namespaced if enabled (see WithResourceNamespacing).
+// Delivery failures are not reported, as for other notifications.
+func (s *Server) forwardResourceUpdated(ctx context.Context, frontSession *mcp.ServerSession, variantID string, params *mcp.ResourceUpdatedNotificationParams) {
+	if params == nil || s.frontSendingHandler == nil {
+		return
+	}
+	p := *params
+	p.Meta = maps.Clone(params.Meta)
+	injectVariantMeta(&p, variantID)
+	if s.namespaceResources {
+		p.URI = namespaceURI(variantID, p.URI)
+	}
+	_, _ = s.frontSendingHandler(ctx, "notifications/resources/updated", &mcp.ServerRequest[*mcp.ResourceUpdatedNotificationParams]{
+		Session: frontSession,
+		Params:  &p,
+	})
+}
+
+// probe performs an ephemeral in-memory connect to discover the server's
+// initialize result.
+func (b *inMemoryBackend) probe(ctx context.Context) (*mcp.InitializeResult, error) {
+	var res *mcp.InitializeResult
+	err := b.withProbeSession(ctx, func(cs *mcp.ClientSession) error {
+		res = cs.InitializeResult()
+		return nil
+	})
+	return res, err
+}
+
+// tools performs an ephemeral in-memory connect to list the server's tools.
+func (b *inMemoryBackend) tools(ctx context.Context) ([]*mcp.Tool, error) {
+	var tools []*mcp.Tool
+	err := b.withProbeSession(ctx, func(cs *mcp.ClientSession) error {
+		for tool, err := range cs.Tools(ctx, nil) {
+			if err != nil {
+				return err
+			}
+			tools = append(tools, tool)
+		}
+		return nil
+	})
+	return tools, err
+}
+
+// withProbeSession connects a throwaway client to the inner server over
+// in-memory transports, calls fn with it, and tears the connection down.
+func (b *inMemoryBackend) withProbeSession(ctx context.Context, fn func(cs *mcp.ClientSession) error) error {
 	st, ct := mcp.NewInMemoryTransports()
 	ss, err := b.server.Connect(ctx, st, nil)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer ss.Close()
 
 	c := mcp.NewClient(&mcp.Implementation{Name: "cap-probe", Version: "1.0.0"}, nil)
 	cs, err := c.Connect(ctx, ct, nil)
 	if err != nil {
-		ss.Close()
-		return nil, err
+		return err
 	}
+	defer cs.Close()
+	return fn(cs)
+}
 
-	var caps *mcp.ServerCapabilities
-	if ir := cs.InitializeResult(); ir != nil {
-		caps = ir.Capabilities
+// watch connects a client to the inner server over in-memory transports.
+// The server's list-changed notifications are sent outside request
+// handling, so the sending redirect middleware passes them through to the
+// client.
+func (b *inMemoryBackend) watch(ctx context.Context, changed func()) (*mcp.ClientSession, func(), error) {
+	st, ct := mcp.NewInMemoryTransports()
+	ss, err := b.server.Connect(ctx, st, nil)
+	if err != nil {
+		return nil, nil, err
 	}
-
-	cs.Close()
-	ss.Close()
-	return caps, nil
+	c := mcp.NewClient(&mcp.Implementation{Name: "catalog-watcher", Version: "1.0.0"}, &mcp.ClientOptions{
+		ToolListChangedHandler:     func(context.Context, *mcp.ToolListChangedRequest) { changed() },
+		PromptListChangedHandler:   func(context.Context, *mcp.PromptListChangedRequest) { changed() },
+		ResourceListChangedHandler: func(context.Context, *mcp.ResourceListChangedRequest) { changed() },
+	})
+	cs, err := c.Connect(ctx, ct, nil)
+	if err != nil {
+		ss.Close()
+		return nil, nil, err
+	}
+	return cs, func() {
+		cs.Close()
+		ss.Close()
+	}, nil
 }
 
 // close is a no-op for in-memory backends.
 func (b *inMemoryBackend) close() error {
 	return nil
 }
+
+func (b *inMemoryBackend) kind() BackendKind {
+	return BackendInMemory
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// BackendTLS configures the TLS connections to a remote variant's server,
+// typically for a service mesh requiring mutual TLS. See WithBackendTLS.
+type BackendTLS struct {
+	// CAFile is a PEM bundle of the certificate authorities trusted to
+	// sign the server's certificate. Empty means the system roots.
+	CAFile string
+
+	// CertFile and KeyFile are the PEM certificate and private key the
+	// front server presents to the variant's server, for mutual TLS. Both
+	// or neither must be set.
+	CertFile string
+	KeyFile  string
+
+	// ServerName is the name sent with SNI and checked against the
+	// server's certificate. Empty means the host of the variant's
+	// endpoint.
+	ServerName string
+
+	// MinVersion is the minimum TLS version, e.g. tls.VersionTLS13. Zero
+	// means tls.VersionTLS12.
+	MinVersion uint16
+}
+
+// WithBackendTLS configures the TLS connections to the server of a remote
+// variant (see WithRemoteVariant), whose endpoint must then be an https
+// URL: the CA bundle verifying the server, the client certificate for
+// mutual TLS, the SNI server name and the minimum TLS version. Files are
+// read when serving starts, or when the variant is first connected to
+// (e.g. by Manifest); certificates rotated later are not picked up.
+// Variants without a configuration use Go's defaults.
+//
+// The variant must be registered by the time serving starts. It panics if
+// only one of CertFile and KeyFile is set, or if MinVersion is not a TLS
+// version.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithBackendTLS(variantID string, cfg BackendTLS) *Server {
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		panic("variants: backend TLS for variant " + variantID + " needs both CertFile and KeyFile")
+	}
+	switch cfg.MinVersion {
+	case 0:
+		cfg.MinVersion = tls.VersionTLS12
+	case tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13:
+	default:
+		panic(fmt.Sprintf("variants: invalid TLS version %#x for variant %s", cfg.MinVersion, variantID))
+	}
+	if s.backendTLS == nil {
+		s.backendTLS = make(map[string]BackendTLS)
+	}
+	s.backendTLS[variantID] = cfg
+	return s
+}
+
+// validateBackendTLS checks that backend TLS configurations name remote
+// variants with https endpoints, and that their files load.
+func (s *Server) validateBackendTLS() error {
+	for _, id := range sortedKeys(s.backendTLS) {
+		i, ok := s.variantIndex[id]
+		if !ok {
+			return fmt.Errorf("variants: backend TLS for unregistered variant %q", id)
+		}
+		b, ok := s.variants[i].backend.(*remoteBackend)
+		if !ok {
+			return fmt.Errorf("variants: backend TLS for variant %q, which is not remote", id)
+		}
+		if u, _ := url.Parse(b.endpoint); u.Scheme != "https" {
+			return fmt.Errorf("variants: backend TLS for variant %q, whose endpoint is not https", id)
+		}
+		if _, err := b.client(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// client returns the HTTP client for the variant's server, configured with
+// the variant's BackendTLS, if any. It is built on first use.
+func (b *remoteBackend) client() (*http.Client, error) {
+	b.clientOnce.Do(func() {
+		cfg, ok := b.vs.backendTLS[b.variantID]
+		if !ok {
+			b.tlsClient = b.httpClient
+			return
+		}
+		conf, err := cfg.config()
+		if err != nil {
+			b.tlsErr = fmt.Errorf("variants: backend TLS for variant %q: %w", b.variantID, err)
+			return
+		}
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = conf
+		b.tlsClient = &http.Client{Transport: t}
+	})
+	return b.tlsClient, b.tlsErr
+}
+
+// config loads the certificates of c into a tls.Config.
+func (c BackendTLS) config() (*tls.Config, error) {
+	conf := &tls.Config{ServerName: c.ServerName, MinVersion: c.MinVersion}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.RootCAs = x509.NewCertPool()
+		if !conf.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates in " + c.CAFile)
+		}
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf, nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCert is a certificate and its key, in PEM files.
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// newTestCert issues a certificate for tmpl, signed by parent, or
+// self-signed if parent is nil, and writes it to dir.
+func newTestCert(t *testing.T, dir, name string, tmpl *x509.Certificate, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl.SerialNumber = big.NewInt(time.Now().UnixNano())
+	tmpl.Subject = pkix.Name{CommonName: name}
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	c := &testCert{cert: cert, key: key, certFile: filepath.Join(dir, name+".pem"), keyFile: filepath.Join(dir, name+"-key.pem")}
+	require.NoError(t, os.WriteFile(c.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(c.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return c
+}
+
+func TestWithBackendTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, dir, "ca", &x509.Certificate{IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign}, nil)
+	serverCert := newTestCert(t, dir, "server", &x509.Certificate{
+		DNSNames:    []string{"coding.mesh.internal"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	clientCert := newTestCert(t, dir, "client", &x509.Certificate{ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}, ca)
+
+	coding, _ := newTestServers()
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return coding }, nil)
+	var mu sync.Mutex
+	var clientNames []string
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		clientNames = append(clientNames, r.TLS.PeerCertificates[0].Subject.CommonName)
+		mu.Unlock()
+		handler.ServeHTTP(w, r)
+	}))
+	pair, err := tls.LoadX509KeyPair(serverCert.certFile, serverCert.keyFile)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	backend.TLS = &tls.Config{Certificates: []tls.Certificate{pair}, ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	backend.StartTLS()
+	t.Cleanup(backend.Close)
+
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithRemoteVariant(ServerVariant{ID: "coding", Description: "Remote coding"}, backend.URL, 0).
+		WithBackendTLS("coding", BackendTLS{
+			CAFile:     ca.certFile,
+			CertFile:   clientCert.certFile,
+			KeyFile:    clientCert.keyFile,
+			ServerName: "coding.mesh.internal",
+			MinVersion: tls.VersionTLS13,
+		})
+	session := connectTestClient(t, vs, nil)
+	_, err = session.CallTool(context.Background(), &mcp.CallToolParams{Name: "analyze_code", Arguments: map[string]any{"code": "x", "language": "go"}})
+	require.NoError(t, err)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, clientNames, "client")
+}
+
+func TestWithBackendTLS_Invalid(t *testing.T) {
+	assert.Panics(t, func() { newTestVariantServer().WithBackendTLS("coding", BackendTLS{CertFile: "cert.pem"}) })
+	assert.Panics(t, func() { newTestVariantServer().WithBackendTLS("coding", BackendTLS{MinVersion: 1}) })
+	assert.Equal(t, uint16(tls.VersionTLS12), newTestVariantServer().WithBackendTLS("coding", BackendTLS{}).backendTLS["coding"].MinVersion)
+
+	newRemote := func(endpoint string) *Server {
+		return NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+			WithRemoteVariant(ServerVariant{ID: "coding", Description: "Remote coding"}, endpoint, 0)
+	}
+	for name, tc := range map[string]struct {
+		vs      *Server
+		wantErr string
+	}{
+		"unregistered": {newRemote("https://coding.example").WithBackendTLS("other", BackendTLS{}), "unregistered variant"},
+		"in-memory":    {newTestVariantServer().WithBackendTLS("coding", BackendTLS{}), "not remote"},
+		"plain http":   {newRemote("http://coding.example").WithBackendTLS("coding", BackendTLS{}), "not https"},
+		"missing CA":   {newRemote("https://coding.example").WithBackendTLS("coding", BackendTLS{CAFile: filepath.Join(t.TempDir(), "ca.pem")}), "no such file"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := tc.vs.NewRouter(nil)
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
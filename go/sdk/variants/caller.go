@@ -0,0 +1,110 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Caller describes who sent a client request over HTTP, limited to the
+// headers and token claims selected with WithCallerContext. Retrieve it
+// with CallerFromContext.
+type Caller struct {
+	// Header holds the selected headers of the HTTP request, if present.
+	Header http.Header
+
+	// UserID identifies the authenticated user, if the HTTP handler is
+	// wrapped with bearer token authentication whose verifier sets it.
+	UserID string
+
+	// Scopes are the scopes of the client's token, if authenticated.
+	Scopes []string
+
+	// Claims holds the selected entries of the token's TokenInfo.Extra,
+	// if present.
+	Claims map[string]any
+}
+
+// CallerOptions selects what the Caller of a request exposes. See
+// WithCallerContext.
+type CallerOptions struct {
+	// Headers are the names of the HTTP headers to expose, e.g.
+	// "X-Tenant-ID". Other headers are left out.
+	Headers []string
+
+	// Claims are the keys of the TokenInfo.Extra entries to expose, e.g.
+	// "tenant" or "groups". Other entries are left out.
+	Claims []string
+}
+
+// callerKey is the context key for the *Caller of a request.
+type callerKey struct{}
+
+// CallerFromContext returns the Caller of the request being handled, if
+// enabled with WithCallerContext and the request came over HTTP.
+func CallerFromContext(ctx context.Context) (*Caller, bool) {
+	c, ok := ctx.Value(callerKey{}).(*Caller)
+	return c, ok
+}
+
+// WithCallerContext exposes who sent each client request in its context,
+// for policies keyed on real identity: the selected HTTP headers, and the
+// user, scopes and selected claims of the token verified by the HTTP
+// handler's bearer token authentication (see auth.RequireBearerToken).
+// Retrieve it with CallerFromContext in ranking functions, flag providers,
+// initialize hooks, dispatch interceptors, event handlers and the handlers
+// of in-memory variants. Only what opts selects is exposed, so that
+// credentials such as the Authorization header do not reach variants'
+// code unless asked for. The context of remote variants' handlers does not
+// cross the network; see WithTokenExchange to authorize their requests.
+// Requests over other transports carry no Caller. Disabled by default.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithCallerContext(opts CallerOptions) *Server {
+	opts.Headers = slices.Clone(opts.Headers)
+	for i, h := range opts.Headers {
+		opts.Headers[i] = http.CanonicalHeaderKey(h)
+	}
+	opts.Claims = slices.Clone(opts.Claims)
+	s.callerOpts = &opts
+	return s
+}
+
+// withCaller attaches the Caller of req to ctx, if enabled.
+func (s *Server) withCaller(ctx context.Context, req mcp.Request) context.Context {
+	if s.callerOpts == nil {
+		return ctx
+	}
+	extra := req.GetExtra()
+	if extra == nil || (extra.Header == nil && extra.TokenInfo == nil) {
+		return ctx
+	}
+	c := &Caller{}
+	for _, h := range s.callerOpts.Headers {
+		if vs := extra.Header.Values(h); len(vs) > 0 {
+			if c.Header == nil {
+				c.Header = make(http.Header)
+			}
+			c.Header[h] = slices.Clone(vs)
+		}
+	}
+	if info := extra.TokenInfo; info != nil {
+		c.UserID = info.UserID
+		c.Scopes = slices.Clone(info.Scopes)
+		for _, k := range s.callerOpts.Claims {
+			if v, ok := info.Extra[k]; ok {
+				if c.Claims == nil {
+					c.Claims = make(map[string]any)
+				}
+				c.Claims[k] = v
+			}
+		}
+	}
+	return context.WithValue(ctx, callerKey{}, c)
+}
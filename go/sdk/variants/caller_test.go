@@ -0,0 +1,103 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTripperFunc adapts a function to http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestWithCallerContext(t *testing.T) {
+	srv := mcp.NewServer(&mcp.Implementation{Name: "identity", Version: "v1.0.0"}, nil)
+	var mu sync.Mutex
+	callers := make(map[string]*Caller) // where -> caller seen
+	record := func(ctx context.Context, where string) {
+		c, _ := CallerFromContext(ctx)
+		mu.Lock()
+		callers[where] = c
+		mu.Unlock()
+	}
+	mcp.AddTool(srv, &mcp.Tool{Name: "whoami"}, func(ctx context.Context, _ *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		record(ctx, "handler")
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "identity", Description: "Identity"}, srv, 0).
+		WithRanking(func(ctx context.Context, _ VariantHints, vs []ServerVariant) []ServerVariant {
+			record(ctx, "ranking")
+			return vs
+		}).
+		WithDispatchInterceptor(func(ctx context.Context, _ DispatchInfo, next DispatchHandler) (mcp.Result, error) {
+			record(ctx, "interceptor")
+			return next(ctx)
+		}).
+		WithCallerContext(CallerOptions{Headers: []string{"x-tenant-id"}, Claims: []string{"tenant"}})
+
+	verify := func(context.Context, string, *http.Request) (*auth.TokenInfo, error) {
+		return &auth.TokenInfo{
+			UserID:     "alice",
+			Scopes:     []string{"tools"},
+			Expiration: time.Now().Add(time.Hour),
+			Extra:      map[string]any{"tenant": "acme", "email": "alice@example.com"},
+		}, nil
+	}
+	httpSrv := httptest.NewServer(auth.RequireBearerToken(verify, nil)(NewStreamableHTTPHandler(vs, nil)))
+	t.Cleanup(httpSrv.Close)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{
+		Endpoint: httpSrv.URL,
+		HTTPClient: &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Set("Authorization", "Bearer secret")
+			req.Header.Set("X-Tenant-ID", "acme")
+			req.Header.Set("X-Other", "hidden")
+			return http.DefaultTransport.RoundTrip(req)
+		})},
+	}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() })
+	_, err = session.CallTool(context.Background(), &mcp.CallToolParams{Name: "whoami"})
+	require.NoError(t, err)
+
+	want := &Caller{
+		Header: http.Header{"X-Tenant-Id": {"acme"}},
+		UserID: "alice",
+		Scopes: []string{"tools"},
+		Claims: map[string]any{"tenant": "acme"},
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, where := range []string{"ranking", "interceptor", "handler"} {
+		assert.Equal(t, want, callers[where], where)
+	}
+}
+
+func TestWithCallerContext_Disabled(t *testing.T) {
+	req := &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{"X-Tenant-Id": {"acme"}}}}
+	_, ok := CallerFromContext(newTestVariantServer().withCaller(context.Background(), req))
+	assert.False(t, ok, "disabled")
+
+	vs := newTestVariantServer().WithCallerContext(CallerOptions{Headers: []string{"X-Tenant-ID"}})
+	_, ok = CallerFromContext(vs.withCaller(context.Background(), &mcp.CallToolRequest{}))
+	assert.False(t, ok, "not over HTTP")
+	c, ok := CallerFromContext(vs.withCaller(context.Background(), req))
+	require.True(t, ok)
+	assert.Equal(t, "acme", c.Header.Get("X-Tenant-ID"))
+	assert.Empty(t, c.UserID)
+}
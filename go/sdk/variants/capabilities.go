@@ -0,0 +1,193 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// WithCapabilityReporting enables or disables the "capabilities" field of
+// each entry of availableVariants, the capabilities the variant's own
+// server advertises (see VariantCapabilities). The front server advertises
+// the union of the variants' capabilities, so without it a client cannot
+// tell, e.g., that only one variant supports resource subscriptions.
+// Variant-aware clients can then gate features per variant. Disabled by
+// default.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithCapabilityReporting(enabled bool) *Server {
+	s.reportCapabilities = enabled
+	s.payloads.invalidate()
+	return s
+}
+
+// VariantCapabilities returns the capabilities the server of the variant
+// with the given ID advertised when it was probed, or nil if it has not
+// been probed: before serving starts, or while its backend is not ready
+// (see StartupPolicy).
+func (s *Server) VariantCapabilities(id string) *mcp.ServerCapabilities {
+	if m := s.capabilities.Load(); m != nil {
+		return (*m)[id]
+	}
+	return nil
+}
+
+// setVariantCapabilities records the capabilities a variant's server
+// advertised.
+func (s *Server) setVariantCapabilities(id string, caps *mcp.ServerCapabilities) {
+	if caps == nil {
+		caps = &mcp.ServerCapabilities{}
+	}
+	// Copy on write, so that the request path reads capabilities without
+	// locking.
+	s.mu.Lock()
+	var old map[string]*mcp.ServerCapabilities
+	if m := s.capabilities.Load(); m != nil {
+		old = *m
+	}
+	m := make(map[string]*mcp.ServerCapabilities, len(old)+1)
+	for k, v := range old {
+		m[k] = v
+	}
+	m[id] = caps
+	s.capabilities.Store(&m)
+	s.mu.Unlock()
+	s.payloads.invalidate()
+}
+
+// WithCapabilityCheck enables or disables checking that the selected
+// variant supports a request's method before forwarding it. The front
+// server advertises the union of the variants' capabilities, so a client
+// may, e.g., send resources/subscribe to a variant whose server lacks
+// subscriptions. When enabled, such a request fails with a method not
+// found error naming the variants that do support it, e.g.
+//
+//	capability "resources.subscribe" not supported by variant "coding"; supported by variants ["docs"]
+//
+// with the method, capability, activeVariant, supportedByVariants and, if
+// any variant supports the capability, the best-ranked of them as
+// suggestedVariant in the error data. Only variants in rotation and
+// enabled for the client are listed. Variants whose capabilities are not
+// known (see VariantCapabilities) are not checked. Disabled by default, in
+// which case such requests are left to the variant to reject.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithCapabilityCheck(enabled bool) *Server {
+	s.checkCapabilities = enabled
+	return s
+}
+
+// methodCapabilities maps the methods routed to variants to the name of
+// the capability they require and a function reporting whether a set of
+// capabilities includes it.
+var methodCapabilities = map[string]struct {
+	name     string
+	supports func(*mcp.ServerCapabilities) bool
+}{
+	"tools/list":               {"tools", supportsTools},
+	"tools/call":               {"tools", supportsTools},
+	"prompts/list":             {"prompts", supportsPrompts},
+	"prompts/get":              {"prompts", supportsPrompts},
+	"resources/list":           {"resources", supportsResources},
+	"resources/read":           {"resources", supportsResources},
+	"resources/templates/list": {"resources", supportsResources},
+	"resources/subscribe":      {"resources.subscribe", supportsSubscriptions},
+	"resources/unsubscribe":    {"resources.subscribe", supportsSubscriptions},
+	"completion/complete":      {"completions", func(c *mcp.ServerCapabilities) bool { return c.Completions != nil }},
+}
+
+func supportsTools(c *mcp.ServerCapabilities) bool     { return c.Tools != nil }
+func supportsPrompts(c *mcp.ServerCapabilities) bool   { return c.Prompts != nil }
+func supportsResources(c *mcp.ServerCapabilities) bool { return c.Resources != nil }
+func supportsSubscriptions(c *mcp.ServerCapabilities) bool {
+	return c.Resources != nil && c.Resources.Subscribe
+}
+
+// checkCapability fails, under WithCapabilityCheck, if the variant is known
+// not to support the capability method requires.
+func (d *dispatcher) checkCapability(ctx context.Context, method string, req mcp.Request, variantID string) error {
+	if !d.server.checkCapabilities {
+		return nil
+	}
+	capability, ok := methodCapabilities[method]
+	if !ok {
+		return nil
+	}
+	if caps := d.server.VariantCapabilities(variantID); caps == nil || capability.supports(caps) {
+		return nil
+	}
+
+	d.mu.RLock()
+	ranked := d.ranked
+	d.mu.RUnlock()
+	if ranked == nil {
+		ranked = d.server.RankedVariants(ctx, VariantHints{})
+	}
+	fc := d.requestFlagContext(req)
+	supported := []string{}
+	for _, v := range ranked {
+		if v.ID == variantID || !d.server.isAvailable(v.ID) || !d.server.flagEnabled(ctx, v.ID, fc) {
+			continue
+		}
+		if caps := d.server.VariantCapabilities(v.ID); caps != nil && capability.supports(caps) {
+			supported = append(supported, v.ID)
+		}
+	}
+
+	msg := fmt.Sprintf("capability %q not supported by variant %q", capability.name, variantID)
+	data := map[string]any{
+		"method":              method,
+		"capability":          capability.name,
+		"activeVariant":       variantID,
+		"supportedByVariants": supported,
+	}
+	if len(supported) > 0 {
+		msg += fmt.Sprintf("; supported by variants %q", supported)
+		data["suggestedVariant"] = supported[0]
+	}
+	dataJSON, _ := json.Marshal(data)
+	return &jsonrpc.Error{
+		Code:    jsonrpc.CodeMethodNotFound,
+		Message: msg,
+		Data:    json.RawMessage(dataJSON),
+	}
+}
+
+// VariantCapabilities returns the capabilities of the advertised variant
+// with the given ID, as reported by the server, or nil if the server does
+// not report them (see Server.WithCapabilityReporting).
+func (s *ClientSession) VariantCapabilities(id string) *mcp.ServerCapabilities {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.variantCapabilitiesLocked(id)
+}
+
+// variantCapabilitiesLocked decodes the capabilities of a variant's entry.
+func (s *ClientSession) variantCapabilitiesLocked(id string) *mcp.ServerCapabilities {
+	for _, v := range s.payload.AvailableVariants {
+		if v.ID != id {
+			continue
+		}
+		raw, ok := v.Extra["capabilities"]
+		if !ok {
+			return nil
+		}
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil
+		}
+		var caps mcp.ServerCapabilities
+		if json.Unmarshal(data, &caps) != nil {
+			return nil
+		}
+		return &caps
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDocsVariantServer returns a server whose "docs" variant supports
+// resource subscriptions and whose "coding" variant has only tools.
+func newDocsVariantServer() *Server {
+	coding, _ := newTestServers()
+	return NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "coding", Description: "Coding"}, coding, 0).
+		WithVariant(ServerVariant{ID: "docs", Description: "Docs"}, newDocsServer("docs"), 1)
+}
+
+func TestCapabilityReporting(t *testing.T) {
+	vs := newDocsVariantServer().WithCapabilityReporting(true)
+	assert.Nil(t, vs.VariantCapabilities("docs"), "not probed before serving")
+	session := connectTestClient(t, vs, nil)
+
+	require.NotNil(t, vs.VariantCapabilities("docs"))
+	assert.True(t, vs.VariantCapabilities("docs").Resources.Subscribe)
+	assert.Nil(t, vs.VariantCapabilities("coding").Resources)
+
+	var p AvailableVariantsPayload
+	wire, err := json.Marshal(session.InitializeResult().Capabilities.Experimental[extensionID])
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(wire, &p))
+	require.Len(t, p.AvailableVariants, 2)
+	coding, docs := p.AvailableVariants[0].Extra["capabilities"], p.AvailableVariants[1].Extra["capabilities"]
+	assert.NotContains(t, coding, "resources")
+	assert.Equal(t, map[string]any{"subscribe": true, "listChanged": true}, docs.(map[string]any)["resources"])
+}
+
+func TestCapabilityReporting_Disabled(t *testing.T) {
+	session := connectTestClient(t, newDocsVariantServer(), nil)
+	wire, err := json.Marshal(session.InitializeResult().Capabilities.Experimental[extensionID])
+	require.NoError(t, err)
+	assert.NotContains(t, string(wire), `"capabilities"`)
+}
+
+func TestClient_VariantCapabilities(t *testing.T) {
+	session := connectVariantsClient(t, newDocsVariantServer().WithCapabilityReporting(true), nil)
+	ctx := context.Background()
+
+	require.NotNil(t, session.VariantCapabilities("docs"))
+	assert.True(t, session.VariantCapabilities("docs").Resources.Subscribe)
+	assert.Nil(t, session.VariantCapabilities("coding").Resources)
+	assert.Nil(t, session.VariantCapabilities("missing"))
+
+	// The coding variant has no resources, so none are listed although the
+	// server advertises them.
+	resources, err := session.Resources(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+	require.NoError(t, session.SelectVariant(ctx, "docs"))
+	resources, err = session.Resources(ctx)
+	require.NoError(t, err)
+	assert.Len(t, resources, 1)
+}
+
+func TestCapabilityCheck(t *testing.T) {
+	session := connectTestClient(t, newDocsVariantServer().WithCapabilityCheck(true), nil)
+	ctx := context.Background()
+
+	// The default coding variant lacks subscriptions and resources.
+	err := session.Subscribe(ctx, &mcp.SubscribeParams{URI: "docs://guide"})
+	var jErr *jsonrpc.Error
+	require.True(t, errors.As(err, &jErr))
+	assert.Equal(t, int64(jsonrpc.CodeMethodNotFound), jErr.Code)
+	assert.Equal(t, `capability "resources.subscribe" not supported by variant "coding"; supported by variants ["docs"]`, jErr.Message)
+	assert.JSONEq(t, `{"method":"resources/subscribe","capability":"resources.subscribe","activeVariant":"coding","supportedByVariants":["docs"],"suggestedVariant":"docs"}`, string(jErr.Data))
+
+	_, err = session.ListResources(ctx, nil)
+	require.True(t, errors.As(err, &jErr))
+	assert.Equal(t, `capability "resources" not supported by variant "coding"; supported by variants ["docs"]`, jErr.Message)
+
+	// Supported methods are forwarded.
+	require.NoError(t, session.Subscribe(ctx, &mcp.SubscribeParams{Meta: mcp.Meta{metaKeyVariant: "docs"}, URI: "docs://guide"}))
+	_, err = session.ListTools(ctx, nil)
+	require.NoError(t, err)
+
+	// Unsupported by all variants.
+	_, err = session.ListPrompts(ctx, &mcp.ListPromptsParams{Meta: mcp.Meta{metaKeyVariant: "docs"}})
+	require.True(t, errors.As(err, &jErr))
+	assert.Equal(t, `capability "prompts" not supported by variant "docs"`, jErr.Message)
+	assert.JSONEq(t, `{"method":"prompts/list","capability":"prompts","activeVariant":"docs","supportedByVariants":[]}`, string(jErr.Data))
+}
+
+func TestCapabilityCheck_Disabled(t *testing.T) {
+	session := connectTestClient(t, newDocsVariantServer(), nil)
+
+	// The request reaches the variant, which answers as its server does.
+	_, err := session.ListResources(context.Background(), nil)
+	require.NoError(t, err)
+}
@@ -0,0 +1,472 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// A CaptureRecord is one front-session message, as mirrored by
+// WithCapture: a request with its response, or a notification. Captures
+// are JSONL: one record per line.
+type CaptureRecord struct {
+	Time time.Time `json:"time"`
+
+	// Outgoing reports whether the server sent the message to the client:
+	// a notification, such as progress or a log message, or a
+	// server-to-client request, such as an elicitation, with the client's
+	// response. Otherwise the client sent it.
+	Outgoing bool `json:"outgoing,omitempty"`
+
+	// SessionID is the front session's ID. It is empty for transports
+	// without session IDs, such as stdio.
+	SessionID string `json:"sessionId,omitempty"`
+
+	// RequestID is the request's correlation ID (see Correlation).
+	RequestID string `json:"requestId,omitempty"`
+
+	// Variants are the variants the request was dispatched to, in order;
+	// more than one for fan-out and redirected calls, none for requests
+	// the front server answers itself, such as initialize.
+	Variants []string `json:"variants,omitempty"`
+
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+
+	// Result or Error is the response. Both are empty for notifications.
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *jsonrpc.Error  `json:"error,omitempty"`
+
+	DurationMs int64 `json:"durationMs"`
+}
+
+// CaptureOptions configures WithCapture.
+type CaptureOptions struct {
+	// Sanitize, if set, edits each record before it is written, e.g. to
+	// remove personal data from tool arguments. It runs after the default
+	// redaction of credentials.
+	Sanitize func(*CaptureRecord)
+
+	// OnError, if set, is called with the errors of writing records, e.g.
+	// when a RotatingFile fails to rotate.
+	OnError func(error)
+}
+
+// redacted replaces the values of sensitive fields in captures.
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are the names of fields whose values are redacted from
+// captures, lowercased and without "-" and "_", besides those ending in
+// sensitiveSuffixes.
+var sensitiveKeys = map[string]bool{
+	"authorization": true, "proxyauthorization": true,
+	"passwd": true, "passphrase": true,
+	"cookie": true, "setcookie": true,
+	"credential": true, "credentials": true, "privatekey": true,
+}
+
+// sensitiveSuffixes are the endings of field names, normalized like
+// sensitiveKeys, whose values are redacted from captures, e.g.
+// "github_token", "openai_api_key" or "X-Auth-Token".
+var sensitiveSuffixes = []string{"token", "apikey", "secret", "password"}
+
+// insensitiveKeys are the field names, normalized like sensitiveKeys, that
+// end in a sensitive suffix but hold no credential.
+var insensitiveKeys = map[string]bool{
+	"progresstoken": true, "maxtokens": true,
+}
+
+// WithCapture mirrors all front-session messages to w as JSONL
+// CaptureRecords, tagged with the session and the variants that served
+// them, so that bug reports can include reproducible transcripts: client
+// requests with their responses, client notifications, and the
+// notifications and requests the server sends to the client (progress,
+// log messages, elicitation, ...).
+// Use OpenRotatingFile for a capture file that does not grow without
+// bound, and ReadCapture to read captures back.
+//
+// Values of fields named after credentials (e.g. "authorization",
+// "password", or names ending in "token", "apiKey" or "secret", such as
+// "access_token" or "X-Auth-Token") are redacted from params, results and
+// error data, except "progressToken" and "maxTokens". Error messages are
+// kept as is; set CaptureOptions.Sanitize for further redaction. Records
+// are written synchronously; write errors are reported to
+// CaptureOptions.OnError.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithCapture(w io.Writer, opts *CaptureOptions) *Server {
+	if w == nil {
+		panic("variants: nil capture writer")
+	}
+	c := &capturer{w: w}
+	if opts != nil {
+		c.sanitize, c.onError = opts.Sanitize, opts.OnError
+	}
+	s.capture = c
+	return s
+}
+
+// capturer writes CaptureRecords.
+type capturer struct {
+	sanitize func(*CaptureRecord)
+	onError  func(error)
+
+	mu sync.Mutex // serializes writes
+	w  io.Writer
+}
+
+// captureVariantsKey is the context key for the *capturedVariants of a
+// captured front request.
+type captureVariantsKey struct{}
+
+// capturedVariants collects the variants a front request is dispatched to.
+type capturedVariants struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+// noteCapturedVariant records that the front request in ctx is being
+// dispatched to variantID, if it is being captured.
+func noteCapturedVariant(ctx context.Context, variantID string) {
+	if cv, ok := ctx.Value(captureVariantsKey{}).(*capturedVariants); ok {
+		cv.mu.Lock()
+		cv.ids = append(cv.ids, variantID)
+		cv.mu.Unlock()
+	}
+}
+
+// captureMiddleware mirrors the messages the client sends to the capture
+// writer.
+func (s *Server) captureMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if s.capture == nil {
+			return next(ctx, method, req)
+		}
+		cv := &capturedVariants{}
+		ctx = withRequestCorrelation(context.WithValue(ctx, captureVariantsKey{}, cv))
+		start := time.Now()
+		result, err := next(ctx, method, req)
+
+		rec := captureRecord(ctx, start, method, req, result, err)
+		cv.mu.Lock()
+		rec.Variants = slices.Clone(cv.ids)
+		cv.mu.Unlock()
+		s.capture.write(rec)
+		return result, err
+	}
+}
+
+// captureSendingMiddleware mirrors the messages the server sends to the
+// client to the capture writer.
+func (s *Server) captureSendingMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if s.capture == nil {
+			return next(ctx, method, req)
+		}
+		start := time.Now()
+		result, err := next(ctx, method, req)
+		rec := captureRecord(ctx, start, method, req, result, err)
+		rec.Outgoing = true
+		s.capture.write(rec)
+		return result, err
+	}
+}
+
+// captureRecord returns the record of a message sent at start. Responses
+// are recorded for requests only.
+func captureRecord(ctx context.Context, start time.Time, method string, req mcp.Request, result mcp.Result, err error) *CaptureRecord {
+	rec := &CaptureRecord{
+		Time:       start.UTC(),
+		SessionID:  sessionID(req),
+		Method:     method,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if c, ok := CorrelationFromContext(ctx); ok {
+		rec.RequestID = c.RequestID
+	}
+	if params := req.GetParams(); !isNilInterface(params) {
+		rec.Params = marshalRedacted(params)
+	}
+	if strings.HasPrefix(method, "notifications/") {
+		return rec
+	}
+	if err != nil {
+		var jErr *jsonrpc.Error
+		if errors.As(err, &jErr) {
+			// Copy the error, which is also the response.
+			e := *jErr
+			if len(e.Data) > 0 {
+				e.Data = marshalRedacted(e.Data)
+			}
+			jErr = &e
+		} else {
+			jErr = &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: err.Error()}
+		}
+		rec.Error = jErr
+	} else if !isNilInterface(result) {
+		rec.Result = marshalRedacted(result)
+	}
+	return rec
+}
+
+// write sanitizes and writes rec as a line.
+func (c *capturer) write(rec *CaptureRecord) {
+	if c.sanitize != nil {
+		c.sanitize(rec)
+	}
+	data, err := json.Marshal(rec)
+	if err == nil {
+		data = append(data, '\n')
+		c.mu.Lock()
+		_, err = c.w.Write(data)
+		c.mu.Unlock()
+	}
+	if err != nil && c.onError != nil {
+		c.onError(err)
+	}
+}
+
+// marshalRedacted returns the JSON encoding of v with sensitive fields
+// redacted, or nil if v cannot be encoded.
+func marshalRedacted(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil
+	}
+	data, err = json.Marshal(redact(generic))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// RedactJSON returns data with the values of sensitive fields redacted, as
+// WithCapture redacts them, e.g. to compare live responses with captured
+// ones. It returns data unchanged if it is not valid JSON.
+func RedactJSON(data json.RawMessage) json.RawMessage {
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return data
+	}
+	out, err := json.Marshal(redact(generic))
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+// redact replaces the values of sensitive fields in a decoded JSON value.
+func redact(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, x := range v {
+			if isSensitiveKey(k) {
+				v[k] = redacted
+			} else {
+				v[k] = redact(x)
+			}
+		}
+	case []any:
+		for i, x := range v {
+			v[i] = redact(x)
+		}
+	}
+	return v
+}
+
+// isSensitiveKey reports whether the value of a field named k is redacted
+// from captures.
+func isSensitiveKey(k string) bool {
+	k = strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(k))
+	if sensitiveKeys[k] {
+		return true
+	}
+	if insensitiveKeys[k] {
+		return false
+	}
+	return slices.ContainsFunc(sensitiveSuffixes, func(suffix string) bool { return strings.HasSuffix(k, suffix) })
+}
+
+// ReadCapture reads the CaptureRecords of a capture written by
+// WithCapture. Empty lines are skipped.
+func ReadCapture(r io.Reader) ([]CaptureRecord, error) {
+	var records []CaptureRecord
+	lines := bufio.NewScanner(r)
+	lines.Buffer(nil, 64<<20)
+	for n := 1; lines.Scan(); n++ {
+		line := lines.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		var rec CaptureRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return records, fmt.Errorf("variants: capture line %d: %w", n, err)
+		}
+		records = append(records, rec)
+	}
+	return records, lines.Err()
+}
+
+// ReadCaptureFiles reads the CaptureRecords of a capture file written by a
+// RotatingFile, oldest first: the rotated files path.N through path.1,
+// then path itself.
+func ReadCaptureFiles(path string) ([]CaptureRecord, error) {
+	var files []string
+	for i := 1; ; i++ {
+		name := rotatedName(path, i)
+		if _, err := os.Stat(name); err != nil {
+			break
+		}
+		files = append(files, name)
+	}
+	slices.Reverse(files)
+	files = append(files, path)
+
+	var records []CaptureRecord
+	for _, name := range files {
+		f, err := os.Open(name)
+		if errors.Is(err, fs.ErrNotExist) && name == path && len(records) > 0 {
+			break // rotated, but not yet written to again
+		}
+		if err != nil {
+			return nil, err
+		}
+		recs, err := ReadCapture(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		records = append(records, recs...)
+	}
+	return records, nil
+}
+
+// A RotatingFile is a capture file that is rotated when it would grow
+// beyond a size limit: path is renamed to path.1, path.1 to path.2, and so
+// on, keeping a limited number of rotated files. Writes are not split
+// across files, so each CaptureRecord stays whole.
+type RotatingFile struct {
+	path     string
+	maxBytes int64
+	maxFiles int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// OpenRotatingFile opens a capture file for appending, rotating it once it
+// would exceed maxBytes (default 10 MiB) and keeping maxFiles rotated
+// files (default 3). It panics if either is negative.
+func OpenRotatingFile(path string, maxBytes int64, maxFiles int) (*RotatingFile, error) {
+	if maxBytes < 0 || maxFiles < 0 {
+		panic("variants: negative rotating file limit")
+	}
+	if maxBytes == 0 {
+		maxBytes = 10 << 20
+	}
+	if maxFiles == 0 {
+		maxFiles = 3
+	}
+	rf := &RotatingFile{path: path, maxBytes: maxBytes, maxFiles: maxFiles}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+// Write writes p to the file, rotating it first if p would make it exceed
+// its size limit. If rotation fails, p is still written to the unrotated
+// file and the rotation error is returned; rotation is retried by the next
+// write.
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.f == nil {
+		return 0, fs.ErrClosed
+	}
+	var rotateErr error
+	if rf.size > 0 && rf.size+int64(len(p)) > rf.maxBytes {
+		if err := rf.rotate(); err != nil {
+			rotateErr = fmt.Errorf("variants: rotating %s: %w", rf.path, err)
+			if rf.f == nil {
+				return 0, rotateErr
+			}
+		}
+	}
+	n, err := rf.f.Write(p)
+	rf.size += int64(n)
+	if err == nil {
+		err = rotateErr
+	}
+	return n, err
+}
+
+// Close closes the file.
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	if rf.f == nil {
+		return nil
+	}
+	err := rf.f.Close()
+	rf.f = nil
+	return err
+}
+
+func (rf *RotatingFile) open() error {
+	f, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	rf.f, rf.size = f, info.Size()
+	return nil
+}
+
+// rotate shifts the rotated files, dropping the oldest, and starts a new
+// file. If the file cannot be renamed, it is reopened for appending. rf.mu
+// must be held; rf.f is nil after rotate if no file could be opened.
+func (rf *RotatingFile) rotate() error {
+	if err := rf.f.Close(); err != nil {
+		return err
+	}
+	rf.f = nil
+	_ = os.Remove(rotatedName(rf.path, rf.maxFiles))
+	for i := rf.maxFiles - 1; i >= 1; i-- {
+		_ = os.Rename(rotatedName(rf.path, i), rotatedName(rf.path, i+1))
+	}
+	renameErr := os.Rename(rf.path, rotatedName(rf.path, 1))
+	if err := rf.open(); err != nil {
+		return errors.Join(renameErr, err)
+	}
+	return renameErr
+}
+
+func rotatedName(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
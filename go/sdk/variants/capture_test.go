@@ -0,0 +1,219 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) records(t *testing.T) []CaptureRecord {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	records, err := ReadCapture(bytes.NewReader(b.buf.Bytes()))
+	require.NoError(t, err)
+	return records
+}
+
+func TestCapture(t *testing.T) {
+	var buf syncBuffer
+	vs := newTestVariantServer().WithCapture(&buf, &CaptureOptions{
+		Sanitize: func(rec *CaptureRecord) {
+			rec.Params = bytes.ReplaceAll(rec.Params, []byte("private"), []byte("xxx"))
+		},
+	})
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	_, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      mcp.Meta{metaKeyVariant: "compact", "apiKey": "sk-123"},
+		Name:      "summarize",
+		Arguments: map[string]any{"text": "private notes"},
+	})
+	require.NoError(t, err)
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Meta: mcp.Meta{metaKeyVariant: "nonexistent"},
+		Name: "summarize",
+	})
+	require.Error(t, err)
+
+	records := buf.records(t)
+	byMethod := make(map[string][]CaptureRecord)
+	for _, rec := range records {
+		byMethod[rec.Method] = append(byMethod[rec.Method], rec)
+	}
+	require.Len(t, byMethod["initialize"], 1)
+	assert.Empty(t, byMethod["initialize"][0].Variants)
+	require.Len(t, byMethod["notifications/initialized"], 1)
+	assert.False(t, byMethod["notifications/initialized"][0].Outgoing)
+	assert.Nil(t, byMethod["notifications/initialized"][0].Result)
+
+	calls := byMethod["tools/call"]
+	require.Len(t, calls, 2)
+	assert.Equal(t, []string{"compact"}, calls[0].Variants)
+	assert.NotEmpty(t, calls[0].RequestID)
+	assert.Contains(t, string(calls[0].Params), `"apiKey":"[REDACTED]"`)
+	assert.Contains(t, string(calls[0].Params), `"text":"xxx notes"`)
+	assert.NotContains(t, string(calls[0].Params), "sk-123")
+	assert.Contains(t, string(calls[0].Result), `"summary"`)
+
+	require.NotNil(t, calls[1].Error)
+	assert.Equal(t, "Invalid server variant", calls[1].Error.Message)
+	assert.Nil(t, calls[1].Result)
+}
+
+func TestCapture_Outgoing(t *testing.T) {
+	var buf syncBuffer
+	vs := newNotifyVariantServer().WithCapture(&buf, nil)
+	collector := &notificationCollector{}
+	session := connectTestClient(t, vs, collector.clientOptions())
+	ctx := context.Background()
+	require.NoError(t, session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "debug"}))
+
+	_, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      mcp.Meta{"progressToken": "tok"},
+		Name:      "notify",
+		Arguments: map[string]any{"client_id": "c", "count": notifyCount},
+	})
+	require.NoError(t, err)
+
+	var call CaptureRecord
+	outgoing := make(map[string]int)
+	for _, rec := range buf.records(t) {
+		if rec.Outgoing {
+			outgoing[rec.Method]++
+			assert.Nil(t, rec.Result)
+			assert.NotEmpty(t, rec.RequestID, "outgoing notifications are correlated with the request")
+		} else if rec.Method == "tools/call" {
+			call = rec
+		}
+	}
+	assert.Equal(t, map[string]int{"notifications/progress": notifyCount, "notifications/message": notifyCount}, outgoing)
+	assert.NotEmpty(t, call.Result)
+}
+
+func TestCapture_WriteError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	rf, err := OpenRotatingFile(path, 1, 1)
+	require.NoError(t, err)
+	t.Cleanup(func() { rf.Close() })
+	// A non-empty directory in the way of path.1 makes rotation fail.
+	require.NoError(t, os.MkdirAll(filepath.Join(path+".1", "x"), 0o700))
+
+	var errs []error
+	vs := newTestVariantServer().WithCapture(rf, &CaptureOptions{OnError: func(err error) { errs = append(errs, err) }})
+	handler := vs.captureMiddleware(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{}, nil
+	})
+	for range 2 {
+		_, err := handler(context.Background(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "t"}})
+		require.NoError(t, err)
+	}
+
+	require.Len(t, errs, 1, "the first write does not rotate")
+	assert.ErrorContains(t, errs[0], "rotating")
+	records, err := ReadCapture(mustOpen(t, path))
+	require.NoError(t, err)
+	assert.Len(t, records, 2, "capture continues in the unrotated file")
+}
+
+func mustOpen(t *testing.T, path string) *os.File {
+	t.Helper()
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestCapture_ErrorData(t *testing.T) {
+	var buf syncBuffer
+	vs := newTestVariantServer().WithCapture(&buf, nil)
+	data := json.RawMessage(`{"github_token":"ghp-123","reason":"bad"}`)
+	handler := vs.captureMiddleware(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: "rejected", Data: data}
+	})
+
+	_, err := handler(context.Background(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "t"}})
+	var jErr *jsonrpc.Error
+	require.True(t, errors.As(err, &jErr))
+	assert.JSONEq(t, string(data), string(jErr.Data), "the response is not redacted")
+
+	records := buf.records(t)
+	require.Len(t, records, 1)
+	require.NotNil(t, records[0].Error)
+	assert.Equal(t, "rejected", records[0].Error.Message)
+	assert.JSONEq(t, `{"github_token":"[REDACTED]","reason":"bad"}`, string(records[0].Error.Data))
+}
+
+func TestIsSensitiveKey(t *testing.T) {
+	for _, k := range []string{"authorization", "Authorization", "apiKey", "api_key", "X-API-Key", "password", "token", "access_token", "refreshToken", "client_secret", "Cookie",
+		"github_token", "openai_api_key", "x-auth-token", "db_password", "webhookSecret"} {
+		assert.True(t, isSensitiveKey(k), k)
+	}
+	for _, k := range []string{"progressToken", "maxTokens", "max_tokens", "tokenizer", "tokens", "secretary", "name"} {
+		assert.False(t, isSensitiveKey(k), k)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	rf, err := OpenRotatingFile(path, 200, 2)
+	require.NoError(t, err)
+
+	for i := range 10 {
+		rec := CaptureRecord{Method: "tools/call", Params: json.RawMessage(`{"name":"t` + strings.Repeat("x", i) + `"}`)}
+		data, err := json.Marshal(rec)
+		require.NoError(t, err)
+		_, err = rf.Write(append(data, '\n'))
+		require.NoError(t, err)
+	}
+	require.NoError(t, rf.Close())
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(200))
+	}
+	assert.NoFileExists(t, path+".3")
+
+	records, err := ReadCaptureFiles(path)
+	require.NoError(t, err)
+	require.NotEmpty(t, records)
+	assert.Less(t, len(records), 10, "oldest records are dropped")
+	assert.JSONEq(t, `{"name":"txxxxxxxxx"}`, string(records[len(records)-1].Params))
+	for i := 1; i < len(records); i++ {
+		assert.Less(t, len(records[i-1].Params), len(records[i].Params), "records are in order")
+	}
+}
+
+func TestReadCapture_Malformed(t *testing.T) {
+	_, err := ReadCapture(strings.NewReader("{\"method\":\"ping\"}\n\nnot json\n"))
+	assert.ErrorContains(t, err, "line 3")
+}
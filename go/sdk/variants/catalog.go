@@ -0,0 +1,79 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// rankForSession ranks the variants for a session's hints: ranking, then
+// feature flags, then consistent defaults. ctx should carry the session's
+// RankingRequest.
+func (s *Server) rankForSession(ctx context.Context, fc FlagContext, hints VariantHints) []ServerVariant {
+	return s.spreadTies(ctx, s.filterFlagged(ctx, fc, s.RankedVariants(ctx, hints)))
+}
+
+// rerankSession ranks the variants of a stateful session after initialize,
+// for updated hints or a changed catalog, and returns the ranking and the
+// variants the initialize hooks stripped from it. A variant restored from a
+// variant token stays first, and the hooks run again, so that variants they
+// strip or reorder stay that way; see InitializeExchange.Rerank.
+func (s *Server) rerankSession(ctx context.Context, ss *mcp.ServerSession, d *dispatcher, fc FlagContext, hints VariantHints) ([]ServerVariant, map[string]bool, error) {
+	ranked := restoreFirst(d.restored, s.rankForSession(ctx, fc, hints))
+	if len(s.initHooks) == 0 || d.initResult == nil {
+		return ranked, nil, nil
+	}
+	return s.applyInitHooks(ctx, &InitializeExchange{
+		Params:   ss.InitializeParams(),
+		Result:   copyInitializeResult(d.initResult),
+		Hints:    hints,
+		Variants: ranked,
+		Rerank:   true,
+	})
+}
+
+// notifyCatalogChanged re-ranks the variants of every stateful session
+// after the catalog changed at runtime (see SetVariantAvailability) and
+// sends variant-aware clients the new payload, as for hint updates (see
+// notifyVariantsChanged). Long-lived sessions thereby learn about variants
+// entering or leaving rotation without reconnecting. Sessions of clients
+// that did not declare the extension are re-ranked without notification;
+// their default already follows availability (see defaultVariant). Sessions
+// whose initialize hooks fail on re-ranking keep their ranking.
+func (s *Server) notifyCatalogChanged() {
+	s.rangeSessions(func(ss *mcp.ServerSession, d *dispatcher) bool {
+		ctx := d.rankingContext()
+		d.mu.RLock()
+		hints, fc := d.hints, d.flagCtx
+		d.mu.RUnlock()
+
+		ranked, stripped, err := s.rerankSession(ctx, ss, d, fc, hints)
+		if err != nil {
+			return true
+		}
+		defaultChanged := d.setRanking(ctx, fc, ranked, stripped)
+		if !supportsVariants(ss) {
+			return true
+		}
+		payload, err := s.variantsPayload(ctx, d, hints, NormalizedHints{}, ranked)
+		if err == nil {
+			s.notifyVariantsChanged(ctx, ss, d, payload, defaultChanged)
+		}
+		return true
+	})
+}
+
+// supportsVariants reports whether the front client declared the variants
+// extension in its capabilities during initialize.
+func supportsVariants(ss *mcp.ServerSession) bool {
+	params := ss.InitializeParams()
+	if params == nil || params.Capabilities == nil {
+		return false
+	}
+	_, ok := params.Capabilities.Experimental[extensionID]
+	return ok
+}
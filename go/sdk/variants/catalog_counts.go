@@ -0,0 +1,59 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+// CatalogCounts are the sizes of a variant's catalog.
+type CatalogCounts struct {
+	ToolCount     int `json:"toolCount"`
+	PromptCount   int `json:"promptCount"`
+	ResourceCount int `json:"resourceCount"` // resources, not counting templates
+}
+
+// WithCatalogCounts enables or disables per-variant catalog counts: the
+// number of tools, prompts and resources of each variant, listed as
+// toolCount, promptCount and resourceCount in its availableVariants entry
+// and in its Manifest entry. They give clients a cheap signal of a
+// variant's size before listing anything.
+//
+// Counts are taken from the catalog snapshots of the ownership index (see
+// WithOwnershipIndex), which WithCatalogCounts builds if it is not enabled,
+// and so follow the variants' list-changed notifications. Variants whose
+// catalogs are not indexed, e.g. because their backends could not be
+// connected, are listed without counts. Disabled by default.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithCatalogCounts(enabled bool) *Server {
+	s.catalogCounts = enabled
+	return s
+}
+
+// CatalogCounts returns the catalog counts of the variant with the given
+// ID, and reports whether they are known: catalog counts must be enabled
+// (see WithCatalogCounts) and the variant indexed.
+func (s *Server) CatalogCounts(variantID string) (CatalogCounts, bool) {
+	if !s.catalogCounts {
+		return CatalogCounts{}, false
+	}
+	return s.ownership.counts(variantID)
+}
+
+// counts returns the sizes of the variant's indexed catalog, and reports
+// whether it is indexed.
+func (x *ownershipIndex) counts(variantID string) (CatalogCounts, bool) {
+	if x == nil {
+		return CatalogCounts{}, false
+	}
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	c := x.catalogs[variantID]
+	if c == nil {
+		return CatalogCounts{}, false
+	}
+	return CatalogCounts{
+		ToolCount:     len(c.tools),
+		PromptCount:   len(c.prompts),
+		ResourceCount: len(c.resources),
+	}, true
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogCounts(t *testing.T) {
+	vs := newTestVariantServer().
+		WithVariant(ServerVariant{ID: "docs", Description: "Docs"}, newDocsServer("docs"), 2).
+		WithCatalogCounts(true)
+	session := connectTestClient(t, vs, nil)
+
+	counts, ok := vs.CatalogCounts("coding")
+	require.True(t, ok)
+	assert.Equal(t, CatalogCounts{ToolCount: 2}, counts)
+	counts, ok = vs.CatalogCounts("docs")
+	require.True(t, ok)
+	assert.Equal(t, CatalogCounts{ResourceCount: 1}, counts, "templates are not counted")
+	_, ok = vs.CatalogCounts("missing")
+	assert.False(t, ok)
+
+	wire, err := json.Marshal(session.InitializeResult().Capabilities.Experimental[extensionID])
+	require.NoError(t, err)
+	var p AvailableVariantsPayload
+	require.NoError(t, json.Unmarshal(wire, &p))
+	require.Len(t, p.AvailableVariants, 3)
+	assert.Equal(t, map[string]any{"toolCount": 2.0, "promptCount": 0.0, "resourceCount": 0.0}, p.AvailableVariants[0].Extra)
+	assert.Equal(t, map[string]any{"toolCount": 0.0, "promptCount": 0.0, "resourceCount": 1.0}, p.AvailableVariants[2].Extra)
+
+	m, err := vs.Manifest(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, m.Variants[0].CatalogCounts)
+	assert.Equal(t, 2, m.Variants[0].ToolCount)
+	data, err := json.Marshal(m.Variants[2])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"resourceCount":1`)
+}
+
+func TestCatalogCounts_Disabled(t *testing.T) {
+	vs := newTestVariantServer()
+	session := connectTestClient(t, vs, nil)
+
+	_, ok := vs.CatalogCounts("coding")
+	assert.False(t, ok)
+	wire, err := json.Marshal(session.InitializeResult().Capabilities.Experimental[extensionID])
+	require.NoError(t, err)
+	assert.NotContains(t, string(wire), "toolCount")
+}
+
+func TestCatalogCounts_ListChanged(t *testing.T) {
+	coding, compact := newTestServers()
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "coding", Description: "Coding"}, coding, 0).
+		WithVariant(ServerVariant{ID: "compact", Description: "Compact"}, compact, 1).
+		WithCatalogCounts(true)
+	_, err := vs.NewRouter(nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { vs.Close() })
+
+	mcp.AddTool(coding, &mcp.Tool{Name: "lookup", Description: "Coding lookup"}, lookup)
+	assert.Eventually(t, func() bool {
+		counts, _ := vs.CatalogCounts("coding")
+		return counts.ToolCount == 3
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCatalogChangeNotification verifies that variant-aware sessions are
+// sent their re-ranked variants when a variant leaves or returns to
+// rotation, and that unchanged availability sends nothing.
+func TestCatalogChangeNotification(t *testing.T) {
+	vs := newTestVariantServer()
+
+	updates := make(chan *mcp.ToolListChangedRequest, 4)
+	opts := hintsClientOptions(map[string]any{HintContextSize: "coding"})
+	opts.ToolListChangedHandler = func(_ context.Context, req *mcp.ToolListChangedRequest) {
+		updates <- req
+	}
+	connectTestClient(t, vs, opts)
+
+	next := func() (ids []string, defaultVariant string) {
+		t.Helper()
+		select {
+		case req := <-updates:
+			extJSON, err := json.Marshal(req.Params.Meta[extensionID])
+			require.NoError(t, err)
+			var p struct {
+				AvailableVariants []struct {
+					ID string `json:"id"`
+				} `json:"availableVariants"`
+				DefaultVariant string `json:"defaultVariant"`
+			}
+			require.NoError(t, json.Unmarshal(extJSON, &p))
+			for _, v := range p.AvailableVariants {
+				ids = append(ids, v.ID)
+			}
+			return ids, p.DefaultVariant
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for variants update notification")
+			return nil, ""
+		}
+	}
+
+	require.NoError(t, vs.SetVariantAvailability("coding", false, "maintenance"))
+	ids, def := next()
+	assert.Equal(t, []string{"compact"}, ids)
+	assert.Equal(t, "compact", def)
+
+	// Same availability and reason: no notification.
+	require.NoError(t, vs.SetVariantAvailability("coding", false, "maintenance"))
+
+	require.NoError(t, vs.SetVariantAvailability("coding", true, ""))
+	ids, def = next()
+	assert.Equal(t, []string{"coding", "compact"}, ids)
+	assert.Equal(t, "coding", def)
+
+	select {
+	case <-updates:
+		t.Fatal("unexpected variants update notification")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestCatalogChangeNotification_UnawareClient verifies that clients that
+// did not declare the extension are not sent variants payloads.
+func TestCatalogChangeNotification_UnawareClient(t *testing.T) {
+	vs := newTestVariantServer()
+
+	updates := make(chan *mcp.ToolListChangedRequest, 1)
+	connectTestClient(t, vs, &mcp.ClientOptions{
+		ToolListChangedHandler: func(_ context.Context, req *mcp.ToolListChangedRequest) {
+			updates <- req
+		},
+	})
+
+	require.NoError(t, vs.SetVariantAvailability("coding", false, "maintenance"))
+	select {
+	case <-updates:
+		t.Fatal("unexpected variants update notification")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
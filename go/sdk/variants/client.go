@@ -0,0 +1,460 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ClientOptions configures a [Client].
+type ClientOptions struct {
+	// MCP configures the underlying mcp.Client. The variants extension is
+	// added to its experimental capabilities, and its list-changed handlers
+	// are called after the Client has processed the notifications.
+	MCP *mcp.ClientOptions
+
+	// Hints are sent to the server at initialize to rank its variants.
+	Hints VariantHints
+
+	// Refetch re-lists the tools, prompts and resources of a newly active
+	// variant as soon as it changes, instead of on their next use, and
+	// passes them to OnVariantChanged.
+	Refetch bool
+
+	// Picker, if non-nil, chooses the variant to select when a session
+	// connects, e.g. PreferStable(). Otherwise the server's default is
+	// used.
+	Picker VariantPicker
+
+	// OnVariantChanged, if non-nil, is called after the active variant of
+	// a session changed, e.g. to refresh the tool set handed to a model.
+	// It is called from SelectVariant, or on its own goroutine when the
+	// server changed the session's default variant.
+	OnVariantChanged func(ctx context.Context, cs *ClientSession, change VariantChange)
+}
+
+// VariantChange describes a change of a session's active variant.
+type VariantChange struct {
+	// Previous and Current are the IDs of the variants active before and
+	// after the change.
+	Previous string
+	Current  string
+
+	// Catalog is the current variant's catalog if ClientOptions.Refetch is
+	// set and it was listed successfully; Err holds the listing failure.
+	Catalog *Catalog
+	Err     error
+}
+
+// Catalog is what a variant offers to a client.
+type Catalog struct {
+	Tools     []*mcp.Tool
+	Prompts   []*mcp.Prompt
+	Resources []*mcp.Resource
+}
+
+// Client is an MCP client for variant servers. It declares the variants
+// extension with the configured hints at initialize, keeps track of the
+// variants the server advertises, and caches the active variant's catalog.
+type Client struct {
+	client   *mcp.Client
+	opts     ClientOptions
+	sessions sync.Map // *mcp.ClientSession -> *ClientSession
+}
+
+// NewClient returns a Client with the given implementation and options,
+// which may be nil.
+func NewClient(impl *mcp.Implementation, opts *ClientOptions) *Client {
+	c := &Client{}
+	if opts != nil {
+		c.opts = *opts
+	}
+	var mcpOpts mcp.ClientOptions
+	if c.opts.MCP != nil {
+		mcpOpts = *c.opts.MCP
+	}
+
+	// Without Capabilities, the SDK advertises roots with listChanged.
+	caps := mcp.ClientCapabilities{RootsV2: &mcp.RootCapabilities{ListChanged: true}}
+	if mcpOpts.Capabilities != nil {
+		caps = *mcpOpts.Capabilities
+	}
+	caps.Experimental = maps.Clone(caps.Experimental)
+	if caps.Experimental == nil {
+		caps.Experimental = make(map[string]any)
+	}
+	ext := ClientHintsPayload{}
+	if c.opts.Hints.Description != "" || len(c.opts.Hints.Hints) > 0 {
+		hints := c.opts.Hints
+		ext.VariantHints = &hints
+	}
+	caps.Experimental[extensionID] = ext
+	mcpOpts.Capabilities = &caps
+
+	tools, prompts, resources := mcpOpts.ToolListChangedHandler, mcpOpts.PromptListChangedHandler, mcpOpts.ResourceListChangedHandler
+	mcpOpts.ToolListChangedHandler = func(ctx context.Context, req *mcp.ToolListChangedRequest) {
+		if s := c.session(req.Session); s != nil {
+			var payload any
+			if req.Params != nil {
+				payload = req.Params.Meta[extensionID]
+			}
+			s.listChanged(catalogTools, payload)
+		}
+		if tools != nil {
+			tools(ctx, req)
+		}
+	}
+	mcpOpts.PromptListChangedHandler = func(ctx context.Context, req *mcp.PromptListChangedRequest) {
+		if s := c.session(req.Session); s != nil {
+			s.listChanged(catalogPrompts, nil)
+		}
+		if prompts != nil {
+			prompts(ctx, req)
+		}
+	}
+	mcpOpts.ResourceListChangedHandler = func(ctx context.Context, req *mcp.ResourceListChangedRequest) {
+		if s := c.session(req.Session); s != nil {
+			s.listChanged(catalogResources, nil)
+		}
+		if resources != nil {
+			resources(ctx, req)
+		}
+	}
+
+	c.client = mcp.NewClient(impl, &mcpOpts)
+	c.client.AddSendingMiddleware(c.selectionMiddleware)
+	return c
+}
+
+// Connect connects to a variant server over t and initializes the session,
+// selecting the variant chosen by ClientOptions.Picker, if set. opts may be
+// nil.
+func (c *Client) Connect(ctx context.Context, t mcp.Transport, opts *mcp.ClientSessionOptions) (*ClientSession, error) {
+	cs, err := c.client.Connect(ctx, t, opts)
+	if err != nil {
+		return nil, err
+	}
+	s := &ClientSession{ClientSession: cs, client: c}
+	if res := cs.InitializeResult(); res != nil && res.Capabilities != nil {
+		s.payload, _ = parseClientPayload(res.Capabilities.Experimental[extensionID])
+	}
+	s.active = s.payload.DefaultVariant
+	c.sessions.Store(cs, s)
+	if c.opts.Picker != nil {
+		if err := s.Pick(ctx, c.opts.Picker); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// session returns the ClientSession of an underlying session, or nil.
+func (c *Client) session(cs *mcp.ClientSession) *ClientSession {
+	if cs == nil {
+		return nil
+	}
+	v, _ := c.sessions.Load(cs)
+	s, _ := v.(*ClientSession)
+	return s
+}
+
+// selectionMiddleware selects the session's selected variant in the _meta
+// of requests that don't select one themselves.
+func (c *Client) selectionMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if !selectableMethod(method) {
+			return next(ctx, method, req)
+		}
+		cs, _ := req.GetSession().(*mcp.ClientSession)
+		s := c.session(cs)
+		if s == nil {
+			return next(ctx, method, req)
+		}
+		s.mu.Lock()
+		selected := s.selected
+		s.mu.Unlock()
+		if selected == "" {
+			return next(ctx, method, req)
+		}
+		// Requests sent without params, e.g. ListTools(ctx, nil), get
+		// params to carry the selection.
+		params := ensureParams(req)
+		if isNilInterface(params) {
+			if params = emptyListParams(method); params == nil {
+				return next(ctx, method, req)
+			}
+			req = &mcp.ClientRequest[mcp.Params]{Session: cs, Params: params}
+		}
+		if _, ok := params.GetMeta()[metaKeyVariant]; !ok {
+			meta := maps.Clone(params.GetMeta())
+			if meta == nil {
+				meta = make(map[string]any)
+			}
+			meta[metaKeyVariant] = selected
+			params.SetMeta(meta)
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// emptyListParams returns empty params for the list methods, whose params
+// are optional, or nil for other methods. The SDK sends such requests
+// without params as ClientRequest[Params] holding nil, which ensureParams
+// cannot allocate.
+func emptyListParams(method string) mcp.Params {
+	switch method {
+	case "tools/list":
+		return &mcp.ListToolsParams{}
+	case "resources/list":
+		return &mcp.ListResourcesParams{}
+	case "prompts/list":
+		return &mcp.ListPromptsParams{}
+	case "resources/templates/list":
+		return &mcp.ListResourceTemplatesParams{}
+	}
+	return nil
+}
+
+// selectableMethod reports whether requests of method are routed to a
+// variant.
+func selectableMethod(method string) bool {
+	switch method {
+	case "tools/list", "resources/list", "prompts/list", "resources/templates/list",
+		"tools/call", "resources/read", "prompts/get", "completion/complete",
+		"resources/subscribe", "resources/unsubscribe":
+		return true
+	}
+	return false
+}
+
+// parseClientPayload decodes an extension payload as received in the
+// initialize result or a variants update. It reports false if v is not a
+// payload.
+func parseClientPayload(v any) (AvailableVariantsPayload, bool) {
+	var p AvailableVariantsPayload
+	if v == nil {
+		return p, false
+	}
+	data, err := json.Marshal(v)
+	if err != nil || json.Unmarshal(data, &p) != nil {
+		return AvailableVariantsPayload{}, false
+	}
+	return p, true
+}
+
+// catalogPart identifies a cached part of a Catalog.
+type catalogPart int
+
+const (
+	catalogTools catalogPart = iota
+	catalogPrompts
+	catalogResources
+)
+
+// ClientSession is a session of a [Client] with a variant server. Requests
+// made through the embedded mcp.ClientSession select the session's selected
+// variant, if any (see SelectVariant); otherwise the server's default
+// variant serves them.
+type ClientSession struct {
+	*mcp.ClientSession
+	client *Client
+
+	mu       sync.Mutex
+	payload  AvailableVariantsPayload
+	selected string // "" follows the server's default
+	active   string
+	gen      int // incremented whenever the cache is invalidated
+	cache    Catalog
+	cached   [3]bool // by catalogPart
+}
+
+// Close closes the session.
+func (s *ClientSession) Close() error {
+	s.client.sessions.Delete(s.ClientSession)
+	return s.ClientSession.Close()
+}
+
+// Variants returns the variants the server advertises, best-ranked first.
+func (s *ClientSession) Variants() []ServerVariant {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.payload.AvailableVariants)
+}
+
+// DefaultVariant returns the ID of the server's default variant for the
+// session.
+func (s *ClientSession) DefaultVariant() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.payload.DefaultVariant
+}
+
+// RecommendedVariant returns the ID of the variant the server recommends
+// for the session's hints.
+func (s *ClientSession) RecommendedVariant() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.payload.RecommendedVariant
+}
+
+// ActiveVariant returns the ID of the variant serving the session's
+// requests: the selected variant, or else the server's default.
+func (s *ClientSession) ActiveVariant() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active
+}
+
+// SelectVariant selects the variant serving the session's requests, or
+// follows the server's default again if id is empty. It fails for variants
+// the server does not advertise, unless the server reports more variants
+// than it advertises.
+//
+// If the active variant changes, the cached catalog is invalidated and
+// OnVariantChanged is called before SelectVariant returns.
+func (s *ClientSession) SelectVariant(ctx context.Context, id string) error {
+	s.mu.Lock()
+	if id != "" && !s.payload.MoreVariantsAvailable && !slices.ContainsFunc(s.payload.AvailableVariants, func(v ServerVariant) bool { return v.ID == id }) {
+		s.mu.Unlock()
+		return fmt.Errorf("variants: variant %q is not available", id)
+	}
+	s.selected = id
+	change, changed := s.updateActiveLocked()
+	s.mu.Unlock()
+	if changed {
+		s.variantChanged(ctx, change)
+	}
+	return nil
+}
+
+// Catalog returns the active variant's tools, prompts and resources,
+// listing those that are not cached. Kinds the server does not support are
+// left empty.
+func (s *ClientSession) Catalog(ctx context.Context) (*Catalog, error) {
+	tools, err := s.Tools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prompts, err := s.Prompts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := s.Resources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Catalog{Tools: tools, Prompts: prompts, Resources: resources}, nil
+}
+
+// Tools returns the active variant's tools, listing them unless cached.
+func (s *ClientSession) Tools(ctx context.Context) ([]*mcp.Tool, error) {
+	return cachedList(s, catalogTools, &s.cache.Tools, func(caps *mcp.ServerCapabilities) bool { return caps.Tools != nil },
+		func() iter.Seq2[*mcp.Tool, error] { return s.ClientSession.Tools(ctx, nil) })
+}
+
+// Prompts returns the active variant's prompts, listing them unless cached.
+func (s *ClientSession) Prompts(ctx context.Context) ([]*mcp.Prompt, error) {
+	return cachedList(s, catalogPrompts, &s.cache.Prompts, func(caps *mcp.ServerCapabilities) bool { return caps.Prompts != nil },
+		func() iter.Seq2[*mcp.Prompt, error] { return s.ClientSession.Prompts(ctx, nil) })
+}
+
+// Resources returns the active variant's resources, listing them unless
+// cached.
+func (s *ClientSession) Resources(ctx context.Context) ([]*mcp.Resource, error) {
+	return cachedList(s, catalogResources, &s.cache.Resources, func(caps *mcp.ServerCapabilities) bool { return caps.Resources != nil },
+		func() iter.Seq2[*mcp.Resource, error] { return s.ClientSession.Resources(ctx, nil) })
+}
+
+// cachedList returns a cached part of the catalog, or lists it with the
+// iterator returned by list and caches it, unless the cache was
+// invalidated meanwhile. Parts the active variant does not support, per
+// its reported capabilities or else the server's, are empty.
+func cachedList[T any](s *ClientSession, part catalogPart, cache *[]T, supported func(*mcp.ServerCapabilities) bool, list func() iter.Seq2[T, error]) ([]T, error) {
+	s.mu.Lock()
+	if s.cached[part] {
+		items := *cache
+		s.mu.Unlock()
+		return items, nil
+	}
+	gen := s.gen
+	caps := s.variantCapabilitiesLocked(s.active)
+	s.mu.Unlock()
+
+	if res := s.InitializeResult(); caps == nil && res != nil {
+		caps = res.Capabilities
+	}
+	var items []T
+	if caps != nil && supported(caps) {
+		for item, err := range list() {
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.gen == gen {
+		*cache, s.cached[part] = items, true
+	}
+	return items, nil
+}
+
+// listChanged handles a list-changed notification for a part of the
+// catalog, which for tools may carry an updated extension payload.
+func (s *ClientSession) listChanged(part catalogPart, payload any) {
+	s.mu.Lock()
+	s.gen++
+	s.cached[part] = false
+	var change VariantChange
+	var changed bool
+	if p, ok := parseClientPayload(payload); ok {
+		s.payload = p
+		change, changed = s.updateActiveLocked()
+	}
+	s.mu.Unlock()
+	if changed {
+		// Notification handlers must not block on requests to the server.
+		go s.variantChanged(context.Background(), change)
+	}
+}
+
+// updateActiveLocked recomputes the active variant, invalidating the cache
+// if it changed. s.mu must be held.
+func (s *ClientSession) updateActiveLocked() (VariantChange, bool) {
+	active := s.selected
+	if active == "" {
+		active = s.payload.DefaultVariant
+	}
+	if active == s.active {
+		return VariantChange{}, false
+	}
+	change := VariantChange{Previous: s.active, Current: active}
+	s.active = active
+	s.gen++
+	s.cache, s.cached = Catalog{}, [3]bool{}
+	return change, true
+}
+
+// variantChanged refetches the catalog if configured and reports a change
+// of the active variant.
+func (s *ClientSession) variantChanged(ctx context.Context, change VariantChange) {
+	if s.client.opts.Refetch {
+		change.Catalog, change.Err = s.Catalog(ctx)
+	}
+	if fn := s.client.opts.OnVariantChanged; fn != nil {
+		fn(ctx, s, change)
+	}
+}
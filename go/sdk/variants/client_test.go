@@ -0,0 +1,117 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connectVariantsClient serves vs over streamable HTTP and connects a
+// Client to it.
+func connectVariantsClient(t *testing.T, vs *Server, opts *ClientOptions) *ClientSession {
+	t.Helper()
+	httpSrv := httptest.NewServer(NewStreamableHTTPHandler(vs, nil))
+	t.Cleanup(httpSrv.Close)
+	t.Cleanup(func() { vs.Close() })
+	client := NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, opts)
+	session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{Endpoint: httpSrv.URL}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+func TestClient_SelectVariant(t *testing.T) {
+	changes := make(chan VariantChange, 4)
+	session := connectVariantsClient(t, newTestVariantServer(), &ClientOptions{
+		Refetch: true,
+		OnVariantChanged: func(_ context.Context, _ *ClientSession, change VariantChange) {
+			changes <- change
+		},
+	})
+	ctx := context.Background()
+
+	assert.Equal(t, "coding", session.DefaultVariant())
+	assert.Equal(t, "coding", session.ActiveVariant())
+	assert.Equal(t, []string{"coding", "compact"}, variantIDs(session.Variants()))
+	tools, err := session.Tools(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"analyze_code", "refactor"}, toolNames(tools))
+
+	// Selecting a variant re-lists its catalog and routes requests to it.
+	require.NoError(t, session.SelectVariant(ctx, "compact"))
+	change := <-changes
+	assert.Equal(t, "coding", change.Previous)
+	assert.Equal(t, "compact", change.Current)
+	require.NoError(t, change.Err)
+	assert.ElementsMatch(t, []string{"summarize", "lookup"}, toolNames(change.Catalog.Tools))
+	tools, err = session.Tools(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"summarize", "lookup"}, toolNames(tools))
+	listed, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"summarize", "lookup"}, toolNames(listed.Tools))
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "summarize", Arguments: map[string]any{"text": "x"}})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+
+	// Reselecting the active variant changes nothing.
+	require.NoError(t, session.SelectVariant(ctx, "compact"))
+	assert.Empty(t, changes)
+
+	assert.Error(t, session.SelectVariant(ctx, "nope"))
+	assert.Equal(t, "compact", session.ActiveVariant())
+}
+
+func TestClient_ServerDefaultChanged(t *testing.T) {
+	vs := newTestVariantServer()
+	changes := make(chan VariantChange, 4)
+	session := connectVariantsClient(t, vs, &ClientOptions{
+		OnVariantChanged: func(_ context.Context, _ *ClientSession, change VariantChange) {
+			changes <- change
+		},
+	})
+	ctx := context.Background()
+	_, err := session.Tools(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, vs.PinSession(session.ID(), "compact"))
+	select {
+	case change := <-changes:
+		assert.Equal(t, "compact", change.Current)
+		assert.Nil(t, change.Catalog)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for variant change")
+	}
+	assert.Equal(t, "compact", session.DefaultVariant())
+	tools, err := session.Tools(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"summarize", "lookup"}, toolNames(tools))
+}
+
+func TestClient_Hints(t *testing.T) {
+	vs := newTestVariantServer()
+	session := connectVariantsClient(t, vs, &ClientOptions{
+		Hints: VariantHints{Hints: map[string]any{HintContextSize: "compact"}},
+	})
+
+	info, err := vs.Session(session.ID())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{HintContextSize: "compact"}, info.Hints.Hints)
+}
+
+func variantIDs(vs []ServerVariant) []string {
+	ids := make([]string, len(vs))
+	for i, v := range vs {
+		ids[i] = v.ID
+	}
+	return ids
+}
@@ -0,0 +1,284 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CompatibilityReport describes how a variant's tools carry over to its
+// replacement, for client authors migrating off a deprecated variant. It is
+// produced by [Server.CompatibilityReport] and is JSON-serializable.
+type CompatibilityReport struct {
+	// Variant is the ID of the variant being replaced.
+	Variant string `json:"variant"`
+
+	// Replacement is the ID of its replacement, from
+	// DeprecationInfo.Replacement.
+	Replacement string `json:"replacement"`
+
+	// Compatible reports whether every tool of Variant is available in
+	// Replacement without breaking changes, or is translated.
+	Compatible bool `json:"compatible"`
+
+	// Tools describes each tool of Variant, sorted by name.
+	Tools []ToolCompatibility `json:"tools"`
+
+	// AddedTools are the tools of Replacement that Variant does not have.
+	AddedTools []string `json:"addedTools,omitempty"`
+}
+
+// ToolCompatibility describes how one tool carries over to the
+// replacement variant.
+type ToolCompatibility struct {
+	// Name is the tool's name in the replaced variant.
+	Name string `json:"name"`
+
+	// Status summarizes the comparison.
+	Status CompatibilityStatus `json:"status"`
+
+	// ReplacementTool is the tool serving calls of a translated tool (see
+	// Server.WithToolTranslation).
+	ReplacementTool string `json:"replacementTool,omitempty"`
+
+	// Changes lists the differences between the tool's schemas in the two
+	// variants.
+	Changes []SchemaChange `json:"changes,omitempty"`
+}
+
+// CompatibilityStatus summarizes how a tool carries over to the
+// replacement variant.
+type CompatibilityStatus string
+
+const (
+	// ToolUnchanged indicates that the replacement has the tool with the
+	// same input and output schemas.
+	ToolUnchanged CompatibilityStatus = "unchanged"
+	// ToolCompatible indicates that the tool's schemas changed, but calls
+	// and results valid for the replaced variant remain valid.
+	ToolCompatible CompatibilityStatus = "compatible"
+	// ToolIncompatible indicates that the tool's schemas changed in a way
+	// that may break existing calls or result handling.
+	ToolIncompatible CompatibilityStatus = "incompatible"
+	// ToolMissing indicates that the replacement has no tool of that name.
+	ToolMissing CompatibilityStatus = "missing"
+	// ToolTranslated indicates that calls of the tool are translated to a
+	// tool of the replacement with Server.WithToolTranslation.
+	ToolTranslated CompatibilityStatus = "translated"
+)
+
+// SchemaChange is one difference between the schemas of a tool in two
+// variants. Only top-level properties are compared.
+type SchemaChange struct {
+	// Schema is "input" or "output".
+	Schema string `json:"schema"`
+
+	// Property is the affected top-level property, or empty for a change
+	// to the schema as a whole.
+	Property string `json:"property,omitempty"`
+
+	// Kind is one of "added", "removed", "required", "optional",
+	// "typeChanged", and "changed".
+	Kind string `json:"kind"`
+
+	// Breaking reports whether the change may break clients of the
+	// replaced variant: for input schemas, removed properties, newly
+	// required properties, and type changes; for output schemas, removed
+	// properties and type changes.
+	Breaking bool `json:"breaking"`
+}
+
+// CompatibilityReport compares the tools of the variant with the given ID
+// against those of the replacement named in its DeprecationInfo, for
+// client authors planning a migration. Tool calls translated with
+// WithToolTranslation count as compatible.
+//
+// It fails if the variant is unknown or names no registered replacement,
+// and with [ErrServerClosed] once the server is closed.
+func (s *Server) CompatibilityReport(ctx context.Context, variantID string) (*CompatibilityReport, error) {
+	if s.closed() {
+		return nil, ErrServerClosed
+	}
+	v, ok := s.lookupVariant(variantID)
+	if !ok {
+		return nil, fmt.Errorf("variants: unknown variant %q", variantID)
+	}
+	if v.DeprecationInfo == nil || v.DeprecationInfo.Replacement == "" {
+		return nil, fmt.Errorf("variants: variant %q has no replacement", variantID)
+	}
+	replacementID := v.DeprecationInfo.Replacement
+	if !s.hasVariant(replacementID) {
+		return nil, fmt.Errorf("variants: replacement %q of variant %q is not registered", replacementID, variantID)
+	}
+
+	oldTools, err := s.variantTools(ctx, variantID)
+	if err != nil {
+		return nil, err
+	}
+	newTools, err := s.variantTools(ctx, replacementID)
+	if err != nil {
+		return nil, err
+	}
+
+	return compareTools(variantID, replacementID, oldTools, newTools, func(name string) (string, bool) {
+		t, ok := s.translations[translationKey{variantID, name}]
+		return t.Tool, ok && t.Variant == replacementID
+	}), nil
+}
+
+// CompareTools compares the tools of a variant against those of its
+// replacement, as [Server.CompatibilityReport] does, for callers that list
+// the tools themselves, such as clients of a remote variant server. Tool
+// translations are not visible to clients, so no tool is reported as
+// translated.
+func CompareTools(variantID, replacementID string, oldTools, newTools []*mcp.Tool) *CompatibilityReport {
+	return compareTools(variantID, replacementID, toolsByName(oldTools), toolsByName(newTools), func(string) (string, bool) { return "", false })
+}
+
+// compareTools builds a compatibility report. translated reports the replacement tool that calls of a tool are translated to.
+func compareTools(variantID, replacementID string, oldTools, newTools map[string]*mcp.Tool, translated func(name string) (string, bool)) *CompatibilityReport {
+	r := &CompatibilityReport{Variant: variantID, Replacement: replacementID, Compatible: true, Tools: []ToolCompatibility{}}
+	for _, name := range sortedKeys(oldTools) {
+		tc := ToolCompatibility{Name: name}
+		if tool, ok := translated(name); ok {
+			tc.Status, tc.ReplacementTool = ToolTranslated, tool
+		} else if nt, ok := newTools[name]; !ok {
+			tc.Status = ToolMissing
+		} else {
+			tc.Changes = append(diffSchema("input", oldTools[name].InputSchema, nt.InputSchema),
+				diffSchema("output", oldTools[name].OutputSchema, nt.OutputSchema)...)
+			tc.Status = ToolUnchanged
+			if len(tc.Changes) > 0 {
+				tc.Status = ToolCompatible
+			}
+			if slices.ContainsFunc(tc.Changes, func(c SchemaChange) bool { return c.Breaking }) {
+				tc.Status = ToolIncompatible
+			}
+		}
+		if tc.Status == ToolMissing || tc.Status == ToolIncompatible {
+			r.Compatible = false
+		}
+		r.Tools = append(r.Tools, tc)
+	}
+	for _, name := range sortedKeys(newTools) {
+		if _, ok := oldTools[name]; !ok {
+			r.AddedTools = append(r.AddedTools, name)
+		}
+	}
+	return r
+}
+
+// variantTools lists a variant's tools by name.
+func (s *Server) variantTools(ctx context.Context, variantID string) (map[string]*mcp.Tool, error) {
+	entry := s.variants[s.variantIndex[variantID]]
+	tools, err := entry.backend.tools(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("variants: listing tools of variant %q: %w", variantID, err)
+	}
+	return toolsByName(tools), nil
+}
+
+// toolsByName indexes tools by name, skipping nil entries.
+func toolsByName(tools []*mcp.Tool) map[string]*mcp.Tool {
+	m := make(map[string]*mcp.Tool, len(tools))
+	for _, t := range tools {
+		if t != nil {
+			m[t.Name] = t
+		}
+	}
+	return m
+}
+
+// diffSchema compares the top-level properties of two object schemas.
+func diffSchema(kind string, oldSchema, newSchema any) []SchemaChange {
+	o, n := schemaObject(oldSchema), schemaObject(newSchema)
+	if reflect.DeepEqual(o, n) {
+		return nil
+	}
+	oldProps, _ := o["properties"].(map[string]any)
+	newProps, _ := n["properties"].(map[string]any)
+	oldReq, newReq := requiredSet(o), requiredSet(n)
+	input := kind == "input"
+
+	var changes []SchemaChange
+	for _, p := range sortedKeys(oldProps) {
+		np, ok := newProps[p]
+		switch {
+		case !ok:
+			changes = append(changes, SchemaChange{Schema: kind, Property: p, Kind: "removed", Breaking: true})
+			continue
+		case schemaType(oldProps[p]) != schemaType(np):
+			changes = append(changes, SchemaChange{Schema: kind, Property: p, Kind: "typeChanged", Breaking: true})
+		case !reflect.DeepEqual(oldProps[p], np):
+			changes = append(changes, SchemaChange{Schema: kind, Property: p, Kind: "changed"})
+		}
+		if input && !oldReq[p] && newReq[p] {
+			changes = append(changes, SchemaChange{Schema: kind, Property: p, Kind: "required", Breaking: true})
+		} else if input && oldReq[p] && !newReq[p] {
+			changes = append(changes, SchemaChange{Schema: kind, Property: p, Kind: "optional"})
+		}
+	}
+	for _, p := range sortedKeys(newProps) {
+		if _, ok := oldProps[p]; !ok {
+			changes = append(changes, SchemaChange{Schema: kind, Property: p, Kind: "added", Breaking: input && newReq[p]})
+		}
+	}
+	if len(changes) == 0 {
+		// Only keywords other than the top-level properties differ.
+		changes = append(changes, SchemaChange{Schema: kind, Kind: "changed"})
+	}
+	return changes
+}
+
+// schemaObject returns a schema as a JSON object, or nil if it is absent or
+// not an object.
+func schemaObject(schema any) map[string]any {
+	if schema == nil {
+		return nil
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil
+	}
+	var m map[string]any
+	_ = json.Unmarshal(data, &m)
+	return m
+}
+
+// requiredSet returns the properties an object schema requires.
+func requiredSet(schema map[string]any) map[string]bool {
+	list, _ := schema["required"].([]any)
+	set := make(map[string]bool, len(list))
+	for _, r := range list {
+		if s, ok := r.(string); ok {
+			set[s] = true
+		}
+	}
+	return set
+}
+
+// schemaType returns a property schema's type keyword, for comparison.
+func schemaType(schema any) string {
+	m, _ := schema.(map[string]any)
+	data, _ := json.Marshal(m["type"])
+	return string(data)
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
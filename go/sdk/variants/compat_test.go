@@ -0,0 +1,99 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompatibilityReport(t *testing.T) {
+	type props = map[string]any
+	object := func(required []string, p props) map[string]any {
+		s := map[string]any{"type": "object", "properties": p}
+		if required != nil {
+			s["required"] = required
+		}
+		return s
+	}
+	str, num := props{"type": "string"}, props{"type": "number"}
+	noop := func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{}, nil
+	}
+
+	v1 := mcp.NewServer(&mcp.Implementation{Name: "trading", Version: "v1.0.0"}, nil)
+	v1.AddTool(&mcp.Tool{Name: "quote", InputSchema: object([]string{"symbol"}, props{"symbol": str})}, noop)
+	v1.AddTool(&mcp.Tool{Name: "history", InputSchema: object(nil, props{"symbol": str, "days": num})}, noop)
+	v1.AddTool(&mcp.Tool{Name: "portfolio", InputSchema: object(nil, nil)}, noop)
+	v1.AddTool(&mcp.Tool{Name: "trade", InputSchema: object(nil, nil)}, noop)
+	v1.AddTool(&mcp.Tool{Name: "news", InputSchema: object(nil, nil)}, noop)
+
+	v2 := mcp.NewServer(&mcp.Implementation{Name: "trading", Version: "v2.0.0"}, nil)
+	v2.AddTool(&mcp.Tool{Name: "quote", InputSchema: object([]string{"symbol"}, props{"symbol": str})}, noop)
+	v2.AddTool(&mcp.Tool{Name: "history", InputSchema: object([]string{"symbol"}, props{"symbol": str, "days": str})}, noop)
+	v2.AddTool(&mcp.Tool{Name: "portfolio", InputSchema: object(nil, props{"currency": str})}, noop)
+	v2.AddTool(&mcp.Tool{Name: "place_order", InputSchema: object(nil, nil)}, noop)
+
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{
+			ID:              "v1",
+			Status:          Deprecated,
+			DeprecationInfo: &DeprecationInfo{Message: "Use v2", Replacement: "v2"},
+		}, v1, 1).
+		WithVariant(ServerVariant{ID: "v2"}, v2, 0).
+		WithToolTranslation("v1", "trade", ToolTranslator{Variant: "v2", Tool: "place_order"})
+
+	r, err := vs.CompatibilityReport(context.Background(), "v1")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", r.Replacement)
+	assert.False(t, r.Compatible)
+	assert.Equal(t, []string{"place_order"}, r.AddedTools)
+	assert.Equal(t, []ToolCompatibility{
+		{Name: "history", Status: ToolIncompatible, Changes: []SchemaChange{
+			{Schema: "input", Property: "days", Kind: "typeChanged", Breaking: true},
+			{Schema: "input", Property: "symbol", Kind: "required", Breaking: true},
+		}},
+		{Name: "news", Status: ToolMissing},
+		{Name: "portfolio", Status: ToolCompatible, Changes: []SchemaChange{
+			{Schema: "input", Property: "currency", Kind: "added"},
+		}},
+		{Name: "quote", Status: ToolUnchanged},
+		{Name: "trade", Status: ToolTranslated, ReplacementTool: "place_order"},
+	}, r.Tools)
+
+	_, err = vs.CompatibilityReport(context.Background(), "v2")
+	assert.ErrorContains(t, err, "has no replacement")
+	_, err = vs.CompatibilityReport(context.Background(), "v3")
+	assert.ErrorContains(t, err, "unknown variant")
+}
+
+func TestCompareTools(t *testing.T) {
+	object := func(p map[string]any) map[string]any {
+		return map[string]any{"type": "object", "properties": p}
+	}
+	oldTools := []*mcp.Tool{
+		{Name: "quote", InputSchema: object(map[string]any{"symbol": map[string]any{"type": "string"}})},
+		{Name: "trade", InputSchema: object(nil)},
+	}
+	newTools := []*mcp.Tool{
+		{Name: "quote", InputSchema: object(map[string]any{"symbol": map[string]any{"type": "string"}})},
+		{Name: "place_order", InputSchema: object(nil)},
+	}
+
+	r := CompareTools("v1", "v2", oldTools, newTools)
+	assert.Equal(t, &CompatibilityReport{
+		Variant:     "v1",
+		Replacement: "v2",
+		Tools: []ToolCompatibility{
+			{Name: "quote", Status: ToolUnchanged},
+			{Name: "trade", Status: ToolMissing},
+		},
+		AddedTools: []string{"place_order"},
+	}, r)
+}
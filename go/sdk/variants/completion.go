@@ -0,0 +1,113 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// resolveCompletion returns the variant for a completion/complete request
+// that selects no variant: the session's default variant if it owns the
+// referenced prompt or resource, else the best-ranked usable variant that
+// does. Prompt and resource names are variant-scoped, so completing against
+// the default variant alone would fail for references listed by another
+// variant. It returns "" if no variant owns the reference, leaving the
+// request to the default variant.
+func (d *dispatcher) resolveCompletion(ctx context.Context, req *mcp.CompleteRequest) string {
+	if req.Params == nil || req.Params.Ref == nil {
+		return ""
+	}
+	d.mu.RLock()
+	ranked := d.ranked
+	d.mu.RUnlock()
+	if ranked == nil {
+		ranked = d.server.RankedVariants(ctx, VariantHints{})
+	}
+	candidates := make([]string, 0, len(ranked)+1)
+	if id, err := d.defaultVariant(ctx, req); err == nil {
+		candidates = append(candidates, id)
+	}
+	fc := d.requestFlagContext(req)
+	for _, v := range ranked {
+		if d.server.isAvailable(v.ID) && d.server.flagEnabled(ctx, v.ID, fc) {
+			candidates = append(candidates, v.ID)
+		}
+	}
+	for _, id := range candidates {
+		conn, err := d.connection(ctx, id)
+		if err != nil {
+			continue
+		}
+		if ok, _ := ownsCompletionRef(ctx, conn, req.Params.Ref); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// ownsCompletionRef reports whether conn's variant lists the prompt or
+// resource a completion reference refers to. Resource references match
+// resource templates and resources by URI.
+func ownsCompletionRef(ctx context.Context, conn *innerConnection, ref *mcp.CompleteReference) (bool, error) {
+	switch ref.Type {
+	case "ref/prompt":
+		return listed(ctx, conn, "prompts/list",
+			func(cursor string) mcp.Request {
+				return &mcp.ListPromptsRequest{Params: &mcp.ListPromptsParams{Cursor: cursor}}
+			},
+			func(res *mcp.ListPromptsResult) ([]*mcp.Prompt, string) { return res.Prompts, res.NextCursor },
+			func(p *mcp.Prompt) bool { return p.Name == ref.Name })
+	case "ref/resource":
+		ok, err := listed(ctx, conn, "resources/templates/list",
+			func(cursor string) mcp.Request {
+				return &mcp.ListResourceTemplatesRequest{Params: &mcp.ListResourceTemplatesParams{Cursor: cursor}}
+			},
+			func(res *mcp.ListResourceTemplatesResult) ([]*mcp.ResourceTemplate, string) {
+				return res.ResourceTemplates, res.NextCursor
+			},
+			func(t *mcp.ResourceTemplate) bool { return t.URITemplate == ref.URI })
+		if ok || err != nil {
+			return ok, err
+		}
+		return listed(ctx, conn, "resources/list",
+			func(cursor string) mcp.Request {
+				return &mcp.ListResourcesRequest{Params: &mcp.ListResourcesParams{Cursor: cursor}}
+			},
+			func(res *mcp.ListResourcesResult) ([]*mcp.Resource, string) { return res.Resources, res.NextCursor },
+			func(r *mcp.Resource) bool { return r.URI == ref.URI })
+	}
+	return false, nil
+}
+
+// listed reports whether a list method of conn's variant returns an item
+// matching match, following pagination. Variants not supporting the method
+// list nothing.
+func listed[R mcp.Result, T comparable](ctx context.Context, conn *innerConnection, method string,
+	page func(cursor string) mcp.Request, items func(R) ([]T, string), match func(T) bool) (bool, error) {
+	var zero T
+	cursor := ""
+	for {
+		res, err := conn.backendSession.handleReceive(ctx, method, page(cursor))
+		if err != nil {
+			return false, err
+		}
+		list, ok := res.(R)
+		if !ok || isNilInterface(list) {
+			return false, nil
+		}
+		all, next := items(list)
+		for _, item := range all {
+			if item != zero && match(item) {
+				return true, nil
+			}
+		}
+		if next == "" {
+			return false, nil
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CompletionCacheOptions configures the completion cache (see
+// WithCompletionCache).
+type CompletionCacheOptions struct {
+	// Size bounds the number of results cached per variant. Zero means 256.
+	Size int
+
+	// TTL is how long a result is reused. Zero means until it is
+	// invalidated.
+	TTL time.Duration
+
+	// Narrow answers a request from the cached result for a prefix of its
+	// argument value, keeping the values that start with the new value,
+	// if that result was complete: not marked as having more values, and
+	// with no total beyond its values. Enable it only for variants whose
+	// completions are prefix matches of the argument value.
+	Narrow bool
+}
+
+// defaultCompletionCacheSize is the cache size when
+// CompletionCacheOptions.Size is zero.
+const defaultCompletionCacheSize = 256
+
+// WithCompletionCache caches the results of completion/complete requests
+// for prompt arguments per variant, so that interactive clients completing
+// an argument as the user types do not reach the variant again for values
+// they already completed, such as after deleting characters. Results are
+// cached per session, prompt, argument and context arguments.
+//
+// Invalidation is prefix-aware: completing a value drops the session's
+// cached results for the same argument whose values are neither prefixes
+// nor extensions of it, since the user has moved on from them. A variant's
+// results are also dropped when it notifies that its prompts changed, and a
+// session's when it closes. Requests answered from the cache do not reach
+// the variant, its dispatch interceptors or event handlers.
+//
+// Caching assumes a variant's completions depend only on the request; do
+// not enable it for variants whose completions change otherwise. It panics
+// if the size or TTL is negative.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithCompletionCache(opts CompletionCacheOptions) *Server {
+	if opts.Size < 0 || opts.TTL < 0 {
+		panic("variants: negative completion cache size or TTL")
+	}
+	if opts.Size == 0 {
+		opts.Size = defaultCompletionCacheSize
+	}
+	s.completions = &completionCache{opts: opts}
+	return s
+}
+
+// completionCache holds completion results by variant. Each variant's
+// results are grouped in chains of the values completed for one argument
+// in one session, which are prefixes or extensions of one another.
+type completionCache struct {
+	opts CompletionCacheOptions
+
+	mu       sync.Mutex
+	variants map[string]*variantCompletions // by variant ID
+}
+
+// variantCompletions are the completion results cached for a variant.
+type variantCompletions struct {
+	chains map[completionKey]map[string]completionEntry // by argument value
+	size   int                                          // number of entries
+}
+
+// completionKey identifies an argument completed in a session.
+type completionKey struct {
+	session  string
+	prompt   string
+	argument string
+	context  string // the context arguments, as JSON
+}
+
+// completionEntry is a cached completion result.
+type completionEntry struct {
+	res *mcp.CompleteResult
+	at  time.Time
+}
+
+// completionRequest returns the key and argument value of a completion
+// request for a prompt argument. ok is false for other requests, which are
+// not cached.
+func completionRequest(req mcp.Request) (key completionKey, value string, ok bool) {
+	complete, _ := req.(*mcp.CompleteRequest)
+	if complete == nil || complete.Params == nil || complete.Params.Ref == nil || complete.Params.Ref.Type != "ref/prompt" {
+		return key, "", false
+	}
+	p := complete.Params
+	key = completionKey{session: sessionID(req), prompt: p.Ref.Name, argument: p.Argument.Name}
+	if p.Context != nil && len(p.Context.Arguments) > 0 {
+		// encoding/json sorts map keys, so equal arguments encode equally.
+		data, err := json.Marshal(p.Context.Arguments)
+		if err != nil {
+			return key, "", false
+		}
+		key.context = string(data)
+	}
+	return key, p.Argument.Value, true
+}
+
+// fresh reports whether e can be reused at now.
+func (c *completionCache) fresh(e completionEntry, now time.Time) bool {
+	return c.opts.TTL == 0 || now.Sub(e.at) < c.opts.TTL
+}
+
+// get returns the cached result of a request to a variant, if any.
+func (c *completionCache) get(variantID string, req mcp.Request) (*mcp.CompleteResult, bool) {
+	key, value, ok := completionRequest(req)
+	if !ok {
+		return nil, false
+	}
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	vc := c.variants[variantID]
+	if vc == nil {
+		return nil, false
+	}
+	chain := vc.chains[key]
+	if e, ok := chain[value]; ok && c.fresh(e, now) {
+		return cloneCompleteResult(e.res), true
+	}
+	if !c.opts.Narrow {
+		return nil, false
+	}
+	// Narrow the complete result of the longest cached prefix.
+	var (
+		best  completionEntry
+		found = -1
+	)
+	for v, e := range chain {
+		if len(v) > found && len(v) < len(value) && strings.HasPrefix(value, v) && completeResult(e.res) && c.fresh(e, now) {
+			best, found = e, len(v)
+		}
+	}
+	if found < 0 {
+		return nil, false
+	}
+	res := cloneCompleteResult(best.res)
+	res.Completion.Values = slices.DeleteFunc(res.Completion.Values, func(v string) bool { return !strings.HasPrefix(v, value) })
+	if res.Completion.Total != 0 {
+		res.Completion.Total = len(res.Completion.Values)
+	}
+	return res, true
+}
+
+// put caches the result of a request to a variant, and drops the results
+// of the session's values for the argument that are neither prefixes nor
+// extensions of the request's.
+func (c *completionCache) put(variantID string, req mcp.Request, res mcp.Result) {
+	key, value, ok := completionRequest(req)
+	complete, _ := res.(*mcp.CompleteResult)
+	if !ok || complete == nil {
+		return
+	}
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.variants == nil {
+		c.variants = make(map[string]*variantCompletions)
+	}
+	vc := c.variants[variantID]
+	if vc == nil {
+		vc = &variantCompletions{chains: make(map[completionKey]map[string]completionEntry)}
+		c.variants[variantID] = vc
+	}
+	chain := vc.chains[key]
+	if chain == nil {
+		chain = make(map[string]completionEntry)
+		vc.chains[key] = chain
+	}
+	for v := range chain {
+		if !strings.HasPrefix(value, v) && !strings.HasPrefix(v, value) {
+			delete(chain, v)
+			vc.size--
+		}
+	}
+	if _, ok := chain[value]; !ok {
+		vc.size++
+	}
+	chain[value] = completionEntry{res: cloneCompleteResult(complete), at: now}
+
+	// Evict arbitrary other entries once full.
+	for k, ch := range vc.chains {
+		for v := range ch {
+			if vc.size <= c.opts.Size {
+				return
+			}
+			if k == key && v == value {
+				continue
+			}
+			delete(ch, v)
+			vc.size--
+		}
+		if len(ch) == 0 {
+			delete(vc.chains, k)
+		}
+	}
+}
+
+// invalidate drops the results cached for a variant.
+func (c *completionCache) invalidate(variantID string) {
+	c.mu.Lock()
+	delete(c.variants, variantID)
+	c.mu.Unlock()
+}
+
+// dropSession drops the results cached for a session.
+func (c *completionCache) dropSession(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, vc := range c.variants {
+		for k, chain := range vc.chains {
+			if k.session == id {
+				vc.size -= len(chain)
+				delete(vc.chains, k)
+			}
+		}
+	}
+}
+
+// completeResult reports whether res lists all the completions of its
+// value.
+func completeResult(res *mcp.CompleteResult) bool {
+	return !res.Completion.HasMore && res.Completion.Total <= len(res.Completion.Values)
+}
+
+// cloneCompleteResult returns a copy of res that does not share its values
+// or metadata.
+func cloneCompleteResult(res *mcp.CompleteResult) *mcp.CompleteResult {
+	out := *res
+	out.Meta = maps.Clone(res.Meta)
+	out.Completion.Values = slices.Clone(res.Completion.Values)
+	return &out
+}
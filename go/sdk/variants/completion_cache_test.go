@@ -0,0 +1,177 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCitiesServer returns a server completing the city argument of its
+// weather prompt with the cities starting with the argument value, and
+// counting the completion requests it receives.
+func newCitiesServer(calls *atomic.Int32) *mcp.Server {
+	cities := []string{"Amsterdam", "Ankara", "Athens", "Berlin", "Bern"}
+	srv := mcp.NewServer(&mcp.Implementation{Name: "weather", Version: "v1.0.0"}, &mcp.ServerOptions{
+		CompletionHandler: func(_ context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+			calls.Add(1)
+			var values []string
+			for _, c := range cities {
+				if strings.HasPrefix(c, req.Params.Argument.Value) {
+					values = append(values, c)
+				}
+			}
+			return &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: values, Total: len(values)}}, nil
+		},
+	})
+	srv.AddPrompt(&mcp.Prompt{Name: "weather", Arguments: []*mcp.PromptArgument{{Name: "city"}}},
+		func(context.Context, *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return &mcp.GetPromptResult{}, nil
+		})
+	// The SDK notifies sessions of the added prompt after a delay; wait it
+	// out, so that the notification does not invalidate cached results of
+	// sessions connected meanwhile.
+	time.Sleep(50 * time.Millisecond)
+	return srv
+}
+
+func TestCompletionCache(t *testing.T) {
+	var calls atomic.Int32
+	inner := newCitiesServer(&calls)
+	vs := NewServer(&mcp.Implementation{Name: "weather", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "weather", Status: Stable}, inner, 0).
+		WithCompletionCache(CompletionCacheOptions{})
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	complete := func(value string) []string {
+		t.Helper()
+		res, err := session.Complete(ctx, &mcp.CompleteParams{
+			Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "weather"},
+			Argument: mcp.CompleteParamsArgument{Name: "city", Value: value},
+		})
+		require.NoError(t, err)
+		return res.Completion.Values
+	}
+
+	assert.Equal(t, []string{"Amsterdam", "Ankara", "Athens"}, complete("A"))
+	assert.Equal(t, []string{"Amsterdam"}, complete("Am"))
+	assert.Equal(t, int32(2), calls.Load())
+
+	// Deleting a character completes a cached prefix.
+	assert.Equal(t, []string{"Amsterdam", "Ankara", "Athens"}, complete("A"))
+	assert.Equal(t, int32(2), calls.Load())
+
+	// Without narrowing, extensions reach the variant.
+	assert.Equal(t, []string{"Ankara"}, complete("An"))
+	assert.Equal(t, int32(3), calls.Load())
+
+	// A divergent value drops the other values.
+	assert.Equal(t, []string{"Berlin", "Bern"}, complete("B"))
+	assert.Equal(t, int32(4), calls.Load())
+	assert.Equal(t, []string{"Amsterdam"}, complete("Am"))
+	assert.Equal(t, int32(5), calls.Load())
+
+	// Prompt changes invalidate the variant's results.
+	inner.AddPrompt(&mcp.Prompt{Name: "forecast"},
+		func(context.Context, *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return &mcp.GetPromptResult{}, nil
+		})
+	assert.Eventually(t, func() bool {
+		complete("Am")
+		return calls.Load() > 5
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestCompletionCache_Narrow(t *testing.T) {
+	var calls atomic.Int32
+	vs := NewServer(&mcp.Implementation{Name: "weather", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "weather", Status: Stable}, newCitiesServer(&calls), 0).
+		WithCompletionCache(CompletionCacheOptions{Narrow: true})
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	complete := func(value string, args map[string]string) *mcp.CompletionResultDetails {
+		t.Helper()
+		params := &mcp.CompleteParams{
+			Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "weather"},
+			Argument: mcp.CompleteParamsArgument{Name: "city", Value: value},
+		}
+		if args != nil {
+			params.Context = &mcp.CompleteContext{Arguments: args}
+		}
+		res, err := session.Complete(ctx, params)
+		require.NoError(t, err)
+		return &res.Completion
+	}
+
+	assert.Equal(t, &mcp.CompletionResultDetails{Values: []string{"Berlin", "Bern"}, Total: 2}, complete("B", nil))
+	assert.Equal(t, &mcp.CompletionResultDetails{Values: []string{"Bern"}, Total: 1}, complete("Bern", nil))
+	assert.Equal(t, &mcp.CompletionResultDetails{Values: []string{}, Total: 0}, complete("Bx", nil))
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Results are cached per context arguments.
+	complete("Be", map[string]string{"units": "metric"})
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCompletionCache_TTL(t *testing.T) {
+	var calls atomic.Int32
+	vs := NewServer(&mcp.Implementation{Name: "weather", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "weather", Status: Stable}, newCitiesServer(&calls), 0).
+		WithCompletionCache(CompletionCacheOptions{TTL: 20 * time.Millisecond})
+	session := connectTestClient(t, vs, nil)
+	params := &mcp.CompleteParams{
+		Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "weather"},
+		Argument: mcp.CompleteParamsArgument{Name: "city", Value: "A"},
+	}
+	for range 2 {
+		_, err := session.Complete(context.Background(), params)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), calls.Load())
+
+	time.Sleep(30 * time.Millisecond)
+	_, err := session.Complete(context.Background(), params)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCompletionCache_Eviction(t *testing.T) {
+	c := &completionCache{opts: CompletionCacheOptions{Size: 2}}
+	req := func(prompt, value string) *mcp.CompleteRequest {
+		return &mcp.CompleteRequest{Params: &mcp.CompleteParams{
+			Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: prompt},
+			Argument: mcp.CompleteParamsArgument{Name: "city", Value: value},
+		}}
+	}
+	res := &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{Values: []string{"Athens"}}}
+	c.put("weather", req("a", "A"), res)
+	c.put("weather", req("b", "B"), res)
+	c.put("weather", req("c", "C"), res)
+	assert.Equal(t, 2, c.variants["weather"].size)
+	_, ok := c.get("weather", req("c", "C"))
+	assert.True(t, ok, "the latest result is kept")
+
+	// Resource references are not cached.
+	c.put("weather", &mcp.CompleteRequest{Params: &mcp.CompleteParams{Ref: &mcp.CompleteReference{Type: "ref/resource", URI: "file:///{path}"}}}, res)
+	assert.Equal(t, 2, c.variants["weather"].size)
+
+	c.invalidate("weather")
+	_, ok = c.get("weather", req("c", "C"))
+	assert.False(t, ok)
+}
+
+func TestWithCompletionCache_Negative(t *testing.T) {
+	assert.Panics(t, func() { newTestVariantServer().WithCompletionCache(CompletionCacheOptions{Size: -1}) })
+	assert.Panics(t, func() { newTestVariantServer().WithCompletionCache(CompletionCacheOptions{TTL: -time.Second}) })
+}
@@ -0,0 +1,56 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompletionRouting(t *testing.T) {
+	novice := newPromptServer("novice", "walkthrough")
+	expert := newPromptServer("expert", "review")
+	expert.AddResourceTemplate(&mcp.ResourceTemplate{URITemplate: "src://{path}", Name: "source"},
+		func(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) { return nil, nil })
+	vs := NewServer(&mcp.Implementation{Name: "tutor", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "novice", Status: Stable}, novice, 0).
+		WithVariant(ServerVariant{ID: "expert", Status: Stable}, expert, 1)
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	complete := func(meta mcp.Meta, ref *mcp.CompleteReference) []string {
+		t.Helper()
+		res, err := session.Complete(ctx, &mcp.CompleteParams{
+			Meta:     meta,
+			Ref:      ref,
+			Argument: mcp.CompleteParamsArgument{Name: "topic", Value: "x"},
+		})
+		require.NoError(t, err)
+		return res.Completion.Values
+	}
+
+	// References owned by the default variant stay there, even if another
+	// variant owns them too.
+	assert.Equal(t, []string{"novice:explain:x"}, complete(nil, &mcp.CompleteReference{Type: "ref/prompt", Name: "explain"}))
+	assert.Equal(t, []string{"novice:walkthrough:x"}, complete(nil, &mcp.CompleteReference{Type: "ref/prompt", Name: "walkthrough"}))
+
+	// References owned by another variant are routed to it.
+	assert.Equal(t, []string{"expert:review:x"}, complete(nil, &mcp.CompleteReference{Type: "ref/prompt", Name: "review"}))
+	assert.Equal(t, []string{"expert::x"}, complete(nil, &mcp.CompleteReference{Type: "ref/resource", URI: "src://{path}"}))
+
+	// Unowned references fall back to the default variant.
+	assert.Equal(t, []string{"novice:missing:x"}, complete(nil, &mcp.CompleteReference{Type: "ref/prompt", Name: "missing"}))
+
+	// An explicit selection wins.
+	assert.Equal(t, []string{"novice:review:x"}, complete(mcp.Meta{metaKeyVariant: "novice"}, &mcp.CompleteReference{Type: "ref/prompt", Name: "review"}))
+
+	// Variants out of rotation are skipped.
+	vs.SetVariantAvailability("expert", false, "maintenance")
+	assert.Equal(t, []string{"novice:review:x"}, complete(nil, &mcp.CompleteReference{Type: "ref/prompt", Name: "review"}))
+}
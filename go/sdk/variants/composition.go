@@ -0,0 +1,461 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ConflictPolicy decides how tools and prompts with the same name in
+// composed variants are handled (see WithComposition).
+type ConflictPolicy string
+
+const (
+	// ConflictFirstWins lists a name once, owned by the first variant of
+	// the composition that lists it.
+	ConflictFirstWins ConflictPolicy = "firstWins"
+
+	// ConflictReject refuses to compose variants that list the same tool
+	// or prompt name.
+	ConflictReject ConflictPolicy = "reject"
+)
+
+// CompositionOptions configures WithComposition.
+type CompositionOptions struct {
+	// Conflict is the policy for names listed by several composed
+	// variants. Default ConflictFirstWins.
+	Conflict ConflictPolicy
+
+	// PageSize is the number of tools or prompts per page of a merged
+	// list. Zero returns merged lists in a single page.
+	PageSize int
+}
+
+// WithComposition lets clients activate a set of variants for their session
+// with the compose _meta key, e.g.
+//
+//	_meta["io.modelcontextprotocol/server-variant-compose"] = ["code-review", "security-readonly"]
+//
+// on any request. The set is remembered for the session (in stateless
+// mode, it applies to the request only); an empty list deactivates it.
+// While a set is active, tools/list and prompts/list return the merged
+// lists of its variants in set order, each tool and prompt carrying its
+// owner in _meta["io.modelcontextprotocol/server-variant"], and
+// tools/call and prompts/get are routed to the owner of the tool or
+// prompt. Other variant-scoped requests go to the set's first variant.
+// Requests that select a variant explicitly or by tags are routed as
+// usual.
+//
+// Activating a set fails with an invalid params error if a variant is
+// unknown or out of rotation, if two variants are alternatives in an
+// exclusive group (see WithVariantGroup), or, under ConflictReject, if two
+// variants list the same tool or prompt name. The initialize payload
+// advertises the policy as composition.conflictPolicy.
+//
+// It panics if opts.PageSize is negative or opts.Conflict is unknown.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithComposition(opts CompositionOptions) *Server {
+	if opts.PageSize < 0 {
+		panic("variants: negative composition page size")
+	}
+	if opts.Conflict == "" {
+		opts.Conflict = ConflictFirstWins
+	}
+	if opts.Conflict != ConflictFirstWins && opts.Conflict != ConflictReject {
+		panic(fmt.Sprintf("variants: unknown conflict policy %q", opts.Conflict))
+	}
+	s.composition = &opts
+	return s
+}
+
+// composeFromMeta extracts the variants a request composes from its _meta
+// field, dropping duplicates. It reports false if the key is absent.
+func composeFromMeta(req mcp.Request) ([]string, bool) {
+	params := req.GetParams()
+	if isNilInterface(params) {
+		return nil, false
+	}
+	raw, ok := params.GetMeta()[metaKeyCompose]
+	if !ok {
+		return nil, false
+	}
+	var values []any
+	switch v := raw.(type) {
+	case []any:
+		values = v
+	case []string:
+		for _, id := range v {
+			values = append(values, id)
+		}
+	}
+	ids := []string{}
+	for _, v := range values {
+		if id, _ := v.(string); id != "" && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, true
+}
+
+// composedVariants returns the variants composed for req: those activated
+// by its compose _meta key, which are remembered for the session, else
+// the session's.
+func (d *dispatcher) composedVariants(ctx context.Context, req mcp.Request) ([]string, error) {
+	ids, ok := composeFromMeta(req)
+	if !ok {
+		d.mu.RLock()
+		defer d.mu.RUnlock()
+		return d.composed, nil
+	}
+	if len(ids) > 0 {
+		if err := d.validateComposition(ctx, req, ids); err != nil {
+			return nil, err
+		}
+	}
+	if !d.shared {
+		d.mu.Lock()
+		d.composed = ids
+		d.composedLists = nil
+		d.mu.Unlock()
+	}
+	return ids, nil
+}
+
+// validateComposition reports whether the variants can be composed for
+// req's client.
+func (d *dispatcher) validateComposition(ctx context.Context, req mcp.Request, ids []string) error {
+	fc := d.requestFlagContext(req)
+	for _, id := range ids {
+		if !d.server.hasVariant(id) || !d.server.flagEnabled(ctx, id, fc) {
+			return d.createInvalidVariantError(ctx, id)
+		}
+		if !d.server.isAvailable(id) {
+			return d.server.unavailableVariantError(id)
+		}
+	}
+	if err := d.server.ValidateSelection(ids); err != nil {
+		return err
+	}
+	if d.server.composition.Conflict != ConflictReject {
+		return nil
+	}
+	conflicts := map[string]map[string][]string{}
+	for _, method := range []string{"tools/list", "prompts/list"} {
+		l, err := d.composedList(ctx, ids, method)
+		if err != nil {
+			return err
+		}
+		if len(l.conflicts) > 0 {
+			conflicts[listKind(method)] = l.conflicts
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	dataJSON, _ := json.Marshal(map[string]any{"conflicts": conflicts})
+	return &jsonrpc.Error{
+		Code:    jsonrpc.CodeInvalidParams,
+		Message: "Server variants conflict",
+		Data:    json.RawMessage(dataJSON),
+	}
+}
+
+// handleComposed routes a request of a session with composed variants. It
+// reports false for requests routed as usual: those selecting a variant,
+// methods that are not variant-scoped, and sessions without composition.
+// Calls are routed by setting their owner as the selected variant.
+func (d *dispatcher) handleComposed(ctx context.Context, method string, req mcp.Request) (mcp.Result, bool, error) {
+	ids, err := d.composedVariants(ctx, req)
+	if err != nil || len(ids) == 0 {
+		return nil, err != nil, err
+	}
+	if variantIDFromMeta(req) != "" || len(variantTagsFromMeta(req)) > 0 {
+		return nil, false, nil
+	}
+	params := req.GetParams()
+	switch method {
+	case "tools/list", "prompts/list":
+		res, err := d.handleComposedList(ctx, method, req, ids)
+		return res, true, err
+	case "tools/call", "prompts/get":
+		owner, err := d.composedOwner(ctx, method, req, ids)
+		if err != nil {
+			return nil, true, err
+		}
+		injectVariantMeta(params, owner)
+	case "resources/list", "resources/templates/list", "resources/read",
+		"resources/subscribe", "resources/unsubscribe", "completion/complete":
+		if !isNilInterface(params) {
+			injectVariantMeta(params, ids[0])
+		}
+	}
+	return nil, false, nil
+}
+
+// composedList is the merged tools or prompts list of composed variants.
+type composedList struct {
+	ids       []string
+	items     []any               // *mcp.Tool or *mcp.Prompt, stamped with their owner
+	owners    map[string]string   // name -> owning variant
+	conflicts map[string][]string // name -> variants listing it, if more than one
+}
+
+// composedList lists the tools or prompts of the composed variants and
+// merges them. Lists are remembered for the session until the next
+// composition or merged list request.
+func (d *dispatcher) composedList(ctx context.Context, ids []string, method string) (*composedList, error) {
+	l := &composedList{ids: ids, owners: map[string]string{}, conflicts: map[string][]string{}}
+	for _, id := range ids {
+		conn, err := d.connection(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		items, err := d.listAll(ctx, conn, method)
+		if err != nil {
+			return nil, enrichError(err, id)
+		}
+		for _, item := range items {
+			name := itemName(item)
+			if owner, ok := l.owners[name]; ok {
+				if len(l.conflicts[name]) == 0 {
+					l.conflicts[name] = []string{owner}
+				}
+				l.conflicts[name] = append(l.conflicts[name], id)
+				continue
+			}
+			l.owners[name] = id
+			l.items = append(l.items, stampOwner(item, id))
+		}
+	}
+	if !d.shared {
+		d.mu.Lock()
+		if d.composedLists == nil {
+			d.composedLists = make(map[string]*composedList)
+		}
+		d.composedLists[method] = l
+		d.mu.Unlock()
+	}
+	return l, nil
+}
+
+// listAll returns all tools or prompts of a variant, following its
+// pagination. Variants without prompts have none.
+func (d *dispatcher) listAll(ctx context.Context, conn *innerConnection, method string) ([]any, error) {
+	variantID := conn.backendSession.variantID
+	var items []any
+	cursor := ""
+	for {
+		var req mcp.Request
+		if method == "tools/list" {
+			req = &mcp.ListToolsRequest{Params: &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: variantID}, Cursor: cursor}}
+		} else {
+			req = &mcp.ListPromptsRequest{Params: &mcp.ListPromptsParams{Meta: mcp.Meta{metaKeyVariant: variantID}, Cursor: cursor}}
+		}
+		res, err := d.receive(ctx, conn, method, req)
+		var jErr *jsonrpc.Error
+		if errors.As(err, &jErr) && jErr.Code == jsonrpc.CodeMethodNotFound {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch res := res.(type) {
+		case *mcp.ListToolsResult:
+			d.server.rewriteTools(ctx, variantID, res)
+			for _, t := range res.Tools {
+				if t != nil {
+					items = append(items, t)
+				}
+			}
+			cursor = res.NextCursor
+		case *mcp.ListPromptsResult:
+			for _, p := range res.Prompts {
+				if p != nil {
+					items = append(items, p)
+				}
+			}
+			cursor = res.NextCursor
+		default:
+			return items, nil
+		}
+		if cursor == "" {
+			return items, nil
+		}
+	}
+}
+
+// handleComposedList returns a page of the merged tools or prompts list of
+// the composed variants.
+func (d *dispatcher) handleComposedList(ctx context.Context, method string, req mcp.Request, ids []string) (mcp.Result, error) {
+	var cursor string
+	switch p := req.GetParams().(type) {
+	case *mcp.ListToolsParams:
+		if p != nil {
+			cursor = p.Cursor
+		}
+	case *mcp.ListPromptsParams:
+		if p != nil {
+			cursor = p.Cursor
+		}
+	}
+	offset, err := unwrapCompositionCursor(cursor, ids)
+	if err != nil {
+		return nil, err
+	}
+	l, err := d.composedList(ctx, ids, method)
+	if err != nil {
+		return nil, err
+	}
+	end := len(l.items)
+	if n := d.server.composition.PageSize; n > 0 {
+		end = min(offset+n, end)
+	}
+	offset = min(offset, end)
+	next := ""
+	if end < len(l.items) {
+		next = wrapCompositionCursor(ids, end)
+	}
+	page := l.items[offset:end]
+	if method == "tools/list" {
+		res := &mcp.ListToolsResult{Tools: make([]*mcp.Tool, len(page)), NextCursor: next}
+		for i, item := range page {
+			res.Tools[i] = item.(*mcp.Tool)
+		}
+		return res, nil
+	}
+	res := &mcp.ListPromptsResult{Prompts: make([]*mcp.Prompt, len(page)), NextCursor: next}
+	for i, item := range page {
+		res.Prompts[i] = item.(*mcp.Prompt)
+	}
+	return res, nil
+}
+
+// composedOwner returns the composed variant owning the tool or prompt req
+// calls. Unknown names go to the first variant, which rejects them.
+func (d *dispatcher) composedOwner(ctx context.Context, method string, req mcp.Request, ids []string) (string, error) {
+	listMethod, name := "tools/list", toolName(req)
+	if get, ok := req.(*mcp.GetPromptRequest); ok && method == "prompts/get" {
+		listMethod, name = "prompts/list", ""
+		if get.Params != nil {
+			name = get.Params.Name
+		}
+	}
+	if owner, ok := d.server.indexedComposedOwner(ids, listMethod, name); ok {
+		return owner, nil
+	}
+	d.mu.RLock()
+	l := d.composedLists[listMethod]
+	d.mu.RUnlock()
+	if l == nil || !slices.Equal(l.ids, ids) || l.owners[name] == "" {
+		var err error
+		if l, err = d.composedList(ctx, ids, listMethod); err != nil {
+			return "", err
+		}
+	}
+	if owner := l.owners[name]; owner != "" {
+		return owner, nil
+	}
+	return ids[0], nil
+}
+
+// itemName returns the name of a tool or prompt.
+func itemName(item any) string {
+	switch item := item.(type) {
+	case *mcp.Tool:
+		return item.Name
+	case *mcp.Prompt:
+		return item.Name
+	}
+	return ""
+}
+
+// stampOwner returns a copy of a tool or prompt naming its owning variant
+// in its _meta.
+func stampOwner(item any, variantID string) any {
+	stamp := func(m mcp.Meta) mcp.Meta {
+		m = maps.Clone(m)
+		if m == nil {
+			m = mcp.Meta{}
+		}
+		m[metaKeyVariant] = variantID
+		return m
+	}
+	switch item := item.(type) {
+	case *mcp.Tool:
+		c := *item
+		c.Meta = stamp(item.Meta)
+		return &c
+	case *mcp.Prompt:
+		c := *item
+		c.Meta = stamp(item.Meta)
+		return &c
+	}
+	return item
+}
+
+// listKind returns the kind of items a list method lists, e.g. "tools".
+func listKind(method string) string {
+	if method == "prompts/list" {
+		return "prompts"
+	}
+	return "tools"
+}
+
+// compositionCursor is the cursor of a merged list page: the composed
+// variants and the offset of the page in their merged list.
+type compositionCursor struct {
+	Variants []string `json:"s"`
+	Offset   int      `json:"o"`
+}
+
+func wrapCompositionCursor(ids []string, offset int) string {
+	data, err := json.Marshal(compositionCursor{Variants: ids, Offset: offset})
+	if err != nil {
+		return ""
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// unwrapCompositionCursor returns the offset of a merged list cursor,
+// failing if it is malformed or belongs to another composition.
+func unwrapCompositionCursor(cursor string, ids []string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	invalid := &jsonrpc.Error{
+		Code:    jsonrpc.CodeInvalidParams,
+		Message: "Invalid cursor format",
+	}
+	data, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, invalid
+	}
+	var c compositionCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Offset <= 0 {
+		return 0, invalid
+	}
+	if !slices.Equal(c.Variants, ids) {
+		dataJSON, _ := json.Marshal(map[string]any{
+			"cursorVariants":    c.Variants,
+			"requestedVariants": ids,
+		})
+		return 0, &jsonrpc.Error{
+			Code:    jsonrpc.CodeInvalidParams,
+			Message: "Cursor invalid for requested variant",
+			Data:    json.RawMessage(dataJSON),
+		}
+	}
+	return c.Offset, nil
+}
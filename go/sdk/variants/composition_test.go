@@ -0,0 +1,138 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newComposableServer returns the test variants plus "overlap", which also
+// lists summarize.
+func newComposableServer(opts CompositionOptions) *Server {
+	overlap := mcp.NewServer(&mcp.Implementation{Name: "overlap-server", Version: "v1.0.0"}, nil)
+	mcp.AddTool(overlap, &mcp.Tool{Name: "summarize", Description: "Other summary"}, summarize)
+	mcp.AddTool(overlap, &mcp.Tool{Name: "translate", Description: "Translate text"}, summarize)
+	return newTestVariantServer().
+		WithVariant(ServerVariant{ID: "overlap", Description: "Overlapping tools"}, overlap, 2).
+		WithComposition(opts)
+}
+
+func composeMeta(ids ...string) mcp.Meta {
+	return mcp.Meta{metaKeyCompose: ids}
+}
+
+func toolOwners(tools []*mcp.Tool) map[string]any {
+	owners := make(map[string]any, len(tools))
+	for _, t := range tools {
+		owners[t.Name] = t.Meta[metaKeyVariant]
+	}
+	return owners
+}
+
+func TestComposition(t *testing.T) {
+	session := connectTestClient(t, newComposableServer(CompositionOptions{}), nil)
+	ctx := context.Background()
+
+	res, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: composeMeta("compact", "overlap")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lookup", "summarize", "translate"}, toolNames(res.Tools))
+	assert.Equal(t, map[string]any{"lookup": "compact", "summarize": "compact", "translate": "overlap"}, toolOwners(res.Tools))
+
+	// The composition is remembered for the session.
+	res, err = session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lookup", "summarize", "translate"}, toolNames(res.Tools))
+
+	// Calls are routed to the owner.
+	call, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "translate", Arguments: map[string]any{"text": "hola"}})
+	require.NoError(t, err)
+	assert.False(t, call.IsError)
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "lookup", Arguments: map[string]any{"query": "x"}})
+	require.NoError(t, err)
+
+	// An explicit selection overrides the composition.
+	res, err = session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: "coding"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"analyze_code", "refactor"}, toolNames(res.Tools))
+
+	// An empty composition deactivates it.
+	res, err = session.ListTools(ctx, &mcp.ListToolsParams{Meta: composeMeta()})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"analyze_code", "refactor"}, toolNames(res.Tools))
+}
+
+func TestComposition_Pagination(t *testing.T) {
+	session := connectTestClient(t, newComposableServer(CompositionOptions{PageSize: 3}), nil)
+	ctx := context.Background()
+
+	res, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: composeMeta("coding", "compact")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"analyze_code", "refactor", "lookup"}, toolNames(res.Tools))
+	require.NotEmpty(t, res.NextCursor)
+	cursor := res.NextCursor
+
+	res, err = session.ListTools(ctx, &mcp.ListToolsParams{Cursor: cursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"summarize"}, toolNames(res.Tools))
+	assert.Empty(t, res.NextCursor)
+
+	// Cursors are scoped to the composition.
+	_, err = session.ListTools(ctx, &mcp.ListToolsParams{Meta: composeMeta("coding", "overlap"), Cursor: cursor})
+	var rpcErr *jsonrpc.Error
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, "Cursor invalid for requested variant", rpcErr.Message)
+}
+
+func TestComposition_ConflictReject(t *testing.T) {
+	session := connectTestClient(t, newComposableServer(CompositionOptions{Conflict: ConflictReject}), nil)
+	ctx := context.Background()
+
+	_, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: composeMeta("compact", "overlap")})
+	var rpcErr *jsonrpc.Error
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, "Server variants conflict", rpcErr.Message)
+	var data struct {
+		Conflicts map[string]map[string][]string `json:"conflicts"`
+	}
+	require.NoError(t, json.Unmarshal(rpcErr.Data, &data))
+	assert.Equal(t, map[string]map[string][]string{"tools": {"summarize": {"compact", "overlap"}}}, data.Conflicts)
+
+	res, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: composeMeta("coding", "compact")})
+	require.NoError(t, err)
+	assert.Len(t, res.Tools, 4)
+}
+
+func TestComposition_Validation(t *testing.T) {
+	vs := newComposableServer(CompositionOptions{}).
+		WithVariantGroup(VariantGroup{ID: "generation", Exclusive: true, Variants: []string{"compact", "overlap"}})
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	_, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: composeMeta("compact", "overlap")})
+	assert.ErrorContains(t, err, "mutually exclusive")
+	_, err = session.ListTools(ctx, &mcp.ListToolsParams{Meta: composeMeta("coding", "ghost")})
+	assert.ErrorContains(t, err, "Invalid server variant")
+
+	// Failed activations leave the session uncomposed.
+	res, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"analyze_code", "refactor"}, toolNames(res.Tools))
+
+	assert.Panics(t, func() { newTestVariantServer().WithComposition(CompositionOptions{PageSize: -1}) })
+	assert.Panics(t, func() { newTestVariantServer().WithComposition(CompositionOptions{Conflict: "merge"}) })
+}
+
+func TestComposition_Advertised(t *testing.T) {
+	var payload map[string]any
+	initExtension(t, connectTestClient(t, newComposableServer(CompositionOptions{Conflict: ConflictReject}), nil), &payload)
+	assert.Equal(t, map[string]any{"conflictPolicy": "reject"}, payload["composition"])
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// ClientKeyFunc returns a stable identifier for the client described by rr,
+// such as the authenticated user from rr.TokenInfo or an installation ID
+// from rr.Header. It returns "" if the client cannot be identified.
+type ClientKeyFunc func(ctx context.Context, rr *RankingRequest) string
+
+// WithConsistentDefault makes the choice among equally ranked variants
+// deterministic per client, for A/B experiments that need the same client
+// to land on the same variant across sessions. When the leading variants of
+// a session's ranking tie — same priority, status, and score — the one
+// chosen by consistent hashing of the key returned by fn is moved to the
+// front and so becomes the session's default. Clients without a key keep
+// the ranking order.
+//
+// The hashing is rendezvous hashing: adding or removing a tied variant
+// only moves the clients that land on it. The choice is made at initialize
+// and on hint updates, so it has no effect in stateless mode; a variant
+// restored from a variant token or pinned with WithDefaultVariant takes
+// precedence.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithConsistentDefault(fn ClientKeyFunc) *Server {
+	s.clientKey = fn
+	return s
+}
+
+// spreadTies moves the variant chosen for the client among the tied
+// leaders of ranked to the front. See WithConsistentDefault.
+func (s *Server) spreadTies(ctx context.Context, ranked []ServerVariant) []ServerVariant {
+	if s.clientKey == nil || len(ranked) < 2 {
+		return ranked
+	}
+	rr, ok := RankingRequestFromContext(ctx)
+	if !ok {
+		rr = &RankingRequest{}
+	}
+	key := s.clientKey(ctx, rr)
+	if key == "" {
+		return ranked
+	}
+
+	lead := ranked[0]
+	best, bestWeight := 0, rendezvousWeight(key, lead.ID)
+	for i := 1; i < len(ranked) && tied(lead, ranked[i]); i++ {
+		if w := rendezvousWeight(key, ranked[i].ID); w > bestWeight {
+			best, bestWeight = i, w
+		}
+	}
+	if best == 0 {
+		return ranked
+	}
+	out := make([]ServerVariant, 0, len(ranked))
+	out = append(out, ranked[best])
+	out = append(out, ranked[:best]...)
+	return append(out, ranked[best+1:]...)
+}
+
+// tied reports whether a and b are ranked equally.
+func tied(a, b ServerVariant) bool {
+	return a.Priority() == b.Priority() && statusWeight(a.Status) == statusWeight(b.Status) && a.Score == b.Score
+}
+
+// rendezvousWeight returns the rendezvous hashing weight of a variant for a
+// client key.
+func rendezvousWeight(key, variantID string) uint64 {
+	sum := sha256.Sum256([]byte(key + "\x00" + variantID))
+	return binary.BigEndian.Uint64(sum[:8])
+}
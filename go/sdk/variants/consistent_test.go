@@ -0,0 +1,67 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+// newExperimentServer returns a server with two tied variants and a
+// lower-priority fallback, keyed by the client's name.
+func newExperimentServer() *Server {
+	coding, compact := newTestServers()
+	fallback, _ := newTestServers()
+	return NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "arm-a"}, coding, 0).
+		WithVariant(ServerVariant{ID: "arm-b"}, compact, 0).
+		WithVariant(ServerVariant{ID: "fallback"}, fallback, 1).
+		WithConsistentDefault(func(ctx context.Context, rr *RankingRequest) string {
+			return rr.ClientInfo.Name
+		})
+}
+
+func TestSpreadTies(t *testing.T) {
+	vs := newExperimentServer()
+	ranked := vs.RankedVariants(context.Background(), VariantHints{})
+	spread := func(key string) []ServerVariant {
+		ctx := context.WithValue(context.Background(), rankingRequestKey{}, &RankingRequest{
+			ClientInfo: &mcp.Implementation{Name: key},
+		})
+		return vs.spreadTies(ctx, ranked)
+	}
+
+	counts := map[string]int{}
+	for i := range 200 {
+		key := fmt.Sprintf("user-%d", i)
+		got := spread(key)
+		assert.Equal(t, got, spread(key), "choice must be stable per key")
+		assert.Equal(t, "fallback", got[2].ID, "untied variants keep their place")
+		counts[got[0].ID]++
+	}
+	assert.Greater(t, counts["arm-a"], 50)
+	assert.Greater(t, counts["arm-b"], 50)
+
+	assert.Equal(t, ranked, spread(""), "clients without a key keep the ranking order")
+}
+
+func TestIntegration_ConsistentDefault(t *testing.T) {
+	// connectTestClient identifies as "test-client".
+	want := "arm-a"
+	if rendezvousWeight("test-client", "arm-b") > rendezvousWeight("test-client", "arm-a") {
+		want = "arm-b"
+	}
+	for range 2 {
+		var payload struct {
+			DefaultVariant string `json:"defaultVariant"`
+		}
+		initExtension(t, connectTestClient(t, newExperimentServer(), hintsClientOptions(nil)), &payload)
+		assert.Equal(t, want, payload.DefaultVariant)
+	}
+}
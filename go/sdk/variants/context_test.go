@@ -94,7 +94,7 @@ func TestContextPreservation(t *testing.T) {
 			Status:      Stable,
 		}, newContextTestInnerServer(), 0)
 
-	frontServer, err := vs.mcpServer(false)
+	frontServer, err := vs.mcpServer(TransportOther, false)
 	require.NoError(t, err)
 
 	frontServer.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
@@ -0,0 +1,77 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync/atomic"
+)
+
+// Correlation identifies a request dispatched to a variant, so that logs and
+// traces from the front server and the variant's server can be joined.
+//
+// The SDK does not expose JSON-RPC request IDs to middleware, and requests
+// are dispatched to in-memory variants without an inner JSON-RPC request,
+// so the IDs are assigned by the variant server instead.
+type Correlation struct {
+	// RequestID identifies the front request. All dispatches it spawns
+	// (e.g. the calls of a fan-out) share it.
+	RequestID string
+
+	// DispatchID identifies one dispatch to a variant. It is RequestID
+	// followed by a per-request sequence number, e.g. "9f86d081884c7d65.2".
+	DispatchID string
+}
+
+// CorrelationFromContext returns the correlation IDs of the request being
+// dispatched. It is available to variant servers' handlers, interceptors and
+// event handlers. The DispatchID is empty before the request is dispatched.
+func CorrelationFromContext(ctx context.Context) (Correlation, bool) {
+	if c, ok := ctx.Value(correlationKey{}).(Correlation); ok {
+		return c, true
+	}
+	if rc, ok := ctx.Value(requestCorrelationKey{}).(*requestCorrelation); ok {
+		return Correlation{RequestID: rc.id}, true
+	}
+	return Correlation{}, false
+}
+
+// correlationKey is the context key for the Correlation of a dispatch.
+type correlationKey struct{}
+
+// requestCorrelationKey is the context key for the *requestCorrelation of a
+// front request.
+type requestCorrelationKey struct{}
+
+// requestCorrelation numbers the dispatches of a front request.
+type requestCorrelation struct {
+	id string
+	n  atomic.Int64
+}
+
+// withRequestCorrelation assigns a new request ID to ctx unless it already
+// has one.
+func withRequestCorrelation(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(requestCorrelationKey{}).(*requestCorrelation); ok {
+		return ctx
+	}
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return context.WithValue(ctx, requestCorrelationKey{}, &requestCorrelation{id: hex.EncodeToString(b[:])})
+}
+
+// withDispatchCorrelation assigns a new dispatch ID to ctx, within the
+// front request's correlation.
+func withDispatchCorrelation(ctx context.Context) context.Context {
+	ctx = withRequestCorrelation(ctx)
+	rc := ctx.Value(requestCorrelationKey{}).(*requestCorrelation)
+	return context.WithValue(ctx, correlationKey{}, Correlation{
+		RequestID:  rc.id,
+		DispatchID: rc.id + "." + strconv.FormatInt(rc.n.Add(1), 10),
+	})
+}
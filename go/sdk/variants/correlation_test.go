@@ -0,0 +1,75 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelation(t *testing.T) {
+	var mu sync.Mutex
+	var inner, intercepted, dispatched []Correlation
+	record := func(dst *[]Correlation, c Correlation) {
+		mu.Lock()
+		defer mu.Unlock()
+		*dst = append(*dst, c)
+	}
+
+	newServer := func(version string) *mcp.Server {
+		s := mcp.NewServer(&mcp.Implementation{Name: "versioned", Version: version}, nil)
+		mcp.AddTool(s, &mcp.Tool{Name: "version"},
+			func(ctx context.Context, _ *mcp.CallToolRequest, _ emptyInput) (*mcp.CallToolResult, versionOutput, error) {
+				c, _ := CorrelationFromContext(ctx)
+				record(&inner, c)
+				return nil, versionOutput{Version: version}, nil
+			})
+		return s
+	}
+	vs := NewServer(&mcp.Implementation{Name: "fanout", Version: "v1.0.0"}).
+		WithVariant(ServerVariant{ID: "v2"}, newServer("v2"), 0).
+		WithVariant(ServerVariant{ID: "v3"}, newServer("v3"), 1).
+		WithFanOut(true).
+		WithDispatchInterceptor(func(ctx context.Context, info DispatchInfo, next DispatchHandler) (mcp.Result, error) {
+			record(&intercepted, info.Correlation)
+			return next(ctx)
+		}).
+		WithEventHandler(func(_ context.Context, e Event) {
+			if e.Kind == EventVariantDispatched {
+				record(&dispatched, e.Correlation)
+			}
+		})
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	_, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "version",
+		Meta:      mcp.Meta{metaKeyFanOut: []any{"v2", "v3"}},
+		Arguments: map[string]any{},
+	})
+	require.NoError(t, err)
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "version", Arguments: map[string]any{}})
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, inner, 3)
+	assert.ElementsMatch(t, inner, intercepted)
+	assert.ElementsMatch(t, inner, dispatched)
+
+	// The fan-out's dispatches share a request ID; the later call has its own.
+	fanOut := inner[:2]
+	assert.NotEmpty(t, fanOut[0].RequestID)
+	assert.Equal(t, fanOut[0].RequestID, fanOut[1].RequestID)
+	assert.ElementsMatch(t, []string{fanOut[0].RequestID + ".1", fanOut[0].RequestID + ".2"},
+		[]string{fanOut[0].DispatchID, fanOut[1].DispatchID})
+	assert.NotEqual(t, fanOut[0].RequestID, inner[2].RequestID)
+	assert.Equal(t, inner[2].RequestID+".1", inner[2].DispatchID)
+}
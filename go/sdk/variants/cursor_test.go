@@ -0,0 +1,133 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cursorString generates arbitrary strings for cursor properties: unicode
+// text, arbitrary bytes (including invalid UTF-8), and long strings.
+type cursorString string
+
+func (cursorString) Generate(r *rand.Rand, size int) reflect.Value {
+	var s string
+	switch r.Intn(4) {
+	case 0:
+		v, _ := quick.Value(reflect.TypeOf(""), r)
+		s = v.String()
+	case 1:
+		b := make([]byte, r.Intn(size+1))
+		r.Read(b)
+		s = string(b)
+	case 2:
+		runes := []rune{'a', 'é', '世', '🙂', '"', '\\', '\x00', ' ', '�'}
+		var sb strings.Builder
+		for range r.Intn(20 * size) {
+			sb.WriteRune(runes[r.Intn(len(runes))])
+		}
+		s = sb.String()
+	default:
+		s = strings.Repeat("page-", r.Intn(5000))
+	}
+	return reflect.ValueOf(cursorString(s))
+}
+
+// variantIDString generates variant IDs: unicode text, as configured by
+// server authors.
+type variantIDString string
+
+func (variantIDString) Generate(r *rand.Rand, size int) reflect.Value {
+	v, _ := quick.Value(reflect.TypeOf(""), r)
+	return reflect.ValueOf(variantIDString(v.String()))
+}
+
+var cursorQuickConfig = &quick.Config{MaxCount: 2000}
+
+func isInvalidParams(err error) bool {
+	var jErr *jsonrpc.Error
+	return errors.As(err, &jErr) && jErr.Code == jsonrpc.CodeInvalidParams
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	roundTrip := func(inner cursorString, id variantIDString) bool {
+		got, err := unwrapCursor(wrapCursor(string(inner), string(id)), string(id))
+		return err == nil && got == string(inner)
+	}
+	require.NoError(t, quick.Check(roundTrip, cursorQuickConfig))
+}
+
+func TestCursor_Empty(t *testing.T) {
+	empty := func(id variantIDString) bool {
+		got, err := unwrapCursor(wrapCursor("", string(id)), string(id))
+		return wrapCursor("", string(id)) == "" && err == nil && got == ""
+	}
+	require.NoError(t, quick.Check(empty, cursorQuickConfig))
+}
+
+// TestCursor_Opaque verifies that wrapped cursors are transport-safe ASCII.
+func TestCursor_Opaque(t *testing.T) {
+	opaque := func(inner cursorString, id variantIDString) bool {
+		if inner == "" {
+			return true
+		}
+		w := wrapCursor(string(inner), string(id))
+		_, err := base64.StdEncoding.DecodeString(w)
+		return w != "" && err == nil
+	}
+	require.NoError(t, quick.Check(opaque, cursorQuickConfig))
+}
+
+func TestCursor_RejectsOtherVariant(t *testing.T) {
+	rejects := func(inner cursorString, id, other variantIDString) bool {
+		if inner == "" || id == other {
+			return true
+		}
+		_, err := unwrapCursor(wrapCursor(string(inner), string(id)), string(other))
+		if !isInvalidParams(err) {
+			return false
+		}
+		var data map[string]string
+		var jErr *jsonrpc.Error
+		errors.As(err, &jErr)
+		return json.Unmarshal(jErr.Data, &data) == nil &&
+			data["cursorVariant"] == string(id) && data["requestedVariant"] == string(other)
+	}
+	require.NoError(t, quick.Check(rejects, cursorQuickConfig))
+}
+
+// TestCursor_RejectsForeign verifies that arbitrary client-supplied cursors
+// either fail with invalid params or unwrap to a non-empty inner cursor,
+// and never panic.
+func TestCursor_RejectsForeign(t *testing.T) {
+	foreign := func(cursor cursorString, id variantIDString) bool {
+		if cursor == "" {
+			return true
+		}
+		got, err := unwrapCursor(string(cursor), string(id))
+		if err != nil {
+			return isInvalidParams(err)
+		}
+		return got != ""
+	}
+	require.NoError(t, quick.Check(foreign, cursorQuickConfig))
+
+	// Well-formed encodings of degenerate payloads.
+	for _, payload := range []string{`null`, `{}`, `{"v":"x"}`, `{"v":"x","c":""}`, `{"v":"x","c":"a","b":"Yg=="}`, `[]`, `"x"`} {
+		_, err := unwrapCursor(base64.StdEncoding.EncodeToString([]byte(payload)), "x")
+		assert.True(t, isInvalidParams(err), payload)
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CustomHint returns the key of a hint outside the common vocabulary,
+// namespaced by a reverse-DNS prefix the caller controls, e.g.
+//
+//	variants.CustomHint("com.example", "apiGeneration") // "com.example/apiGeneration"
+//
+// so that it cannot collide with keys defined by the extension or by other
+// servers. Panics if the key is invalid (see ParseCustomHint).
+func CustomHint(namespace, name string) string {
+	key := namespace + "/" + name
+	if _, _, err := ParseCustomHint(key); err != nil {
+		panic(err.Error())
+	}
+	return key
+}
+
+// ParseCustomHint splits a custom hint key into its namespace and name.
+// The namespace must be a reverse-DNS name of at least two dot-separated
+// labels of letters, digits and hyphens, and the name must be non-empty
+// and free of slashes and whitespace.
+func ParseCustomHint(key string) (namespace, name string, err error) {
+	namespace, name, ok := strings.Cut(key, "/")
+	if !ok {
+		return "", "", fmt.Errorf("variants: custom hint key %q is not namespaced", key)
+	}
+	if !isReverseDNS(namespace) {
+		return "", "", fmt.Errorf("variants: custom hint key %q: namespace %q is not a reverse-DNS name", key, namespace)
+	}
+	if name == "" || strings.ContainsAny(name, "/ \t\r\n") {
+		return "", "", fmt.Errorf("variants: custom hint key %q: invalid name %q", key, name)
+	}
+	return namespace, name, nil
+}
+
+// isReverseDNS reports whether s is a name like "com.example".
+func isReverseDNS(s string) bool {
+	labels := strings.Split(s, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, l := range labels {
+		if l == "" || strings.HasPrefix(l, "-") || strings.HasSuffix(l, "-") {
+			return false
+		}
+		for _, r := range l {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsWellKnownHint reports whether key belongs to the Common Hint Vocabulary
+// defined by the SEP (see HintModelFamily and the related constants).
+func IsWellKnownHint(key string) bool {
+	return wellKnownHintKeys[key]
+}
+
+// PartitionHints splits hints, either a ServerVariant's or a client's
+// VariantHints.Hints, into those of the Common Hint Vocabulary and the
+// others. Either result is nil if it would be empty.
+func PartitionHints[V any](hints map[string]V) (wellKnown, custom map[string]V) {
+	for k, v := range hints {
+		dst := &custom
+		if wellKnownHintKeys[k] {
+			dst = &wellKnown
+		}
+		if *dst == nil {
+			*dst = make(map[string]V)
+		}
+		(*dst)[k] = v
+	}
+	return wellKnown, custom
+}
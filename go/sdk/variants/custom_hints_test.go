@@ -0,0 +1,61 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomHint(t *testing.T) {
+	assert.Equal(t, "com.example/apiGeneration", CustomHint("com.example", "apiGeneration"))
+	assert.Equal(t, "io.my-co.trading/tier", CustomHint("io.my-co.trading", "tier"))
+
+	for _, tc := range []struct{ namespace, name string }{
+		{"", "tier"},
+		{"example", "tier"},
+		{"com..example", "tier"},
+		{"com.-example", "tier"},
+		{"com.exa mple", "tier"},
+		{"com/example", "tier"},
+		{"com.example", ""},
+		{"com.example", "a/b"},
+		{"com.example", "a b"},
+	} {
+		assert.Panics(t, func() { CustomHint(tc.namespace, tc.name) }, "%q, %q", tc.namespace, tc.name)
+	}
+}
+
+func TestParseCustomHint(t *testing.T) {
+	namespace, name, err := ParseCustomHint("com.example/apiGeneration")
+	require.NoError(t, err)
+	assert.Equal(t, "com.example", namespace)
+	assert.Equal(t, "apiGeneration", name)
+
+	_, _, err = ParseCustomHint(HintContextSize)
+	assert.ErrorContains(t, err, "not namespaced")
+	_, _, err = ParseCustomHint("example/tier")
+	assert.ErrorContains(t, err, "not a reverse-DNS name")
+}
+
+func TestPartitionHints(t *testing.T) {
+	wellKnown, custom := PartitionHints(map[string]string{
+		HintContextSize:    "compact",
+		HintUseCase:        "ide",
+		"com.example/tier": "pro",
+		"legacy":           "x",
+	})
+	assert.Equal(t, map[string]string{HintContextSize: "compact", HintUseCase: "ide"}, wellKnown)
+	assert.Equal(t, map[string]string{"com.example/tier": "pro", "legacy": "x"}, custom)
+
+	wellKnown2, custom2 := PartitionHints(map[string]any{HintModelFamily: []string{"anthropic", "openai"}})
+	assert.Equal(t, map[string]any{HintModelFamily: []string{"anthropic", "openai"}}, wellKnown2)
+	assert.Nil(t, custom2)
+
+	assert.True(t, IsWellKnownHint(HintLanguageOptimization))
+	assert.False(t, IsWellKnownHint("com.example/tier"))
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// confirmDeprecated asks the user, via elicitation, to confirm the use of a
+// deprecated variant the client explicitly selected. It is a no-op unless
+// enabled with Server.WithDeprecationConfirmation, the dispatcher belongs to
+// a single session, the variant is Deprecated, and the front client supports
+// elicitation.
+//
+// The answer is remembered for the rest of the session, so the prompt is
+// shown at most once per variant; a cancelled prompt is asked again on the
+// next request. A declined prompt fails the request with an InvalidParams
+// error carrying the replacement, if any.
+func (d *dispatcher) confirmDeprecated(ctx context.Context, req mcp.Request, variantID string) error {
+	if !d.server.confirmDeprecated || d.shared {
+		return nil
+	}
+	v, ok := d.server.lookupVariant(variantID)
+	if !ok || v.Status != Deprecated {
+		return nil
+	}
+	ss, _ := req.GetSession().(*mcp.ServerSession)
+	if ss == nil || !supportsElicitation(ss) {
+		return nil
+	}
+
+	accepted, err := d.awaitConfirmation(ctx, ss, v)
+	if err != nil {
+		return err
+	}
+	if accepted {
+		return nil
+	}
+
+	data := map[string]any{"requestedVariant": variantID}
+	if v.DeprecationInfo != nil && v.DeprecationInfo.Replacement != "" {
+		data["replacement"] = v.DeprecationInfo.Replacement
+	}
+	dataJSON, _ := json.Marshal(data)
+	return &jsonrpc.Error{
+		Code:    jsonrpc.CodeInvalidParams,
+		Message: "Use of deprecated server variant was not confirmed",
+		Data:    json.RawMessage(dataJSON),
+	}
+}
+
+// awaitConfirmation returns the user's answer to the confirmation prompt
+// for a deprecated variant, prompting for it unless it was given before.
+// Concurrent first requests for the variant wait for one prompt, without
+// holding confirmMu while the user answers. A cancelled prompt is not
+// accepted, and not remembered.
+func (d *dispatcher) awaitConfirmation(ctx context.Context, ss *mcp.ServerSession, v ServerVariant) (bool, error) {
+	for {
+		d.confirmMu.Lock()
+		if accepted, decided := d.confirmed[v.ID]; decided {
+			d.confirmMu.Unlock()
+			return accepted, nil
+		}
+		if done, ok := d.confirming[v.ID]; ok {
+			d.confirmMu.Unlock()
+			select {
+			case <-done:
+				continue
+			case <-ctx.Done():
+				return false, ctx.Err()
+			}
+		}
+		done := make(chan struct{})
+		if d.confirming == nil {
+			d.confirming = make(map[string]chan struct{})
+		}
+		d.confirming[v.ID] = done
+		d.confirmMu.Unlock()
+
+		res, err := ss.Elicit(ctx, &mcp.ElicitParams{
+			Message:         deprecationPrompt(v),
+			RequestedSchema: map[string]any{"type": "object", "properties": map[string]any{}},
+		})
+
+		d.confirmMu.Lock()
+		delete(d.confirming, v.ID)
+		close(done)
+		var accepted bool
+		if err == nil && (res.Action == "accept" || res.Action == "decline") {
+			accepted = res.Action == "accept"
+			if d.confirmed == nil {
+				d.confirmed = make(map[string]bool)
+			}
+			d.confirmed[v.ID] = accepted
+		}
+		d.confirmMu.Unlock()
+		return accepted, err
+	}
+}
+
+// supportsElicitation reports whether the front client advertised the
+// elicitation capability during initialize.
+func supportsElicitation(ss *mcp.ServerSession) bool {
+	params := ss.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Elicitation != nil
+}
+
+// deprecationPrompt builds the elicitation message for a deprecated variant.
+func deprecationPrompt(v ServerVariant) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Server variant %q is deprecated.", v.ID)
+	if info := v.DeprecationInfo; info != nil {
+		if info.Message != "" {
+			b.WriteString(" " + info.Message)
+		}
+		if info.Replacement != "" {
+			fmt.Fprintf(&b, " Consider switching to %q.", info.Replacement)
+		}
+		if info.RemovalDate != "" {
+			fmt.Fprintf(&b, " It will be removed on %s.", info.RemovalDate)
+		}
+	}
+	b.WriteString(" Continue?")
+	return b.String()
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDeprecatedVariantServer() *Server {
+	coding, compact := newTestServers()
+	return NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "coding", Status: Stable}, coding, 0).
+		WithVariant(ServerVariant{
+			ID:     "legacy",
+			Status: Deprecated,
+			DeprecationInfo: &DeprecationInfo{
+				Message:     "Superseded by coding.",
+				Replacement: "coding",
+			},
+		}, compact, 1).
+		WithDeprecationConfirmation(true)
+}
+
+func TestDeprecationConfirmation(t *testing.T) {
+	ctx := context.Background()
+	legacy := &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: "legacy"}}
+
+	t.Run("accept", func(t *testing.T) {
+		var prompts atomic.Int32
+		session := connectTestClient(t, newDeprecatedVariantServer(), &mcp.ClientOptions{
+			ElicitationHandler: func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+				prompts.Add(1)
+				assert.Contains(t, req.Params.Message, `"legacy" is deprecated`)
+				assert.Contains(t, req.Params.Message, `"coding"`)
+				return &mcp.ElicitResult{Action: "accept", Content: map[string]any{}}, nil
+			},
+		})
+
+		for range 2 {
+			tools, err := session.ListTools(ctx, legacy)
+			require.NoError(t, err)
+			assert.Contains(t, toolNames(tools.Tools), "summarize")
+		}
+		assert.EqualValues(t, 1, prompts.Load(), "confirmation should be asked once per session")
+
+		// Non-deprecated variants never prompt.
+		_, err := session.ListTools(ctx, nil)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, prompts.Load())
+	})
+
+	t.Run("decline", func(t *testing.T) {
+		session := connectTestClient(t, newDeprecatedVariantServer(), &mcp.ClientOptions{
+			ElicitationHandler: func(context.Context, *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+				return &mcp.ElicitResult{Action: "decline"}, nil
+			},
+		})
+
+		_, err := session.ListTools(ctx, legacy)
+		require.Error(t, err)
+		var jErr *jsonrpc.Error
+		require.True(t, errors.As(err, &jErr))
+		assert.Contains(t, jErr.Message, "not confirmed")
+		assert.Contains(t, string(jErr.Data), `"replacement":"coding"`)
+	})
+
+	t.Run("concurrent prompts", func(t *testing.T) {
+		coding, _ := newTestServers()
+		vs := newDeprecatedVariantServer().
+			WithVariant(ServerVariant{ID: "old", Status: Deprecated}, coding, 2)
+		answer := make(chan struct{})
+		var prompts atomic.Int32
+		session := connectTestClient(t, vs, &mcp.ClientOptions{
+			ElicitationHandler: func(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+				prompts.Add(1)
+				if strings.Contains(req.Params.Message, `"legacy"`) {
+					select {
+					case <-answer:
+					case <-ctx.Done():
+						return nil, ctx.Err()
+					}
+				}
+				return &mcp.ElicitResult{Action: "accept", Content: map[string]any{}}, nil
+			},
+		})
+
+		errs := make(chan error, 2)
+		for range 2 {
+			go func() {
+				_, err := session.ListTools(ctx, legacy)
+				errs <- err
+			}()
+		}
+		require.Eventually(t, func() bool { return prompts.Load() == 1 }, 5*time.Second, time.Millisecond)
+
+		// While the user has yet to answer for legacy, other deprecated
+		// variants are not held up.
+		_, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: "old"}})
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, prompts.Load())
+
+		close(answer)
+		for range 2 {
+			assert.NoError(t, <-errs)
+		}
+		assert.EqualValues(t, 2, prompts.Load(), "concurrent requests should share one prompt")
+	})
+
+	t.Run("client without elicitation", func(t *testing.T) {
+		session := connectTestClient(t, newDeprecatedVariantServer(), nil)
+		_, err := session.ListTools(ctx, legacy)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DeriveReadOnly returns a server exposing only those tools of base that
+// are annotated as read-only (readOnlyHint) or explicitly non-destructive
+// (destructiveHint false), for registering as an "analysis-only" variant
+// without curating its tools by hand. Tools without annotations are
+// excluded, since MCP treats them as potentially destructive.
+//
+// Tool calls are forwarded to base's handlers with the caller's context.
+// The tool set is captured when DeriveReadOnly is called; base's resources
+// and prompts are not included. The derived server holds a session with
+// base until the returned stop function is called, after which its tool
+// calls fail; call it once the derived server is no longer served.
+func DeriveReadOnly(base *mcp.Server) (derived *mcp.Server, stop func(), err error) {
+	ctx := context.Background()
+	st, ct := mcp.NewInMemoryTransports()
+	handler := captureReceivingMethodHandler(base)
+	ss, err := base.Connect(ctx, st, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("variants: connecting to base server: %w", err)
+	}
+	// The client completes the initialize handshake; it must stay open for
+	// the lifetime of ss.
+	client := mcp.NewClient(&mcp.Implementation{Name: "variant-derive-client", Version: "1.0.0"}, nil)
+	cs, err := client.Connect(ctx, ct, nil)
+	if err != nil {
+		ss.Close()
+		return nil, nil, fmt.Errorf("variants: connecting to base server: %w", err)
+	}
+
+	var tools []*mcp.Tool
+	for tool, err := range cs.Tools(ctx, nil) {
+		if err != nil {
+			cs.Close()
+			ss.Close()
+			return nil, nil, fmt.Errorf("variants: listing base server tools: %w", err)
+		}
+		if isReadOnlyTool(tool) {
+			tools = append(tools, tool)
+		}
+	}
+
+	info := cs.InitializeResult()
+	derived = mcp.NewServer(info.ServerInfo, &mcp.ServerOptions{Instructions: info.Instructions})
+	bs := &backendSession{serverSession: ss, mcpMethodHandler: handler}
+	var stopped atomic.Bool
+	for _, tool := range tools {
+		derived.AddTool(tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if stopped.Load() {
+				return nil, errors.New("variants: derived server stopped")
+			}
+			res, err := bs.handleReceive(ctx, "tools/call", req)
+			if err != nil {
+				return nil, err
+			}
+			r, ok := res.(*mcp.CallToolResult)
+			if !ok || r == nil {
+				return nil, fmt.Errorf("variants: base server returned %T for tools/call %s", res, req.Params.Name)
+			}
+			return r, nil
+		})
+	}
+	stop = func() {
+		stopped.Store(true)
+		cs.Close()
+		ss.Close()
+	}
+	return derived, stop, nil
+}
+
+// isReadOnlyTool reports whether the tool's annotations mark it read-only
+// or non-destructive.
+func isReadOnlyTool(t *mcp.Tool) bool {
+	a := t.Annotations
+	if a == nil {
+		return false
+	}
+	return a.ReadOnlyHint || (a.DestructiveHint != nil && !*a.DestructiveHint)
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveReadOnly(t *testing.T) {
+	no, yes := false, true
+	base := mcp.NewServer(&mcp.Implementation{Name: "github", Version: "v1.0.0"}, nil)
+	mcp.AddTool(base, &mcp.Tool{Name: "get_issue", Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}}, lookup)
+	mcp.AddTool(base, &mcp.Tool{Name: "add_label", Annotations: &mcp.ToolAnnotations{DestructiveHint: &no}}, lookup)
+	mcp.AddTool(base, &mcp.Tool{Name: "delete_repo", Annotations: &mcp.ToolAnnotations{DestructiveHint: &yes}}, lookup)
+	mcp.AddTool(base, &mcp.Tool{Name: "unannotated"}, lookup)
+
+	readOnly, stop, err := DeriveReadOnly(base)
+	require.NoError(t, err)
+	defer stop()
+
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "full"}, base, 0).
+		WithVariant(ServerVariant{ID: "read-only"}, readOnly, 1)
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+	meta := mcp.Meta{metaKeyVariant: "read-only"}
+
+	res, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: meta})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"get_issue", "add_label"}, toolNames(res.Tools))
+
+	call, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "get_issue",
+		Meta:      meta,
+		Arguments: map[string]any{"query": "42"},
+	})
+	require.NoError(t, err)
+	assert.False(t, call.IsError)
+	assert.NotNil(t, call.StructuredContent)
+
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "delete_repo",
+		Meta:      meta,
+		Arguments: map[string]any{"query": "42"},
+	})
+	assert.Error(t, err)
+}
+
+func TestDeriveReadOnly_Stop(t *testing.T) {
+	base := mcp.NewServer(&mcp.Implementation{Name: "github", Version: "v1.0.0"}, nil)
+	mcp.AddTool(base, &mcp.Tool{Name: "get_issue", Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}}, lookup)
+	readOnly, stop, err := DeriveReadOnly(base)
+	require.NoError(t, err)
+
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "read-only"}, readOnly, 0)
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+	params := &mcp.CallToolParams{Name: "get_issue", Arguments: map[string]any{"query": "42"}}
+	_, err = session.CallTool(ctx, params)
+	require.NoError(t, err)
+
+	stop()
+	_, err = session.CallTool(ctx, params)
+	assert.ErrorContains(t, err, "derived server stopped")
+}
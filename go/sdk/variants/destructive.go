@@ -0,0 +1,132 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// WithDestructiveConfirmation enables or disables dry runs of destructive
+// tools on Experimental variants, to limit the blast radius of preview
+// variants evaluated by autonomous agents. When enabled, a tools/call for a
+// destructive tool of an Experimental variant is only forwarded if the
+// request confirms it with
+//
+//	_meta["io.modelcontextprotocol/server-variant-confirm"] = true
+//
+// or, failing that, the user accepts an elicitation prompt (stateful mode,
+// clients supporting elicitation). Otherwise the call is not run and
+// returns an error result explaining how to confirm it. Disabled by
+// default.
+//
+// A tool is destructive unless its annotations, after any
+// WithAnnotationOverride, mark it read-only or set destructiveHint to
+// false; tools without annotations are destructive, as in the MCP spec.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithDestructiveConfirmation(enabled bool) *Server {
+	s.confirmDestructive = enabled
+	return s
+}
+
+// guardDestructive decides whether a tools/call may be forwarded to conn's
+// variant under WithDestructiveConfirmation. It returns a non-nil dry-run
+// result if the call must not run.
+func (d *dispatcher) guardDestructive(ctx context.Context, conn *innerConnection, req mcp.Request) (*mcp.CallToolResult, error) {
+	variantID := conn.backendSession.variantID
+	if !d.server.confirmDestructive {
+		return nil, nil
+	}
+	v, ok := d.server.lookupVariant(variantID)
+	if !ok || v.Status != Experimental {
+		return nil, nil
+	}
+	params, _ := req.GetParams().(*mcp.CallToolParamsRaw)
+	if params == nil {
+		return nil, nil
+	}
+	if confirmed, _ := params.Meta[metaKeyConfirm].(bool); confirmed {
+		return nil, nil
+	}
+	tool, err := d.findTool(ctx, conn, params.Name)
+	if err != nil || tool == nil || !isDestructive(d.server.effectiveAnnotations(variantID, tool)) {
+		// Unknown tools are left to the variant to reject.
+		return nil, err
+	}
+
+	if ss, _ := req.GetSession().(*mcp.ServerSession); ss != nil && !d.shared && supportsElicitation(ss) {
+		res, err := ss.Elicit(ctx, &mcp.ElicitParams{
+			Message: fmt.Sprintf("Tool %q of experimental server variant %q may modify or delete data. Run it?",
+				params.Name, variantID),
+			RequestedSchema: map[string]any{"type": "object", "properties": map[string]any{}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if res.Action == "accept" {
+			return nil, nil
+		}
+	}
+
+	text := fmt.Sprintf("Dry run: tool %q of experimental server variant %q is destructive and was not run. "+
+		"To run it, repeat the call with _meta[%q] set to true.", params.Name, variantID, metaKeyConfirm)
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+		StructuredContent: map[string]any{
+			"dryRun":        true,
+			"tool":          params.Name,
+			"activeVariant": variantID,
+		},
+		IsError: true,
+	}, nil
+}
+
+// findTool looks up a tool of conn's variant by name, returning nil if the
+// variant has no such tool.
+func (d *dispatcher) findTool(ctx context.Context, conn *innerConnection, name string) (*mcp.Tool, error) {
+	cursor := ""
+	for {
+		res, err := conn.backendSession.handleReceive(ctx, "tools/list", &mcp.ListToolsRequest{
+			Params: &mcp.ListToolsParams{Cursor: cursor},
+		})
+		if err != nil {
+			return nil, err
+		}
+		list, _ := res.(*mcp.ListToolsResult)
+		if list == nil {
+			return nil, nil
+		}
+		for _, t := range list.Tools {
+			if t != nil && t.Name == name {
+				return t, nil
+			}
+		}
+		if list.NextCursor == "" {
+			return nil, nil
+		}
+		cursor = list.NextCursor
+	}
+}
+
+// effectiveAnnotations returns a tool's annotations as listed to clients,
+// with any WithAnnotationOverride for the variant applied.
+func (s *Server) effectiveAnnotations(variantID string, t *mcp.Tool) *mcp.ToolAnnotations {
+	if o, ok := s.annotationOverrides[variantID]; ok {
+		return o.apply(t.Annotations)
+	}
+	return t.Annotations
+}
+
+// isDestructive reports whether tool annotations leave a tool possibly
+// destructive, applying the MCP spec defaults.
+func isDestructive(a *mcp.ToolAnnotations) bool {
+	if a == nil {
+		return true
+	}
+	return !a.ReadOnlyHint && (a.DestructiveHint == nil || *a.DestructiveHint)
+}
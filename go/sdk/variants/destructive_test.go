@@ -0,0 +1,96 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPreviewServer returns a server whose experimental "preview" variant
+// has a destructive tool, a read-only tool, and an unannotated tool.
+func newPreviewServer() *Server {
+	newInner := func() *mcp.Server {
+		s := mcp.NewServer(&mcp.Implementation{Name: "inner", Version: "v1.0.0"}, nil)
+		yes := true
+		mcp.AddTool(s, &mcp.Tool{Name: "drop_table", Annotations: &mcp.ToolAnnotations{DestructiveHint: &yes}}, lookup)
+		mcp.AddTool(s, &mcp.Tool{Name: "query", Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}}, lookup)
+		mcp.AddTool(s, &mcp.Tool{Name: "lookup"}, lookup)
+		return s
+	}
+	return NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "stable"}, newInner(), 0).
+		WithVariant(ServerVariant{ID: "preview", Status: Experimental}, newInner(), 1).
+		WithDestructiveConfirmation(true)
+}
+
+func TestDestructiveConfirmation(t *testing.T) {
+	ctx := context.Background()
+	call := func(session *mcp.ClientSession, variant, tool string, confirm bool) *mcp.CallToolResult {
+		t.Helper()
+		meta := mcp.Meta{metaKeyVariant: variant}
+		if confirm {
+			meta[metaKeyConfirm] = true
+		}
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      tool,
+			Meta:      meta,
+			Arguments: map[string]any{"query": "x"},
+		})
+		require.NoError(t, err)
+		return res
+	}
+	dryRun := func(res *mcp.CallToolResult) bool {
+		m, _ := res.StructuredContent.(map[string]any)
+		return res.IsError && m["dryRun"] == true
+	}
+
+	t.Run("meta confirmation", func(t *testing.T) {
+		session := connectTestClient(t, newPreviewServer(), nil)
+
+		res := call(session, "preview", "drop_table", false)
+		assert.True(t, dryRun(res))
+		assert.Contains(t, res.Content[0].(*mcp.TextContent).Text, metaKeyConfirm)
+		assert.True(t, dryRun(call(session, "preview", "lookup", false)), "unannotated tools are destructive")
+
+		assert.False(t, dryRun(call(session, "preview", "drop_table", true)))
+		assert.False(t, dryRun(call(session, "preview", "query", false)))
+		assert.False(t, dryRun(call(session, "stable", "drop_table", false)), "stable variants are not guarded")
+	})
+
+	t.Run("elicitation", func(t *testing.T) {
+		var prompts atomic.Int32
+		action := "accept"
+		session := connectTestClient(t, newPreviewServer(), &mcp.ClientOptions{
+			ElicitationHandler: func(_ context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+				prompts.Add(1)
+				assert.Contains(t, req.Params.Message, `"drop_table"`)
+				return &mcp.ElicitResult{Action: action, Content: map[string]any{}}, nil
+			},
+		})
+
+		assert.False(t, dryRun(call(session, "preview", "drop_table", false)))
+		action = "decline"
+		assert.True(t, dryRun(call(session, "preview", "drop_table", false)))
+		assert.EqualValues(t, 2, prompts.Load(), "every destructive call is confirmed")
+	})
+
+	t.Run("annotation override", func(t *testing.T) {
+		no := false
+		vs := newPreviewServer().WithAnnotationOverride("preview", AnnotationOverride{DestructiveHint: &no})
+		session := connectTestClient(t, vs, nil)
+		assert.False(t, dryRun(call(session, "preview", "drop_table", false)))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		session := connectTestClient(t, newPreviewServer().WithDestructiveConfirmation(false), nil)
+		assert.False(t, dryRun(call(session, "preview", "drop_table", false)))
+	})
+}
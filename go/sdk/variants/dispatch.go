@@ -10,6 +10,9 @@ import (
 	"encoding/json"
 	"errors"
 	"reflect"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -19,13 +22,163 @@ import (
 // dispatcher exists per client session; in stateless mode a single dispatcher
 // is shared across all requests.
 type dispatcher struct {
-	server      *Server
+	server       *Server
+	shared       bool               // true for the stateless dispatcher shared by all requests
+	frontSession *mcp.ServerSession // nil in stateless mode
+
+	// connMu guards connections, the connections to the variants by ID,
+	// dialing, the variants being connected to by ID, and closed. Variants
+	// whose backends were not ready when the session started are connected
+	// on first use.
+	connMu      sync.RWMutex
 	connections map[string]*innerConnection
+	dialing     map[string]chan struct{} // closed when the dial is done
+	closed      bool
+
+	// pools balances requests across multiple shared connections per
+	// variant in stateless mode; nil unless Server.WithStatelessPool is used.
+	pools map[string]*connPool
+
+	// mu guards hints and ranked, the client's current hints and the
+	// variants ranked for them. Both are set per session at initialize and
+	// on hint updates; they stay unset on the shared stateless dispatcher.
+	mu     sync.RWMutex
+	hints  VariantHints
+	ranked []ServerVariant
+
+	// confirmMu guards confirmed, the user's answers to deprecated variant
+	// confirmation prompts, and confirming, the prompts awaiting an answer,
+	// both keyed by variant ID.
+	confirmMu  sync.Mutex
+	confirmed  map[string]bool
+	confirming map[string]chan struct{} // closed when the prompt is answered
+
+	// lastVariant is the variant of the session's previous dispatched
+	// request, used to report EventVariantSelected. Guarded by mu.
+	lastVariant string
+
+	// flagCtx describes the session's client for feature-flag decisions.
+	// Set with the ranking; guarded by mu.
+	flagCtx FlagContext
+
+	// pinned is the variant pinned for the session by Server.PinSession, or
+	// empty. Guarded by mu.
+	pinned string
+
+	// rankingReq describes the session's initialize request, for re-ranking
+	// its variants outside a request (see Server.notifyCatalogChanged).
+	// Set once before the dispatcher is shared; nil in stateless mode.
+	rankingReq *RankingRequest
+
+	// initResult is the session's initialize result. Its capabilities gate
+	// list_changed notifications, and initialize hooks re-run on re-ranking
+	// see a copy of it. Set once before the dispatcher is shared; nil in
+	// stateless mode.
+	initResult *mcp.InitializeResult
+
+	// restored is the variant restored from the variantToken the client
+	// presented at initialize (see Server.WithVariantTokens), which stays
+	// first in the session's ranking, or empty. Set once before the
+	// dispatcher is shared.
+	restored string
+
+	// stripped are the IDs of the variants the initialize hooks removed
+	// from the session's ranking, which the session may not select.
+	// Guarded by mu.
+	stripped map[string]bool
+
+	// composed are the variants composed for the session (see
+	// Server.WithComposition), and composedLists their merged lists by
+	// list method. Guarded by mu.
+	composed      []string
+	composedLists map[string]*composedList
+}
+
+// setRanking records the session's hints, flag context and ranked variants,
+// and the variants the initialize hooks stripped from the ranking. It
+// reports whether the default variant changed as a result.
+func (d *dispatcher) setRanking(ctx context.Context, fc FlagContext, ranked []ServerVariant, stripped map[string]bool) (defaultChanged bool) {
+	before, _ := d.defaultVariant(ctx, nil)
+	d.mu.Lock()
+	d.hints = fc.Hints
+	d.flagCtx = fc
+	d.ranked = ranked
+	d.stripped = stripped
+	d.mu.Unlock()
+	after, _ := d.defaultVariant(ctx, nil)
+	return before != after
+}
+
+// defaultVariant returns the ID of the variant used when a request does not
+// select one: the variant pinned for the session via Server.PinSession, else
+// the variant pinned via Server.WithDefaultVariant, else the first variant
+// of the session's ranking, else the first-ranked variant for empty hints
+// (stateless mode). Variants out of rotation, disabled for the client (see
+// Server.flagEnabled) or stripped by initialize hooks are skipped. The shared stateless dispatcher has no
+// client of its own, so it checks flags against req's client; req is nil
+// when resolving the default outside a request.
+func (d *dispatcher) defaultVariant(ctx context.Context, req mcp.Request) (string, error) {
+	d.mu.RLock()
+	ranked, fc, pinned, stripped := d.ranked, d.flagCtx, d.pinned, d.stripped
+	d.mu.RUnlock()
+	if d.shared {
+		fc = FlagContext{}
+		if req != nil {
+			fc = d.requestFlagContext(req)
+		}
+	}
+	usable := func(id string) bool {
+		return d.server.isAvailable(id) && d.server.flagEnabled(ctx, id, fc) && !stripped[id]
+	}
+	if pinned != "" && usable(pinned) {
+		return pinned, nil
+	}
+	if id := d.server.defaultVariantID; id != "" && usable(id) {
+		return id, nil
+	}
+	for _, v := range ranked {
+		if usable(v.ID) {
+			return v.ID, nil
+		}
+	}
+	return d.server.defaultVariantWhere(ctx, usable)
+}
+
+// isStripped reports whether the initialize hooks stripped a variant from
+// the session's ranking.
+func (d *dispatcher) isStripped(variantID string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.stripped[variantID]
 }
 
 // handle dispatches a request to the appropriate inner variant server.
-// Unknown methods are passed through to next.
+// Unknown methods are passed through to next. The request completes for
+// the progress throttle when handle returns, before the response is sent.
 func (d *dispatcher) handle(ctx context.Context, method string, req mcp.Request, next mcp.MethodHandler) (mcp.Result, error) {
+	if p := d.server.progressThrottle; p != nil {
+		if token := progressToken(req); token != nil {
+			ss, _ := req.GetSession().(*mcp.ServerSession)
+			p.start(ss, token)
+			defer p.finish(ss, token)
+		}
+	}
+	routed := d.server.routeMethod(method, req)
+	if method == "tools/call" && d.server.fanOut && !routed {
+		if ids := fanOutVariants(req); len(ids) > 0 {
+			return d.handleFanOut(ctx, req, ids)
+		}
+	}
+	if d.server.composition != nil && !routed {
+		if res, handled, err := d.handleComposed(ctx, method, req); handled {
+			return res, err
+		}
+	}
+	if call, ok := req.(*mcp.CallToolRequest); ok && method == "tools/call" {
+		if t, ok := d.server.translation(call); ok {
+			return d.handleTranslated(ctx, call, t)
+		}
+	}
 	switch method {
 	case "tools/list", "resources/list", "prompts/list", "resources/templates/list":
 		return d.handleList(ctx, method, req)
@@ -71,6 +224,26 @@ func isNilInterface(v any) bool {
 	return rv.Kind() == reflect.Ptr && rv.IsNil()
 }
 
+// ensureParams returns req's params, first allocating empty ones if it has
+// none (nil or typed-nil), so that _meta can be set on requests sent
+// without parameters. It returns nil if req has no settable Params field.
+func ensureParams(req mcp.Request) mcp.Params {
+	if params := req.GetParams(); !isNilInterface(params) {
+		return params
+	}
+	rv := reflect.ValueOf(req)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil
+	}
+	f := rv.Elem().FieldByName("Params")
+	if !f.IsValid() || f.Kind() != reflect.Ptr || !f.CanSet() {
+		return nil
+	}
+	f.Set(reflect.New(f.Type().Elem()))
+	params, _ := f.Interface().(mcp.Params)
+	return params
+}
+
 // The reflect-based field access (cursor unwrap/wrap, metadata injection) calls
 // Elem() to dereference pointers, which panics on non-pointer types. Even without
 // the panic, mutations on a value type would not propagate back to the original
@@ -115,43 +288,259 @@ func variantIDFromMeta(req mcp.Request) string {
 }
 
 // getConnection extracts the variant ID from request _meta and returns the
-// corresponding innerConnection for dispatching. Falls back to the
-// first-ranked variant when no variant is specified.
+// corresponding innerConnection for dispatching. A tag-based selection (see
+// SelectVariantTags) resolves to the best-ranked matching variant. Falls
+// back to the default variant (see Server.WithDefaultVariant) when no
+// variant is specified.
 func (d *dispatcher) getConnection(ctx context.Context, req mcp.Request) (*innerConnection, error) {
 	variantID := variantIDFromMeta(req)
 
-	// If no variant specified, use first-ranked (default).
-	//
-	// BUG: This re-ranks with empty hints, which may differ from the
-	// ranking returned during initialize (where client hints were used).
-	// Per SEP-2053, the default should be the first variant from the
-	// initialize response. To fix this properly, the per-session ranked
-	// order should be stored during initialize and reused here.
+	// If no variant specified, resolve a tag-based selection, the owner of
+	// a completion reference, or use the session's default.
+	explicit := variantID != ""
+	if !explicit {
+		if tags := variantTagsFromMeta(req); len(tags) > 0 {
+			id, err := d.resolveTags(ctx, req, tags)
+			if err != nil {
+				return nil, err
+			}
+			variantID = id
+		}
+	}
+	if complete, ok := req.(*mcp.CompleteRequest); ok && variantID == "" {
+		variantID = d.resolveCompletion(ctx, complete)
+	}
+	if variantID == "" {
+		variantID = d.sharedPin(ctx, req)
+	}
 	if variantID == "" {
-		ranked := d.server.RankedVariants(ctx, VariantHints{})
-		if len(ranked) == 0 {
-			return nil, errors.New("no variants available")
+		id, err := d.defaultVariant(ctx, req)
+		if err != nil {
+			return nil, err
 		}
-		variantID = ranked[0].ID
+		variantID = id
 	}
 
-	conn, ok := d.connections[variantID]
+	ok := d.server.hasVariant(variantID)
+	fc := d.requestFlagContext(req)
+	if unmet := d.server.unmetRules(variantID, fc.Hints); ok && len(unmet) > 0 {
+		err := d.server.ineligibleVariantError(variantID, unmet)
+		d.server.emit(ctx, Event{
+			Kind:      EventDispatchFailed,
+			SessionID: sessionID(req),
+			VariantID: variantID,
+			Err:       err,
+		})
+		return nil, err
+	}
+	if ok && (!d.server.flagEnabled(ctx, variantID, fc) || d.isStripped(variantID)) {
+		// A variant disabled for this client, or stripped from its
+		// ranking by an initialize hook, is indistinguishable from one
+		// that does not exist.
+		ok = false
+	}
 	if !ok {
-		return nil, d.createInvalidVariantError(ctx, variantID)
+		err := d.createInvalidVariantError(ctx, variantID)
+		d.server.emit(ctx, Event{
+			Kind:      EventDispatchFailed,
+			SessionID: sessionID(req),
+			VariantID: variantID,
+			Err:       err,
+		})
+		return nil, err
+	}
+
+	if !d.server.isAvailable(variantID) {
+		return nil, d.server.unavailableVariantError(variantID)
+	}
+
+	if explicit {
+		if err := d.confirmDeprecated(ctx, req, variantID); err != nil {
+			return nil, err
+		}
+	}
+
+	return d.connection(ctx, variantID)
+}
+
+// connection returns the connection to a registered variant, connecting to
+// it first if its backend was not ready when the session started (see
+// Server.WithStartupPolicy) or it was beyond the session's eager connections
+// (see SessionLimits). The backend is dialed without holding connMu, so a
+// slow backend only delays requests to its own variant; concurrent requests
+// for the variant wait for the same dial.
+func (d *dispatcher) connection(ctx context.Context, variantID string) (*innerConnection, error) {
+	for {
+		d.connMu.RLock()
+		conn, ok := d.connections[variantID]
+		d.connMu.RUnlock()
+		if ok {
+			return conn, nil
+		}
+
+		d.connMu.Lock()
+		if conn, ok := d.connections[variantID]; ok {
+			d.connMu.Unlock()
+			return conn, nil
+		}
+		if d.closed {
+			d.connMu.Unlock()
+			return nil, ErrServerClosed
+		}
+		if done, ok := d.dialing[variantID]; ok {
+			// Another request is connecting to the variant; use its
+			// connection, or try again if it failed.
+			d.connMu.Unlock()
+			select {
+			case <-done:
+				continue
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		if n := d.server.sessionLimits.MaxConnectionsPerSession; n > 0 && !d.shared && len(d.connections)+len(d.dialing) >= n {
+			err := d.tooManyConnectionsError(variantID)
+			d.connMu.Unlock()
+			return nil, err
+		}
+		done := make(chan struct{})
+		if d.dialing == nil {
+			d.dialing = make(map[string]chan struct{})
+		}
+		d.dialing[variantID] = done
+		d.connMu.Unlock()
+
+		return d.dial(ctx, variantID, done)
 	}
+}
 
+// dial connects to a variant for connection, which registered done as the
+// variant's pending dial, and warms the connection up (see
+// Server.WithWarmup). It publishes the connection unless the session was
+// closed meanwhile, and closes done when finished.
+func (d *dispatcher) dial(ctx context.Context, variantID string, done chan struct{}) (*innerConnection, error) {
+	entry := d.server.variants[d.server.variantIndex[variantID]]
+	conn, err := entry.backend.connect(ctx, entry.variant, d.frontSession)
+
+	if err != nil {
+		d.connMu.Lock()
+		delete(d.dialing, variantID)
+		close(done)
+		d.connMu.Unlock()
+		e := Event{Kind: EventBackendUnhealthy, VariantID: variantID, Err: err}
+		if d.frontSession != nil {
+			e.SessionID = d.frontSession.ID()
+		}
+		d.server.emit(ctx, e)
+		return nil, err
+	}
+
+	// Warm the connection up before publishing it: requests for the
+	// variant wait for it as for the dial, and others are not held up.
+	d.server.warmUp(ctx, d.frontSession, conn)
+
+	d.connMu.Lock()
+	defer d.connMu.Unlock()
+	delete(d.dialing, variantID)
+	close(done)
+	if d.closed {
+		conn.close()
+		return nil, ErrServerClosed
+	}
+	d.connections[variantID] = conn
 	return conn, nil
 }
 
+// receive dispatches req to the given inner connection under a new dispatch
+// ID (see Correlation), reporting lifecycle events for the selected variant
+// and the outcome of the call. Unconfirmed destructive tool calls are
+// answered with a dry run instead (see WithDestructiveConfirmation). The
+// pooled connection, if any, and the call are bounded by the dispatch
+// timeout (see WithDispatchTimeouts), and retried under the variant's
+// retry policy (see WithRetryPolicy).
+func (d *dispatcher) receive(ctx context.Context, conn *innerConnection, method string, req mcp.Request) (mcp.Result, error) {
+	ctx = withDispatchCorrelation(ctx)
+	if method == "tools/call" {
+		dryRun, err := d.guardDestructive(ctx, conn, req)
+		if err != nil {
+			return nil, err
+		}
+		if dryRun != nil {
+			return dryRun, nil
+		}
+	}
+	variantID := conn.backendSession.variantID
+	sid := sessionID(req)
+	ctx = d.server.traceDispatch(ctx, conn, method, req)
+	noteCapturedVariant(ctx, variantID)
+	if len(d.server.eventHandlers) > 0 {
+		if !d.shared {
+			d.mu.Lock()
+			changed := d.lastVariant != variantID
+			d.lastVariant = variantID
+			d.mu.Unlock()
+			if changed {
+				d.server.emit(ctx, Event{Kind: EventVariantSelected, SessionID: sid, VariantID: variantID, Method: method})
+			}
+		}
+		if v, ok := d.server.lookupVariant(variantID); ok && v.Status == Deprecated {
+			d.server.emit(ctx, Event{Kind: EventVariantDeprecatedUsed, SessionID: sid, VariantID: variantID, Method: method})
+		}
+	}
+
+	start := time.Now()
+	result, err := d.server.withDispatchTimeout(ctx, variantID, method, func(ctx context.Context) (mcp.Result, error) {
+		ctx, err := d.server.withBackendToken(ctx, conn, req)
+		if err != nil {
+			return nil, err
+		}
+		return d.withRetries(ctx, conn, method, req, sid, func(ctx context.Context, conn *innerConnection) (mcp.Result, error) {
+			if p := d.pools[variantID]; p != nil {
+				c, release, err := p.acquire(ctx)
+				if err != nil {
+					return nil, err
+				}
+				defer release()
+				conn = c
+			}
+			return d.server.intercept(ctx, conn, method, req, sid)
+		})
+	})
+	e := Event{
+		Kind:      EventVariantDispatched,
+		SessionID: sid,
+		VariantID: variantID,
+		Method:    method,
+		Duration:  time.Since(start),
+	}
+	if err != nil {
+		e.Kind = EventDispatchFailed
+		e.Err = err
+	} else if method == "tools/call" && d.server.resultAccounting {
+		d.server.recordResult(variantID, req, result)
+	}
+	d.server.emit(ctx, e)
+	return result, err
+}
+
+// sessionID returns the ID of the request's session, or "" if it has none.
+func sessionID(req mcp.Request) string {
+	ss := req.GetSession()
+	if isNilInterface(ss) {
+		return ""
+	}
+	return ss.ID()
+}
+
 // enrichError adds activeVariant to a jsonrpc.Error's Data field for
 // variant-scoped resolution failures. Per SEP-2053 Implementation Notes:
 // "Servers SHOULD include activeVariant in error data for variant-scoped
 // resolution failures (unknown tool/prompt/resource, invalid cursor, invalid
 // subscription context)."
 //
-// Only errors with codes -32602 (InvalidParams) or -32601 (MethodNotFound)
-// are enriched; business-logic errors from tool execution are passed through
-// unmodified.
+// Only errors with codes -32602 (InvalidParams), -32601 (MethodNotFound) or
+// -32002 (resource not found) are enriched; business-logic errors from tool
+// execution are passed through unmodified.
 func enrichError(err error, variantID string) error {
 	var jErr *jsonrpc.Error
 	if !errors.As(err, &jErr) {
@@ -160,24 +549,31 @@ func enrichError(err error, variantID string) error {
 
 	// Only enrich resolution-class errors.
 	switch jErr.Code {
-	case jsonrpc.CodeInvalidParams, jsonrpc.CodeMethodNotFound:
+	case jsonrpc.CodeInvalidParams, jsonrpc.CodeMethodNotFound, mcp.CodeResourceNotFound:
 		// fall through to enrich
 	default:
 		return err
 	}
 
-	// Parse existing data (if any) and inject activeVariant.
-	// Copy the error to avoid mutating the original
-	data := make(map[string]any)
-	if len(jErr.Data) > 0 {
-		_ = json.Unmarshal(jErr.Data, &data)
-	}
-	data["activeVariant"] = variantID
-
+	// Copy the error to avoid mutating the original.
 	enriched := &jsonrpc.Error{
 		Code:    jErr.Code,
 		Message: jErr.Message,
 	}
+	activeVariant, mErr := json.Marshal(variantID)
+	if mErr != nil {
+		return enriched
+	}
+	if len(jErr.Data) == 0 {
+		enriched.Data = json.RawMessage(`{"activeVariant":` + string(activeVariant) + `}`)
+		return enriched
+	}
+
+	// Parse existing data and inject activeVariant, leaving the other
+	// fields encoded.
+	data := make(map[string]json.RawMessage)
+	_ = json.Unmarshal(jErr.Data, &data)
+	data["activeVariant"] = activeVariant
 	if encoded, mErr := json.Marshal(data); mErr == nil {
 		enriched.Data = json.RawMessage(encoded)
 	}
@@ -191,6 +587,7 @@ func enrichError(err error, variantID string) error {
 // handleList handles list methods using the generic backend session call method.
 // Implements cursor scoping per SEP-2053: unwraps incoming cursors and wraps outgoing cursors.
 func (d *dispatcher) handleList(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+	d.server.routeMethod(method, req)
 	conn, err := d.getConnection(ctx, req)
 	if err != nil {
 		return nil, err
@@ -198,6 +595,9 @@ func (d *dispatcher) handleList(ctx context.Context, method string, req mcp.Requ
 
 	backendSession := conn.backendSession
 	variantID := backendSession.variantID
+	if err := d.checkCapability(ctx, method, req, variantID); err != nil {
+		return nil, err
+	}
 	params := req.GetParams()
 
 	// Inject variant metadata and handle cursor unwrapping (guard against typed-nil params)
@@ -216,7 +616,7 @@ func (d *dispatcher) handleList(ctx context.Context, method string, req mcp.Requ
 		}
 	}
 
-	result, err := backendSession.handleReceive(ctx, method, req)
+	result, err := d.receiveHedged(ctx, conn, method, req)
 	if err != nil {
 		return nil, enrichError(err, variantID)
 	}
@@ -230,10 +630,42 @@ func (d *dispatcher) handleList(ctx context.Context, method string, req mcp.Requ
 	if f := reflect.ValueOf(result).Elem().FieldByName("NextCursor"); f.IsValid() && f.String() != "" {
 		f.SetString(wrapCursor(f.String(), variantID))
 	}
+	if res, ok := result.(*mcp.ListToolsResult); ok {
+		d.server.rewriteTools(ctx, variantID, res)
+	}
+	if d.server.namespaceResources {
+		scopeResourceURIs(variantID, result)
+	}
+	d.server.stampActiveVariant(result, variantID)
 
 	return result, nil
 }
 
+// rewriteTools replaces the tools in res with copies carrying rendered
+// descriptions (see WithDescriptionTemplates) and overridden annotations
+// (see WithAnnotationOverride). The tools themselves are not modified, since
+// they may be shared with the variant's server.
+func (s *Server) rewriteTools(ctx context.Context, variantID string, res *mcp.ListToolsResult) {
+	override, hasOverride := s.annotationOverrides[variantID]
+	if (s.templateData == nil && !hasOverride) || res == nil {
+		return
+	}
+	tools := make([]*mcp.Tool, len(res.Tools))
+	for i, t := range res.Tools {
+		tools[i] = t
+		if t == nil {
+			continue
+		}
+		c := *t
+		c.Description = s.renderDescription(ctx, variantID, t.Description)
+		if hasOverride {
+			c.Annotations = override.apply(t.Annotations)
+		}
+		tools[i] = &c
+	}
+	res.Tools = tools
+}
+
 // ---------------------------------------------------------------------------
 // Simple methods (no pagination)
 // ---------------------------------------------------------------------------
@@ -241,13 +673,36 @@ func (d *dispatcher) handleList(ctx context.Context, method string, req mcp.Requ
 // handleDirect handles all simple methods (call, subscribe, unsubscribe, completion)
 // that don't require special cursor handling.
 func (d *dispatcher) handleDirect(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+	d.server.routeMethod(method, req)
+	if d.server.namespaceResources {
+		if err := unscopeResourceURI(req); err != nil {
+			return nil, err
+		}
+	}
 	conn, err := d.getConnection(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
+	if method == "tools/call" {
+		conn, err = d.checkTool(ctx, conn, req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	backendSession := conn.backendSession
 	variantID := backendSession.variantID
+	if err := d.checkCapability(ctx, method, req, variantID); err != nil {
+		return nil, err
+	}
+	cache := d.server.completions
+	if cache != nil && method == "completion/complete" {
+		if res, ok := cache.get(variantID, req); ok {
+			d.server.stampActiveVariant(res, variantID)
+			return res, nil
+		}
+	}
 	params := req.GetParams()
 
 	// Inject variant metadata (guard against typed-nil params)
@@ -258,10 +713,17 @@ func (d *dispatcher) handleDirect(ctx context.Context, method string, req mcp.Re
 		injectVariantMeta(params, variantID)
 	}
 
-	result, err := backendSession.handleReceive(ctx, method, req)
+	result, err := d.receiveHedged(ctx, conn, method, req)
 	if err != nil {
 		return nil, enrichError(err, variantID)
 	}
+	if cache != nil && method == "completion/complete" {
+		cache.put(variantID, req, result)
+	}
+	if d.server.namespaceResources && !isNilInterface(result) {
+		scopeResourceURIs(variantID, result)
+	}
+	d.server.stampActiveVariant(result, variantID)
 
 	return result, nil
 }
@@ -272,9 +734,13 @@ func (d *dispatcher) handleDirect(ctx context.Context, method string, req mcp.Re
 
 // variantCursor wraps pagination cursors with variant ID for scoping.
 // Per SEP-2053: "Cursors MUST be treated as opaque and variant-scoped"
+//
+// Inner cursors that are not valid UTF-8 are carried as bytes, since JSON
+// strings would replace invalid sequences.
 type variantCursor struct {
 	VariantID   string `json:"v"`
-	InnerCursor string `json:"c"`
+	InnerCursor string `json:"c,omitempty"`
+	InnerBytes  []byte `json:"b,omitempty"`
 }
 
 // wrapCursor wraps a cursor from an inner server with the variant ID.
@@ -283,9 +749,11 @@ func wrapCursor(cursor string, variantID string) string {
 	if cursor == "" {
 		return ""
 	}
-	wrapped := variantCursor{
-		VariantID:   variantID,
-		InnerCursor: cursor,
+	wrapped := variantCursor{VariantID: variantID}
+	if utf8.ValidString(cursor) {
+		wrapped.InnerCursor = cursor
+	} else {
+		wrapped.InnerBytes = []byte(cursor)
 	}
 	data, err := json.Marshal(wrapped)
 	if err != nil {
@@ -297,26 +765,35 @@ func wrapCursor(cursor string, variantID string) string {
 
 // unwrapCursor validates and unwraps a cursor for the expected variant.
 // Returns the inner cursor if valid, or an error if the cursor is invalid
-// or belongs to a different variant.
+// or belongs to a different variant. A non-empty cursor never unwraps to
+// an empty inner cursor, which would restart pagination.
 func unwrapCursor(cursor string, expectedVariant string) (string, error) {
 	if cursor == "" {
 		return "", nil
 	}
 
+	invalid := &jsonrpc.Error{
+		Code:    jsonrpc.CodeInvalidParams,
+		Message: "Invalid cursor format",
+	}
 	data, err := base64.StdEncoding.DecodeString(cursor)
 	if err != nil {
-		return "", &jsonrpc.Error{
-			Code:    jsonrpc.CodeInvalidParams,
-			Message: "Invalid cursor format",
-		}
+		return "", invalid
 	}
 
 	var wrapped variantCursor
 	if err := json.Unmarshal(data, &wrapped); err != nil {
-		return "", &jsonrpc.Error{
-			Code:    jsonrpc.CodeInvalidParams,
-			Message: "Invalid cursor format",
+		return "", invalid
+	}
+	inner := wrapped.InnerCursor
+	if len(wrapped.InnerBytes) > 0 {
+		if inner != "" {
+			return "", invalid
 		}
+		inner = string(wrapped.InnerBytes)
+	}
+	if inner == "" {
+		return "", invalid
 	}
 
 	if wrapped.VariantID != expectedVariant {
@@ -333,5 +810,5 @@ func unwrapCursor(cursor string, expectedVariant string) (string, error) {
 		}
 	}
 
-	return wrapped.InnerCursor, nil
+	return inner, nil
 }
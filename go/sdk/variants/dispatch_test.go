@@ -6,9 +6,12 @@ package variants
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"reflect"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -682,3 +685,68 @@ func TestEnrichError(t *testing.T) {
 		})
 	}
 }
+
+func TestEnrichError_KeepsData(t *testing.T) {
+	err := enrichError(&jsonrpc.Error{
+		Code:    jsonrpc.CodeInvalidParams,
+		Message: "bad param",
+		Data:    json.RawMessage(`{"activeVariant":"stale","field":{"name":"x"}}`),
+	}, "v1")
+	var jErr *jsonrpc.Error
+	require.ErrorAs(t, err, &jErr)
+	assert.JSONEq(t, `{"activeVariant":"v1","field":{"name":"x"}}`, string(jErr.Data))
+
+	err = enrichError(&jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: "bad param"}, `quo"te`)
+	require.ErrorAs(t, err, &jErr)
+	assert.JSONEq(t, `{"activeVariant":"quo\"te"}`, string(jErr.Data))
+}
+
+// ---------------------------------------------------------------------------
+// connection
+// ---------------------------------------------------------------------------
+
+// gatedBackend is an in-memory backend whose connections block until gate
+// is closed.
+type gatedBackend struct {
+	*inMemoryBackend
+	gate     chan struct{}
+	connects atomic.Int32
+}
+
+func (b *gatedBackend) connect(ctx context.Context, v ServerVariant, frontSession *mcp.ServerSession) (*innerConnection, error) {
+	b.connects.Add(1)
+	<-b.gate
+	return b.inMemoryBackend.connect(ctx, v, frontSession)
+}
+
+// TestConnection_SlowDial verifies that connecting to a slow backend does
+// not block connections to other variants, and that concurrent requests
+// for the variant share one dial.
+func TestConnection_SlowDial(t *testing.T) {
+	codingServer, compactServer := newTestServers()
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "coding", Status: Stable}, codingServer, 0)
+	b := &gatedBackend{inMemoryBackend: newInMemoryBackend(compactServer, "compact", vs), gate: make(chan struct{})}
+	vs.addVariant(ServerVariant{ID: "compact", Status: Experimental}, b, 1)
+	d := &dispatcher{server: vs, shared: true, connections: make(map[string]*innerConnection)}
+	t.Cleanup(func() { (&sessionState{dispatcher: d}).close() })
+	ctx := context.Background()
+
+	conns := make(chan *innerConnection, 2)
+	for range 2 {
+		go func() {
+			conn, err := d.connection(ctx, "compact")
+			assert.NoError(t, err)
+			conns <- conn
+		}()
+	}
+	require.Eventually(t, func() bool { return b.connects.Load() == 1 }, time.Second, time.Millisecond)
+
+	_, err := d.connection(ctx, "coding")
+	require.NoError(t, err, "dialing compact must not block coding")
+
+	close(b.gate)
+	first, second := <-conns, <-conns
+	assert.Same(t, first, second)
+	assert.Equal(t, int32(1), b.connects.Load())
+}
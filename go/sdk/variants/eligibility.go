@@ -0,0 +1,158 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// CodeVariantIneligible is the JSON-RPC error code returned when a request
+// selects a variant whose eligibility rules the client's hints do not meet
+// (see Server.WithEligibility). The client may select another variant, or
+// update its hints.
+const CodeVariantIneligible int64 = -32054
+
+// EligibilityRule is a condition on one of the client's hints. A hint with
+// several values meets In if any of them is listed, and fails NotIn if any
+// of them is listed.
+type EligibilityRule struct {
+	// Key is the hint key, e.g. HintRenderingCapabilities.
+	Key string `json:"key"`
+
+	// In lists the accepted values. If set, the rule fails when the
+	// client's hint has none of them, or is absent (unless IfPresent).
+	In []string `json:"in,omitempty"`
+
+	// NotIn lists the rejected values. The rule fails when the client's
+	// hint has any of them.
+	NotIn []string `json:"notIn,omitempty"`
+
+	// IfPresent makes the rule hold when the client does not send the hint.
+	IfPresent bool `json:"ifPresent,omitempty"`
+}
+
+// String describes the rule, e.g. `renderingCapabilities in ["rich"]`.
+func (r EligibilityRule) String() string {
+	var parts []string
+	if len(r.In) > 0 {
+		parts = append(parts, fmt.Sprintf("%s in %q", r.Key, r.In))
+	}
+	if len(r.NotIn) > 0 {
+		parts = append(parts, fmt.Sprintf("%s not in %q", r.Key, r.NotIn))
+	}
+	s := strings.Join(parts, " and ")
+	if r.IfPresent {
+		s += " if present"
+	}
+	return s
+}
+
+// holds reports whether the client's hints meet the rule.
+func (r EligibilityRule) holds(hints VariantHints) bool {
+	raw, ok := hints.Hints[r.Key]
+	values := flattenHintValues(nil, raw)
+	if !ok || len(values) == 0 {
+		return r.IfPresent || len(r.In) == 0
+	}
+	if len(r.In) > 0 && !slices.ContainsFunc(values, func(v string) bool { return slices.Contains(r.In, v) }) {
+		return false
+	}
+	return !slices.ContainsFunc(values, func(v string) bool { return slices.Contains(r.NotIn, v) })
+}
+
+// WithEligibility restricts a variant to clients whose hints meet all of
+// rules, instead of custom ranking code. For example, to only offer a
+// variant to clients rendering rich content:
+//
+//	vs.WithEligibility("rich-ui", variants.EligibilityRule{
+//		Key: variants.HintRenderingCapabilities,
+//		In:  []string{"rich"},
+//	})
+//
+// Ineligible variants are omitted from the client's ranked list and
+// skipped when resolving its default, like variants disabled by a
+// FlagProvider. Requests selecting one explicitly fail with a
+// CodeVariantIneligible error whose data lists the unmet rules. Rules are
+// checked against the client's normalized hints, as updated during the
+// session; in stateless mode, clients have no hints, so only rules that
+// hold without the hint (NotIn or IfPresent) admit them, and requests that
+// select no variant fall back to the first eligible one. Later calls for
+// the same variant replace its rules.
+//
+// The variant must be registered by the time serving starts. It panics if
+// a rule has no Key, neither In nor NotIn, or a value outside the
+// vocabulary of a hint whose values the SEP defines exhaustively (e.g.
+// contextSize).
+//
+// Returns the receiver for chaining.
+func (s *Server) WithEligibility(variantID string, rules ...EligibilityRule) *Server {
+	for _, r := range rules {
+		if r.Key == "" {
+			panic("variants: eligibility rule for variant " + variantID + " has no key")
+		}
+		if len(r.In) == 0 && len(r.NotIn) == 0 {
+			panic(fmt.Sprintf("variants: eligibility rule %q for variant %s has no values", r.Key, variantID))
+		}
+		if closed, ok := closedHintValues[r.Key]; ok {
+			for _, v := range slices.Concat(r.In, r.NotIn) {
+				if !slices.Contains(closed, v) {
+					panic(fmt.Sprintf("variants: invalid value %q for hint %q in eligibility rule for variant %s; want one of %q", v, r.Key, variantID, closed))
+				}
+			}
+		}
+	}
+	if s.eligibility == nil {
+		s.eligibility = make(map[string][]EligibilityRule)
+	}
+	s.eligibility[variantID] = slices.Clone(rules)
+	return s
+}
+
+// validateEligibility checks that eligibility rules name registered
+// variants.
+func (s *Server) validateEligibility() error {
+	for id := range s.eligibility {
+		if !s.hasVariant(id) {
+			return fmt.Errorf("variants: eligibility rules for unregistered variant %q", id)
+		}
+	}
+	return nil
+}
+
+// unmetRules returns the eligibility rules of a variant that hints do not
+// meet.
+func (s *Server) unmetRules(variantID string, hints VariantHints) []EligibilityRule {
+	var unmet []EligibilityRule
+	for _, r := range s.eligibility[variantID] {
+		if !r.holds(hints) {
+			unmet = append(unmet, r)
+		}
+	}
+	return unmet
+}
+
+// ineligibleVariantError creates the error for a request selecting a
+// variant whose rules hints do not meet.
+func (s *Server) ineligibleVariantError(variantID string, unmet []EligibilityRule) error {
+	reasons := make([]string, len(unmet))
+	for i, r := range unmet {
+		reasons[i] = r.String()
+	}
+	dataJSON, _ := json.Marshal(map[string]any{
+		"requestedVariant": variantID,
+		"reason":           "client hints do not meet the variant's eligibility rules: " + strings.Join(reasons, "; "),
+		"unmetRules":       unmet,
+	})
+	return &jsonrpc.Error{
+		Code:    CodeVariantIneligible,
+		Message: "Server variant not eligible for client hints",
+		Data:    json.RawMessage(dataJSON),
+	}
+}
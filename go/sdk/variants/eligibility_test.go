@@ -0,0 +1,106 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEligibility(t *testing.T) {
+	ctx := context.Background()
+	newServer := func() *Server {
+		return newTestVariantServer().WithEligibility("compact", EligibilityRule{Key: HintContextSize, In: []string{"compact"}})
+	}
+
+	t.Run("eligible", func(t *testing.T) {
+		session := connectVariantsClient(t, newServer(), &ClientOptions{
+			Hints: VariantHints{Hints: map[string]any{HintContextSize: []string{"verbose", "compact"}}},
+		})
+		assert.ElementsMatch(t, []string{"coding", "compact"}, variantIDs(session.Variants()))
+		_, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: "compact"}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("ineligible", func(t *testing.T) {
+		session := connectVariantsClient(t, newServer(), &ClientOptions{
+			Hints: VariantHints{Hints: map[string]any{HintContextSize: "verbose"}},
+		})
+		assert.Equal(t, []string{"coding"}, variantIDs(session.Variants()))
+
+		_, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: "compact"}})
+		var jErr *jsonrpc.Error
+		require.True(t, errors.As(err, &jErr))
+		assert.Equal(t, CodeVariantIneligible, jErr.Code)
+		assert.JSONEq(t, `{
+			"requestedVariant": "compact",
+			"reason": "client hints do not meet the variant's eligibility rules: contextSize in [\"compact\"]",
+			"unmetRules": [{"key": "contextSize", "in": ["compact"]}]
+		}`, string(jErr.Data))
+	})
+
+	t.Run("no hints", func(t *testing.T) {
+		session := connectVariantsClient(t, newServer(), nil)
+		assert.Equal(t, []string{"coding"}, variantIDs(session.Variants()))
+	})
+
+	t.Run("stateless default skips ineligible variant", func(t *testing.T) {
+		vs := newTestVariantServer().WithEligibility("coding", EligibilityRule{Key: HintContextSize, In: []string{"verbose"}})
+		session := connectStatelessTestClient(t, vs)
+
+		tools, err := session.ListTools(ctx, nil)
+		require.NoError(t, err)
+		assert.Contains(t, toolNames(tools.Tools), "summarize")
+	})
+}
+
+func TestEligibilityRule(t *testing.T) {
+	hints := func(v any) VariantHints {
+		if v == nil {
+			return VariantHints{}
+		}
+		return VariantHints{Hints: map[string]any{HintRenderingCapabilities: v}}
+	}
+	rich := EligibilityRule{Key: HintRenderingCapabilities, In: []string{"rich"}}
+	noText := EligibilityRule{Key: HintRenderingCapabilities, NotIn: []string{"text-only"}}
+	richIfPresent := EligibilityRule{Key: HintRenderingCapabilities, In: []string{"rich"}, IfPresent: true}
+
+	for _, tc := range []struct {
+		rule  EligibilityRule
+		value any
+		want  bool
+	}{
+		{rich, "rich", true},
+		{rich, []string{"markdown", "rich"}, true},
+		{rich, "markdown", false},
+		{rich, nil, false},
+		{noText, nil, true},
+		{noText, "rich", true},
+		{noText, []string{"rich", "text-only"}, false},
+		{richIfPresent, nil, true},
+		{richIfPresent, "markdown", false},
+	} {
+		assert.Equal(t, tc.want, tc.rule.holds(hints(tc.value)), "%v with %v", tc.rule, tc.value)
+	}
+	assert.Equal(t, `renderingCapabilities in ["rich"] if present`, richIfPresent.String())
+	assert.Equal(t, `renderingCapabilities not in ["text-only"]`, noText.String())
+}
+
+func TestWithEligibility_Invalid(t *testing.T) {
+	assert.Panics(t, func() { newTestVariantServer().WithEligibility("compact", EligibilityRule{In: []string{"x"}}) })
+	assert.Panics(t, func() { newTestVariantServer().WithEligibility("compact", EligibilityRule{Key: HintUseCase}) })
+	assert.Panics(t, func() {
+		newTestVariantServer().WithEligibility("compact", EligibilityRule{Key: HintContextSize, In: []string{"tiny"}})
+	})
+
+	_, err := newTestVariantServer().WithEligibility("nope", EligibilityRule{Key: HintUseCase, In: []string{"ide"}}).NewRouter(nil)
+	assert.ErrorContains(t, err, `eligibility rules for unregistered variant "nope"`)
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Embedder embeds texts as vectors, for EmbeddingRanker. The vectors of
+// texts with similar meanings should have a high cosine similarity, as
+// with the embedding models of LLM providers.
+type Embedder interface {
+	// Embed returns the vectors of texts, in order.
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// EmbedderFunc adapts a function to the Embedder interface.
+type EmbedderFunc func(ctx context.Context, texts []string) ([][]float64, error)
+
+// Embed calls f(ctx, texts).
+func (f EmbedderFunc) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	return f(ctx, texts)
+}
+
+// EmbeddingRankerOptions configures an EmbeddingRanker.
+type EmbeddingRankerOptions struct {
+	// Tolerance is the width of the bands of similarity within which
+	// variants are considered equally similar to the client's description,
+	// and are ranked by their hints instead. Zero means 0.05.
+	Tolerance float64
+
+	// OnError, if non-nil, is called when embedding fails, in which case
+	// the variants are ranked by their hints alone.
+	OnError func(error)
+}
+
+// defaultEmbeddingTolerance is the tolerance when
+// EmbeddingRankerOptions.Tolerance is zero.
+const defaultEmbeddingTolerance = 0.05
+
+// EmbeddingRanker ranks variants by the semantic similarity of their
+// descriptions to the client's free-text hints description, so that
+// clients are matched with variants even when their hint keys and values
+// do not match the variants' exactly. Use its Rank method as the ranking
+// function:
+//
+//	r := variants.NewEmbeddingRanker(embedder, nil)
+//	vs.WithRanking(r.Rank)
+//
+// Variants are ordered by the cosine similarity of the embeddings, in
+// bands of EmbeddingRankerOptions.Tolerance. Within a band, and for
+// clients without a description, variants that match more of the client's
+// hints come first, then variants by priority and status as in the
+// default ranking. Each variant's similarity is reported as its Score,
+// with a MatchReason.
+//
+// Embeddings of the variants' descriptions are cached by description, so
+// each is embedded once; the client's description is embedded for each
+// ranking. Consider WithRankingCache to rank clients with the same hints
+// once.
+type EmbeddingRanker struct {
+	embedder  Embedder
+	tolerance float64
+	onError   func(error)
+
+	mu      sync.Mutex
+	vectors map[string][]float64 // by variant description
+}
+
+// NewEmbeddingRanker returns an EmbeddingRanker using e to embed texts.
+// opts may be nil. It panics if e is nil or the tolerance is negative.
+func NewEmbeddingRanker(e Embedder, opts *EmbeddingRankerOptions) *EmbeddingRanker {
+	if e == nil {
+		panic("variants: nil Embedder")
+	}
+	var o EmbeddingRankerOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Tolerance < 0 {
+		panic("variants: negative embedding tolerance")
+	}
+	if o.Tolerance == 0 {
+		o.Tolerance = defaultEmbeddingTolerance
+	}
+	return &EmbeddingRanker{embedder: e, tolerance: o.Tolerance, onError: o.OnError, vectors: make(map[string][]float64)}
+}
+
+// Rank ranks variants for hints. It has the signature of a RankingFunc.
+func (r *EmbeddingRanker) Rank(ctx context.Context, hints VariantHints, variants []ServerVariant) []ServerVariant {
+	type candidate struct {
+		v    ServerVariant
+		band float64
+		hint float64
+	}
+	candidates := make([]candidate, len(variants))
+	for i, v := range variants {
+		candidates[i] = candidate{v: v, hint: hintMatchScore(hints, v)}
+	}
+	if desc := strings.TrimSpace(hints.Description); desc != "" {
+		sims, err := r.similarities(ctx, desc, variants)
+		if err != nil {
+			if r.onError != nil {
+				r.onError(err)
+			}
+		} else {
+			for i, sim := range sims {
+				c := &candidates[i]
+				c.band = math.Floor(sim / r.tolerance)
+				c.v.Score = sim
+				c.v.MatchReason = fmt.Sprintf("description similarity %.2f", sim)
+			}
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Or(
+			cmp.Compare(b.band, a.band),
+			cmp.Compare(b.hint, a.hint),
+			cmp.Compare(a.v.Priority(), b.v.Priority()),
+			cmp.Compare(statusWeight(a.v.Status), statusWeight(b.v.Status)),
+		)
+	})
+	out := make([]ServerVariant, len(candidates))
+	for i, c := range candidates {
+		out[i] = c.v
+	}
+	return out
+}
+
+// similarities returns the cosine similarities of the variants'
+// descriptions to desc. Variants without a description have similarity 0.
+func (r *EmbeddingRanker) similarities(ctx context.Context, desc string, variants []ServerVariant) ([]float64, error) {
+	texts := []string{desc}
+	r.mu.Lock()
+	for _, v := range variants {
+		if _, ok := r.vectors[v.Description]; !ok && v.Description != "" && !slices.Contains(texts[1:], v.Description) {
+			texts = append(texts, v.Description)
+		}
+	}
+	r.mu.Unlock()
+
+	vectors, err := r.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("variants: embedding descriptions: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("variants: embedder returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	r.mu.Lock()
+	for i, text := range texts[1:] {
+		r.vectors[text] = vectors[i+1]
+	}
+	known := make([][]float64, len(variants))
+	for i, v := range variants {
+		known[i] = r.vectors[v.Description]
+	}
+	r.mu.Unlock()
+
+	sims := make([]float64, len(variants))
+	for i, v := range variants {
+		if v.Description == "" {
+			continue
+		}
+		sim, err := cosineSimilarity(vectors[0], known[i])
+		if err != nil {
+			return nil, fmt.Errorf("variants: variant %q: %w", v.ID, err)
+		}
+		sims[i] = sim
+	}
+	return sims, nil
+}
+
+// cosineSimilarity returns the cosine similarity of a and b, or 0 if
+// either is zero.
+func cosineSimilarity(a, b []float64) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("embeddings have different dimensions %d and %d", len(a), len(b))
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0, nil
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb)), nil
+}
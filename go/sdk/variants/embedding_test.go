@@ -0,0 +1,141 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bagOfWords is an Embedder counting the words of texts in a fixed
+// vocabulary. texts counts the texts embedded.
+type bagOfWords struct {
+	vocabulary []string
+	texts      atomic.Int32
+}
+
+func (b *bagOfWords) Embed(_ context.Context, texts []string) ([][]float64, error) {
+	b.texts.Add(int32(len(texts)))
+	out := make([][]float64, len(texts))
+	for i, text := range texts {
+		out[i] = make([]float64, len(b.vocabulary))
+		for _, w := range strings.Fields(strings.ToLower(text)) {
+			for j, v := range b.vocabulary {
+				if strings.Trim(w, ".,") == v {
+					out[i][j]++
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+func embeddingTestVariants() []ServerVariant {
+	return []ServerVariant{
+		{ID: "coding", Description: "Tools for coding and refactoring", Status: Stable},
+		{ID: "docs", Description: "Tools for writing documentation", Status: Stable, Hints: map[string]string{HintContextSize: "compact"}},
+		{ID: "docs-preview", Description: "Tools for writing documentation", Status: Experimental},
+		{ID: "bare", Status: Stable},
+	}
+}
+
+func TestEmbeddingRanker(t *testing.T) {
+	embedder := &bagOfWords{vocabulary: []string{"coding", "refactoring", "writing", "documentation"}}
+	r := NewEmbeddingRanker(embedder, nil)
+	ctx := context.Background()
+
+	ranked := r.Rank(ctx, VariantHints{Description: "I am writing documentation"}, embeddingTestVariants())
+	assert.Equal(t, []string{"docs", "docs-preview", "coding", "bare"}, variantIDs(ranked))
+	assert.InDelta(t, 1.0, ranked[0].Score, 1e-9)
+	assert.Equal(t, "description similarity 1.00", ranked[0].MatchReason)
+	assert.Zero(t, ranked[2].Score)
+
+	// Equally similar variants are ranked by hints.
+	ranked = r.Rank(ctx, VariantHints{
+		Description: "Writing documentation",
+		Hints:       map[string]any{HintContextSize: "compact"},
+	}, []ServerVariant{embeddingTestVariants()[2], embeddingTestVariants()[1]})
+	assert.Equal(t, []string{"docs", "docs-preview"}, variantIDs(ranked))
+
+	ranked = r.Rank(ctx, VariantHints{Description: "refactoring"}, embeddingTestVariants())
+	assert.Equal(t, "coding", ranked[0].ID)
+
+	// Variant descriptions are embedded once; client descriptions each time.
+	assert.Equal(t, int32(2+3), embedder.texts.Load())
+}
+
+func TestEmbeddingRanker_NoDescription(t *testing.T) {
+	embedder := &bagOfWords{}
+	r := NewEmbeddingRanker(embedder, nil)
+	ranked := r.Rank(context.Background(), VariantHints{Hints: map[string]any{HintContextSize: "compact"}}, embeddingTestVariants())
+	assert.Equal(t, []string{"docs", "coding", "bare", "docs-preview"}, variantIDs(ranked))
+	assert.Zero(t, ranked[0].Score)
+	assert.Empty(t, ranked[0].MatchReason)
+	assert.Zero(t, embedder.texts.Load())
+}
+
+func TestEmbeddingRanker_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		embedder EmbedderFunc
+		want     string
+	}{
+		{
+			name: "embedder error",
+			embedder: func(context.Context, []string) ([][]float64, error) {
+				return nil, errors.New("unavailable")
+			},
+			want: "variants: embedding descriptions: unavailable",
+		},
+		{
+			name: "missing vectors",
+			embedder: func(context.Context, []string) ([][]float64, error) {
+				return [][]float64{{1}}, nil
+			},
+			want: "variants: embedder returned 1 vectors for 3 texts",
+		},
+		{
+			name: "dimension mismatch",
+			embedder: func(_ context.Context, texts []string) ([][]float64, error) {
+				out := make([][]float64, len(texts))
+				for i := range texts {
+					out[i] = make([]float64, i+1)
+				}
+				return out, nil
+			},
+			want: `variants: variant "coding": embeddings have different dimensions 1 and 2`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got error
+			r := NewEmbeddingRanker(tt.embedder, &EmbeddingRankerOptions{OnError: func(err error) { got = err }})
+			ranked := r.Rank(context.Background(), VariantHints{
+				Description: "documentation",
+				Hints:       map[string]any{HintContextSize: "compact"},
+			}, embeddingTestVariants())
+			require.EqualError(t, got, tt.want)
+			assert.Equal(t, []string{"docs", "coding", "bare", "docs-preview"}, variantIDs(ranked), "ranked by hints")
+		})
+	}
+}
+
+func TestEmbeddingRanker_WithRanking(t *testing.T) {
+	embedder := &bagOfWords{vocabulary: []string{"token", "usage", "coding", "workflows"}}
+	vs := newTestVariantServer().WithRanking(NewEmbeddingRanker(embedder, nil).Rank)
+	ranked := vs.RankedVariants(context.Background(), VariantHints{Description: "keep token usage low"})
+	assert.Equal(t, []string{"compact", "coding"}, variantIDs(ranked))
+}
+
+func TestNewEmbeddingRanker_Panics(t *testing.T) {
+	assert.Panics(t, func() { NewEmbeddingRanker(nil, nil) })
+	assert.Panics(t, func() { NewEmbeddingRanker(&bagOfWords{}, &EmbeddingRankerOptions{Tolerance: -1}) })
+}
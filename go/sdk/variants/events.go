@@ -0,0 +1,103 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"time"
+)
+
+// EventKind identifies the type of a lifecycle Event.
+type EventKind string
+
+const (
+	// EventSessionStarted is emitted when a client completes initialize.
+	// VariantID is the session's default variant.
+	EventSessionStarted EventKind = "sessionStarted"
+
+	// EventVariantSelected is emitted when a session starts using a variant:
+	// on its first dispatched request and whenever a request resolves to a
+	// different variant than the session's previous one.
+	EventVariantSelected EventKind = "variantSelected"
+
+	// EventVariantDispatched is emitted after a request was successfully
+	// handled by a variant's backend. Duration is the backend latency.
+	EventVariantDispatched EventKind = "variantDispatched"
+
+	// EventDispatchFailed is emitted when a request could not be routed or
+	// the variant's backend returned an error. Err holds the cause.
+	EventDispatchFailed EventKind = "dispatchFailed"
+
+	// EventDispatchRetried is emitted before a request that failed
+	// transiently is retried (see WithRetryPolicy). Err holds the failure.
+	EventDispatchRetried EventKind = "dispatchRetried"
+
+	// EventDispatchHedged is emitted when a slow request is also sent to an
+	// equivalent variant (see WithHedging). VariantID is that variant.
+	EventDispatchHedged EventKind = "dispatchHedged"
+
+	// EventBackendUnhealthy is emitted when a variant's backend cannot be
+	// connected to. Err holds the cause.
+	EventBackendUnhealthy EventKind = "backendUnhealthy"
+
+	// EventBackendWarmedUp is emitted after a new connection to a
+	// variant's backend was warmed up (see WithWarmup). Duration is the
+	// warmup's; Err holds its failure, if any.
+	EventBackendWarmedUp EventKind = "backendWarmedUp"
+
+	// EventVariantDeprecatedUsed is emitted when a request is dispatched to
+	// a Deprecated variant.
+	EventVariantDeprecatedUsed EventKind = "variantDeprecatedUsed"
+)
+
+// Event describes a variant lifecycle occurrence. Fields that do not apply
+// to an event's Kind are left zero.
+type Event struct {
+	Kind EventKind
+	Time time.Time
+
+	// SessionID is the front session's ID. It is empty for transports
+	// without session IDs, such as stdio.
+	SessionID string
+
+	VariantID string
+	Method    string
+	Duration  time.Duration
+	Err       error
+
+	// Correlation identifies the request the event concerns, if any.
+	Correlation Correlation
+}
+
+// EventHandler receives lifecycle events. Handlers are called synchronously
+// on the request path and must not block; hand events off to a channel or
+// buffer for expensive processing.
+type EventHandler func(ctx context.Context, e Event)
+
+// WithEventHandler registers a handler for variant lifecycle events, for
+// wiring the server into an observability system without depending on a
+// specific metrics library. Multiple handlers may be registered; each
+// receives every event in registration order.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithEventHandler(h EventHandler) *Server {
+	if h != nil {
+		s.eventHandlers = append(s.eventHandlers, h)
+	}
+	return s
+}
+
+// emit delivers e to all registered event handlers, stamping its time and
+// the correlation IDs of the request in ctx.
+func (s *Server) emit(ctx context.Context, e Event) {
+	if len(s.eventHandlers) == 0 {
+		return
+	}
+	e.Time = time.Now()
+	e.Correlation, _ = CorrelationFromContext(ctx)
+	for _, h := range s.eventHandlers {
+		h(ctx, e)
+	}
+}
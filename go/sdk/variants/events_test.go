@@ -0,0 +1,73 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventRecorder collects events delivered to its handler.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (r *eventRecorder) handle(_ context.Context, e Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+// kinds returns the kinds of all recorded events, in order.
+func (r *eventRecorder) kinds() []EventKind {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]EventKind, len(r.events))
+	for i, e := range r.events {
+		out[i] = e.Kind
+	}
+	return out
+}
+
+func TestEvents(t *testing.T) {
+	rec := &eventRecorder{}
+	vs := newDeprecatedVariantServer().WithEventHandler(rec.handle)
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	_, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	_, err = session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	_, err = session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: "legacy"}})
+	require.NoError(t, err)
+	_, err = session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: "nonexistent"}})
+	require.Error(t, err)
+
+	assert.Equal(t, []EventKind{
+		EventSessionStarted,
+		EventVariantSelected, EventVariantDispatched,
+		EventVariantDispatched,
+		EventVariantSelected, EventVariantDeprecatedUsed, EventVariantDispatched,
+		EventDispatchFailed,
+	}, rec.kinds())
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	assert.Equal(t, "coding", rec.events[0].VariantID)
+	assert.Equal(t, "tools/list", rec.events[2].Method)
+	assert.Equal(t, "legacy", rec.events[5].VariantID)
+	assert.Equal(t, "nonexistent", rec.events[7].VariantID)
+	assert.Error(t, rec.events[7].Err)
+	for _, e := range rec.events {
+		assert.False(t, e.Time.IsZero())
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// fanOutResult is the outcome of a tools/call on one variant, as reported in
+// the combined result's structuredContent.
+type fanOutResult struct {
+	Variant string              `json:"variant"`
+	Result  *mcp.CallToolResult `json:"result,omitempty"`
+	Error   *fanOutError        `json:"error,omitempty"`
+}
+
+// fanOutError describes a protocol-level failure of one fanned-out call.
+type fanOutError struct {
+	Code    int64  `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// fanOutVariants extracts the list of variant IDs to fan out to from the
+// request's _meta field:
+//
+//	_meta["io.modelcontextprotocol/server-variant-fanout"] = ["v2", "v3"]
+//
+// Duplicate IDs are dropped. Returns nil if the key is absent or empty.
+func fanOutVariants(req mcp.Request) []string {
+	params := req.GetParams()
+	if isNilInterface(params) {
+		return nil
+	}
+	raw, _ := params.GetMeta()[metaKeyFanOut].([]any)
+	var ids []string
+	seen := make(map[string]bool, len(raw))
+	for _, v := range raw {
+		id, _ := v.(string)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// handleFanOut invokes the same tools/call on each of the given variants
+// concurrently and combines the outcomes into a single result. Every variant
+// ID must be valid, enabled for the client and in rotation; otherwise no
+// call is made.
+//
+// The combined result lists each variant's content in request order,
+// preceded by a text header naming the variant, and carries the per-variant
+// results (or errors) in structuredContent under "results". It is marked
+// IsError only if every variant failed.
+func (d *dispatcher) handleFanOut(ctx context.Context, req mcp.Request, variantIDs []string) (mcp.Result, error) {
+	callReq, ok := req.(*mcp.CallToolRequest)
+	if !ok || callReq.Params == nil {
+		return nil, errors.New("variants: fan-out requires a tools/call request")
+	}
+	fc := d.requestFlagContext(req)
+	for _, id := range variantIDs {
+		if !d.server.hasVariant(id) {
+			return nil, d.createInvalidVariantError(ctx, id)
+		}
+		if unmet := d.server.unmetRules(id, fc.Hints); len(unmet) > 0 {
+			return nil, d.server.ineligibleVariantError(id, unmet)
+		}
+		if !d.server.flagEnabled(ctx, id, fc) {
+			// As for single-variant requests, a disabled variant is
+			// indistinguishable from one that does not exist.
+			return nil, d.createInvalidVariantError(ctx, id)
+		}
+		if !d.server.isAvailable(id) {
+			return nil, d.server.unavailableVariantError(id)
+		}
+	}
+
+	results := make([]fanOutResult, len(variantIDs))
+	var wg sync.WaitGroup
+	for i, id := range variantIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = d.callVariant(ctx, callReq, id)
+		}()
+	}
+	wg.Wait()
+
+	combined := &mcp.CallToolResult{
+		StructuredContent: map[string]any{"results": results},
+		IsError:           true,
+	}
+	for _, r := range results {
+		if r.Error != nil {
+			combined.Content = append(combined.Content, &mcp.TextContent{
+				Text: fmt.Sprintf("variant %s failed: %s", r.Variant, r.Error.Message),
+			})
+			continue
+		}
+		combined.Content = append(combined.Content, &mcp.TextContent{Text: "variant " + r.Variant + ":"})
+		combined.Content = append(combined.Content, r.Result.Content...)
+		if !r.Result.IsError {
+			combined.IsError = false
+		}
+	}
+	return combined, nil
+}
+
+// callVariant performs one fanned-out tools/call. The params are copied so
+// that concurrent calls do not share the _meta map.
+func (d *dispatcher) callVariant(ctx context.Context, req *mcp.CallToolRequest, variantID string) fanOutResult {
+	params := *req.Params
+	params.Meta = maps.Clone(req.Params.Meta)
+	delete(params.Meta, metaKeyFanOut)
+	injectVariantMeta(&params, variantID)
+
+	out := fanOutResult{Variant: variantID}
+	conn, err := d.connection(ctx, variantID)
+	var result mcp.Result
+	if err == nil {
+		result, err = d.receive(ctx, conn, "tools/call", &mcp.CallToolRequest{
+			Session: req.Session,
+			Params:  &params,
+			Extra:   req.Extra,
+		})
+	}
+	if err == nil {
+		if r, ok := result.(*mcp.CallToolResult); ok && r != nil {
+			out.Result = r
+			return out
+		}
+		err = fmt.Errorf("unexpected result type %T", result)
+	}
+
+	out.Error = &fanOutError{Message: err.Error()}
+	var jErr *jsonrpc.Error
+	if errors.As(err, &jErr) {
+		out.Error.Code = jErr.Code
+		out.Error.Message = jErr.Message
+	}
+	return out
+}
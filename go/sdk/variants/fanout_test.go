@@ -0,0 +1,130 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type versionOutput struct {
+	Version string `json:"version"`
+}
+
+// newVersionServer returns an inner server whose "version" tool reports the
+// given version string.
+func newVersionServer(version string) *mcp.Server {
+	s := mcp.NewServer(&mcp.Implementation{Name: "versioned", Version: version}, nil)
+	mcp.AddTool(s, &mcp.Tool{Name: "version", Description: "Report the API version"},
+		func(context.Context, *mcp.CallToolRequest, emptyInput) (*mcp.CallToolResult, versionOutput, error) {
+			return nil, versionOutput{Version: version}, nil
+		})
+	return s
+}
+
+func newFanOutVariantServer() *Server {
+	return NewServer(&mcp.Implementation{Name: "fanout", Version: "v1.0.0"}).
+		WithVariant(ServerVariant{ID: "v2", Status: Stable}, newVersionServer("v2"), 0).
+		WithVariant(ServerVariant{ID: "v3", Status: Experimental}, newVersionServer("v3"), 1).
+		WithVariant(ServerVariant{ID: "other", Status: Stable}, mcp.NewServer(&mcp.Implementation{Name: "other", Version: "v1.0.0"}, nil), 2)
+}
+
+func TestFanOut(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("combined result", func(t *testing.T) {
+		session := connectTestClient(t, newFanOutVariantServer().WithFanOut(true), nil)
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "version",
+			Meta:      mcp.Meta{metaKeyFanOut: []any{"v2", "v3", "other", "v2"}},
+			Arguments: map[string]any{},
+		})
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+
+		data, err := json.Marshal(result.StructuredContent)
+		require.NoError(t, err)
+		var combined struct {
+			Results []struct {
+				Variant string `json:"variant"`
+				Result  *struct {
+					StructuredContent versionOutput `json:"structuredContent"`
+				} `json:"result"`
+				Error *struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			} `json:"results"`
+		}
+		require.NoError(t, json.Unmarshal(data, &combined))
+		require.Len(t, combined.Results, 3, "duplicate variant IDs should be dropped")
+
+		assert.Equal(t, "v2", combined.Results[0].Variant)
+		require.NotNil(t, combined.Results[0].Result)
+		assert.Equal(t, "v2", combined.Results[0].Result.StructuredContent.Version)
+
+		assert.Equal(t, "v3", combined.Results[1].Variant)
+		require.NotNil(t, combined.Results[1].Result)
+		assert.Equal(t, "v3", combined.Results[1].Result.StructuredContent.Version)
+
+		assert.Equal(t, "other", combined.Results[2].Variant)
+		assert.Nil(t, combined.Results[2].Result)
+		require.NotNil(t, combined.Results[2].Error, "tool missing from variant should be reported per variant")
+	})
+
+	t.Run("invalid variant", func(t *testing.T) {
+		session := connectTestClient(t, newFanOutVariantServer().WithFanOut(true), nil)
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "version",
+			Meta:      mcp.Meta{metaKeyFanOut: []any{"v2", "nonexistent"}},
+			Arguments: map[string]any{},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Invalid server variant")
+	})
+
+	t.Run("flagged variant", func(t *testing.T) {
+		vs := newFanOutVariantServer().WithFanOut(true).
+			WithFlagProvider(FlagProviderFunc(func(_ context.Context, id string, _ FlagContext) bool { return id != "v3" }))
+		session := connectTestClient(t, vs, nil)
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "version",
+			Meta:      mcp.Meta{metaKeyFanOut: []any{"v2", "v3"}},
+			Arguments: map[string]any{},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Invalid server variant")
+	})
+
+	t.Run("ineligible variant", func(t *testing.T) {
+		vs := newFanOutVariantServer().WithFanOut(true).
+			WithEligibility("v3", EligibilityRule{Key: HintUseCase, In: []string{"ide"}})
+		session := connectTestClient(t, vs, nil)
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "version",
+			Meta:      mcp.Meta{metaKeyFanOut: []any{"v2", "v3"}},
+			Arguments: map[string]any{},
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not eligible")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		session := connectTestClient(t, newFanOutVariantServer(), nil)
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "version",
+			Meta:      mcp.Meta{metaKeyFanOut: []any{"v2", "v3"}},
+			Arguments: map[string]any{},
+		})
+		require.NoError(t, err)
+		data, err := json.Marshal(result.StructuredContent)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"version":"v2"}`, string(data), "fan-out key should be ignored when disabled")
+	})
+}
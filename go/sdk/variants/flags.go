@@ -0,0 +1,110 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// FlagContext describes the client a feature-flag decision is made for.
+type FlagContext struct {
+	// SessionID is the front session's ID; empty for transports without
+	// session IDs, such as stdio.
+	SessionID string
+
+	// ClientInfo is the client implementation reported at initialize, if
+	// known.
+	ClientInfo *mcp.Implementation
+
+	// Hints are the client's normalized variant hints, if known.
+	Hints VariantHints
+
+	// ProtocolVersion is the MCP protocol version negotiated at
+	// initialize, if known.
+	ProtocolVersion string
+}
+
+// FlagProvider gates variants behind a feature-flag system, enabling gradual
+// exposure of variants by user cohort without custom ranking code.
+type FlagProvider interface {
+	// VariantEnabled reports whether the variant is exposed to the client
+	// described by fc.
+	VariantEnabled(ctx context.Context, variantID string, fc FlagContext) bool
+}
+
+// FlagProviderFunc adapts an ordinary function to the FlagProvider
+// interface.
+type FlagProviderFunc func(ctx context.Context, variantID string, fc FlagContext) bool
+
+// VariantEnabled calls f(ctx, variantID, fc).
+func (f FlagProviderFunc) VariantEnabled(ctx context.Context, variantID string, fc FlagContext) bool {
+	return f(ctx, variantID, fc)
+}
+
+// WithFlagProvider sets a feature-flag provider consulted at ranking and
+// dispatch time. Variants the provider disables for a client are omitted
+// from that client's ranked list, skipped when resolving its default, and
+// rejected as invalid when it selects them explicitly. If nil, all variants
+// are enabled.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithFlagProvider(p FlagProvider) *Server {
+	s.flagProvider = p
+	return s
+}
+
+// newFlagContext builds the flag context for a front session and its hints.
+func newFlagContext(ss *mcp.ServerSession, hints VariantHints) FlagContext {
+	fc := FlagContext{Hints: hints}
+	if ss == nil {
+		return fc
+	}
+	fc.SessionID = ss.ID()
+	if params := ss.InitializeParams(); params != nil {
+		fc.ClientInfo = params.ClientInfo
+		fc.ProtocolVersion = params.ProtocolVersion
+	}
+	return fc
+}
+
+// flagEnabled reports whether the variant is enabled for fc: it supports
+// fc's protocol version (see ServerVariant.MinimumProtocolVersion), fc's
+// hints meet its eligibility rules (see WithEligibility), and the flag
+// provider does not disable it.
+func (s *Server) flagEnabled(ctx context.Context, variantID string, fc FlagContext) bool {
+	if !s.supportsProtocol(variantID, fc.ProtocolVersion) || len(s.unmetRules(variantID, fc.Hints)) > 0 {
+		return false
+	}
+	return s.flagProvider == nil || s.flagProvider.VariantEnabled(ctx, variantID, fc)
+}
+
+// filterFlagged removes variants disabled for fc, in place.
+func (s *Server) filterFlagged(ctx context.Context, fc FlagContext, vs []ServerVariant) []ServerVariant {
+	if s.flagProvider == nil && !s.versionGated && len(s.eligibility) == 0 {
+		return vs
+	}
+	out := vs[:0]
+	for _, v := range vs {
+		if s.flagEnabled(ctx, v.ID, fc) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// requestFlagContext returns the flag context for a dispatched request. Per
+// session dispatchers use the context recorded at initialize; the shared
+// stateless dispatcher derives it from the request's session.
+func (d *dispatcher) requestFlagContext(req mcp.Request) FlagContext {
+	if !d.shared {
+		d.mu.RLock()
+		defer d.mu.RUnlock()
+		return d.flagCtx
+	}
+	ss, _ := req.GetSession().(*mcp.ServerSession)
+	return newFlagContext(ss, VariantHints{})
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagProvider(t *testing.T) {
+	ctx := context.Background()
+
+	// Disable the given variant for the test client's cohort.
+	disableFor := func(variantID string) FlagProvider {
+		return FlagProviderFunc(func(_ context.Context, id string, fc FlagContext) bool {
+			return !(id == variantID && fc.ClientInfo != nil && fc.ClientInfo.Name == "test-client")
+		})
+	}
+
+	t.Run("hidden and rejected", func(t *testing.T) {
+		session := connectTestClient(t, newTestVariantServer().WithFlagProvider(disableFor("compact")), nil)
+
+		var p struct {
+			AvailableVariants []struct {
+				ID string `json:"id"`
+			} `json:"availableVariants"`
+		}
+		initExtension(t, session, &p)
+		require.Len(t, p.AvailableVariants, 1)
+		assert.Equal(t, "coding", p.AvailableVariants[0].ID)
+
+		_, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: "compact"}})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Invalid server variant")
+	})
+
+	t.Run("default skips disabled variant", func(t *testing.T) {
+		session := connectTestClient(t, newTestVariantServer().WithFlagProvider(disableFor("coding")), nil)
+
+		tools, err := session.ListTools(ctx, nil)
+		require.NoError(t, err)
+		assert.Contains(t, toolNames(tools.Tools), "summarize")
+	})
+
+	t.Run("stateless default skips disabled variant", func(t *testing.T) {
+		// Disable the default only for real sessions, so that resolving it
+		// without a client would pick it.
+		vs := newTestVariantServer().WithFlagProvider(FlagProviderFunc(func(_ context.Context, id string, fc FlagContext) bool {
+			return !(id == "coding" && fc.SessionID != "")
+		}))
+		session := connectStatelessTestClient(t, vs)
+
+		tools, err := session.ListTools(ctx, nil)
+		require.NoError(t, err)
+		assert.Contains(t, toolNames(tools.Tools), "summarize")
+	})
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// A VariantGroup relates variants to each other, e.g. the versions of an
+// API ("api-generation": v1, v2, v3) or the domains of a toolset
+// ("tool-domain": code-review, pm, security). Groups are advertised to
+// clients in the "variantGroups" field of the initialize payload.
+type VariantGroup struct {
+	// ID identifies the group. It must be unique among the server's groups.
+	ID string `json:"id"`
+
+	// Description is a human-readable description of the group.
+	Description string `json:"description,omitempty"`
+
+	// Exclusive marks the variants as alternatives, of which a session uses
+	// one at a time. Variants of a non-exclusive group can be used
+	// together.
+	Exclusive bool `json:"exclusive"`
+
+	// Variants are the IDs of the group's variants. A variant may belong
+	// to several groups.
+	Variants []string `json:"variants"`
+}
+
+// WithVariantGroup declares a group of variants. Its variants must be
+// registered by the time serving starts. Advertised groups list only the
+// variants advertised to the client, and groups with none are omitted.
+//
+// It panics if the group has no ID or a group with the same ID was already
+// declared.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithVariantGroup(g VariantGroup) *Server {
+	if g.ID == "" {
+		panic("variants: variant group ID must not be empty")
+	}
+	if slices.ContainsFunc(s.groups, func(x VariantGroup) bool { return x.ID == g.ID }) {
+		panic(fmt.Sprintf("variants: duplicate variant group %q", g.ID))
+	}
+	g.Variants = slices.Clone(g.Variants)
+	s.groups = append(s.groups, g)
+	return s
+}
+
+// VariantGroups returns a copy of the declared variant groups, in
+// declaration order.
+func (s *Server) VariantGroups() []VariantGroup {
+	out := make([]VariantGroup, len(s.groups))
+	for i, g := range s.groups {
+		g.Variants = slices.Clone(g.Variants)
+		out[i] = g
+	}
+	return out
+}
+
+// ValidateSelection reports whether the variants with the given IDs can be
+// used together: it fails with an invalid params error if two of them are
+// alternatives in an exclusive group. The error data names the group and
+// the conflicting variants.
+func (s *Server) ValidateSelection(ids []string) error {
+	for _, g := range s.groups {
+		if !g.Exclusive {
+			continue
+		}
+		var conflicting []string
+		for _, id := range ids {
+			if slices.Contains(g.Variants, id) && !slices.Contains(conflicting, id) {
+				conflicting = append(conflicting, id)
+			}
+		}
+		if len(conflicting) > 1 {
+			dataJSON, _ := json.Marshal(map[string]any{
+				"group":               g.ID,
+				"conflictingVariants": conflicting,
+			})
+			return &jsonrpc.Error{
+				Code:    jsonrpc.CodeInvalidParams,
+				Message: "Server variants are mutually exclusive",
+				Data:    json.RawMessage(dataJSON),
+			}
+		}
+	}
+	return nil
+}
+
+// validateVariantGroups reports groups naming unregistered variants.
+func (s *Server) validateVariantGroups() error {
+	for _, g := range s.groups {
+		for _, id := range g.Variants {
+			if !s.hasVariant(id) {
+				return fmt.Errorf("variants: variant group %q names unregistered variant %q", g.ID, id)
+			}
+		}
+	}
+	return nil
+}
+
+// groupsPayload returns the groups to advertise alongside the listed
+// variants, restricted to those variants.
+func (s *Server) groupsPayload(listed []ServerVariant) []VariantGroup {
+	var out []VariantGroup
+	for _, g := range s.groups {
+		var members []string
+		for _, id := range g.Variants {
+			if slices.ContainsFunc(listed, func(v ServerVariant) bool { return v.ID == id }) {
+				members = append(members, id)
+			}
+		}
+		if len(members) > 0 {
+			g.Variants = members
+			out = append(out, g)
+		}
+	}
+	return out
+}
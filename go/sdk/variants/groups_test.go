@@ -0,0 +1,65 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariantGroups_Advertised(t *testing.T) {
+	vs := newTestVariantServer().
+		WithVariantGroup(VariantGroup{ID: "api-generation", Exclusive: true, Variants: []string{"coding", "compact"}}).
+		WithVariantGroup(VariantGroup{ID: "tool-domain", Description: "Coexisting toolsets", Variants: []string{"compact"}})
+	require.NoError(t, vs.SetVariantAvailability("compact", false, "maintenance"))
+
+	var payload struct {
+		VariantGroups []VariantGroup `json:"variantGroups"`
+	}
+	initExtension(t, connectTestClient(t, vs, nil), &payload)
+	assert.Equal(t, []VariantGroup{
+		{ID: "api-generation", Exclusive: true, Variants: []string{"coding"}},
+	}, payload.VariantGroups, "unlisted variants and empty groups are omitted")
+}
+
+func TestVariantGroups_NoneDeclared(t *testing.T) {
+	var payload map[string]any
+	initExtension(t, connectTestClient(t, newTestVariantServer(), nil), &payload)
+	assert.NotContains(t, payload, "variantGroups")
+}
+
+func TestValidateSelection(t *testing.T) {
+	vs := newTestVariantServer().
+		WithVariantGroup(VariantGroup{ID: "api-generation", Exclusive: true, Variants: []string{"coding", "compact"}}).
+		WithVariantGroup(VariantGroup{ID: "tool-domain", Variants: []string{"coding", "compact"}})
+
+	assert.NoError(t, vs.ValidateSelection([]string{"coding"}))
+	assert.NoError(t, vs.ValidateSelection([]string{"coding", "coding"}))
+
+	err := vs.ValidateSelection([]string{"coding", "compact"})
+	var rpcErr *jsonrpc.Error
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, int64(jsonrpc.CodeInvalidParams), rpcErr.Code)
+	var data map[string]any
+	require.NoError(t, json.Unmarshal(rpcErr.Data, &data))
+	assert.Equal(t, "api-generation", data["group"])
+	assert.Equal(t, []any{"coding", "compact"}, data["conflictingVariants"])
+}
+
+func TestVariantGroups_Validation(t *testing.T) {
+	_, err := newTestVariantServer().
+		WithVariantGroup(VariantGroup{ID: "g", Variants: []string{"coding", "ghost"}}).
+		NewRouter(nil)
+	assert.ErrorContains(t, err, `variant group "g" names unregistered variant "ghost"`)
+
+	assert.Panics(t, func() { newTestVariantServer().WithVariantGroup(VariantGroup{}) })
+	assert.Panics(t, func() {
+		newTestVariantServer().WithVariantGroup(VariantGroup{ID: "g"}).WithVariantGroup(VariantGroup{ID: "g"})
+	})
+}
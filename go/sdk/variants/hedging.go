@@ -0,0 +1,190 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// HedgingOptions configures hedged requests (see WithHedging).
+type HedgingOptions struct {
+	// Equivalent lists sets of variants that serve identical data, such as
+	// replicas of one server: the same tools, prompts and resources, and
+	// interchangeable pagination cursors. A variant may belong to one set.
+	Equivalent [][]string
+
+	// Delay is how long a request waits for its variant before it is also
+	// sent to an equivalent one. Zero means 100ms.
+	Delay time.Duration
+}
+
+// defaultHedgeDelay is the hedging delay when HedgingOptions.Delay is zero.
+const defaultHedgeDelay = 100 * time.Millisecond
+
+// WithHedging cuts the tail latency of idempotent list and read requests
+// (tools/list, prompts/list, resources/list, resources/templates/list and
+// resources/read) when a backend is slow: if the selected variant has not
+// responded within the delay, the request is also sent to the first
+// equivalent variant in rotation, and the first successful response wins.
+// The other request is canceled. Responses are returned as the selected
+// variant's, so equivalent variants must be interchangeable. Each hedged
+// request emits an EventDispatchHedged.
+//
+// The variants must be registered by the time serving starts. It panics if
+// the delay is negative, a set has fewer than two variants, or a variant
+// belongs to several sets.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithHedging(opts HedgingOptions) *Server {
+	if opts.Delay < 0 {
+		panic("variants: negative hedging delay")
+	}
+	if opts.Delay == 0 {
+		opts.Delay = defaultHedgeDelay
+	}
+	h := &hedging{delay: opts.Delay, equivalent: make(map[string][]string)}
+	for _, set := range opts.Equivalent {
+		if len(set) < 2 {
+			panic(fmt.Sprintf("variants: equivalence set %q needs at least two variants", set))
+		}
+		for _, id := range set {
+			if _, ok := h.equivalent[id]; ok {
+				panic(fmt.Sprintf("variants: variant %q is in several equivalence sets", id))
+			}
+			h.equivalent[id] = set
+		}
+	}
+	s.hedging = h
+	return s
+}
+
+// hedging is the resolved HedgingOptions.
+type hedging struct {
+	delay      time.Duration
+	equivalent map[string][]string // variant ID -> its equivalence set
+}
+
+// validateHedging checks that equivalence sets name registered variants.
+func (s *Server) validateHedging() error {
+	if s.hedging == nil {
+		return nil
+	}
+	for id := range s.hedging.equivalent {
+		if !s.hasVariant(id) {
+			return fmt.Errorf("variants: equivalence set names unregistered variant %q", id)
+		}
+	}
+	return nil
+}
+
+// hedgeableMethod reports whether method may be hedged.
+func hedgeableMethod(method string) bool {
+	switch method {
+	case "tools/list", "prompts/list", "resources/list", "resources/templates/list", "resources/read":
+		return true
+	}
+	return false
+}
+
+// hedgeTarget returns the variant to hedge req of the given method to, and
+// reports whether the request is hedged. Variants out of rotation or
+// disabled for the client (see flagEnabled) are not hedged to.
+func (d *dispatcher) hedgeTarget(ctx context.Context, req mcp.Request, variantID, method string) (string, bool) {
+	s := d.server
+	if s.hedging == nil || !hedgeableMethod(method) {
+		return "", false
+	}
+	fc := d.requestFlagContext(req)
+	for _, id := range s.hedging.equivalent[variantID] {
+		if id != variantID && s.isAvailable(id) && s.flagEnabled(ctx, id, fc) {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// receiveHedged is receive, hedging the request to an equivalent variant
+// if conn's variant is slow to respond (see WithHedging).
+func (d *dispatcher) receiveHedged(ctx context.Context, conn *innerConnection, method string, req mcp.Request) (mcp.Result, error) {
+	alt, ok := d.hedgeTarget(ctx, req, conn.backendSession.variantID, method)
+	if !ok {
+		return d.receive(ctx, conn, method, req)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type outcome struct {
+		result mcp.Result
+		err    error
+	}
+	outcomes := make(chan outcome, 2)
+	send := func(conn *innerConnection, req mcp.Request) {
+		result, err := d.receive(ctx, conn, method, req)
+		outcomes <- outcome{result, err}
+	}
+	go send(conn, req)
+
+	timer := time.NewTimer(d.server.hedging.delay)
+	defer timer.Stop()
+	select {
+	case o := <-outcomes:
+		return o.result, o.err
+	case <-timer.C:
+	}
+
+	altReq, err := requestFor(req, alt)
+	if err != nil {
+		o := <-outcomes
+		return o.result, o.err
+	}
+	altConn, err := d.connection(ctx, alt)
+	if err != nil {
+		o := <-outcomes
+		return o.result, o.err
+	}
+	d.server.emit(ctx, Event{Kind: EventDispatchHedged, SessionID: sessionID(req), VariantID: alt, Method: method})
+	go send(altConn, altReq)
+
+	first := <-outcomes
+	if first.err == nil {
+		return first.result, nil
+	}
+	if second := <-outcomes; second.err == nil {
+		return second.result, nil
+	}
+	return first.result, first.err
+}
+
+// requestFor returns a copy of req selecting variantID, so that it can be
+// sent concurrently with req.
+func requestFor(req mcp.Request, variantID string) (mcp.Request, error) {
+	reqVal := reflect.ValueOf(req)
+	if reqVal.Kind() != reflect.Ptr {
+		return nil, errParamsNotPointer
+	}
+	copyPtr := reflect.New(reqVal.Elem().Type())
+	copyPtr.Elem().Set(reqVal.Elem())
+	params := req.GetParams()
+	if isNilInterface(params) {
+		return copyPtr.Interface().(mcp.Request), nil
+	}
+	paramsVal := reflect.ValueOf(params)
+	if paramsVal.Kind() != reflect.Ptr {
+		return nil, errParamsNotPointer
+	}
+	paramsCopy := reflect.New(paramsVal.Elem().Type())
+	paramsCopy.Elem().Set(paramsVal.Elem())
+	p := paramsCopy.Interface().(mcp.Params)
+	p.SetMeta(maps.Clone(p.GetMeta()))
+	injectVariantMeta(p, variantID)
+	copyPtr.Elem().FieldByName("Params").Set(paramsCopy)
+	return copyPtr.Interface().(mcp.Request), nil
+}
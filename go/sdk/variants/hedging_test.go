@@ -0,0 +1,105 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReplicaServer returns a server with a docs://guide resource whose
+// text is name, read after delay or when the read is canceled.
+func newReplicaServer(name string, delay time.Duration) *mcp.Server {
+	srv := mcp.NewServer(&mcp.Implementation{Name: name, Version: "v1.0.0"}, nil)
+	srv.AddResource(&mcp.Resource{URI: "docs://guide", Name: "guide"}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: req.Params.URI, Text: name}}}, nil
+	})
+	return srv
+}
+
+func TestHedging(t *testing.T) {
+	vs := NewServer(&mcp.Implementation{Name: "docs", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "primary", Status: Stable}, newReplicaServer("primary", time.Minute), 0).
+		WithVariant(ServerVariant{ID: "replica", Status: Stable}, newReplicaServer("replica", 0), 1).
+		WithHedging(HedgingOptions{Equivalent: [][]string{{"primary", "replica"}}, Delay: 10 * time.Millisecond})
+	var mu sync.Mutex
+	var hedged []string
+	vs.WithEventHandler(func(ctx context.Context, e Event) {
+		if e.Kind == EventDispatchHedged {
+			mu.Lock()
+			hedged = append(hedged, e.VariantID)
+			mu.Unlock()
+		}
+	})
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	// The slow primary is hedged to the replica.
+	res, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "docs://guide"})
+	require.NoError(t, err)
+	require.Len(t, res.Contents, 1)
+	assert.Equal(t, "replica", res.Contents[0].Text)
+
+	// Fast variants are not hedged.
+	res, err = session.ReadResource(ctx, &mcp.ReadResourceParams{Meta: mcp.Meta{metaKeyVariant: "replica"}, URI: "docs://guide"})
+	require.NoError(t, err)
+	assert.Equal(t, "replica", res.Contents[0].Text)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"replica"}, hedged)
+}
+
+func TestHedging_UnavailableReplica(t *testing.T) {
+	vs := NewServer(&mcp.Implementation{Name: "docs", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "primary", Status: Stable}, newReplicaServer("primary", 50*time.Millisecond), 0).
+		WithVariant(ServerVariant{ID: "replica", Status: Stable}, newReplicaServer("replica", 0), 1).
+		WithHedging(HedgingOptions{Equivalent: [][]string{{"primary", "replica"}}, Delay: time.Millisecond})
+	require.NoError(t, vs.SetVariantAvailability("replica", false, "maintenance"))
+	session := connectTestClient(t, vs, nil)
+
+	res, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "docs://guide"})
+	require.NoError(t, err)
+	assert.Equal(t, "primary", res.Contents[0].Text)
+}
+
+func TestHedging_FlaggedReplica(t *testing.T) {
+	vs := NewServer(&mcp.Implementation{Name: "docs", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "primary", Status: Stable}, newReplicaServer("primary", 50*time.Millisecond), 0).
+		WithVariant(ServerVariant{ID: "replica", Status: Stable}, newReplicaServer("replica", 0), 1).
+		WithHedging(HedgingOptions{Equivalent: [][]string{{"primary", "replica"}}, Delay: time.Millisecond}).
+		WithFlagProvider(FlagProviderFunc(func(_ context.Context, id string, _ FlagContext) bool { return id != "replica" }))
+	session := connectTestClient(t, vs, nil)
+
+	res, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "docs://guide"})
+	require.NoError(t, err)
+	assert.Equal(t, "primary", res.Contents[0].Text)
+}
+
+func TestHedging_Validation(t *testing.T) {
+	_, err := newTestVariantServer().
+		WithHedging(HedgingOptions{Equivalent: [][]string{{"coding", "ghost"}}}).
+		NewRouter(nil)
+	assert.ErrorContains(t, err, `equivalence set names unregistered variant "ghost"`)
+
+	assert.Panics(t, func() { newTestVariantServer().WithHedging(HedgingOptions{Delay: -1}) })
+	assert.Panics(t, func() {
+		newTestVariantServer().WithHedging(HedgingOptions{Equivalent: [][]string{{"coding"}}})
+	})
+	assert.Panics(t, func() {
+		newTestVariantServer().WithHedging(HedgingOptions{Equivalent: [][]string{{"coding", "compact"}, {"compact", "coding"}}})
+	})
+}
@@ -0,0 +1,156 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// HintLimits bound the size of the variant hints a client may send, so that
+// ranking functions, hint statistics and logs never process unbounded
+// client-controlled input. See Server.WithHintLimits.
+//
+// For each limit, zero means the default and a negative value means no
+// limit.
+type HintLimits struct {
+	// MaxHints is the maximum number of hint keys. Default 32.
+	MaxHints int
+
+	// MaxKeyLength is the maximum length of a hint key, in bytes.
+	// Default 128.
+	MaxKeyLength int
+
+	// MaxValueLength is the maximum length of a hint value string, or of
+	// each string of an array value, in bytes. Default 256.
+	MaxValueLength int
+
+	// MaxValues is the maximum number of strings in an array value,
+	// counting the elements of nested arrays. Default 32.
+	MaxValues int
+
+	// MaxDescriptionLength is the maximum length of the description, in
+	// bytes. Default 2048.
+	MaxDescriptionLength int
+}
+
+// Default hint limits.
+const (
+	defaultMaxHints                 = 32
+	defaultMaxHintKeyLength         = 128
+	defaultMaxHintValueLength       = 256
+	defaultMaxHintValues            = 32
+	defaultMaxHintDescriptionLength = 2048
+)
+
+// WithHintLimits sets the limits on the variant hints clients send at
+// initialize and in hint updates. Hints exceeding a limit are rejected as a
+// whole, failing the request with an invalid params error whose data names
+// the limit, e.g.
+//
+//	{"limit": "maxHints", "max": 32, "actual": 1000}
+//
+// or, for limits on keys and values, also the offending "key". Limits apply
+// by default; see HintLimits.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithHintLimits(l HintLimits) *Server {
+	s.hintLimits = l
+	return s
+}
+
+// limit returns the effective value of a limit: def if it is zero, and -1
+// (no limit) if it is negative.
+func limit(v, def int) int {
+	switch {
+	case v == 0:
+		return def
+	case v < 0:
+		return -1
+	default:
+		return v
+	}
+}
+
+// exceeds reports whether n exceeds bound, where -1 means no limit.
+func exceeds(n, bound int) bool {
+	return bound >= 0 && n > bound
+}
+
+// checkHintLimits returns an error if raw hints exceed the server's hint
+// limits.
+func (s *Server) checkHintLimits(raw VariantHints) error {
+	l := s.hintLimits
+	if bound := limit(l.MaxDescriptionLength, defaultMaxHintDescriptionLength); exceeds(len(raw.Description), bound) {
+		return hintLimitError("maxDescriptionLength", "", bound, len(raw.Description))
+	}
+	if bound := limit(l.MaxHints, defaultMaxHints); exceeds(len(raw.Hints), bound) {
+		return hintLimitError("maxHints", "", bound, len(raw.Hints))
+	}
+	maxKey := limit(l.MaxKeyLength, defaultMaxHintKeyLength)
+	maxValue := limit(l.MaxValueLength, defaultMaxHintValueLength)
+	maxValues := limit(l.MaxValues, defaultMaxHintValues)
+	for key, value := range raw.Hints {
+		if exceeds(len(key), maxKey) {
+			return hintLimitError("maxKeyLength", key[:maxKey], maxKey, len(key))
+		}
+		longest, n := hintValueSize(value, maxValues)
+		if exceeds(longest, maxValue) {
+			return hintLimitError("maxValueLength", key, maxValue, longest)
+		}
+		if exceeds(n, maxValues) {
+			return hintLimitError("maxValues", key, maxValues, n)
+		}
+	}
+	return nil
+}
+
+// hintValueSize returns the length of the longest string in a hint value
+// and the number of strings in it, recursing into nested arrays. Counting
+// stops once n exceeds maxValues (unless -1), bounding the work done for
+// oversized values.
+func hintValueSize(v any, maxValues int) (longest, n int) {
+	var walk func(v any)
+	walk = func(v any) {
+		if exceeds(n, maxValues) {
+			return
+		}
+		switch v := v.(type) {
+		case string:
+			n++
+			longest = max(longest, len(v))
+		case []string:
+			for _, e := range v {
+				walk(e)
+			}
+		case []any:
+			for _, e := range v {
+				walk(e)
+			}
+		}
+	}
+	walk(v)
+	// A single string is not an array value.
+	if _, ok := v.(string); ok {
+		n = 0
+	}
+	return longest, n
+}
+
+// hintLimitError reports hints exceeding a limit. key is the offending hint
+// key, if any; over-long keys are truncated to the limit.
+func hintLimitError(name, key string, bound, actual int) error {
+	data := map[string]any{"limit": name, "max": bound, "actual": actual}
+	if key != "" {
+		data["key"] = key
+	}
+	dataJSON, _ := json.Marshal(data)
+	return &jsonrpc.Error{
+		Code:    jsonrpc.CodeInvalidParams,
+		Message: "Variant hints exceed limits",
+		Data:    json.RawMessage(dataJSON),
+	}
+}
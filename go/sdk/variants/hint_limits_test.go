@@ -0,0 +1,106 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckHintLimits(t *testing.T) {
+	manyHints := map[string]any{}
+	for i := range 33 {
+		manyHints[fmt.Sprintf("k%d", i)] = "v"
+	}
+	manyValues := make([]any, 40)
+	for i := range manyValues {
+		manyValues[i] = "v"
+	}
+
+	tests := []struct {
+		name   string
+		limits HintLimits
+		raw    VariantHints
+		want   string // expected error data, or empty for no error
+	}{
+		{
+			name: "within defaults",
+			raw:  VariantHints{Description: "agent", Hints: map[string]any{"modelFamily": []any{"a", []any{"b"}}}},
+		},
+		{
+			name: "too many hints",
+			raw:  VariantHints{Hints: manyHints},
+			want: `{"limit":"maxHints","max":32,"actual":33}`,
+		},
+		{
+			name: "long key",
+			raw:  VariantHints{Hints: map[string]any{strings.Repeat("k", 200): "v"}},
+			want: `{"limit":"maxKeyLength","key":"` + strings.Repeat("k", 128) + `","max":128,"actual":200}`,
+		},
+		{
+			name: "long value in array",
+			raw:  VariantHints{Hints: map[string]any{"useCase": []any{"a", strings.Repeat("v", 300)}}},
+			want: `{"limit":"maxValueLength","key":"useCase","max":256,"actual":300}`,
+		},
+		{
+			name: "too many values",
+			raw:  VariantHints{Hints: map[string]any{"useCase": manyValues}},
+			want: `{"limit":"maxValues","key":"useCase","max":32,"actual":33}`,
+		},
+		{
+			name: "long description",
+			raw:  VariantHints{Description: strings.Repeat("d", 2049)},
+			want: `{"limit":"maxDescriptionLength","max":2048,"actual":2049}`,
+		},
+		{
+			name:   "custom limit",
+			limits: HintLimits{MaxHints: 1},
+			raw:    VariantHints{Hints: map[string]any{"a": "1", "b": "2"}},
+			want:   `{"limit":"maxHints","max":1,"actual":2}`,
+		},
+		{
+			name:   "no limit",
+			limits: HintLimits{MaxHints: -1},
+			raw:    VariantHints{Hints: manyHints},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vs := NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}).WithHintLimits(tt.limits)
+			err := vs.checkHintLimits(tt.raw)
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var jErr *jsonrpc.Error
+			require.True(t, errors.As(err, &jErr))
+			assert.Equal(t, int64(jsonrpc.CodeInvalidParams), jErr.Code)
+			assert.JSONEq(t, tt.want, string(jErr.Data))
+		})
+	}
+}
+
+func TestHintLimits_Initialize(t *testing.T) {
+	vs := newTestVariantServer().WithHintLimits(HintLimits{MaxHints: 1})
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = vs.Run(ctx, serverTransport) }()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"},
+		hintsClientOptions(map[string]any{"modelFamily": "a", "useCase": "b"}))
+	_, err := client.Connect(ctx, clientTransport, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Variant hints exceed limits")
+	assert.Equal(t, HintStats{Keys: map[string]HintKeyStats{}}, vs.HintStats())
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"log/slog"
+	"maps"
+	"sync"
+)
+
+// Cardinality limits of HintStats, bounding its memory use when clients send
+// arbitrary keys and values. Once a limit is reached, the least frequent key
+// or value makes room for a new one.
+const (
+	maxHintStatsKeys   = 256
+	maxHintStatsValues = 64
+)
+
+// HintStats aggregates the variant hints received from clients since the
+// server was created, in memory, so that server authors can learn which hints real
+// clients send and design their hint vocabularies accordingly. It is
+// returned by [Server.HintStats], is JSON-serializable for export, and
+// implements [slog.LogValuer] for logging:
+//
+//	logger.Info("variant hints", "stats", vs.HintStats())
+//
+// The statistics are not persisted: export snapshots periodically to keep
+// them across restarts.
+type HintStats struct {
+	// Received is the number of hint sets received: one per initialize
+	// request and one per hint update.
+	Received int64 `json:"received"`
+
+	// Empty is the number of hint sets with neither hints nor a
+	// description.
+	Empty int64 `json:"empty"`
+
+	// WithDescription is the number of hint sets with a description.
+	WithDescription int64 `json:"withDescription"`
+
+	// Keys holds statistics per hint key, for keys the server understands
+	// and unknown keys alike. At most 256 keys are tracked: a new key
+	// evicts the least frequent one, whose hints are then counted in
+	// OtherKeys.
+	Keys map[string]HintKeyStats `json:"keys,omitempty"`
+
+	// OtherKeys is the number of hints whose keys are not tracked,
+	// including those of evicted keys.
+	OtherKeys int64 `json:"otherKeys,omitempty"`
+}
+
+// HintKeyStats aggregates the hints received for one key.
+type HintKeyStats struct {
+	// Count is the number of hint sets carrying the key.
+	Count int64 `json:"count"`
+
+	// Known reports whether the server understands the key (a well-known
+	// key, a key of a registered variant's Hints, or a namespaced key).
+	Known bool `json:"known"`
+
+	// Ignored is the number of times the key was dropped by normalization:
+	// always for unknown keys, and for known keys with empty or invalid
+	// values.
+	Ignored int64 `json:"ignored,omitempty"`
+
+	// Values counts the received string values, after trimming; each
+	// element of an array value counts separately. At most 64 values are
+	// tracked per key: a new value evicts the least frequent one, which is
+	// then counted in OtherValues.
+	Values map[string]int64 `json:"values,omitempty"`
+
+	// OtherValues is the number of values that are not tracked, including
+	// evicted ones.
+	OtherValues int64 `json:"otherValues,omitempty"`
+}
+
+// LogValue implements [slog.LogValuer], logging the totals and the key and
+// value counts.
+func (st HintStats) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.Int64("received", st.Received),
+		slog.Int64("empty", st.Empty),
+		slog.Int64("withDescription", st.WithDescription),
+	}
+	for _, k := range sortedKeys(st.Keys) {
+		ks := st.Keys[k]
+		group := []any{slog.Int64("count", ks.Count), slog.Bool("known", ks.Known)}
+		if ks.Ignored > 0 {
+			group = append(group, slog.Int64("ignored", ks.Ignored))
+		}
+		if len(ks.Values) > 0 {
+			group = append(group, slog.Any("values", ks.Values))
+		}
+		if ks.OtherValues > 0 {
+			group = append(group, slog.Int64("otherValues", ks.OtherValues))
+		}
+		attrs = append(attrs, slog.Group(k, group...))
+	}
+	if st.OtherKeys > 0 {
+		attrs = append(attrs, slog.Int64("otherKeys", st.OtherKeys))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// HintStats returns the statistics of the variant hints received so far.
+// Statistics are kept in memory for the server's lifetime.
+func (s *Server) HintStats() HintStats {
+	return s.hintStats.snapshot()
+}
+
+// hintStatsCollector accumulates HintStats. The zero value is ready to use.
+type hintStatsCollector struct {
+	mu    sync.Mutex
+	stats HintStats
+}
+
+// record adds a hint set received from a client, with the report of its
+// normalization.
+func (c *hintStatsCollector) record(s *Server, raw VariantHints, report NormalizedHints) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st := &c.stats
+	st.Received++
+	if report.Description != "" {
+		st.WithDescription++
+	}
+	if report.Description == "" && len(raw.Hints) == 0 {
+		st.Empty++
+	}
+	for key, value := range raw.Hints {
+		ks, ok := st.Keys[key]
+		if !ok {
+			if len(st.Keys) >= maxHintStatsKeys {
+				evicted := leastFrequent(st.Keys, func(ks HintKeyStats) int64 { return ks.Count })
+				st.OtherKeys += st.Keys[evicted].Count
+				delete(st.Keys, evicted)
+			}
+			if st.Keys == nil {
+				st.Keys = make(map[string]HintKeyStats)
+			}
+			ks.Known = s.isKnownHintKey(key)
+		}
+		ks.Count++
+		if _, ignored := report.Ignored[key]; ignored {
+			ks.Ignored++
+		}
+		for _, v := range flattenHintValues(nil, value) {
+			if _, ok := ks.Values[v]; !ok && len(ks.Values) >= maxHintStatsValues {
+				evicted := leastFrequent(ks.Values, func(n int64) int64 { return n })
+				ks.OtherValues += ks.Values[evicted]
+				delete(ks.Values, evicted)
+			}
+			if ks.Values == nil {
+				ks.Values = make(map[string]int64)
+			}
+			ks.Values[v]++
+		}
+		st.Keys[key] = ks
+	}
+}
+
+// leastFrequent returns the key of m with the lowest count, the first in
+// sort order among ties. m must not be empty.
+func leastFrequent[V any](m map[string]V, count func(V) int64) string {
+	var (
+		least  string
+		fewest int64
+		found  bool
+	)
+	for k, v := range m {
+		if n := count(v); !found || n < fewest || n == fewest && k < least {
+			least, fewest, found = k, n, true
+		}
+	}
+	return least
+}
+
+// snapshot returns a copy of the statistics.
+func (c *hintStatsCollector) snapshot() HintStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st := c.stats
+	st.Keys = make(map[string]HintKeyStats, len(c.stats.Keys))
+	for k, ks := range c.stats.Keys {
+		ks.Values = maps.Clone(ks.Values)
+		st.Keys[k] = ks
+	}
+	return st
+}
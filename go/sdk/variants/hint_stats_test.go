@@ -0,0 +1,110 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHintStats(t *testing.T) {
+	vs := newTestVariantServer()
+	session := connectTestClient(t, vs, hintsClientOptions(map[string]any{
+		HintContextSize: "compact",
+		HintUseCase:     []any{"coding", " review ", ""},
+		"tier":          "gold",
+	}))
+
+	// A hint update counts as another hint set.
+	_, err := session.ListTools(context.Background(), &mcp.ListToolsParams{
+		Meta: mcp.Meta{metaKeyVariantHints: map[string]any{
+			"hints": map[string]any{HintContextSize: "compact", HintModelFamily: 42},
+		}},
+	})
+	require.NoError(t, err)
+
+	st := vs.HintStats()
+	assert.Equal(t, int64(2), st.Received)
+	assert.Equal(t, int64(0), st.Empty)
+	assert.Equal(t, map[string]HintKeyStats{
+		HintContextSize: {Count: 2, Known: true, Values: map[string]int64{"compact": 2}},
+		HintUseCase:     {Count: 1, Known: true, Values: map[string]int64{"coding": 1, "review": 1}},
+		HintModelFamily: {Count: 1, Known: true, Ignored: 1},
+		"tier":          {Count: 1, Ignored: 1, Values: map[string]int64{"gold": 1}},
+	}, st.Keys)
+
+	// Snapshots are independent of later updates.
+	st.Keys[HintContextSize].Values["compact"] = 100
+	assert.Equal(t, int64(2), vs.HintStats().Keys[HintContextSize].Values["compact"])
+}
+
+func TestHintStats_Limits(t *testing.T) {
+	vs := newTestVariantServer()
+	hints := make(map[string]any)
+	for i := range maxHintStatsKeys + 10 {
+		hints[fmt.Sprintf("key%d", i)] = "x"
+	}
+	values := make([]any, maxHintStatsValues+5)
+	for i := range values {
+		values[i] = fmt.Sprintf("v%d", i)
+	}
+	vs.hintStats.record(vs, VariantHints{Hints: hints}, NormalizedHints{})
+	var frequent string
+	for k := range vs.HintStats().Keys {
+		frequent = k
+		break
+	}
+	vs.hintStats.record(vs, VariantHints{Hints: map[string]any{frequent: "x"}}, NormalizedHints{})
+	// Once the key limit is reached, new keys evict the least frequent
+	// ones, so keys sent later are still tracked.
+	vs.hintStats.record(vs, VariantHints{Hints: map[string]any{HintUseCase: values}}, NormalizedHints{})
+
+	st := vs.HintStats()
+	assert.Len(t, st.Keys, maxHintStatsKeys)
+	assert.Equal(t, int64(11), st.OtherKeys)
+	assert.Contains(t, st.Keys, HintUseCase)
+	assert.Equal(t, int64(2), st.Keys[frequent].Count, "frequent keys are kept")
+
+	vs = newTestVariantServer()
+	vs.hintStats.record(vs, VariantHints{Hints: map[string]any{HintUseCase: values}}, NormalizedHints{})
+	last := values[len(values)-1].(string) // tracked, as it was added last
+	vs.hintStats.record(vs, VariantHints{Hints: map[string]any{HintUseCase: []any{last, "new"}}}, NormalizedHints{})
+	ks := vs.HintStats().Keys[HintUseCase]
+	assert.Len(t, ks.Values, maxHintStatsValues)
+	assert.Equal(t, int64(6), ks.OtherValues)
+	assert.Equal(t, int64(1), ks.Values["new"])
+	assert.Equal(t, int64(2), ks.Values[last])
+}
+
+func TestHintStats_LogValue(t *testing.T) {
+	vs := newTestVariantServer()
+	vs.hintStats.record(vs, VariantHints{}, NormalizedHints{})
+	vs.hintStats.record(vs, VariantHints{Hints: map[string]any{HintContextSize: "compact"}}, NormalizedHints{})
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("variant hints", "stats", vs.HintStats())
+	var entry struct {
+		Stats map[string]any `json:"stats"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, map[string]any{
+		"received":        float64(2),
+		"empty":           float64(1),
+		"withDescription": float64(0),
+		HintContextSize: map[string]any{
+			"count":  float64(1),
+			"known":  true,
+			"values": map[string]any{"compact": float64(1)},
+		},
+	}, entry.Stats)
+}
@@ -0,0 +1,171 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"slices"
+	"strings"
+)
+
+// wellKnownHintKeys is the Common Hint Vocabulary defined by the SEP.
+var wellKnownHintKeys = map[string]bool{
+	HintModelFamily:           true,
+	HintUseCase:               true,
+	HintContextSize:           true,
+	HintRenderingCapabilities: true,
+	HintLanguageOptimization:  true,
+}
+
+// Reasons reported in NormalizedHints.Ignored.
+const (
+	ignoredUnknownKey   = "unknown key"
+	ignoredInvalidValue = "value must be a string or an array of strings"
+	ignoredEmptyValue   = "empty value"
+)
+
+// NormalizedHints describes the hints the server actually used for ranking,
+// echoed back to the client in the initialize response (see
+// AvailableVariantsPayload) so client developers can verify that their
+// hints were understood.
+type NormalizedHints struct {
+	// Description is the client's description, with surrounding whitespace
+	// removed.
+	Description string `json:"description,omitempty"`
+
+	// Hints are the accepted hints after normalization. Values are either a
+	// string or a []string in order of preference.
+	Hints map[string]any `json:"hints,omitempty"`
+
+	// Ignored maps each dropped hint key to the reason it was dropped.
+	Ignored map[string]string `json:"ignored,omitempty"`
+}
+
+// empty reports whether the client sent nothing worth echoing.
+func (r NormalizedHints) empty() bool {
+	return r.Description == "" && len(r.Hints) == 0 && len(r.Ignored) == 0
+}
+
+// isKnownHintKey reports whether key is one the server understands: a
+// well-known key, a key used by any registered variant's Hints, or a
+// namespaced custom key (containing "/", e.g. "com.example/tier").
+func (s *Server) isKnownHintKey(key string) bool {
+	if wellKnownHintKeys[key] || strings.Contains(key, "/") {
+		return true
+	}
+	for _, e := range s.variants {
+		if _, ok := e.variant.Hints[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeHints normalizes client hints as a Server with the given
+// variants does before passing them to its RankingFunc, and reports what
+// was accepted and what was ignored. Use it to test ranking functions with
+// the hints they receive when serving (see CheckRanking).
+func NormalizeHints(raw VariantHints, variants []ServerVariant) (VariantHints, NormalizedHints) {
+	return normalizeHints(raw, func(key string) bool {
+		if wellKnownHintKeys[key] || strings.Contains(key, "/") {
+			return true
+		}
+		return slices.ContainsFunc(variants, func(v ServerVariant) bool {
+			_, ok := v.Hints[key]
+			return ok
+		})
+	})
+}
+
+// normalizeHints validates and normalizes client hints before ranking:
+//
+//   - unknown keys (see isKnownHintKey) are dropped, as the SEP requires
+//     them to be ignored;
+//   - string values are trimmed; empty strings are dropped;
+//   - array values are flattened into a []string, dropping empty entries and
+//     non-string elements; a single-element array collapses to its string;
+//   - any other value type is dropped.
+//
+// It returns the normalized hints, which are passed to the RankingFunc, and a
+// report of what was accepted and what was ignored.
+func (s *Server) normalizeHints(raw VariantHints) (VariantHints, NormalizedHints) {
+	return normalizeHints(raw, s.isKnownHintKey)
+}
+
+// normalizeHints implements Server.normalizeHints, with known reporting
+// whether a hint key is known.
+func normalizeHints(raw VariantHints, known func(key string) bool) (VariantHints, NormalizedHints) {
+	out := VariantHints{Description: strings.TrimSpace(raw.Description)}
+	report := NormalizedHints{Description: out.Description}
+
+	ignore := func(key, reason string) {
+		if report.Ignored == nil {
+			report.Ignored = make(map[string]string)
+		}
+		report.Ignored[key] = reason
+	}
+
+	for key, value := range raw.Hints {
+		if !known(key) {
+			ignore(key, ignoredUnknownKey)
+			continue
+		}
+		var normalized any
+		switch v := value.(type) {
+		case string:
+			if v = strings.TrimSpace(v); v != "" {
+				normalized = v
+			}
+		case []string, []any:
+			normalized = collapseHintValues(flattenHintValues(nil, v))
+		default:
+			ignore(key, ignoredInvalidValue)
+			continue
+		}
+		if normalized == nil {
+			ignore(key, ignoredEmptyValue)
+			continue
+		}
+		if out.Hints == nil {
+			out.Hints = make(map[string]any)
+		}
+		out.Hints[key] = normalized
+	}
+
+	report.Hints = out.Hints
+	return out, report
+}
+
+// flattenHintValues appends the non-empty strings found in v (recursing into
+// nested arrays) to dst, preserving order.
+func flattenHintValues(dst []string, v any) []string {
+	switch v := v.(type) {
+	case string:
+		if v = strings.TrimSpace(v); v != "" {
+			dst = append(dst, v)
+		}
+	case []string:
+		for _, e := range v {
+			dst = flattenHintValues(dst, e)
+		}
+	case []any:
+		for _, e := range v {
+			dst = flattenHintValues(dst, e)
+		}
+	}
+	return dst
+}
+
+// collapseHintValues converts a flattened value list to its normalized form:
+// nil when empty, the sole string for single-element lists, or the list.
+func collapseHintValues(vs []string) any {
+	switch len(vs) {
+	case 0:
+		return nil
+	case 1:
+		return vs[0]
+	default:
+		return vs
+	}
+}
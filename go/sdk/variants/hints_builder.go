@@ -0,0 +1,127 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// closedHintValues lists the values of well-known hint keys whose
+// vocabulary the SEP defines exhaustively. The other well-known keys list
+// common values only, and accept any.
+var closedHintValues = map[string][]string{
+	HintContextSize:           {"compact", "standard", "verbose"},
+	HintRenderingCapabilities: {"rich", "markdown", "text-only"},
+}
+
+// HintsBuilder builds the VariantHints a client sends to rank a server's
+// variants, validating them as it goes:
+//
+//	hints, err := variants.NewHintsBuilder().
+//		WithModelFamily("anthropic").
+//		WithContextSize("compact").
+//		WithCustom("com.example/tier", "pro").
+//		Build()
+//
+// Methods taking several values record them in order of preference. Later
+// calls for the same key replace earlier ones.
+type HintsBuilder struct {
+	hints VariantHints
+	errs  []error
+}
+
+// NewHintsBuilder returns an empty HintsBuilder.
+func NewHintsBuilder() *HintsBuilder {
+	return &HintsBuilder{}
+}
+
+// WithDescription sets the human-readable description of the client's
+// context and requirements.
+func (b *HintsBuilder) WithDescription(description string) *HintsBuilder {
+	b.hints.Description = strings.TrimSpace(description)
+	return b
+}
+
+// WithModelFamily sets the model families the client serves, e.g.
+// "anthropic" or "openai" (see HintModelFamily).
+func (b *HintsBuilder) WithModelFamily(families ...string) *HintsBuilder {
+	return b.set(HintModelFamily, families)
+}
+
+// WithUseCase sets the client's usage scenarios, e.g. "ide" or
+// "autonomous-agent" (see HintUseCase).
+func (b *HintsBuilder) WithUseCase(useCases ...string) *HintsBuilder {
+	return b.set(HintUseCase, useCases)
+}
+
+// WithContextSize sets the desired verbosity: "compact", "standard" or
+// "verbose" (see HintContextSize).
+func (b *HintsBuilder) WithContextSize(sizes ...string) *HintsBuilder {
+	return b.set(HintContextSize, sizes)
+}
+
+// WithRenderingCapabilities sets the client's rendering support: "rich",
+// "markdown" or "text-only" (see HintRenderingCapabilities).
+func (b *HintsBuilder) WithRenderingCapabilities(capabilities ...string) *HintsBuilder {
+	return b.set(HintRenderingCapabilities, capabilities)
+}
+
+// WithLanguageOptimization sets the natural language optimization, e.g.
+// "en" or "multilingual" (see HintLanguageOptimization).
+func (b *HintsBuilder) WithLanguageOptimization(languages ...string) *HintsBuilder {
+	return b.set(HintLanguageOptimization, languages)
+}
+
+// WithCustom sets a hint outside the common vocabulary. The key must be
+// namespaced with a "/" (e.g. "com.example/tier"), so that it cannot
+// collide with keys defined by the extension.
+func (b *HintsBuilder) WithCustom(key string, values ...string) *HintsBuilder {
+	if !wellKnownHintKeys[key] && !strings.Contains(key, "/") {
+		b.errs = append(b.errs, fmt.Errorf("variants: custom hint key %q is not namespaced", key))
+		return b
+	}
+	return b.set(key, values)
+}
+
+// set validates and records the values of a hint.
+func (b *HintsBuilder) set(key string, values []string) *HintsBuilder {
+	var clean []string
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			b.errs = append(b.errs, fmt.Errorf("variants: empty value for hint %q", key))
+			return b
+		}
+		if closed, ok := closedHintValues[key]; ok && !slices.Contains(closed, v) {
+			b.errs = append(b.errs, fmt.Errorf("variants: invalid value %q for hint %q; want one of %q", v, key, closed))
+			return b
+		}
+		clean = append(clean, v)
+	}
+	if len(clean) == 0 {
+		b.errs = append(b.errs, fmt.Errorf("variants: no value for hint %q", key))
+		return b
+	}
+	if b.hints.Hints == nil {
+		b.hints.Hints = make(map[string]any)
+	}
+	b.hints.Hints[key] = collapseHintValues(clean)
+	return b
+}
+
+// Build returns the hints, or an error describing every invalid hint
+// recorded.
+func (b *HintsBuilder) Build() (VariantHints, error) {
+	if err := errors.Join(b.errs...); err != nil {
+		return VariantHints{}, err
+	}
+	hints := b.hints
+	hints.Hints = maps.Clone(b.hints.Hints)
+	return hints, nil
+}
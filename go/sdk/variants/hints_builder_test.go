@@ -0,0 +1,57 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHintsBuilder(t *testing.T) {
+	hints, err := NewHintsBuilder().
+		WithDescription("  IDE assistant ").
+		WithModelFamily("anthropic").
+		WithContextSize("compact", "standard").
+		WithCustom("com.example/tier", "pro").
+		Build()
+	require.NoError(t, err)
+
+	data, err := json.Marshal(hints)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"description": "IDE assistant",
+		"hints": {
+			"modelFamily": "anthropic",
+			"contextSize": ["compact", "standard"],
+			"com.example/tier": "pro"
+		}
+	}`, string(data))
+
+	// The built hints pass normalization unchanged.
+	normalized, report := newTestVariantServer().normalizeHints(hints)
+	assert.Equal(t, hints, normalized)
+	assert.Empty(t, report.Ignored)
+}
+
+func TestHintsBuilder_Invalid(t *testing.T) {
+	_, err := NewHintsBuilder().
+		WithContextSize("tiny").
+		WithRenderingCapabilities(" ").
+		WithUseCase().
+		WithCustom("tier", "pro").
+		Build()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `invalid value "tiny" for hint "contextSize"`)
+	assert.ErrorContains(t, err, `empty value for hint "renderingCapabilities"`)
+	assert.ErrorContains(t, err, `no value for hint "useCase"`)
+	assert.ErrorContains(t, err, `custom hint key "tier" is not namespaced`)
+
+	// Open vocabularies accept any value.
+	_, err = NewHintsBuilder().WithModelFamily("mistral").WithLanguageOptimization("de").Build()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeHints(t *testing.T) {
+	s := NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"}).
+		WithVariant(ServerVariant{ID: "v1", Hints: map[string]string{"domain": "ci"}}, mcp.NewServer(&mcp.Implementation{Name: "inner", Version: "v0.0.1"}, nil), 0)
+
+	hints, report := s.normalizeHints(VariantHints{
+		Description: "  an agent  ",
+		Hints: map[string]any{
+			HintModelFamily:           " anthropic ",
+			HintContextSize:           []any{"compact", []any{"standard", ""}, 3},
+			HintUseCase:               []any{"ide"},
+			HintRenderingCapabilities: "",
+			HintLanguageOptimization:  42,
+			"domain":                  "ci",
+			"com.example/tier":        "pro",
+			"madeUp":                  "x",
+		},
+	})
+
+	assert.Equal(t, "an agent", hints.Description)
+	assert.Equal(t, map[string]any{
+		HintModelFamily:    "anthropic",
+		HintContextSize:    []string{"compact", "standard"},
+		HintUseCase:        "ide",
+		"domain":           "ci",
+		"com.example/tier": "pro",
+	}, hints.Hints)
+
+	assert.Equal(t, hints.Hints, report.Hints)
+	assert.Equal(t, map[string]string{
+		HintRenderingCapabilities: ignoredEmptyValue,
+		HintLanguageOptimization:  ignoredInvalidValue,
+		"madeUp":                  ignoredUnknownKey,
+	}, report.Ignored)
+}
+
+func TestNormalizeHints_Exported(t *testing.T) {
+	raw := VariantHints{Hints: map[string]any{HintContextSize: []any{" compact "}, "domain": "ci", "madeUp": "x"}}
+	variants := []ServerVariant{{ID: "v1", Hints: map[string]string{"domain": "ci"}}}
+	s := NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"}).
+		WithVariant(variants[0], mcp.NewServer(&mcp.Implementation{Name: "inner", Version: "v0.0.1"}, nil), 0)
+
+	wantHints, wantReport := s.normalizeHints(raw)
+	hints, report := NormalizeHints(raw, variants)
+	assert.Equal(t, wantHints, hints)
+	assert.Equal(t, wantReport, report)
+	assert.Equal(t, map[string]any{HintContextSize: "compact", "domain": "ci"}, hints.Hints)
+}
+
+func TestNormalizeHints_Empty(t *testing.T) {
+	s := NewServer(&mcp.Implementation{Name: "test", Version: "v0.0.1"})
+	hints, report := s.normalizeHints(VariantHints{})
+	assert.Nil(t, hints.Hints)
+	assert.True(t, report.empty())
+}
@@ -0,0 +1,204 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// payloadStep is one line of a payload transcript: a message the client
+// sends and, for requests, the subset of the response it relies on.
+type payloadStep struct {
+	Send   json.RawMessage `json:"send"`
+	Expect json.RawMessage `json:"expect,omitempty"`
+}
+
+// TestStreamableHTTPPayloads replays the transcripts in testdata/payloads
+// against a server over streamable HTTP, as raw JSON-RPC messages, checking
+// the wire format of the extension's payloads rather than their decoding by
+// the Go client. The transcripts are written by hand from the extension's
+// message shapes.
+func TestStreamableHTTPPayloads(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "payloads", "*.jsonl"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	replayTranscripts(t, files)
+}
+
+// replayTranscripts replays each transcript file against a new server over
+// streamable HTTP, in a subtest named after the file.
+func replayTranscripts(t *testing.T, files []string) {
+	for _, file := range files {
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".jsonl"), func(t *testing.T) {
+			srv := httptest.NewServer(NewStreamableHTTPHandler(newTestVariantServer(), nil))
+			t.Cleanup(srv.Close)
+
+			c := &rawHTTPClient{url: srv.URL}
+			for i, step := range readPayloadTranscript(t, file) {
+				got, err := c.send(step.Send)
+				require.NoError(t, err, "step %d", i)
+				if step.Expect == nil {
+					continue
+				}
+				var want any
+				require.NoError(t, json.Unmarshal(step.Expect, &want))
+				for _, diff := range jsonSubsetDiffs("", want, got) {
+					t.Errorf("step %d: %s", i, diff)
+				}
+			}
+		})
+	}
+}
+
+// readPayloadTranscript reads the steps of a JSONL transcript.
+func readPayloadTranscript(t *testing.T, file string) []payloadStep {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	var steps []payloadStep
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var step payloadStep
+		require.NoError(t, json.Unmarshal([]byte(line), &step))
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// rawHTTPClient posts JSON-RPC messages as a streamable HTTP client does,
+// tracking the session ID and negotiated protocol version.
+type rawHTTPClient struct {
+	url       string
+	sessionID string
+	protocol  string
+}
+
+// send posts msg and returns the decoded response with msg's ID, or nil
+// for a notification.
+func (c *rawHTTPClient) send(msg json.RawMessage) (any, error) {
+	var head struct {
+		ID     any    `json:"id"`
+		Method string `json:"method"`
+	}
+	if err := json.Unmarshal(msg, &head); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewReader(msg))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if c.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", c.sessionID)
+	}
+	if c.protocol != "" {
+		req.Header.Set("Mcp-Protocol-Version", c.protocol)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s: HTTP %s: %s", head.Method, resp.Status, body)
+	}
+	if id := resp.Header.Get("Mcp-Session-Id"); id != "" {
+		c.sessionID = id
+	}
+	if head.ID == nil {
+		return nil, nil
+	}
+
+	var messages [][]byte
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "text/event-stream" {
+		lines := bufio.NewScanner(resp.Body)
+		lines.Buffer(nil, 1<<20)
+		for lines.Scan() {
+			if data, ok := strings.CutPrefix(lines.Text(), "data:"); ok {
+				messages = append(messages, []byte(strings.TrimSpace(data)))
+			}
+		}
+		if err := lines.Err(); err != nil {
+			return nil, err
+		}
+	} else {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, body)
+	}
+	for _, data := range messages {
+		var m map[string]any
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		if fmt.Sprint(m["id"]) != fmt.Sprint(head.ID) {
+			continue // a notification or server request on the stream
+		}
+		if head.Method == "initialize" {
+			if result, ok := m["result"].(map[string]any); ok {
+				c.protocol, _ = result["protocolVersion"].(string)
+			}
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("%s: no response with id %v", head.Method, head.ID)
+}
+
+// jsonSubsetDiffs describes where got does not contain want: objects must
+// have want's keys, arrays want's length, and other values must be equal.
+func jsonSubsetDiffs(path string, want, got any) []string {
+	switch want := want.(type) {
+	case map[string]any:
+		obj, ok := got.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: got %v, want an object", path, got)}
+		}
+		var diffs []string
+		for k, w := range want {
+			g, ok := obj[k]
+			if !ok {
+				diffs = append(diffs, fmt.Sprintf("%s.%s: missing", path, k))
+				continue
+			}
+			diffs = append(diffs, jsonSubsetDiffs(path+"."+k, w, g)...)
+		}
+		return diffs
+	case []any:
+		arr, ok := got.([]any)
+		if !ok || len(arr) != len(want) {
+			return []string{fmt.Sprintf("%s: got %v, want %d elements", path, got, len(want))}
+		}
+		var diffs []string
+		for i := range want {
+			diffs = append(diffs, jsonSubsetDiffs(fmt.Sprintf("%s[%d]", path, i), want[i], arr[i])...)
+		}
+		return diffs
+	default:
+		if want != got {
+			return []string{fmt.Sprintf("%s: got %v, want %v", path, got, want)}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// InitializeExchange is the initialize request and response as seen by an
+// InitializeHook, after ranking and before the variants payload is added to
+// the result.
+type InitializeExchange struct {
+	// Params are the client's initialize parameters. Hooks should treat
+	// them as read-only.
+	Params *mcp.InitializeParams
+
+	// Result is the initialize result produced by the front server. Hooks
+	// may modify it, e.g. to add experimental capability keys; the variants
+	// payload is added under the extension ID afterwards and replaces any
+	// value a hook sets there.
+	Result *mcp.InitializeResult
+
+	// Hints are the client's normalized variant hints.
+	Hints VariantHints
+
+	// Variants are the variants ranked for this client. Hooks may reorder
+	// or remove entries, e.g. to strip variants based on the negotiated
+	// protocol version; the result becomes the session's ranking. Entries
+	// that do not name a registered variant are dropped, and removed
+	// variants cannot be selected by the session.
+	Variants []ServerVariant
+
+	// Rerank is set when the hooks run again because a stateful session is
+	// re-ranked after initialize, on a hint update or a catalog change
+	// (see Server.SetVariantAvailability). Result is then a copy of the
+	// session's initialize result, and changes to it are discarded.
+	Rerank bool
+}
+
+// InitializeHook inspects or modifies the initialize exchange. Returning an
+// error fails the initialize request.
+type InitializeHook func(ctx context.Context, ex *InitializeExchange) error
+
+// WithInitializeHook registers a hook run on every initialize exchange,
+// after ranking and before the variants payload is injected into the result.
+// Use it to add custom experimental keys, strip variants based on
+// negotiation, or log client information. Multiple hooks run in
+// registration order.
+//
+// Hooks run again whenever a stateful session is re-ranked, so that the
+// variants they strip or reorder stay that way for the whole session. A
+// hook error on re-ranking fails the request carrying the hint update, or
+// keeps the session's ranking on a catalog change.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithInitializeHook(h InitializeHook) *Server {
+	if h != nil {
+		s.initHooks = append(s.initHooks, h)
+	}
+	return s
+}
+
+// validHookVariants drops entries that do not name a registered variant, as
+// well as duplicates, from a ranking returned by initialize hooks.
+func (s *Server) validHookVariants(vs []ServerVariant) []ServerVariant {
+	out := make([]ServerVariant, 0, len(vs))
+	seen := make(map[string]bool, len(vs))
+	for _, v := range vs {
+		if seen[v.ID] || !s.hasVariant(v.ID) {
+			continue
+		}
+		seen[v.ID] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// applyInitHooks runs the initialize hooks on ex, returning the adjusted
+// ranking and the IDs of the variants the hooks removed from it.
+func (s *Server) applyInitHooks(ctx context.Context, ex *InitializeExchange) ([]ServerVariant, map[string]bool, error) {
+	original := slices.Clone(ex.Variants)
+	for _, h := range s.initHooks {
+		if err := h(ctx, ex); err != nil {
+			return nil, nil, err
+		}
+	}
+	ranked := s.validHookVariants(ex.Variants)
+	var stripped map[string]bool
+	for _, v := range original {
+		if !slices.ContainsFunc(ranked, func(r ServerVariant) bool { return r.ID == v.ID }) {
+			if stripped == nil {
+				stripped = make(map[string]bool)
+			}
+			stripped[v.ID] = true
+		}
+	}
+	return ranked, stripped, nil
+}
+
+// copyInitializeResult copies an initialize result deeply enough that a
+// hook's changes to it, including its experimental capabilities, do not
+// reach the original.
+func copyInitializeResult(res *mcp.InitializeResult) *mcp.InitializeResult {
+	c := *res
+	if res.Capabilities != nil {
+		caps := *res.Capabilities
+		caps.Experimental = maps.Clone(caps.Experimental)
+		c.Capabilities = &caps
+	}
+	return &c
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_InitializeHook(t *testing.T) {
+	var sawClient string
+	vs := newTestVariantServer().
+		WithInitializeHook(func(_ context.Context, ex *InitializeExchange) error {
+			sawClient = ex.Params.ClientInfo.Name
+			// Strip the first-ranked variant.
+			ex.Variants = ex.Variants[1:]
+			return nil
+		}).
+		WithInitializeHook(func(_ context.Context, ex *InitializeExchange) error {
+			if ex.Result.Capabilities.Experimental == nil {
+				ex.Result.Capabilities.Experimental = map[string]any{}
+			}
+			ex.Result.Capabilities.Experimental["example.com/custom"] = map[string]any{"enabled": true}
+			ex.Variants = append(ex.Variants, ServerVariant{ID: "bogus"})
+			return nil
+		})
+
+	session := connectTestClient(t, vs, nil)
+	assert.Equal(t, "test-client", sawClient)
+
+	var p struct {
+		AvailableVariants []struct {
+			ID string `json:"id"`
+		} `json:"availableVariants"`
+		DefaultVariant string `json:"defaultVariant"`
+	}
+	initExtension(t, session, &p)
+	require.Len(t, p.AvailableVariants, 1)
+	assert.Equal(t, "compact", p.AvailableVariants[0].ID)
+	assert.Equal(t, "compact", p.DefaultVariant)
+	assert.Contains(t, session.InitializeResult().Capabilities.Experimental, "example.com/custom")
+
+	// Requests without a variant go to the hook-adjusted default.
+	tools, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"summarize", "lookup"}, toolNames(tools.Tools))
+}
+
+func TestIntegration_InitializeHookError(t *testing.T) {
+	vs := newTestVariantServer().
+		WithInitializeHook(func(context.Context, *InitializeExchange) error {
+			return errors.New("rejected")
+		})
+
+	ctx := context.Background()
+	ct, st := mcp.NewInMemoryTransports()
+	srv, err := vs.mcpServer(TransportOther, false)
+	require.NoError(t, err)
+	ss, err := srv.Connect(ctx, st, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { ss.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	_, err = client.Connect(ctx, ct, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rejected")
+}
+
+// stripCompact is an initialize hook removing the compact variant.
+func stripCompact(_ context.Context, ex *InitializeExchange) error {
+	ex.Variants = slices.DeleteFunc(ex.Variants, func(v ServerVariant) bool { return v.ID == "compact" })
+	return nil
+}
+
+func TestIntegration_InitializeHookHintUpdate(t *testing.T) {
+	var reranks atomic.Int32
+	vs := newTestVariantServer().
+		WithRanking(contextSizeRanking).
+		WithInitializeHook(stripCompact).
+		WithInitializeHook(func(_ context.Context, ex *InitializeExchange) error {
+			if ex.Rerank {
+				reranks.Add(1)
+				ex.Result.Instructions = "discarded"
+			}
+			return nil
+		})
+	session := connectTestClient(t, vs, hintsClientOptions(map[string]any{HintContextSize: "coding"}))
+	ctx := context.Background()
+
+	// Hints ranking the stripped variant first leave it stripped.
+	tools, err := session.ListTools(ctx, &mcp.ListToolsParams{
+		Meta: mcp.Meta{metaKeyVariantHints: map[string]any{
+			"hints": map[string]any{HintContextSize: "compact"},
+		}},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, toolNames(tools.Tools), "analyze_code")
+	assert.Equal(t, int32(1), reranks.Load())
+
+	sessions := vs.Sessions()
+	require.Len(t, sessions, 1)
+	assert.Equal(t, []string{"coding"}, sessions[0].RankedVariants)
+	assert.Equal(t, "coding", sessions[0].DefaultVariant)
+	assert.NotEqual(t, "discarded", session.InitializeResult().Instructions)
+
+	// The stripped variant cannot be selected.
+	_, err = session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: "compact"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Invalid server variant")
+}
+
+func TestIntegration_InitializeHookCatalogChange(t *testing.T) {
+	vs := newTestVariantServer().WithInitializeHook(stripCompact)
+	session := connectTestClient(t, vs, nil)
+
+	require.NoError(t, vs.SetVariantAvailability("coding", false, "maintenance"))
+	require.NoError(t, vs.SetVariantAvailability("coding", true, ""))
+
+	sessions := vs.Sessions()
+	require.Len(t, sessions, 1)
+	assert.Equal(t, []string{"coding"}, sessions[0].RankedVariants)
+
+	tools, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Contains(t, toolNames(tools.Tools), "analyze_code")
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// InstructionsPolicy selects which variant servers' instructions the front
+// server returns at initialize.
+type InstructionsPolicy int
+
+const (
+	// InstructionsDefaultVariant returns the instructions of the session's
+	// default variant. This is the default policy.
+	InstructionsDefaultVariant InstructionsPolicy = iota
+
+	// InstructionsAllVariants returns the instructions of all variants
+	// ranked for the session, in ranked order, each labeled with its
+	// variant ID.
+	InstructionsAllVariants
+
+	// InstructionsNone returns no variant instructions.
+	InstructionsNone
+)
+
+// WithInstructionsPolicy sets which of the variant servers' instructions
+// (see [mcp.ServerOptions]) the front server returns at initialize. If the
+// front server has instructions of its own (see WithServerOptions), the
+// variant instructions follow them. Regardless of the policy, each
+// variant's instructions are listed with it in availableVariants.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithInstructionsPolicy(p InstructionsPolicy) *Server {
+	s.instructionsPolicy = p
+	return s
+}
+
+// sessionInstructions returns the instructions for an initialize result
+// whose front server instructions are base.
+func (s *Server) sessionInstructions(ctx context.Context, d *dispatcher, ranked []ServerVariant, base string) string {
+	var parts []string
+	if base != "" {
+		parts = append(parts, base)
+	}
+	switch s.instructionsPolicy {
+	case InstructionsDefaultVariant:
+		if id, err := d.defaultVariant(ctx, nil); err == nil && s.instructions[id] != "" {
+			parts = append(parts, s.instructions[id])
+		}
+	case InstructionsAllVariants:
+		for _, v := range ranked {
+			if text := s.instructions[v.ID]; text != "" {
+				parts = append(parts, fmt.Sprintf("Variant %q:\n%s", v.ID, text))
+			}
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
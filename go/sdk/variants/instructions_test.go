@@ -0,0 +1,66 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstructions(t *testing.T) {
+	newServer := func() *Server {
+		impl := &mcp.Implementation{Name: "inner", Version: "v1.0.0"}
+		return NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+			WithVariant(ServerVariant{ID: "coding"}, mcp.NewServer(impl, &mcp.ServerOptions{Instructions: "Analyze before refactoring."}), 0).
+			WithVariant(ServerVariant{ID: "compact"}, mcp.NewServer(impl, &mcp.ServerOptions{Instructions: "Keep answers short."}), 1).
+			WithVariant(ServerVariant{ID: "plain"}, mcp.NewServer(impl, nil), 2)
+	}
+
+	tests := []struct {
+		name  string
+		setup func(*Server)
+		want  string
+	}{
+		{"default variant", func(*Server) {}, "Analyze before refactoring."},
+		{"pinned default", func(vs *Server) { vs.WithDefaultVariant("compact") }, "Keep answers short."},
+		{
+			"all variants",
+			func(vs *Server) { vs.WithInstructionsPolicy(InstructionsAllVariants) },
+			"Variant \"coding\":\nAnalyze before refactoring.\n\nVariant \"compact\":\nKeep answers short.",
+		},
+		{"none", func(vs *Server) { vs.WithInstructionsPolicy(InstructionsNone) }, ""},
+		{
+			"front server instructions first",
+			func(vs *Server) { vs.WithServerOptions(&mcp.ServerOptions{Instructions: "Select a variant."}) },
+			"Select a variant.\n\nAnalyze before refactoring.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vs := newServer()
+			tt.setup(vs)
+			session := connectTestClient(t, vs, nil)
+			assert.Equal(t, tt.want, session.InitializeResult().Instructions)
+		})
+	}
+
+	t.Run("listed per variant", func(t *testing.T) {
+		session := connectTestClient(t, newServer().WithInstructionsPolicy(InstructionsNone), nil)
+		var p struct {
+			AvailableVariants []struct {
+				ID           string `json:"id"`
+				Instructions string `json:"instructions"`
+			} `json:"availableVariants"`
+		}
+		initExtension(t, session, &p)
+		require.Len(t, p.AvailableVariants, 3)
+		assert.Equal(t, "Analyze before refactoring.", p.AvailableVariants[0].Instructions)
+		assert.Equal(t, "Keep answers short.", p.AvailableVariants[1].Instructions)
+		assert.Empty(t, p.AvailableVariants[2].Instructions)
+	})
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DispatchInfo describes a request being dispatched to a variant.
+type DispatchInfo struct {
+	// SessionID is the front session's ID, if any.
+	SessionID string
+	// VariantID is the variant the request is routed to.
+	VariantID string
+	// Method is the MCP method, e.g. "tools/call".
+	Method string
+	// Correlation identifies the front request and this dispatch.
+	Correlation Correlation
+	// Params are the request's parameters, with the variant selection
+	// already set in _meta. Interceptors may modify them before calling
+	// next. Params may be nil.
+	Params mcp.Params
+}
+
+// DispatchHandler performs the dispatch an interceptor wraps.
+type DispatchHandler func(ctx context.Context) (mcp.Result, error)
+
+// DispatchInterceptor wraps the dispatch of a request to a variant. It may
+// inspect or modify the request before calling next, and the result or
+// error after; or answer without calling next at all.
+type DispatchInterceptor func(ctx context.Context, info DispatchInfo, next DispatchHandler) (mcp.Result, error)
+
+// WithDispatchInterceptor registers an interceptor wrapping every request
+// dispatched to a variant, including each call of a fan-out. Use it for
+// cross-cutting concerns such as masking PII in results or adding
+// correlation IDs. The first interceptor registered is outermost.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithDispatchInterceptor(i DispatchInterceptor) *Server {
+	if i != nil {
+		s.interceptors = append(s.interceptors, i)
+	}
+	return s
+}
+
+// intercept dispatches req to the inner connection through the registered
+// interceptors, under profiling labels if enabled.
+func (s *Server) intercept(ctx context.Context, conn *innerConnection, method string, req mcp.Request, sid string) (mcp.Result, error) {
+	h := func(ctx context.Context) (mcp.Result, error) {
+		return conn.backendSession.handleReceive(ctx, method, req)
+	}
+	if len(s.interceptors) == 0 {
+		return s.profile(ctx, conn.backendSession.variantID, method, h)
+	}
+	info := DispatchInfo{
+		SessionID: sid,
+		VariantID: conn.backendSession.variantID,
+		Method:    method,
+	}
+	info.Correlation, _ = CorrelationFromContext(ctx)
+	if params := req.GetParams(); !isNilInterface(params) {
+		info.Params = params
+	}
+	for i := len(s.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := s.interceptors[i], h
+		h = func(ctx context.Context) (mcp.Result, error) {
+			return interceptor(ctx, info, next)
+		}
+	}
+	return s.profile(ctx, info.VariantID, method, h)
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatchInterceptor(t *testing.T) {
+	inner := mcp.NewServer(&mcp.Implementation{Name: "inner", Version: "v1.0.0"}, nil)
+	mcp.AddTool(inner, &mcp.Tool{Name: "whoami"}, func(_ context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+		text := fmt.Sprintf("alice@example.com (correlation %v)", req.Params.Meta["example.com/correlation-id"])
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil, nil
+	})
+
+	var order []string
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "v1"}, inner, 0).
+		WithDispatchInterceptor(func(ctx context.Context, info DispatchInfo, next DispatchHandler) (mcp.Result, error) {
+			order = append(order, "outer:"+info.Method)
+			res, err := next(ctx)
+			if r, ok := res.(*mcp.CallToolResult); ok {
+				for _, c := range r.Content {
+					if tc, ok := c.(*mcp.TextContent); ok {
+						tc.Text = strings.ReplaceAll(tc.Text, "alice@example.com", "[email]")
+					}
+				}
+			}
+			return res, err
+		}).
+		WithDispatchInterceptor(func(ctx context.Context, info DispatchInfo, next DispatchHandler) (mcp.Result, error) {
+			order = append(order, "inner:"+info.VariantID)
+			if info.Params != nil {
+				info.Params.GetMeta()["example.com/correlation-id"] = "c-1"
+			}
+			return next(ctx)
+		})
+	session := connectTestClient(t, vs, nil)
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "whoami"})
+	require.NoError(t, err)
+	require.Len(t, res.Content, 1)
+	assert.Equal(t, "[email] (correlation c-1)", res.Content[0].(*mcp.TextContent).Text)
+	assert.Equal(t, []string{"outer:tools/call", "inner:v1"}, order)
+}
@@ -0,0 +1,58 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+//go:build interop
+
+// Interop tests check compatibility with the TypeScript SDK's variants
+// client over streamable HTTP. They replay the transcripts recorded from
+// that client in testdata/interop against the Go server, and, if
+// VARIANTS_INTEROP_TS_CLIENT names a command, also run that command with
+// MCP_SERVER_URL set to the server's URL, expecting it to exit successfully.
+//
+// Unlike the hand-written transcripts of TestStreamableHTTPPayloads, the
+// transcripts here must be recorded from the TypeScript client, so that
+// they detect drift between the SDKs. The client is not implemented yet, so
+// none are recorded and both tests skip.
+//
+// Run with:
+//
+//	go test -tags interop ./variants -run Interop
+
+package variants
+
+import (
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterop_RecordedTranscripts(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "interop", "*.jsonl"))
+	require.NoError(t, err)
+	if len(files) == 0 {
+		t.Skip("no transcripts recorded from the TypeScript client in testdata/interop")
+	}
+	replayTranscripts(t, files)
+}
+
+func TestInterop_TypeScriptClient(t *testing.T) {
+	command := os.Getenv("VARIANTS_INTEROP_TS_CLIENT")
+	if command == "" {
+		t.Skip("VARIANTS_INTEROP_TS_CLIENT not set")
+	}
+	srv := httptest.NewServer(NewStreamableHTTPHandler(newTestVariantServer(), nil))
+	t.Cleanup(srv.Close)
+
+	fields := strings.Fields(command)
+	cmd := exec.Command(fields[0], fields[1:]...)
+	cmd.Env = append(os.Environ(), "MCP_SERVER_URL="+srv.URL)
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, "%s", out)
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"iter"
+)
+
+// AllVariants returns an iterator over the registered variants, in
+// registration order, as Variants does without copying the catalog into a
+// slice.
+func (s *Server) AllVariants() iter.Seq[ServerVariant] {
+	return func(yield func(ServerVariant) bool) {
+		for _, e := range s.variants {
+			if !yield(s.withStatus(e.variant)) {
+				return
+			}
+		}
+	}
+}
+
+// AllRankedVariants returns an iterator over the variants ranked for the
+// given hints, best first, as RankedVariants does. The variants are ranked
+// each time iteration starts; with WithRankingCache, cached rankings are
+// iterated in place instead of being copied.
+func (s *Server) AllRankedVariants(ctx context.Context, hints VariantHints) iter.Seq[ServerVariant] {
+	return func(yield func(ServerVariant) bool) {
+		ranked, _ := s.rankedVariants(ctx, hints)
+		for _, v := range ranked {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// AllVariants returns an iterator over the variants the server advertises,
+// best-ranked first, as Variants does without copying them.
+func (s *ClientSession) AllVariants() iter.Seq[ServerVariant] {
+	return func(yield func(ServerVariant) bool) {
+		s.mu.Lock()
+		variants := s.payload.AvailableVariants // replaced, never modified
+		s.mu.Unlock()
+		for _, v := range variants {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllVariants(t *testing.T) {
+	vs := newTestVariantServer()
+	require.NoError(t, vs.SetVariantStatus("compact", Deprecated))
+	assert.Equal(t, vs.Variants(), slices.Collect(vs.AllVariants()))
+
+	for v := range vs.AllVariants() {
+		assert.Equal(t, "coding", v.ID, "iteration stops when the loop breaks")
+		break
+	}
+}
+
+func TestAllRankedVariants(t *testing.T) {
+	for _, cacheSize := range []int{0, 4} {
+		vs := newTestVariantServer().WithRankingCache(cacheSize)
+		require.NoError(t, vs.SetVariantAvailability("coding", false, "maintenance"))
+		ctx := context.Background()
+		hints := VariantHints{Hints: map[string]any{HintContextSize: "compact"}}
+
+		want := vs.RankedVariants(ctx, hints)
+		for range 2 { // the second pass is served from the cache, if enabled
+			assert.Equal(t, want, slices.Collect(vs.AllRankedVariants(ctx, hints)), "cache size %d", cacheSize)
+		}
+		assert.Equal(t, []string{"compact"}, variantIDs(want))
+	}
+}
+
+func TestClientSession_AllVariants(t *testing.T) {
+	session := connectVariantsClient(t, newTestVariantServer(), nil)
+	assert.Equal(t, session.Variants(), slices.Collect(session.AllVariants()))
+}
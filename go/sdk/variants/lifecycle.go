@@ -0,0 +1,135 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"errors"
+	"slices"
+)
+
+// ErrServerClosed is returned when serving a Server that has been closed,
+// and for requests received after Close.
+var ErrServerClosed = errors.New("variants: server closed")
+
+// errServerRunning is returned when running a Server with Run or ServeUnix
+// while another Run or ServeUnix serves it: they close the server when they
+// return, so they serve it exclusively.
+var errServerRunning = errors.New("variants: server is already serving")
+
+// Server lifecycle states. A Server is configured while new, starts running
+// when its first router is created (by Run, ServeUnix, the HTTP handlers
+// or NewRouter), and is closed for good by Close. A running Server may back
+// several routers, e.g. to serve both streamable HTTP and SSE clients.
+const (
+	stateNew int32 = iota
+	stateRunning
+	stateClosed
+)
+
+// start moves the server from new to running, recording r as one of its
+// routers.
+func (s *Server) start(r *VariantRouter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed() {
+		return ErrServerClosed
+	}
+	s.state.Store(stateRunning)
+	s.routers = append(s.routers, r)
+	return nil
+}
+
+// abortStart forgets a router that could not be set up, returning the
+// server to new if it has no other router.
+func (s *Server) abortStart(r *VariantRouter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routers = slices.DeleteFunc(s.routers, func(x *VariantRouter) bool { return x == r })
+	if len(s.routers) == 0 && s.state.Load() == stateRunning {
+		s.state.Store(stateNew)
+	}
+}
+
+// claimRun reserves the server for Run or ServeUnix. release must be called
+// if serving could not start.
+func (s *Server) claimRun() (release func(), err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed() {
+		return nil, ErrServerClosed
+	}
+	if s.runClaimed {
+		return nil, errServerRunning
+	}
+	s.runClaimed = true
+	return func() {
+		s.mu.Lock()
+		s.runClaimed = false
+		s.mu.Unlock()
+	}, nil
+}
+
+// routerList returns the server's routers.
+func (s *Server) routerList() []*VariantRouter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.routers)
+}
+
+// closed reports whether Close has been called.
+func (s *Server) closed() bool {
+	return s.state.Load() == stateClosed
+}
+
+// Close shuts the server down: it tears down the inner connections of all
+// sessions of all its routers (and, in stateless mode, the shared
+// connections) and releases resources held by all registered backends.
+// Requests received afterwards fail with [ErrServerClosed], and the server
+// cannot be served again.
+//
+// Close is safe to call concurrently with request handling and more than
+// once; calls after the first return nil.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed() {
+		s.mu.Unlock()
+		return nil
+	}
+	s.state.Store(stateClosed)
+	routers := s.routers
+	s.routers = nil
+	stopStartup := s.stopStartup
+	s.mu.Unlock()
+
+	if stopStartup != nil {
+		stopStartup()
+	}
+	for _, r := range routers {
+		r.close()
+	}
+	if s.ownership != nil {
+		s.ownership.stop()
+	}
+	var firstErr error
+	for _, entry := range s.variants {
+		if err := entry.backend.close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// close tears down the inner connections of all sessions and the shared
+// connections.
+func (r *VariantRouter) close() {
+	r.sessions.Range(func(k, v any) bool {
+		r.sessions.Delete(k)
+		v.(*sessionState).close()
+		return true
+	})
+	if r.shared != nil {
+		r.shared.close()
+	}
+}
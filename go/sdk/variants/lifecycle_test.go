@@ -0,0 +1,97 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClose(t *testing.T) {
+	t.Run("idempotent and concurrent", func(t *testing.T) {
+		vs := newTestVariantServer()
+		_, err := vs.NewRouter(&RouterOptions{Stateless: true})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, vs.Close())
+			}()
+		}
+		wg.Wait()
+		assert.NoError(t, vs.Close())
+	})
+
+	t.Run("run after close", func(t *testing.T) {
+		vs := newTestVariantServer()
+		require.NoError(t, vs.Close())
+		_, st := mcp.NewInMemoryTransports()
+		assert.ErrorIs(t, vs.Run(context.Background(), st), ErrServerClosed)
+		_, err := vs.NewRouter(nil)
+		assert.ErrorIs(t, err, ErrServerClosed)
+	})
+
+	t.Run("double run", func(t *testing.T) {
+		vs := newTestVariantServer()
+		session := connectTestClient(t, vs, nil)
+
+		_, st := mcp.NewInMemoryTransports()
+		err := vs.Run(context.Background(), st)
+		assert.ErrorIs(t, err, errServerRunning)
+
+		// The failed Run must not have closed the running server.
+		_, err = session.ListTools(context.Background(), nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("several transports", func(t *testing.T) {
+		vs := newTestVariantServer()
+		mux := http.NewServeMux()
+		mux.Handle("/mcp", NewStreamableHTTPHandler(vs, nil))
+		mux.Handle("/sse", NewSSEHandler(vs, nil))
+		httpSrv := httptest.NewServer(mux)
+		t.Cleanup(httpSrv.Close)
+
+		ctx := context.Background()
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+		streamable, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: httpSrv.URL + "/mcp"}, nil)
+		require.NoError(t, err)
+		sse, err := client.Connect(ctx, &mcp.SSEClientTransport{Endpoint: httpSrv.URL + "/sse"}, nil)
+		require.NoError(t, err)
+		for _, session := range []*mcp.ClientSession{streamable, sse} {
+			_, err := session.ListTools(ctx, nil)
+			assert.NoError(t, err)
+		}
+		assert.Len(t, vs.Sessions(), 2)
+
+		// Close shuts down the sessions of both front servers.
+		require.NoError(t, vs.Close())
+		for _, session := range []*mcp.ClientSession{streamable, sse} {
+			_, err := session.ListTools(ctx, nil)
+			assert.ErrorContains(t, err, ErrServerClosed.Error())
+			session.Close()
+		}
+	})
+
+	t.Run("requests after close", func(t *testing.T) {
+		vs := newTestVariantServer()
+		session := connectTestClient(t, vs, nil)
+		require.NoError(t, vs.Close())
+
+		_, err := session.ListTools(context.Background(), nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), ErrServerClosed.Error())
+	})
+}
@@ -0,0 +1,233 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Rules reported in LintIssue.Rule.
+const (
+	// LintDescriptionTooShort: a variant's description is shorter than
+	// LintOptions.MinDescriptionLength.
+	LintDescriptionTooShort = "descriptionTooShort"
+
+	// LintDescriptionTooLong: a variant's description is longer than
+	// LintOptions.MaxDescriptionLength.
+	LintDescriptionTooLong = "descriptionTooLong"
+
+	// LintNoUseCase: a variant's description does not say whom or what the
+	// variant is for, e.g. "Best for agents with limited context budgets".
+	LintNoUseCase = "noUseCase"
+
+	// LintNoCharacteristics: a variant's description does not say what
+	// distinguishes the variant, e.g. "Condensed tool descriptions".
+	LintNoCharacteristics = "noCharacteristics"
+
+	// LintNoTradeoffs: a variant's description does not say what the
+	// variant gives up, e.g. "rather than exact details".
+	LintNoTradeoffs = "noTradeoffs"
+
+	// LintHintMismatch: a variant's description contradicts its hints,
+	// e.g. a description calling a variant with contextSize "compact"
+	// verbose.
+	LintHintMismatch = "hintMismatch"
+
+	// LintDuplicateDescription: several variants have the same
+	// description, so a client cannot choose between them.
+	LintDuplicateDescription = "duplicateDescription"
+)
+
+// Default description length bounds of LintOptions, in characters.
+const (
+	defaultMinDescriptionLength = 40
+	defaultMaxDescriptionLength = 400
+)
+
+// LintOptions configures LintVariants. The zero value uses the defaults.
+type LintOptions struct {
+	// MinDescriptionLength and MaxDescriptionLength bound the length of
+	// descriptions, in characters. Zero means 40 and 400.
+	MinDescriptionLength int
+	MaxDescriptionLength int
+
+	// Disable lists rules not to check, e.g. LintNoTradeoffs.
+	Disable []string
+}
+
+// LintIssue is a departure of a variant's description from the guidance
+// of the variants extension (SEP-2053) for writing descriptions that LLMs
+// and users can select variants by.
+type LintIssue struct {
+	// Rule identifies the guidance, e.g. LintNoUseCase.
+	Rule string `json:"rule"`
+
+	// VariantID is the variant concerned.
+	VariantID string `json:"variantId"`
+
+	// Detail describes the issue.
+	Detail string `json:"detail"`
+}
+
+// LintVariants checks the descriptions of variants against the guidance
+// of the variants extension: a description should state the variant's
+// target use case, its characteristics and its trade-offs, be neither too
+// short nor too long, agree with the variant's hints, and differ from the
+// other variants' descriptions. opts may be nil.
+//
+// Descriptions are written in natural language, so the checks are
+// heuristics, based on English wording: they may miss issues, and
+// reported issues are suggestions for review rather than errors. Variants
+// without a description are reported by Server.Validate, not here. Issues
+// are sorted by variant, in the order given, then by rule.
+func LintVariants(variants []ServerVariant, opts *LintOptions) []LintIssue {
+	var o LintOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.MinDescriptionLength == 0 {
+		o.MinDescriptionLength = defaultMinDescriptionLength
+	}
+	if o.MaxDescriptionLength == 0 {
+		o.MaxDescriptionLength = defaultMaxDescriptionLength
+	}
+
+	var out []LintIssue
+	firstWith := make(map[string]string) // description -> first variant ID
+	for _, v := range variants {
+		desc := strings.TrimSpace(v.Description)
+		if desc == "" {
+			continue
+		}
+		issue := func(rule, format string, args ...any) {
+			if !slices.Contains(o.Disable, rule) {
+				out = append(out, LintIssue{Rule: rule, VariantID: v.ID, Detail: fmt.Sprintf(format, args...)})
+			}
+		}
+		switch n := len([]rune(desc)); {
+		case n < o.MinDescriptionLength:
+			issue(LintDescriptionTooShort, "description has %d characters, fewer than %d", n, o.MinDescriptionLength)
+		case n > o.MaxDescriptionLength:
+			issue(LintDescriptionTooLong, "description has %d characters, more than %d", n, o.MaxDescriptionLength)
+		}
+		words := lintWords(desc)
+		if !mentionsAny(words, useCaseTerms) {
+			issue(LintNoUseCase, "description does not state the variant's target use case (e.g. \"best for ...\")")
+		}
+		if !mentionsAny(words, characteristicTerms) {
+			issue(LintNoCharacteristics, "description does not state the variant's characteristics (e.g. \"concise tool descriptions\")")
+		}
+		if !mentionsAny(words, tradeoffTerms) {
+			issue(LintNoTradeoffs, "description does not state the variant's trade-offs (e.g. \"rather than ...\")")
+		}
+		for _, key := range sortedKeys(hintVocabularies) {
+			value, ok := v.Hints[key]
+			if !ok {
+				continue
+			}
+			if other, ok := hintMismatch(words, hintVocabularies[key], value); ok {
+				issue(LintHintMismatch, "hint %s=%q, but description suggests %q", key, value, other)
+			}
+		}
+		key := strings.ToLower(desc)
+		if first, ok := firstWith[key]; ok {
+			issue(LintDuplicateDescription, "description is the same as variant %q's", first)
+		} else {
+			firstWith[key] = v.ID
+		}
+	}
+	order := make(map[string]int, len(variants))
+	for i, v := range variants {
+		if _, ok := order[v.ID]; !ok {
+			order[v.ID] = i
+		}
+	}
+	slices.SortStableFunc(out, func(a, b LintIssue) int {
+		return cmp.Or(cmp.Compare(order[a.VariantID], order[b.VariantID]), cmp.Compare(a.Rule, b.Rule))
+	})
+	return out
+}
+
+// Lint checks the descriptions of the registered variants with
+// LintVariants. opts may be nil.
+func (s *Server) Lint(opts *LintOptions) []LintIssue {
+	return LintVariants(s.Variants(), opts)
+}
+
+// Terms whose presence in a description suggests it covers a part of the
+// guidance. Multi-word terms match consecutive words.
+var (
+	useCaseTerms = []string{
+		"for", "when", "best", "ideal", "designed", "intended", "suited", "suitable",
+		"optimized", "tailored", "targets", "aimed", "recommended", "use",
+	}
+	characteristicTerms = []string{
+		"tool", "tools", "description", "descriptions", "response", "responses",
+		"result", "results", "output", "outputs", "schema", "schemas", "format",
+		"detail", "details", "detailed", "concise", "compact", "verbose", "minimal",
+		"condensed", "complete", "full", "terse", "balanced", "structured",
+		"includes", "including", "provides", "returns", "exposes", "offers",
+		"supports", "adds", "access", "operations", "read-only", "readonly",
+	}
+	tradeoffTerms = []string{
+		"but", "however", "although", "though", "while", "whereas", "instead",
+		"rather than", "at the cost", "at the expense", "trade-off", "tradeoff",
+		"fewer", "less", "limited", "lower", "slower", "smaller", "larger", "more tokens",
+		"without", "omits", "omitted", "only", "not", "no", "may", "requires",
+		"deprecated", "legacy", "preview", "experimental",
+	}
+)
+
+// hintVocabularies are the terms suggesting each common value of the
+// well-known hint keys that descriptions are checked against.
+var hintVocabularies = map[string]map[string][]string{
+	HintContextSize: {
+		"compact":  {"compact", "concise", "minimal", "condensed", "terse", "brief"},
+		"standard": {"standard", "balanced", "moderate"},
+		"verbose":  {"verbose", "detailed", "thorough", "exhaustive"},
+	},
+	HintModelFamily: {
+		"anthropic": {"anthropic", "claude"},
+		"openai":    {"openai", "gpt", "chatgpt"},
+		"google":    {"google", "gemini"},
+		"meta":      {"meta", "llama"},
+	},
+}
+
+// hintMismatch reports whether a description's words suggest another
+// value of a hint than value, and do not suggest value itself. Values
+// outside the vocabulary, such as "any", are not checked.
+func hintMismatch(words string, vocabulary map[string][]string, value string) (other string, ok bool) {
+	own, known := vocabulary[value]
+	if !known || mentionsAny(words, own) {
+		return "", false
+	}
+	for _, v := range sortedKeys(vocabulary) {
+		if v != value && mentionsAny(words, vocabulary[v]) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// lintWords returns the lowercase words of s separated and surrounded by
+// single spaces, for matching terms with mentionsAny. Hyphenated words
+// are kept whole.
+func lintWords(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+	})
+	return " " + strings.Join(fields, " ") + " "
+}
+
+// mentionsAny reports whether words, as returned by lintWords, contain any
+// of terms.
+func mentionsAny(words string, terms []string) bool {
+	return slices.ContainsFunc(terms, func(t string) bool { return strings.Contains(words, " "+t+" ") })
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintVariants(t *testing.T) {
+	tests := []struct {
+		name    string
+		variant ServerVariant
+		want    []string // rules
+	}{
+		{
+			name: "complete",
+			variant: ServerVariant{
+				Description: "Condensed manuals and key points. Best for agents with limited context budgets that need orientation rather than detail.",
+				Hints:       map[string]string{HintContextSize: "compact"},
+			},
+		},
+		{
+			name:    "too short",
+			variant: ServerVariant{Description: "Minimal token usage"},
+			want:    []string{LintDescriptionTooShort, LintNoTradeoffs, LintNoUseCase},
+		},
+		{
+			name: "no trade-offs",
+			variant: ServerVariant{
+				Description: "Complete product manuals with per-section access. Best for agents with large context windows that need exact details.",
+			},
+			want: []string{LintNoTradeoffs},
+		},
+		{
+			name: "context size mismatch",
+			variant: ServerVariant{
+				Description: "Verbose tool descriptions with examples, for agents with large context windows, at the cost of more tokens.",
+				Hints:       map[string]string{HintContextSize: "compact"},
+			},
+			want: []string{LintHintMismatch},
+		},
+		{
+			name: "own hint value mentioned",
+			variant: ServerVariant{
+				Description: "Compact tool descriptions, less verbose than the default, for agents with limited context budgets.",
+				Hints:       map[string]string{HintContextSize: "compact"},
+			},
+		},
+		{
+			name: "model family mismatch",
+			variant: ServerVariant{
+				Description: "Structured tool descriptions optimized for GPT models, but longer than the compact variant's.",
+				Hints:       map[string]string{HintModelFamily: "anthropic"},
+			},
+			want: []string{LintHintMismatch},
+		},
+		{
+			name: "values outside the vocabulary",
+			variant: ServerVariant{
+				Description: "Minimal tool descriptions for Claude and GPT models alike, without usage examples.",
+				Hints:       map[string]string{HintModelFamily: "any", "tier": "verbose"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.variant.ID = "v"
+			var rules []string
+			for _, issue := range LintVariants([]ServerVariant{tt.variant}, nil) {
+				assert.Equal(t, "v", issue.VariantID)
+				assert.NotEmpty(t, issue.Detail)
+				rules = append(rules, issue.Rule)
+			}
+			assert.Equal(t, tt.want, rules)
+		})
+	}
+}
+
+func TestLintVariants_Catalog(t *testing.T) {
+	desc := "Concise tool descriptions for agents with limited context budgets, without usage examples."
+	issues := LintVariants([]ServerVariant{
+		{ID: "b", Description: desc},
+		{ID: "a", Description: "Synthetic variant"},
+		{ID: "missing"},
+		{ID: "c", Description: "  " + desc},
+	}, &LintOptions{MinDescriptionLength: 10, Disable: []string{LintNoTradeoffs}})
+	assert.Equal(t, []LintIssue{
+		{Rule: LintNoCharacteristics, VariantID: "a", Detail: `description does not state the variant's characteristics (e.g. "concise tool descriptions")`},
+		{Rule: LintNoUseCase, VariantID: "a", Detail: `description does not state the variant's target use case (e.g. "best for ...")`},
+		{Rule: LintDuplicateDescription, VariantID: "c", Detail: `description is the same as variant "b"'s`},
+	}, issues)
+
+	issues = LintVariants([]ServerVariant{{ID: "b", Description: desc}}, &LintOptions{MaxDescriptionLength: 20})
+	assert.Equal(t, []LintIssue{{Rule: LintDescriptionTooLong, VariantID: "b", Detail: "description has 90 characters, more than 20"}}, issues)
+}
+
+func TestServer_Lint(t *testing.T) {
+	vs := newTestVariantServer()
+	issues := vs.Lint(nil)
+	assert.Contains(t, issues, LintIssue{Rule: LintDescriptionTooShort, VariantID: "compact", Detail: "description has 19 characters, fewer than 40"})
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// LogLevelOff, as the minimum log level of a variant, drops all of the
+// variant's log messages. See Server.WithVariantLogLevel.
+const LogLevelOff mcp.LoggingLevel = "off"
+
+// logSeverity orders the MCP logging levels, the syslog severities of
+// RFC 5424, from the least severe.
+var logSeverity = map[mcp.LoggingLevel]int{
+	"debug":     0,
+	"info":      1,
+	"notice":    2,
+	"warning":   3,
+	"error":     4,
+	"critical":  5,
+	"alert":     6,
+	"emergency": 7,
+	LogLevelOff: 8,
+}
+
+// WithVariantLogLevel sets the minimum level of the variant's log messages
+// (notifications/message) forwarded to clients. Messages below it are
+// dropped whatever level the client set with logging/setLevel. Use it to
+// mute noisy variants, such as experimental backends, while others stay
+// verbose. LogLevelOff drops all of the variant's messages. The variant's
+// backend sessions are set to the level too, so that backends do not send
+// messages only to have them dropped.
+//
+// Serving fails if the variant is not registered. Panics if level is not
+// an MCP logging level or LogLevelOff.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithVariantLogLevel(variantID string, level mcp.LoggingLevel) *Server {
+	if _, ok := logSeverity[level]; !ok {
+		panic(fmt.Sprintf("variants: unknown log level %q", level))
+	}
+	if s.logLevels == nil {
+		s.logLevels = make(map[string]mcp.LoggingLevel)
+	}
+	s.logLevels[variantID] = level
+	return s
+}
+
+// validateLogLevels checks that per-variant log levels name registered
+// variants.
+func (s *Server) validateLogLevels() error {
+	for id := range s.logLevels {
+		if !s.hasVariant(id) {
+			return fmt.Errorf("variants: log level for unregistered variant %q", id)
+		}
+	}
+	return nil
+}
+
+// forwardsLog reports whether a log message of the given level from a
+// variant is forwarded to clients. Messages of unknown levels are only
+// dropped by LogLevelOff.
+func (s *Server) forwardsLog(variantID string, level mcp.LoggingLevel) bool {
+	floor, ok := s.logLevels[variantID]
+	if !ok {
+		return true
+	}
+	if floor == LogLevelOff {
+		return false
+	}
+	severity, known := logSeverity[level]
+	return !known || severity >= logSeverity[floor]
+}
+
+// backendLogLevel returns the log level to set on the variant's backend
+// sessions. Without a minimum level it is "debug", since the front session
+// filters messages by the client's level.
+func (s *Server) backendLogLevel(variantID string) mcp.LoggingLevel {
+	switch floor := s.logLevels[variantID]; floor {
+	case "":
+		return "debug"
+	case LogLevelOff:
+		return "emergency"
+	default:
+		return floor
+	}
+}
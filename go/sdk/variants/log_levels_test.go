@@ -0,0 +1,91 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// allLogLevels are the MCP logging levels, from the least severe.
+var allLogLevels = []mcp.LoggingLevel{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
+// newLoggingServer returns a server whose log tool logs a message at each
+// level, with the level as data.
+func newLoggingServer(name string) *mcp.Server {
+	srv := mcp.NewServer(&mcp.Implementation{Name: name, Version: "v1.0.0"}, &mcp.ServerOptions{
+		Capabilities: &mcp.ServerCapabilities{Logging: &mcp.LoggingCapabilities{}},
+	})
+	srv.AddTool(&mcp.Tool{Name: "log", InputSchema: map[string]any{"type": "object"}}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		for _, level := range allLogLevels {
+			_ = req.Session.Log(ctx, &mcp.LoggingMessageParams{Level: level, Logger: name, Data: string(level)})
+		}
+		return &mcp.CallToolResult{}, nil
+	})
+	return srv
+}
+
+// loggedLevels calls the log tool of a variant, waits for want messages,
+// and returns the levels of the messages the client received.
+func loggedLevels(t *testing.T, session *mcp.ClientSession, collector *notificationCollector, variantID string, want int) []mcp.LoggingLevel {
+	t.Helper()
+	collector.mu.Lock()
+	collector.logs = nil
+	collector.mu.Unlock()
+	_, err := session.CallTool(context.Background(), &mcp.CallToolParams{Meta: mcp.Meta{metaKeyVariant: variantID}, Name: "log"})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return collector.logCount() >= want }, 2*time.Second, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond) // let messages beyond want arrive
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	var levels []mcp.LoggingLevel
+	for _, l := range collector.logs {
+		levels = append(levels, l.Level)
+	}
+	return levels
+}
+
+func TestWithVariantLogLevel(t *testing.T) {
+	endpoint, _ := serveRemote(t, newLoggingServer("remote"))
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "stable", Description: "Stable"}, newLoggingServer("stable"), 0).
+		WithVariant(ServerVariant{ID: "experimental", Description: "Experimental"}, newLoggingServer("experimental"), 1).
+		WithVariant(ServerVariant{ID: "muted", Description: "Muted"}, newLoggingServer("muted"), 2).
+		WithRemoteVariant(ServerVariant{ID: "remote", Description: "Remote"}, endpoint, 3).
+		WithVariantLogLevel("experimental", "error").
+		WithVariantLogLevel("muted", LogLevelOff).
+		WithVariantLogLevel("remote", "critical")
+	collector := &notificationCollector{}
+	session := connectTestClient(t, vs, collector.clientOptions())
+	require.NoError(t, session.SetLoggingLevel(context.Background(), &mcp.SetLoggingLevelParams{Level: "debug"}))
+
+	assert.Equal(t, allLogLevels, loggedLevels(t, session, collector, "stable", 8))
+	assert.Equal(t, allLogLevels[4:], loggedLevels(t, session, collector, "experimental", 4))
+	assert.Equal(t, allLogLevels[5:], loggedLevels(t, session, collector, "remote", 3))
+	assert.Empty(t, loggedLevels(t, session, collector, "muted", 0))
+}
+
+func TestForwardsLog(t *testing.T) {
+	vs := newTestVariantServer().WithVariantLogLevel("compact", "warning")
+	assert.True(t, vs.forwardsLog("coding", "debug"))
+	assert.False(t, vs.forwardsLog("compact", "info"))
+	assert.True(t, vs.forwardsLog("compact", "warning"))
+	assert.True(t, vs.forwardsLog("compact", "unknown"))
+	assert.Equal(t, mcp.LoggingLevel("debug"), vs.backendLogLevel("coding"))
+	assert.Equal(t, mcp.LoggingLevel("warning"), vs.backendLogLevel("compact"))
+	assert.Equal(t, mcp.LoggingLevel("emergency"), vs.WithVariantLogLevel("coding", LogLevelOff).backendLogLevel("coding"))
+}
+
+func TestWithVariantLogLevel_Invalid(t *testing.T) {
+	assert.Panics(t, func() { newTestVariantServer().WithVariantLogLevel("compact", "verbose") })
+
+	_, err := newTestVariantServer().WithVariantLogLevel("nope", "info").NewRouter(nil)
+	assert.ErrorContains(t, err, `log level for unregistered variant "nope"`)
+}
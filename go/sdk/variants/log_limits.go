@@ -0,0 +1,181 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxLogFingerprints bounds the recent messages remembered per session and
+// variant for deduplication.
+const maxLogFingerprints = 256
+
+// LogLimits limits the log messages (notifications/message) that each
+// variant forwards to each client session. See Server.WithLogLimits.
+type LogLimits struct {
+	// Rate is the number of messages per second a variant may forward to
+	// a session, on average. Zero means no limit.
+	Rate float64
+
+	// Burst is the number of messages a variant may forward at once,
+	// beyond Rate. Zero means Rate rounded up, and at least 1.
+	Burst int
+
+	// DedupWindow drops messages identical to one the variant forwarded to
+	// the session within the window: with the same level, logger and data.
+	// Zero disables deduplication.
+	DedupWindow time.Duration
+}
+
+// WithLogLimits limits the log messages each variant forwards to each
+// client session, so that a chatty backend cannot flood the client's log
+// channel, which all variants share: duplicate messages are dropped and
+// the remaining ones are rate limited. Limits apply after the variant's
+// minimum log level (see WithVariantLogLevel). The number of messages
+// dropped is reported with the variant's next forwarded message, by a
+// preceding "warning" message of the "variants" logger with data
+//
+//	{"message": "...", "variant": "compact", "suppressed": 42}
+//
+// Messages are not limited in stateless mode, where they are not
+// forwarded. Panics if a field is negative.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithLogLimits(l LogLimits) *Server {
+	if l.Rate < 0 || l.Burst < 0 || l.DedupWindow < 0 {
+		panic("variants: negative log limit")
+	}
+	if l.Burst == 0 {
+		l.Burst = max(1, int(math.Ceil(l.Rate)))
+	}
+	s.logLimiter = &logLimiter{limits: l, streams: make(map[logStreamKey]*logStream)}
+	return s
+}
+
+// logStreamKey identifies the messages of a variant to a session. Sessions
+// are keyed by pointer, since in-memory and stdio sessions have no ID.
+type logStreamKey struct {
+	session *mcp.ServerSession
+	variant string
+}
+
+// logStream is the limiting state of a logStreamKey.
+type logStream struct {
+	tokens     float64
+	refilled   time.Time
+	recent     map[string]time.Time // message fingerprint -> last forwarded
+	suppressed int                  // since the last forwarded message
+}
+
+// logLimiter applies LogLimits.
+type logLimiter struct {
+	limits LogLimits
+
+	mu      sync.Mutex
+	streams map[logStreamKey]*logStream
+}
+
+// admit reports whether a message of a variant is forwarded to a session,
+// and how many of the variant's messages to the session were dropped since
+// the last forwarded one.
+func (l *logLimiter) admit(session *mcp.ServerSession, variant string, params *mcp.LoggingMessageParams, now time.Time) (ok bool, suppressed int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := logStreamKey{session, variant}
+	st := l.streams[key]
+	if st == nil {
+		st = &logStream{tokens: float64(l.limits.Burst), refilled: now}
+		l.streams[key] = st
+	}
+
+	var fp string
+	if l.limits.DedupWindow > 0 {
+		fp = logFingerprint(params)
+		if last, ok := st.recent[fp]; ok && now.Sub(last) < l.limits.DedupWindow {
+			st.suppressed++
+			return false, 0
+		}
+	}
+	if l.limits.Rate > 0 {
+		st.tokens = min(float64(l.limits.Burst), st.tokens+now.Sub(st.refilled).Seconds()*l.limits.Rate)
+		st.refilled = now
+		if st.tokens < 1 {
+			st.suppressed++
+			return false, 0
+		}
+		st.tokens--
+	}
+	if l.limits.DedupWindow > 0 {
+		if st.recent == nil {
+			st.recent = make(map[string]time.Time)
+		}
+		if len(st.recent) >= maxLogFingerprints {
+			for k, t := range st.recent {
+				if now.Sub(t) >= l.limits.DedupWindow {
+					delete(st.recent, k)
+				}
+			}
+		}
+		if len(st.recent) < maxLogFingerprints {
+			st.recent[fp] = now
+		}
+	}
+	suppressed, st.suppressed = st.suppressed, 0
+	return true, suppressed
+}
+
+// dropSession drops the state of a session.
+func (l *logLimiter) dropSession(ss *mcp.ServerSession) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for k := range l.streams {
+		if k.session == ss {
+			delete(l.streams, k)
+		}
+	}
+}
+
+// logFingerprint returns a key identifying messages with the same level,
+// logger and data.
+func logFingerprint(params *mcp.LoggingMessageParams) string {
+	data, err := json.Marshal(params.Data)
+	if err != nil {
+		data = []byte(fmt.Sprint(params.Data))
+	}
+	return string(params.Level) + "\x00" + params.Logger + "\x00" + string(data)
+}
+
+// admitLog reports whether a log message of a variant is forwarded to
+// frontSession, which may be nil, applying the variant's minimum log level
+// and the log limits. If messages of the variant were dropped by the
+// limits since the last one forwarded, it also returns a message reporting
+// them, to forward first.
+func (s *Server) admitLog(frontSession *mcp.ServerSession, variantID string, params *mcp.LoggingMessageParams) (ok bool, report *mcp.LoggingMessageParams) {
+	if !s.forwardsLog(variantID, params.Level) {
+		return false, nil
+	}
+	if s.logLimiter == nil || frontSession == nil {
+		return true, nil
+	}
+	ok, suppressed := s.logLimiter.admit(frontSession, variantID, params, time.Now())
+	if ok && suppressed > 0 {
+		report = &mcp.LoggingMessageParams{
+			Level:  "warning",
+			Logger: "variants",
+			Data: map[string]any{
+				"message":    fmt.Sprintf("suppressed %d log messages from variant %q", suppressed, variantID),
+				"variant":    variantID,
+				"suppressed": suppressed,
+			},
+		}
+	}
+	return ok, report
+}
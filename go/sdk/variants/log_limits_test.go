@@ -0,0 +1,149 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sayInput struct {
+	Text  string `json:"text"`
+	Count int    `json:"count"`
+}
+
+// newChattyServer returns a server whose say tool logs text count times.
+func newChattyServer(name string) *mcp.Server {
+	srv := mcp.NewServer(&mcp.Implementation{Name: name, Version: "v1.0.0"}, &mcp.ServerOptions{
+		Capabilities: &mcp.ServerCapabilities{Logging: &mcp.LoggingCapabilities{}},
+	})
+	mcp.AddTool(srv, &mcp.Tool{Name: "say"}, func(ctx context.Context, req *mcp.CallToolRequest, in sayInput) (*mcp.CallToolResult, any, error) {
+		for range in.Count {
+			_ = req.Session.Log(ctx, &mcp.LoggingMessageParams{Level: "info", Logger: name, Data: in.Text})
+		}
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	return srv
+}
+
+func TestWithLogLimits(t *testing.T) {
+	endpoint, _ := serveRemote(t, newChattyServer("remote"))
+	for _, variantID := range []string{"local", "remote"} {
+		t.Run(variantID, func(t *testing.T) {
+			vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+				WithVariant(ServerVariant{ID: "local", Description: "Local"}, newChattyServer("local"), 0).
+				WithRemoteVariant(ServerVariant{ID: "remote", Description: "Remote"}, endpoint, 1).
+				WithLogLimits(LogLimits{DedupWindow: time.Minute})
+			collector := &notificationCollector{}
+			session := connectTestClient(t, vs, collector.clientOptions())
+			ctx := context.Background()
+			require.NoError(t, session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "debug"}))
+			say := func(text string, count int) {
+				t.Helper()
+				_, err := session.CallTool(ctx, &mcp.CallToolParams{
+					Meta:      mcp.Meta{metaKeyVariant: variantID},
+					Name:      "say",
+					Arguments: map[string]any{"text": text, "count": count},
+				})
+				require.NoError(t, err)
+			}
+
+			say("hello", 3)
+			say("bye", 1)
+			require.Eventually(t, func() bool { return collector.logCount() == 3 }, 2*time.Second, 10*time.Millisecond)
+			collector.mu.Lock()
+			defer collector.mu.Unlock()
+			assert.Equal(t, "hello", collector.logs[0].Data)
+			assert.Equal(t, "variants", collector.logs[1].Logger)
+			assert.Equal(t, mcp.LoggingLevel("warning"), collector.logs[1].Level)
+			assert.Equal(t, map[string]any{
+				"message":    `suppressed 2 log messages from variant "` + variantID + `"`,
+				"variant":    variantID,
+				"suppressed": float64(2),
+			}, collector.logs[1].Data)
+			assert.Equal(t, "bye", collector.logs[2].Data)
+		})
+	}
+}
+
+func TestWithLogLimits_SessionsWithoutID(t *testing.T) {
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "local", Description: "Local"}, newChattyServer("local"), 0).
+		WithLogLimits(LogLimits{DedupWindow: time.Minute})
+	front, err := vs.mcpServer(TransportOther, false)
+	require.NoError(t, err)
+	t.Cleanup(func() { vs.Close() })
+
+	// In-memory sessions have no ID. Their messages are deduplicated
+	// separately.
+	ctx := context.Background()
+	for range 2 {
+		collector := &notificationCollector{}
+		session := connectInMemoryClient(t, front, collector.clientOptions())
+		require.NoError(t, session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "debug"}))
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "say", Arguments: map[string]any{"text": "hello", "count": 1}})
+		require.NoError(t, err)
+		assert.Eventually(t, func() bool { return collector.logCount() == 1 }, 2*time.Second, 10*time.Millisecond)
+	}
+}
+
+func TestLogLimiter_Rate(t *testing.T) {
+	l := &logLimiter{limits: LogLimits{Rate: 1, Burst: 2}, streams: make(map[logStreamKey]*logStream)}
+	msg := &mcp.LoggingMessageParams{Level: "info", Data: "x"}
+	now := time.Now()
+	s1, s2 := &mcp.ServerSession{}, &mcp.ServerSession{}
+
+	admit := func(session *mcp.ServerSession, at time.Duration) (bool, int) {
+		return l.admit(session, "v", msg, now.Add(at))
+	}
+	ok, _ := admit(s1, 0)
+	assert.True(t, ok)
+	ok, _ = admit(s1, 0)
+	assert.True(t, ok)
+	ok, _ = admit(s1, 0)
+	assert.False(t, ok, "burst exhausted")
+	ok, _ = admit(s2, 0)
+	assert.True(t, ok, "sessions are limited separately")
+
+	ok, _ = admit(s1, 500*time.Millisecond)
+	assert.False(t, ok)
+	ok, suppressed := admit(s1, time.Second)
+	assert.True(t, ok, "refilled")
+	assert.Equal(t, 2, suppressed)
+
+	l.dropSession(s1)
+	// Contains would compare the session pointers deeply.
+	assert.Nil(t, l.streams[logStreamKey{s1, "v"}])
+	assert.NotNil(t, l.streams[logStreamKey{s2, "v"}])
+}
+
+func TestLogLimiter_Dedup(t *testing.T) {
+	l := &logLimiter{limits: LogLimits{DedupWindow: time.Second}, streams: make(map[logStreamKey]*logStream)}
+	s, now := &mcp.ServerSession{}, time.Now()
+	admit := func(level mcp.LoggingLevel, data any, at time.Duration) (bool, int) {
+		return l.admit(s, "v", &mcp.LoggingMessageParams{Level: level, Data: data}, now.Add(at))
+	}
+
+	ok, _ := admit("info", map[string]any{"n": 1}, 0)
+	assert.True(t, ok)
+	ok, _ = admit("info", map[string]any{"n": 1}, 100*time.Millisecond)
+	assert.False(t, ok, "duplicate")
+	ok, suppressed := admit("error", map[string]any{"n": 1}, 200*time.Millisecond)
+	assert.True(t, ok, "other level")
+	assert.Equal(t, 1, suppressed)
+	ok, suppressed = admit("info", map[string]any{"n": 1}, time.Second)
+	assert.True(t, ok, "window passed")
+	assert.Zero(t, suppressed)
+}
+
+func TestWithLogLimits_Invalid(t *testing.T) {
+	assert.Panics(t, func() { newTestVariantServer().WithLogLimits(LogLimits{Rate: -1}) })
+	assert.Equal(t, 3, newTestVariantServer().WithLogLimits(LogLimits{Rate: 2.5}).logLimiter.limits.Burst)
+}
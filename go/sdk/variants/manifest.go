@@ -0,0 +1,166 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Manifest is a serializable description of a variant server's whole
+// catalog, for registries and documentation generators to index. It is
+// produced by [Server.Manifest].
+type Manifest struct {
+	// Extension is the ID of the variants extension,
+	// "io.modelcontextprotocol/server-variants".
+	Extension string `json:"extension"`
+
+	// Server identifies the front server.
+	Server *mcp.Implementation `json:"server"`
+
+	// DefaultVariant is the variant served to clients that send no hints,
+	// or empty if no variant is available.
+	DefaultVariant string `json:"defaultVariant,omitempty"`
+
+	// Variants describes every registered variant, including those out of
+	// rotation, in registration order.
+	Variants []VariantManifest `json:"variants"`
+}
+
+// VariantManifest describes one variant in a [Manifest].
+type VariantManifest struct {
+	// ServerVariant is the variant as registered, with its description
+	// rendered if description templates are configured. Its priority is
+	// serialized as "priority", and its Extra entries as top-level fields.
+	ServerVariant
+
+	// Available reports whether the variant is in rotation (see
+	// SetVariantAvailability); UnavailableReason says why not.
+	Available         bool   `json:"available"`
+	UnavailableReason string `json:"unavailableReason,omitempty"`
+
+	// Instructions are the instructions of the variant's server.
+	Instructions string `json:"instructions,omitempty"`
+
+	// Tools are the variant's tools with their input and output schemas,
+	// as a client of the variant would list them.
+	Tools []*mcp.Tool `json:"tools"`
+
+	// CatalogCounts are the sizes of the variant's catalog, if catalog
+	// counts are enabled and known (see WithCatalogCounts).
+	*CatalogCounts
+}
+
+// MarshalJSON flattens the embedded variant's priority and Extra entries
+// into the variant's object.
+func (m VariantManifest) MarshalJSON() ([]byte, error) {
+	type plain VariantManifest
+	return marshalFlattened(plain(m), m.ServerVariant)
+}
+
+// marshalFlattened encodes v, an object embedding the variant sv, with sv's
+// priority as "priority" and sv's Extra entries as top-level fields, which
+// replace any others. v must not implement json.Marshaler itself.
+func marshalFlattened(v any, sv ServerVariant) ([]byte, error) {
+	extra := make(map[string]any, len(sv.Extra)+1)
+	extra["priority"] = sv.Priority()
+	maps.Copy(extra, sv.Extra)
+	return marshalWithExtra(v, extra, true)
+}
+
+// Manifest describes the server's variant catalog: every registered
+// variant with its metadata, availability, instructions, and tools. The
+// tools are listed from each variant's server, with description templates
+// and annotation overrides applied as they would be for a client; ctx is
+// passed to the TemplateDataFunc.
+//
+// Manifest may be called before the server is served and while it runs,
+// but fails with [ErrServerClosed] once it is closed.
+func (s *Server) Manifest(ctx context.Context) (*Manifest, error) {
+	if s.closed() {
+		return nil, ErrServerClosed
+	}
+	if err := s.validateDescriptionTemplates(); err != nil {
+		return nil, err
+	}
+	m := &Manifest{
+		Extension: extensionID,
+		Server:    s.impl,
+		Variants:  make([]VariantManifest, 0, len(s.variants)),
+	}
+	if id, err := s.defaultVariant(ctx); err == nil {
+		m.DefaultVariant = id
+	}
+	for _, entry := range s.variants {
+		v := s.withStatus(entry.variant)
+		init, err := entry.backend.probe(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("variants: probing variant %q: %w", v.ID, err)
+		}
+		tools, err := entry.backend.tools(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("variants: listing tools of variant %q: %w", v.ID, err)
+		}
+		res := &mcp.ListToolsResult{Tools: tools}
+		s.rewriteTools(ctx, v.ID, res)
+		if res.Tools == nil {
+			res.Tools = []*mcp.Tool{}
+		}
+
+		v.Description = s.renderDescription(ctx, v.ID, v.Description)
+		available, reason := s.VariantAvailability(v.ID)
+		vm := VariantManifest{
+			ServerVariant:     v,
+			Available:         available,
+			UnavailableReason: reason,
+			Instructions:      init.Instructions,
+			Tools:             res.Tools,
+		}
+		if counts, ok := s.CatalogCounts(v.ID); ok {
+			vm.CatalogCounts = &counts
+		}
+		m.Variants = append(m.Variants, vm)
+	}
+	return m, nil
+}
+
+// NewManifestHandler returns an [http.Handler] that serves the server's
+// [Manifest] as JSON in response to GET requests, for mounting next to the
+// MCP endpoint:
+//
+//	mux.Handle("/mcp", variants.NewStreamableHTTPHandler(vs, nil))
+//	mux.Handle("/variants/manifest", variants.NewManifestHandler(vs))
+//
+// The manifest is built per request, so it reflects the variants'
+// current availability and tools.
+func NewManifestHandler(vs *Server) http.Handler {
+	if vs == nil {
+		panic("variants: nil Server")
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		m, err := vs.Manifest(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		data, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(append(data, '\n'))
+	})
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest(t *testing.T) {
+	yes := true
+	codingServer, compactServer := newTestServers()
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{
+			ID:          "coding",
+			Description: "Optimized for coding workflows",
+			Hints:       map[string]string{HintUseCase: "ide"},
+			Extra:       map[string]any{"example.com/tier": "pro"},
+		}, codingServer, 0).
+		WithVariant(ServerVariant{ID: "compact", Status: Experimental}, compactServer, 1).
+		WithAnnotationOverride("compact", AnnotationOverride{ReadOnlyHint: &yes})
+	require.NoError(t, vs.SetVariantAvailability("compact", false, "maintenance"))
+
+	m, err := vs.Manifest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, extensionID, m.Extension)
+	assert.Equal(t, "test-server", m.Server.Name)
+	assert.Equal(t, "coding", m.DefaultVariant)
+	require.Len(t, m.Variants, 2)
+
+	coding := m.Variants[0]
+	assert.Equal(t, "coding", coding.ID)
+	assert.True(t, coding.Available)
+	assert.ElementsMatch(t, []string{"analyze_code", "refactor"}, toolNames(coding.Tools))
+	for _, tool := range coding.Tools {
+		assert.NotNil(t, tool.InputSchema, tool.Name)
+	}
+
+	compact := m.Variants[1]
+	assert.Equal(t, 1, compact.Priority())
+	assert.False(t, compact.Available)
+	assert.Equal(t, "maintenance", compact.UnavailableReason)
+	for _, tool := range compact.Tools {
+		assert.True(t, tool.Annotations.ReadOnlyHint, tool.Name)
+	}
+
+	data, err := json.Marshal(coding)
+	require.NoError(t, err)
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, "coding", fields["id"])
+	assert.Equal(t, map[string]any{HintUseCase: "ide"}, fields["hints"])
+	assert.Equal(t, float64(0), fields["priority"])
+	assert.Equal(t, "pro", fields["example.com/tier"])
+	assert.Len(t, fields["tools"], 2)
+}
+
+func TestManifest_Closed(t *testing.T) {
+	vs := newTestVariantServer()
+	require.NoError(t, vs.Close())
+	_, err := vs.Manifest(context.Background())
+	assert.ErrorIs(t, err, ErrServerClosed)
+}
+
+func TestNewManifestHandler(t *testing.T) {
+	srv := httptest.NewServer(NewManifestHandler(newTestVariantServer()))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	var m Manifest
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
+	require.Len(t, m.Variants, 2)
+	assert.Equal(t, "coding", m.Variants[0].ID)
+
+	resp, err = http.Post(srv.URL, "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
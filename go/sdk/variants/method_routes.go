@@ -0,0 +1,75 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// routableMethods are the methods WithMethodRoute accepts.
+var routableMethods = map[string]bool{
+	"tools/list":               true,
+	"tools/call":               true,
+	"prompts/list":             true,
+	"prompts/get":              true,
+	"resources/list":           true,
+	"resources/templates/list": true,
+	"resources/read":           true,
+	"resources/subscribe":      true,
+	"resources/unsubscribe":    true,
+	"completion/complete":      true,
+}
+
+// WithMethodRoute routes every request of the given method to one variant,
+// regardless of the variant the client selects, by tags, composition or
+// default, for hybrid architectures where some capabilities do not vary:
+// e.g. all resources/read requests go to a shared "docs" variant while
+// tools stay per-selection. Routed tools/call requests are not fanned out.
+// The initialize payload advertises routes as methodRoutes, so that
+// clients know which selections are ignored. A later call for the same
+// method replaces the earlier one.
+//
+// The variant must be registered by the time the server is served. It
+// panics if method is not a variant-scoped method (lists, tools/call,
+// prompts/get, resources/read, resources/subscribe,
+// resources/unsubscribe, completion/complete).
+//
+// Returns the receiver for chaining.
+func (s *Server) WithMethodRoute(method, variantID string) *Server {
+	if !routableMethods[method] {
+		panic(fmt.Sprintf("variants: cannot route method %q", method))
+	}
+	if s.methodRoutes == nil {
+		s.methodRoutes = make(map[string]string)
+	}
+	s.methodRoutes[method] = variantID
+	return s
+}
+
+// validateMethodRoutes checks that method routes name registered variants.
+func (s *Server) validateMethodRoutes() error {
+	for method, id := range s.methodRoutes {
+		if !s.hasVariant(id) {
+			return fmt.Errorf("variants: route for %s to unregistered variant %q", method, id)
+		}
+	}
+	return nil
+}
+
+// routeMethod selects the variant the method is routed to in req's _meta,
+// reporting whether the method is routed. Requests sent without params get
+// empty ones to carry the selection.
+func (s *Server) routeMethod(method string, req mcp.Request) bool {
+	id, ok := s.methodRoutes[method]
+	if !ok {
+		return false
+	}
+	if params := ensureParams(req); params != nil {
+		injectVariantMeta(params, id)
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMethodRoute(t *testing.T) {
+	vs := newTestVariantServer().
+		WithVariant(ServerVariant{ID: "docs", Status: Stable}, newDocsServer("docs"), 2).
+		WithMethodRoute("resources/read", "docs")
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	// Tools follow the selection.
+	for id, want := range map[string]string{"coding": "analyze_code", "compact": "summarize"} {
+		tools, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: id}})
+		require.NoError(t, err)
+		assert.Contains(t, toolNames(tools.Tools), want)
+	}
+
+	// Reads go to docs, whatever the selection.
+	for _, meta := range []mcp.Meta{nil, {metaKeyVariant: "compact"}} {
+		res, err := session.ReadResource(ctx, &mcp.ReadResourceParams{Meta: meta, URI: "docs://guide"})
+		require.NoError(t, err)
+		require.Len(t, res.Contents, 1)
+		assert.Equal(t, "docs: docs://guide", res.Contents[0].Text)
+	}
+}
+
+func TestMethodRoute_NilParams(t *testing.T) {
+	vs := newTestVariantServer().WithMethodRoute("tools/list", "compact")
+	tools, err := connectTestClient(t, vs, nil).ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"summarize", "lookup"}, toolNames(tools.Tools))
+}
+
+func TestMethodRoute_Advertised(t *testing.T) {
+	vs := newTestVariantServer().WithMethodRoute("completion/complete", "compact")
+	var payload struct {
+		MethodRoutes map[string]string `json:"methodRoutes"`
+	}
+	initExtension(t, connectTestClient(t, vs, nil), &payload)
+	assert.Equal(t, map[string]string{"completion/complete": "compact"}, payload.MethodRoutes)
+
+	var none map[string]any
+	initExtension(t, connectTestClient(t, newTestVariantServer(), nil), &none)
+	assert.NotContains(t, none, "methodRoutes")
+}
+
+func TestMethodRoute_Validation(t *testing.T) {
+	_, err := newTestVariantServer().WithMethodRoute("resources/read", "ghost").NewRouter(nil)
+	assert.ErrorContains(t, err, `route for resources/read to unregistered variant "ghost"`)
+
+	assert.Panics(t, func() { newTestVariantServer().WithMethodRoute("initialize", "coding") })
+}
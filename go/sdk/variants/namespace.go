@@ -0,0 +1,145 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// namespacePrefix starts the scheme of namespaced resource URIs.
+const namespacePrefix = "variant+"
+
+// WithResourceNamespacing presents resource URIs to clients namespaced by
+// variant, so that clients mixing variants can't read a resource with the
+// same URI from the wrong variant. A URI uri of variant id is presented as
+//
+//	variant+id://uri
+//
+// e.g. "variant+coding://file:///docs/guide.md", in resources/list,
+// resources/templates/list (where the URI template is namespaced),
+// resources/read results and resource update notifications.
+//
+// Namespaced URIs in resources/read, resources/subscribe,
+// resources/unsubscribe and resource completion references are translated
+// back and select their variant, as if it were set in _meta. A request
+// whose _meta selects a different variant fails with an invalid params
+// error. URIs that are not namespaced are passed through unchanged.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithResourceNamespacing(enabled bool) *Server {
+	s.namespaceResources = enabled
+	return s
+}
+
+// namespaceURI returns the URI presented to clients for a resource URI of
+// a variant.
+func namespaceURI(variantID, uri string) string {
+	return namespacePrefix + variantID + "://" + uri
+}
+
+// parseNamespacedURI returns the variant and inner URI of a namespaced
+// resource URI, reporting whether uri is namespaced.
+func parseNamespacedURI(uri string) (variantID, inner string, ok bool) {
+	rest, ok := strings.CutPrefix(uri, namespacePrefix)
+	if !ok {
+		return "", "", false
+	}
+	variantID, inner, ok = strings.Cut(rest, "://")
+	if !ok || variantID == "" {
+		return "", "", false
+	}
+	return variantID, inner, true
+}
+
+// unscopeResourceURI translates a namespaced resource URI in req back to
+// the variant's URI, selecting the variant in req's _meta. It fails if
+// _meta already selects a different variant.
+func unscopeResourceURI(req mcp.Request) error {
+	var uri *string
+	switch p := req.GetParams().(type) {
+	case *mcp.ReadResourceParams:
+		if p != nil {
+			uri = &p.URI
+		}
+	case *mcp.SubscribeParams:
+		if p != nil {
+			uri = &p.URI
+		}
+	case *mcp.UnsubscribeParams:
+		if p != nil {
+			uri = &p.URI
+		}
+	case *mcp.CompleteParams:
+		if p != nil && p.Ref != nil && p.Ref.Type == "ref/resource" {
+			uri = &p.Ref.URI
+		}
+	}
+	if uri == nil {
+		return nil
+	}
+	variantID, inner, ok := parseNamespacedURI(*uri)
+	if !ok {
+		return nil
+	}
+	if requested := variantIDFromMeta(req); requested != "" && requested != variantID {
+		dataJSON, _ := json.Marshal(map[string]any{
+			"uriVariant":       variantID,
+			"requestedVariant": requested,
+		})
+		return &jsonrpc.Error{
+			Code:    jsonrpc.CodeInvalidParams,
+			Message: "Resource URI invalid for requested variant",
+			Data:    json.RawMessage(dataJSON),
+		}
+	}
+	*uri = inner
+	injectVariantMeta(req.GetParams(), variantID)
+	return nil
+}
+
+// scopeResourceURIs namespaces the resource URIs in a list or read result
+// of a variant. The resources are replaced with copies, since they may be
+// shared with the variant's server.
+func scopeResourceURIs(variantID string, result mcp.Result) {
+	switch res := result.(type) {
+	case *mcp.ListResourcesResult:
+		resources := make([]*mcp.Resource, len(res.Resources))
+		for i, r := range res.Resources {
+			if r != nil {
+				c := *r
+				c.URI = namespaceURI(variantID, r.URI)
+				r = &c
+			}
+			resources[i] = r
+		}
+		res.Resources = resources
+	case *mcp.ListResourceTemplatesResult:
+		templates := make([]*mcp.ResourceTemplate, len(res.ResourceTemplates))
+		for i, t := range res.ResourceTemplates {
+			if t != nil {
+				c := *t
+				c.URITemplate = namespaceURI(variantID, t.URITemplate)
+				t = &c
+			}
+			templates[i] = t
+		}
+		res.ResourceTemplates = templates
+	case *mcp.ReadResourceResult:
+		contents := make([]*mcp.ResourceContents, len(res.Contents))
+		for i, rc := range res.Contents {
+			if rc != nil {
+				c := *rc
+				c.URI = namespaceURI(variantID, rc.URI)
+				rc = &c
+			}
+			contents[i] = rc
+		}
+		res.Contents = contents
+	}
+}
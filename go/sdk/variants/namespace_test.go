@@ -0,0 +1,101 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceNamespacing(t *testing.T) {
+	full, summaries := newDocsServer("full"), newDocsServer("summaries")
+	vs := NewServer(&mcp.Implementation{Name: "docs", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "full", Status: Stable}, full, 0).
+		WithVariant(ServerVariant{ID: "summaries", Status: Stable}, summaries, 1).
+		WithResourceNamespacing(true)
+
+	updates := make(chan *mcp.ResourceUpdatedNotificationRequest, 4)
+	session := connectTestClient(t, vs, &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			updates <- req
+		},
+	})
+	ctx := context.Background()
+
+	// Listed URIs are namespaced by variant.
+	list, err := session.ListResources(ctx, &mcp.ListResourcesParams{Meta: mcp.Meta{metaKeyVariant: "summaries"}})
+	require.NoError(t, err)
+	require.Len(t, list.Resources, 1)
+	assert.Equal(t, "variant+summaries://docs://guide", list.Resources[0].URI)
+	templates, err := session.ListResourceTemplates(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, templates.ResourceTemplates, 1)
+	assert.Equal(t, "variant+full://docs://{topic}/{section}", templates.ResourceTemplates[0].URITemplate)
+
+	// The inner servers' resources are not modified.
+	list, err = session.ListResources(ctx, &mcp.ListResourcesParams{Meta: mcp.Meta{metaKeyVariant: "summaries"}})
+	require.NoError(t, err)
+	assert.Equal(t, "variant+summaries://docs://guide", list.Resources[0].URI)
+
+	// Namespaced URIs select their variant without _meta.
+	res, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "variant+summaries://docs://guide"})
+	require.NoError(t, err)
+	require.Len(t, res.Contents, 1)
+	assert.Equal(t, "summaries: docs://guide", res.Contents[0].Text)
+	assert.Equal(t, "variant+summaries://docs://guide", res.Contents[0].URI)
+
+	// Plain URIs are routed as before.
+	res, err = session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "docs://api/auth"})
+	require.NoError(t, err)
+	assert.Equal(t, "full: docs://api/auth", res.Contents[0].Text)
+
+	// A conflicting selection in _meta is rejected.
+	_, err = session.ReadResource(ctx, &mcp.ReadResourceParams{
+		Meta: mcp.Meta{metaKeyVariant: "full"},
+		URI:  "variant+summaries://docs://guide",
+	})
+	var jErr *jsonrpc.Error
+	require.True(t, errors.As(err, &jErr))
+	assert.Equal(t, int64(jsonrpc.CodeInvalidParams), jErr.Code)
+	assert.JSONEq(t, `{"uriVariant":"summaries","requestedVariant":"full"}`, string(jErr.Data))
+
+	// Updates of subscribed resources carry the namespaced URI.
+	require.NoError(t, session.Subscribe(ctx, &mcp.SubscribeParams{URI: "variant+summaries://docs://guide"}))
+	require.NoError(t, summaries.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: "docs://guide"}))
+	select {
+	case req := <-updates:
+		assert.Equal(t, "variant+summaries://docs://guide", req.Params.URI)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for resource updated notification")
+	}
+	require.NoError(t, session.Unsubscribe(ctx, &mcp.UnsubscribeParams{URI: "variant+summaries://docs://guide"}))
+}
+
+func TestParseNamespacedURI(t *testing.T) {
+	tests := []struct {
+		uri, variant, inner string
+		ok                  bool
+	}{
+		{"variant+coding://file:///a.md", "coding", "file:///a.md", true},
+		{"variant+a.b://x://y", "a.b", "x://y", true},
+		{"variant+://x", "", "", false},
+		{"variant+coding:x", "", "", false},
+		{"file:///a.md", "", "", false},
+	}
+	for _, tt := range tests {
+		variant, inner, ok := parseNamespacedURI(tt.uri)
+		assert.Equal(t, tt.ok, ok, tt.uri)
+		assert.Equal(t, tt.variant, variant, tt.uri)
+		assert.Equal(t, tt.inner, inner, tt.uri)
+	}
+	assert.Equal(t, "variant+coding://file:///a.md", namespaceURI("coding", "file:///a.md"))
+}
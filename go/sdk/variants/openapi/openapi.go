@@ -0,0 +1,582 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+// Package openapi generates variant servers from OpenAPI 3 specifications,
+// for exposing an existing REST API as a multi-variant MCP server.
+//
+// Each operation of the spec becomes a tool that calls the API. A
+// [VariantMapping] selects the operations of one variant by OpenAPI tag or
+// operation ID:
+//
+//	doc, err := openapi.Load(spec)
+//	vs, err := openapi.Generate(doc, []openapi.VariantMapping{
+//		{Variant: variants.ServerVariant{ID: "issues"}, Tags: []string{"issues"}},
+//		{Variant: variants.ServerVariant{ID: "admin"}, Tags: []string{"admin"}, Priority: 1},
+//	}, nil)
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+// bodyArgument is the tool argument carrying an operation's JSON request
+// body.
+const bodyArgument = "body"
+
+// maxRefDepth bounds $ref resolution, so that recursive schemas terminate.
+const maxRefDepth = 8
+
+// MaxResponseSize is the maximum size in bytes of an API response body
+// that a tool call returns; calls whose responses are larger fail.
+const MaxResponseSize = 10 << 20
+
+// methods are the HTTP methods of OpenAPI path items, in the order their
+// operations are listed.
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Document is a parsed OpenAPI 3 document.
+type Document struct {
+	root       map[string]any
+	title      string
+	version    string
+	baseURL    string
+	operations []*Operation
+}
+
+// Operation is one operation of a [Document].
+type Operation struct {
+	// ID is the operation's operationId, or one derived from its method and
+	// path if it has none. It is used as the tool name.
+	ID string
+
+	// Method is the upper-case HTTP method.
+	Method string
+
+	// Path is the path template, such as "/repos/{owner}/{repo}".
+	Path string
+
+	// Tags are the operation's OpenAPI tags.
+	Tags []string
+
+	summary     string
+	description string
+	params      []parameter
+	body        map[string]any // JSON schema of the request body, if any
+	bodyReq     bool
+}
+
+// parameter is a path, query, or header parameter of an operation.
+type parameter struct {
+	name     string
+	in       string
+	required bool
+	schema   map[string]any
+}
+
+// Load parses an OpenAPI 3 document in JSON or YAML.
+func Load(data []byte) (*Document, error) {
+	var root map[string]any
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("openapi: parsing document: %w", err)
+	}
+	version, _ := root["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("openapi: unsupported document version %q, want 3.x", version)
+	}
+	d := &Document{root: root}
+	if info, ok := root["info"].(map[string]any); ok {
+		d.title, _ = info["title"].(string)
+		d.version, _ = info["version"].(string)
+	}
+	if servers, ok := root["servers"].([]any); ok && len(servers) > 0 {
+		if s, ok := servers[0].(map[string]any); ok {
+			d.baseURL, _ = s["url"].(string)
+		}
+	}
+	if err := d.loadOperations(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Operations returns the document's operations, ordered by path and then
+// method.
+func (d *Document) Operations() []*Operation {
+	return slices.Clone(d.operations)
+}
+
+// loadOperations collects the operations of all path items.
+func (d *Document) loadOperations() error {
+	paths, _ := d.root["paths"].(map[string]any)
+	keys := make([]string, 0, len(paths))
+	for p := range paths {
+		keys = append(keys, p)
+	}
+	sort.Strings(keys)
+
+	seen := make(map[string]bool)
+	for _, path := range keys {
+		item, _ := d.resolve(paths[path], 0).(map[string]any)
+		common := d.parameters(item["parameters"])
+		for _, method := range methods {
+			raw, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			op, err := d.operation(method, path, raw, common)
+			if err != nil {
+				return err
+			}
+			if seen[op.ID] {
+				return fmt.Errorf("openapi: duplicate operation ID %q", op.ID)
+			}
+			seen[op.ID] = true
+			d.operations = append(d.operations, op)
+		}
+	}
+	return nil
+}
+
+// operation parses one operation. Its own parameters override the path
+// item's common parameters of the same name and location.
+func (d *Document) operation(method, path string, raw map[string]any, common []parameter) (*Operation, error) {
+	op := &Operation{Method: strings.ToUpper(method), Path: path}
+	op.ID, _ = raw["operationId"].(string)
+	if op.ID == "" {
+		op.ID = method + "_" + strings.Trim(nonIdentChars.ReplaceAllString(path, "_"), "_")
+	}
+	op.summary, _ = raw["summary"].(string)
+	op.description, _ = raw["description"].(string)
+	if tags, ok := raw["tags"].([]any); ok {
+		for _, t := range tags {
+			if s, ok := t.(string); ok {
+				op.Tags = append(op.Tags, s)
+			}
+		}
+	}
+
+	own := d.parameters(raw["parameters"])
+	for _, p := range common {
+		if !slices.ContainsFunc(own, func(o parameter) bool { return o.name == p.name && o.in == p.in }) {
+			op.params = append(op.params, p)
+		}
+	}
+	op.params = append(op.params, own...)
+
+	if body, ok := d.resolve(raw["requestBody"], 0).(map[string]any); ok {
+		content, _ := body["content"].(map[string]any)
+		media, ok := content["application/json"].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("openapi: operation %q: only application/json request bodies are supported", op.ID)
+		}
+		op.body, _ = d.resolve(media["schema"], 0).(map[string]any)
+		if op.body == nil {
+			op.body = map[string]any{}
+		}
+		op.bodyReq, _ = body["required"].(bool)
+	}
+
+	names := make(map[string]bool)
+	for _, p := range op.params {
+		if names[p.name] || (op.body != nil && p.name == bodyArgument) {
+			return nil, fmt.Errorf("openapi: operation %q: parameter name %q is ambiguous", op.ID, p.name)
+		}
+		names[p.name] = true
+	}
+	return op, nil
+}
+
+// parameters parses a list of parameter objects, skipping cookie
+// parameters.
+func (d *Document) parameters(raw any) []parameter {
+	list, _ := raw.([]any)
+	var params []parameter
+	for _, r := range list {
+		p, ok := d.resolve(r, 0).(map[string]any)
+		if !ok {
+			continue
+		}
+		param := parameter{}
+		param.name, _ = p["name"].(string)
+		param.in, _ = p["in"].(string)
+		param.required, _ = p["required"].(bool)
+		param.schema, _ = p["schema"].(map[string]any)
+		if param.schema == nil {
+			param.schema = map[string]any{"type": "string"}
+		}
+		if desc, ok := p["description"].(string); ok {
+			param.schema = withDescription(param.schema, desc)
+		}
+		if param.name == "" || param.in == "cookie" {
+			continue
+		}
+		param.required = param.required || param.in == "path"
+		params = append(params, param)
+	}
+	return params
+}
+
+// resolve returns v with local $ref references ("#/components/...")
+// replaced by their targets, recursively. References nested deeper than
+// maxRefDepth, and references that do not resolve, become empty schemas.
+func (d *Document) resolve(v any, depth int) any {
+	switch v := v.(type) {
+	case map[string]any:
+		if ref, ok := v["$ref"].(string); ok {
+			if depth >= maxRefDepth {
+				return map[string]any{}
+			}
+			target, ok := d.lookup(ref)
+			if !ok {
+				return map[string]any{}
+			}
+			return d.resolve(target, depth+1)
+		}
+		out := make(map[string]any, len(v))
+		for k, x := range v {
+			out[k] = d.resolve(x, depth)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, x := range v {
+			out[i] = d.resolve(x, depth)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// lookup finds the target of a local JSON pointer reference.
+func (d *Document) lookup(ref string) (any, bool) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, false
+	}
+	var cur any = d.root
+	for _, tok := range strings.Split(ref[2:], "/") {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[tok]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// nonIdentChars matches characters not allowed in derived operation IDs.
+var nonIdentChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// withDescription returns a copy of schema with its description set, unless
+// it already has one.
+func withDescription(schema map[string]any, desc string) map[string]any {
+	if _, ok := schema["description"]; ok {
+		return schema
+	}
+	out := make(map[string]any, len(schema)+1)
+	for k, v := range schema {
+		out[k] = v
+	}
+	out["description"] = desc
+	return out
+}
+
+// ---------------------------------------------------------------------------
+// Generation
+// ---------------------------------------------------------------------------
+
+// VariantMapping selects the operations of one variant.
+type VariantMapping struct {
+	// Variant describes the variant.
+	Variant variants.ServerVariant
+
+	// Priority is the variant's priority, as for Server.WithVariant.
+	Priority int
+
+	// Tags selects the operations carrying any of these OpenAPI tags.
+	Tags []string
+
+	// Operations selects operations by ID, in addition to those selected
+	// by Tags. If both Tags and Operations are empty, all operations are
+	// selected.
+	Operations []string
+}
+
+// Options configure the generated servers. The zero value is valid.
+type Options struct {
+	// Implementation identifies the generated servers. It defaults to the
+	// document's info title and version.
+	Implementation *mcp.Implementation
+
+	// BaseURL is the URL that operation paths are relative to. It defaults
+	// to the URL of the document's first server.
+	BaseURL string
+
+	// HTTPClient makes the API calls; use its Transport to add
+	// authentication. It defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Generate returns a variant server with one variant per mapping, each
+// backed by a server from [Document.NewServer]. opts may be nil.
+func Generate(d *Document, mappings []VariantMapping, opts *Options) (*variants.Server, error) {
+	if len(mappings) == 0 {
+		return nil, errors.New("openapi: no variant mappings")
+	}
+	vs := variants.NewServer(d.implementation(opts))
+	for _, m := range mappings {
+		s, err := d.NewServer(m, opts)
+		if err != nil {
+			return nil, err
+		}
+		vs.WithVariant(m.Variant, s, m.Priority)
+	}
+	return vs, nil
+}
+
+// NewServer returns an MCP server exposing the operations selected by m as
+// tools. opts may be nil.
+//
+// A tool's arguments are the operation's path, query, and header
+// parameters, plus "body" for its JSON request body. Tools for GET and
+// HEAD operations are annotated read-only, and those for DELETE
+// destructive, so that variants can be derived with
+// [variants.DeriveReadOnly]. A tool call's result is the API's response
+// body, flagged as an error for non-2xx responses.
+func (d *Document) NewServer(m VariantMapping, opts *Options) (*mcp.Server, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = d.baseURL
+	}
+	if baseURL == "" {
+		return nil, errors.New("openapi: no base URL: the document has no servers and Options.BaseURL is empty")
+	}
+	client := opts.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	ops, err := d.selectOperations(m)
+	if err != nil {
+		return nil, err
+	}
+	s := mcp.NewServer(d.implementation(opts), nil)
+	for _, op := range ops {
+		c := &caller{op: op, baseURL: strings.TrimSuffix(baseURL, "/"), client: client}
+		s.AddTool(op.tool(), c.call)
+	}
+	return s, nil
+}
+
+// selectOperations returns the operations selected by m, failing if there
+// are none or if m names an unknown operation.
+func (d *Document) selectOperations(m VariantMapping) ([]*Operation, error) {
+	for _, id := range m.Operations {
+		if !slices.ContainsFunc(d.operations, func(op *Operation) bool { return op.ID == id }) {
+			return nil, fmt.Errorf("openapi: variant %q: unknown operation %q", m.Variant.ID, id)
+		}
+	}
+	var ops []*Operation
+	for _, op := range d.operations {
+		all := len(m.Tags) == 0 && len(m.Operations) == 0
+		byTag := slices.ContainsFunc(op.Tags, func(t string) bool { return slices.Contains(m.Tags, t) })
+		if all || byTag || slices.Contains(m.Operations, op.ID) {
+			ops = append(ops, op)
+		}
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("openapi: variant %q: no operations selected", m.Variant.ID)
+	}
+	return ops, nil
+}
+
+// implementation returns the implementation of the generated servers.
+func (d *Document) implementation(opts *Options) *mcp.Implementation {
+	if opts != nil && opts.Implementation != nil {
+		return opts.Implementation
+	}
+	return &mcp.Implementation{Name: d.title, Version: d.version}
+}
+
+// tool returns the tool definition of the operation.
+func (op *Operation) tool() *mcp.Tool {
+	props := map[string]any{}
+	required := []string{}
+	for _, p := range op.params {
+		props[p.name] = p.schema
+		if p.required {
+			required = append(required, p.name)
+		}
+	}
+	if op.body != nil {
+		props[bodyArgument] = op.body
+		if op.bodyReq {
+			required = append(required, bodyArgument)
+		}
+	}
+	schema := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	desc := op.summary
+	if op.description != "" {
+		if desc != "" {
+			desc += "\n\n"
+		}
+		desc += op.description
+	}
+
+	t := &mcp.Tool{Name: op.ID, Description: desc, InputSchema: schema}
+	switch op.Method {
+	case http.MethodGet, http.MethodHead:
+		t.Annotations = &mcp.ToolAnnotations{ReadOnlyHint: true}
+	case http.MethodDelete:
+		destructive := true
+		t.Annotations = &mcp.ToolAnnotations{DestructiveHint: &destructive, IdempotentHint: true}
+	case http.MethodPut:
+		t.Annotations = &mcp.ToolAnnotations{IdempotentHint: true}
+	}
+	return t
+}
+
+// caller calls the API for one operation's tool.
+type caller struct {
+	op      *Operation
+	baseURL string
+	client  *http.Client
+}
+
+// call performs the API request for a tool call.
+func (c *caller) call(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var args map[string]any
+	if raw := req.Params.Arguments; len(raw) > 0 {
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+	}
+	httpReq, err := c.request(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("calling %s %s: %w", c.op.Method, c.op.Path, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("calling %s %s: %w", c.op.Method, c.op.Path, err)
+	}
+	if len(body) > MaxResponseSize {
+		return nil, fmt.Errorf("calling %s %s: response exceeds %d bytes", c.op.Method, c.op.Path, MaxResponseSize)
+	}
+
+	text := string(body)
+	failed := resp.StatusCode < 200 || resp.StatusCode > 299
+	if failed {
+		text = resp.Status + ": " + text
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+		IsError: failed,
+	}, nil
+}
+
+// request builds the HTTP request of a tool call.
+func (c *caller) request(ctx context.Context, args map[string]any) (*http.Request, error) {
+	path := c.op.Path
+	query := url.Values{}
+	header := http.Header{}
+	for _, p := range c.op.params {
+		v, ok := args[p.name]
+		if !ok || v == nil {
+			if p.required {
+				return nil, fmt.Errorf("missing required argument %q", p.name)
+			}
+			continue
+		}
+		switch p.in {
+		case "path":
+			// PathEscape keeps dot segments, which would change the path
+			// the argument is substituted into.
+			seg := argString(v)
+			if seg == "." || seg == ".." {
+				return nil, fmt.Errorf("invalid argument %q: %q is not a path segment", p.name, seg)
+			}
+			path = strings.ReplaceAll(path, "{"+p.name+"}", url.PathEscape(seg))
+		case "query":
+			if list, ok := v.([]any); ok {
+				for _, x := range list {
+					query.Add(p.name, argString(x))
+				}
+			} else {
+				query.Set(p.name, argString(v))
+			}
+		case "header":
+			header.Set(p.name, argString(v))
+		}
+	}
+
+	var body io.Reader
+	if b, ok := args[bodyArgument]; ok && c.op.body != nil {
+		data, err := json.Marshal(b)
+		if err != nil {
+			return nil, fmt.Errorf("encoding body: %w", err)
+		}
+		body = bytes.NewReader(data)
+		header.Set("Content-Type", "application/json")
+	} else if c.op.bodyReq {
+		return nil, fmt.Errorf("missing required argument %q", bodyArgument)
+	}
+
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, c.op.Method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		httpReq.Header[k] = v
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	return httpReq, nil
+}
+
+// argString formats a scalar argument for a URL or header.
+func argString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const issuesSpec = `
+openapi: 3.0.3
+info:
+  title: issues-api
+  version: 2.1.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /repos/{repo}/issues:
+    parameters:
+      - $ref: '#/components/parameters/Repo'
+    get:
+      operationId: list_issues
+      summary: List issues
+      tags: [issues]
+      parameters:
+        - name: state
+          in: query
+          schema: {type: string, enum: [open, closed]}
+    post:
+      operationId: create_issue
+      summary: Create an issue
+      tags: [issues]
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/Issue'}
+  /repos/{repo}:
+    delete:
+      summary: Delete a repository
+      tags: [admin]
+      parameters:
+        - $ref: '#/components/parameters/Repo'
+components:
+  parameters:
+    Repo:
+      name: repo
+      in: path
+      description: Repository name
+      schema: {type: string}
+  schemas:
+    Issue:
+      type: object
+      properties:
+        title: {type: string}
+`
+
+func TestLoad(t *testing.T) {
+	d, err := Load([]byte(issuesSpec))
+	require.NoError(t, err)
+
+	var ids []string
+	for _, op := range d.Operations() {
+		ids = append(ids, op.ID)
+	}
+	assert.Equal(t, []string{"delete_repos_repo", "list_issues", "create_issue"}, ids)
+
+	tools := map[string]*mcp.Tool{}
+	for _, op := range d.Operations() {
+		tools[op.ID] = op.tool()
+	}
+	assert.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"repo":  map[string]any{"type": "string", "description": "Repository name"},
+			"state": map[string]any{"type": "string", "enum": []any{"open", "closed"}},
+		},
+		"required": []string{"repo"},
+	}, tools["list_issues"].InputSchema)
+	assert.Equal(t, map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"title": map[string]any{"type": "string"}},
+	}, tools["create_issue"].InputSchema.(map[string]any)["properties"].(map[string]any)["body"])
+	assert.True(t, tools["list_issues"].Annotations.ReadOnlyHint)
+	assert.True(t, *tools["delete_repos_repo"].Annotations.DestructiveHint)
+	assert.Nil(t, tools["create_issue"].Annotations)
+
+	_, err = Load([]byte(`{"swagger": "2.0"}`))
+	assert.ErrorContains(t, err, "unsupported document version")
+}
+
+func TestGenerate(t *testing.T) {
+	var got *http.Request
+	var gotBody string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		data, _ := io.ReadAll(r.Body)
+		gotBody = string(data)
+		if r.URL.Query().Get("state") == "bogus" {
+			http.Error(w, "bad state", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(api.Close)
+
+	d, err := Load([]byte(issuesSpec))
+	require.NoError(t, err)
+	vs, err := Generate(d, []VariantMapping{
+		{Variant: variants.ServerVariant{ID: "issues"}, Tags: []string{"issues"}},
+		{Variant: variants.ServerVariant{ID: "admin"}, Operations: []string{"delete_repos_repo"}, Priority: 1},
+	}, &Options{BaseURL: api.URL + "/v1"})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	st, ct := mcp.NewInMemoryTransports()
+	go func() { _ = vs.Run(ctx, st) }()
+	t.Cleanup(func() { _ = vs.Close() })
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, ct, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+	assert.Equal(t, "issues-api", session.InitializeResult().ServerInfo.Name)
+
+	res, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	var names []string
+	for _, tool := range res.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.ElementsMatch(t, []string{"list_issues", "create_issue"}, names)
+
+	call, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "list_issues",
+		Arguments: map[string]any{"repo": "a b", "state": "open"},
+	})
+	require.NoError(t, err)
+	assert.False(t, call.IsError)
+	assert.Equal(t, `{"ok":true}`, call.Content[0].(*mcp.TextContent).Text)
+	assert.Equal(t, http.MethodGet, got.Method)
+	assert.Equal(t, "/v1/repos/a%20b/issues", got.URL.EscapedPath())
+	assert.Equal(t, "open", got.URL.Query().Get("state"))
+
+	call, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "create_issue",
+		Arguments: map[string]any{"repo": "r", "body": map[string]any{"title": "bug"}},
+	})
+	require.NoError(t, err)
+	assert.False(t, call.IsError)
+	assert.Equal(t, http.MethodPost, got.Method)
+	assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"title":"bug"}`, gotBody)
+
+	call, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "list_issues",
+		Arguments: map[string]any{"repo": "r", "state": "bogus"},
+	})
+	require.NoError(t, err)
+	assert.True(t, call.IsError)
+	assert.Contains(t, call.Content[0].(*mcp.TextContent).Text, "400 Bad Request")
+
+	call, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "delete_repos_repo",
+		Meta:      mcp.Meta{"io.modelcontextprotocol/server-variant": "admin"},
+		Arguments: map[string]any{"repo": "r"},
+	})
+	require.NoError(t, err)
+	assert.False(t, call.IsError)
+	assert.Equal(t, http.MethodDelete, got.Method)
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	d, err := Load([]byte(issuesSpec))
+	require.NoError(t, err)
+
+	_, err = Generate(d, nil, nil)
+	assert.Error(t, err)
+	_, err = Generate(d, []VariantMapping{{Variant: variants.ServerVariant{ID: "x"}, Tags: []string{"none"}}}, nil)
+	assert.ErrorContains(t, err, "no operations selected")
+	_, err = Generate(d, []VariantMapping{{Variant: variants.ServerVariant{ID: "x"}, Operations: []string{"nope"}}}, nil)
+	assert.ErrorContains(t, err, `unknown operation "nope"`)
+
+	noServers, err := Load([]byte(`{"openapi": "3.1.0", "info": {"title": "t", "version": "1"}, "paths": {}}`))
+	require.NoError(t, err)
+	_, err = noServers.NewServer(VariantMapping{}, nil)
+	assert.ErrorContains(t, err, "no base URL")
+}
+
+func TestArgString(t *testing.T) {
+	assert.Equal(t, "1000000", argString(float64(1e6)))
+	assert.Equal(t, "true", argString(true))
+	data, _ := json.Marshal(map[string]int{"a": 1})
+	assert.Equal(t, string(data), argString(map[string]any{"a": float64(1)}))
+}
+
+func TestCaller_Limits(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(make([]byte, MaxResponseSize+1))
+	}))
+	t.Cleanup(api.Close)
+	d, err := Load([]byte(issuesSpec))
+	require.NoError(t, err)
+	c := &caller{op: d.Operations()[1], baseURL: api.URL, client: http.DefaultClient}
+	require.Equal(t, "list_issues", c.op.ID)
+
+	for _, repo := range []string{".", ".."} {
+		_, err := c.request(context.Background(), map[string]any{"repo": repo})
+		assert.ErrorContains(t, err, "is not a path segment", repo)
+	}
+
+	_, err = c.call(context.Background(), &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Arguments: json.RawMessage(`{"repo":"r"}`)}})
+	assert.ErrorContains(t, err, "response exceeds")
+}
@@ -0,0 +1,275 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// WithOwnershipIndex enables or disables an index mapping tool, prompt and
+// resource names to the variants listing them, looked up with ToolOwners,
+// PromptOwners and ResourceOwners. The index is built when serving starts
+// and kept fresh from the variants' list-changed notifications. When
+// enabled, tool checks and redirects (see WithToolCheck and
+// WithToolRedirect) and composed calls (see WithComposition) find owners
+// in the index instead of listing variants. Disabled by default.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithOwnershipIndex(enabled bool) *Server {
+	if enabled {
+		s.ownership = &ownershipIndex{}
+	} else {
+		s.ownership = nil
+	}
+	return s
+}
+
+// ToolOwners returns the IDs of the variants listing the tool with the
+// given name, in registration order. It returns nil if the ownership index
+// is disabled (see WithOwnershipIndex) or not yet built.
+func (s *Server) ToolOwners(name string) []string {
+	if s.ownership == nil {
+		return nil
+	}
+	return s.ownership.lookup(func(c *variantCatalog) bool { return c.tools[name] })
+}
+
+// PromptOwners returns the IDs of the variants listing the prompt with the
+// given name, in registration order, as for ToolOwners.
+func (s *Server) PromptOwners(name string) []string {
+	if s.ownership == nil {
+		return nil
+	}
+	return s.ownership.lookup(func(c *variantCatalog) bool { return c.prompts[name] })
+}
+
+// ResourceOwners returns the IDs of the variants listing the resource with
+// the given URI, or a resource template matching it, in registration
+// order, as for ToolOwners. URIs are the variants' own, not namespaced
+// (see WithResourceNamespacing).
+func (s *Server) ResourceOwners(uri string) []string {
+	if s.ownership == nil {
+		return nil
+	}
+	return s.ownership.lookup(func(c *variantCatalog) bool {
+		return c.resources[uri] || slices.ContainsFunc(c.templates, func(t *regexp.Regexp) bool { return t.MatchString(uri) })
+	})
+}
+
+// ownershipIndex holds the catalogs of the variants.
+type ownershipIndex struct {
+	mu       sync.RWMutex
+	order    []string                   // variant IDs in registration order
+	catalogs map[string]*variantCatalog // by variant ID; missing until listed
+	stops    []func()                   // close the watching client sessions
+}
+
+// variantCatalog is the names a variant lists.
+type variantCatalog struct {
+	tools     map[string]bool
+	prompts   map[string]bool
+	resources map[string]bool
+	templates []*regexp.Regexp
+}
+
+// lookup returns the variants whose catalogs satisfy owns.
+func (x *ownershipIndex) lookup(owns func(*variantCatalog) bool) []string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	var out []string
+	for _, id := range x.order {
+		if c := x.catalogs[id]; c != nil && owns(c) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// start connects a client to each variant, lists its catalog, and relists
+// it whenever the variant reports a list change. Variants whose backends
+// cannot be connected are left out of the index.
+func (x *ownershipIndex) start(ctx context.Context, s *Server) {
+	x.mu.Lock()
+	x.catalogs = make(map[string]*variantCatalog, len(s.variants))
+	for _, entry := range s.variants {
+		x.order = append(x.order, entry.variant.ID)
+	}
+	x.mu.Unlock()
+
+	for _, entry := range s.variants {
+		id := entry.variant.ID
+		var (
+			refreshMu sync.Mutex
+			cs        *mcp.ClientSession
+		)
+		refresh := func() {
+			refreshMu.Lock()
+			defer refreshMu.Unlock()
+			if cs == nil {
+				return
+			}
+			c, err := listCatalog(context.Background(), cs)
+			if err != nil {
+				return
+			}
+			x.mu.Lock()
+			x.catalogs[id] = c
+			x.mu.Unlock()
+		}
+		refreshMu.Lock()
+		var stop func()
+		var err error
+		cs, stop, err = entry.backend.watch(ctx, func() { go refresh() })
+		refreshMu.Unlock()
+		if err != nil {
+			continue
+		}
+		x.mu.Lock()
+		x.stops = append(x.stops, stop)
+		x.mu.Unlock()
+		refresh()
+	}
+}
+
+// stop closes the watching client sessions.
+func (x *ownershipIndex) stop() {
+	x.mu.Lock()
+	stops := x.stops
+	x.stops = nil
+	x.mu.Unlock()
+	for _, stop := range stops {
+		stop()
+	}
+}
+
+// listCatalog lists the names of the tools, prompts, resources and
+// resource templates cs's server offers, per its capabilities.
+func listCatalog(ctx context.Context, cs *mcp.ClientSession) (*variantCatalog, error) {
+	c := &variantCatalog{
+		tools:     map[string]bool{},
+		prompts:   map[string]bool{},
+		resources: map[string]bool{},
+	}
+	caps := &mcp.ServerCapabilities{}
+	if res := cs.InitializeResult(); res != nil && res.Capabilities != nil {
+		caps = res.Capabilities
+	}
+	if caps.Tools != nil {
+		for t, err := range cs.Tools(ctx, nil) {
+			if err != nil {
+				return nil, err
+			}
+			c.tools[t.Name] = true
+		}
+	}
+	if caps.Prompts != nil {
+		for p, err := range cs.Prompts(ctx, nil) {
+			if err != nil {
+				return nil, err
+			}
+			c.prompts[p.Name] = true
+		}
+	}
+	if caps.Resources != nil {
+		for r, err := range cs.Resources(ctx, nil) {
+			if err != nil {
+				return nil, err
+			}
+			c.resources[r.URI] = true
+		}
+		for t, err := range cs.ResourceTemplates(ctx, nil) {
+			if err != nil {
+				return nil, err
+			}
+			if re, err := uriTemplatePattern(t.URITemplate); err == nil {
+				c.templates = append(c.templates, re)
+			}
+		}
+	}
+	return c, nil
+}
+
+// uriTemplatePattern compiles a URI template (RFC 6570) into a pattern
+// matching the URIs it expands to. Simple expressions ({var}) match
+// within a path segment; reserved and path expressions ({+var}, {/var},
+// ...) match across segments.
+func uriTemplatePattern(template string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+		b.WriteString(regexp.QuoteMeta(template[:start]))
+		expr := template[start+1 : start+end]
+		if expr != "" && strings.ContainsRune("+#/.;?&", rune(expr[0])) {
+			b.WriteString(".*")
+		} else {
+			b.WriteString("[^/?#]*")
+		}
+		template = template[start+end+1:]
+	}
+	b.WriteString(regexp.QuoteMeta(template))
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// owns reports whether the variant's catalog satisfies owns. It reports
+// false for ok if the index is disabled or has not listed the variant.
+func (x *ownershipIndex) owns(variantID string, owns func(*variantCatalog) bool) (owned, ok bool) {
+	if x == nil {
+		return false, false
+	}
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	c := x.catalogs[variantID]
+	if c == nil {
+		return false, false
+	}
+	return owns(c), true
+}
+
+// listsTool reports whether the variant of conn lists the named tool,
+// looked up in the ownership index if it covers the variant, else by
+// listing the variant's tools.
+func (d *dispatcher) listsTool(ctx context.Context, conn *innerConnection, name string) (bool, error) {
+	if owned, ok := d.server.ownership.owns(conn.backendSession.variantID, func(c *variantCatalog) bool { return c.tools[name] }); ok {
+		return owned, nil
+	}
+	tool, err := d.findTool(ctx, conn, name)
+	return tool != nil, err
+}
+
+// indexedComposedOwner returns the first of the composed variants listing
+// the named tool or prompt, per listMethod, according to the ownership
+// index. It reports false unless the index covers all the variants and one
+// of them lists the name.
+func (s *Server) indexedComposedOwner(ids []string, listMethod, name string) (string, bool) {
+	owns := func(c *variantCatalog) bool { return c.tools[name] }
+	if listMethod == "prompts/list" {
+		owns = func(c *variantCatalog) bool { return c.prompts[name] }
+	}
+	for _, id := range ids {
+		owned, ok := s.ownership.owns(id, owns)
+		if !ok {
+			return "", false
+		}
+		if owned {
+			return id, true
+		}
+	}
+	return "", false
+}
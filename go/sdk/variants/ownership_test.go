@@ -0,0 +1,89 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnershipIndex(t *testing.T) {
+	docs := newDocsServer("docs")
+	mcp.AddTool(docs, &mcp.Tool{Name: "summarize", Description: "Summarize docs"}, summarize)
+	docs.AddPrompt(&mcp.Prompt{Name: "explain"}, func(context.Context, *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return &mcp.GetPromptResult{}, nil
+	})
+	vs := newTestVariantServer().
+		WithVariant(ServerVariant{ID: "docs", Description: "Docs"}, docs, 2).
+		WithOwnershipIndex(true)
+	assert.Nil(t, vs.ToolOwners("summarize"), "not built before serving")
+
+	_, err := vs.NewRouter(nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { vs.Close() })
+
+	assert.Equal(t, []string{"compact", "docs"}, vs.ToolOwners("summarize"))
+	assert.Equal(t, []string{"coding"}, vs.ToolOwners("refactor"))
+	assert.Nil(t, vs.ToolOwners("missing"))
+	assert.Equal(t, []string{"docs"}, vs.PromptOwners("explain"))
+	assert.Equal(t, []string{"docs"}, vs.ResourceOwners("docs://guide"))
+	assert.Equal(t, []string{"docs"}, vs.ResourceOwners("docs://api/intro"), "matches the template")
+	assert.Nil(t, vs.ResourceOwners("docs://api/intro/extra"))
+}
+
+func TestOwnershipIndex_ListChanged(t *testing.T) {
+	coding, compact := newTestServers()
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "coding", Description: "Coding"}, coding, 0).
+		WithVariant(ServerVariant{ID: "compact", Description: "Compact"}, compact, 1).
+		WithOwnershipIndex(true)
+	_, err := vs.NewRouter(nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { vs.Close() })
+
+	mcp.AddTool(coding, &mcp.Tool{Name: "lookup", Description: "Coding lookup"}, lookup)
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"coding", "compact"}, vs.ToolOwners("lookup"))
+	}, 5*time.Second, 10*time.Millisecond)
+
+	compact.RemoveTools("lookup")
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"coding"}, vs.ToolOwners("lookup"))
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestOwnershipIndex_ToolRedirect(t *testing.T) {
+	vs := newTestVariantServer().WithToolRedirect(true).WithOwnershipIndex(true)
+	session := connectTestClient(t, vs, nil)
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "summarize",
+		Arguments: map[string]any{"text": "redirected"},
+	})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+}
+
+func TestURITemplatePattern(t *testing.T) {
+	for _, tc := range []struct {
+		template, uri string
+		match         bool
+	}{
+		{"docs://{topic}", "docs://intro", true},
+		{"docs://{topic}", "docs://intro/more", false},
+		{"file:///{+path}", "file:///a/b/c.txt", true},
+		{"db://tables{/name}", "db://tables/users", true},
+		{"x://a.b/{id}", "x://aXb/1", false},
+	} {
+		re, err := uriTemplatePattern(tc.template)
+		require.NoError(t, err)
+		assert.Equal(t, tc.match, re.MatchString(tc.uri), "%s ~ %s", tc.template, tc.uri)
+	}
+}
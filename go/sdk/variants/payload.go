@@ -0,0 +1,263 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// AvailableVariantsPayload is the extension payload a server sends under
+// the extension ID: in the experimental capabilities of its initialize
+// result, and in the _meta of the tools/list_changed notifications that
+// update a session's variants.
+type AvailableVariantsPayload struct {
+	// AvailableVariants are the variants offered to the client,
+	// best-ranked first. Entry fields that ServerVariant does not cover,
+	// such as instructions, availability and ranking, are in each variant's
+	// Extra.
+	AvailableVariants []ServerVariant `json:"availableVariants"`
+
+	// MoreVariantsAvailable reports whether the server has variants that
+	// are not listed.
+	MoreVariantsAvailable bool `json:"moreVariantsAvailable"`
+
+	DefaultVariant     string `json:"defaultVariant"`
+	RecommendedVariant string `json:"recommendedVariant"`
+
+	// NormalizedHints echoes the hints the server ranked by, if the client
+	// sent any.
+	NormalizedHints *NormalizedHints `json:"normalizedHints,omitempty"`
+
+	VariantGroups []VariantGroup      `json:"variantGroups,omitempty"`
+	MethodRoutes  map[string]string   `json:"methodRoutes,omitempty"`
+	Composition   *CompositionSupport `json:"composition,omitempty"`
+
+	// VariantToken resumes the session's variant on reconnect (see
+	// WithVariantTokens).
+	VariantToken string `json:"variantToken,omitempty"`
+
+	// Extra holds payload fields not covered above, by name.
+	Extra map[string]any `json:"-"`
+
+	// entries, if set, are the encoded AvailableVariants, as built by a
+	// Server (see payloadCache).
+	entries []json.RawMessage
+}
+
+// CompositionSupport advertises multi-variant composition (see
+// WithComposition).
+type CompositionSupport struct {
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy"`
+}
+
+// payloadFields aliases AvailableVariantsPayload without its methods.
+type payloadFields AvailableVariantsPayload
+
+var (
+	knownPayloadFields = jsonFieldNames(reflect.TypeFor[AvailableVariantsPayload]())
+	knownVariantFields = jsonFieldNames(reflect.TypeFor[ServerVariant]())
+)
+
+// MarshalJSON encodes the payload, merging each variant's Extra into its
+// entry and the payload's Extra into the payload. Extra fields of variants
+// override the others; those of the payload do not.
+func (p AvailableVariantsPayload) MarshalJSON() ([]byte, error) {
+	entries := p.entries
+	if entries == nil {
+		entries = make([]json.RawMessage, len(p.AvailableVariants))
+		for i, v := range p.AvailableVariants {
+			entry, err := marshalWithExtra(v, v.Extra, true)
+			if err != nil {
+				return nil, err
+			}
+			entries[i] = entry
+		}
+	}
+	return marshalWithExtra(struct {
+		payloadFields
+		AvailableVariants []json.RawMessage `json:"availableVariants"`
+	}{payloadFields(p), entries}, p.Extra, false)
+}
+
+// UnmarshalJSON decodes a payload, collecting unknown fields of the payload
+// and of its variants into their Extra.
+func (p *AvailableVariantsPayload) UnmarshalJSON(data []byte) error {
+	var f payloadFields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	var raw struct {
+		AvailableVariants []json.RawMessage `json:"availableVariants"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for i, entry := range raw.AvailableVariants {
+		extra, err := unknownFields(entry, knownVariantFields)
+		if err != nil {
+			return err
+		}
+		f.AvailableVariants[i].Extra = extra
+	}
+	extra, err := unknownFields(data, knownPayloadFields)
+	if err != nil {
+		return err
+	}
+	f.Extra = extra
+	f.entries = nil
+	*p = AvailableVariantsPayload(f)
+	return nil
+}
+
+// ClientHintsPayload is the extension object a client declares under the
+// extension ID in the experimental capabilities of its initialize request.
+type ClientHintsPayload struct {
+	// VariantHints, if set, are the hints to rank the server's variants by.
+	VariantHints *VariantHints `json:"variantHints,omitempty"`
+
+	// VariantToken, if set, resumes the variant of an earlier session (see
+	// WithVariantTokens).
+	VariantToken string `json:"variantToken,omitempty"`
+}
+
+// UnmarshalJSON decodes a client payload leniently: malformed fields are
+// left zero rather than failing the whole payload. It fails only if data is
+// not a JSON object.
+func (p *ClientHintsPayload) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*p = ClientHintsPayload{}
+	if hints, ok := decodeVariantHints(fields["variantHints"]); ok {
+		p.VariantHints = &hints
+	}
+	_ = json.Unmarshal(fields["variantToken"], &p.VariantToken)
+	return nil
+}
+
+// clientExtension returns the extension object the client declared in an
+// initialize request, and reports whether it declared one.
+func clientExtension(req mcp.Request) (ClientHintsPayload, bool) {
+	params, _ := req.GetParams().(*mcp.InitializeParams)
+	if params == nil || params.Capabilities == nil {
+		return ClientHintsPayload{}, false
+	}
+	ext, ok := params.Capabilities.Experimental[extensionID]
+	if !ok {
+		return ClientHintsPayload{}, false
+	}
+	var p ClientHintsPayload
+	data, err := json.Marshal(ext)
+	if err != nil || json.Unmarshal(data, &p) != nil {
+		return ClientHintsPayload{}, false
+	}
+	return p, true
+}
+
+// parseVariantHints decodes a wire-format variantHints object, which may be
+// a decoded JSON value or a VariantHints. It reports false if v is not an
+// object.
+func parseVariantHints(v any) (VariantHints, bool) {
+	if v == nil {
+		return VariantHints{}, false
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return VariantHints{}, false
+	}
+	return decodeVariantHints(data)
+}
+
+// decodeVariantHints decodes a variantHints object leniently, ignoring
+// malformed fields. It reports false if data is not an object.
+func decodeVariantHints(data []byte) (VariantHints, bool) {
+	var fields map[string]json.RawMessage
+	if len(data) == 0 || json.Unmarshal(data, &fields) != nil || fields == nil {
+		return VariantHints{}, false
+	}
+	var hints VariantHints
+	if json.Unmarshal(fields["description"], &hints.Description) != nil {
+		hints.Description = ""
+	}
+	if json.Unmarshal(fields["hints"], &hints.Hints) != nil {
+		hints.Hints = nil
+	}
+	return hints, true
+}
+
+// marshalWithExtra encodes v, an object, with the fields of extra added.
+// If override is set, extra fields replace those of v; otherwise they are
+// only added where v has no such field.
+func marshalWithExtra(v any, extra map[string]any, override bool) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for k, x := range extra {
+		if _, exists := fields[k]; exists && !override {
+			continue
+		}
+		encoded, err := json.Marshal(x)
+		if err != nil {
+			return nil, err
+		}
+		fields[k] = encoded
+	}
+	return json.Marshal(fields)
+}
+
+// unknownFields decodes the fields of the object data whose names are not
+// known, or returns nil if there are none.
+func unknownFields(data []byte, known map[string]bool) (map[string]any, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	var extra map[string]any
+	for k, raw := range fields {
+		if known[k] {
+			continue
+		}
+		var x any
+		if err := json.Unmarshal(raw, &x); err != nil {
+			return nil, err
+		}
+		if extra == nil {
+			extra = make(map[string]any)
+		}
+		extra[k] = x
+	}
+	return extra, nil
+}
+
+// jsonFieldNames returns the JSON names of the encoded fields of struct
+// type t.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+		case "":
+			names[f.Name] = true
+		default:
+			names[name] = true
+		}
+	}
+	return names
+}
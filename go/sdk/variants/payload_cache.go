@@ -0,0 +1,213 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+)
+
+// payloadCache holds the JSON encodings of the static fields of
+// availableVariants entries, so that initialize responses and ranking
+// updates do not rebuild and re-marshal them for every session. Fields that
+// vary per request (score, match reason, availability, catalog counts,
+// ranking metadata, and templated descriptions) are spliced into the cached
+// encodings.
+//
+// Entries are dropped on catalog changes, and are only used for variants
+// whose static fields are the ones they were encoded from, since ranking
+// functions may return edited copies of the variants.
+type payloadCache struct {
+	mu      sync.RWMutex
+	entries map[string]*payloadEntry // by variant ID
+}
+
+// payloadEntry is the encoding of a variant's static fields.
+type payloadEntry struct {
+	source ServerVariant // the variant the entry was encoded from
+	strict bool          // whether it was encoded in strict mode
+	data   json.RawMessage
+}
+
+// invalidate drops all entries.
+func (c *payloadCache) invalidate() {
+	c.mu.Lock()
+	c.entries = nil
+	c.mu.Unlock()
+}
+
+// payloadBuffers pools the buffers entries are spliced in.
+var payloadBuffers = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// variantEntry returns the availableVariants entry of v, listed at index i
+// of a payload for the given hints; ranked reports whether v is ranked, as
+// opposed to listed as out of rotation.
+func (s *Server) variantEntry(ctx context.Context, hints VariantHints, v ServerVariant, i int, ranked bool) (json.RawMessage, error) {
+	static, err := s.staticEntry(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var dynamic []struct {
+		key   string
+		value any
+	}
+	add := func(key string, value any) {
+		if _, ok := v.Extra[key]; !ok {
+			dynamic = append(dynamic, struct {
+				key   string
+				value any
+			}{key, value})
+		}
+	}
+	if s.templateData != nil {
+		add("description", s.renderDescription(ctx, v.ID, v.Description))
+	}
+	if v.Score != 0 {
+		add("score", v.Score)
+	}
+	if v.MatchReason != "" {
+		add("matchReason", v.MatchReason)
+	}
+	if s.reportAvailability {
+		add("availability", s.availability(v.ID))
+	}
+	if counts, ok := s.CatalogCounts(v.ID); ok {
+		add("toolCount", counts.ToolCount)
+		add("promptCount", counts.PromptCount)
+		add("resourceCount", counts.ResourceCount)
+	}
+	if s.rankingMetadata && ranked {
+		add("ranking", rankingMetadata(hints, v, i))
+	}
+	if len(dynamic) == 0 {
+		return static, nil
+	}
+
+	buf := payloadBuffers.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		payloadBuffers.Put(buf)
+	}()
+	buf.Write(static[:len(static)-1]) // without the closing brace
+	for _, f := range dynamic {
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteByte(',')
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+// staticEntry returns the encoding of v's static fields, from the cache if
+// possible.
+func (s *Server) staticEntry(v ServerVariant) (json.RawMessage, error) {
+	c := &s.payloads
+	c.mu.RLock()
+	e := c.entries[v.ID]
+	c.mu.RUnlock()
+	if e != nil && e.strict == s.strict && sameStaticFields(e.source, v) {
+		return e.data, nil
+	}
+
+	entry := map[string]any{"id": v.ID}
+	if s.templateData == nil {
+		entry["description"] = v.Description
+	}
+	if v.Hints != nil {
+		entry["hints"] = v.Hints
+	} else if s.strict {
+		entry["hints"] = map[string]string{}
+	}
+	if len(v.Tags) > 0 {
+		entry["tags"] = v.Tags
+	}
+	if text := s.instructions[v.ID]; text != "" {
+		entry["instructions"] = text
+	}
+	if caps := s.VariantCapabilities(v.ID); caps != nil && s.reportCapabilities {
+		entry["capabilities"] = caps
+	}
+	if v.DocsURL != "" {
+		entry["docsUrl"] = v.DocsURL
+	}
+	if len(v.Icons) > 0 {
+		entry["icons"] = v.Icons
+	}
+	if v.Status != "" {
+		entry["status"] = v.Status
+	} else if s.strict {
+		entry["status"] = Stable
+	}
+	if v.DeprecationInfo != nil {
+		entry["deprecationInfo"] = v.DeprecationInfo
+	}
+	if v.MinimumProtocolVersion != "" {
+		entry["minimumProtocolVersion"] = v.MinimumProtocolVersion
+	}
+	for k, x := range v.Extra {
+		entry[k] = x
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]*payloadEntry, len(s.variants))
+	}
+	c.entries[v.ID] = &payloadEntry{source: v, strict: s.strict, data: data}
+	c.mu.Unlock()
+	return data, nil
+}
+
+// sameStaticFields reports whether a and b have the same static fields.
+// Maps, slices and pointers are compared by identity: variants are copied
+// by value, sharing them with the registered variant, and an edited copy
+// with new ones is re-encoded.
+func sameStaticFields(a, b ServerVariant) bool {
+	return a.ID == b.ID &&
+		a.Description == b.Description &&
+		a.DocsURL == b.DocsURL &&
+		a.Status == b.Status &&
+		a.DeprecationInfo == b.DeprecationInfo &&
+		a.MinimumProtocolVersion == b.MinimumProtocolVersion &&
+		sameReference(a.Hints, b.Hints) &&
+		sameReference(a.Extra, b.Extra) &&
+		len(a.Tags) == len(b.Tags) && (len(a.Tags) == 0 || &a.Tags[0] == &b.Tags[0]) &&
+		len(a.Icons) == len(b.Icons) && (len(a.Icons) == 0 || &a.Icons[0] == &b.Icons[0])
+}
+
+// sameReference reports whether two maps are the same map.
+func sameReference[M ~map[K]V, K comparable, V any](a, b M) bool {
+	return reflect.ValueOf(a).UnsafePointer() == reflect.ValueOf(b).UnsafePointer()
+}
+
+// availableVariantsPayload returns the availableVariants entries of listed,
+// of which the first len(ranked) are ranked.
+func (s *Server) availableVariantsPayload(ctx context.Context, hints VariantHints, listed, ranked []ServerVariant) ([]json.RawMessage, error) {
+	out := make([]json.RawMessage, len(listed))
+	for i, v := range listed {
+		entry, err := s.variantEntry(ctx, hints, v, i, i < len(ranked))
+		if err != nil {
+			return nil, err
+		}
+		out[i] = entry
+	}
+	return out, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type payloadEntries struct {
+	AvailableVariants []map[string]any `json:"availableVariants"`
+}
+
+// serveHTTP serves vs over streamable HTTP for the duration of the test.
+func serveHTTP(t *testing.T, vs *Server) *httptest.Server {
+	httpSrv := httptest.NewServer(NewStreamableHTTPHandler(vs, nil))
+	t.Cleanup(httpSrv.Close)
+	return httpSrv
+}
+
+func TestPayloadCache_Invalidation(t *testing.T) {
+	vs := newTestVariantServer()
+	httpSrv := serveHTTP(t, vs)
+	var p payloadEntries
+	initExtension(t, connectHTTPTestClient(t, httpSrv), &p)
+	require.Len(t, p.AvailableVariants, 2)
+	assert.Equal(t, string(Experimental), p.AvailableVariants[1]["status"])
+
+	require.NoError(t, vs.SetVariantStatus("compact", Deprecated))
+	initExtension(t, connectHTTPTestClient(t, httpSrv), &p)
+	require.Len(t, p.AvailableVariants, 2)
+	assert.Equal(t, string(Deprecated), p.AvailableVariants[1]["status"])
+}
+
+func TestPayloadCache_EditedVariants(t *testing.T) {
+	var edit atomic.Bool
+	vs := newTestVariantServer().
+		WithRanking(func(_ context.Context, hints VariantHints, vs []ServerVariant) []ServerVariant {
+			if edit.Load() {
+				vs[0].Description = "edited"
+				vs[0].Hints = map[string]string{"edited": "true"}
+				vs[0].Score = 0.5
+				vs[0].Extra = map[string]any{"score": "overridden"}
+			}
+			return vs
+		})
+	httpSrv := serveHTTP(t, vs)
+	var p payloadEntries
+	initExtension(t, connectHTTPTestClient(t, httpSrv), &p)
+	assert.Equal(t, "Optimized for coding workflows", p.AvailableVariants[0]["description"])
+
+	edit.Store(true)
+	initExtension(t, connectHTTPTestClient(t, httpSrv), &p)
+	assert.Equal(t, map[string]any{
+		"id":          "coding",
+		"description": "edited",
+		"hints":       map[string]any{"edited": "true"},
+		"status":      string(Stable),
+		"score":       "overridden",
+	}, p.AvailableVariants[0], "entries of edited variants are re-encoded; Extra wins over spliced fields")
+}
+
+func BenchmarkAvailableVariantsPayload(b *testing.B) {
+	vs := NewServer(&mcp.Implementation{Name: "bench", Version: "v0.0.1"})
+	for i := range 100 {
+		vs.WithVariant(ServerVariant{
+			ID:          fmt.Sprintf("v%d", i),
+			Description: "A synthetic variant",
+			Hints:       map[string]string{HintModelFamily: "family", HintContextSize: "compact"},
+			Tags:        []string{"bench"},
+			Status:      Stable,
+		}, mcp.NewServer(&mcp.Implementation{Name: "inner", Version: "v0.0.1"}, nil), i)
+	}
+	ctx := context.Background()
+	ranked := vs.RankedVariants(ctx, VariantHints{})
+
+	for _, cached := range []bool{false, true} {
+		b.Run(fmt.Sprintf("cached=%v", cached), func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				if !cached {
+					vs.payloads.invalidate()
+				}
+				entries, err := vs.availableVariantsPayload(ctx, VariantHints{}, ranked, ranked)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := json.Marshal(entries); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
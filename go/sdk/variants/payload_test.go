@@ -0,0 +1,84 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAvailableVariantsPayload_RoundTrip(t *testing.T) {
+	vs := newTestVariantServer().
+		WithRankingMetadata(true).
+		WithVariantGroup(VariantGroup{ID: "g", Exclusive: true, Variants: []string{"coding", "compact"}})
+	session := connectTestClient(t, vs, hintsClientOptions(map[string]any{HintContextSize: "compact"}))
+	wire, err := json.Marshal(session.InitializeResult().Capabilities.Experimental[extensionID])
+	require.NoError(t, err)
+
+	var p AvailableVariantsPayload
+	require.NoError(t, json.Unmarshal(wire, &p))
+	require.Len(t, p.AvailableVariants, 2)
+	coding := p.AvailableVariants[0]
+	assert.Equal(t, "coding", coding.ID)
+	assert.Equal(t, Stable, coding.Status)
+	assert.Equal(t, map[string]any{"index": 0.0, "hintMatched": false}, coding.Extra["ranking"])
+	assert.Equal(t, "coding", p.DefaultVariant)
+	require.NotNil(t, p.NormalizedHints)
+	assert.Equal(t, map[string]any{HintContextSize: "compact"}, p.NormalizedHints.Hints)
+	assert.Equal(t, []VariantGroup{{ID: "g", Exclusive: true, Variants: []string{"coding", "compact"}}}, p.VariantGroups)
+	assert.Nil(t, p.Extra)
+
+	again, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(wire), string(again))
+}
+
+func TestAvailableVariantsPayload_Extra(t *testing.T) {
+	p := AvailableVariantsPayload{
+		AvailableVariants: []ServerVariant{{ID: "a", Extra: map[string]any{"id": "overridden", "tier": "pro"}}},
+		DefaultVariant:    "a",
+		Extra:             map[string]any{"defaultVariant": "ignored", "com.example/x": 1},
+	}
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"availableVariants": [{"id": "overridden", "description": "", "tier": "pro"}],
+		"moreVariantsAvailable": false,
+		"defaultVariant": "a",
+		"recommendedVariant": "",
+		"com.example/x": 1
+	}`, string(data))
+
+	var back AvailableVariantsPayload
+	require.NoError(t, json.Unmarshal(data, &back))
+	assert.Equal(t, map[string]any{"com.example/x": 1.0}, back.Extra)
+	assert.Equal(t, map[string]any{"tier": "pro"}, back.AvailableVariants[0].Extra)
+}
+
+func TestClientHintsPayload(t *testing.T) {
+	var p ClientHintsPayload
+	require.NoError(t, json.Unmarshal([]byte(`{"variantHints": {"description": 7, "hints": {"contextSize": "compact"}}, "variantToken": "tok"}`), &p))
+	require.NotNil(t, p.VariantHints)
+	assert.Equal(t, VariantHints{Hints: map[string]any{HintContextSize: "compact"}}, *p.VariantHints, "malformed fields are dropped")
+	assert.Equal(t, "tok", p.VariantToken)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"variantHints": "x"}`), &p))
+	assert.Equal(t, ClientHintsPayload{}, p)
+	assert.Error(t, json.Unmarshal([]byte(`[]`), &p))
+}
+
+func TestExtractVariantHints_TypedExtension(t *testing.T) {
+	hints := VariantHints{Description: "agent", Hints: map[string]any{HintContextSize: "compact"}}
+	req := &mcp.InitializeRequest{Params: &mcp.InitializeParams{
+		Capabilities: &mcp.ClientCapabilities{Experimental: map[string]any{
+			extensionID: ClientHintsPayload{VariantHints: &hints},
+		}},
+	}}
+	assert.Equal(t, hints, extractVariantHints(req))
+}
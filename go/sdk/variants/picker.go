@@ -0,0 +1,172 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// A VariantPicker chooses the variant a client session uses among the
+// candidates the server advertises, in the server's ranking order. It
+// returns the chosen variant's ID, or "" to follow the server's default.
+//
+// Pickers are set with ClientOptions.Picker or applied with
+// ClientSession.Pick.
+type VariantPicker func(ctx context.Context, cs *ClientSession, candidates []ServerVariant) (string, error)
+
+// Pick chooses a variant with picker among the advertised variants and
+// selects it (see SelectVariant). If picker returns "", the selection is
+// left unchanged.
+func (s *ClientSession) Pick(ctx context.Context, picker VariantPicker) error {
+	id, err := picker(ctx, s, s.Variants())
+	if err != nil || id == "" {
+		return err
+	}
+	return s.SelectVariant(ctx, id)
+}
+
+// PreferStable picks the best-ranked stable variant, or else the
+// best-ranked experimental one, avoiding deprecated variants. Variants
+// without a status are stable.
+func PreferStable() VariantPicker {
+	return func(_ context.Context, _ *ClientSession, candidates []ServerVariant) (string, error) {
+		for _, status := range []VariantStatus{Stable, Experimental} {
+			for _, v := range candidates {
+				if v.Status == status || (status == Stable && v.Status == "") {
+					return v.ID, nil
+				}
+			}
+		}
+		return "", nil
+	}
+}
+
+// PreferMatchingHints picks the variant whose Hints match most of the
+// given hints, weighting earlier (more preferred) values of a hint higher.
+// Ties go to the better-ranked variant; if no variant matches any hint,
+// the server's default is kept.
+func PreferMatchingHints(hints VariantHints) VariantPicker {
+	return func(_ context.Context, _ *ClientSession, candidates []ServerVariant) (string, error) {
+		best, bestScore := "", 0.0
+		for _, v := range candidates {
+			if score := hintMatchScore(hints, v); score > bestScore {
+				best, bestScore = v.ID, score
+			}
+		}
+		return best, nil
+	}
+}
+
+// hintMatchScore scores how well a variant matches client hints: each
+// matching hint adds 1/(i+1) for the i-th preferred value it matches.
+func hintMatchScore(hints VariantHints, v ServerVariant) float64 {
+	var score float64
+	for key, value := range hints.Hints {
+		have, ok := v.Hints[key]
+		if !ok {
+			continue
+		}
+		if i := slices.Index(flattenHintValues(nil, value), have); i >= 0 {
+			score += 1 / float64(i+1)
+		}
+	}
+	return score
+}
+
+// TokenEstimator estimates the tokens a variant's catalog costs in a
+// model's context.
+type TokenEstimator func(ctx context.Context, cs *ClientSession, variantID string) (int, error)
+
+// PreferLowestTokenCost picks the variant with the lowest estimated token
+// cost. Ties go to the better-ranked variant. A nil estimate uses
+// EstimateToolTokens.
+func PreferLowestTokenCost(estimate TokenEstimator) VariantPicker {
+	if estimate == nil {
+		estimate = EstimateToolTokens
+	}
+	return func(ctx context.Context, cs *ClientSession, candidates []ServerVariant) (string, error) {
+		best, bestCost := "", 0
+		for _, v := range candidates {
+			cost, err := estimate(ctx, cs, v.ID)
+			if err != nil {
+				return "", fmt.Errorf("variants: estimating tokens of variant %q: %w", v.ID, err)
+			}
+			if best == "" || cost < bestCost {
+				best, bestCost = v.ID, cost
+			}
+		}
+		return best, nil
+	}
+}
+
+// EstimateToolTokens estimates the tokens of a variant's tool definitions
+// as a quarter of their JSON size, a common rule of thumb for English text
+// and JSON. The tools are listed from the server.
+func EstimateToolTokens(ctx context.Context, cs *ClientSession, variantID string) (int, error) {
+	size := 0
+	for tool, err := range cs.ClientSession.Tools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: variantID}}) {
+		if err != nil {
+			return 0, err
+		}
+		data, err := json.Marshal(tool)
+		if err != nil {
+			return 0, err
+		}
+		size += len(data)
+	}
+	return (size + 3) / 4, nil
+}
+
+// InteractivePrompt asks a user to pick a variant: it writes the candidates
+// as a numbered list to out and reads the chosen number or ID from a line
+// of in. An empty line keeps the server's default. It asks again after an
+// invalid choice, and fails if in ends.
+func InteractivePrompt(in io.Reader, out io.Writer) VariantPicker {
+	return func(_ context.Context, cs *ClientSession, candidates []ServerVariant) (string, error) {
+		if len(candidates) == 0 {
+			return "", nil
+		}
+		for i, v := range candidates {
+			line := fmt.Sprintf("%d) %s", i+1, v.ID)
+			if v.Description != "" {
+				line += ": " + v.Description
+			}
+			if v.Status != "" && v.Status != Stable {
+				line += " [" + string(v.Status) + "]"
+			}
+			fmt.Fprintln(out, line)
+		}
+		lines := bufio.NewScanner(in)
+		for {
+			fmt.Fprintf(out, "Variant [default %s]: ", cs.DefaultVariant())
+			if !lines.Scan() {
+				if err := lines.Err(); err != nil {
+					return "", err
+				}
+				return "", io.ErrUnexpectedEOF
+			}
+			choice := strings.TrimSpace(lines.Text())
+			if choice == "" {
+				return "", nil
+			}
+			if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(candidates) {
+				return candidates[n-1].ID, nil
+			}
+			if slices.ContainsFunc(candidates, func(v ServerVariant) bool { return v.ID == choice }) {
+				return choice, nil
+			}
+			fmt.Fprintf(out, "Unknown variant %q.\n", choice)
+		}
+	}
+}
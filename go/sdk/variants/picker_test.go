@@ -0,0 +1,88 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferStable(t *testing.T) {
+	pick := PreferStable()
+	ctx := context.Background()
+
+	id, err := pick(ctx, nil, []ServerVariant{{ID: "beta", Status: Experimental}, {ID: "old", Status: Deprecated}, {ID: "ga"}})
+	require.NoError(t, err)
+	assert.Equal(t, "ga", id)
+
+	id, err = pick(ctx, nil, []ServerVariant{{ID: "old", Status: Deprecated}, {ID: "beta", Status: Experimental}})
+	require.NoError(t, err)
+	assert.Equal(t, "beta", id)
+
+	id, err = pick(ctx, nil, []ServerVariant{{ID: "old", Status: Deprecated}})
+	require.NoError(t, err)
+	assert.Empty(t, id)
+}
+
+func TestPreferMatchingHints(t *testing.T) {
+	candidates := []ServerVariant{
+		{ID: "generic"},
+		{ID: "openai", Hints: map[string]string{HintModelFamily: "openai", HintContextSize: "standard"}},
+		{ID: "claude", Hints: map[string]string{HintModelFamily: "anthropic", HintContextSize: "verbose"}},
+	}
+	pick := PreferMatchingHints(VariantHints{Hints: map[string]any{
+		HintModelFamily: []string{"anthropic", "openai"},
+		HintContextSize: "standard",
+	}})
+	id, err := pick(context.Background(), nil, candidates)
+	require.NoError(t, err)
+	// openai matches the second model family and the context size (1.5),
+	// claude only the preferred model family (1).
+	assert.Equal(t, "openai", id)
+
+	id, err = PreferMatchingHints(VariantHints{Hints: map[string]any{HintUseCase: "ide"}})(context.Background(), nil, candidates)
+	require.NoError(t, err)
+	assert.Empty(t, id)
+}
+
+func TestPreferLowestTokenCost(t *testing.T) {
+	session := connectVariantsClient(t, newTestVariantServer(), &ClientOptions{
+		Picker: PreferLowestTokenCost(func(_ context.Context, _ *ClientSession, id string) (int, error) {
+			return map[string]int{"coding": 900, "compact": 200}[id], nil
+		}),
+	})
+	assert.Equal(t, "compact", session.ActiveVariant())
+
+	coding, err := EstimateToolTokens(context.Background(), session, "coding")
+	require.NoError(t, err)
+	assert.Positive(t, coding)
+}
+
+func TestInteractivePrompt(t *testing.T) {
+	session := connectVariantsClient(t, newTestVariantServer(), nil)
+	ctx := context.Background()
+	var out strings.Builder
+
+	require.NoError(t, session.Pick(ctx, InteractivePrompt(strings.NewReader("7\ncompact\n"), &out)))
+	assert.Equal(t, "compact", session.ActiveVariant())
+	assert.Equal(t, "1) coding: Optimized for coding workflows\n"+
+		"2) compact: Minimal token usage [experimental]\n"+
+		"Variant [default coding]: Unknown variant \"7\".\n"+
+		"Variant [default coding]: ", out.String())
+
+	require.NoError(t, session.Pick(ctx, InteractivePrompt(strings.NewReader("1\n"), io.Discard)))
+	assert.Equal(t, "coding", session.ActiveVariant())
+
+	// An empty answer keeps the selection.
+	require.NoError(t, session.Pick(ctx, InteractivePrompt(strings.NewReader("\n"), io.Discard)))
+	assert.Equal(t, "coding", session.ActiveVariant())
+
+	assert.ErrorIs(t, session.Pick(ctx, InteractivePrompt(strings.NewReader(""), io.Discard)), io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,173 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// CodeVariantOverloaded is the JSON-RPC error code returned when a request
+// cannot be queued because a variant's stateless connection pool is
+// saturated (see [PoolOptions]). Clients may retry later.
+const CodeVariantOverloaded int64 = -32051
+
+// PoolOptions configure the connections to each variant that are shared by
+// all requests in stateless mode.
+type PoolOptions struct {
+	// Size is the number of shared connections per variant. Requests are
+	// balanced across them, least busy first. Values below 1 mean 1.
+	Size int
+
+	// MaxInFlight limits the number of concurrent requests per connection.
+	// Zero means unlimited, in which case MaxQueue has no effect.
+	MaxInFlight int
+
+	// MaxQueue limits the number of requests per variant waiting for a free
+	// connection when all are at MaxInFlight. Requests beyond the limit fail
+	// immediately with a [CodeVariantOverloaded] error. Zero means requests
+	// never wait.
+	MaxQueue int
+}
+
+// PoolStats reports the load on a variant's stateless connection pool.
+type PoolStats struct {
+	Connections int // shared connections to the variant
+	InFlight    int // requests being handled
+	Queued      int // requests waiting for a free connection
+}
+
+// WithStatelessPool configures the connections shared by all requests in
+// stateless mode (see [NewStreamableHTTPHandler]). By default each variant
+// has a single shared connection with no concurrency limit. Limiting
+// concurrency bounds the load each variant sees, with a queue absorbing
+// bursts and overflow errors pushing back on clients. It has no effect in
+// stateful mode, where each session has its own connections.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithStatelessPool(opts PoolOptions) *Server {
+	if opts.Size < 1 {
+		opts.Size = 1
+	}
+	s.poolOpts = opts
+	return s
+}
+
+// PoolStats returns the current load on the variant's stateless connection
+// pool. It reports false unless the server is serving in stateless mode with
+// a pool configured by WithStatelessPool, or if the variant is unknown. If
+// several stateless routers serve the server, the first one's pool is
+// reported.
+func (s *Server) PoolStats(variantID string) (PoolStats, bool) {
+	for _, r := range s.routerList() {
+		if r.shared == nil {
+			continue
+		}
+		if p := r.shared.dispatcher.pools[variantID]; p != nil {
+			return p.stats(), true
+		}
+	}
+	return PoolStats{}, false
+}
+
+// connPool balances requests for one variant across its shared connections.
+type connPool struct {
+	variantID string
+	maxQueue  int64
+	slots     chan struct{} // nil if concurrency is unlimited
+	queued    atomic.Int64
+
+	mu       sync.Mutex
+	conns    []*innerConnection
+	inFlight []int
+}
+
+func newConnPool(variantID string, conns []*innerConnection, opts PoolOptions) *connPool {
+	p := &connPool{
+		variantID: variantID,
+		maxQueue:  int64(opts.MaxQueue),
+		conns:     conns,
+		inFlight:  make([]int, len(conns)),
+	}
+	if opts.MaxInFlight > 0 {
+		p.slots = make(chan struct{}, opts.MaxInFlight*len(conns))
+	}
+	return p
+}
+
+// acquire returns the least busy connection, waiting for capacity if the
+// pool is saturated and the queue has room. The caller must call release
+// when the request completes.
+func (p *connPool) acquire(ctx context.Context) (conn *innerConnection, release func(), err error) {
+	if p.slots != nil {
+		select {
+		case p.slots <- struct{}{}:
+		default:
+			if p.queued.Add(1) > p.maxQueue {
+				p.queued.Add(-1)
+				return nil, nil, p.overloadedError()
+			}
+			select {
+			case p.slots <- struct{}{}:
+				p.queued.Add(-1)
+			case <-ctx.Done():
+				p.queued.Add(-1)
+				return nil, nil, ctx.Err()
+			}
+		}
+	}
+
+	p.mu.Lock()
+	idx := 0
+	for i, n := range p.inFlight {
+		if n < p.inFlight[idx] {
+			idx = i
+		}
+	}
+	p.inFlight[idx]++
+	p.mu.Unlock()
+
+	return p.conns[idx], func() {
+		p.mu.Lock()
+		p.inFlight[idx]--
+		p.mu.Unlock()
+		if p.slots != nil {
+			<-p.slots
+		}
+	}, nil
+}
+
+func (p *connPool) stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := PoolStats{Connections: len(p.conns), Queued: int(p.queued.Load())}
+	for _, n := range p.inFlight {
+		st.InFlight += n
+	}
+	return st
+}
+
+func (p *connPool) close() {
+	// conns[0] is also the dispatcher's primary connection, closed with it.
+	for _, c := range p.conns[1:] {
+		c.close()
+	}
+}
+
+func (p *connPool) overloadedError() error {
+	dataJSON, _ := json.Marshal(map[string]any{
+		"requestedVariant": p.variantID,
+		"retriable":        true,
+	})
+	return &jsonrpc.Error{
+		Code:    CodeVariantOverloaded,
+		Message: "Server variant overloaded",
+		Data:    json.RawMessage(dataJSON),
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatelessPool(t *testing.T) {
+	started := make(chan *mcp.ServerSession, 3)
+	unblock := make(chan struct{})
+	inner := mcp.NewServer(&mcp.Implementation{Name: "inner", Version: "v1.0.0"}, nil)
+	mcp.AddTool(inner, &mcp.Tool{Name: "wait"}, func(_ context.Context, req *mcp.CallToolRequest, _ emptyInput) (*mcp.CallToolResult, any, error) {
+		started <- req.Session
+		<-unblock
+		return &mcp.CallToolResult{}, nil, nil
+	})
+
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "v1"}, inner, 0).
+		WithStatelessPool(PoolOptions{Size: 2, MaxInFlight: 1, MaxQueue: 1})
+	t.Cleanup(func() { vs.Close() })
+	r, err := vs.NewRouter(&RouterOptions{Stateless: true})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	call := func() error {
+		_, err := r.Call(ctx, "tools/call", &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: "wait", Arguments: json.RawMessage(`{}`)},
+		})
+		return err
+	}
+
+	// Two calls occupy both connections, a third is queued.
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- call()
+		}()
+	}
+	first, second := <-started, <-started
+	assert.NotSame(t, first, second, "calls should be balanced across connections")
+	require.Eventually(t, func() bool {
+		st, _ := vs.PoolStats("v1")
+		return st.Queued == 1
+	}, time.Second, time.Millisecond)
+	st, ok := vs.PoolStats("v1")
+	require.True(t, ok)
+	assert.Equal(t, PoolStats{Connections: 2, InFlight: 2, Queued: 1}, st)
+
+	// The queue is full: further calls are rejected.
+	err = call()
+	var rpcErr *jsonrpc.Error
+	require.ErrorAs(t, err, &rpcErr)
+	assert.Equal(t, CodeVariantOverloaded, rpcErr.Code)
+
+	close(unblock)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	st, _ = vs.PoolStats("v1")
+	assert.Equal(t, PoolStats{Connections: 2}, st)
+}
+
+func TestPoolStats_NoPool(t *testing.T) {
+	vs := newTestVariantServer()
+	t.Cleanup(func() { vs.Close() })
+	_, err := vs.NewRouter(&RouterOptions{Stateless: true})
+	require.NoError(t, err)
+	_, ok := vs.PoolStats("coding")
+	assert.False(t, ok)
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"runtime/pprof"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Keys of the profiling labels set with WithProfilingLabels.
+const (
+	// ProfileLabelVariant is the ID of the variant a request is
+	// dispatched to.
+	ProfileLabelVariant = "variant"
+	// ProfileLabelMethod is the MCP method of the request, e.g.
+	// "tools/call".
+	ProfileLabelMethod = "method"
+)
+
+// WithProfilingLabels enables or disables pprof labels on dispatches to
+// variants. When enabled, each dispatch, including its interceptors (see
+// WithDispatchInterceptor), runs with the labels ProfileLabelVariant and
+// ProfileLabelMethod, so that profiles of a busy server attribute CPU time
+// to the variants' servers:
+//
+//	go tool pprof -tagfocus=variant=compact http://localhost:6060/debug/pprof/profile
+//
+// Labels are inherited by goroutines the variant's handlers start. They
+// are recorded in CPU and goroutine profiles; Go's heap profiles do not
+// record labels, so allocations are best attributed by focusing on the
+// variants' handler functions instead. In-memory variants run in the
+// server's process and are profiled in full; for remote variants, only
+// the proxying is. Disabled by default.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithProfilingLabels(enabled bool) *Server {
+	s.profilingLabels = enabled
+	return s
+}
+
+// profile calls h under the profiling labels of a dispatch, if enabled.
+func (s *Server) profile(ctx context.Context, variantID, method string, h DispatchHandler) (res mcp.Result, err error) {
+	if !s.profilingLabels {
+		return h(ctx)
+	}
+	pprof.Do(ctx, pprof.Labels(ProfileLabelVariant, variantID, ProfileLabelMethod, method), func(ctx context.Context) {
+		res, err = h(ctx)
+	})
+	return res, err
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLabelsVariantServer returns a server with a variant whose tool
+// reports the profiling labels it runs with.
+func newLabelsVariantServer() *Server {
+	inner := mcp.NewServer(&mcp.Implementation{Name: "labels", Version: "v1.0.0"}, nil)
+	mcp.AddTool(inner, &mcp.Tool{Name: "labels"}, func(ctx context.Context, _ *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, map[string]string, error) {
+		labels := map[string]string{}
+		pprof.ForLabels(ctx, func(k, v string) bool {
+			labels[k] = v
+			return true
+		})
+		return nil, labels, nil
+	})
+	return NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "hot", Description: "Hot path"}, inner, 0)
+}
+
+func TestProfilingLabels(t *testing.T) {
+	var intercepted map[string]string
+	vs := newLabelsVariantServer().
+		WithProfilingLabels(true).
+		WithDispatchInterceptor(func(ctx context.Context, info DispatchInfo, next DispatchHandler) (mcp.Result, error) {
+			intercepted = map[string]string{}
+			pprof.ForLabels(ctx, func(k, v string) bool {
+				intercepted[k] = v
+				return true
+			})
+			return next(ctx)
+		})
+	session := connectTestClient(t, vs, nil)
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "labels"})
+	require.NoError(t, err)
+	want := map[string]any{ProfileLabelVariant: "hot", ProfileLabelMethod: "tools/call"}
+	assert.Equal(t, want, res.StructuredContent)
+	assert.Equal(t, map[string]string{ProfileLabelVariant: "hot", ProfileLabelMethod: "tools/call"}, intercepted, "interceptors run under the labels")
+}
+
+func TestProfilingLabels_Disabled(t *testing.T) {
+	session := connectTestClient(t, newLabelsVariantServer(), nil)
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "labels"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{}, res.StructuredContent)
+}
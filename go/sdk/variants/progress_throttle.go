@@ -0,0 +1,193 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// WithProgressThrottle coalesces the progress notifications variants send
+// for a request, forwarding at most one per interval to the client, e.g.
+// 100*time.Millisecond for 10 per second. This reduces transport overhead
+// when tools send thousands of progress updates. Of the notifications
+// within an interval, only the latest is kept; unless a later one is
+// forwarded first, it is forwarded when the request completes, so the
+// client sees the latest progress before the response. Notifications
+// reporting completion (Progress >= Total) are always forwarded. Other
+// notifications arriving after the response, as those of remote variants
+// may, are dropped. Zero, the default, forwards all notifications. Panics
+// if interval is negative.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithProgressThrottle(interval time.Duration) *Server {
+	if interval < 0 {
+		panic("variants: negative progress throttle interval")
+	}
+	if interval == 0 {
+		s.progressThrottle = nil
+		return s
+	}
+	s.progressThrottle = &progressThrottle{interval: interval, streams: make(map[progressKey]*progressStream)}
+	return s
+}
+
+// progressTombstoneTTL is how long the throttle remembers a completed
+// request, dropping its late notifications.
+const progressTombstoneTTL = time.Minute
+
+// progressKey identifies the progress notifications of a request. Sessions
+// are keyed by pointer, since in-memory and stdio sessions have no ID.
+type progressKey struct {
+	session *mcp.ServerSession
+	token   any // string or float64, see progressTokenKey
+}
+
+// progressStream is the throttling state of a request.
+type progressStream struct {
+	last     time.Time // when a notification was last forwarded
+	pending  func()    // forwards the latest notification not forwarded, if any
+	finished bool      // the request completed
+}
+
+// progressTombstone records when a request completed.
+type progressTombstone struct {
+	key    progressKey
+	stream *progressStream
+	at     time.Time
+}
+
+// progressThrottle implements WithProgressThrottle.
+type progressThrottle struct {
+	interval time.Duration
+
+	mu         sync.Mutex
+	streams    map[progressKey]*progressStream
+	tombstones []progressTombstone // in completion order
+}
+
+// progressTokenKey returns the map key of a progress token, and false if
+// the token is neither a string nor a number. Numbers decoded from JSON
+// are float64, so integers are converted to match them.
+func progressTokenKey(token any) (any, bool) {
+	switch t := token.(type) {
+	case string, float64:
+		return t, true
+	case int:
+		return float64(t), true
+	case int32:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	}
+	return nil, false
+}
+
+// start forgets a completed request of a session with the same progress
+// token as a new one, so that the token can be reused.
+func (p *progressThrottle) start(session *mcp.ServerSession, token any) {
+	tok, ok := progressTokenKey(token)
+	if !ok {
+		return
+	}
+	key := progressKey{session, tok}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if st := p.streams[key]; st != nil && st.finished {
+		delete(p.streams, key)
+	}
+}
+
+// forward forwards a progress notification of a request of a session with
+// send, or keeps send pending if the request's last notification was
+// forwarded less than the interval ago. Notifications of completed
+// requests are dropped unless they report completion.
+func (p *progressThrottle) forward(session *mcp.ServerSession, params *mcp.ProgressNotificationParams, send func()) {
+	tok, ok := progressTokenKey(params.ProgressToken)
+	if !ok {
+		send()
+		return
+	}
+	key := progressKey{session, tok}
+	now := time.Now()
+	done := params.Total > 0 && params.Progress >= params.Total
+	p.mu.Lock()
+	st := p.streams[key]
+	if st == nil {
+		st = &progressStream{}
+		p.streams[key] = st
+	}
+	if st.finished && !done {
+		p.mu.Unlock()
+		return
+	}
+	if !done && !st.last.IsZero() && now.Sub(st.last) < p.interval {
+		st.pending = send
+		p.mu.Unlock()
+		return
+	}
+	st.last = now
+	st.pending = nil
+	p.mu.Unlock()
+	send()
+}
+
+// finish forwards the pending notification of a completed request, if
+// any, and marks the request completed. Completed requests are forgotten
+// after progressTombstoneTTL.
+func (p *progressThrottle) finish(session *mcp.ServerSession, token any) {
+	tok, ok := progressTokenKey(token)
+	if !ok {
+		return
+	}
+	key := progressKey{session, tok}
+	now := time.Now()
+	p.mu.Lock()
+	st := p.streams[key]
+	if st == nil {
+		st = &progressStream{}
+		p.streams[key] = st
+	}
+	pending := st.pending
+	st.pending = nil
+	st.finished = true
+	p.tombstones = append(p.tombstones, progressTombstone{key, st, now})
+	for len(p.tombstones) > 0 && now.Sub(p.tombstones[0].at) >= progressTombstoneTTL {
+		t := p.tombstones[0]
+		p.tombstones = p.tombstones[1:]
+		if p.streams[t.key] == t.stream {
+			delete(p.streams, t.key)
+		}
+	}
+	p.mu.Unlock()
+	if pending != nil {
+		pending()
+	}
+}
+
+// dropSession forgets the requests of a session.
+func (p *progressThrottle) dropSession(ss *mcp.ServerSession) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for k := range p.streams {
+		if k.session == ss {
+			delete(p.streams, k)
+		}
+	}
+	p.tombstones = slices.DeleteFunc(p.tombstones, func(t progressTombstone) bool { return t.key.session == ss })
+}
+
+// progressToken returns the progress token of a request, or nil if it has
+// none.
+func progressToken(req mcp.Request) any {
+	params, ok := req.GetParams().(mcp.RequestParams)
+	if !ok || isNilInterface(params) {
+		return nil
+	}
+	return params.GetProgressToken()
+}
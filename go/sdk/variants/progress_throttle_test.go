@@ -0,0 +1,162 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tickInput struct {
+	Count      int  `json:"count"`
+	KnownTotal bool `json:"knownTotal"`
+}
+
+// newTickServer returns a server whose tick tool sends count progress
+// notifications, with the total if knownTotal is set.
+func newTickServer() *mcp.Server {
+	srv := mcp.NewServer(&mcp.Implementation{Name: "ticks", Version: "v1.0.0"}, nil)
+	mcp.AddTool(srv, &mcp.Tool{Name: "tick"}, func(ctx context.Context, req *mcp.CallToolRequest, in tickInput) (*mcp.CallToolResult, any, error) {
+		for i := 1; i <= in.Count; i++ {
+			p := &mcp.ProgressNotificationParams{ProgressToken: req.Params.GetProgressToken(), Progress: float64(i)}
+			if in.KnownTotal {
+				p.Total = float64(in.Count)
+			}
+			_ = req.Session.NotifyProgress(ctx, p)
+		}
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	return srv
+}
+
+// progressValues calls the tick tool and returns the progress values the
+// client received once the last one arrived.
+func progressValues(t *testing.T, vs *Server, count int, knownTotal bool) []float64 {
+	t.Helper()
+	collector := &notificationCollector{}
+	session := connectTestClient(t, vs, collector.clientOptions())
+	_, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Meta:      mcp.Meta{"progressToken": "tok"},
+		Name:      "tick",
+		Arguments: map[string]any{"count": count, "knownTotal": knownTotal},
+	})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		collector.mu.Lock()
+		defer collector.mu.Unlock()
+		n := len(collector.progress)
+		return n > 0 && collector.progress[n-1].Progress == float64(count)
+	}, 2*time.Second, 10*time.Millisecond)
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	var values []float64
+	for _, p := range collector.progress {
+		assert.Equal(t, "tok", p.ProgressToken)
+		values = append(values, p.Progress)
+	}
+	return values
+}
+
+func TestWithProgressThrottle(t *testing.T) {
+	newServer := func() *Server {
+		return NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+			WithVariant(ServerVariant{ID: "ticks", Description: "Ticks"}, newTickServer(), 0).
+			WithProgressThrottle(time.Hour)
+	}
+	assert.Equal(t, []float64{1, 500}, progressValues(t, newServer(), 500, false), "latest flushed at completion")
+	assert.Equal(t, []float64{1, 500}, progressValues(t, newServer(), 500, true), "completion forwarded")
+
+	unthrottled := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "ticks", Description: "Ticks"}, newTickServer(), 0)
+	assert.Len(t, progressValues(t, unthrottled, 50, false), 50)
+}
+
+func TestWithProgressThrottle_Remote(t *testing.T) {
+	endpoint, _ := serveRemote(t, newTickServer())
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithRemoteVariant(ServerVariant{ID: "ticks", Description: "Ticks"}, endpoint, 0).
+		WithProgressThrottle(time.Hour)
+	values := progressValues(t, vs, 200, true)
+	assert.LessOrEqual(t, len(values), 3, "notifications arriving after the response are dropped")
+	assert.Equal(t, float64(200), values[len(values)-1], "completion is forwarded")
+}
+
+func TestProgressThrottle(t *testing.T) {
+	p := &progressThrottle{interval: time.Hour, streams: make(map[progressKey]*progressStream)}
+	s, other := &mcp.ServerSession{}, &mcp.ServerSession{}
+	var sent []string
+	forward := func(session *mcp.ServerSession, token any, label string) {
+		p.forward(session, &mcp.ProgressNotificationParams{ProgressToken: token}, func() { sent = append(sent, label) })
+	}
+	forward(s, "a", "a1")
+	forward(s, "a", "a2")
+	forward(s, "a", "a3")
+	forward(s, 7, "b1")
+	forward(other, "a", "c1")
+	assert.Equal(t, []string{"a1", "b1", "c1"}, sent)
+
+	p.finish(s, "a")
+	assert.Equal(t, []string{"a1", "b1", "c1", "a3"}, sent)
+	p.finish(s, 7)
+	assert.Len(t, sent, 4, "nothing pending")
+
+	// Tokens are matched by value and type.
+	forward(s, "7", "d1")
+	forward(s, int64(7), "b2")
+	assert.Equal(t, []string{"a1", "b1", "c1", "a3", "d1"}, sent, "late notifications are dropped")
+	p.forward(s, &mcp.ProgressNotificationParams{ProgressToken: "a", Progress: 1, Total: 1}, func() { sent = append(sent, "a4") })
+	assert.Equal(t, "a4", sent[len(sent)-1], "completion is forwarded")
+
+	// A new request may reuse a token.
+	p.start(s, "a")
+	forward(s, "a", "a5")
+	assert.Equal(t, "a5", sent[len(sent)-1])
+
+	p.dropSession(other)
+	p.dropSession(s)
+	assert.Empty(t, p.streams)
+	assert.Empty(t, p.tombstones)
+	assert.Panics(t, func() { newTestVariantServer().WithProgressThrottle(-time.Second) })
+}
+
+func TestWithProgressThrottle_SessionsWithoutID(t *testing.T) {
+	release := make(chan struct{})
+	srv := mcp.NewServer(&mcp.Implementation{Name: "hold", Version: "v1.0.0"}, nil)
+	mcp.AddTool(srv, &mcp.Tool{Name: "hold"}, func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+		_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{ProgressToken: req.Params.GetProgressToken(), Progress: 1})
+		<-release
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "hold", Description: "Hold"}, srv, 0).
+		WithProgressThrottle(time.Hour)
+	front, err := vs.mcpServer(TransportOther, false)
+	require.NoError(t, err)
+	t.Cleanup(func() { vs.Close() })
+
+	// In-memory sessions have no ID. Concurrent requests of two of them
+	// with the same progress token are throttled separately.
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for range 2 {
+		collector := &notificationCollector{}
+		cs := connectInMemoryClient(t, front, collector.clientOptions())
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = cs.CallTool(ctx, &mcp.CallToolParams{Meta: mcp.Meta{"progressToken": "tok"}, Name: "hold"})
+		}()
+		assert.Eventually(t, func() bool { return collector.progressCount() == 1 }, 2*time.Second, 10*time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+}
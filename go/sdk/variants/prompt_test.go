@@ -0,0 +1,98 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPromptServer returns a server with an "explain" prompt whose message is
+// prefixed with name, plus the given extra prompts, and a completion handler
+// completing the "topic" argument with values prefixed with name.
+func newPromptServer(name string, extra ...string) *mcp.Server {
+	srv := mcp.NewServer(&mcp.Implementation{Name: name, Version: "v1.0.0"}, &mcp.ServerOptions{
+		CompletionHandler: func(_ context.Context, req *mcp.CompleteRequest) (*mcp.CompleteResult, error) {
+			return &mcp.CompleteResult{Completion: mcp.CompletionResultDetails{
+				Values: []string{name + ":" + req.Params.Ref.Name + ":" + req.Params.Argument.Value},
+			}}, nil
+		},
+	})
+	get := func(_ context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return &mcp.GetPromptResult{Messages: []*mcp.PromptMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: name + ": " + req.Params.Name + " " + req.Params.Arguments["topic"]}},
+		}}, nil
+	}
+	for _, p := range append([]string{"explain"}, extra...) {
+		srv.AddPrompt(&mcp.Prompt{Name: p, Arguments: []*mcp.PromptArgument{{Name: "topic", Required: true}}}, get)
+	}
+	return srv
+}
+
+// TestIntegration_Prompts verifies prompt listing, prompt retrieval and
+// argument completion routed to variants through the proxy.
+func TestIntegration_Prompts(t *testing.T) {
+	vs := NewServer(&mcp.Implementation{Name: "tutor", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "novice", Status: Stable}, newPromptServer("novice", "walkthrough"), 0).
+		WithVariant(ServerVariant{ID: "expert", Status: Stable}, newPromptServer("expert", "review"), 1)
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+	caps := session.InitializeResult().Capabilities
+	require.NotNil(t, caps.Prompts)
+	require.NotNil(t, caps.Completions)
+
+	selecting := func(id string) mcp.Meta { return mcp.Meta{metaKeyVariant: id} }
+	promptNames := func(res *mcp.ListPromptsResult) []string {
+		var names []string
+		for _, p := range res.Prompts {
+			names = append(names, p.Name)
+		}
+		return names
+	}
+
+	res, err := session.ListPrompts(ctx, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"explain", "walkthrough"}, promptNames(res))
+	res, err = session.ListPrompts(ctx, &mcp.ListPromptsParams{Meta: selecting("expert")})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"explain", "review"}, promptNames(res))
+
+	for _, id := range []string{"novice", "expert"} {
+		got, err := session.GetPrompt(ctx, &mcp.GetPromptParams{
+			Meta:      selecting(id),
+			Name:      "explain",
+			Arguments: map[string]string{"topic": "goroutines"},
+		})
+		require.NoError(t, err)
+		require.Len(t, got.Messages, 1)
+		assert.Equal(t, id+": explain goroutines", got.Messages[0].Content.(*mcp.TextContent).Text)
+
+		completed, err := session.Complete(ctx, &mcp.CompleteParams{
+			Meta:     selecting(id),
+			Ref:      &mcp.CompleteReference{Type: "ref/prompt", Name: "explain"},
+			Argument: mcp.CompleteParamsArgument{Name: "topic", Value: "gor"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{id + ":explain:gor"}, completed.Completion.Values)
+	}
+
+	// Prompts of other variants are not found in the selected variant.
+	_, err = session.GetPrompt(ctx, &mcp.GetPromptParams{
+		Meta:      selecting("novice"),
+		Name:      "review",
+		Arguments: map[string]string{"topic": "x"},
+	})
+	var jErr *jsonrpc.Error
+	require.True(t, errors.As(err, &jErr))
+	assert.True(t, strings.Contains(jErr.Message, "review"), jErr.Message)
+	assert.JSONEq(t, `{"activeVariant":"novice"}`, string(jErr.Data))
+}
@@ -0,0 +1,26 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import "time"
+
+// supportsProtocol reports whether the variant supports the given
+// negotiated protocol version. MCP protocol versions are dates
+// (YYYY-MM-DD), so they order lexically. An unknown version is assumed to
+// be supported.
+func (s *Server) supportsProtocol(variantID, version string) bool {
+	if !s.versionGated || version == "" {
+		return true
+	}
+	v, ok := s.lookupVariant(variantID)
+	return !ok || v.MinimumProtocolVersion <= version
+}
+
+// validProtocolVersion reports whether v has the form of an MCP protocol
+// version.
+func validProtocolVersion(v string) bool {
+	_, err := time.Parse(time.DateOnly, v)
+	return err == nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVersionGatedServer returns a server whose "tasks" variant requires
+// protocol version 2025-06-18.
+func newVersionGatedServer() *Server {
+	_, compact := newTestServers()
+	return newTestVariantServer().
+		WithVariant(ServerVariant{ID: "tasks", Description: "Tasks", MinimumProtocolVersion: "2025-06-18"}, compact, -1)
+}
+
+// initializeWithVersion initializes a raw session with vs, requesting the
+// given protocol version, and returns the variants extension payload.
+func initializeWithVersion(t *testing.T, vs *Server, version string) AvailableVariantsPayload {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	go vs.Run(ctx, serverTransport)
+	conn, err := clientTransport.Connect(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	params, err := json.Marshal(map[string]any{
+		"protocolVersion": version,
+		"clientInfo":      map[string]any{"name": "old-client", "version": "1.0.0"},
+		"capabilities": map[string]any{
+			"experimental": map[string]any{extensionID: map[string]any{}},
+		},
+	})
+	require.NoError(t, err)
+	id, err := jsonrpc.MakeID(1.0)
+	require.NoError(t, err)
+	require.NoError(t, conn.Write(ctx, &jsonrpc.Request{ID: id, Method: "initialize", Params: params}))
+	msg, err := conn.Read(ctx)
+	require.NoError(t, err)
+	resp, ok := msg.(*jsonrpc.Response)
+	require.True(t, ok, "got %T", msg)
+	require.NoError(t, resp.Error)
+
+	var result struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		Capabilities    struct {
+			Experimental map[string]AvailableVariantsPayload `json:"experimental"`
+		} `json:"capabilities"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Result, &result))
+	require.Equal(t, version, result.ProtocolVersion)
+	return result.Capabilities.Experimental[extensionID]
+}
+
+func TestMinimumProtocolVersion(t *testing.T) {
+	for _, tc := range []struct {
+		version     string
+		wantDefault string
+		wantIDs     []string
+	}{
+		{"2025-06-18", "tasks", []string{"tasks", "coding", "compact"}},
+		{"2025-03-26", "coding", []string{"coding", "compact"}},
+	} {
+		t.Run(tc.version, func(t *testing.T) {
+			p := initializeWithVersion(t, newVersionGatedServer(), tc.version)
+			var ids []string
+			for _, v := range p.AvailableVariants {
+				ids = append(ids, v.ID)
+			}
+			assert.Equal(t, tc.wantIDs, ids)
+			assert.Equal(t, tc.wantDefault, p.DefaultVariant)
+			if tc.wantDefault == "tasks" {
+				assert.Equal(t, "2025-06-18", p.AvailableVariants[0].MinimumProtocolVersion)
+			}
+		})
+	}
+}
+
+func TestMinimumProtocolVersion_FlagEnabled(t *testing.T) {
+	vs := newVersionGatedServer()
+	ctx := context.Background()
+	assert.True(t, vs.flagEnabled(ctx, "tasks", FlagContext{ProtocolVersion: "2025-11-25"}))
+	assert.False(t, vs.flagEnabled(ctx, "tasks", FlagContext{ProtocolVersion: "2024-11-05"}))
+	assert.True(t, vs.flagEnabled(ctx, "tasks", FlagContext{}), "unknown versions are not gated")
+	assert.True(t, vs.flagEnabled(ctx, "coding", FlagContext{ProtocolVersion: "2024-11-05"}))
+}
+
+func TestMinimumProtocolVersion_Invalid(t *testing.T) {
+	_, compact := newTestServers()
+	assert.PanicsWithValue(t, "variants: minimum protocol version of variant v is not a date: 2025", func() {
+		newTestVariantServer().WithVariant(ServerVariant{ID: "v", MinimumProtocolVersion: "2025"}, compact, 2)
+	})
+}
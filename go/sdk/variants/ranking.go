@@ -6,7 +6,10 @@ package variants
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"slices"
+	"sync"
 )
 
 // defaultRankingFunc is the built-in ranking function used when no custom
@@ -39,3 +42,94 @@ func statusWeight(s VariantStatus) int {
 		return 3
 	}
 }
+
+// recommendedVariant returns the ID of the variant recommended to a client
+// with the given hints. The configured RecommendFunc is consulted first; its
+// result is used only if it names one of the ranked variants. Otherwise the
+// first-ranked variant is recommended.
+func (s *Server) recommendedVariant(ctx context.Context, hints VariantHints, ranked []ServerVariant) string {
+	if len(ranked) == 0 {
+		return ""
+	}
+	if s.recommendFunc != nil {
+		id := s.recommendFunc(ctx, hints, ranked)
+		for _, v := range ranked {
+			if v.ID == id {
+				return id
+			}
+		}
+	}
+	return ranked[0].ID
+}
+
+// rankingCache memoizes RankedVariants results keyed by a canonical
+// fingerprint of the client hints. It is bounded: once full, an arbitrary
+// entry is evicted for each insertion. The zero value is a disabled cache.
+type rankingCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[[sha256.Size]byte][]ServerVariant
+}
+
+// hintsFingerprint returns a canonical fingerprint of hints. encoding/json
+// sorts map keys, so equal hints always produce equal fingerprints. ok is
+// false if the hints cannot be marshaled, in which case they are not cached.
+func hintsFingerprint(hints VariantHints) (fp [sha256.Size]byte, ok bool) {
+	data, err := json.Marshal(hints)
+	if err != nil {
+		return fp, false
+	}
+	return sha256.Sum256(data), true
+}
+
+// get returns the cached ranking for fp, if any. The ranking is shared
+// with the cache and must not be modified.
+func (c *rankingCache) get(fp [sha256.Size]byte) ([]ServerVariant, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ranked, ok := c.entries[fp]
+	return ranked, ok
+}
+
+// put stores a copy of ranked under fp.
+func (c *rankingCache) put(fp [sha256.Size]byte, ranked []ServerVariant) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size <= 0 {
+		return
+	}
+	if c.entries == nil {
+		c.entries = make(map[[sha256.Size]byte][]ServerVariant)
+	}
+	if _, exists := c.entries[fp]; !exists && len(c.entries) >= c.size {
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+	c.entries[fp] = slices.Clone(ranked)
+}
+
+// enabled reports whether the cache stores entries.
+func (c *rankingCache) enabled() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size > 0
+}
+
+// reset sets the cache capacity and drops all entries. It is called whenever
+// the inputs to ranking other than hints change (variant set, ranking
+// function).
+func (c *rankingCache) reset(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.size = size
+	c.entries = nil
+}
+
+// invalidate drops all cached rankings, keeping the capacity.
+func (c *rankingCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// rankingExclusionsKey is the context key of the *rankingExclusions of a
+// RankingFunc call.
+type rankingExclusionsKey struct{}
+
+// rankingExclusions records the variants a RankingFunc excludes with
+// ExcludeFromRanking.
+type rankingExclusions struct {
+	mu  sync.Mutex
+	ids map[string]bool
+}
+
+// ExcludeFromRanking declares, from within a RankingFunc, that the ranking
+// leaves out the variants with the given IDs on purpose, for example
+// because they do not suit the client. Variants left out otherwise are
+// reported as RuleDroppedVariant violations, since a ranking that loses
+// variants by mistake silently changes which variants clients are offered.
+// It has no effect outside a RankingFunc called by a Server or
+// CheckRanking.
+func ExcludeFromRanking(ctx context.Context, ids ...string) {
+	ex, ok := ctx.Value(rankingExclusionsKey{}).(*rankingExclusions)
+	if !ok {
+		return
+	}
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	for _, id := range ids {
+		ex.ids[id] = true
+	}
+}
+
+// rankWith calls fn to rank variants for hints, and returns the ranking
+// with the IDs fn excluded with ExcludeFromRanking.
+func rankWith(ctx context.Context, fn RankingFunc, hints VariantHints, variants []ServerVariant) (ranked []ServerVariant, excluded map[string]bool) {
+	ex := &rankingExclusions{ids: make(map[string]bool)}
+	ranked = fn(context.WithValue(ctx, rankingExclusionsKey{}, ex), hints, variants)
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	return ranked, ex.ids
+}
+
+// RankingError reports an invalid ranking returned by a RankingFunc.
+type RankingError struct {
+	// Violations are the ranking's violations, with the rules
+	// RuleDuplicateVariantID, RuleUnknownVariantID and RuleDroppedVariant.
+	Violations []Violation
+}
+
+func (e *RankingError) Error() string {
+	details := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		details[i] = v.Detail
+	}
+	return "variants: invalid ranking: " + strings.Join(details, "; ")
+}
+
+// CheckRanking calls fn to rank variants for hints, as a Server does, and
+// checks the ranking: it must list each variant at most once, list no
+// other variants, and list all of them but those excluded with
+// ExcludeFromRanking. It returns the ranking and, if it is invalid, a
+// *RankingError. Use it to test ranking functions.
+func CheckRanking(ctx context.Context, fn RankingFunc, hints VariantHints, variants []ServerVariant) ([]ServerVariant, error) {
+	ranked, excluded := rankWith(ctx, fn, hints, slices.Clone(variants))
+	if violations := rankingViolations(variants, ranked, excluded); len(violations) > 0 {
+		return ranked, &RankingError{Violations: violations}
+	}
+	return ranked, nil
+}
+
+// WithRankingAssertions makes the server panic with a *RankingError when
+// its RankingFunc returns an invalid ranking, as described by
+// CheckRanking, so that bugs in ranking functions surface at the first
+// ranking during development and in tests. Otherwise, invalid rankings are
+// recorded and reported by Validate, and served as described by
+// WithStrict. Do not enable it in production. Disabled by default.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithRankingAssertions(enabled bool) *Server {
+	s.rankingAssertions = enabled
+	return s
+}
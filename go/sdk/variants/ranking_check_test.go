@@ -0,0 +1,82 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// firstOnlyRanking keeps only the first variant, excluding the others if
+// exclude is set.
+func firstOnlyRanking(exclude bool) RankingFunc {
+	return func(ctx context.Context, _ VariantHints, vs []ServerVariant) []ServerVariant {
+		if exclude {
+			for _, v := range vs[1:] {
+				ExcludeFromRanking(ctx, v.ID)
+			}
+		}
+		return vs[:1]
+	}
+}
+
+func TestCheckRanking(t *testing.T) {
+	ctx := context.Background()
+	all := newTestVariantServer().Variants()
+
+	ranked, err := CheckRanking(ctx, defaultRankingFunc, VariantHints{}, all)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"coding", "compact"}, variantIDs(ranked))
+
+	ranked, err = CheckRanking(ctx, firstOnlyRanking(true), VariantHints{}, all)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"coding"}, variantIDs(ranked))
+
+	_, err = CheckRanking(ctx, firstOnlyRanking(false), VariantHints{}, all)
+	var rankErr *RankingError
+	require.ErrorAs(t, err, &rankErr)
+	assert.Equal(t, []Violation{{
+		Rule:      RuleDroppedVariant,
+		VariantID: "compact",
+		Detail:    `ranking leaves out variant "compact"; call ExcludeFromRanking to leave it out on purpose`,
+	}}, rankErr.Violations)
+
+	_, err = CheckRanking(ctx, duplicateRanking, VariantHints{}, all)
+	assert.EqualError(t, err, `variants: invalid ranking: ranking lists variant "coding" more than once; `+
+		`ranking lists variant "ghost", which is not registered or not in rotation`)
+	assert.Equal(t, []string{"coding", "compact"}, variantIDs(all), "variants are not modified")
+}
+
+func TestServer_DroppedVariant(t *testing.T) {
+	ctx := context.Background()
+
+	vs := newTestVariantServer().WithRanking(firstOnlyRanking(false))
+	assert.Equal(t, []string{"coding"}, variantIDs(vs.RankedVariants(ctx, VariantHints{})))
+	violations := vs.Validate(ctx)
+	require.Len(t, violations, 1)
+	assert.Equal(t, RuleDroppedVariant, violations[0].Rule)
+
+	vs = newTestVariantServer().WithRanking(firstOnlyRanking(false)).WithStrict(true)
+	assert.Equal(t, []string{"coding"}, variantIDs(vs.RankedVariants(ctx, VariantHints{})), "dropped variants stay dropped")
+
+	vs = newTestVariantServer().WithRanking(firstOnlyRanking(true))
+	assert.Equal(t, []string{"coding"}, variantIDs(vs.RankedVariants(ctx, VariantHints{})))
+	assert.Empty(t, vs.Validate(ctx))
+}
+
+func TestServer_WithRankingAssertions(t *testing.T) {
+	ctx := context.Background()
+	vs := newTestVariantServer().WithRanking(duplicateRanking).WithRankingAssertions(true)
+	assert.PanicsWithError(t, `variants: invalid ranking: ranking lists variant "coding" more than once; `+
+		`ranking lists variant "ghost", which is not registered or not in rotation`, func() {
+		vs.RankedVariants(ctx, VariantHints{})
+	})
+
+	vs = newTestVariantServer().WithRanking(firstOnlyRanking(true)).WithRankingAssertions(true)
+	assert.NotPanics(t, func() { vs.RankedVariants(ctx, VariantHints{}) })
+}
@@ -0,0 +1,40 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+// RankingMetadata describes how the server ranked an advertised variant.
+// It is serialized as the "ranking" field of the variant's availableVariants
+// entry when enabled with Server.WithRankingMetadata.
+type RankingMetadata struct {
+	// Index is the variant's 0-based position in the server's ranking for
+	// the client's hints.
+	Index int `json:"index"`
+
+	// HintMatched reports whether the variant matched the client's hints:
+	// one of its Hints equals a value the client gave for the same key, or
+	// the RankingFunc gave a MatchReason.
+	HintMatched bool `json:"hintMatched"`
+}
+
+// WithRankingMetadata enables or disables the "ranking" field of each
+// ranked entry of availableVariants, a [RankingMetadata] object, so that
+// analytics on client-side selection can be compared with the server's
+// recommendations. Variants listed only for availability reporting have
+// no ranking. Disabled by default.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithRankingMetadata(enabled bool) *Server {
+	s.rankingMetadata = enabled
+	return s
+}
+
+// rankingMetadata returns the RankingMetadata of the variant v at index i
+// of the ranking for hints.
+func rankingMetadata(hints VariantHints, v ServerVariant, i int) RankingMetadata {
+	return RankingMetadata{
+		Index:       i,
+		HintMatched: v.MatchReason != "" || hintMatchScore(hints, v) > 0,
+	}
+}
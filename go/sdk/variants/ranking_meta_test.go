@@ -0,0 +1,51 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRankingMetadata(t *testing.T) {
+	coding, compact := newTestServers()
+	docs := newDocsServer("docs")
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "coding", Description: "Coding", Hints: map[string]string{HintContextSize: "verbose"}}, coding, 0).
+		WithVariant(ServerVariant{ID: "compact", Description: "Compact", Hints: map[string]string{HintContextSize: "compact"}}, compact, 1).
+		WithVariant(ServerVariant{ID: "docs", Description: "Docs"}, docs, 2).
+		WithRankingMetadata(true).
+		WithAvailabilityReporting(true)
+	require.NoError(t, vs.SetVariantAvailability("docs", false, "maintenance"))
+
+	session := connectTestClient(t, vs, hintsClientOptions(map[string]any{HintContextSize: "compact"}))
+	var payload struct {
+		AvailableVariants []struct {
+			ID      string           `json:"id"`
+			Ranking *RankingMetadata `json:"ranking"`
+		} `json:"availableVariants"`
+	}
+	initExtension(t, session, &payload)
+
+	require.Len(t, payload.AvailableVariants, 3)
+	assert.Equal(t, "coding", payload.AvailableVariants[0].ID)
+	assert.Equal(t, &RankingMetadata{Index: 0, HintMatched: false}, payload.AvailableVariants[0].Ranking)
+	assert.Equal(t, "compact", payload.AvailableVariants[1].ID)
+	assert.Equal(t, &RankingMetadata{Index: 1, HintMatched: true}, payload.AvailableVariants[1].Ranking)
+	assert.Equal(t, "docs", payload.AvailableVariants[2].ID)
+	assert.Nil(t, payload.AvailableVariants[2].Ranking, "unavailable variants are not ranked")
+}
+
+func TestRankingMetadata_Disabled(t *testing.T) {
+	var payload struct {
+		AvailableVariants []map[string]any `json:"availableVariants"`
+	}
+	initExtension(t, connectTestClient(t, newTestVariantServer(), nil), &payload)
+	require.NotEmpty(t, payload.AvailableVariants)
+	assert.NotContains(t, payload.AvailableVariants[0], "ranking")
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// TransportKind identifies how the front server is being served.
+type TransportKind string
+
+const (
+	// TransportStdio is used by Server.Run with an *mcp.StdioTransport.
+	TransportStdio TransportKind = "stdio"
+	// TransportStreamableHTTP is used by NewStreamableHTTPHandler.
+	TransportStreamableHTTP TransportKind = "streamable-http"
+	// TransportSSE is used by NewSSEHandler.
+	TransportSSE TransportKind = "sse"
+	// TransportUnix is used by Server.ServeUnix.
+	TransportUnix TransportKind = "unix"
+	// TransportOther is used by Server.Run with any other transport.
+	TransportOther TransportKind = "other"
+)
+
+// RankingRequest describes who is connecting, for ranking functions that
+// consider the client's identity and not just the hints it sent. It is
+// attached to the context passed to the RankingFunc; retrieve it with
+// RankingRequestFromContext. Fields are zero when unknown.
+type RankingRequest struct {
+	// ClientInfo is the client implementation reported at initialize.
+	ClientInfo *mcp.Implementation
+
+	// ProtocolVersion is the negotiated MCP protocol version.
+	ProtocolVersion string
+
+	// Transport is how the front server is being served.
+	Transport TransportKind
+
+	// Header is the header of the HTTP request that carried the MCP
+	// request, for HTTP transports.
+	Header http.Header
+
+	// TokenInfo describes the authenticated principal, if the HTTP handler
+	// is wrapped with bearer token authentication.
+	TokenInfo *auth.TokenInfo
+}
+
+// rankingRequestKey is the context key for the *RankingRequest.
+type rankingRequestKey struct{}
+
+// RankingRequestFromContext returns the RankingRequest attached to a
+// RankingFunc's context, if any. Calls to Server.RankedVariants made outside
+// request handling carry none.
+func RankingRequestFromContext(ctx context.Context) (*RankingRequest, bool) {
+	rr, ok := ctx.Value(rankingRequestKey{}).(*RankingRequest)
+	return rr, ok
+}
+
+// newRankingRequest describes the client that sent req over the given
+// transport. For initialize, pass the result to report the negotiated
+// protocol version; otherwise pass nil.
+func newRankingRequest(req mcp.Request, transport TransportKind, initResult *mcp.InitializeResult) *RankingRequest {
+	rr := &RankingRequest{Transport: transport}
+	if extra := req.GetExtra(); extra != nil {
+		rr.Header = extra.Header
+		rr.TokenInfo = extra.TokenInfo
+	}
+	if params, ok := req.GetParams().(*mcp.InitializeParams); ok && params != nil {
+		rr.ClientInfo = params.ClientInfo
+		rr.ProtocolVersion = params.ProtocolVersion
+	} else if ss, ok := req.GetSession().(*mcp.ServerSession); ok && ss != nil {
+		if params := ss.InitializeParams(); params != nil {
+			rr.ClientInfo = params.ClientInfo
+			rr.ProtocolVersion = params.ProtocolVersion
+		}
+	}
+	if initResult != nil && initResult.ProtocolVersion != "" {
+		rr.ProtocolVersion = initResult.ProtocolVersion
+	}
+	return rr
+}
+
+// transportKindOf returns the TransportKind for a transport passed to
+// Server.Run.
+func transportKindOf(t mcp.Transport) TransportKind {
+	if _, ok := t.(*mcp.StdioTransport); ok {
+		return TransportStdio
+	}
+	return TransportOther
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecommendedVariant(t *testing.T) {
+	ranked := []ServerVariant{{ID: "a"}, {ID: "b"}}
+
+	tests := []struct {
+		name string
+		fn   RecommendFunc
+		want string
+	}{
+		{"no func", nil, "a"},
+		{"known ID", func(context.Context, VariantHints, []ServerVariant) string { return "b" }, "b"},
+		{"unknown ID", func(context.Context, VariantHints, []ServerVariant) string { return "zzz" }, "a"},
+		{"empty ID", func(context.Context, VariantHints, []ServerVariant) string { return "" }, "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{recommendFunc: tt.fn}
+			assert.Equal(t, tt.want, s.recommendedVariant(context.Background(), VariantHints{}, ranked))
+		})
+	}
+}
+
+func TestRankedVariants_Cache(t *testing.T) {
+	calls := 0
+	vs := newTestVariantServer().
+		WithRanking(func(ctx context.Context, hints VariantHints, vs []ServerVariant) []ServerVariant {
+			calls++
+			return defaultRankingFunc(ctx, hints, vs)
+		}).
+		WithRankingCache(2)
+	ctx := context.Background()
+	hints := func(size string) VariantHints {
+		return VariantHints{Hints: map[string]any{HintContextSize: size, HintModelFamily: "any"}}
+	}
+
+	first := vs.RankedVariants(ctx, hints("compact"))
+	require.Len(t, first, 2)
+	first[0].ID = "mutated"
+
+	second := vs.RankedVariants(ctx, hints("compact"))
+	assert.Equal(t, 1, calls, "equal hints should hit the cache")
+	assert.Equal(t, "coding", second[0].ID, "callers must not be able to mutate cached rankings")
+
+	vs.RankedVariants(ctx, hints("verbose"))
+	assert.Equal(t, 2, calls, "different hints should miss the cache")
+
+	// Registering a variant invalidates the cache.
+	vs.WithVariant(ServerVariant{ID: "extra"}, mcp.NewServer(&mcp.Implementation{Name: "extra", Version: "v0.0.1"}, nil), 2)
+	assert.Len(t, vs.RankedVariants(ctx, hints("compact")), 3)
+	assert.Equal(t, 3, calls)
+
+	// The cache stays within its bound.
+	vs.RankedVariants(ctx, hints("standard"))
+	vs.RankedVariants(ctx, hints("other"))
+	assert.LessOrEqual(t, len(vs.rankCache.entries), 2)
+}
+
+func TestRankedVariants_CacheDisabled(t *testing.T) {
+	calls := 0
+	vs := newTestVariantServer().
+		WithRanking(func(ctx context.Context, hints VariantHints, vs []ServerVariant) []ServerVariant {
+			calls++
+			return defaultRankingFunc(ctx, hints, vs)
+		})
+	vs.RankedVariants(context.Background(), VariantHints{})
+	vs.RankedVariants(context.Background(), VariantHints{})
+	assert.Equal(t, 2, calls)
+}
+
+func BenchmarkRankedVariants(b *testing.B) {
+	for _, size := range []int{0, 64} {
+		name := "uncached"
+		if size > 0 {
+			name = "cached"
+		}
+		b.Run(name, func(b *testing.B) {
+			vs := NewServer(&mcp.Implementation{Name: "bench", Version: "v0.0.1"}).WithRankingCache(size)
+			for i := range 50 {
+				vs.WithVariant(ServerVariant{ID: fmt.Sprintf("v%d", i)}, mcp.NewServer(&mcp.Implementation{Name: "inner", Version: "v0.0.1"}, nil), 50-i)
+			}
+			hints := VariantHints{Hints: map[string]any{HintContextSize: "compact"}}
+			ctx := context.Background()
+			b.ResetTimer()
+			for range b.N {
+				vs.RankedVariants(ctx, hints)
+			}
+		})
+	}
+}
+
+// TestRankingRequest verifies that the RankingFunc context describes the
+// connecting client: its implementation, protocol version, transport and
+// HTTP headers.
+func TestRankingRequest(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		seen *RankingRequest
+	)
+	vs := newTestVariantServer().
+		WithRanking(func(ctx context.Context, hints VariantHints, vs []ServerVariant) []ServerVariant {
+			vs = defaultRankingFunc(ctx, hints, vs)
+			rr, ok := RankingRequestFromContext(ctx)
+			if !ok {
+				return vs
+			}
+			mu.Lock()
+			seen = rr
+			mu.Unlock()
+			if rr.Header.Get("X-Tier") == "lite" {
+				vs[0], vs[1] = vs[1], vs[0]
+			}
+			return vs
+		})
+
+	httpSrv := httptest.NewServer(NewStreamableHTTPHandler(vs, nil))
+	t.Cleanup(httpSrv.Close)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "ranking-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{
+		Endpoint: httpSrv.URL,
+		HTTPClient: &http.Client{
+			Transport: &headerRoundTripper{
+				base:   http.DefaultTransport,
+				header: http.Header{"X-Tier": []string{"lite"}},
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() })
+
+	var p struct {
+		AvailableVariants []struct {
+			ID string `json:"id"`
+		} `json:"availableVariants"`
+	}
+	initExtension(t, session, &p)
+	require.Len(t, p.AvailableVariants, 2)
+	assert.Equal(t, "compact", p.AvailableVariants[0].ID)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotNil(t, seen)
+	require.NotNil(t, seen.ClientInfo)
+	assert.Equal(t, "ranking-client", seen.ClientInfo.Name)
+	assert.Equal(t, TransportStreamableHTTP, seen.Transport)
+	assert.Equal(t, session.InitializeResult().ProtocolVersion, seen.ProtocolVersion)
+
+	_, ok := RankingRequestFromContext(context.Background())
+	assert.False(t, ok)
+}
@@ -0,0 +1,340 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+// Package redisstore implements [variants.SharedStore] on Redis, for
+// horizontally scaled variant servers:
+//
+//	st := redisstore.New("redis:6379", &redisstore.Options{Password: pw})
+//	defer st.Close()
+//	vs.WithSharedStore(st, nil)
+//
+// It speaks the Redis protocol (RESP2) directly over a small connection
+// pool, using only GET, SET with PX, DEL, AUTH and SELECT, so it needs no
+// client library and works with Redis-compatible servers such as Valkey
+// and KeyDB. It supports TLS and ACL users, but not Redis Cluster or
+// Sentinel.
+package redisstore
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+)
+
+// Options configure a Store. The zero value is valid.
+type Options struct {
+	// Username and Password, if Password is set, authenticate new
+	// connections with AUTH. Username names an ACL user (Redis 6 and
+	// later); empty means the default user.
+	Username string
+	Password string
+
+	// TLSConfig, if set, connects with TLS using the configuration. If its
+	// ServerName is empty, it is taken from the address.
+	TLSConfig *tls.Config
+
+	// DB selects the database of new connections with SELECT. Zero is the
+	// default database.
+	DB int
+
+	// PoolSize is the maximum number of idle connections kept for reuse.
+	// Zero means 4.
+	PoolSize int
+
+	// DialTimeout bounds connecting to the server. Zero means 5 seconds.
+	DialTimeout time.Duration
+
+	// ReadTimeout bounds each command when the context has no earlier
+	// deadline. Zero means 3 seconds. Commands also end when their
+	// context is cancelled.
+	ReadTimeout time.Duration
+}
+
+// Defaults for Options.
+const (
+	defaultPoolSize    = 4
+	defaultDialTimeout = 5 * time.Second
+	defaultReadTimeout = 3 * time.Second
+)
+
+// ErrClosed is returned by the methods of a closed Store.
+var ErrClosed = errors.New("redisstore: store closed")
+
+// Store is a variants.SharedStore on a Redis server. It is safe for
+// concurrent use.
+type Store struct {
+	addr string
+	opts Options
+	idle chan *conn
+
+	mu     sync.Mutex
+	closed bool
+}
+
+var _ variants.SharedStore = (*Store)(nil)
+
+// New returns a Store for the Redis server at addr ("host:port"). It
+// connects lazily, on the first command. opts may be nil.
+func New(addr string, opts *Options) *Store {
+	s := &Store{addr: addr}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.PoolSize <= 0 {
+		s.opts.PoolSize = defaultPoolSize
+	}
+	if s.opts.DialTimeout <= 0 {
+		s.opts.DialTimeout = defaultDialTimeout
+	}
+	if s.opts.ReadTimeout <= 0 {
+		s.opts.ReadTimeout = defaultReadTimeout
+	}
+	s.idle = make(chan *conn, s.opts.PoolSize)
+	return s
+}
+
+// Get implements variants.SharedStore.
+func (s *Store) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := s.do(ctx, "GET", key)
+	if err != nil {
+		return nil, false, err
+	}
+	if reply == nil {
+		return nil, false, nil
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redisstore: unexpected GET reply %v", reply)
+	}
+	return value, true, nil
+}
+
+// Set implements variants.SharedStore. A ttl under a millisecond is
+// rounded up to one.
+func (s *Store) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	args := []string{"SET", key, string(value)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10))
+	}
+	_, err := s.do(ctx, args...)
+	return err
+}
+
+// Delete implements variants.SharedStore.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	_, err := s.do(ctx, "DEL", key)
+	return err
+}
+
+// Close closes the idle connections. Commands in flight complete, and
+// later commands fail with ErrClosed.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	for {
+		select {
+		case c := <-s.idle:
+			c.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// do runs a command on a pooled connection and returns its reply: nil,
+// a string for a status, an int64 or a []byte. Error replies are returned
+// as errors.
+func (s *Store) do(ctx context.Context, args ...string) (any, error) {
+	c, err := s.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := c.do(ctx, s.opts.ReadTimeout, args...)
+	var replyErr replyError
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection may be mid-reply; don't reuse it.
+		c.Close()
+		return nil, err
+	}
+	s.put(c)
+	return reply, err
+}
+
+// get returns an idle connection, or dials a new one.
+func (s *Store) get(ctx context.Context) (*conn, error) {
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed {
+		return nil, ErrClosed
+	}
+	select {
+	case c := <-s.idle:
+		return c, nil
+	default:
+	}
+	return s.dial(ctx)
+}
+
+// put returns a connection to the pool, closing it if the pool is full or
+// the store closed.
+func (s *Store) put(c *conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		c.Close()
+		return
+	}
+	select {
+	case s.idle <- c:
+	default:
+		c.Close()
+	}
+}
+
+// dial connects to the server, authenticating and selecting the database
+// as configured.
+func (s *Store) dial(ctx context.Context) (*conn, error) {
+	d := &net.Dialer{Timeout: s.opts.DialTimeout}
+	var (
+		nc  net.Conn
+		err error
+	)
+	if s.opts.TLSConfig != nil {
+		nc, err = (&tls.Dialer{NetDialer: d, Config: s.opts.TLSConfig}).DialContext(ctx, "tcp", s.addr)
+	} else {
+		nc, err = d.DialContext(ctx, "tcp", s.addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redisstore: %w", err)
+	}
+	c := &conn{Conn: nc, r: bufio.NewReader(nc)}
+	if s.opts.Password != "" {
+		auth := []string{"AUTH", s.opts.Password}
+		if s.opts.Username != "" {
+			auth = []string{"AUTH", s.opts.Username, s.opts.Password}
+		}
+		if _, err := c.do(ctx, s.opts.ReadTimeout, auth...); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	if s.opts.DB != 0 {
+		if _, err := c.do(ctx, s.opts.ReadTimeout, "SELECT", strconv.Itoa(s.opts.DB)); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// replyError is an error reply from the server.
+type replyError string
+
+func (e replyError) Error() string { return "redisstore: " + string(e) }
+
+// conn is a connection to the server.
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// do sends a command and reads its reply, within the context's deadline
+// or timeout, whichever is earlier, and until the context is cancelled.
+func (c *conn) do(ctx context.Context, timeout time.Duration, args ...string) (reply any, err error) {
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, fmt.Errorf("redisstore: %w", err)
+	}
+	// Cancellation interrupts blocked reads and writes by expiring the
+	// deadline.
+	stop := context.AfterFunc(ctx, func() { _ = c.SetDeadline(time.Now()) })
+	defer func() {
+		if !stop() && ctx.Err() != nil {
+			reply, err = nil, fmt.Errorf("redisstore: %w", ctx.Err())
+		}
+	}()
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, "\r\n"...)
+	for _, a := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(a)), 10)
+		buf = append(buf, "\r\n"...)
+		buf = append(buf, a...)
+		buf = append(buf, "\r\n"...)
+	}
+	if _, err := c.Write(buf); err != nil {
+		return nil, fmt.Errorf("redisstore: %w", err)
+	}
+	return c.readReply()
+}
+
+// readReply reads a RESP2 reply. Arrays are not needed by the commands
+// the store sends and are rejected.
+func (c *conn) readReply() (any, error) {
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("redisstore: empty reply")
+	}
+	switch line[0] {
+	case '+':
+		return string(line[1:]), nil
+	case '-':
+		return nil, replyError(line[1:])
+	case ':':
+		n, err := strconv.ParseInt(string(line[1:]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("redisstore: malformed integer reply %q", line)
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(string(line[1:]))
+		if err != nil || n < -1 {
+			return nil, fmt.Errorf("redisstore: malformed bulk reply %q", line)
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, data); err != nil {
+			return nil, fmt.Errorf("redisstore: %w", err)
+		}
+		return data[:n], nil
+	default:
+		return nil, fmt.Errorf("redisstore: unsupported reply %q", line)
+	}
+}
+
+// readLine reads a CRLF-terminated line, without the terminator.
+func (c *conn) readLine() ([]byte, error) {
+	line, err := c.r.ReadSlice('\n')
+	if err != nil {
+		return nil, fmt.Errorf("redisstore: %w", err)
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redisstore: malformed reply %q", line)
+	}
+	return line[:len(line)-2], nil
+}
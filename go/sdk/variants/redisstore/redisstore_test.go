@@ -0,0 +1,274 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package redisstore
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis is an in-process server for the commands Store sends.
+type fakeRedis struct {
+	ln       net.Listener
+	password string
+
+	mu       sync.Mutex
+	values   map[string]string
+	expires  map[string]time.Time
+	commands []string
+	dials    int
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	return serveFakeRedis(t, ln, password)
+}
+
+// newFakeRedisTLS is newFakeRedis serving TLS. It returns the TLS
+// configuration for clients.
+func newFakeRedisTLS(t *testing.T) (*fakeRedis, *tls.Config) {
+	t.Helper()
+	// Borrow httptest's certificate for 127.0.0.1.
+	https := httptest.NewTLSServer(http.NotFoundHandler())
+	cert := https.TLS.Certificates[0]
+	clientConfig := https.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+	https.Close()
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	require.NoError(t, err)
+	return serveFakeRedis(t, ln, ""), clientConfig
+}
+
+// serveFakeRedis serves a fakeRedis on ln until the test ends.
+func serveFakeRedis(t *testing.T, ln net.Listener, password string) *fakeRedis {
+	t.Helper()
+	f := &fakeRedis{ln: ln, password: password, values: map[string]string{}, expires: map[string]time.Time{}}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			f.mu.Lock()
+			f.dials++
+			f.mu.Unlock()
+			go f.serve(c)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) serve(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	authed := f.password == ""
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.commands = append(f.commands, strings.Join(args, " "))
+		var reply string
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "AUTH":
+			// The only ACL user besides the default one is "admin".
+			authed = len(args) == 2 && args[1] == f.password || len(args) == 3 && args[1] == "admin" && args[2] == f.password
+			reply = "+OK\r\n"
+			if !authed {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case cmd == "SELECT":
+			reply = "+OK\r\n"
+		case cmd == "GET":
+			v, ok := f.values[args[1]]
+			if exp, has := f.expires[args[1]]; has && !time.Now().Before(exp) {
+				ok = false
+			}
+			reply = "$-1\r\n"
+			if ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+			}
+		case cmd == "SET":
+			f.values[args[1]] = args[2]
+			delete(f.expires, args[1])
+			if len(args) == 5 && args[3] == "PX" {
+				ms, _ := strconv.Atoi(args[4])
+				f.expires[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+			}
+			reply = "+OK\r\n"
+		case cmd == "DEL":
+			n := 0
+			if _, ok := f.values[args[1]]; ok {
+				n = 1
+			}
+			delete(f.values, args[1])
+			reply = fmt.Sprintf(":%d\r\n", n)
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+		if _, err := io.WriteString(c, reply); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	var n int
+	if _, err := fmt.Fscanf(r, "*%d\r\n", &n); err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		var size int
+		if _, err := fmt.Fscanf(r, "$%d\r\n", &size); err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+func TestStore(t *testing.T) {
+	f := newFakeRedis(t, "")
+	st := New(f.ln.Addr().String(), nil)
+	t.Cleanup(func() { st.Close() })
+	ctx := context.Background()
+
+	_, ok, err := st.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, st.Set(ctx, "k", []byte("line\r\nbreak"), 0))
+	v, ok, err := st.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("line\r\nbreak"), v)
+
+	require.NoError(t, st.Set(ctx, "short", []byte("v"), 20*time.Millisecond))
+	time.Sleep(40 * time.Millisecond)
+	_, ok, err = st.Get(ctx, "short")
+	require.NoError(t, err)
+	assert.False(t, ok, "expired values are gone")
+
+	require.NoError(t, st.Delete(ctx, "k"))
+	_, ok, _ = st.Get(ctx, "k")
+	assert.False(t, ok)
+
+	f.mu.Lock()
+	assert.Equal(t, 1, f.dials, "sequential commands reuse a connection")
+	assert.Contains(t, f.commands, "SET short v PX 20")
+	f.mu.Unlock()
+
+	require.NoError(t, st.Close())
+	_, _, err = st.Get(ctx, "k")
+	assert.ErrorIs(t, err, ErrClosed)
+}
+
+func TestStore_AuthAndSelect(t *testing.T) {
+	f := newFakeRedis(t, "secret")
+	ctx := context.Background()
+
+	st := New(f.ln.Addr().String(), &Options{Password: "secret", DB: 2})
+	t.Cleanup(func() { st.Close() })
+	require.NoError(t, st.Set(ctx, "k", []byte("v"), time.Minute))
+	f.mu.Lock()
+	assert.Equal(t, []string{"AUTH secret", "SELECT 2", "SET k v PX 60000"}, f.commands)
+	f.mu.Unlock()
+
+	admin := New(f.ln.Addr().String(), &Options{Username: "admin", Password: "secret"})
+	t.Cleanup(func() { admin.Close() })
+	require.NoError(t, admin.Delete(ctx, "k"))
+	f.mu.Lock()
+	assert.Equal(t, "AUTH admin secret", f.commands[3])
+	f.mu.Unlock()
+
+	bad := New(f.ln.Addr().String(), &Options{Password: "wrong"})
+	t.Cleanup(func() { bad.Close() })
+	_, _, err := bad.Get(ctx, "k")
+	assert.ErrorContains(t, err, "WRONGPASS")
+}
+
+func TestStore_Unreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	st := New(addr, &Options{DialTimeout: time.Second})
+	t.Cleanup(func() { st.Close() })
+	_, _, err = st.Get(context.Background(), "k")
+	assert.Error(t, err)
+}
+
+func TestStore_TLS(t *testing.T) {
+	f, config := newFakeRedisTLS(t)
+	ctx := context.Background()
+
+	st := New(f.ln.Addr().String(), &Options{TLSConfig: config})
+	t.Cleanup(func() { st.Close() })
+	require.NoError(t, st.Set(ctx, "k", []byte("v"), 0))
+	v, ok, err := st.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("v"), v)
+
+	plain := New(f.ln.Addr().String(), &Options{DialTimeout: time.Second, ReadTimeout: time.Second})
+	t.Cleanup(func() { plain.Close() })
+	_, _, err = plain.Get(ctx, "k")
+	assert.Error(t, err, "plaintext commands fail")
+}
+
+func TestStore_Cancel(t *testing.T) {
+	// The server accepts commands but never replies.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				_, _ = io.Copy(io.Discard, c)
+			}()
+		}
+	}()
+
+	st := New(ln.Addr().String(), &Options{ReadTimeout: time.Minute})
+	t.Cleanup(func() { st.Close() })
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, _, err = st.Get(ctx, "k")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
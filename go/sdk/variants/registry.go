@@ -0,0 +1,168 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// registryPublisherMetaKey is the _meta key of a registry entry under which
+// publishers place their own metadata, such as the variant catalog.
+const registryPublisherMetaKey = "io.modelcontextprotocol.registry/publisher-provided"
+
+// RegistryEntry is an MCP server registry entry, in the server.json format
+// published to the registry. Only the fields the variants extension reads
+// or fills in are modeled; the rest are carried as raw JSON.
+type RegistryEntry struct {
+	Schema      string          `json:"$schema,omitempty"`
+	Name        string          `json:"name"`
+	Title       string          `json:"title,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Version     string          `json:"version,omitempty"`
+	WebsiteURL  string          `json:"websiteUrl,omitempty"`
+	Repository  json.RawMessage `json:"repository,omitempty"`
+	Packages    json.RawMessage `json:"packages,omitempty"`
+	Remotes     json.RawMessage `json:"remotes,omitempty"`
+	Meta        map[string]any  `json:"_meta,omitempty"`
+}
+
+// registryCatalog is the variant catalog embedded in a registry entry's
+// publisher-provided metadata under the extension ID.
+type registryCatalog struct {
+	DefaultVariant string            `json:"defaultVariant,omitempty"`
+	Variants       []VariantManifest `json:"variants"`
+}
+
+// RegistryEntry returns a registry entry advertising the server's variant
+// catalog, for publishing as server.json. The entry starts as a copy of
+// base, which supplies the registry name and packages or remotes; its
+// title and version default to the server's implementation. The catalog
+// from [Server.Manifest] is added to the publisher-provided metadata under
+// the extension ID, "io.modelcontextprotocol/server-variants".
+func (s *Server) RegistryEntry(ctx context.Context, base RegistryEntry) (*RegistryEntry, error) {
+	m, err := s.Manifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry := base
+	if entry.Title == "" {
+		entry.Title = s.impl.Title
+	}
+	if entry.Version == "" {
+		entry.Version = s.impl.Version
+	}
+
+	entry.Meta = make(map[string]any, len(base.Meta)+1)
+	for k, v := range base.Meta {
+		entry.Meta[k] = v
+	}
+	publisher := map[string]any{}
+	if p, ok := base.Meta[registryPublisherMetaKey].(map[string]any); ok {
+		for k, v := range p {
+			publisher[k] = v
+		}
+	}
+	publisher[extensionID] = registryCatalog{DefaultVariant: m.DefaultVariant, Variants: m.Variants}
+	entry.Meta[registryPublisherMetaKey] = publisher
+	return &entry, nil
+}
+
+// FetchRegistryEntry fetches the registry entry at url, either a bare
+// server.json document or a registry API response wrapping it in a
+// "server" field. It fails if the entry's variant catalog is malformed. If
+// client is nil, http.DefaultClient is used.
+func FetchRegistryEntry(ctx context.Context, client *http.Client, url string) (*RegistryEntry, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("variants: fetching registry entry: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("variants: fetching registry entry: %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("variants: fetching registry entry: %w", err)
+	}
+
+	var wrapped struct {
+		Server *RegistryEntry `json:"server"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("variants: decoding registry entry: %w", err)
+	}
+	entry := wrapped.Server
+	if entry == nil {
+		entry = &RegistryEntry{}
+		if err := json.Unmarshal(data, entry); err != nil {
+			return nil, fmt.Errorf("variants: decoding registry entry: %w", err)
+		}
+	}
+	if _, err := entry.variantCatalog(); err != nil {
+		return nil, fmt.Errorf("variants: %w", err)
+	}
+	return entry, nil
+}
+
+// WithRegistryEntry fills in the descriptions of registered variants from
+// the variant catalog of a registry entry, such as one published by
+// [Server.RegistryEntry] and fetched with [FetchRegistryEntry], so that
+// descriptions can be maintained in the registry. Only variants registered
+// without a description are changed; catalog entries for other variant IDs
+// are ignored. Call it after registering the variants.
+//
+// It panics if entry is nil or its variant catalog is malformed.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithRegistryEntry(entry *RegistryEntry) *Server {
+	if entry == nil {
+		panic("variants: nil RegistryEntry")
+	}
+	catalog, err := entry.variantCatalog()
+	if err != nil {
+		panic("variants: " + err.Error())
+	}
+	for _, v := range catalog {
+		i, ok := s.variantIndex[v.ID]
+		if ok && s.variants[i].variant.Description == "" {
+			s.variants[i].variant.Description = v.Description
+		}
+	}
+	s.rankCache.invalidate()
+	s.payloads.invalidate()
+	return s
+}
+
+// variantCatalog decodes the variants of the entry's catalog, if any.
+func (e *RegistryEntry) variantCatalog() ([]ServerVariant, error) {
+	publisher, _ := e.Meta[registryPublisherMetaKey].(map[string]any)
+	raw, ok := publisher[extensionID]
+	if !ok {
+		return nil, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("registry entry %q: %w", e.Name, err)
+	}
+	var catalog struct {
+		Variants []ServerVariant `json:"variants"`
+	}
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("registry entry %q: malformed variant catalog: %w", e.Name, err)
+	}
+	return catalog.Variants, nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryEntry(t *testing.T) {
+	ctx := context.Background()
+	base := RegistryEntry{
+		Name:    "io.github.example/devtools",
+		Remotes: json.RawMessage(`[{"type":"streamable-http","url":"https://example.com/mcp"}]`),
+		Meta: map[string]any{
+			registryPublisherMetaKey: map[string]any{"example.com/build": "1234"},
+		},
+	}
+	entry, err := newTestVariantServer().RegistryEntry(ctx, base)
+	require.NoError(t, err)
+	assert.Equal(t, "io.github.example/devtools", entry.Name)
+	assert.Equal(t, "1.0.0", entry.Version)
+	assert.Len(t, base.Meta[registryPublisherMetaKey], 1, "base must not be modified")
+
+	data, err := json.Marshal(entry)
+	require.NoError(t, err)
+	var doc struct {
+		Remotes []map[string]any                      `json:"remotes"`
+		Meta    map[string]map[string]json.RawMessage `json:"_meta"`
+	}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Len(t, doc.Remotes, 1)
+	publisher := doc.Meta[registryPublisherMetaKey]
+	assert.JSONEq(t, `"1234"`, string(publisher["example.com/build"]))
+	var catalog struct {
+		DefaultVariant string `json:"defaultVariant"`
+		Variants       []struct {
+			ID    string     `json:"id"`
+			Tools []mcp.Tool `json:"tools"`
+		} `json:"variants"`
+	}
+	require.NoError(t, json.Unmarshal(publisher[extensionID], &catalog))
+	assert.Equal(t, "coding", catalog.DefaultVariant)
+	require.Len(t, catalog.Variants, 2)
+	assert.Equal(t, "compact", catalog.Variants[1].ID)
+	assert.Len(t, catalog.Variants[1].Tools, 2)
+
+	// The published entry populates descriptions of a server that has none.
+	httpSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"server": entry})
+	}))
+	t.Cleanup(httpSrv.Close)
+	fetched, err := FetchRegistryEntry(ctx, nil, httpSrv.URL)
+	require.NoError(t, err)
+
+	coding, compact := newTestServers()
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "coding"}, coding, 0).
+		WithVariant(ServerVariant{ID: "compact", Description: "Local description"}, compact, 1).
+		WithRegistryEntry(fetched)
+	got := vs.Variants()
+	assert.Equal(t, "Optimized for coding workflows", got[0].Description)
+	assert.Equal(t, "Local description", got[1].Description)
+}
+
+func TestFetchRegistryEntry_Errors(t *testing.T) {
+	httpSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/malformed":
+			_, _ = w.Write([]byte(`{"name":"x","_meta":{"` + registryPublisherMetaKey + `":{"` + extensionID + `":{"variants":"oops"}}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(httpSrv.Close)
+
+	_, err := FetchRegistryEntry(context.Background(), httpSrv.Client(), httpSrv.URL+"/missing")
+	assert.ErrorContains(t, err, "404")
+	_, err = FetchRegistryEntry(context.Background(), httpSrv.Client(), httpSrv.URL+"/malformed")
+	assert.ErrorContains(t, err, "malformed variant catalog")
+}
@@ -0,0 +1,375 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// remoteBackend connects to a variant's MCP server over the streamable HTTP
+// transport, typically a separate service owned by another team.
+//
+// Unlike in-memory dispatch, requests cross the network, so context values
+// of the front request do not reach the variant's handlers. Notifications
+// and server-to-client requests the variant sends while handling a request
+// (progress, logging, elicitation, sampling) are forwarded to the front
+// session of the connection, as are resource updates. In stateless mode,
+// connections are shared by all front sessions, so nothing is forwarded.
+type remoteBackend struct {
+	variantID  string
+	endpoint   string
+	httpClient *http.Client
+	vs         *Server
+
+	// clientOnce builds tlsClient, the client configured with the
+	// variant's BackendTLS, or tlsErr.
+	clientOnce sync.Once
+	tlsClient  *http.Client
+	tlsErr     error
+}
+
+// transport returns a new client transport to the variant's server. With
+// Server.WithTracing, its requests carry the trace context of their
+// context. With creds, they carry exchanged tokens (see
+// Server.WithTokenExchange).
+func (b *remoteBackend) transport(creds *backendCredentials) (mcp.Transport, error) {
+	client, err := b.client()
+	if err != nil {
+		return nil, err
+	}
+	if creds != nil || b.vs.tracing != nil {
+		c := *client
+		if creds != nil {
+			c.Transport = &authTransport{base: c.Transport, creds: creds}
+		}
+		if b.vs.tracing != nil {
+			c.Transport = &traceTransport{base: c.Transport}
+		}
+		client = &c
+	}
+	return &mcp.StreamableClientTransport{Endpoint: b.endpoint, HTTPClient: client}, nil
+}
+
+// connect connects a client to the variant's server on behalf of
+// frontSession, which may be nil in stateless mode. If the connection
+// drops, for example because the variant's server restarted and lost its
+// sessions, the next request reconnects.
+func (b *remoteBackend) connect(ctx context.Context, variant ServerVariant, frontSession *mcp.ServerSession) (*innerConnection, error) {
+	opts := &mcp.ClientOptions{}
+	if b.vs.completions != nil {
+		opts.PromptListChangedHandler = func(context.Context, *mcp.PromptListChangedRequest) {
+			b.vs.completions.invalidate(b.variantID)
+		}
+	}
+	if frontSession != nil {
+		opts.ElicitationHandler = func(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			return frontSession.Elicit(ctx, req.Params)
+		}
+		opts.CreateMessageHandler = func(ctx context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			return frontSession.CreateMessage(ctx, req.Params)
+		}
+		opts.LoggingMessageHandler = func(ctx context.Context, req *mcp.LoggingMessageRequest) {
+			if req.Params == nil {
+				return
+			}
+			forward, report := b.vs.admitLog(frontSession, b.variantID, req.Params)
+			if report != nil {
+				_ = frontSession.Log(ctx, report)
+			}
+			if forward {
+				_ = frontSession.Log(ctx, req.Params)
+			}
+		}
+		opts.ProgressNotificationHandler = func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			if b.vs.progressThrottle == nil || req.Params == nil {
+				_ = frontSession.NotifyProgress(ctx, req.Params)
+				return
+			}
+			ctx = context.WithoutCancel(ctx)
+			b.vs.progressThrottle.forward(frontSession, req.Params, func() {
+				_ = frontSession.NotifyProgress(ctx, req.Params)
+			})
+		}
+		opts.ResourceUpdatedHandler = func(ctx context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			b.vs.forwardResourceUpdated(ctx, frontSession, b.variantID, req.Params)
+		}
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "variant-proxy-client", Version: "1.0.0"}, opts)
+	creds := b.credentials(frontSession != nil)
+	if rr, ok := RankingRequestFromContext(ctx); ok && creds != nil && frontSession != nil {
+		// Initialize the connection with the client's token.
+		tok, err := creds.tokenFor(ctx, bearerToken(rr.Header), rr.TokenInfo)
+		if err != nil {
+			return nil, err
+		}
+		ctx = context.WithValue(ctx, backendTokenKey{}, tok)
+	}
+	rs := &remoteSession{creds: creds, dial: func(ctx context.Context) (*mcp.ClientSession, error) {
+		t, err := b.transport(creds)
+		if err != nil {
+			return nil, err
+		}
+		cs, err := client.Connect(ctx, t, nil)
+		if err != nil {
+			return nil, fmt.Errorf("variants: connecting to remote variant %q: %w", b.variantID, err)
+		}
+		if frontSession != nil {
+			// As for in-memory variants, the front session filters logs.
+			_ = cs.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: b.vs.backendLogLevel(b.variantID)})
+		}
+		return cs, nil
+	}}
+	if _, err := rs.session(ctx); err != nil {
+		return nil, err
+	}
+	return &innerConnection{
+		backendSession: &backendSession{variantID: b.variantID, remote: rs},
+		cleanupFn:      rs.close,
+	}, nil
+}
+
+// probe connects to the variant's server to discover its initialize
+// result.
+func (b *remoteBackend) probe(ctx context.Context) (*mcp.InitializeResult, error) {
+	var res *mcp.InitializeResult
+	err := b.withProbeSession(ctx, func(cs *mcp.ClientSession) error {
+		res = cs.InitializeResult()
+		return nil
+	})
+	return res, err
+}
+
+// tools connects to the variant's server to list its tools.
+func (b *remoteBackend) tools(ctx context.Context) ([]*mcp.Tool, error) {
+	var tools []*mcp.Tool
+	err := b.withProbeSession(ctx, func(cs *mcp.ClientSession) error {
+		for tool, err := range cs.Tools(ctx, nil) {
+			if err != nil {
+				return err
+			}
+			tools = append(tools, tool)
+		}
+		return nil
+	})
+	return tools, err
+}
+
+// withProbeSession connects a throwaway client to the variant's server,
+// calls fn with it, and closes the connection.
+func (b *remoteBackend) withProbeSession(ctx context.Context, fn func(cs *mcp.ClientSession) error) error {
+	c := mcp.NewClient(&mcp.Implementation{Name: "cap-probe", Version: "1.0.0"}, nil)
+	t, err := b.transport(b.credentials(false))
+	if err != nil {
+		return err
+	}
+	cs, err := c.Connect(ctx, t, nil)
+	if err != nil {
+		return fmt.Errorf("variants: connecting to remote variant %q: %w", b.variantID, err)
+	}
+	defer cs.Close()
+	return fn(cs)
+}
+
+// watch connects a long-lived client to the variant's server for its
+// list-changed notifications.
+func (b *remoteBackend) watch(ctx context.Context, changed func()) (*mcp.ClientSession, func(), error) {
+	c := mcp.NewClient(&mcp.Implementation{Name: "catalog-watcher", Version: "1.0.0"}, &mcp.ClientOptions{
+		ToolListChangedHandler:     func(context.Context, *mcp.ToolListChangedRequest) { changed() },
+		PromptListChangedHandler:   func(context.Context, *mcp.PromptListChangedRequest) { changed() },
+		ResourceListChangedHandler: func(context.Context, *mcp.ResourceListChangedRequest) { changed() },
+	})
+	t, err := b.transport(b.credentials(false))
+	if err != nil {
+		return nil, nil, err
+	}
+	cs, err := c.Connect(ctx, t, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("variants: connecting to remote variant %q: %w", b.variantID, err)
+	}
+	return cs, func() { cs.Close() }, nil
+}
+
+// close is a no-op for remote backends: their connections belong to
+// sessions.
+func (b *remoteBackend) close() error {
+	return nil
+}
+
+func (b *remoteBackend) kind() BackendKind {
+	return BackendRemote
+}
+
+// remoteSession is a client connection to a remote variant that is
+// re-established on demand after it drops. The remote server forgets the
+// resource subscriptions of a dropped connection, so they are renewed on
+// the new one.
+type remoteSession struct {
+	dial  func(context.Context) (*mcp.ClientSession, error)
+	creds *backendCredentials // non-nil with Server.WithTokenExchange
+
+	mu     sync.Mutex
+	cs     *mcp.ClientSession // nil while disconnected
+	closed bool
+	subs   map[string]*mcp.SubscribeParams // by URI
+}
+
+// session returns the current connection, connecting if there is none.
+func (r *remoteSession) session(ctx context.Context) (*mcp.ClientSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil, mcp.ErrConnectionClosed
+	}
+	if r.cs != nil {
+		return r.cs, nil
+	}
+	cs, err := r.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Renew subscriptions made on earlier connections. Failures are not
+	// reported, as for resource updates in general.
+	for _, params := range r.subs {
+		_ = cs.Subscribe(ctx, params)
+	}
+	r.cs = cs
+	go func() {
+		cs.Wait()
+		r.drop(cs)
+	}()
+	return cs, nil
+}
+
+// drop forgets cs if it is the current connection, so that the next
+// request reconnects.
+func (r *remoteSession) drop(cs *mcp.ClientSession) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cs == cs {
+		r.cs = nil
+	}
+}
+
+// close closes the connection for good.
+func (r *remoteSession) close() {
+	r.mu.Lock()
+	cs := r.cs
+	r.cs, r.closed = nil, true
+	r.mu.Unlock()
+	if cs != nil {
+		cs.Close()
+	}
+}
+
+// call sends a request routed to the variant over the connection. The
+// request's params, already rewritten by the dispatcher, are sent as is.
+func (r *remoteSession) call(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+	cs, err := r.session(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := callRemote(ctx, cs, method, req)
+	if errors.Is(err, mcp.ErrConnectionClosed) {
+		r.drop(cs)
+	}
+	if err == nil {
+		r.noteSubscription(req)
+	}
+	return res, err
+}
+
+// noteSubscription records a successful subscribe or unsubscribe request,
+// for renewal on reconnection.
+func (r *remoteSession) noteSubscription(req mcp.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch req := req.(type) {
+	case *mcp.SubscribeRequest:
+		if req.Params != nil {
+			if r.subs == nil {
+				r.subs = make(map[string]*mcp.SubscribeParams)
+			}
+			r.subs[req.Params.URI] = req.Params
+		}
+	case *mcp.UnsubscribeRequest:
+		if req.Params != nil {
+			delete(r.subs, req.Params.URI)
+		}
+	}
+}
+
+// callRemote sends req with the client method for method.
+func callRemote(ctx context.Context, cs *mcp.ClientSession, method string, req mcp.Request) (mcp.Result, error) {
+	switch req := req.(type) {
+	case *mcp.ListToolsRequest:
+		return remoteResult(cs.ListTools(ctx, req.Params))
+	case *mcp.CallToolRequest:
+		var params *mcp.CallToolParams
+		if p := req.Params; p != nil {
+			params = &mcp.CallToolParams{Meta: p.Meta, Name: p.Name}
+			if len(p.Arguments) > 0 {
+				params.Arguments = json.RawMessage(p.Arguments)
+			}
+		}
+		return remoteResult(cs.CallTool(ctx, params))
+	case *mcp.ListPromptsRequest:
+		return remoteResult(cs.ListPrompts(ctx, req.Params))
+	case *mcp.GetPromptRequest:
+		return remoteResult(cs.GetPrompt(ctx, req.Params))
+	case *mcp.ListResourcesRequest:
+		return remoteResult(cs.ListResources(ctx, req.Params))
+	case *mcp.ListResourceTemplatesRequest:
+		return remoteResult(cs.ListResourceTemplates(ctx, req.Params))
+	case *mcp.ReadResourceRequest:
+		return remoteResult(cs.ReadResource(ctx, req.Params))
+	case *mcp.CompleteRequest:
+		return remoteResult(cs.Complete(ctx, req.Params))
+	case *mcp.SubscribeRequest:
+		return emptyResult(cs.Subscribe(ctx, req.Params))
+	case *mcp.UnsubscribeRequest:
+		return emptyResult(cs.Unsubscribe(ctx, req.Params))
+	}
+	return nil, &jsonrpc.Error{
+		Code:    jsonrpc.CodeMethodNotFound,
+		Message: fmt.Sprintf("variants: %s cannot be sent to remote variants", method),
+	}
+}
+
+// remoteResult converts the result of a client method to an mcp.Result,
+// keeping it a nil interface on failure.
+func remoteResult[R mcp.Result](res R, err error) (mcp.Result, error) {
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// emptyResult returns the result of a client method without one, keeping
+// it a nil interface on failure.
+func emptyResult(err error) (mcp.Result, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &remoteEmptyResult{}, nil
+}
+
+// remoteEmptyResult is the result of remote methods without one, such as
+// resources/subscribe, which marshals as {} like the SDK's own empty result:
+// that type is unexported, and a nil result cannot be sent. The embedded
+// Result only satisfies the interface.
+type remoteEmptyResult struct {
+	mcp.Result `json:"-"`
+}
+
+func (*remoteEmptyResult) GetMeta() map[string]any { return nil }
+func (*remoteEmptyResult) SetMeta(map[string]any)  {}
@@ -0,0 +1,190 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveRemote serves server over streamable HTTP, as a variant's own
+// service would, and returns its URL. The returned function replaces the
+// served handler with a fresh one, losing all sessions, as a restart of the
+// service would.
+func serveRemote(t *testing.T, server *mcp.Server) (string, func()) {
+	t.Helper()
+	var h atomic.Pointer[http.Handler]
+	restart := func() {
+		var handler http.Handler = mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+		h.Store(&handler)
+	}
+	restart()
+	httpSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		(*h.Load()).ServeHTTP(w, r)
+	}))
+	t.Cleanup(httpSrv.Close)
+	return httpSrv.URL, restart
+}
+
+func TestRemoteVariant(t *testing.T) {
+	coding, _ := newTestServers()
+	endpoint, _ := serveRemote(t, coding)
+	_, compact := newTestServers()
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithRemoteVariant(ServerVariant{ID: "coding", Description: "Remote coding"}, endpoint, 0).
+		WithVariant(ServerVariant{ID: "compact", Description: "Local compact"}, compact, 1)
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	tools, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"analyze_code", "refactor"}, toolNames(tools.Tools))
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "analyze_code",
+		Arguments: map[string]any{"code": "x := 1", "language": "go"},
+	})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+	assert.JSONEq(t, `{"issues": ["unused variable"]}`, mustJSON(t, res.StructuredContent))
+
+	tools, err = session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: "compact"}})
+	require.NoError(t, err)
+	assert.Contains(t, toolNames(tools.Tools), "summarize")
+}
+
+func TestRemoteVariant_Notifications(t *testing.T) {
+	inner := newNotifyVariantServer().variants[0].backend.(*inMemoryBackend).server
+	endpoint, _ := serveRemote(t, inner)
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithRemoteVariant(ServerVariant{ID: "default"}, endpoint, 0)
+	collector := &notificationCollector{}
+	session := connectTestClient(t, vs, collector.clientOptions())
+	ctx := context.Background()
+	require.NoError(t, session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "debug"}))
+
+	_, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      mcp.Meta{"progressToken": "tok"},
+		Name:      "notify",
+		Arguments: map[string]any{"client_id": "remote", "count": notifyCount},
+	})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return collector.progressCount() == notifyCount && collector.logCount() == notifyCount
+	}, 2*time.Second, 10*time.Millisecond)
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	assert.Equal(t, "tok", collector.progress[0].ProgressToken)
+}
+
+func TestRemoteVariant_Subscribe(t *testing.T) {
+	docs := newDocsServer("remote")
+	endpoint, _ := serveRemote(t, docs)
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithRemoteVariant(ServerVariant{ID: "docs"}, endpoint, 0)
+	updates := make(chan *mcp.ResourceUpdatedNotificationRequest, 1)
+	session := connectTestClient(t, vs, &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			updates <- req
+		},
+	})
+	ctx := context.Background()
+
+	require.NoError(t, session.Subscribe(ctx, &mcp.SubscribeParams{URI: "docs://guide"}))
+	require.NoError(t, docs.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: "docs://guide"}))
+	select {
+	case req := <-updates:
+		assert.Equal(t, "docs://guide", req.Params.URI)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for resource updated notification")
+	}
+	require.NoError(t, session.Unsubscribe(ctx, &mcp.UnsubscribeParams{URI: "docs://guide"}))
+}
+
+func TestRemoteVariant_SubscribeReconnect(t *testing.T) {
+	docs := newDocsServer("remote")
+	endpoint, restart := serveRemote(t, docs)
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithRemoteVariant(ServerVariant{ID: "docs"}, endpoint, 0)
+	updates := make(chan *mcp.ResourceUpdatedNotificationRequest, 1)
+	session := connectTestClient(t, vs, &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			updates <- req
+		},
+	})
+	ctx := context.Background()
+	require.NoError(t, session.Subscribe(ctx, &mcp.SubscribeParams{URI: "docs://guide"}))
+
+	// The variant's service restarts and forgets the subscription, which
+	// is renewed when the router reconnects.
+	restart()
+	require.Eventually(t, func() bool {
+		_, err := session.ListResources(ctx, nil)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, docs.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: "docs://guide"}))
+	select {
+	case req := <-updates:
+		assert.Equal(t, "docs://guide", req.Params.URI)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for resource updated notification")
+	}
+}
+
+func TestRemoteVariant_Reconnect(t *testing.T) {
+	coding, _ := newTestServers()
+	endpoint, restart := serveRemote(t, coding)
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithRemoteVariant(ServerVariant{ID: "coding"}, endpoint, 0)
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	_, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+
+	// The variant's service restarts and forgets the session. The request
+	// that finds out fails; later ones reconnect.
+	restart()
+	require.Eventually(t, func() bool {
+		_, err := session.ListTools(ctx, nil)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRemoteVariant_Unreachable(t *testing.T) {
+	httpSrv := httptest.NewServer(http.NotFoundHandler())
+	endpoint := httpSrv.URL
+	httpSrv.Close()
+
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithRemoteVariant(ServerVariant{ID: "coding"}, endpoint, 0)
+	_, err := vs.NewRouter(nil)
+	assert.ErrorContains(t, err, `remote variant "coding"`)
+}
+
+func TestWithRemoteVariant_InvalidEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"", "localhost:8080", "ftp://host/mcp", "http://"} {
+		assert.Panics(t, func() {
+			NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+				WithRemoteVariant(ServerVariant{ID: "v"}, endpoint, 0)
+		}, endpoint)
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDocsServer returns a server with a docs://guide resource whose text is
+// prefixed with name, a docs://{topic}/{section} template, and resource
+// subscriptions.
+func newDocsServer(name string) *mcp.Server {
+	srv := mcp.NewServer(&mcp.Implementation{Name: name, Version: "v1.0.0"}, &mcp.ServerOptions{
+		SubscribeHandler:   func(context.Context, *mcp.SubscribeRequest) error { return nil },
+		UnsubscribeHandler: func(context.Context, *mcp.UnsubscribeRequest) error { return nil },
+	})
+	read := func(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{
+			{URI: req.Params.URI, MIMEType: "text/markdown", Text: name + ": " + req.Params.URI},
+		}}, nil
+	}
+	srv.AddResource(&mcp.Resource{URI: "docs://guide", Name: "guide", MIMEType: "text/markdown"}, read)
+	srv.AddResourceTemplate(&mcp.ResourceTemplate{URITemplate: "docs://{topic}/{section}", Name: "section"}, read)
+	return srv
+}
+
+// TestIntegration_Resources verifies resource reads, templates and
+// subscriptions routed to variants through the proxy.
+func TestIntegration_Resources(t *testing.T) {
+	full, summaries := newDocsServer("full"), newDocsServer("summaries")
+	vs := NewServer(&mcp.Implementation{Name: "docs", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "full", Status: Stable}, full, 0).
+		WithVariant(ServerVariant{ID: "summaries", Status: Stable}, summaries, 1)
+
+	updates := make(chan *mcp.ResourceUpdatedNotificationRequest, 4)
+	session := connectTestClient(t, vs, &mcp.ClientOptions{
+		ResourceUpdatedHandler: func(_ context.Context, req *mcp.ResourceUpdatedNotificationRequest) {
+			updates <- req
+		},
+	})
+	ctx := context.Background()
+	caps := session.InitializeResult().Capabilities
+	require.NotNil(t, caps.Resources)
+	assert.True(t, caps.Resources.Subscribe)
+
+	selecting := func(id string) mcp.Meta { return mcp.Meta{metaKeyVariant: id} }
+
+	for _, id := range []string{"full", "summaries"} {
+		res, err := session.ReadResource(ctx, &mcp.ReadResourceParams{Meta: selecting(id), URI: "docs://guide"})
+		require.NoError(t, err)
+		require.Len(t, res.Contents, 1)
+		assert.Equal(t, id+": docs://guide", res.Contents[0].Text)
+
+		res, err = session.ReadResource(ctx, &mcp.ReadResourceParams{Meta: selecting(id), URI: "docs://api/auth"})
+		require.NoError(t, err)
+		assert.Equal(t, id+": docs://api/auth", res.Contents[0].Text)
+
+		templates, err := session.ListResourceTemplates(ctx, &mcp.ListResourceTemplatesParams{Meta: selecting(id)})
+		require.NoError(t, err)
+		require.Len(t, templates.ResourceTemplates, 1)
+		assert.Equal(t, "docs://{topic}/{section}", templates.ResourceTemplates[0].URITemplate)
+	}
+
+	// Subscriptions are scoped to the selected variant.
+	require.NoError(t, session.Subscribe(ctx, &mcp.SubscribeParams{Meta: selecting("summaries"), URI: "docs://guide"}))
+	require.NoError(t, full.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: "docs://guide"}))
+	require.NoError(t, summaries.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: "docs://guide"}))
+	select {
+	case req := <-updates:
+		assert.Equal(t, "docs://guide", req.Params.URI)
+		assert.Equal(t, "summaries", req.Params.Meta[metaKeyVariant])
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for resource updated notification")
+	}
+	select {
+	case req := <-updates:
+		t.Fatalf("unexpected notification from variant %v", req.Params.Meta[metaKeyVariant])
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, session.Unsubscribe(ctx, &mcp.UnsubscribeParams{Meta: selecting("summaries"), URI: "docs://guide"}))
+	require.NoError(t, summaries.ResourceUpdated(ctx, &mcp.ResourceUpdatedNotificationParams{URI: "docs://guide"}))
+	select {
+	case <-updates:
+		t.Fatal("unexpected notification after unsubscribe")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Unknown resources fail within the selected variant.
+	_, err := session.ReadResource(ctx, &mcp.ReadResourceParams{Meta: selecting("full"), URI: "other://x"})
+	var jErr *jsonrpc.Error
+	require.True(t, errors.As(err, &jErr))
+	assert.Equal(t, int64(mcp.CodeResourceNotFound), jErr.Code)
+	assert.JSONEq(t, `{"uri":"other://x","activeVariant":"full"}`, string(jErr.Data))
+}
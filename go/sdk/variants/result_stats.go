@@ -0,0 +1,209 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxResultStatsTools bounds the number of tools tracked per variant in
+// Stats, bounding its memory use when clients call arbitrary tool names.
+const maxResultStatsTools = 256
+
+// resultSizeBuckets are the upper bounds, in bytes, of the buckets of
+// ResultSizeStats.Histogram.
+var resultSizeBuckets = []int64{256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10}
+
+// Stats are the statistics of the results returned by the variants since
+// the server was created, so that server authors can verify quantitatively
+// that a variant meant for small context windows returns smaller results
+// than the verbose one. They are returned by [Server.Stats], are
+// JSON-serializable for export, and implement [slog.LogValuer] for logging:
+//
+//	logger.Info("variant results", "stats", vs.Stats())
+type Stats struct {
+	// Variants holds statistics per variant ID, for variants that returned
+	// tool results.
+	Variants map[string]VariantStats `json:"variants,omitempty"`
+}
+
+// VariantStats are the statistics of the results returned by a variant.
+type VariantStats struct {
+	// Results aggregates the variant's tool results.
+	Results ResultSizeStats `json:"results"`
+
+	// Tools aggregates the results per tool name. At most 256 tools are
+	// tracked; results of further tools count in Results only.
+	Tools map[string]ResultSizeStats `json:"tools,omitempty"`
+}
+
+// ResultSizeStats is the distribution of the sizes of tool results. Sizes
+// are those of the results' JSON encodings. Tokens are estimated at four
+// bytes per token, which is rough for any one tokenizer but suitable to
+// compare variants.
+type ResultSizeStats struct {
+	// Count is the number of results.
+	Count int64 `json:"count"`
+
+	// Bytes and Tokens are the total size and estimated tokens of the
+	// results.
+	Bytes  int64 `json:"bytes"`
+	Tokens int64 `json:"tokens"`
+
+	// MaxBytes is the size of the largest result.
+	MaxBytes int64 `json:"maxBytes"`
+
+	// Histogram counts the results by size, in buckets of at most 256
+	// bytes, 1, 4, 16, 64 and 256 KiB, and larger.
+	Histogram []int64 `json:"histogram"`
+}
+
+// MeanBytes returns the mean size of the results, or 0 if there are none.
+func (st ResultSizeStats) MeanBytes() int64 {
+	if st.Count == 0 {
+		return 0
+	}
+	return st.Bytes / st.Count
+}
+
+// MeanTokens returns the mean estimated tokens of the results, or 0 if
+// there are none.
+func (st ResultSizeStats) MeanTokens() int64 {
+	if st.Count == 0 {
+		return 0
+	}
+	return st.Tokens / st.Count
+}
+
+// add records a result of n bytes.
+func (st *ResultSizeStats) add(n int64) {
+	if st.Histogram == nil {
+		st.Histogram = make([]int64, len(resultSizeBuckets)+1)
+	}
+	st.Count++
+	st.Bytes += n
+	st.Tokens += estimateTokens(n)
+	st.MaxBytes = max(st.MaxBytes, n)
+	i := 0
+	for i < len(resultSizeBuckets) && n > resultSizeBuckets[i] {
+		i++
+	}
+	st.Histogram[i]++
+}
+
+// estimateTokens estimates the tokens of n bytes of JSON.
+func estimateTokens(n int64) int64 {
+	return (n + 3) / 4
+}
+
+// LogValue implements [slog.LogValuer], logging the count, mean size and
+// mean estimated tokens of each variant's and tool's results.
+func (st Stats) LogValue() slog.Value {
+	var attrs []slog.Attr
+	for _, id := range sortedKeys(st.Variants) {
+		vs := st.Variants[id]
+		group := resultSizeAttrs(vs.Results)
+		for _, name := range sortedKeys(vs.Tools) {
+			group = append(group, slog.Group("tool."+name, resultSizeAttrs(vs.Tools[name])...))
+		}
+		attrs = append(attrs, slog.Group(id, group...))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func resultSizeAttrs(st ResultSizeStats) []any {
+	return []any{
+		slog.Int64("count", st.Count),
+		slog.Int64("meanBytes", st.MeanBytes()),
+		slog.Int64("meanTokens", st.MeanTokens()),
+		slog.Int64("maxBytes", st.MaxBytes),
+	}
+}
+
+// WithResultAccounting enables or disables the accounting of the sizes of
+// tool results per variant and per tool, reported by Stats. Accounting
+// encodes each result once more to measure it. Results are measured as
+// returned by the dispatch interceptors (see WithDispatchInterceptor);
+// failed calls are not counted. Disabled by default.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithResultAccounting(enabled bool) *Server {
+	s.resultAccounting = enabled
+	return s
+}
+
+// Stats returns the statistics of the results returned by the variants so
+// far, which are empty unless WithResultAccounting is enabled. Statistics
+// are kept for the server's lifetime.
+func (s *Server) Stats() Stats {
+	return s.resultStats.snapshot()
+}
+
+// resultStatsCollector accumulates Stats. The zero value is ready to use.
+type resultStatsCollector struct {
+	mu    sync.Mutex
+	stats Stats
+}
+
+// recordResult adds a variant's result to a tools/call request.
+func (s *Server) recordResult(variantID string, req mcp.Request, res mcp.Result) {
+	call, _ := req.(*mcp.CallToolRequest)
+	if isNilInterface(res) || call == nil || call.Params == nil {
+		return
+	}
+	data, err := json.Marshal(res)
+	if err != nil {
+		return
+	}
+	s.resultStats.record(variantID, call.Params.Name, int64(len(data)))
+}
+
+// record adds a result of n bytes of a variant's tool.
+func (c *resultStatsCollector) record(variantID, tool string, n int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stats.Variants == nil {
+		c.stats.Variants = make(map[string]VariantStats)
+	}
+	vs := c.stats.Variants[variantID]
+	vs.Results.add(n)
+	ts, ok := vs.Tools[tool]
+	if ok || len(vs.Tools) < maxResultStatsTools {
+		if vs.Tools == nil {
+			vs.Tools = make(map[string]ResultSizeStats)
+		}
+		ts.add(n)
+		vs.Tools[tool] = ts
+	}
+	c.stats.Variants[variantID] = vs
+}
+
+// snapshot returns a copy of the statistics.
+func (c *resultStatsCollector) snapshot() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st := Stats{Variants: make(map[string]VariantStats, len(c.stats.Variants))}
+	for id, vs := range c.stats.Variants {
+		vs.Results = vs.Results.clone()
+		tools := make(map[string]ResultSizeStats, len(vs.Tools))
+		for name, ts := range vs.Tools {
+			tools[name] = ts.clone()
+		}
+		vs.Tools = tools
+		st.Variants[id] = vs
+	}
+	return st
+}
+
+// clone returns a copy of st that does not share its histogram.
+func (st ResultSizeStats) clone() ResultSizeStats {
+	st.Histogram = slices.Clone(st.Histogram)
+	return st
+}
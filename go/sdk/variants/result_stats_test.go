@@ -0,0 +1,107 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSearchServer returns a server whose search tool returns text of the
+// given length.
+func newSearchServer(name string, length int) *mcp.Server {
+	srv := mcp.NewServer(&mcp.Implementation{Name: name, Version: "v1.0.0"}, nil)
+	srv.AddTool(&mcp.Tool{Name: "search", InputSchema: map[string]any{"type": "object"}}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: strings.Repeat("x", length)}}}, nil
+	})
+	return srv
+}
+
+func TestStats(t *testing.T) {
+	vs := NewServer(&mcp.Implementation{Name: "search", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "verbose", Status: Stable}, newSearchServer("verbose", 2000), 0).
+		WithVariant(ServerVariant{ID: "compact", Status: Stable}, newSearchServer("compact", 100), 1).
+		WithResultAccounting(true)
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	call := func(variantID string) int64 {
+		t.Helper()
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Meta: mcp.Meta{metaKeyVariant: variantID}, Name: "search"})
+		require.NoError(t, err)
+		res.Meta = nil // as returned by the variant
+		data, err := json.Marshal(res)
+		require.NoError(t, err)
+		return int64(len(data))
+	}
+	verbose := call("verbose")
+	call("verbose")
+	compact := call("compact")
+	_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "missing"})
+	require.Error(t, err, "failed calls are not counted")
+
+	st := vs.Stats()
+	require.Len(t, st.Variants, 2)
+	v := st.Variants["verbose"]
+	assert.Equal(t, ResultSizeStats{
+		Count:     2,
+		Bytes:     2 * verbose,
+		Tokens:    2 * estimateTokens(verbose),
+		MaxBytes:  verbose,
+		Histogram: []int64{0, 0, 2, 0, 0, 0, 0},
+	}, v.Results)
+	assert.Equal(t, v.Results, v.Tools["search"])
+	c := st.Variants["compact"]
+	assert.Equal(t, compact, c.Results.MeanBytes())
+	assert.Equal(t, []int64{1, 0, 0, 0, 0, 0, 0}, c.Results.Histogram)
+	assert.Less(t, c.Results.MeanTokens(), v.Results.MeanTokens())
+
+	// Snapshots are copies.
+	st.Variants["compact"].Results.Histogram[0] = 42
+	assert.Equal(t, int64(1), vs.Stats().Variants["compact"].Results.Histogram[0])
+}
+
+func TestStats_Disabled(t *testing.T) {
+	vs := newTestVariantServer()
+	session := connectTestClient(t, vs, nil)
+	_, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "analyze_code",
+		Arguments: map[string]any{"code": "fmt.Println(x)", "language": "go"},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, vs.Stats().Variants)
+}
+
+func TestStats_ToolLimit(t *testing.T) {
+	var c resultStatsCollector
+	for i := range maxResultStatsTools + 1 {
+		c.record("v", strings.Repeat("t", i+1), 10)
+	}
+	st := c.snapshot().Variants["v"]
+	assert.Equal(t, int64(maxResultStatsTools+1), st.Results.Count)
+	assert.Len(t, st.Tools, maxResultStatsTools)
+}
+
+func TestStats_LogValue(t *testing.T) {
+	var c resultStatsCollector
+	c.record("compact", "search", 100)
+	c.record("compact", "search", 300)
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("variant results", "stats", c.snapshot())
+	out := buf.String()
+	assert.Contains(t, out, "stats.compact.count=2")
+	assert.Contains(t, out, "stats.compact.meanBytes=200")
+	assert.Contains(t, out, "stats.compact.meanTokens=50")
+	assert.Contains(t, out, "stats.compact.tool.search.maxBytes=300")
+}
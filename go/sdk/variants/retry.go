@@ -0,0 +1,209 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// BackendKind identifies how a variant connects to its server, for
+// configuration that depends on the transport, such as retries (see
+// [Server.WithRetryPolicy]).
+type BackendKind string
+
+const (
+	// BackendInMemory is a variant registered with WithVariant.
+	BackendInMemory BackendKind = "inMemory"
+	// BackendHTTP is a variant registered with WithHTTPVariant.
+	BackendHTTP BackendKind = "http"
+	// BackendRemote is a variant registered with WithRemoteVariant.
+	BackendRemote BackendKind = "remote"
+)
+
+// RetryPolicy configures retries of requests dispatched to variants that
+// fail transiently.
+type RetryPolicy struct {
+	// MaxAttempts is the number of attempts of a request, including the
+	// first. Zero or one means no retries.
+	MaxAttempts int
+
+	// InitialBackoff is the backoff before the first retry; it doubles
+	// with each further retry, up to MaxBackoff. Each backoff is jittered
+	// down by up to half. Zero means 50ms and 2s respectively.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// Retryable reports whether a failure is transient. Nil means the
+	// connection dropped ([mcp.ErrConnectionClosed] or an unexpected EOF),
+	// or the variant was overloaded ([CodeVariantOverloaded]). A dropped
+	// connection is replaced by a new one before the retry.
+	Retryable func(error) bool
+}
+
+// WithRetryPolicy retries requests to variants of the given backend kind
+// that fail transiently, with jittered exponential backoff. Only
+// idempotent requests are retried: lists, resources/read, prompts/get and
+// completion/complete, and tools/call of tools annotated read-only or
+// idempotent (after any WithAnnotationOverride). Other tool calls are never
+// retried, since a failed call may still have had effects.
+//
+// Retries happen within the request's dispatch timeout (see
+// WithDispatchTimeouts), and stop when the request is canceled. Each retry
+// emits an EventDispatchRetried. By default requests are not retried.
+//
+// WithRetryPolicy panics if p has negative fields.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithRetryPolicy(kind BackendKind, p RetryPolicy) *Server {
+	if p.MaxAttempts < 0 || p.InitialBackoff < 0 || p.MaxBackoff < 0 {
+		panic("variants: negative retry policy")
+	}
+	if s.retryPolicies == nil {
+		s.retryPolicies = make(map[BackendKind]RetryPolicy)
+	}
+	s.retryPolicies[kind] = p
+	return s
+}
+
+// retryPolicy returns the retry policy of a variant's backend, and whether
+// it retries at all.
+func (s *Server) retryPolicy(variantID string) (RetryPolicy, bool) {
+	i, ok := s.variantIndex[variantID]
+	if !ok {
+		return RetryPolicy{}, false
+	}
+	p := s.retryPolicies[s.variants[i].backend.kind()]
+	return p, p.MaxAttempts > 1
+}
+
+// retryable reports whether err is transient under the policy.
+func (p RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	if connectionDropped(err) {
+		return true
+	}
+	var jErr *jsonrpc.Error
+	return errors.As(err, &jErr) && jErr.Code == CodeVariantOverloaded
+}
+
+// backoff returns the jittered backoff before the given retry, counting
+// from 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	initial, limit := p.InitialBackoff, p.MaxBackoff
+	if initial == 0 {
+		initial = 50 * time.Millisecond
+	}
+	if limit == 0 {
+		limit = 2 * time.Second
+	}
+	d := initial
+	for i := 1; i < retry && d < limit; i++ {
+		d *= 2
+	}
+	d = min(d, limit)
+	return d - rand.N(d/2+1)
+}
+
+// idempotentMethod reports whether retrying method cannot have effects
+// beyond those of a single request.
+func idempotentMethod(method string) bool {
+	switch method {
+	case "tools/list", "resources/list", "resources/templates/list", "prompts/list",
+		"resources/read", "prompts/get", "completion/complete":
+		return true
+	}
+	return false
+}
+
+// withRetries calls attempt with conn, retrying transient failures of
+// idempotent requests under the variant's retry policy. conn is also used to
+// look up the annotations of called tools. A connection that dropped is
+// evicted whether or not the request is retried; a retry dials a new one.
+func (d *dispatcher) withRetries(ctx context.Context, conn *innerConnection, method string, req mcp.Request, sid string, attempt func(context.Context, *innerConnection) (mcp.Result, error)) (mcp.Result, error) {
+	variantID := conn.backendSession.variantID
+	p, ok := d.server.retryPolicy(variantID)
+	if !ok {
+		p.MaxAttempts = 1
+	}
+	idempotent := idempotentMethod(method)
+	checked := idempotent
+	for n := 1; ; n++ {
+		result, err := attempt(ctx, conn)
+		evicted := err != nil && connectionDropped(err) && d.evict(conn)
+		if err == nil || n >= p.MaxAttempts || ctx.Err() != nil || !p.retryable(err) {
+			return result, err
+		}
+		if evicted {
+			c, dialErr := d.connection(ctx, variantID)
+			if dialErr != nil {
+				return nil, dialErr
+			}
+			conn = c
+		}
+		if !checked {
+			// Tool annotations are only looked up once a call has failed.
+			idempotent, checked = d.idempotentTool(ctx, conn, req), true
+		}
+		if !idempotent {
+			return result, err
+		}
+		d.server.emit(ctx, Event{Kind: EventDispatchRetried, SessionID: sid, VariantID: variantID, Method: method, Err: err})
+		t := time.NewTimer(p.backoff(n))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return result, err
+		case <-t.C:
+		}
+	}
+}
+
+// connectionDropped reports whether err means that the connection to a
+// variant dropped.
+func connectionDropped(err error) bool {
+	return errors.Is(err, mcp.ErrConnectionClosed) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// evict removes a connection that dropped from the session's connections
+// and closes it, so that the next request for its variant dials a new one,
+// reporting whether it did. Pooled connections (see WithStatelessPool) are
+// managed by their pool and left alone.
+func (d *dispatcher) evict(conn *innerConnection) bool {
+	variantID := conn.backendSession.variantID
+	if d.pools[variantID] != nil {
+		return false
+	}
+	d.connMu.Lock()
+	if d.connections[variantID] == conn {
+		delete(d.connections, variantID)
+	}
+	d.connMu.Unlock()
+	conn.close()
+	return true
+}
+
+// idempotentTool reports whether the tool called by req is annotated
+// read-only or idempotent.
+func (d *dispatcher) idempotentTool(ctx context.Context, conn *innerConnection, req mcp.Request) bool {
+	params, _ := req.GetParams().(*mcp.CallToolParamsRaw)
+	if params == nil {
+		return false
+	}
+	tool, err := d.findTool(ctx, conn, params.Name)
+	if err != nil || tool == nil {
+		return false
+	}
+	a := d.server.effectiveAnnotations(conn.backendSession.variantID, tool)
+	return a != nil && (a.ReadOnlyHint || a.IdempotentHint)
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyInterceptor fails the first failures dispatches of each method with
+// a dropped connection, counting dispatches by method.
+type flakyInterceptor struct {
+	mu       sync.Mutex
+	failures int
+	calls    map[string]int
+}
+
+func (f *flakyInterceptor) intercept(ctx context.Context, info DispatchInfo, next DispatchHandler) (mcp.Result, error) {
+	f.mu.Lock()
+	f.calls[info.Method]++
+	fail := f.calls[info.Method] <= f.failures
+	f.mu.Unlock()
+	if fail {
+		return nil, mcp.ErrConnectionClosed
+	}
+	return next(ctx)
+}
+
+func (f *flakyInterceptor) count(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[method]
+}
+
+func newRetryVariantServer(failures int) (*Server, *flakyInterceptor) {
+	s := mcp.NewServer(&mcp.Implementation{Name: "orders", Version: "v1.0.0"}, nil)
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, in struct{}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil, nil
+	}
+	mcp.AddTool(s, &mcp.Tool{Name: "get_order", Annotations: &mcp.ToolAnnotations{IdempotentHint: true}}, handler)
+	mcp.AddTool(s, &mcp.Tool{Name: "place_order"}, handler)
+
+	f := &flakyInterceptor{failures: failures, calls: make(map[string]int)}
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "orders"}, s, 0).
+		WithDispatchInterceptor(f.intercept).
+		WithRetryPolicy(BackendInMemory, RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	return vs, f
+}
+
+func TestRetryPolicy(t *testing.T) {
+	vs, f := newRetryVariantServer(2)
+	var mu sync.Mutex
+	var retried []string
+	vs.WithEventHandler(func(ctx context.Context, e Event) {
+		if e.Kind == EventDispatchRetried {
+			mu.Lock()
+			retried = append(retried, e.Method)
+			mu.Unlock()
+		}
+	})
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	// Lists are retried.
+	_, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, f.count("tools/list"))
+
+	// Idempotent tools are retried.
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "get_order"})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+	assert.Equal(t, 3, f.count("tools/call"))
+
+	mu.Lock()
+	assert.Equal(t, []string{"tools/list", "tools/list", "tools/call", "tools/call"}, retried)
+	mu.Unlock()
+}
+
+func TestRetryPolicy_NonIdempotentTool(t *testing.T) {
+	vs, f := newRetryVariantServer(1)
+	session := connectTestClient(t, vs, nil)
+
+	_, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "place_order"})
+	require.Error(t, err)
+	assert.Equal(t, 1, f.count("tools/call"))
+}
+
+func TestRetryPolicy_Exhausted(t *testing.T) {
+	vs, f := newRetryVariantServer(5)
+	session := connectTestClient(t, vs, nil)
+
+	_, err := session.ListTools(context.Background(), nil)
+	require.Error(t, err)
+	assert.Equal(t, 3, f.count("tools/list"))
+}
+
+func TestRetryPolicy_OtherBackendKind(t *testing.T) {
+	vs, f := newRetryVariantServer(1)
+	vs.retryPolicies = nil
+	vs.WithRetryPolicy(BackendHTTP, RetryPolicy{MaxAttempts: 3})
+	session := connectTestClient(t, vs, nil)
+
+	_, err := session.ListTools(context.Background(), nil)
+	require.Error(t, err)
+	assert.Equal(t, 1, f.count("tools/list"))
+}
+
+// sessionConnection returns the connection of the session of vs to a
+// variant.
+func sessionConnection(t *testing.T, vs *Server, variantID string) *innerConnection {
+	t.Helper()
+	var d *dispatcher
+	vs.rangeSessions(func(_ *mcp.ServerSession, sd *dispatcher) bool {
+		d = sd
+		return false
+	})
+	require.NotNil(t, d)
+	d.connMu.RLock()
+	defer d.connMu.RUnlock()
+	conn := d.connections[variantID]
+	require.NotNil(t, conn)
+	return conn
+}
+
+func TestRetryPolicy_ClosedConnection(t *testing.T) {
+	coding, _ := newTestServers()
+	endpoint, _ := serveRemote(t, coding)
+	newServer := func() *Server {
+		return NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+			WithRemoteVariant(ServerVariant{ID: "coding"}, endpoint, 0)
+	}
+	ctx := context.Background()
+
+	t.Run("retried on a new connection", func(t *testing.T) {
+		vs := newServer().WithRetryPolicy(BackendRemote, RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond})
+		session := connectTestClient(t, vs, nil)
+		closed := sessionConnection(t, vs, "coding")
+		closed.close()
+
+		tools, err := session.ListTools(ctx, nil)
+		require.NoError(t, err)
+		assert.Contains(t, toolNames(tools.Tools), "analyze_code")
+		assert.NotSame(t, closed, sessionConnection(t, vs, "coding"))
+	})
+
+	t.Run("evicted without retries", func(t *testing.T) {
+		vs := newServer()
+		session := connectTestClient(t, vs, nil)
+		sessionConnection(t, vs, "coding").close()
+
+		_, err := session.ListTools(ctx, nil)
+		require.ErrorContains(t, err, "connection closed")
+		_, err = session.ListTools(ctx, nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	for retry, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 10: 300 * time.Millisecond} {
+		for range 20 {
+			d := p.backoff(retry)
+			assert.LessOrEqual(t, d, want)
+			assert.GreaterOrEqual(t, d, want/2)
+		}
+	}
+
+	assert.True(t, p.retryable(mcp.ErrConnectionClosed))
+	assert.True(t, p.retryable(&jsonrpc.Error{Code: CodeVariantOverloaded}))
+	assert.False(t, p.retryable(errors.New("boom")))
+	assert.Panics(t, func() {
+		NewServer(&mcp.Implementation{Name: "s"}).WithRetryPolicy(BackendInMemory, RetryPolicy{MaxAttempts: -1})
+	})
+}
@@ -0,0 +1,286 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// VariantRouter is the routing component of a [Server]: it sets up
+// per-session connections to the variants at initialize and routes
+// variant-scoped requests to them by the _meta variant selection. The
+// built-in front server used by [Server.Run] and the HTTP handlers is an
+// [mcp.Server] with a VariantRouter installed; integrators can install one
+// into their own server or middleware chain, or call its routing methods
+// from a custom gateway.
+//
+// A Server may back several routers, e.g. one per transport. Notifications
+// from the variants are forwarded through the server the first router was
+// installed in, addressed to the receiving client's session. Closing the
+// Server shuts all its routers down.
+type VariantRouter struct {
+	server    *Server
+	transport TransportKind
+	caps      *mcp.ServerCapabilities
+
+	sessions     sync.Map      // *mcp.ServerSession -> *sessionState
+	sessionCount atomic.Int64  // number of stateful sessions; see SessionLimits.MaxSessions
+	shared       *sessionState // non-nil in stateless mode
+}
+
+// RouterOptions configure a VariantRouter.
+type RouterOptions struct {
+	// Stateless shares a single set of variant connections across all
+	// requests instead of creating connections per session at initialize.
+	// Hint updates and deprecation confirmation are unavailable in
+	// stateless mode.
+	Stateless bool
+
+	// Transport is how the router's sessions are served, reported to
+	// ranking functions via [RankingRequest]. Zero means TransportOther.
+	Transport TransportKind
+}
+
+// NewRouter validates the server's configuration, probes the variants'
+// capabilities, and returns a router for them. opts may be nil.
+//
+// Creating the router starts serving: it fails with [ErrServerClosed] if the
+// server has been closed. A Server may back several routers.
+func (s *Server) NewRouter(opts *RouterOptions) (*VariantRouter, error) {
+	if opts == nil {
+		opts = &RouterOptions{}
+	}
+	if len(s.variants) == 0 {
+		return nil, errors.New("variants: no variants registered")
+	}
+	if err := s.validateVariants(); err != nil {
+		return nil, err
+	}
+	if s.defaultVariantID != "" && !s.hasVariant(s.defaultVariantID) {
+		return nil, fmt.Errorf("variants: default variant %q is not registered", s.defaultVariantID)
+	}
+	if err := s.validateDescriptionTemplates(); err != nil {
+		return nil, err
+	}
+	if err := s.validateAnnotationOverrides(); err != nil {
+		return nil, err
+	}
+	if err := s.validateToolTranslations(); err != nil {
+		return nil, err
+	}
+	if err := s.validateDispatchTimeouts(); err != nil {
+		return nil, err
+	}
+	if err := s.validateLogLevels(); err != nil {
+		return nil, err
+	}
+	if err := s.validateVariantGroups(); err != nil {
+		return nil, err
+	}
+	if err := s.validateMethodRoutes(); err != nil {
+		return nil, err
+	}
+	if err := s.validateHedging(); err != nil {
+		return nil, err
+	}
+	if err := s.validateBackendTLS(); err != nil {
+		return nil, err
+	}
+	if err := s.validateEligibility(); err != nil {
+		return nil, err
+	}
+	if err := s.validateWarmups(); err != nil {
+		return nil, err
+	}
+
+	var found []discovery
+	if s.startupReporting {
+		found = make([]discovery, len(s.variants))
+	}
+	began := time.Now()
+	caps, instructions, pending, err := s.discoverCapabilities(found)
+	took := time.Since(began)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &VariantRouter{
+		server:    s,
+		transport: opts.Transport,
+		caps:      caps,
+	}
+	if r.transport == "" {
+		r.transport = TransportOther
+	}
+	if err := s.start(r); err != nil {
+		return nil, err
+	}
+
+	// In stateless mode, create shared connections once and reuse them
+	// across all requests (no per-session state). Close releases them.
+	if opts.Stateless {
+		r.shared, err = s.createSessionState(context.Background(), nil, nil)
+		if err != nil {
+			s.abortStart(r)
+			return nil, err
+		}
+	}
+
+	// The variants' instructions, catalog index, startup report and
+	// background startup retries are server-wide: the first router sets
+	// them up for any others.
+	s.launch.Do(func() {
+		s.instructions = instructions
+		if s.catalogCounts && s.ownership == nil {
+			s.ownership = &ownershipIndex{}
+		}
+		if s.ownership != nil {
+			s.ownership.start(context.Background(), s)
+		}
+		if found != nil {
+			s.buildStartupReport(context.Background(), caps, found, took)
+		}
+		if len(pending) > 0 {
+			ctx, cancel := context.WithCancel(context.Background())
+			s.mu.Lock()
+			s.stopStartup = cancel
+			s.mu.Unlock()
+			s.awaitBackends(ctx, pending)
+		}
+	})
+	return r, nil
+}
+
+// Capabilities returns the union of the variants' capabilities, for the
+// server the router is installed in to advertise.
+func (r *VariantRouter) Capabilities() *mcp.ServerCapabilities {
+	return r.caps
+}
+
+// Install adds the router's middleware to server and forwards notifications
+// and server-to-client requests from the variants (progress, logging,
+// elicitation, ...) through it. server should advertise Capabilities.
+func (r *VariantRouter) Install(server *mcp.Server) error {
+	server.AddReceivingMiddleware(r.Middleware())
+	server.AddSendingMiddleware(r.server.captureSendingMiddleware)
+
+	handler, err := captureSendingMethodHandler(server)
+	if err != nil {
+		return err
+	}
+	s := r.server
+	s.mu.Lock()
+	if s.frontSendingHandler == nil {
+		s.frontSendingHandler = handler
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Middleware returns receiving middleware that handles the variants
+// extension: it ranks the variants and adds them to the initialize result,
+// applies hint updates, and routes variant-scoped requests (lists, calls,
+// reads, gets, subscriptions and completions) to the selected variant.
+// Other methods are passed to the next handler.
+//
+// Use Install instead to also forward notifications from the variants.
+func (r *VariantRouter) Middleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		// Inject the front-facing session into the context so inner
+		// servers' sending middleware can redirect notifications to the
+		// real client.
+		return captureFrontSessionMiddleware(r.server.captureMiddleware(r.sessionMiddleware(next)))
+	}
+}
+
+// List routes a tools/list, resources/list, prompts/list or
+// resources/templates/list request to the variant it selects. Pagination
+// cursors in the request and result are variant-scoped.
+func (r *VariantRouter) List(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+	switch method {
+	case "tools/list", "resources/list", "prompts/list", "resources/templates/list":
+	default:
+		return nil, fmt.Errorf("variants: %s is not a list method", method)
+	}
+	d, err := r.dispatcherFor(req)
+	if err != nil {
+		return nil, err
+	}
+	return d.handleList(withFrontSession(ctx, req), method, req)
+}
+
+// Call routes a tools/call, resources/read, prompts/get or
+// completion/complete request to the variant it selects. Tool calls are fanned
+// out if enabled with Server.WithFanOut.
+func (r *VariantRouter) Call(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+	switch method {
+	case "tools/call", "resources/read", "prompts/get", "completion/complete":
+	default:
+		return nil, fmt.Errorf("variants: %s is not a call method", method)
+	}
+	d, err := r.dispatcherFor(req)
+	if err != nil {
+		return nil, err
+	}
+	return d.handle(withFrontSession(ctx, req), method, req, nil)
+}
+
+// Subscribe routes a resources/subscribe request to the variant it selects.
+func (r *VariantRouter) Subscribe(ctx context.Context, req *mcp.SubscribeRequest) error {
+	return r.subscription(ctx, "resources/subscribe", req)
+}
+
+// Unsubscribe routes a resources/unsubscribe request to the variant it
+// selects.
+func (r *VariantRouter) Unsubscribe(ctx context.Context, req *mcp.UnsubscribeRequest) error {
+	return r.subscription(ctx, "resources/unsubscribe", req)
+}
+
+func (r *VariantRouter) subscription(ctx context.Context, method string, req mcp.Request) error {
+	d, err := r.dispatcherFor(req)
+	if err != nil {
+		return err
+	}
+	_, err = d.handleDirect(withFrontSession(ctx, req), method, req)
+	return err
+}
+
+// dispatcherFor returns the dispatcher for req's session: its per-session
+// dispatcher if it was initialized through the router, else the shared
+// dispatcher in stateless mode. In strict mode, it fails for requests
+// with unknown extension _meta keys.
+func (r *VariantRouter) dispatcherFor(req mcp.Request) (*dispatcher, error) {
+	if r.server.closed() {
+		return nil, ErrServerClosed
+	}
+	if err := r.server.checkExtensionMeta(req); err != nil {
+		return nil, err
+	}
+	if ss, ok := req.GetSession().(*mcp.ServerSession); ok && ss != nil {
+		if v, ok := r.sessions.Load(ss); ok {
+			return v.(*sessionState).dispatcher, nil
+		}
+	}
+	if r.shared != nil {
+		return r.shared.dispatcher, nil
+	}
+	return nil, errors.New("variants: session was not initialized through the router")
+}
+
+// withFrontSession records req's session as the front session in ctx, as
+// captureFrontSessionMiddleware does for requests routed by Middleware.
+func withFrontSession(ctx context.Context, req mcp.Request) context.Context {
+	if ss, ok := req.GetSession().(*mcp.ServerSession); ok && ss != nil {
+		ctx = context.WithValue(ctx, frontSessionKeyType{}, ss)
+	}
+	return ctx
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariantRouter_Install(t *testing.T) {
+	vs := newTestVariantServer()
+	r, err := vs.NewRouter(nil)
+	require.NoError(t, err)
+
+	// The integrator's own server, with its own middleware.
+	gateway := mcp.NewServer(&mcp.Implementation{Name: "gateway", Version: "1.0.0"}, &mcp.ServerOptions{
+		Capabilities: r.Capabilities(),
+	})
+	var mu sync.Mutex
+	var methods []string
+	require.NoError(t, r.Install(gateway))
+	gateway.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			mu.Lock()
+			methods = append(methods, method)
+			mu.Unlock()
+			return next(ctx, method, req)
+		}
+	})
+
+	ctx := context.Background()
+	ct, st := mcp.NewInMemoryTransports()
+	ss, err := gateway.Connect(ctx, st, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { ss.Close() })
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, ct, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() })
+
+	var p struct {
+		DefaultVariant string `json:"defaultVariant"`
+	}
+	initExtension(t, session, &p)
+	assert.Equal(t, "coding", p.DefaultVariant)
+
+	res, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: "compact"}})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"summarize", "lookup"}, toolNames(res.Tools))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, methods, "tools/list")
+}
+
+func TestVariantRouter_Direct(t *testing.T) {
+	vs := newTestVariantServer()
+	t.Cleanup(func() { vs.Close() })
+	r, err := vs.NewRouter(&RouterOptions{Stateless: true})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	res, err := r.List(ctx, "tools/list", &mcp.ListToolsRequest{
+		Params: &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: "compact"}},
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"summarize", "lookup"}, toolNames(res.(*mcp.ListToolsResult).Tools))
+
+	callRes, err := r.Call(ctx, "tools/call", &mcp.CallToolRequest{
+		Params: &mcp.CallToolParamsRaw{Name: "analyze_code", Arguments: json.RawMessage(`{"code":"x","language":"go"}`)},
+	})
+	require.NoError(t, err)
+	assert.False(t, callRes.(*mcp.CallToolResult).IsError)
+
+	_, err = r.Call(ctx, "tools/list", &mcp.ListToolsRequest{})
+	assert.ErrorContains(t, err, "not a call method")
+	_, err = r.List(ctx, "tools/call", &mcp.CallToolRequest{})
+	assert.ErrorContains(t, err, "not a list method")
+}
+
+func TestVariantRouter_NotInitialized(t *testing.T) {
+	r, err := newTestVariantServer().NewRouter(nil)
+	require.NoError(t, err)
+	_, err = r.List(context.Background(), "tools/list", &mcp.ListToolsRequest{})
+	assert.ErrorContains(t, err, "not initialized")
+}
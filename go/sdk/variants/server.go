@@ -6,10 +6,16 @@ package variants
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"text/template"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -34,9 +40,89 @@ type variantEntry struct {
 type Server struct {
 	impl                *mcp.Implementation
 	variants            []variantEntry
+	variantIndex        map[string]int // variant ID -> index into variants
+	priorityOrder       []string       // variant IDs in default ranking order
 	rankingFunc         RankingFunc
-	shared              *sessionState     // non-nil in stateless mode; cleaned up by Close
-	frontSendingHandler mcp.MethodHandler // set by mcpServer(); used by sendingRedirectMiddleware
+	recommendFunc       RecommendFunc
+	defaultVariantID    string       // pinned default; empty means first-ranked
+	rankCache           rankingCache // disabled unless WithRankingCache is used
+	payloads            payloadCache // encoded availableVariants entries
+	fanOut              bool         // honor the fan-out _meta key on tools/call
+	confirmDeprecated   bool         // elicit confirmation before using deprecated variants
+	confirmDestructive  bool         // dry-run unconfirmed destructive tools of experimental variants
+	checkTools          bool         // reject tools/call for tools the variant does not list
+	redirectTools       bool         // redirect unselected tools/call to a variant listing the tool
+	eventHandlers       []EventHandler
+	flagProvider        FlagProvider
+	duplicateIDs        []string // variant IDs registered more than once
+	initHooks           []InitializeHook
+	templateData        TemplateDataFunc // non-nil enables description templates
+	descMu              sync.Mutex
+	descTemplates       map[string]*template.Template // description -> parsed template, nil if it has no actions or fails to parse; see maxDescTemplates
+	interceptors        []DispatchInterceptor
+	poolOpts            PoolOptions // stateless connection pools; zero Size means none
+	serverOpts          *mcp.ServerOptions
+	annotationOverrides map[string]AnnotationOverride // variant ID -> override
+	translations        map[translationKey]ToolTranslator
+	instructionsPolicy  InstructionsPolicy
+	instructions        map[string]string // variant ID -> server instructions; set when serving starts
+	tokenKey            []byte            // non-nil enables variant tokens
+	clientKey           ClientKeyFunc     // non-nil enables consistent defaults
+	startup             StartupPolicy
+	reportAvailability  bool         // list variant health in availableVariants
+	reportCapabilities  bool         // list variant capabilities in availableVariants
+	checkCapabilities   bool         // reject methods the selected variant does not support
+	store               *sharedStore // non-nil shares state across replicas; see WithSharedStore
+	profilingLabels     bool         // label dispatches for pprof; see WithProfilingLabels
+	resultAccounting    bool         // measure tool results; see WithResultAccounting
+	startupReporting    bool
+	startupReportFn     func(*StartupReport)
+	startupReport       atomic.Pointer[StartupReport] // built when serving starts; see WithStartupReport
+	hintLimits          HintLimits
+	sessionLimits       SessionLimits
+	timeouts            DispatchTimeouts
+	variantTimeouts     map[string]DispatchTimeouts // variant ID -> timeouts
+	logLevels           map[string]mcp.LoggingLevel // variant ID -> minimum forwarded log level
+	backendTLS          map[string]BackendTLS       // variant ID -> TLS to its remote server
+	warmups             map[string]*warmup          // variant ID -> warmup of new connections
+	retryPolicies       map[BackendKind]RetryPolicy
+	eligibility         map[string][]EligibilityRule
+	namespaceResources  bool // present resource URIs as variant+id://uri
+	activeVariantMeta   bool // stamp the serving variant into result _meta
+	strict              bool // enforce SEP-2053 MUSTs; see WithStrict
+	rankingAssertions   bool // panic on invalid rankings
+	rankingMetadata     bool // list RankingMetadata in availableVariants
+	groups              []VariantGroup
+	composition         *CompositionOptions // non-nil enables multi-variant composition
+	ownership           *ownershipIndex     // non-nil enables the ownership index
+	methodRoutes        map[string]string   // method -> variant ID; see WithMethodRoute
+	hedging             *hedging            // non-nil enables hedged requests
+	completions         *completionCache    // non-nil caches prompt completions
+	logLimiter          *logLimiter         // non-nil limits forwarded log messages
+	progressThrottle    *progressThrottle   // non-nil coalesces forwarded progress notifications
+	tracing             *TracingOptions     // non-nil propagates trace context; see WithTracing
+	tokenExchange       TokenExchangeFunc   // non-nil exchanges client tokens for remote variants
+	callerOpts          *CallerOptions      // non-nil exposes the Caller; see WithCallerContext
+	catalogCounts       bool                // list CatalogCounts in availableVariants
+	versionGated        bool                // some variant sets MinimumProtocolVersion
+	violations          violationLog
+	capture             *capturer // non-nil mirrors front-session traffic; see WithCapture
+	hintStats           hintStatsCollector
+	resultStats         resultStatsCollector
+
+	// mu serializes changes to runtime state that may change while
+	// serving. The state itself is read without locking.
+	mu                  sync.Mutex
+	state               atomic.Int32                                       // stateNew, stateRunning or stateClosed; changed under mu
+	routers             []*VariantRouter                                   // set while running; guarded by mu
+	runClaimed          bool                                               // Run or ServeUnix is serving; guarded by mu
+	launch              sync.Once                                          // starts the server-wide background work of the first router
+	stopStartup         context.CancelFunc                                 // stops background startup retries; guarded by mu
+	unavailable         atomic.Pointer[map[string]string]                  // variant ID -> reason; see SetVariantAvailability
+	degraded            atomic.Pointer[map[string]string]                  // variant ID -> reason; see SetVariantDegraded
+	statuses            atomic.Pointer[map[string]VariantStatus]           // variant ID -> status; see SetVariantStatus
+	capabilities        atomic.Pointer[map[string]*mcp.ServerCapabilities] // variant ID -> probed capabilities
+	frontSendingHandler mcp.MethodHandler                                  // set by VariantRouter.Install; used by sendingRedirectMiddleware
 }
 
 // NewServer creates a new variant-aware server with no registered variants.
@@ -62,7 +148,7 @@ func NewStreamableHTTPHandler(vs *Server, opts *mcp.StreamableHTTPOptions) *mcp.
 		panic("variants: nil Server")
 	}
 	stateless := opts != nil && opts.Stateless
-	srv, err := vs.mcpServer(stateless)
+	srv, err := vs.mcpServer(TransportStreamableHTTP, stateless)
 	if err != nil {
 		panic("variants: " + err.Error())
 	}
@@ -72,16 +158,60 @@ func NewStreamableHTTPHandler(vs *Server, opts *mcp.StreamableHTTPOptions) *mcp.
 	)
 }
 
+// NewSSEHandler returns a new [mcp.SSEHandler] serving the variant server
+// over the legacy HTTP+SSE transport, for older MCP clients that do not
+// speak the streamable HTTP transport. It mirrors [mcp.NewSSEHandler]. Each
+// SSE connection is a stateful session with the same variant initialization
+// and _meta routing as [NewStreamableHTTPHandler].
+//
+//	handler := variants.NewSSEHandler(vs, nil)
+//	http.ListenAndServe(":8080", handler)
+func NewSSEHandler(vs *Server, opts *mcp.SSEOptions) *mcp.SSEHandler {
+	if vs == nil {
+		panic("variants: nil Server")
+	}
+	srv, err := vs.mcpServer(TransportSSE, false)
+	if err != nil {
+		panic("variants: " + err.Error())
+	}
+	return mcp.NewSSEHandler(
+		func(r *http.Request) *mcp.Server { return srv },
+		opts,
+	)
+}
+
 // addVariant is the shared registration logic for all With* methods.
-// It checks for duplicates, sets priority, and appends the entry.
+// It sets priority and appends the entry. Duplicates are not registered,
+// but recorded, so that Validate reports them and serving fails (see
+// validateVariants).
 func (s *Server) addVariant(v ServerVariant, b backend, priority int) *Server {
-	for _, e := range s.variants {
-		if e.variant.ID == v.ID {
-			panic("variants: duplicate variant ID: " + v.ID)
+	if s.hasVariant(v.ID) {
+		s.duplicateIDs = append(s.duplicateIDs, v.ID)
+		return s
+	}
+	for k := range v.Extra {
+		if !strings.Contains(k, "/") {
+			panic("variants: Extra key of variant " + v.ID + " is not namespaced: " + k)
 		}
 	}
+	if v.MinimumProtocolVersion != "" {
+		if !validProtocolVersion(v.MinimumProtocolVersion) {
+			panic("variants: minimum protocol version of variant " + v.ID + " is not a date: " + v.MinimumProtocolVersion)
+		}
+		s.versionGated = true
+	}
 	v.priority = priority
 	s.variants = append(s.variants, variantEntry{variant: v, backend: b})
+	if s.variantIndex == nil {
+		s.variantIndex = make(map[string]int)
+	}
+	s.variantIndex[v.ID] = len(s.variants) - 1
+	s.priorityOrder = s.priorityOrder[:0]
+	for _, v := range defaultRankingFunc(context.Background(), VariantHints{}, s.Variants()) {
+		s.priorityOrder = append(s.priorityOrder, v.ID)
+	}
+	s.rankCache.invalidate()
+	s.payloads.invalidate()
 	return s
 }
 
@@ -92,7 +222,8 @@ func (s *Server) addVariant(v ServerVariant, b backend, priority int) *Server {
 // in the list and serve as the recommended default for clients. This
 // behavior can be overridden by providing a custom RankingFunc.
 //
-// Variant IDs must be unique; registering a duplicate panics.
+// Variant IDs must be unique: a duplicate is not registered, and the server
+// fails to serve (see Validate).
 func (s *Server) WithVariant(v ServerVariant, mcpServer *mcp.Server, priority int) *Server {
 	return s.addVariant(v, newInMemoryBackend(mcpServer, v.ID, s), priority)
 }
@@ -104,9 +235,41 @@ func (s *Server) WithHTTPVariant(v ServerVariant, mcpServer *mcp.Server, priorit
 }
 
 // WithRemoteVariant registers a ServerVariant backed by a remote MCP server
-// at the given endpoint URL. Not yet implemented.
+// served over the streamable HTTP transport at the given endpoint URL, such
+// as a separate service in the same cluster. priority is as for
+// WithVariant.
+//
+// Each stateful front session connects to the remote server on first use
+// or at initialize, and reconnects if the connection drops, renewing the
+// session's resource subscriptions on a best-effort basis. Notifications
+// and server-to-client requests the remote server sends while handling a
+// request are forwarded to the front session; context values of the front
+// request are not, since the request crosses the network. See
+// WithBackendTLS to configure TLS, e.g. for mutual TLS.
+//
+// WithRemoteVariant panics if endpoint is not an absolute http or https
+// URL. Duplicate variant IDs are handled as for WithVariant.
 func (s *Server) WithRemoteVariant(v ServerVariant, endpoint string, priority int) *Server {
-	panic("variants: WithRemoteVariant not yet implemented")
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		panic("variants: endpoint of remote variant " + v.ID + " is not an http(s) URL: " + endpoint)
+	}
+	return s.addVariant(v, &remoteBackend{variantID: v.ID, endpoint: endpoint, httpClient: http.DefaultClient, vs: s}, priority)
+}
+
+// WithServerOptions sets options for the front mcp.Server that clients
+// connect to, such as Instructions, KeepAlive, Logger or
+// InitializedHandler. opts.Capabilities are merged with the capabilities
+// of the variants, as for the variants among themselves.
+//
+// Handlers for requests routed to variants (CompletionHandler,
+// SubscribeHandler, UnsubscribeHandler) and PageSize have no effect, since
+// those requests are answered by the variants' servers.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithServerOptions(opts *mcp.ServerOptions) *Server {
+	s.serverOpts = opts
+	return s
 }
 
 // WithRanking sets a custom ranking function used to order variants based
@@ -117,79 +280,275 @@ func (s *Server) WithRemoteVariant(v ServerVariant, endpoint string, priority in
 // Returns the receiver for chaining.
 func (s *Server) WithRanking(fn RankingFunc) *Server {
 	s.rankingFunc = fn
+	s.rankCache.invalidate()
+	s.payloads.invalidate()
 	return s
 }
 
+// WithRankingCache enables caching of ranking results for up to size
+// distinct client hints (size <= 0 disables caching, the default). Results
+// are keyed by a canonical fingerprint of the hints and invalidated when
+// variants are registered or the ranking function changes.
+//
+// Caching assumes the RankingFunc output depends only on its hints and
+// variants arguments; do not enable it for ranking functions that consult
+// the context or other external state.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithRankingCache(size int) *Server {
+	s.rankCache.reset(size)
+	return s
+}
+
+// WithFanOut enables or disables fan-out of tools/call requests. When
+// enabled, a tools/call whose _meta carries
+//
+//	"io.modelcontextprotocol/server-variant-fanout": ["v2", "v3"]
+//
+// is invoked on each listed variant concurrently and answered with a single
+// combined result. This is useful for comparing variant behavior or
+// ensembling outputs during migration testing. Disabled by default, in which
+// case the key is ignored.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithFanOut(enabled bool) *Server {
+	s.fanOut = enabled
+	return s
+}
+
+// WithRecommendation sets a function that picks the recommended variant
+// reported as recommendedVariant in the initialize response. If nil, the
+// first-ranked variant is recommended.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithRecommendation(fn RecommendFunc) *Server {
+	s.recommendFunc = fn
+	return s
+}
+
+// WithDefaultVariant pins the variant used for requests that carry no
+// variant selection in _meta (variant-unaware clients and clients that
+// omit the field), regardless of the RankingFunc output. The ID must refer
+// to a registered variant; this is checked when the front server is built
+// so that registration order does not matter.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithDefaultVariant(id string) *Server {
+	s.defaultVariantID = id
+	return s
+}
+
+// WithDeprecationConfirmation enables or disables confirmation prompts for
+// deprecated variants. When enabled and a client that supports elicitation
+// explicitly selects a Deprecated variant, the server asks the user to
+// confirm before dispatching the session's first request to it. Declining
+// fails the request. Disabled by default.
+//
+// Confirmations are per session, so they have no effect in stateless mode.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithDeprecationConfirmation(enabled bool) *Server {
+	s.confirmDeprecated = enabled
+	return s
+}
+
+// lookupVariant returns the registered variant with the given ID.
+func (s *Server) lookupVariant(id string) (ServerVariant, bool) {
+	i, ok := s.variantIndex[id]
+	if !ok {
+		return ServerVariant{}, false
+	}
+	return s.withStatus(s.variants[i].variant), true
+}
+
+// hasVariant reports whether a variant with the given ID is registered.
+func (s *Server) hasVariant(id string) bool {
+	_, ok := s.lookupVariant(id)
+	return ok
+}
+
+// defaultVariant returns the server-wide default variant ID, used by
+// sessions without their own ranking (stateless mode). A variant pinned via
+// WithDefaultVariant takes precedence while it is available; otherwise the
+// first-ranked variant for empty hints is used.
+func (s *Server) defaultVariant(ctx context.Context) (string, error) {
+	return s.defaultVariantWhere(ctx, s.isAvailable)
+}
+
+// defaultVariantWhere is like defaultVariant, but only considers variants
+// for which usable reports true.
+func (s *Server) defaultVariantWhere(ctx context.Context, usable func(id string) bool) (string, error) {
+	if s.defaultVariantID != "" && usable(s.defaultVariantID) {
+		return s.defaultVariantID, nil
+	}
+	if s.rankingFunc == nil {
+		// Fast path: the default ranking ignores hints, so its order is
+		// known from registration.
+		for _, id := range s.defaultOrder() {
+			if usable(id) {
+				return id, nil
+			}
+		}
+		return "", errors.New("no variants available")
+	}
+	for _, v := range s.RankedVariants(ctx, VariantHints{}) {
+		if usable(v.ID) {
+			return v.ID, nil
+		}
+	}
+	return "", errors.New("no variants available")
+}
+
 // Variants returns a copy of all registered ServerVariant values in
-// registration order.
+// registration order, with any status set by SetVariantStatus.
 func (s *Server) Variants() []ServerVariant {
 	out := make([]ServerVariant, len(s.variants))
 	for i, e := range s.variants {
-		out[i] = e.variant
+		out[i] = s.withStatus(e.variant)
 	}
 	return out
 }
 
 // RankedVariants returns the registered variants ranked according to the
 // configured RankingFunc (or the default priority-based ranking if none is
-// set).
+// set). Variants taken out of rotation with SetVariantAvailability are
+// omitted. If enabled via WithRankingCache, results are served from the
+// cache.
 func (s *Server) RankedVariants(ctx context.Context, hints VariantHints) []ServerVariant {
-	all := s.Variants()
+	ranked, shared := s.rankedVariants(ctx, hints)
+	if shared {
+		return slices.Clone(ranked)
+	}
+	return ranked
+}
+
+// rankedVariants implements RankedVariants. It reports whether ranked is
+// shared with the ranking cache, in which case it must not be modified.
+func (s *Server) rankedVariants(ctx context.Context, hints VariantHints) (ranked []ServerVariant, shared bool) {
+	var fp [sha256.Size]byte
+	cacheable := s.rankCache.enabled()
+	if cacheable {
+		fp, cacheable = hintsFingerprint(hints)
+	}
+	if cacheable {
+		if ranked, ok := s.rankCache.get(fp); ok {
+			return ranked, true
+		}
+	}
+
+	all := s.filterAvailable(s.Variants())
 	if len(all) == 0 {
-		return all
+		return all, false
+	}
+	var storeKey string
+	if cacheable && s.store != nil {
+		storeKey = s.store.rankingKey(fp, all)
+		if ranked, ok := s.store.getRanking(ctx, storeKey, all); ok {
+			s.rankCache.put(fp, ranked)
+			return ranked, false
+		}
 	}
 	rankFn := s.rankingFunc
 	if rankFn == nil {
 		rankFn = defaultRankingFunc
 	}
-	return rankFn(ctx, hints, all)
-}
+	ranked, excluded := rankWith(ctx, rankFn, hints, slices.Clone(all))
+	ranked = s.checkRanking(all, ranked, excluded)
 
-// Close releases resources held by all registered backends and, in stateless
-// mode, tears down the shared inner connections.
-func (s *Server) Close() error {
-	if s.shared != nil {
-		s.shared.close()
-		s.shared = nil
-	}
-	var firstErr error
-	for _, entry := range s.variants {
-		if err := entry.backend.close(); err != nil && firstErr == nil {
-			firstErr = err
+	if cacheable {
+		s.rankCache.put(fp, ranked)
+		if storeKey != "" {
+			s.store.putRanking(ctx, storeKey, ranked)
 		}
 	}
-	return firstErr
+	return ranked, false
 }
 
 // Run starts the variant server on the given transport (e.g., stdio).
 // For multi-client HTTP support, use [NewStreamableHTTPHandler] instead.
+//
+// Run closes the server when it returns. It returns [ErrServerClosed] if the
+// server has been closed, and an error if another Run or [Server.ServeUnix]
+// is serving it.
 func (s *Server) Run(ctx context.Context, t mcp.Transport) error {
-	defer s.Close()
-	srv, err := s.mcpServer(false)
+	release, err := s.claimRun()
 	if err != nil {
 		return err
 	}
+	srv, err := s.mcpServer(transportKindOf(t), false)
+	if err != nil {
+		release()
+		return err
+	}
+	defer s.Close()
 	return srv.Run(ctx, t)
 }
 
 // discoverCapabilities probes each backend to determine its advertised
-// capabilities. The results are merged into a single set for the front
-// proxy server.
-func (s *Server) discoverCapabilities() (*mcp.ServerCapabilities, error) {
+// capabilities and instructions, retrying per the startup policy. The
+// capabilities are merged into a single set for the front proxy server; the
+// instructions are returned by variant ID. With a background startup
+// policy, variants whose backends are not ready are taken out of rotation
+// and returned in pending with their unavailability reason.
+// If found is not nil, the outcome of each probe is recorded in it,
+// indexed like s.variants, for the startup report.
+func (s *Server) discoverCapabilities(found []discovery) (caps *mcp.ServerCapabilities, instructions, pending map[string]string, err error) {
 	ctx := context.Background()
+	if s.startup.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.startup.Timeout)
+		defer cancel()
+	}
 	var allCaps []*mcp.ServerCapabilities
+	instructions = make(map[string]string)
+
+	// Probe the backends concurrently, so that retrying one that is down
+	// does not use up the others' share of the timeout.
+	probed := make([]discovery, len(s.variants))
+	var wg sync.WaitGroup
+	for i, entry := range s.variants {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := s.probeWithRetry(ctx, entry.backend, s.startup.Timeout > 0)
+			probed[i] = discovery{res: res, err: err}
+		}()
+	}
+	wg.Wait()
 
-	for _, entry := range s.variants {
-		caps, err := entry.backend.capabilities(ctx)
+	for i, entry := range s.variants {
+		res, err := probed[i].res, probed[i].err
+		if found != nil {
+			found[i] = discovery{res: res, err: err}
+		}
 		if err != nil {
-			return nil, err
+			if !s.startup.Background {
+				return nil, nil, nil, err
+			}
+			id := entry.variant.ID
+			s.emit(ctx, Event{Kind: EventBackendUnhealthy, VariantID: id, Err: err})
+			if pending == nil {
+				pending = make(map[string]string)
+			}
+			pending[id] = startupReason(err)
+			if s.isAvailable(id) {
+				s.setAvailability(id, false, pending[id], nil)
+			}
+			continue
+		}
+		if res == nil {
+			continue
 		}
-		if caps != nil {
-			allCaps = append(allCaps, caps)
+		s.setVariantCapabilities(entry.variant.ID, res.Capabilities)
+		if res.Capabilities != nil {
+			allCaps = append(allCaps, res.Capabilities)
+		}
+		if res.Instructions != "" {
+			instructions[entry.variant.ID] = res.Instructions
 		}
 	}
 
-	return unionCapabilities(allCaps), nil
+	return unionCapabilities(allCaps), instructions, pending, nil
 }
 
 // mcpServer returns a configured *mcp.Server that routes requests to the
@@ -197,7 +556,8 @@ func (s *Server) discoverCapabilities() (*mcp.ServerCapabilities, error) {
 //
 // The returned server is a thin proxy: it handles initialization (injecting
 // variant metadata into the response) and delegates all other requests to the
-// backing inner servers via dispatchers.
+// backing inner servers via dispatchers. The routing is done by a
+// [VariantRouter] installed in the server.
 //
 // Request flow (stateful mode):
 //
@@ -227,46 +587,23 @@ func (s *Server) discoverCapabilities() (*mcp.ServerCapabilities, error) {
 //
 // In stateless mode (see [NewStreamableHTTPHandler]), the inner connections
 // are created once and shared across all requests instead of per-session.
-func (s *Server) mcpServer(stateless bool) (*mcp.Server, error) {
-	if len(s.variants) == 0 {
-		return nil, errors.New("variants: no variants registered")
-	}
-
-	caps, err := s.discoverCapabilities()
+//
+// transport records how the server is served, for [RankingRequest].
+func (s *Server) mcpServer(transport TransportKind, stateless bool) (*mcp.Server, error) {
+	r, err := s.NewRouter(&RouterOptions{Stateless: stateless, Transport: transport})
 	if err != nil {
 		return nil, err
 	}
 
-	// Per-session state, keyed by *mcp.ServerSession pointer identity.
-	sessions := &sync.Map{}
-
-	// In stateless mode, create shared connections once and reuse them
-	// across all requests (no per-session state). The shared state is
-	// stored on the Server so Close() can release it.
-	var shared *sessionState
-	if stateless {
-		shared, err = s.createSessionState(context.Background(), nil)
-		if err != nil {
-			return nil, err
-		}
-		s.shared = shared
+	var opts mcp.ServerOptions
+	if s.serverOpts != nil {
+		opts = *s.serverOpts
 	}
-
-	frontServer := mcp.NewServer(s.impl, &mcp.ServerOptions{
-		Capabilities: caps,
-	})
-
-	frontServer.AddReceivingMiddleware(s.sessionMiddleware(sessions, shared))
-
-	// Inject the front-facing session into the context so inner servers'
-	// sending middleware can redirect notifications to the real client.
-	frontServer.AddReceivingMiddleware(captureFrontSessionMiddleware)
-
-	s.frontSendingHandler, err = captureSendingMethodHandler(frontServer)
-	if err != nil {
+	opts.Capabilities = unionCapabilities([]*mcp.ServerCapabilities{opts.Capabilities, r.Capabilities()})
+	frontServer := mcp.NewServer(s.impl, &opts)
+	if err := r.Install(frontServer); err != nil {
 		return nil, err
 	}
-
 	return frontServer, nil
 }
 
@@ -306,53 +643,24 @@ func captureSendingMethodHandler(server *mcp.Server) (mcp.MethodHandler, error)
 //
 //	experimental["io.modelcontextprotocol/server-variants"]["variantHints"]
 func extractVariantHints(req mcp.Request) VariantHints {
-	params, _ := req.GetParams().(*mcp.InitializeParams)
-	if params == nil || params.Capabilities == nil || params.Capabilities.Experimental == nil {
-		return VariantHints{}
-	}
-	ext, ok := params.Capabilities.Experimental[extensionID]
-	if !ok {
-		return VariantHints{}
-	}
-	extMap, _ := ext.(map[string]any)
-	vh, _ := extMap["variantHints"].(map[string]any)
-	if vh == nil {
-		return VariantHints{}
+	if ext, _ := clientExtension(req); ext.VariantHints != nil {
+		return *ext.VariantHints
 	}
-	var hints VariantHints
-	hints.Description, _ = vh["description"].(string)
-	if h, ok := vh["hints"].(map[string]any); ok {
-		hints.Hints = h
-	}
-	return hints
+	return VariantHints{}
 }
 
 // enrichInitResult injects variant information into the initialize response.
-func (s *Server) enrichInitResult(ctx context.Context, result mcp.Result, req mcp.Request) (mcp.Result, error) {
+// The hints must already be normalized and ranked; report is echoed back as
+// normalizedHints when non-empty.
+func (s *Server) enrichInitResult(ctx context.Context, result mcp.Result, d *dispatcher, hints VariantHints, report NormalizedHints, ranked []ServerVariant) (mcp.Result, error) {
 	initResult, ok := result.(*mcp.InitializeResult)
 	if !ok {
 		return result, nil
 	}
 
-	ranked := s.RankedVariants(ctx, extractVariantHints(req))
-
-	// Build availableVariants payload
-	availableVariants := make([]map[string]any, len(ranked))
-	for i, v := range ranked {
-		variant := map[string]any{
-			"id":          v.ID,
-			"description": v.Description,
-		}
-		if v.Hints != nil {
-			variant["hints"] = v.Hints
-		}
-		if v.Status != "" {
-			variant["status"] = v.Status
-		}
-		if v.DeprecationInfo != nil {
-			variant["deprecationInfo"] = v.DeprecationInfo
-		}
-		availableVariants[i] = variant
+	payload, err := s.variantsPayload(ctx, d, hints, report, ranked)
+	if err != nil {
+		return nil, err
 	}
 
 	if initResult.Capabilities == nil {
@@ -361,14 +669,58 @@ func (s *Server) enrichInitResult(ctx context.Context, result mcp.Result, req mc
 	if initResult.Capabilities.Experimental == nil {
 		initResult.Capabilities.Experimental = make(map[string]any)
 	}
-	initResult.Capabilities.Experimental[extensionID] = map[string]any{
-		"availableVariants":     availableVariants,
-		"moreVariantsAvailable": len(ranked) < len(s.variants),
-	}
+	initResult.Capabilities.Experimental[extensionID] = payload
 
 	return initResult, nil
 }
 
+// variantsPayload builds the extension payload describing the ranked
+// variants, as sent in the initialize response and in ranking update
+// notifications.
+func (s *Server) variantsPayload(ctx context.Context, d *dispatcher, hints VariantHints, report NormalizedHints, ranked []ServerVariant) (*AvailableVariantsPayload, error) {
+	defaultID, err := d.defaultVariant(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	listed := ranked
+	if s.reportAvailability {
+		unavailable := s.unavailableVariantsList()
+		if !d.shared {
+			d.mu.RLock()
+			fc := d.flagCtx
+			d.mu.RUnlock()
+			unavailable = s.filterFlagged(ctx, fc, unavailable)
+		}
+		listed = append(slices.Clip(ranked), unavailable...)
+	}
+
+	entries, err := s.availableVariantsPayload(ctx, hints, listed, ranked)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &AvailableVariantsPayload{
+		AvailableVariants:     listed,
+		MoreVariantsAvailable: len(ranked) < len(s.variants),
+		DefaultVariant:        defaultID,
+		RecommendedVariant:    s.recommendedVariant(ctx, hints, ranked),
+		VariantGroups:         s.groupsPayload(listed),
+		MethodRoutes:          s.methodRoutes,
+		entries:               entries,
+	}
+	if !report.empty() {
+		payload.NormalizedHints = &report
+	}
+	if s.composition != nil {
+		payload.Composition = &CompositionSupport{ConflictPolicy: s.composition.Conflict}
+	}
+	if s.tokenKey != nil {
+		payload.VariantToken = s.variantToken(defaultID)
+	}
+	return payload, nil
+}
+
 // unionCapabilities merges multiple ServerCapabilities into a single set
 // that the front proxy server advertises to clients. The merge strategy is:
 //
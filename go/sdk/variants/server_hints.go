@@ -0,0 +1,143 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ServerVariantHints is a typed form of ServerVariant.Hints, with a field
+// for each well-known hint key, so that variant declarations are checked
+// by the compiler:
+//
+//	variants.ServerVariant{
+//		ID:    "compact",
+//		Hints: variants.ServerVariantHints{ContextSize: "compact", ModelFamily: "any"}.Map(),
+//	}
+//
+// Other keys go in Custom. Empty fields and values are omitted from the
+// map. It marshals to JSON as the map.
+type ServerVariantHints struct {
+	// ModelFamily is the HintModelFamily hint, e.g. "anthropic".
+	ModelFamily string
+
+	// UseCase is the HintUseCase hint, e.g. "ide".
+	UseCase string
+
+	// ContextSize is the HintContextSize hint: "compact", "standard" or
+	// "verbose".
+	ContextSize string
+
+	// Rendering is the HintRenderingCapabilities hint: "rich", "markdown"
+	// or "text-only".
+	Rendering string
+
+	// Language is the HintLanguageOptimization hint, e.g. "en".
+	Language string
+
+	// Custom holds hints outside the common vocabulary, e.g.
+	// "com.example/tier". Well-known keys are ignored in favor of the
+	// fields.
+	Custom map[string]string
+}
+
+// fields returns pointers to the fields of h by well-known key.
+func (h *ServerVariantHints) fields() map[string]*string {
+	return map[string]*string{
+		HintModelFamily:           &h.ModelFamily,
+		HintUseCase:               &h.UseCase,
+		HintContextSize:           &h.ContextSize,
+		HintRenderingCapabilities: &h.Rendering,
+		HintLanguageOptimization:  &h.Language,
+	}
+}
+
+// ServerVariantHintsFromMap returns the typed form of a ServerVariant.Hints
+// map. Keys other than the well-known ones go in Custom.
+func ServerVariantHintsFromMap(m map[string]string) ServerVariantHints {
+	var h ServerVariantHints
+	fields := h.fields()
+	for k, v := range m {
+		if f, ok := fields[k]; ok {
+			*f = v
+			continue
+		}
+		if h.Custom == nil {
+			h.Custom = make(map[string]string)
+		}
+		h.Custom[k] = v
+	}
+	return h
+}
+
+// Map returns the hints as a ServerVariant.Hints map, or nil if there are
+// none.
+func (h ServerVariantHints) Map() map[string]string {
+	var m map[string]string
+	set := func(k, v string) {
+		if v == "" {
+			return
+		}
+		if m == nil {
+			m = make(map[string]string)
+		}
+		m[k] = v
+	}
+	for k, v := range h.Custom {
+		if !wellKnownHintKeys[k] {
+			set(k, v)
+		}
+	}
+	for k, f := range h.fields() {
+		set(k, *f)
+	}
+	return m
+}
+
+// Validate reports values of well-known hints outside the vocabulary the
+// SEP defines exhaustively (contextSize and renderingCapabilities), and
+// Custom entries with a well-known or empty key, which Map ignores.
+func (h ServerVariantHints) Validate() error {
+	var errs []error
+	fields := h.fields()
+	for _, k := range sortedKeys(fields) {
+		v := *fields[k]
+		if closed, ok := closedHintValues[k]; ok && v != "" && !slices.Contains(closed, v) {
+			errs = append(errs, fmt.Errorf("variants: invalid value %q for hint %q; want one of %q", v, k, closed))
+		}
+	}
+	for _, k := range sortedKeys(h.Custom) {
+		switch {
+		case k == "":
+			errs = append(errs, errors.New("variants: empty custom hint key"))
+		case wellKnownHintKeys[k]:
+			errs = append(errs, fmt.Errorf("variants: custom hint %q is well-known; set its field instead", k))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// MarshalJSON marshals the hints as the map returned by Map.
+func (h ServerVariantHints) MarshalJSON() ([]byte, error) {
+	m := h.Map()
+	if m == nil {
+		m = map[string]string{}
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON unmarshals hints from a JSON object of string values, as
+// by ServerVariantHintsFromMap.
+func (h *ServerVariantHints) UnmarshalJSON(data []byte) error {
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*h = ServerVariantHintsFromMap(m)
+	return nil
+}
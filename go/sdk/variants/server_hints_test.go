@@ -0,0 +1,82 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerVariantHints_Map(t *testing.T) {
+	h := ServerVariantHints{
+		ModelFamily: "anthropic",
+		ContextSize: "compact",
+		Rendering:   "markdown",
+		Custom:      map[string]string{"com.example/tier": "free", HintContextSize: "verbose", "empty": ""},
+	}
+	m := h.Map()
+	assert.Equal(t, map[string]string{
+		HintModelFamily:           "anthropic",
+		HintContextSize:           "compact",
+		HintRenderingCapabilities: "markdown",
+		"com.example/tier":        "free",
+	}, m)
+	assert.Nil(t, ServerVariantHints{}.Map())
+
+	back := ServerVariantHintsFromMap(m)
+	assert.Equal(t, ServerVariantHints{
+		ModelFamily: "anthropic",
+		ContextSize: "compact",
+		Rendering:   "markdown",
+		Custom:      map[string]string{"com.example/tier": "free"},
+	}, back)
+	assert.Equal(t, m, back.Map())
+}
+
+func TestServerVariantHints_JSON(t *testing.T) {
+	h := ServerVariantHints{UseCase: "ide", Language: "en", Custom: map[string]string{"com.example/tier": "pro"}}
+	data, err := json.Marshal(h)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"useCase":"ide","languageOptimization":"en","com.example/tier":"pro"}`, string(data))
+
+	var got ServerVariantHints
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, h, got)
+
+	data, err = json.Marshal(ServerVariantHints{})
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(data))
+	assert.Error(t, json.Unmarshal([]byte(`{"useCase":1}`), &got))
+}
+
+func TestServerVariantHints_Validate(t *testing.T) {
+	assert.NoError(t, ServerVariantHints{ContextSize: "verbose", Rendering: "text-only", ModelFamily: "anything"}.Validate())
+
+	err := ServerVariantHints{
+		ContextSize: "huge",
+		Rendering:   "html",
+		Custom:      map[string]string{HintUseCase: "ide", "": "x"},
+	}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid value "huge" for hint "contextSize"`)
+	assert.Contains(t, err.Error(), `invalid value "html" for hint "renderingCapabilities"`)
+	assert.Contains(t, err.Error(), `custom hint "useCase" is well-known`)
+	assert.Contains(t, err.Error(), "empty custom hint key")
+}
+
+func TestServerVariantHints_Registration(t *testing.T) {
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).WithVariant(ServerVariant{
+		ID:          "compact",
+		Description: "Compact",
+		Hints:       ServerVariantHints{ContextSize: "compact"}.Map(),
+	}, newTickServer(), 0)
+	got := vs.Variants()
+	require.Len(t, got, 1)
+	assert.Equal(t, "compact", ServerVariantHintsFromMap(got[0].Hints).ContextSize)
+}
@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
@@ -81,6 +82,22 @@ func connectTestClient(t *testing.T, vs *Server, clientOpts *mcp.ClientOptions)
 	return session
 }
 
+// connectInMemoryClient connects a client to front over in-memory
+// transports. Unlike connectTestClient, it can connect several clients to
+// the same front server; their sessions have no ID.
+func connectInMemoryClient(t *testing.T, front *mcp.Server, clientOpts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+	st, ct := mcp.NewInMemoryTransports()
+	ss, err := front.Connect(ctx, st, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { ss.Close() })
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, clientOpts).Connect(ctx, ct, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { cs.Close() })
+	return cs
+}
+
 // TestIntegration_EndToEnd verifies the full variant server lifecycle with a
 // variant-aware client: the client advertises variant support and sends hints
 // during initialization, then selects variants per-request via _meta.
@@ -222,6 +239,306 @@ func TestIntegration_ClientWithoutVariantSupport(t *testing.T) {
 	assert.Error(t, err, "tool from non-default variant should not be reachable without _meta")
 }
 
+// TestIntegration_PinnedDefaultVariant verifies that WithDefaultVariant
+// overrides the ranking for requests without a _meta variant selection and
+// that the pinned default is reported in the initialize payload.
+func TestIntegration_PinnedDefaultVariant(t *testing.T) {
+	vs := newTestVariantServer().WithDefaultVariant("compact")
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	var extData struct {
+		AvailableVariants []struct {
+			ID string `json:"id"`
+		} `json:"availableVariants"`
+		DefaultVariant string `json:"defaultVariant"`
+	}
+	initExtension(t, session, &extData)
+
+	// Ranking order is unchanged; the default is reported explicitly.
+	require.Len(t, extData.AvailableVariants, 2)
+	assert.Equal(t, "coding", extData.AvailableVariants[0].ID)
+	assert.Equal(t, "compact", extData.DefaultVariant)
+
+	tools, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	names := toolNames(tools.Tools)
+	assert.Contains(t, names, "summarize")
+	assert.NotContains(t, names, "analyze_code")
+}
+
+func TestWithDefaultVariant_Unregistered(t *testing.T) {
+	vs := newTestVariantServer().WithDefaultVariant("missing")
+
+	_, err := vs.mcpServer(TransportOther, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"missing"`)
+}
+
+func TestIntegration_VariantDisplayMetadata(t *testing.T) {
+	codingServer, _ := newTestServers()
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{
+			ID:          "coding",
+			Description: "Optimized for coding workflows",
+			Extra: map[string]any{
+				"example.com/tier": "pro",
+				"example.com/sla":  map[string]any{"uptime": 99.9},
+			},
+			DocsURL: "https://example.com/docs/coding",
+			Icons:   []mcp.Icon{{Source: "https://example.com/coding.svg", MIMEType: "image/svg+xml"}},
+		}, codingServer, 0)
+
+	session := connectTestClient(t, vs, nil)
+	var p struct {
+		AvailableVariants []map[string]any `json:"availableVariants"`
+	}
+	initExtension(t, session, &p)
+	require.Len(t, p.AvailableVariants, 1)
+	assert.Equal(t, "coding", p.AvailableVariants[0]["id"])
+	assert.Equal(t, "pro", p.AvailableVariants[0]["example.com/tier"])
+	assert.Equal(t, map[string]any{"uptime": 99.9}, p.AvailableVariants[0]["example.com/sla"])
+	assert.Equal(t, "https://example.com/docs/coding", p.AvailableVariants[0]["docsUrl"])
+	assert.Equal(t, []any{map[string]any{"src": "https://example.com/coding.svg", "mimeType": "image/svg+xml"}}, p.AvailableVariants[0]["icons"])
+}
+
+func TestWithVariant_UnnamespacedExtra(t *testing.T) {
+	codingServer, _ := newTestServers()
+	assert.PanicsWithValue(t, "variants: Extra key of variant coding is not namespaced: tier", func() {
+		NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+			WithVariant(ServerVariant{ID: "coding", Extra: map[string]any{"tier": "pro"}}, codingServer, 0)
+	})
+}
+
+func TestIntegration_ServerOptions(t *testing.T) {
+	initialized := make(chan struct{})
+	vs := newTestVariantServer().WithServerOptions(&mcp.ServerOptions{
+		Instructions: "Use the coding variant for code.",
+		Capabilities: &mcp.ServerCapabilities{
+			Logging:      &mcp.LoggingCapabilities{},
+			Experimental: map[string]any{"example.com/feature": map[string]any{}},
+		},
+		InitializedHandler: func(context.Context, *mcp.InitializedRequest) { close(initialized) },
+	})
+	session := connectTestClient(t, vs, nil)
+
+	res := session.InitializeResult()
+	assert.Equal(t, "Use the coding variant for code.", res.Instructions)
+	assert.NotNil(t, res.Capabilities.Logging)
+	assert.NotNil(t, res.Capabilities.Tools, "variant capabilities are kept")
+	assert.Contains(t, res.Capabilities.Experimental, "example.com/feature")
+	assert.Contains(t, res.Capabilities.Experimental, extensionID)
+	select {
+	case <-initialized:
+	case <-time.After(time.Second):
+		t.Fatal("InitializedHandler not called")
+	}
+}
+
+// hintsClientOptions returns client options that advertise variant support
+// with the given hints in the initialize request.
+func hintsClientOptions(hints map[string]any) *mcp.ClientOptions {
+	return &mcp.ClientOptions{
+		Capabilities: &mcp.ClientCapabilities{
+			Experimental: map[string]any{
+				extensionID: map[string]any{
+					"variantHints": map[string]any{"hints": hints},
+				},
+			},
+		},
+	}
+}
+
+// initExtension decodes the variants extension payload from the session's
+// initialize result into v.
+func initExtension(t *testing.T, session *mcp.ClientSession, v any) {
+	t.Helper()
+	initResult := session.InitializeResult()
+	require.NotNil(t, initResult)
+	require.NotNil(t, initResult.Capabilities)
+	extJSON, err := json.Marshal(initResult.Capabilities.Experimental[extensionID])
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(extJSON, v))
+}
+
+// TestIntegration_RecommendedVariant verifies that the initialize payload
+// carries an explicit recommendedVariant plus any score and matchReason set
+// by the RankingFunc, and that a RecommendFunc can diverge from rank order.
+func TestIntegration_RecommendedVariant(t *testing.T) {
+	newServer := func() *Server {
+		return newTestVariantServer().
+			WithRanking(func(_ context.Context, hints VariantHints, vs []ServerVariant) []ServerVariant {
+				want, _ := HintValue[string](hints, HintContextSize)
+				for i := range vs {
+					if vs[i].ID == want {
+						vs[i].Score = 1
+						vs[i].MatchReason = "contextSize matched"
+					}
+				}
+				return defaultRankingFunc(context.Background(), hints, vs)
+			})
+	}
+
+	type payload struct {
+		AvailableVariants []struct {
+			ID          string  `json:"id"`
+			Score       float64 `json:"score"`
+			MatchReason string  `json:"matchReason"`
+		} `json:"availableVariants"`
+		RecommendedVariant string `json:"recommendedVariant"`
+	}
+
+	t.Run("first-ranked by default", func(t *testing.T) {
+		session := connectTestClient(t, newServer(), hintsClientOptions(map[string]any{HintContextSize: "compact"}))
+		var p payload
+		initExtension(t, session, &p)
+		assert.Equal(t, "coding", p.RecommendedVariant)
+		require.Len(t, p.AvailableVariants, 2)
+		assert.Equal(t, "compact", p.AvailableVariants[1].ID)
+		assert.Equal(t, 1.0, p.AvailableVariants[1].Score)
+		assert.Equal(t, "contextSize matched", p.AvailableVariants[1].MatchReason)
+		assert.Zero(t, p.AvailableVariants[0].Score)
+	})
+
+	t.Run("RecommendFunc", func(t *testing.T) {
+		vs := newServer().WithRecommendation(func(_ context.Context, _ VariantHints, ranked []ServerVariant) string {
+			for _, v := range ranked {
+				if v.Score > 0 {
+					return v.ID
+				}
+			}
+			return ""
+		})
+		session := connectTestClient(t, vs, hintsClientOptions(map[string]any{HintContextSize: "compact"}))
+		var p payload
+		initExtension(t, session, &p)
+		assert.Equal(t, "compact", p.RecommendedVariant)
+		assert.Equal(t, "coding", p.AvailableVariants[0].ID)
+	})
+}
+
+// TestIntegration_NormalizedHints verifies that the hints the server used for
+// ranking are echoed back in the initialize payload.
+func TestIntegration_NormalizedHints(t *testing.T) {
+	vs := newTestVariantServer()
+	session := connectTestClient(t, vs, hintsClientOptions(map[string]any{
+		HintContextSize: []any{"compact"},
+		"notAHint":      "x",
+	}))
+
+	var p struct {
+		NormalizedHints struct {
+			Hints   map[string]any    `json:"hints"`
+			Ignored map[string]string `json:"ignored"`
+		} `json:"normalizedHints"`
+	}
+	initExtension(t, session, &p)
+	assert.Equal(t, map[string]any{HintContextSize: "compact"}, p.NormalizedHints.Hints)
+	assert.Equal(t, map[string]string{"notAHint": ignoredUnknownKey}, p.NormalizedHints.Ignored)
+}
+
+// contextSizeRanking ranks the variant whose ID equals the contextSize hint
+// first, falling back to the default ranking.
+func contextSizeRanking(ctx context.Context, hints VariantHints, vs []ServerVariant) []ServerVariant {
+	vs = defaultRankingFunc(ctx, hints, vs)
+	want, _ := HintValue[string](hints, HintContextSize)
+	for i, v := range vs {
+		if v.ID == want {
+			return append(append([]ServerVariant{v}, vs[:i]...), vs[i+1:]...)
+		}
+	}
+	return vs
+}
+
+// TestIntegration_HintUpdate verifies that hints sent in _meta after
+// initialize re-rank the session's variants, change the default used for
+// requests without a variant selection, and notify the client.
+func TestIntegration_HintUpdate(t *testing.T) {
+	vs := newTestVariantServer().WithRanking(contextSizeRanking)
+
+	updates := make(chan *mcp.ToolListChangedRequest, 1)
+	opts := hintsClientOptions(map[string]any{HintContextSize: "coding"})
+	opts.ToolListChangedHandler = func(_ context.Context, req *mcp.ToolListChangedRequest) {
+		updates <- req
+	}
+	session := connectTestClient(t, vs, opts)
+	ctx := context.Background()
+
+	tools, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.Contains(t, toolNames(tools.Tools), "analyze_code")
+
+	// Switch hints: the same request is already served by the new default.
+	tools, err = session.ListTools(ctx, &mcp.ListToolsParams{
+		Meta: mcp.Meta{metaKeyVariantHints: map[string]any{
+			"hints": map[string]any{HintContextSize: "compact"},
+		}},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, toolNames(tools.Tools), "summarize")
+
+	select {
+	case req := <-updates:
+		extJSON, err := json.Marshal(req.Params.Meta[extensionID])
+		require.NoError(t, err)
+		var p struct {
+			AvailableVariants []struct {
+				ID string `json:"id"`
+			} `json:"availableVariants"`
+			DefaultVariant string `json:"defaultVariant"`
+		}
+		require.NoError(t, json.Unmarshal(extJSON, &p))
+		assert.Equal(t, "compact", p.DefaultVariant)
+		require.Len(t, p.AvailableVariants, 2)
+		assert.Equal(t, "compact", p.AvailableVariants[0].ID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for variants update notification")
+	}
+
+	tools, err = session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.Contains(t, toolNames(tools.Tools), "summarize")
+}
+
+// TestIntegration_HintUpdateUnchanged verifies that repeating hints that
+// leave the session's ranking as is does not notify the client again.
+func TestIntegration_HintUpdateUnchanged(t *testing.T) {
+	vs := newTestVariantServer().WithRanking(contextSizeRanking)
+
+	updates := make(chan *mcp.ToolListChangedRequest, 4)
+	opts := hintsClientOptions(map[string]any{HintContextSize: "coding"})
+	opts.ToolListChangedHandler = func(_ context.Context, req *mcp.ToolListChangedRequest) {
+		updates <- req
+	}
+	session := connectTestClient(t, vs, opts)
+	ctx := context.Background()
+
+	sendHints := func(contextSize string) {
+		t.Helper()
+		_, err := session.ListTools(ctx, &mcp.ListToolsParams{
+			Meta: mcp.Meta{metaKeyVariantHints: map[string]any{
+				"hints": map[string]any{HintContextSize: contextSize},
+			}},
+		})
+		require.NoError(t, err)
+	}
+
+	sendHints("coding")
+	sendHints("compact")
+	sendHints("compact")
+
+	select {
+	case <-updates:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for variants update notification")
+	}
+	select {
+	case <-updates:
+		t.Fatal("unchanged hints notified the client")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
 // connectHTTPTestClient connects a client to an existing httptest server via
 // StreamableClientTransport. Returns the client session; cleanup is handled
 // via t.Cleanup.
@@ -246,6 +563,16 @@ func connectHTTPTestClient(t *testing.T, httpSrv *httptest.Server) *mcp.ClientSe
 	return session
 }
 
+// connectStatelessTestClient serves vs over stateless streamable HTTP and
+// connects a test client. Cleanup is handled via t.Cleanup.
+func connectStatelessTestClient(t *testing.T, vs *Server) *mcp.ClientSession {
+	t.Helper()
+	httpSrv := httptest.NewServer(NewStreamableHTTPHandler(vs, &mcp.StreamableHTTPOptions{Stateless: true}))
+	t.Cleanup(httpSrv.Close)
+	t.Cleanup(func() { vs.Close() })
+	return connectHTTPTestClient(t, httpSrv)
+}
+
 // TestIntegration_HTTP verifies the variant server works over HTTP with
 // multiple concurrent clients. Each client gets its own session with
 // independent variant routing.
@@ -456,3 +783,29 @@ func toolNames(tools []*mcp.Tool) []string {
 	}
 	return names
 }
+
+// TestIntegration_SSE verifies the variant server works over the legacy
+// HTTP+SSE transport with the same _meta routing semantics.
+func TestIntegration_SSE(t *testing.T) {
+	httpSrv := httptest.NewServer(NewSSEHandler(newTestVariantServer(), nil))
+	t.Cleanup(httpSrv.Close)
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-sse-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.SSEClientTransport{Endpoint: httpSrv.URL}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() })
+	ctx := context.Background()
+
+	assert.Contains(t, session.InitializeResult().Capabilities.Experimental, extensionID)
+
+	tools, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.Contains(t, toolNames(tools.Tools), "analyze_code")
+
+	tools, err = session.ListTools(ctx, &mcp.ListToolsParams{
+		Meta: mcp.Meta{metaKeyVariant: "compact"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, toolNames(tools.Tools), "summarize")
+	assert.NotContains(t, toolNames(tools.Tools), "analyze_code")
+}
@@ -7,7 +7,9 @@ package variants
 import (
 	"context"
 	"errors"
+	"maps"
 	"reflect"
+	"slices"
 	"sync"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -23,14 +25,17 @@ import (
 type innerConnection struct {
 	backendSession *backendSession
 	cleanupFn      func()
+	closeOnce      sync.Once
 }
 
 // close invokes the backend-specific cleanup function which tears down
-// both the client and server sessions.
+// both the client and server sessions. It is safe to call more than once.
 func (c *innerConnection) close() {
-	if c.cleanupFn != nil {
-		c.cleanupFn()
-	}
+	c.closeOnce.Do(func() {
+		if c.cleanupFn != nil {
+			c.cleanupFn()
+		}
+	})
 }
 
 // backendSession bypasses the in-memory transport and calls the inner
@@ -41,6 +46,7 @@ type backendSession struct {
 	variantID        string
 	serverSession    *mcp.ServerSession
 	mcpMethodHandler mcp.MethodHandler
+	remote           *remoteSession // non-nil for remote variants, which are called over the network instead
 }
 
 // handleReceive invokes mcpMethodHandler for any MCP method by modifying the request's
@@ -50,6 +56,9 @@ type backendSession struct {
 // The dispatcher is responsible for modifying params (metadata injection,
 // cursor unwrapping) before calling this method.
 func (s *backendSession) handleReceive(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+	if s.remote != nil {
+		return s.remote.call(ctx, method, req)
+	}
 	// Shallow-copy the concrete request struct so we don't mutate the caller's object
 	// while replacing the Session field with our inner server session.
 	// We can't use a wrapper (like the sending side's sessionSwappedRequest) because
@@ -80,9 +89,16 @@ type sessionState struct {
 
 // close tears down all inner connections for this session.
 func (ss *sessionState) close() {
-	for _, c := range ss.dispatcher.connections {
+	d := ss.dispatcher
+	d.connMu.Lock()
+	d.closed = true
+	for _, c := range d.connections {
 		c.close()
 	}
+	d.connMu.Unlock()
+	for _, p := range d.pools {
+		p.close()
+	}
 }
 
 // frontSessionKeyType is the context key for the front-facing ServerSession.
@@ -104,13 +120,31 @@ func injectVariantMeta(p mcp.Params, variantID string) {
 // ---------------------------------------------------------------------------
 
 // createSessionState sets up inner connections for all variants and returns
-// the per-session state.
-func (s *Server) createSessionState(ctx context.Context, frontSession *mcp.ServerSession) (*sessionState, error) {
+// the per-session state. Variants out of rotation whose backends cannot be
+// connected are connected on first use instead (see dispatcher.connection),
+// as are variants beyond SessionLimits.MaxConnectionsPerSession in the
+// session's ranking.
+func (s *Server) createSessionState(ctx context.Context, frontSession *mcp.ServerSession, ranked []ServerVariant) (*sessionState, error) {
 	connections := make(map[string]*innerConnection, len(s.variants))
+	var eager map[string]bool
+	if frontSession != nil {
+		eager = s.eagerConnections(ranked)
+	}
 
 	for _, entry := range s.variants {
+		if eager != nil && !eager[entry.variant.ID] {
+			continue
+		}
 		conn, err := entry.backend.connect(ctx, entry.variant, frontSession)
 		if err != nil {
+			e := Event{Kind: EventBackendUnhealthy, VariantID: entry.variant.ID, Err: err}
+			if frontSession != nil {
+				e.SessionID = frontSession.ID()
+			}
+			s.emit(ctx, e)
+			if !s.isAvailable(entry.variant.ID) {
+				continue
+			}
 			for _, c := range connections {
 				c.close()
 			}
@@ -118,65 +152,294 @@ func (s *Server) createSessionState(ctx context.Context, frontSession *mcp.Serve
 		}
 		connections[entry.variant.ID] = conn
 	}
+	s.warmUp(ctx, frontSession, slices.Collect(maps.Values(connections))...)
 
-	return &sessionState{
+	state := &sessionState{
 		dispatcher: &dispatcher{
-			server:      s,
-			connections: connections,
+			server:       s,
+			connections:  connections,
+			shared:       frontSession == nil,
+			frontSession: frontSession,
 		},
-	}, nil
+	}
+	if frontSession == nil && s.poolOpts.Size > 0 {
+		if err := s.createPools(ctx, state.dispatcher); err != nil {
+			state.close()
+			return nil, err
+		}
+	}
+	return state, nil
+}
+
+// createPools sets up the stateless connection pools configured with
+// Server.WithStatelessPool, each starting with the dispatcher's connection
+// to the variant.
+func (s *Server) createPools(ctx context.Context, d *dispatcher) error {
+	d.pools = make(map[string]*connPool, len(s.variants))
+	for _, entry := range s.variants {
+		id := entry.variant.ID
+		first, ok := d.connections[id]
+		if !ok {
+			// Not ready; served by a single connection once it is.
+			continue
+		}
+		conns := []*innerConnection{first}
+		for len(conns) < s.poolOpts.Size {
+			conn, err := entry.backend.connect(ctx, entry.variant, nil)
+			if err != nil {
+				s.emit(ctx, Event{Kind: EventBackendUnhealthy, VariantID: id, Err: err})
+				for _, c := range conns[1:] {
+					c.close()
+				}
+				return err
+			}
+			conns = append(conns, conn)
+		}
+		s.warmUp(ctx, nil, conns[1:]...)
+		d.pools[id] = newConnPool(id, conns, s.poolOpts)
+	}
+	return nil
 }
 
 // sessionMiddleware builds the receiving middleware that manages per-session
 // state and delegates to the variant dispatcher.
 //
-// When shared is non-nil (stateless mode), all requests use the shared
-// connections instead of creating per-session state.
-func (s *Server) sessionMiddleware(sessions *sync.Map, shared *sessionState) mcp.Middleware {
-	return func(next mcp.MethodHandler) mcp.MethodHandler {
-		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
-			ss := req.GetSession().(*mcp.ServerSession)
-
-			if method == "initialize" {
-				// Let the SDK handle init first (capability negotiation etc.)
-				result, err := next(ctx, method, req)
-				if err != nil {
+// In stateless mode, all requests use the shared connections instead of
+// creating per-session state.
+//
+// Every request's context carries a RankingRequest describing the client,
+// so that ranking performed on its behalf can consider who is connecting,
+// and its Caller if enabled (see WithCallerContext).
+func (r *VariantRouter) sessionMiddleware(next mcp.MethodHandler) mcp.MethodHandler {
+	s := r.server
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		ss := req.GetSession().(*mcp.ServerSession)
+		if s.closed() {
+			return nil, ErrServerClosed
+		}
+		ctx = s.withCaller(ctx, req)
+
+		if method == "initialize" {
+			// Let the SDK handle init first (capability negotiation etc.)
+			result, err := next(ctx, method, req)
+			if err != nil {
+				return nil, err
+			}
+			return r.handleInitialize(ctx, req, result)
+		}
+		if err := s.checkExtensionMeta(req); err != nil {
+			return nil, err
+		}
+
+		ctx = context.WithValue(ctx, rankingRequestKey{}, newRankingRequest(req, r.transport, nil))
+		ctx = withRequestCorrelation(ctx)
+
+		// Try per-session state first
+		if v, ok := r.sessions.Load(ss); ok {
+			state := v.(*sessionState)
+			if raw, ok := variantHintsFromMeta(req); ok {
+				if err := s.updateHints(ctx, ss, state.dispatcher, raw); err != nil {
 					return nil, err
 				}
+			}
+			return state.dispatcher.handle(ctx, method, req, next)
+		}
 
-				// In stateless mode, skip per-session connection creation;
-				// requests will use the shared connections.
-				if shared == nil {
-					state, err := s.createSessionState(ctx, ss)
-					if err != nil {
-						return nil, err
-					}
-					sessions.Store(ss, state)
-
-					// Clean up when the front session closes.
-					go func() {
-						ss.Wait()
-						sessions.Delete(ss)
-						state.close()
-					}()
-				}
+		// Fall back to shared state (stateless mode)
+		if r.shared != nil {
+			return r.shared.dispatcher.handle(ctx, method, req, next)
+		}
 
-				// Enrich the init result with variant information
-				return s.enrichInitResult(ctx, result, req)
-			}
+		return next(ctx, method, req)
+	}
+}
 
-			// Try per-session state first
-			if v, ok := sessions.Load(ss); ok {
-				state := v.(*sessionState)
-				return state.dispatcher.handle(ctx, method, req, next)
-			}
+// handleInitialize completes the initialize exchange once the SDK has
+// produced result: it ranks the variants for the client's hints (restoring
+// the default of a presented variant token), runs the initialize hooks,
+// sets up per-session state (stateful mode), and injects the variants
+// payload into the result.
+func (r *VariantRouter) handleInitialize(ctx context.Context, req mcp.Request, result mcp.Result) (mcp.Result, error) {
+	s := r.server
+	ss := req.GetSession().(*mcp.ServerSession)
+	initResult, _ := result.(*mcp.InitializeResult)
+	rr := newRankingRequest(req, r.transport, initResult)
+	ctx = context.WithValue(ctx, rankingRequestKey{}, rr)
 
-			// Fall back to shared state (stateless mode)
-			if shared != nil {
-				return shared.dispatcher.handle(ctx, method, req, next)
-			}
+	raw := extractVariantHints(req)
+	if err := s.checkHintLimits(raw); err != nil {
+		return nil, err
+	}
+	hints, report := s.normalizeHints(raw)
+	s.hintStats.record(s, raw, report)
+	fc := newFlagContext(ss, hints)
+	fc.ProtocolVersion = rr.ProtocolVersion // negotiated, not requested
+	ranked := s.rankForSession(ctx, fc, hints)
+	restored := s.restoredVariant(req, ranked)
+	ranked = restoreFirst(restored, ranked)
+
+	var stripped map[string]bool
+	if initResult != nil && len(s.initHooks) > 0 {
+		params, _ := req.GetParams().(*mcp.InitializeParams)
+		var err error
+		ranked, stripped, err = s.applyInitHooks(ctx, &InitializeExchange{
+			Params:   params,
+			Result:   initResult,
+			Hints:    hints,
+			Variants: ranked,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 
-			return next(ctx, method, req)
+	// In stateless mode, skip per-session connection creation;
+	// requests will use the shared connections.
+	var d *dispatcher
+	if r.shared == nil {
+		if !r.acquireSession() {
+			return nil, s.tooManySessionsError()
+		}
+		state, err := s.createSessionState(ctx, ss, ranked)
+		if err != nil {
+			r.releaseSession()
+			return nil, err
+		}
+		state.dispatcher.rankingReq = rr
+		state.dispatcher.initResult = initResult
+		state.dispatcher.restored = restored
+		state.dispatcher.setRanking(ctx, fc, ranked, stripped)
+		r.sessions.Store(ss, state)
+		if s.closed() {
+			// Lost a race with Close, which may have missed the state.
+			r.sessions.Delete(ss)
+			state.close()
+			r.releaseSession()
+			return nil, ErrServerClosed
 		}
+		d = state.dispatcher
+
+		// Clean up when the front session closes.
+		go func() {
+			ss.Wait()
+			r.sessions.Delete(ss)
+			state.close()
+			r.releaseSession()
+			if s.completions != nil {
+				s.completions.dropSession(ss.ID())
+			}
+			if s.logLimiter != nil {
+				s.logLimiter.dropSession(ss)
+			}
+			if s.progressThrottle != nil {
+				s.progressThrottle.dropSession(ss)
+			}
+		}()
+	} else {
+		d = r.shared.dispatcher
+	}
+
+	if defaultID, err := d.defaultVariant(ctx, req); err == nil {
+		s.emit(ctx, Event{Kind: EventSessionStarted, SessionID: ss.ID(), VariantID: defaultID})
+	}
+
+	if initResult != nil {
+		initResult.Instructions = s.sessionInstructions(ctx, d, ranked, initResult.Instructions)
+	}
+
+	// Enrich the init result with variant information
+	return s.enrichInitResult(ctx, result, d, hints, report, ranked)
+}
+
+// ---------------------------------------------------------------------------
+// Hint updates
+// ---------------------------------------------------------------------------
+
+// variantHintsFromMeta extracts a variantHints update from the request's
+// _meta field. Clients send it alongside any request after initialize, e.g.
+// when the agent switches model or its context budget shrinks:
+//
+//	_meta["io.modelcontextprotocol/server-variant-hints"] = {"hints": {...}}
+func variantHintsFromMeta(req mcp.Request) (VariantHints, bool) {
+	params := req.GetParams()
+	if isNilInterface(params) {
+		return VariantHints{}, false
+	}
+	return parseVariantHints(params.GetMeta()[metaKeyVariantHints])
+}
+
+// updateHints re-ranks the variants for a session whose client sent updated
+// hints (see rerankSession), records the new ranking (and so possibly a new
+// default), and notifies the client with the updated variants payload.
+// Clients may repeat their hints on every request; if neither the
+// normalized hints nor the ranking changed, the client is not notified.
+//
+// Hint updates are only honored in stateful mode; in stateless mode there is
+// no session to store them in and they are ignored.
+func (s *Server) updateHints(ctx context.Context, ss *mcp.ServerSession, d *dispatcher, raw VariantHints) error {
+	if err := s.checkHintLimits(raw); err != nil {
+		return err
+	}
+	hints, report := s.normalizeHints(raw)
+	s.hintStats.record(s, raw, report)
+	fc := newFlagContext(ss, hints)
+	d.mu.RLock()
+	fc.ProtocolVersion = d.flagCtx.ProtocolVersion
+	d.mu.RUnlock()
+	ranked, stripped, err := s.rerankSession(ctx, ss, d, fc, hints)
+	if err != nil {
+		return err
+	}
+	d.mu.RLock()
+	unchanged := reflect.DeepEqual(d.hints, hints) && reflect.DeepEqual(d.ranked, ranked)
+	d.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+	defaultChanged := d.setRanking(ctx, fc, ranked, stripped)
+
+	payload, err := s.variantsPayload(ctx, d, hints, report, ranked)
+	if err != nil {
+		return err
+	}
+	s.notifyVariantsChanged(ctx, ss, d, payload, defaultChanged)
+	return nil
+}
+
+// notifyVariantsChanged sends the updated variants payload to a front
+// session. The payload travels in the _meta of a tools/list_changed
+// notification under the extension ID: the SDK only sends spec-defined
+// notification methods, and for variant-unaware clients a new default
+// variant does change the tool list. When the default changed, prompt and
+// resource list_changed notifications are sent as well. Each notification
+// is only sent if the session's client was told at initialize that the list
+// can change (listChanged in the server capabilities).
+//
+// Delivery failures are not reported; the client can always re-initialize.
+func (s *Server) notifyVariantsChanged(ctx context.Context, ss *mcp.ServerSession, d *dispatcher, payload *AvailableVariantsPayload, defaultChanged bool) {
+	if s.frontSendingHandler == nil || d.initResult == nil || d.initResult.Capabilities == nil {
+		return
+	}
+	caps := d.initResult.Capabilities
+	if caps.Tools != nil && caps.Tools.ListChanged {
+		_, _ = s.frontSendingHandler(ctx, "notifications/tools/list_changed", &mcp.ServerRequest[*mcp.ToolListChangedParams]{
+			Session: ss,
+			Params:  &mcp.ToolListChangedParams{Meta: mcp.Meta{extensionID: payload}},
+		})
+	}
+	if !defaultChanged {
+		return
+	}
+	if caps.Prompts != nil && caps.Prompts.ListChanged {
+		_, _ = s.frontSendingHandler(ctx, "notifications/prompts/list_changed", &mcp.ServerRequest[*mcp.PromptListChangedParams]{
+			Session: ss,
+			Params:  &mcp.PromptListChangedParams{},
+		})
+	}
+	if caps.Resources != nil && caps.Resources.ListChanged {
+		_, _ = s.frontSendingHandler(ctx, "notifications/resources/list_changed", &mcp.ServerRequest[*mcp.ResourceListChangedParams]{
+			Session: ss,
+			Params:  &mcp.ResourceListChangedParams{},
+		})
 	}
 }
@@ -0,0 +1,133 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// CodeSessionLimitExceeded is the JSON-RPC error code returned when a
+// session limit configured with [Server.WithSessionLimits] is reached.
+const CodeSessionLimitExceeded int64 = -32052
+
+// SessionLimits cap the memory held by stateful sessions, so that a burst
+// of clients cannot exhaust it by fanning out connections to every
+// variant. See Server.WithSessionLimits.
+type SessionLimits struct {
+	// MaxSessions caps the number of concurrent stateful sessions.
+	// Initialize requests beyond it fail with a retriable
+	// [CodeSessionLimitExceeded] error suggesting a retry delay. Zero means
+	// no limit.
+	MaxSessions int
+
+	// MaxConnectionsPerSession caps the number of variants each session
+	// connects to. A session connects to its first-ranked variants at
+	// initialize, and to further variants on first use until the cap is
+	// reached; requests for other variants then fail with a
+	// [CodeSessionLimitExceeded] error listing the connected variants. Zero
+	// means no limit.
+	MaxConnectionsPerSession int
+
+	// RetryAfter is the retry delay suggested to clients rejected by
+	// MaxSessions. Zero means 5s.
+	RetryAfter time.Duration
+}
+
+const defaultSessionRetryAfter = 5 * time.Second
+
+// WithSessionLimits caps the number of concurrent sessions and of variant
+// connections per session. Limits only apply in stateful mode; in stateless
+// mode requests share one set of connections (see WithStatelessPool).
+//
+// Returns the receiver for chaining. Panics if a limit is negative.
+func (s *Server) WithSessionLimits(l SessionLimits) *Server {
+	if l.MaxSessions < 0 || l.MaxConnectionsPerSession < 0 || l.RetryAfter < 0 {
+		panic("variants: negative session limit")
+	}
+	s.sessionLimits = l
+	return s
+}
+
+// acquireSession reserves a slot for a new stateful session under
+// MaxSessions, returning false if none is left. Slots are returned with
+// releaseSession.
+func (r *VariantRouter) acquireSession() bool {
+	bound := int64(r.server.sessionLimits.MaxSessions)
+	for {
+		n := r.sessionCount.Load()
+		if bound > 0 && n >= bound {
+			return false
+		}
+		if r.sessionCount.CompareAndSwap(n, n+1) {
+			return true
+		}
+	}
+}
+
+// releaseSession returns a slot reserved with acquireSession.
+func (r *VariantRouter) releaseSession() {
+	r.sessionCount.Add(-1)
+}
+
+// eagerConnections returns the IDs of the variants a new session connects
+// to at initialize under MaxConnectionsPerSession, its first-ranked
+// variants, or nil if it connects to all.
+func (s *Server) eagerConnections(ranked []ServerVariant) map[string]bool {
+	n := s.sessionLimits.MaxConnectionsPerSession
+	if n == 0 {
+		return nil
+	}
+	eager := make(map[string]bool, n)
+	for _, v := range ranked[:min(n, len(ranked))] {
+		eager[v.ID] = true
+	}
+	return eager
+}
+
+// tooManySessionsError returns the retriable error for an initialize
+// request beyond MaxSessions.
+func (s *Server) tooManySessionsError() error {
+	retry := s.sessionLimits.RetryAfter
+	if retry == 0 {
+		retry = defaultSessionRetryAfter
+	}
+	dataJSON, _ := json.Marshal(map[string]any{
+		"limit":        "maxSessions",
+		"max":          s.sessionLimits.MaxSessions,
+		"retriable":    true,
+		"retryAfterMs": retry.Milliseconds(),
+	})
+	return &jsonrpc.Error{
+		Code:    CodeSessionLimitExceeded,
+		Message: "Too many sessions",
+		Data:    json.RawMessage(dataJSON),
+	}
+}
+
+// tooManyConnectionsError returns the error for a request selecting a
+// variant a session cannot connect to under MaxConnectionsPerSession.
+// Called with d.connMu held.
+func (d *dispatcher) tooManyConnectionsError(variantID string) error {
+	connected := make([]string, 0, len(d.connections))
+	for _, entry := range d.server.variants {
+		if _, ok := d.connections[entry.variant.ID]; ok {
+			connected = append(connected, entry.variant.ID)
+		}
+	}
+	dataJSON, _ := json.Marshal(map[string]any{
+		"limit":             "maxConnectionsPerSession",
+		"max":               d.server.sessionLimits.MaxConnectionsPerSession,
+		"requestedVariant":  variantID,
+		"connectedVariants": connected,
+	})
+	return &jsonrpc.Error{
+		Code:    CodeSessionLimitExceeded,
+		Message: "Session connection limit reached",
+		Data:    json.RawMessage(dataJSON),
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionLimits_MaxSessions(t *testing.T) {
+	vs := newTestVariantServer().WithSessionLimits(SessionLimits{MaxSessions: 1, RetryAfter: 2 * time.Second})
+	httpSrv := httptest.NewServer(NewStreamableHTTPHandler(vs, nil))
+	t.Cleanup(httpSrv.Close)
+	t.Cleanup(func() { vs.Close() })
+	ctx := context.Background()
+	connect := func() (*mcp.ClientSession, error) {
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+		return client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: httpSrv.URL, MaxRetries: -1}, nil)
+	}
+
+	first, err := connect()
+	require.NoError(t, err)
+
+	_, err = connect()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Too many sessions")
+
+	// Closing a session frees its slot.
+	require.NoError(t, first.Close())
+	assert.Eventually(t, func() bool {
+		s, err := connect()
+		if err != nil {
+			return false
+		}
+		s.Close()
+		return true
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func TestSessionLimits_TooManySessionsError(t *testing.T) {
+	vs := newTestVariantServer().WithSessionLimits(SessionLimits{MaxSessions: 3})
+	var jErr *jsonrpc.Error
+	require.True(t, errors.As(vs.tooManySessionsError(), &jErr))
+	assert.Equal(t, CodeSessionLimitExceeded, jErr.Code)
+	assert.JSONEq(t, `{"limit":"maxSessions","max":3,"retriable":true,"retryAfterMs":5000}`, string(jErr.Data))
+}
+
+func TestSessionLimits_MaxConnectionsPerSession(t *testing.T) {
+	vs := newTestVariantServer().WithSessionLimits(SessionLimits{MaxConnectionsPerSession: 1})
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	sessions := vs.Sessions()
+	require.Len(t, sessions, 1)
+	assert.Equal(t, []ConnectionInfo{{VariantID: "coding", Connected: true}, {VariantID: "compact", Connected: false}}, sessions[0].Connections)
+
+	_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "analyze_code", Arguments: map[string]any{"code": "x", "language": "go"}})
+	require.NoError(t, err)
+
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      mcp.Meta{metaKeyVariant: "compact"},
+		Name:      "summarize",
+		Arguments: map[string]any{"text": "x"},
+	})
+	var jErr *jsonrpc.Error
+	require.True(t, errors.As(err, &jErr))
+	assert.Equal(t, CodeSessionLimitExceeded, jErr.Code)
+	assert.JSONEq(t, `{"limit":"maxConnectionsPerSession","max":1,"requestedVariant":"compact","connectedVariants":["coding"]}`, string(jErr.Data))
+}
+
+func TestSessionLimits_Lazy(t *testing.T) {
+	vs := newTestVariantServer().WithSessionLimits(SessionLimits{MaxConnectionsPerSession: 2})
+	vs.SetVariantAvailability("compact", false, "maintenance")
+	session := connectTestClient(t, vs, nil)
+
+	// Variants out of rotation at initialize are connected on first use.
+	assert.False(t, vs.Sessions()[0].Connections[1].Connected)
+	vs.SetVariantAvailability("compact", true, "")
+	_, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Meta:      mcp.Meta{metaKeyVariant: "compact"},
+		Name:      "summarize",
+		Arguments: map[string]any{"text": "x"},
+	})
+	require.NoError(t, err)
+	assert.True(t, vs.Sessions()[0].Connections[1].Connected)
+}
+
+func TestWithSessionLimits_Negative(t *testing.T) {
+	assert.Panics(t, func() { newTestVariantServer().WithSessionLimits(SessionLimits{MaxSessions: -1}) })
+}
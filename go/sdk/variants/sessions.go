@@ -0,0 +1,210 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ErrUnknownSession is returned by the session administration methods of
+// Server for session IDs without a stateful session.
+var ErrUnknownSession = errors.New("variants: unknown session")
+
+// SessionInfo describes a stateful client session, for operators debugging
+// a specific user's session. See Server.Sessions.
+type SessionInfo struct {
+	// ID is the MCP session ID, as in the Mcp-Session-Id header of the
+	// streamable HTTP transport. It is empty for transports without
+	// session IDs.
+	ID string `json:"id"`
+
+	// DefaultVariant is the variant used for requests that select none.
+	DefaultVariant string `json:"defaultVariant,omitempty"`
+
+	// PinnedVariant is the variant pinned with Server.PinSession, if any.
+	PinnedVariant string `json:"pinnedVariant,omitempty"`
+
+	// Hints are the client's current variant hints.
+	Hints VariantHints `json:"hints"`
+
+	// RankedVariants are the IDs of the variants ranked for the session.
+	RankedVariants []string `json:"rankedVariants"`
+
+	// Connections are the session's connections to the variants, in
+	// registration order.
+	Connections []ConnectionInfo `json:"connections"`
+}
+
+// ConnectionInfo describes a session's connection to a variant.
+type ConnectionInfo struct {
+	VariantID string `json:"variantId"`
+
+	// Connected reports whether the session is connected to the variant.
+	// Variants whose backends were not ready when the session started are
+	// connected on first use (see WithStartupPolicy).
+	Connected bool `json:"connected"`
+}
+
+// Sessions returns the stateful sessions of the server's routers, ordered by
+// ID. It returns nil before serving starts and in stateless mode, where
+// sessions share their state.
+func (s *Server) Sessions() []SessionInfo {
+	var infos []SessionInfo
+	s.rangeSessions(func(ss *mcp.ServerSession, d *dispatcher) bool {
+		infos = append(infos, s.sessionInfo(ss, d))
+		return true
+	})
+	slices.SortFunc(infos, func(a, b SessionInfo) int { return strings.Compare(a.ID, b.ID) })
+	return infos
+}
+
+// Session returns the stateful session with the given ID. It returns
+// ErrUnknownSession if there is none.
+func (s *Server) Session(id string) (SessionInfo, error) {
+	ss, d, err := s.lookupSession(id)
+	if err != nil {
+		return SessionInfo{}, err
+	}
+	return s.sessionInfo(ss, d), nil
+}
+
+// CloseSession closes the stateful session with the given ID, e.g. to
+// force a misbehaving client to reconnect. Its connections to the variants
+// are torn down. It returns ErrUnknownSession if there is no such session.
+func (s *Server) CloseSession(id string) error {
+	ss, _, err := s.lookupSession(id)
+	if err != nil {
+		return err
+	}
+	return ss.Close()
+}
+
+// PinSession pins a session to a variant, which then serves the session's
+// requests that select no variant, taking precedence over its ranking and
+// WithDefaultVariant. While the variant is out of rotation or disabled for
+// the client, the session falls back to its usual default. An empty
+// variantID removes the pin.
+//
+// A stateful session is pinned locally, and a variant-aware client is sent
+// its updated variants payload, as for hint updates. It returns
+// ErrUnknownSession if there is no such session, and an error if no
+// variant with the given ID is registered.
+//
+// With a shared store (see WithSharedStore), a server serving stateless
+// sessions stores the pin of any other session ID for the replicas of the
+// deployment, so that stateless sessions are pinned on every replica.
+// Stateless sessions keep no state on the server, so their IDs cannot be
+// validated: a mistyped ID is stored like any other, and expires with the
+// store's PinTTL. Stateful sessions are only pinned through the replica
+// serving them; pinning one through another replica stores an unused pin.
+func (s *Server) PinSession(id, variantID string) error {
+	if variantID != "" && !s.hasVariant(variantID) {
+		return fmt.Errorf("variants: unknown variant %q", variantID)
+	}
+	ss, d, err := s.lookupSession(id)
+	if err != nil {
+		if s.store == nil || id == "" || !s.servesStateless() {
+			return err
+		}
+		return s.store.setPin(context.Background(), id, variantID)
+	}
+	ctx := d.rankingContext()
+	before, _ := d.defaultVariant(ctx, nil)
+	d.mu.Lock()
+	d.pinned = variantID
+	hints, ranked := d.hints, d.ranked
+	d.mu.Unlock()
+	after, _ := d.defaultVariant(ctx, nil)
+
+	if supportsVariants(ss) {
+		payload, err := s.variantsPayload(ctx, d, hints, NormalizedHints{}, ranked)
+		if err == nil {
+			s.notifyVariantsChanged(ctx, ss, d, payload, before != after)
+		}
+	}
+	return nil
+}
+
+// rangeSessions calls fn for each stateful session of the server's routers
+// until fn returns false.
+func (s *Server) rangeSessions(fn func(*mcp.ServerSession, *dispatcher) bool) {
+	for _, r := range s.routerList() {
+		more := true
+		r.sessions.Range(func(k, v any) bool {
+			more = fn(k.(*mcp.ServerSession), v.(*sessionState).dispatcher)
+			return more
+		})
+		if !more {
+			return
+		}
+	}
+}
+
+// servesStateless reports whether one of the server's routers serves
+// stateless sessions.
+func (s *Server) servesStateless() bool {
+	return slices.ContainsFunc(s.routerList(), func(r *VariantRouter) bool { return r.shared != nil })
+}
+
+// lookupSession returns the stateful session with the given ID.
+func (s *Server) lookupSession(id string) (*mcp.ServerSession, *dispatcher, error) {
+	var (
+		found *mcp.ServerSession
+		fd    *dispatcher
+	)
+	s.rangeSessions(func(ss *mcp.ServerSession, d *dispatcher) bool {
+		if ss.ID() == id {
+			found, fd = ss, d
+			return false
+		}
+		return true
+	})
+	if found == nil {
+		return nil, nil, fmt.Errorf("%w %q", ErrUnknownSession, id)
+	}
+	return found, fd, nil
+}
+
+// sessionInfo describes a session.
+func (s *Server) sessionInfo(ss *mcp.ServerSession, d *dispatcher) SessionInfo {
+	ctx := d.rankingContext()
+	defaultID, _ := d.defaultVariant(ctx, nil)
+	d.mu.RLock()
+	info := SessionInfo{
+		ID:             ss.ID(),
+		DefaultVariant: defaultID,
+		PinnedVariant:  d.pinned,
+		Hints:          d.hints,
+		RankedVariants: make([]string, len(d.ranked)),
+	}
+	for i, v := range d.ranked {
+		info.RankedVariants[i] = v.ID
+	}
+	d.mu.RUnlock()
+
+	d.connMu.RLock()
+	for _, entry := range s.variants {
+		_, ok := d.connections[entry.variant.ID]
+		info.Connections = append(info.Connections, ConnectionInfo{VariantID: entry.variant.ID, Connected: ok})
+	}
+	d.connMu.RUnlock()
+	return info
+}
+
+// rankingContext returns a context carrying the session's RankingRequest,
+// for ranking its variants outside a request.
+func (d *dispatcher) rankingContext() context.Context {
+	ctx := context.Background()
+	if d.rankingReq != nil {
+		ctx = context.WithValue(ctx, rankingRequestKey{}, d.rankingReq)
+	}
+	return ctx
+}
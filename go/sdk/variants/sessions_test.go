@@ -0,0 +1,63 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessions(t *testing.T) {
+	vs := newTestVariantServer()
+	assert.Nil(t, vs.Sessions())
+
+	httpSrv := httptest.NewServer(NewStreamableHTTPHandler(vs, nil))
+	t.Cleanup(httpSrv.Close)
+	t.Cleanup(func() { vs.Close() })
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, hintsClientOptions(nil))
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: httpSrv.URL}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() })
+
+	sessions := vs.Sessions()
+	require.Len(t, sessions, 1)
+	info := sessions[0]
+	assert.Equal(t, session.ID(), info.ID)
+	assert.Equal(t, "coding", info.DefaultVariant)
+	assert.Empty(t, info.PinnedVariant)
+	assert.Equal(t, []string{"coding", "compact"}, info.RankedVariants)
+	assert.Equal(t, []ConnectionInfo{{VariantID: "coding", Connected: true}, {VariantID: "compact", Connected: true}}, info.Connections)
+
+	_, err = vs.Session("nope")
+	assert.True(t, errors.Is(err, ErrUnknownSession))
+	assert.True(t, errors.Is(vs.PinSession("nope", "compact"), ErrUnknownSession))
+	assert.Error(t, vs.PinSession(session.ID(), "nope"))
+
+	// Pinning changes the default variant of the session.
+	require.NoError(t, vs.PinSession(session.ID(), "compact"))
+	info, err = vs.Session(session.ID())
+	require.NoError(t, err)
+	assert.Equal(t, "compact", info.DefaultVariant)
+	assert.Equal(t, "compact", info.PinnedVariant)
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "summarize", Arguments: map[string]any{"text": "x"}})
+	require.NoError(t, err)
+
+	require.NoError(t, vs.PinSession(session.ID(), ""))
+	info, err = vs.Session(session.ID())
+	require.NoError(t, err)
+	assert.Equal(t, "coding", info.DefaultVariant)
+
+	// Closing the session removes it.
+	require.NoError(t, vs.CloseSession(session.ID()))
+	assert.Eventually(t, func() bool { return len(vs.Sessions()) == 0 }, time.Second, 10*time.Millisecond)
+}
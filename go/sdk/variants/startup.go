@@ -0,0 +1,118 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// StartupPolicy configures how serving starts when variant backends are not
+// yet ready, e.g. remote servers or subprocesses still booting alongside the
+// variant server. See Server.WithStartupPolicy.
+type StartupPolicy struct {
+	// Timeout bounds how long backends are retried when serving starts.
+	// Backends are probed concurrently, each for up to Timeout. Zero means
+	// a single attempt per backend.
+	Timeout time.Duration
+
+	// Backoff is the delay before the first retry; it doubles after each
+	// failed attempt, up to MaxBackoff. Zero means 100ms.
+	Backoff time.Duration
+
+	// MaxBackoff caps the delay between attempts. Zero means 5s.
+	MaxBackoff time.Duration
+
+	// Background starts serving even if backends are still not ready after
+	// Timeout: their variants are taken out of rotation (see
+	// Server.SetVariantAvailability) and retried in the background until
+	// they become ready or the server is closed. Otherwise serving fails.
+	Background bool
+}
+
+const (
+	defaultStartupBackoff    = 100 * time.Millisecond
+	defaultStartupMaxBackoff = 5 * time.Second
+)
+
+// WithStartupPolicy sets how backends that are not ready when serving
+// starts are handled, for deployments where backends become ready
+// eventually. By default each backend is probed once and serving fails if
+// any probe fails.
+//
+// With p.Background, a variant whose backend becomes ready in the
+// background returns to rotation (unless it was taken out of rotation
+// otherwise meanwhile) and existing sessions connect to it on first use.
+// Its capabilities and instructions are not merged into the front server's,
+// which are fixed when serving starts.
+//
+// Returns the receiver for chaining. Panics if a duration is negative.
+func (s *Server) WithStartupPolicy(p StartupPolicy) *Server {
+	if p.Timeout < 0 || p.Backoff < 0 || p.MaxBackoff < 0 {
+		panic("variants: negative startup policy duration")
+	}
+	s.startup = p
+	return s
+}
+
+// backoffs returns the policy's initial and maximum retry delays.
+func (p StartupPolicy) backoffs() (initial, max time.Duration) {
+	initial, max = p.Backoff, p.MaxBackoff
+	if initial == 0 {
+		initial = defaultStartupBackoff
+	}
+	if max == 0 {
+		max = defaultStartupMaxBackoff
+	}
+	return min(initial, max), max
+}
+
+// probeWithRetry probes a backend, retrying with backoff until it succeeds
+// or ctx is done. Without retry, the backend is probed once.
+func (s *Server) probeWithRetry(ctx context.Context, b backend, retry bool) (*mcp.InitializeResult, error) {
+	delay, maxDelay := s.startup.backoffs()
+	for {
+		res, err := b.probe(ctx)
+		if err == nil || !retry {
+			return res, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, maxDelay)
+	}
+}
+
+// startupReason is the unavailability reason of a variant whose backend
+// was not ready when serving started.
+func startupReason(err error) string {
+	return fmt.Sprintf("backend not ready: %v", err)
+}
+
+// awaitBackends retries the backends of pending variants in the background
+// until they become ready, returning each to rotation, or until ctx is
+// canceled by Close. pending maps variant IDs to their startupReason.
+func (s *Server) awaitBackends(ctx context.Context, pending map[string]string) {
+	for id, reason := range pending {
+		entry := s.variants[s.variantIndex[id]]
+		go func() {
+			res, err := s.probeWithRetry(ctx, entry.backend, true)
+			if err != nil {
+				return
+			}
+			if res != nil {
+				s.setVariantCapabilities(id, res.Capabilities)
+			}
+			// Leave the variant out of rotation if it was taken out
+			// otherwise meanwhile.
+			s.setAvailability(id, true, "", &reason)
+		}()
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// StartupReport describes a server's variants and the discovery of their
+// backends when serving started, so that misconfiguration shows at boot
+// rather than at the first client request. It is passed to the function
+// set with [Server.WithStartupReport], returned by [Server.StartupReport],
+// is JSON-serializable, and implements [slog.LogValuer] for logging:
+//
+//	vs.WithStartupReport(func(r *variants.StartupReport) {
+//		logger.Info("variants started", "report", r)
+//	})
+//
+// The report is a snapshot: backends that become ready later (see
+// WithStartupPolicy) and later changes of availability are not reflected.
+type StartupReport struct {
+	// Variants are the registered variants, in registration order.
+	Variants []StartupVariant `json:"variants"`
+
+	// DefaultVariant is the variant serving requests that select none,
+	// for clients without hints: the one set with WithDefaultVariant, or
+	// else the first-ranked variant in rotation. Empty if no variant is in
+	// rotation.
+	DefaultVariant string `json:"defaultVariant"`
+
+	// Capabilities is the union of the variants' capabilities, which the
+	// front server advertises.
+	Capabilities *mcp.ServerCapabilities `json:"capabilities"`
+
+	// Conflicts are the tools that several variants list with different
+	// input schemas, for which arguments valid in one variant may be
+	// rejected by another.
+	Conflicts []ToolConflict `json:"conflicts,omitempty"`
+
+	// Violations are the violations of the variants extension found in
+	// the configuration, as reported by Validate.
+	Violations []Violation `json:"violations,omitempty"`
+
+	// Discovery is how long the discovery of the backends took.
+	Discovery time.Duration `json:"discovery"`
+}
+
+// StartupVariant describes a variant and the discovery of its backend in a
+// StartupReport.
+type StartupVariant struct {
+	ID       string        `json:"id"`
+	Status   VariantStatus `json:"status,omitempty"`
+	Priority int           `json:"priority"`
+	Backend  BackendKind   `json:"backend"`
+
+	// Ready reports whether the backend could be connected. If not, Error
+	// says why, and the variant is out of rotation (see WithStartupPolicy).
+	Ready bool   `json:"ready"`
+	Error string `json:"error,omitempty"`
+
+	// Available reports whether the variant is in rotation, and if not,
+	// Reason is the reason given to SetVariantAvailability.
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"`
+
+	// ServerInfo and Capabilities are those the backend reported, and
+	// Tools is the number of tools it lists, for ready backends.
+	ServerInfo   *mcp.Implementation     `json:"serverInfo,omitempty"`
+	Capabilities *mcp.ServerCapabilities `json:"capabilities,omitempty"`
+	Tools        int                     `json:"tools"`
+}
+
+// ToolConflict is a tool that several variants list with different input
+// schemas.
+type ToolConflict struct {
+	Tool     string   `json:"tool"`
+	Variants []string `json:"variants"`
+}
+
+// WithStartupReport makes the server build a StartupReport when serving
+// starts, and pass it to fn if fn is not nil. The report is also available
+// from StartupReport. Building it lists the tools of each backend once, in
+// addition to probing its capabilities.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithStartupReport(fn func(*StartupReport)) *Server {
+	s.startupReportFn = fn
+	s.startupReporting = true
+	return s
+}
+
+// StartupReport returns the report built when serving started, or nil if
+// serving has not started or WithStartupReport was not called.
+func (s *Server) StartupReport() *StartupReport {
+	return s.startupReport.Load()
+}
+
+// discovery records the discovery of a variant's backend for the startup
+// report.
+type discovery struct {
+	res *mcp.InitializeResult
+	err error
+}
+
+// buildStartupReport builds the startup report from the discovery of the
+// backends, indexed like s.variants, and reports it.
+func (s *Server) buildStartupReport(ctx context.Context, caps *mcp.ServerCapabilities, found []discovery, took time.Duration) {
+	r := &StartupReport{
+		Capabilities: caps,
+		Violations:   s.Validate(ctx),
+		Discovery:    took,
+	}
+	schemas := make(map[string][]toolSchema)
+	var names []string
+	for i, e := range s.variants {
+		v := StartupVariant{
+			ID:       e.variant.ID,
+			Status:   e.variant.Status,
+			Priority: e.variant.priority,
+			Backend:  e.backend.kind(),
+			Ready:    found[i].err == nil,
+		}
+		v.Available, v.Reason = s.VariantAvailability(v.ID)
+		if found[i].err != nil {
+			v.Error = found[i].err.Error()
+		} else if res := found[i].res; res != nil {
+			v.ServerInfo, v.Capabilities = res.ServerInfo, res.Capabilities
+		}
+		if v.Ready {
+			tools, err := e.backend.tools(ctx)
+			if err != nil {
+				v.Error = "listing tools: " + err.Error()
+			}
+			v.Tools = len(tools)
+			for _, t := range tools {
+				schema, _ := json.Marshal(t.InputSchema)
+				if _, ok := schemas[t.Name]; !ok {
+					names = append(names, t.Name)
+				}
+				schemas[t.Name] = append(schemas[t.Name], toolSchema{v.ID, schema})
+			}
+		}
+		r.Variants = append(r.Variants, v)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		ts := schemas[name]
+		if !slices.ContainsFunc(ts[1:], func(t toolSchema) bool { return !bytes.Equal(t.schema, ts[0].schema) }) {
+			continue
+		}
+		c := ToolConflict{Tool: name}
+		for _, t := range ts {
+			c.Variants = append(c.Variants, t.variantID)
+		}
+		r.Conflicts = append(r.Conflicts, c)
+	}
+	if id, err := s.defaultVariant(ctx); err == nil {
+		r.DefaultVariant = id
+	}
+
+	s.startupReport.Store(r)
+	if s.startupReportFn != nil {
+		s.startupReportFn(r)
+	}
+}
+
+// toolSchema is a variant's input schema of a tool, as JSON.
+type toolSchema struct {
+	variantID string
+	schema    []byte
+}
+
+// LogValue implements [slog.LogValuer], logging the default variant, each
+// variant's status, backend and discovery result, and the conflicts and
+// violations.
+func (r *StartupReport) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("defaultVariant", r.DefaultVariant),
+		slog.Duration("discovery", r.Discovery),
+	}
+	for _, v := range r.Variants {
+		group := []any{
+			slog.String("status", string(v.Status)),
+			slog.String("backend", string(v.Backend)),
+			slog.Bool("ready", v.Ready),
+			slog.Bool("available", v.Available),
+			slog.Int("tools", v.Tools),
+		}
+		if v.Error != "" {
+			group = append(group, slog.String("error", v.Error))
+		}
+		if v.Reason != "" {
+			group = append(group, slog.String("reason", v.Reason))
+		}
+		attrs = append(attrs, slog.Group("variant."+v.ID, group...))
+	}
+	for _, c := range r.Conflicts {
+		attrs = append(attrs, slog.Any("conflict."+c.Tool, c.Variants))
+	}
+	// Several violations may share a rule and variant, so they are logged
+	// by index.
+	for i, v := range r.Violations {
+		group := []any{slog.String("rule", v.Rule)}
+		if v.VariantID != "" {
+			group = append(group, slog.String("variant", v.VariantID))
+		}
+		group = append(group, slog.String("detail", v.Detail))
+		attrs = append(attrs, slog.Group("violation."+strconv.Itoa(i), group...))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type analyzeFileInput struct {
+	Path string `json:"path"`
+}
+
+func TestStartupReport(t *testing.T) {
+	alt := mcp.NewServer(&mcp.Implementation{Name: "alt-server", Version: "v2.0.0"}, nil)
+	mcp.AddTool(alt, &mcp.Tool{Name: "analyze_code"}, func(context.Context, *mcp.CallToolRequest, analyzeFileInput) (*mcp.CallToolResult, any, error) {
+		return nil, nil, nil
+	})
+	var reported *StartupReport
+	vs := newTestVariantServer().
+		WithVariant(ServerVariant{ID: "alt", Status: Experimental}, alt, 2).
+		WithStartupReport(func(r *StartupReport) { reported = r })
+	assert.Nil(t, vs.StartupReport(), "no report before serving")
+
+	connectTestClient(t, vs, nil)
+	require.NotNil(t, reported)
+	assert.Same(t, reported, vs.StartupReport())
+
+	require.Len(t, reported.Variants, 3)
+	assert.Equal(t, StartupVariant{
+		ID:           "coding",
+		Status:       Stable,
+		Priority:     0,
+		Backend:      BackendInMemory,
+		Ready:        true,
+		Available:    true,
+		ServerInfo:   &mcp.Implementation{Name: "coding-server", Version: "v1.0.0"},
+		Capabilities: reported.Variants[0].Capabilities,
+		Tools:        2,
+	}, reported.Variants[0])
+	assert.NotNil(t, reported.Variants[0].Capabilities.Tools)
+	assert.Equal(t, "alt-server", reported.Variants[2].ServerInfo.Name)
+	assert.Equal(t, 1, reported.Variants[2].Tools)
+
+	assert.Equal(t, "coding", reported.DefaultVariant)
+	assert.NotNil(t, reported.Capabilities.Tools)
+	assert.Equal(t, []ToolConflict{{Tool: "analyze_code", Variants: []string{"coding", "alt"}}}, reported.Conflicts)
+	assert.Contains(t, reported.Violations, Violation{Rule: RuleMissingDescription, VariantID: "alt", Detail: `variant "alt" has no description`})
+}
+
+func TestStartupReport_BackendNotReady(t *testing.T) {
+	httpSrv := httptest.NewServer(http.NotFoundHandler())
+	endpoint := httpSrv.URL
+	httpSrv.Close()
+
+	vs := newTestVariantServer().
+		WithRemoteVariant(ServerVariant{ID: "remote", Description: "Remote"}, endpoint, 0).
+		WithDefaultVariant("compact").
+		WithStartupPolicy(StartupPolicy{Background: true}).
+		WithStartupReport(nil)
+	connectTestClient(t, vs, nil)
+
+	r := vs.StartupReport()
+	require.NotNil(t, r)
+	remote := r.Variants[2]
+	assert.Equal(t, BackendRemote, remote.Backend)
+	assert.False(t, remote.Ready)
+	assert.False(t, remote.Available)
+	assert.Contains(t, remote.Error, `remote variant "remote"`)
+	assert.Contains(t, remote.Reason, "backend not ready")
+	assert.Nil(t, remote.ServerInfo)
+	assert.Equal(t, "compact", r.DefaultVariant)
+}
+
+func TestStartupReport_Disabled(t *testing.T) {
+	vs := newTestVariantServer()
+	connectTestClient(t, vs, nil)
+	assert.Nil(t, vs.StartupReport())
+}
+
+func TestStartupReport_LogValue(t *testing.T) {
+	r := &StartupReport{
+		DefaultVariant: "coding",
+		Variants: []StartupVariant{
+			{ID: "coding", Status: Stable, Backend: BackendInMemory, Ready: true, Available: true, Tools: 2},
+			{ID: "remote", Backend: BackendRemote, Error: "connection refused", Reason: "backend not ready: connection refused"},
+		},
+		Conflicts: []ToolConflict{{Tool: "search", Variants: []string{"coding", "remote"}}},
+		Violations: []Violation{
+			{Rule: RuleMissingDescription, VariantID: "remote", Detail: "no description"},
+			{Rule: RuleMissingDescription, VariantID: "coding", Detail: "no description"},
+		},
+	}
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("started", "report", r)
+	out := buf.String()
+	assert.Contains(t, out, "report.defaultVariant=coding")
+	assert.Contains(t, out, "report.variant.coding.ready=true")
+	assert.Contains(t, out, "report.variant.coding.tools=2")
+	assert.Contains(t, out, `report.variant.remote.error="connection refused"`)
+	assert.Contains(t, out, "report.conflict.search=\"[coding remote]\"")
+	assert.Contains(t, out, "report.violation.0.rule="+RuleMissingDescription)
+	assert.Contains(t, out, "report.violation.0.variant=remote")
+	assert.Contains(t, out, `report.violation.0.detail="no description"`)
+	assert.Contains(t, out, "report.violation.1.variant=coding")
+}
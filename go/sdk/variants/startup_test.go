@@ -0,0 +1,170 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errNotReady = errors.New("connection refused")
+
+// slowStartBackend is an in-memory backend that fails to probe and connect
+// until ready is set or failures probes have failed.
+type slowStartBackend struct {
+	*inMemoryBackend
+	ready    atomic.Bool
+	failures atomic.Int32
+	probes   atomic.Int32
+}
+
+func (b *slowStartBackend) up() bool {
+	if b.failures.Add(-1) < 0 {
+		b.ready.Store(true)
+	}
+	return b.ready.Load()
+}
+
+func (b *slowStartBackend) probe(ctx context.Context) (*mcp.InitializeResult, error) {
+	b.probes.Add(1)
+	if !b.up() {
+		return nil, errNotReady
+	}
+	return b.inMemoryBackend.probe(ctx)
+}
+
+func (b *slowStartBackend) connect(ctx context.Context, v ServerVariant, frontSession *mcp.ServerSession) (*innerConnection, error) {
+	if !b.ready.Load() {
+		return nil, errNotReady
+	}
+	return b.inMemoryBackend.connect(ctx, v, frontSession)
+}
+
+// newSlowStartVariantServer is newTestVariantServer with the compact
+// variant served by a slowStartBackend.
+func newSlowStartVariantServer() (*Server, *slowStartBackend) {
+	codingServer, compactServer := newTestServers()
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "coding", Status: Stable}, codingServer, 0)
+	b := &slowStartBackend{inMemoryBackend: newInMemoryBackend(compactServer, "compact", vs)}
+	b.failures.Store(math.MaxInt32)
+	vs.addVariant(ServerVariant{ID: "compact", Status: Experimental}, b, 1)
+	return vs, b
+}
+
+func TestStartupPolicy_Default(t *testing.T) {
+	vs, b := newSlowStartVariantServer()
+	b.failures.Store(1)
+
+	_, err := vs.NewRouter(nil)
+	require.ErrorIs(t, err, errNotReady)
+	assert.Equal(t, int32(1), b.probes.Load())
+}
+
+func TestStartupPolicy_Retry(t *testing.T) {
+	vs, b := newSlowStartVariantServer()
+	b.failures.Store(2)
+	vs.WithStartupPolicy(StartupPolicy{Timeout: 5 * time.Second, Backoff: time.Millisecond})
+	defer vs.Close()
+
+	_, err := vs.NewRouter(nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), b.probes.Load())
+	assert.True(t, vs.isAvailable("compact"))
+}
+
+func TestStartupPolicy_Timeout(t *testing.T) {
+	vs, _ := newSlowStartVariantServer()
+	vs.WithStartupPolicy(StartupPolicy{Timeout: 20 * time.Millisecond, Backoff: time.Millisecond})
+
+	_, err := vs.NewRouter(nil)
+	require.ErrorIs(t, err, errNotReady)
+}
+
+// TestStartupPolicy_TimeoutPerBackend verifies that a backend that is down
+// does not use up the startup timeout of those registered after it.
+func TestStartupPolicy_TimeoutPerBackend(t *testing.T) {
+	codingServer, compactServer := newTestServers()
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"})
+	b := &slowStartBackend{inMemoryBackend: newInMemoryBackend(compactServer, "compact", vs)}
+	b.failures.Store(math.MaxInt32)
+	vs.addVariant(ServerVariant{ID: "compact", Status: Experimental}, b, 0)
+	vs.WithVariant(ServerVariant{ID: "coding", Status: Stable}, codingServer, 1).
+		WithStartupPolicy(StartupPolicy{Timeout: 50 * time.Millisecond, Backoff: time.Millisecond, Background: true})
+	defer vs.Close()
+
+	_, err := vs.NewRouter(nil)
+	require.NoError(t, err)
+	assert.False(t, vs.isAvailable("compact"))
+	assert.True(t, vs.isAvailable("coding"))
+	caps := vs.VariantCapabilities("coding")
+	require.NotNil(t, caps)
+	assert.NotNil(t, caps.Tools)
+}
+
+// TestStartupPolicy_Background verifies that a variant whose backend is not
+// ready is served as unavailable, and returns to rotation and is connected
+// by existing sessions once its backend becomes ready.
+func TestStartupPolicy_Background(t *testing.T) {
+	vs, b := newSlowStartVariantServer()
+	vs.WithStartupPolicy(StartupPolicy{Backoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, Background: true})
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	available, reason := vs.VariantAvailability("compact")
+	assert.False(t, available)
+	assert.True(t, strings.HasPrefix(reason, "backend not ready"), reason)
+
+	_, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: "compact"}})
+	var jErr *jsonrpc.Error
+	require.True(t, errors.As(err, &jErr))
+	assert.Equal(t, CodeVariantUnavailable, jErr.Code)
+
+	tools, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.Contains(t, toolNames(tools.Tools), "analyze_code")
+
+	b.ready.Store(true)
+	require.Eventually(t, func() bool { return vs.isAvailable("compact") }, 5*time.Second, time.Millisecond)
+
+	tools, err = session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: "compact"}})
+	require.NoError(t, err)
+	assert.Contains(t, toolNames(tools.Tools), "summarize")
+}
+
+// TestStartupPolicy_BackgroundOverride verifies that a variant taken out of
+// rotation otherwise while its backend is not ready stays out of rotation.
+func TestStartupPolicy_BackgroundOverride(t *testing.T) {
+	vs, b := newSlowStartVariantServer()
+	vs.WithStartupPolicy(StartupPolicy{Backoff: time.Millisecond, MaxBackoff: time.Millisecond, Background: true})
+	defer vs.Close()
+
+	_, err := vs.NewRouter(nil)
+	require.NoError(t, err)
+	require.NoError(t, vs.SetVariantAvailability("compact", false, "maintenance"))
+
+	probes := b.probes.Load()
+	b.ready.Store(true)
+	require.Eventually(t, func() bool { return b.probes.Load() > probes }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	_, reason := vs.VariantAvailability("compact")
+	assert.Equal(t, "maintenance", reason)
+}
+
+func TestWithStartupPolicy_Negative(t *testing.T) {
+	assert.Panics(t, func() {
+		newTestVariantServer().WithStartupPolicy(StartupPolicy{Timeout: -time.Second})
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newStatelessBenchRouter returns a stateless router over n variants, each
+// with a trivial "echo" tool.
+func newStatelessBenchRouter(b *testing.B, n int) *VariantRouter {
+	b.Helper()
+	vs := NewServer(&mcp.Implementation{Name: "bench", Version: "v0.0.1"})
+	for i := range n {
+		inner := mcp.NewServer(&mcp.Implementation{Name: "inner", Version: "v0.0.1"}, nil)
+		mcp.AddTool(inner, &mcp.Tool{Name: "echo"}, func(context.Context, *mcp.CallToolRequest, emptyInput) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{}, nil, nil
+		})
+		vs.WithVariant(ServerVariant{ID: fmt.Sprintf("v%d", i)}, inner, i)
+	}
+	b.Cleanup(func() { vs.Close() })
+	r, err := vs.NewRouter(&RouterOptions{Stateless: true})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return r
+}
+
+// BenchmarkStatelessDispatch simulates many concurrent stateless requests
+// sharing one dispatcher, with and without an explicit variant selection.
+// Run with -cpu to vary the number of concurrent clients, e.g.
+//
+//	go test -run=^$ -bench=StatelessDispatch -cpu=1,8,64
+func BenchmarkStatelessDispatch(b *testing.B) {
+	for _, n := range []int{2, 50} {
+		for _, explicit := range []bool{false, true} {
+			name := fmt.Sprintf("variants=%d/default", n)
+			if explicit {
+				name = fmt.Sprintf("variants=%d/explicit", n)
+			}
+			b.Run(name, func(b *testing.B) {
+				r := newStatelessBenchRouter(b, n)
+				ctx := context.Background()
+				b.ReportAllocs()
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					i := 0
+					for pb.Next() {
+						params := &mcp.CallToolParamsRaw{Name: "echo", Arguments: json.RawMessage(`{}`)}
+						if explicit {
+							params.Meta = mcp.Meta{metaKeyVariant: fmt.Sprintf("v%d", i%n)}
+						}
+						if _, err := r.Call(ctx, "tools/call", &mcp.CallToolRequest{Params: params}); err != nil {
+							// Fatal must not be called from RunParallel's goroutines.
+							b.Error(err)
+							return
+						}
+						i++
+					}
+				})
+			})
+		}
+	}
+}
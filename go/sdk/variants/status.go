@@ -0,0 +1,82 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"fmt"
+)
+
+// SetVariantStatus changes the status of a registered variant while
+// serving, e.g. to deprecate a variant or promote an experimental one
+// without restarting. The status affects ranking and the behaviors tied to
+// it (see WithDeprecationConfirmation and WithDestructiveConfirmation).
+// Sessions of clients declaring the variants extension are sent their
+// re-ranked variants payload, as for SetVariantAvailability.
+//
+// It is safe to call concurrently with request handling. It returns an
+// error if no variant with the given ID is registered or status is not
+// Stable, Experimental or Deprecated.
+func (s *Server) SetVariantStatus(id string, status VariantStatus) error {
+	if !s.hasVariant(id) {
+		return fmt.Errorf("variants: unknown variant %q", id)
+	}
+	switch status {
+	case Stable, Experimental, Deprecated:
+	default:
+		return fmt.Errorf("variants: invalid status %q", status)
+	}
+
+	// Copy on write, as for availability.
+	s.mu.Lock()
+	v, _ := s.lookupVariant(id)
+	if v.Status == status {
+		s.mu.Unlock()
+		return nil
+	}
+	old := s.statusOverrides()
+	m := make(map[string]VariantStatus, len(old)+1)
+	for k, st := range old {
+		m[k] = st
+	}
+	m[id] = status
+	s.statuses.Store(&m)
+	s.mu.Unlock()
+	s.rankCache.invalidate()
+	s.payloads.invalidate()
+	s.notifyCatalogChanged()
+	return nil
+}
+
+// statusOverrides returns the statuses set with SetVariantStatus, by
+// variant ID. The map must not be modified.
+func (s *Server) statusOverrides() map[string]VariantStatus {
+	if m := s.statuses.Load(); m != nil {
+		return *m
+	}
+	return nil
+}
+
+// withStatus returns v with any status set with SetVariantStatus applied.
+func (s *Server) withStatus(v ServerVariant) ServerVariant {
+	if st, ok := s.statusOverrides()[v.ID]; ok {
+		v.Status = st
+	}
+	return v
+}
+
+// defaultOrder returns the variant IDs in default ranking order: the order
+// computed at registration, unless statuses were changed since.
+func (s *Server) defaultOrder() []string {
+	if len(s.statusOverrides()) == 0 {
+		return s.priorityOrder
+	}
+	ranked := defaultRankingFunc(context.Background(), VariantHints{}, s.Variants())
+	ids := make([]string, len(ranked))
+	for i, v := range ranked {
+		ids[i] = v.ID
+	}
+	return ids
+}
@@ -0,0 +1,36 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetVariantStatus(t *testing.T) {
+	coding, compact := newTestServers()
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "coding", Status: Stable}, coding, 0).
+		WithVariant(ServerVariant{ID: "compact", Status: Experimental}, compact, 0)
+	ctx := context.Background()
+	assert.Equal(t, "coding", vs.RankedVariants(ctx, VariantHints{})[0].ID)
+
+	require.NoError(t, vs.SetVariantStatus("coding", Deprecated))
+	v, ok := vs.lookupVariant("coding")
+	require.True(t, ok)
+	assert.Equal(t, Deprecated, v.Status)
+	assert.Equal(t, Deprecated, vs.Variants()[0].Status)
+	assert.Equal(t, "compact", vs.RankedVariants(ctx, VariantHints{})[0].ID)
+	id, err := vs.defaultVariant(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "compact", id)
+
+	assert.Error(t, vs.SetVariantStatus("nope", Stable))
+	assert.Error(t, vs.SetVariantStatus("coding", "retired"))
+}
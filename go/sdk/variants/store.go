@@ -0,0 +1,260 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SharedStore is a key-value store shared by the replicas of a horizontally
+// scaled deployment, such as several stateless HTTP handlers behind a load
+// balancer, so that they behave consistently (see WithSharedStore). It
+// must be safe for concurrent use. Package variants/redisstore implements
+// it on Redis; NewMemoryStore returns an in-process store for tests and
+// single-replica deployments.
+type SharedStore interface {
+	// Get returns the value stored under key, and reports whether there
+	// is one.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+
+	// Set stores value under key, expiring after ttl if it is positive.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes the value stored under key, if any.
+	Delete(ctx context.Context, key string) error
+}
+
+// SharedStoreOptions configures the use of a SharedStore (see
+// WithSharedStore).
+type SharedStoreOptions struct {
+	// Prefix is prepended to the keys the server stores, so that several
+	// servers can share a store. Empty means "variants/".
+	Prefix string
+
+	// PinTTL is how long session pins are kept. Zero means 24 hours.
+	PinTTL time.Duration
+
+	// RankingTTL is how long shared ranking results are kept. Zero means
+	// 10 minutes.
+	RankingTTL time.Duration
+}
+
+// Defaults for SharedStoreOptions.
+const (
+	defaultStorePrefix     = "variants/"
+	defaultStorePinTTL     = 24 * time.Hour
+	defaultStoreRankingTTL = 10 * time.Minute
+)
+
+// WithSharedStore keeps state that must be consistent across the replicas
+// of a deployment in st, for horizontally scaled deployments such as
+// several stateless HTTP handlers behind a load balancer:
+//
+//   - Pins of stateless sessions (see PinSession) are stored by session
+//     ID, so a pin set through any replica serving stateless sessions
+//     applies to the session's requests on every replica. Stateless
+//     sessions are identified by the Mcp-Session-Id header their clients
+//     send. Each stateless request that selects no variant looks its
+//     session's pin up in the store. Stateful sessions are served by one
+//     replica, which keeps their pins locally, so they must be pinned
+//     through that replica.
+//   - Ranking results cached with WithRankingCache are shared, keyed by the
+//     hints and the variants in rotation, so an expensive RankingFunc runs
+//     once per distinct hints across replicas. Replicas must run the same
+//     RankingFunc.
+//
+// Consistent defaults (see WithConsistentDefault) need no store: rendezvous
+// hashing assigns a client the same variant on every replica, which is how
+// the server keeps canary and A/B buckets consistent. The server enforces
+// no quotas, so none are shared; quotas implemented in a FlagProvider or a
+// DispatchInterceptor can keep their counters in st directly. Failures of
+// the store are not fatal to requests: a pin or ranking that cannot be read
+// is treated as absent. opts may be nil.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithSharedStore(st SharedStore, opts *SharedStoreOptions) *Server {
+	if st == nil {
+		s.store = nil
+		return s
+	}
+	c := &sharedStore{SharedStore: st}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.Prefix == "" {
+		c.opts.Prefix = defaultStorePrefix
+	}
+	if c.opts.PinTTL == 0 {
+		c.opts.PinTTL = defaultStorePinTTL
+	}
+	if c.opts.RankingTTL == 0 {
+		c.opts.RankingTTL = defaultStoreRankingTTL
+	}
+	s.store = c
+	return s
+}
+
+// sharedStore is a SharedStore with the server's options.
+type sharedStore struct {
+	SharedStore
+	opts SharedStoreOptions
+}
+
+// pinKey returns the key of a session's pin.
+func (c *sharedStore) pinKey(sessionID string) string {
+	return c.opts.Prefix + "pin/" + sessionID
+}
+
+// setPin stores or, if variantID is empty, removes a session's pin.
+func (c *sharedStore) setPin(ctx context.Context, sessionID, variantID string) error {
+	if variantID == "" {
+		return c.Delete(ctx, c.pinKey(sessionID))
+	}
+	return c.Set(ctx, c.pinKey(sessionID), []byte(variantID), c.opts.PinTTL)
+}
+
+// sharedPin returns the variant pinned in the shared store for the
+// stateless session of req, if any and usable by the session. Stateful
+// sessions keep their pins locally (see PinSession), so the store is only
+// read for requests to the shared stateless dispatcher.
+func (d *dispatcher) sharedPin(ctx context.Context, req mcp.Request) string {
+	c := d.server.store
+	if c == nil || !d.shared {
+		return ""
+	}
+	sid := sessionID(req)
+	if sid == "" {
+		return ""
+	}
+	value, ok, err := c.Get(ctx, c.pinKey(sid))
+	if err != nil || !ok {
+		return ""
+	}
+	id := string(value)
+	if !d.server.hasVariant(id) || !d.server.isAvailable(id) || !d.server.flagEnabled(ctx, id, d.requestFlagContext(req)) {
+		return ""
+	}
+	return id
+}
+
+// storedRanking is a ranked variant as shared in the store.
+type storedRanking struct {
+	ID          string  `json:"id"`
+	Score       float64 `json:"score,omitempty"`
+	MatchReason string  `json:"matchReason,omitempty"`
+}
+
+// rankingKey returns the key of the ranking for hints with the given
+// fingerprint, among the given variants in rotation.
+func (c *sharedStore) rankingKey(fp [sha256.Size]byte, all []ServerVariant) string {
+	h := sha256.New()
+	for _, v := range all {
+		h.Write([]byte(v.ID))
+		h.Write([]byte{0})
+		h.Write([]byte(v.Status))
+		h.Write([]byte{0})
+	}
+	return c.opts.Prefix + "ranking/" + hex.EncodeToString(fp[:]) + "/" + hex.EncodeToString(h.Sum(nil))
+}
+
+// getRanking returns the shared ranking stored under key, resolved against
+// the registered variants. It reports false if there is none or it names a
+// variant that is not in all.
+func (c *sharedStore) getRanking(ctx context.Context, key string, all []ServerVariant) ([]ServerVariant, bool) {
+	value, ok, err := c.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false
+	}
+	var stored []storedRanking
+	if json.Unmarshal(value, &stored) != nil {
+		return nil, false
+	}
+	byID := make(map[string]ServerVariant, len(all))
+	for _, v := range all {
+		byID[v.ID] = v
+	}
+	ranked := make([]ServerVariant, 0, len(stored))
+	for _, r := range stored {
+		v, ok := byID[r.ID]
+		if !ok {
+			return nil, false
+		}
+		v.Score, v.MatchReason = r.Score, r.MatchReason
+		ranked = append(ranked, v)
+	}
+	return ranked, true
+}
+
+// putRanking shares a ranking under key. Failures are ignored.
+func (c *sharedStore) putRanking(ctx context.Context, key string, ranked []ServerVariant) {
+	stored := make([]storedRanking, len(ranked))
+	for i, v := range ranked {
+		stored[i] = storedRanking{ID: v.ID, Score: v.Score, MatchReason: v.MatchReason}
+	}
+	value, err := json.Marshal(stored)
+	if err != nil {
+		return
+	}
+	_ = c.Set(ctx, key, value, c.opts.RankingTTL)
+}
+
+// NewMemoryStore returns a SharedStore keeping its values in memory, for
+// tests and single-replica deployments. Expired values are removed when
+// they are next read.
+func NewMemoryStore() SharedStore {
+	return &memoryStore{values: make(map[string]memoryValue)}
+}
+
+// memoryStore is the SharedStore returned by NewMemoryStore.
+type memoryStore struct {
+	mu     sync.Mutex
+	values map[string]memoryValue
+}
+
+// memoryValue is a value of a memoryStore.
+type memoryValue struct {
+	data    []byte
+	expires time.Time // zero for none
+}
+
+func (m *memoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.values[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !v.expires.IsZero() && !time.Now().Before(v.expires) {
+		delete(m.values, key)
+		return nil, false, nil
+	}
+	return bytes.Clone(v.data), true, nil
+}
+
+func (m *memoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	v := memoryValue{data: bytes.Clone(value)}
+	if ttl > 0 {
+		v.expires = time.Now().Add(ttl)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = v
+	return nil
+}
+
+func (m *memoryStore) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.values, key)
+	return nil
+}
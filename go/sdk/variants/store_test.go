@@ -0,0 +1,157 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	st := NewMemoryStore()
+
+	_, ok, err := st.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	value := []byte("v")
+	require.NoError(t, st.Set(ctx, "k", value, 0))
+	value[0] = 'x'
+	got, ok, err := st.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("v"), got, "the store keeps its own copy")
+
+	require.NoError(t, st.Set(ctx, "short", []byte("v"), time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	_, ok, _ = st.Get(ctx, "short")
+	assert.False(t, ok, "expired values are gone")
+
+	require.NoError(t, st.Delete(ctx, "k"))
+	_, ok, _ = st.Get(ctx, "k")
+	assert.False(t, ok)
+}
+
+// roundRobin serves requests by each handler in turn, as a load balancer
+// in front of several replicas would.
+func roundRobin(handlers ...http.Handler) http.Handler {
+	var next atomic.Int64
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handlers[int(next.Add(1)-1)%len(handlers)].ServeHTTP(w, r)
+	})
+}
+
+func TestSharedStore_PinAcrossReplicas(t *testing.T) {
+	st := NewMemoryStore()
+	a := newTestVariantServer().WithSharedStore(st, nil)
+	b := newTestVariantServer().WithSharedStore(st, nil)
+	opts := &mcp.StreamableHTTPOptions{Stateless: true}
+	httpSrv := httptest.NewServer(roundRobin(NewStreamableHTTPHandler(a, opts), NewStreamableHTTPHandler(b, opts)))
+	t.Cleanup(httpSrv.Close)
+	t.Cleanup(func() { a.Close(); b.Close() })
+
+	session := connectHTTPTestClient(t, httpSrv)
+	require.NotEmpty(t, session.ID())
+	ctx := context.Background()
+	list := func() []string {
+		t.Helper()
+		res, err := session.ListTools(ctx, nil)
+		require.NoError(t, err)
+		return toolNames(res.Tools)
+	}
+	assert.Contains(t, list(), "analyze_code")
+
+	require.NoError(t, b.PinSession(session.ID(), "compact"))
+	for range 4 {
+		assert.Contains(t, list(), "summarize", "the pin applies on every replica")
+	}
+
+	require.NoError(t, a.PinSession(session.ID(), ""))
+	for range 2 {
+		assert.Contains(t, list(), "analyze_code")
+	}
+
+	assert.Error(t, a.PinSession(session.ID(), "nope"))
+}
+
+// countingStore is a SharedStore counting its reads.
+type countingStore struct {
+	SharedStore
+	gets atomic.Int32
+}
+
+func (c *countingStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.gets.Add(1)
+	return c.SharedStore.Get(ctx, key)
+}
+
+func TestSharedStore_PinStatefulSession(t *testing.T) {
+	st := &countingStore{SharedStore: NewMemoryStore()}
+	a := newTestVariantServer().WithSharedStore(st, nil)
+	httpSrv := serveHTTP(t, a)
+
+	session := connectHTTPTestClient(t, httpSrv)
+	ctx := context.Background()
+
+	// The session is pinned locally by the replica serving it, and its
+	// requests do not read the store.
+	require.NoError(t, a.PinSession(session.ID(), "compact"))
+	res, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.Contains(t, toolNames(res.Tools), "summarize")
+
+	require.NoError(t, a.PinSession(session.ID(), ""))
+	res, err = session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	assert.Contains(t, toolNames(res.Tools), "analyze_code")
+	assert.Zero(t, st.gets.Load())
+
+	// Without stateless sessions, pins are neither stored nor accepted for
+	// unknown IDs.
+	require.NoError(t, a.PinSession(session.ID(), "compact"))
+	_, ok, err := st.SharedStore.Get(ctx, defaultStorePrefix+"pin/"+session.ID())
+	require.NoError(t, err)
+	assert.False(t, ok, "a stateful session's pin is kept locally")
+	assert.ErrorIs(t, a.PinSession("typo", "compact"), ErrUnknownSession)
+}
+
+func TestSharedStore_Ranking(t *testing.T) {
+	st := NewMemoryStore()
+	var calls atomic.Int64
+	newReplica := func() *Server {
+		return newTestVariantServer().
+			WithRanking(func(ctx context.Context, hints VariantHints, vs []ServerVariant) []ServerVariant {
+				calls.Add(1)
+				return defaultRankingFunc(ctx, hints, vs)
+			}).
+			WithRankingCache(8).
+			WithSharedStore(st, &SharedStoreOptions{Prefix: "test/"})
+	}
+	a, b := newReplica(), newReplica()
+	ctx := context.Background()
+	hints := VariantHints{Hints: map[string]any{HintContextSize: "compact"}}
+
+	want := a.RankedVariants(ctx, hints)
+	assert.Equal(t, want, b.RankedVariants(ctx, hints))
+	assert.EqualValues(t, 1, calls.Load(), "the second replica should use the shared ranking")
+
+	b.RankedVariants(ctx, VariantHints{Hints: map[string]any{HintContextSize: "verbose"}})
+	assert.EqualValues(t, 2, calls.Load(), "different hints should miss the shared cache")
+
+	// A replica with other variants in rotation ranks for itself.
+	c := newReplica()
+	c.WithVariant(ServerVariant{ID: "extra"}, mcp.NewServer(&mcp.Implementation{Name: "extra", Version: "v0.0.1"}, nil), 2)
+	assert.Len(t, c.RankedVariants(ctx, hints), 3)
+	assert.EqualValues(t, 3, calls.Load())
+}
@@ -0,0 +1,299 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Rules reported in Violation.Rule.
+const (
+	// RuleUnknownMetaKey: a request carried a _meta key in the extension's
+	// namespace that the extension does not define.
+	RuleUnknownMetaKey = "unknownMetaKey"
+
+	// RuleDuplicateVariantID: a variant ID was registered more than once,
+	// or the ranking listed a variant more than once, although IDs MUST be
+	// unique within availableVariants.
+	RuleDuplicateVariantID = "duplicateVariantID"
+
+	// RuleUnknownVariantID: the ranking listed a variant that is not
+	// registered or not in rotation.
+	RuleUnknownVariantID = "unknownVariantID"
+
+	// RuleDroppedVariant: the ranking left out a variant in rotation
+	// without excluding it with ExcludeFromRanking.
+	RuleDroppedVariant = "droppedVariant"
+
+	// RuleMissingDescription: a variant has no description, although every
+	// advertised variant MUST have one.
+	RuleMissingDescription = "missingDescription"
+)
+
+// maxViolations bounds the distinct violations recorded while serving.
+const maxViolations = 100
+
+// extensionMetaPrefix is the prefix of the _meta keys the extension
+// defines.
+const extensionMetaPrefix = "io.modelcontextprotocol/server-variant"
+
+// knownMetaKeys are the request _meta keys the extension defines.
+var knownMetaKeys = map[string]bool{
+	extensionID:         true,
+	metaKeyVariant:      true,
+	metaKeyVariantHints: true,
+	metaKeyVariantTags:  true,
+	metaKeyFanOut:       true,
+	metaKeyConfirm:      true,
+	metaKeyCompose:      true,
+}
+
+// Violation describes a departure from a requirement of the variants
+// extension (SEP-2053), as reported by [Server.Validate].
+type Violation struct {
+	// Rule identifies the requirement, e.g. RuleDuplicateVariantID.
+	Rule string `json:"rule"`
+
+	// VariantID is the variant concerned, if any.
+	VariantID string `json:"variantId,omitempty"`
+
+	// Detail describes the violation.
+	Detail string `json:"detail"`
+
+	// Count is how often the violation was observed while serving; zero
+	// for violations of the configuration.
+	Count int `json:"count,omitempty"`
+}
+
+// violationLog records violations observed while serving.
+type violationLog struct {
+	mu     sync.Mutex
+	counts map[Violation]int // by violation with zero Count
+}
+
+// record counts an observed violation. New violations beyond maxViolations
+// are dropped.
+func (l *violationLog) record(v Violation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.counts[v]; !ok && len(l.counts) >= maxViolations {
+		return
+	}
+	if l.counts == nil {
+		l.counts = make(map[Violation]int)
+	}
+	l.counts[v]++
+}
+
+// snapshot returns the recorded violations with their counts.
+func (l *violationLog) snapshot() []Violation {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]Violation, 0, len(l.counts))
+	for v, n := range l.counts {
+		v.Count = n
+		out = append(out, v)
+	}
+	return out
+}
+
+// WithStrict enforces the MUSTs of the variants extension (SEP-2053) where
+// the server is lenient by default for interoperability with older
+// clients:
+//
+//   - Requests carrying _meta keys in the extension's namespace
+//     ("io.modelcontextprotocol/server-variant...") that the extension
+//     does not define fail with an invalid params error, instead of being
+//     ignored.
+//   - Variants a RankingFunc lists twice, or that are not registered or
+//     out of rotation, are dropped from the ranking instead of being
+//     advertised.
+//   - Advertised variants always include hints and status, with the
+//     default status "stable", instead of omitting them when empty.
+//   - Serving fails if a variant has no description.
+//
+// In either mode, serving fails if a variant ID is registered more than
+// once, and a RankingFunc leaving out variants without ExcludeFromRanking
+// is only recorded (RuleDroppedVariant), since rankings may filter on
+// purpose. Violations are recorded in either mode and reported by
+// Validate.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithStrict(enabled bool) *Server {
+	s.strict = enabled
+	return s
+}
+
+// Validate checks the server against the requirements of the variants
+// extension and returns the violations found, sorted by rule and variant:
+// those of its configuration, including the ranking for empty hints, and
+// those observed while serving, with their counts.
+func (s *Server) Validate(ctx context.Context) []Violation {
+	var out []Violation
+	for _, id := range s.duplicateIDs {
+		out = append(out, Violation{
+			Rule:      RuleDuplicateVariantID,
+			VariantID: id,
+			Detail:    fmt.Sprintf("variant %q is registered more than once", id),
+		})
+	}
+	for _, e := range s.variants {
+		if strings.TrimSpace(e.variant.Description) == "" {
+			out = append(out, Violation{
+				Rule:      RuleMissingDescription,
+				VariantID: e.variant.ID,
+				Detail:    fmt.Sprintf("variant %q has no description", e.variant.ID),
+			})
+		}
+	}
+	rankFn := s.rankingFunc
+	if rankFn == nil {
+		rankFn = defaultRankingFunc
+	}
+	all := s.filterAvailable(s.Variants())
+	ranked, excluded := rankWith(ctx, rankFn, VariantHints{}, slices.Clone(all))
+	out = append(out, rankingViolations(all, ranked, excluded)...)
+	for _, v := range s.violations.snapshot() {
+		if !slices.ContainsFunc(out, func(c Violation) bool { return c.Rule == v.Rule && c.VariantID == v.VariantID && c.Detail == v.Detail }) {
+			out = append(out, v)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b Violation) int {
+		return cmp.Or(cmp.Compare(a.Rule, b.Rule), cmp.Compare(a.VariantID, b.VariantID))
+	})
+	return out
+}
+
+// validateVariants checks that variant IDs were registered once and, in
+// strict mode, that every variant has a description.
+func (s *Server) validateVariants() error {
+	if len(s.duplicateIDs) > 0 {
+		return fmt.Errorf("variants: duplicate variant ID %q", s.duplicateIDs[0])
+	}
+	if !s.strict {
+		return nil
+	}
+	for _, e := range s.variants {
+		if strings.TrimSpace(e.variant.Description) == "" {
+			return fmt.Errorf("variants: variant %q has no description, which strict mode requires", e.variant.ID)
+		}
+	}
+	return nil
+}
+
+// rankingViolations returns the violations of a ranking of available:
+// duplicate variants, variants that are not available, and available
+// variants left out that are not in excluded.
+func rankingViolations(available, ranked []ServerVariant, excluded map[string]bool) []Violation {
+	var out []Violation
+	known := make(map[string]bool, len(available))
+	for _, v := range available {
+		known[v.ID] = true
+	}
+	seen := make(map[string]bool, len(ranked))
+	for _, v := range ranked {
+		switch {
+		case seen[v.ID]:
+			out = append(out, Violation{
+				Rule:      RuleDuplicateVariantID,
+				VariantID: v.ID,
+				Detail:    fmt.Sprintf("ranking lists variant %q more than once", v.ID),
+			})
+		case !known[v.ID]:
+			out = append(out, Violation{
+				Rule:      RuleUnknownVariantID,
+				VariantID: v.ID,
+				Detail:    fmt.Sprintf("ranking lists variant %q, which is not registered or not in rotation", v.ID),
+			})
+		}
+		seen[v.ID] = true
+	}
+	for _, v := range available {
+		if !seen[v.ID] && !excluded[v.ID] {
+			out = append(out, Violation{
+				Rule:      RuleDroppedVariant,
+				VariantID: v.ID,
+				Detail:    fmt.Sprintf("ranking leaves out variant %q; call ExcludeFromRanking to leave it out on purpose", v.ID),
+			})
+		}
+	}
+	return out
+}
+
+// checkRanking records the violations of a ranking of available. With
+// WithRankingAssertions, it panics on violations. In strict mode, it
+// returns the ranking without duplicate and unknown variants.
+func (s *Server) checkRanking(available, ranked []ServerVariant, excluded map[string]bool) []ServerVariant {
+	violations := rankingViolations(available, ranked, excluded)
+	if len(violations) == 0 {
+		return ranked
+	}
+	for _, v := range violations {
+		s.violations.record(v)
+	}
+	if s.rankingAssertions {
+		panic(&RankingError{Violations: violations})
+	}
+	if !s.strict {
+		return ranked
+	}
+	known := make(map[string]bool, len(available))
+	for _, v := range available {
+		known[v.ID] = true
+	}
+	out := make([]ServerVariant, 0, len(ranked))
+	seen := make(map[string]bool, len(ranked))
+	for _, v := range ranked {
+		if !seen[v.ID] && known[v.ID] {
+			out = append(out, v)
+		}
+		seen[v.ID] = true
+	}
+	return out
+}
+
+// checkExtensionMeta records _meta keys of req in the extension's
+// namespace that the extension does not define, failing the request in
+// strict mode.
+func (s *Server) checkExtensionMeta(req mcp.Request) error {
+	params := req.GetParams()
+	if isNilInterface(params) {
+		return nil
+	}
+	var unknown []string
+	for k := range params.GetMeta() {
+		if strings.HasPrefix(k, extensionMetaPrefix) && !knownMetaKeys[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	slices.Sort(unknown)
+	for _, k := range unknown {
+		s.violations.record(Violation{
+			Rule:   RuleUnknownMetaKey,
+			Detail: fmt.Sprintf("unknown _meta key %q", k),
+		})
+	}
+	if !s.strict {
+		return nil
+	}
+	dataJSON, _ := json.Marshal(map[string]any{"keys": unknown})
+	return &jsonrpc.Error{
+		Code:    jsonrpc.CodeInvalidParams,
+		Message: "Unknown variants extension _meta key",
+		Data:    json.RawMessage(dataJSON),
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// duplicateRanking lists the first variant twice and an unregistered one.
+func duplicateRanking(_ context.Context, _ VariantHints, vs []ServerVariant) []ServerVariant {
+	return append(vs, vs[0], ServerVariant{ID: "ghost", Description: "Not registered"})
+}
+
+func TestStrict_UnknownMetaKey(t *testing.T) {
+	params := &mcp.ListToolsParams{Meta: mcp.Meta{extensionMetaPrefix + "-bogus": true}}
+
+	t.Run("lenient", func(t *testing.T) {
+		vs := newTestVariantServer()
+		session := connectTestClient(t, vs, nil)
+		_, err := session.ListTools(context.Background(), params)
+		require.NoError(t, err)
+
+		violations := vs.Validate(context.Background())
+		require.Len(t, violations, 1)
+		assert.Equal(t, RuleUnknownMetaKey, violations[0].Rule)
+		assert.Equal(t, 1, violations[0].Count)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		vs := newTestVariantServer().WithStrict(true)
+		session := connectTestClient(t, vs, nil)
+		_, err := session.ListTools(context.Background(), params)
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, int64(jsonrpc.CodeInvalidParams), rpcErr.Code)
+		assert.Contains(t, string(rpcErr.Data), "server-variant-bogus")
+
+		_, err = session.ListTools(context.Background(), &mcp.ListToolsParams{Meta: mcp.Meta{metaKeyVariant: "compact"}})
+		assert.NoError(t, err, "known keys are accepted")
+	})
+}
+
+func TestStrict_Ranking(t *testing.T) {
+	t.Run("lenient", func(t *testing.T) {
+		vs := newTestVariantServer().WithRanking(duplicateRanking)
+		ids := variantIDs(vs.RankedVariants(context.Background(), VariantHints{}))
+		assert.Equal(t, []string{"coding", "compact", "coding", "ghost"}, ids)
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		vs := newTestVariantServer().WithRanking(duplicateRanking).WithStrict(true)
+		ids := variantIDs(vs.RankedVariants(context.Background(), VariantHints{}))
+		assert.Equal(t, []string{"coding", "compact"}, ids)
+
+		var rules []string
+		for _, v := range vs.Validate(context.Background()) {
+			rules = append(rules, v.Rule+":"+v.VariantID)
+		}
+		assert.Equal(t, []string{RuleDuplicateVariantID + ":coding", RuleUnknownVariantID + ":ghost"}, rules)
+	})
+}
+
+func TestStrict_RequiredFields(t *testing.T) {
+	newServer := func() *Server {
+		return NewServer(&mcp.Implementation{Name: "docs", Version: "1.0.0"}).
+			WithVariant(ServerVariant{ID: "full", Description: "Full docs"}, newDocsServer("full"), 0)
+	}
+
+	var lenient struct {
+		AvailableVariants []map[string]any `json:"availableVariants"`
+	}
+	initExtension(t, connectTestClient(t, newServer(), nil), &lenient)
+	require.Len(t, lenient.AvailableVariants, 1)
+	assert.NotContains(t, lenient.AvailableVariants[0], "hints")
+	assert.NotContains(t, lenient.AvailableVariants[0], "status")
+
+	var strict struct {
+		AvailableVariants []map[string]any `json:"availableVariants"`
+	}
+	initExtension(t, connectTestClient(t, newServer().WithStrict(true), nil), &strict)
+	require.Len(t, strict.AvailableVariants, 1)
+	assert.Equal(t, map[string]any{}, strict.AvailableVariants[0]["hints"])
+	assert.Equal(t, "stable", strict.AvailableVariants[0]["status"])
+}
+
+func TestValidate_MissingDescription(t *testing.T) {
+	vs := NewServer(&mcp.Implementation{Name: "docs", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "full"}, newDocsServer("full"), 0)
+
+	violations := vs.Validate(context.Background())
+	require.Len(t, violations, 1)
+	assert.Equal(t, Violation{Rule: RuleMissingDescription, VariantID: "full", Detail: `variant "full" has no description`}, violations[0])
+	assert.Empty(t, newTestVariantServer().Validate(context.Background()))
+}
+
+func TestValidate_DuplicateVariantID(t *testing.T) {
+	vs := newTestVariantServer().
+		WithVariant(ServerVariant{ID: "coding", Description: "Another coding variant"}, newDocsServer("docs"), 2)
+
+	violations := vs.Validate(context.Background())
+	require.Len(t, violations, 1)
+	assert.Equal(t, Violation{Rule: RuleDuplicateVariantID, VariantID: "coding", Detail: `variant "coding" is registered more than once`}, violations[0])
+	assert.Len(t, vs.Variants(), 2)
+
+	_, err := vs.NewRouter(nil)
+	assert.ErrorContains(t, err, `duplicate variant ID "coding"`)
+}
+
+func TestStrict_MissingDescription(t *testing.T) {
+	newServer := func() *Server {
+		return NewServer(&mcp.Implementation{Name: "docs", Version: "1.0.0"}).
+			WithVariant(ServerVariant{ID: "full"}, newDocsServer("full"), 0)
+	}
+	_, err := newServer().NewRouter(nil)
+	require.NoError(t, err)
+
+	_, err = newServer().WithStrict(true).NewRouter(nil)
+	assert.ErrorContains(t, err, `variant "full" has no description`)
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SelectVariantTags sets meta to select any variant carrying all of the
+// given tags instead of a specific variant ID, and returns it (allocating
+// it if nil). The server resolves the selection to the best-ranked matching
+// variant for the session. An explicit variant ID in the same _meta takes
+// precedence.
+//
+//	params := &mcp.CallToolParams{Name: "search"}
+//	params.Meta = variants.SelectVariantTags(params.Meta, "read-only")
+func SelectVariantTags(meta mcp.Meta, tags ...string) mcp.Meta {
+	if meta == nil {
+		meta = mcp.Meta{}
+	}
+	meta[metaKeyVariantTags] = tags
+	return meta
+}
+
+// variantTagsFromMeta extracts a tag-based variant selection from the
+// request's _meta field. Returns nil if none is present.
+func variantTagsFromMeta(req mcp.Request) []string {
+	params := req.GetParams()
+	if isNilInterface(params) {
+		return nil
+	}
+	var tags []string
+	switch v := params.GetMeta()[metaKeyVariantTags].(type) {
+	case []string:
+		tags = v
+	case []any:
+		for _, t := range v {
+			if s, ok := t.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+	case string:
+		tags = []string{v}
+	}
+	return tags
+}
+
+// hasTags reports whether v carries every tag in tags.
+func (v ServerVariant) hasTags(tags []string) bool {
+	for _, t := range tags {
+		if !slices.Contains(v.Tags, t) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveTags returns the ID of the best-ranked usable variant carrying all
+// of tags: the session's ranking is consulted in stateful mode, and the
+// ranking for empty hints in stateless mode.
+func (d *dispatcher) resolveTags(ctx context.Context, req mcp.Request, tags []string) (string, error) {
+	d.mu.RLock()
+	ranked := d.ranked
+	d.mu.RUnlock()
+	if ranked == nil {
+		ranked = d.server.RankedVariants(ctx, VariantHints{})
+	}
+	fc := d.requestFlagContext(req)
+	for _, v := range ranked {
+		if v.hasTags(tags) && d.server.isAvailable(v.ID) && d.server.flagEnabled(ctx, v.ID, fc) {
+			return v.ID, nil
+		}
+	}
+	return "", d.noTaggedVariantError(ctx, tags)
+}
+
+// noTaggedVariantError reports that no variant matches a tag-based
+// selection.
+func (d *dispatcher) noTaggedVariantError(ctx context.Context, tags []string) error {
+	ranked := d.server.RankedVariants(ctx, VariantHints{})
+	availableIDs := make([]string, len(ranked))
+	for i, v := range ranked {
+		availableIDs[i] = v.ID
+	}
+	dataJSON, _ := json.Marshal(map[string]any{
+		"requestedTags":     tags,
+		"availableVariants": availableIDs,
+	})
+	return &jsonrpc.Error{
+		Code:    jsonrpc.CodeInvalidParams,
+		Message: "No server variant matches the requested tags",
+		Data:    json.RawMessage(dataJSON),
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVariantTags(t *testing.T) {
+	codingServer, compactServer := newTestServers()
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "coding", Tags: []string{"write"}}, codingServer, 0).
+		WithVariant(ServerVariant{ID: "compact", Tags: []string{"read-only", "cheap"}}, compactServer, 1)
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	listWithTags := func(tags ...string) (*mcp.ListToolsResult, error) {
+		return session.ListTools(ctx, &mcp.ListToolsParams{Meta: SelectVariantTags(nil, tags...)})
+	}
+
+	t.Run("payload", func(t *testing.T) {
+		var p struct {
+			AvailableVariants []struct {
+				ID   string   `json:"id"`
+				Tags []string `json:"tags"`
+			} `json:"availableVariants"`
+		}
+		initExtension(t, session, &p)
+		require.Len(t, p.AvailableVariants, 2)
+		assert.Equal(t, []string{"read-only", "cheap"}, p.AvailableVariants[1].Tags)
+	})
+
+	t.Run("matching tag", func(t *testing.T) {
+		res, err := listWithTags("read-only")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"summarize", "lookup"}, toolNames(res.Tools))
+	})
+
+	t.Run("all tags must match", func(t *testing.T) {
+		res, err := listWithTags("read-only", "cheap")
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"summarize", "lookup"}, toolNames(res.Tools))
+
+		_, err = listWithTags("read-only", "write")
+		var rpcErr *jsonrpc.Error
+		require.ErrorAs(t, err, &rpcErr)
+		assert.Equal(t, int64(jsonrpc.CodeInvalidParams), rpcErr.Code)
+		assert.JSONEq(t, `{"requestedTags":["read-only","write"],"availableVariants":["coding","compact"]}`, string(rpcErr.Data))
+	})
+
+	t.Run("explicit variant wins", func(t *testing.T) {
+		meta := SelectVariantTags(mcp.Meta{metaKeyVariant: "coding"}, "read-only")
+		res, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: meta})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"analyze_code", "refactor"}, toolNames(res.Tools))
+	})
+
+	t.Run("unavailable variants are skipped", func(t *testing.T) {
+		require.NoError(t, vs.SetVariantAvailability("compact", false, "maintenance"))
+		t.Cleanup(func() { _ = vs.SetVariantAvailability("compact", true, "") })
+		_, err := listWithTags("read-only")
+		require.Error(t, err)
+	})
+}
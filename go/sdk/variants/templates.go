@@ -0,0 +1,104 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// maxDescTemplates bounds the parsed description templates cached by a
+// Server. Tool descriptions change with the variants' catalogs, so the cache
+// is cleared when full rather than growing with every description seen.
+const maxDescTemplates = 1024
+
+// TemplateDataFunc provides the values that description templates are
+// executed with for the given variant, e.g. current rate limits, region, or
+// data freshness timestamps.
+type TemplateDataFunc func(ctx context.Context, variantID string) map[string]any
+
+// WithDescriptionTemplates enables templated descriptions. Variant
+// descriptions and the descriptions of tools listed by a variant may then
+// contain [text/template] actions, such as
+//
+//	"Market data for {{.region}}, refreshed {{.freshness}}"
+//
+// which are executed with the values returned by fn whenever the
+// description is sent to a client: variant descriptions when building the
+// initialize response, tool descriptions on every tools/list. This keeps
+// descriptions accurate without rebuilding servers.
+//
+// Variant description templates are parsed when the server starts serving
+// and a malformed one is reported as an error. A tool description that
+// fails to parse or execute, such as one using a key missing from the
+// values returned by fn, is sent unchanged.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithDescriptionTemplates(fn TemplateDataFunc) *Server {
+	s.templateData = fn
+	return s
+}
+
+// validateDescriptionTemplates parses every variant description template.
+func (s *Server) validateDescriptionTemplates() error {
+	if s.templateData == nil {
+		return nil
+	}
+	for _, e := range s.variants {
+		if _, err := parseDescription(e.variant.Description); err != nil {
+			return fmt.Errorf("variants: description template of variant %q: %w", e.variant.ID, err)
+		}
+	}
+	return nil
+}
+
+// parseDescription parses desc as a template. It returns nil, nil for
+// descriptions without template actions. Executing the template fails on
+// keys missing from the template data.
+func parseDescription(desc string) (*template.Template, error) {
+	if !strings.Contains(desc, "{{") {
+		return nil, nil
+	}
+	return template.New("description").Option("missingkey=error").Parse(desc)
+}
+
+// descriptionTemplate returns desc parsed as a template, or nil if it has
+// no template actions or fails to parse. Templates are parsed once per
+// description, since tool descriptions are rendered on every tools/list.
+func (s *Server) descriptionTemplate(desc string) *template.Template {
+	s.descMu.Lock()
+	defer s.descMu.Unlock()
+	if tmpl, ok := s.descTemplates[desc]; ok {
+		return tmpl
+	}
+	tmpl, err := parseDescription(desc)
+	if err != nil {
+		tmpl = nil
+	}
+	if s.descTemplates == nil || len(s.descTemplates) >= maxDescTemplates {
+		s.descTemplates = make(map[string]*template.Template)
+	}
+	s.descTemplates[desc] = tmpl
+	return tmpl
+}
+
+// renderDescription executes desc as a template with the variant's template
+// data, returning desc unchanged if templating is disabled or fails.
+func (s *Server) renderDescription(ctx context.Context, variantID, desc string) string {
+	if s.templateData == nil || !strings.Contains(desc, "{{") {
+		return desc
+	}
+	tmpl := s.descriptionTemplate(desc)
+	if tmpl == nil {
+		return desc
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, s.templateData(ctx, variantID)); err != nil {
+		return desc
+	}
+	return b.String()
+}
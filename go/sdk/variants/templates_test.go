@@ -0,0 +1,86 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescriptionTemplates(t *testing.T) {
+	inner := mcp.NewServer(&mcp.Implementation{Name: "inner", Version: "v1.0.0"}, nil)
+	mcp.AddTool(inner, &mcp.Tool{Name: "summarize", Description: "Summarize, up to {{.limit}} calls/min"}, summarize)
+	mcp.AddTool(inner, &mcp.Tool{Name: "lookup", Description: "Quick lookup {{.missing"}, lookup)
+	mcp.AddTool(inner, &mcp.Tool{Name: "quota", Description: "Up to {{.quota}} calls"}, lookup)
+
+	limit := 10
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "eu", Description: "Served from {{.region}}"}, inner, 0).
+		WithDescriptionTemplates(func(_ context.Context, variantID string) map[string]any {
+			return map[string]any{"region": variantID + "-west-1", "limit": limit}
+		})
+	session := connectTestClient(t, vs, nil)
+
+	var p struct {
+		AvailableVariants []struct {
+			Description string `json:"description"`
+		} `json:"availableVariants"`
+	}
+	initExtension(t, session, &p)
+	require.Len(t, p.AvailableVariants, 1)
+	assert.Equal(t, "Served from eu-west-1", p.AvailableVariants[0].Description)
+
+	descriptions := func() map[string]string {
+		res, err := session.ListTools(context.Background(), nil)
+		require.NoError(t, err)
+		m := map[string]string{}
+		for _, tool := range res.Tools {
+			m[tool.Name] = tool.Description
+		}
+		return m
+	}
+	assert.Equal(t, map[string]string{
+		"summarize": "Summarize, up to 10 calls/min",
+		"lookup":    "Quick lookup {{.missing", // malformed templates are sent unchanged
+		"quota":     "Up to {{.quota}} calls",  // and so are templates using missing keys
+	}, descriptions())
+
+	// Templates are parsed once per description.
+	tmpl, ok := vs.descTemplates["Summarize, up to {{.limit}} calls/min"]
+	require.True(t, ok)
+	assert.NotNil(t, tmpl)
+
+	// Values are resolved on every listing, and the variant's own tools are
+	// left untouched.
+	limit = 20
+	assert.Equal(t, "Summarize, up to 20 calls/min", descriptions()["summarize"])
+	again := vs.descTemplates["Summarize, up to {{.limit}} calls/min"]
+	assert.Same(t, tmpl, again)
+}
+
+func TestDescriptionTemplates_Malformed(t *testing.T) {
+	inner := mcp.NewServer(&mcp.Implementation{Name: "inner", Version: "v1.0.0"}, nil)
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "eu", Description: "Served from {{.region"}, inner, 0).
+		WithDescriptionTemplates(func(context.Context, string) map[string]any { return nil })
+
+	_, err := vs.mcpServer(TransportOther, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `variant "eu"`)
+}
+
+func TestDescriptionTemplates_CacheBound(t *testing.T) {
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"})
+	for i := range maxDescTemplates + 10 {
+		assert.NotNil(t, vs.descriptionTemplate(fmt.Sprintf("Tool %d for {{.region}}", i)))
+	}
+	assert.LessOrEqual(t, len(vs.descTemplates), maxDescTemplates)
+	assert.NotNil(t, vs.descriptionTemplate("Tool 0 for {{.region}}"), "evicted templates are parsed again")
+}
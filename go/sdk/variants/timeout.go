@@ -0,0 +1,167 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CodeDispatchTimeout is the JSON-RPC error code returned when a request
+// dispatched to a variant does not complete within its timeout (see
+// [Server.WithDispatchTimeouts]). Clients may retry.
+const CodeDispatchTimeout int64 = -32053
+
+// DispatchTimeouts bound how long requests dispatched to variants may take,
+// by method class:
+//
+//   - List: tools/list, resources/list, resources/templates/list and
+//     prompts/list
+//   - Call: tools/call
+//   - Read: resources/read, prompts/get, completion/complete and resource
+//     (un)subscriptions
+//
+// A zero duration inherits the next more general setting: a class falls
+// back to Default, and a variant's settings to the server's (see
+// Server.WithVariantDispatchTimeouts). A negative duration means no
+// timeout.
+type DispatchTimeouts struct {
+	Default time.Duration
+	List    time.Duration
+	Call    time.Duration
+	Read    time.Duration
+}
+
+// forMethod returns the timeout for a method: its class's, else Default.
+// Zero means unset.
+func (t DispatchTimeouts) forMethod(method string) time.Duration {
+	var class time.Duration
+	switch method {
+	case "tools/list", "resources/list", "resources/templates/list", "prompts/list":
+		class = t.List
+	case "tools/call":
+		class = t.Call
+	default:
+		class = t.Read
+	}
+	if class != 0 {
+		return class
+	}
+	return t.Default
+}
+
+// WithDispatchTimeouts sets timeouts for requests dispatched to variants, so
+// that a hung variant server cannot block front requests forever. A request
+// exceeding its timeout fails with a [CodeDispatchTimeout] error whose data
+// carries activeVariant, the method and the timeout, e.g.
+//
+//	{"activeVariant": "coding", "method": "tools/call", "timeoutMs": 30000, "retriable": true}
+//
+// The timeout is applied to the request's context; variant servers that
+// ignore cancellation keep running in the background until they return.
+// Fan-out calls apply the timeout per variant. By default there is no
+// timeout.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithDispatchTimeouts(t DispatchTimeouts) *Server {
+	s.timeouts = t
+	return s
+}
+
+// WithVariantDispatchTimeouts overrides the dispatch timeouts for one
+// variant, e.g. one backed by a slow model. Zero durations fall back to the
+// server's timeouts (see WithDispatchTimeouts). A later call for the same
+// variant replaces the earlier one.
+//
+// The variant must be registered by the time the server is served.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithVariantDispatchTimeouts(variantID string, t DispatchTimeouts) *Server {
+	if s.variantTimeouts == nil {
+		s.variantTimeouts = make(map[string]DispatchTimeouts)
+	}
+	s.variantTimeouts[variantID] = t
+	return s
+}
+
+// validateDispatchTimeouts checks that per-variant timeouts name
+// registered variants.
+func (s *Server) validateDispatchTimeouts() error {
+	for id := range s.variantTimeouts {
+		if !s.hasVariant(id) {
+			return fmt.Errorf("variants: dispatch timeouts for unregistered variant %q", id)
+		}
+	}
+	return nil
+}
+
+// dispatchTimeout returns the timeout for a request to a variant, or zero
+// for none.
+func (s *Server) dispatchTimeout(variantID, method string) time.Duration {
+	d := s.variantTimeouts[variantID].forMethod(method)
+	if d == 0 {
+		d = s.timeouts.forMethod(method)
+	}
+	return max(d, 0)
+}
+
+// withDispatchTimeout calls fn under the request's dispatch timeout,
+// returning a [CodeDispatchTimeout] error once it expires even if fn has not
+// returned.
+func (s *Server) withDispatchTimeout(ctx context.Context, variantID, method string, fn func(context.Context) (mcp.Result, error)) (mcp.Result, error) {
+	timeout := s.dispatchTimeout(variantID, method)
+	if timeout == 0 {
+		return fn(ctx)
+	}
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		result mcp.Result
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := fn(callCtx)
+		done <- outcome{result, err}
+	}()
+	select {
+	case o := <-done:
+		// A result that arrives as the deadline fires is kept.
+		return o.result, o.err
+	case <-callCtx.Done():
+		select {
+		case o := <-done:
+			return o.result, o.err
+		default:
+		}
+		if ctx.Err() == nil {
+			return nil, dispatchTimeoutError(variantID, method, timeout)
+		}
+		// The caller's context was canceled first.
+		return nil, ctx.Err()
+	}
+}
+
+// dispatchTimeoutError returns the retriable error for a request to a
+// variant that timed out.
+func dispatchTimeoutError(variantID, method string, timeout time.Duration) error {
+	dataJSON, _ := json.Marshal(map[string]any{
+		"activeVariant": variantID,
+		"method":        method,
+		"timeoutMs":     timeout.Milliseconds(),
+		"retriable":     true,
+	})
+	return &jsonrpc.Error{
+		Code:    CodeDispatchTimeout,
+		Message: "Server variant timed out",
+		Data:    json.RawMessage(dataJSON),
+	}
+}
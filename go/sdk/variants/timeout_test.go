@@ -0,0 +1,111 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHangingServer returns a server whose "hang" tool blocks until release
+// is closed, ignoring cancellation.
+func newHangingServer(release <-chan struct{}) *mcp.Server {
+	s := mcp.NewServer(&mcp.Implementation{Name: "hanging", Version: "v1.0.0"}, nil)
+	mcp.AddTool(s, &mcp.Tool{Name: "hang"}, func(ctx context.Context, req *mcp.CallToolRequest, in struct{}) (*mcp.CallToolResult, any, error) {
+		<-release
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+	})
+	return s
+}
+
+func TestDispatchTimeouts(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	_, compactServer := newTestServers()
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "slow"}, newHangingServer(release), 0).
+		WithVariant(ServerVariant{ID: "compact"}, compactServer, 1).
+		WithDispatchTimeouts(DispatchTimeouts{Default: time.Hour, Call: 50 * time.Millisecond})
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	start := time.Now()
+	_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "hang"})
+	var jErr *jsonrpc.Error
+	require.True(t, errors.As(err, &jErr))
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.Equal(t, CodeDispatchTimeout, jErr.Code)
+	assert.JSONEq(t, `{"activeVariant":"slow","method":"tools/call","timeoutMs":50,"retriable":true}`, string(jErr.Data))
+
+	// Requests completing in time are unaffected.
+	_, err = session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      mcp.Meta{metaKeyVariant: "compact"},
+		Name:      "summarize",
+		Arguments: map[string]any{"text": "x"},
+	})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+}
+
+func TestDispatchTimeouts_Resolution(t *testing.T) {
+	codingServer, compactServer := newTestServers()
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "coding"}, codingServer, 0).
+		WithVariant(ServerVariant{ID: "compact"}, compactServer, 1).
+		WithDispatchTimeouts(DispatchTimeouts{Default: 10 * time.Second, List: time.Second}).
+		WithVariantDispatchTimeouts("compact", DispatchTimeouts{Default: 20 * time.Second, Call: -1})
+
+	tests := []struct {
+		variant, method string
+		want            time.Duration
+	}{
+		{"coding", "tools/list", time.Second},
+		{"coding", "tools/call", 10 * time.Second},
+		{"coding", "resources/read", 10 * time.Second},
+		{"compact", "tools/list", 20 * time.Second},
+		{"compact", "prompts/get", 20 * time.Second},
+		{"compact", "tools/call", 0},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, vs.dispatchTimeout(tt.variant, tt.method), "%s %s", tt.variant, tt.method)
+	}
+}
+
+func TestWithVariantDispatchTimeouts_UnregisteredVariant(t *testing.T) {
+	vs := newTestVariantServer().WithVariantDispatchTimeouts("nope", DispatchTimeouts{Default: time.Second})
+	_, err := vs.NewRouter(nil)
+	assert.ErrorContains(t, err, `unregistered variant "nope"`)
+}
+
+func TestWithDispatchTimeout_Outcomes(t *testing.T) {
+	vs := newTestVariantServer().WithDispatchTimeouts(DispatchTimeouts{Default: time.Second})
+	ctx := context.Background()
+
+	// A nil result without error is returned as is.
+	res, err := vs.withDispatchTimeout(ctx, "coding", "notifications/x", func(context.Context) (mcp.Result, error) {
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+
+	// The caller's cancellation is reported as such.
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = vs.withDispatchTimeout(cctx, "coding", "tools/list", func(ctx context.Context) (mcp.Result, error) {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return nil, nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
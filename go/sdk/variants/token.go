@@ -0,0 +1,112 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// variantTokenVersion prefixes variant tokens, so that the format can
+// change without misreading old tokens.
+const variantTokenVersion = "v1"
+
+// WithVariantTokens enables variant tokens: the initialize response (and
+// the payload of hint update notifications) carries a variantToken that
+// encodes the session's default variant. A client reconnecting with a new
+// session can present the token in its extension capabilities:
+//
+//	"io.modelcontextprotocol/server-variants": {"variantToken": "v1...."}
+//
+// to restore the same default, even if its hints or the server's ranking
+// changed in between. The restored variant is ranked first, ahead of the
+// RankingFunc output, for the whole session: it stays first when the
+// session is re-ranked on hint updates or catalog changes, so the payload
+// keeps carrying a token for it. A variant pinned with WithDefaultVariant or
+// PinSession still takes precedence. Tokens for unknown, unavailable, or disabled variants, and
+// tokens not signed with key, are ignored. Initialize hooks see the
+// restored ranking and may still change it.
+//
+// Tokens are signed with key using HMAC-SHA256, so they stay valid across
+// server restarts as long as the key does. key must not be empty.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithVariantTokens(key []byte) *Server {
+	if len(key) == 0 {
+		panic("variants: empty variant token key")
+	}
+	s.tokenKey = key
+	return s
+}
+
+// variantToken returns the token restoring variantID as the default.
+func (s *Server) variantToken(variantID string) string {
+	payload := variantTokenVersion + "." + base64.RawURLEncoding.EncodeToString([]byte(variantID))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.tokenMAC(payload))
+}
+
+// parseVariantToken returns the variant ID encoded in token, reporting
+// false if token is malformed or not signed with the server's key.
+func (s *Server) parseVariantToken(token string) (string, bool) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 || !strings.HasPrefix(token, variantTokenVersion+".") {
+		return "", false
+	}
+	payload := token[:i]
+	mac, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil || !hmac.Equal(mac, s.tokenMAC(payload)) {
+		return "", false
+	}
+	id, err := base64.RawURLEncoding.DecodeString(payload[len(variantTokenVersion)+1:])
+	if err != nil {
+		return "", false
+	}
+	return string(id), true
+}
+
+// tokenMAC signs a token payload.
+func (s *Server) tokenMAC(payload string) []byte {
+	h := hmac.New(sha256.New, s.tokenKey)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+// restoredVariant returns the ID of the variant selected by the
+// variantToken the client presented at initialize, or "" if there is none
+// or it is not among ranked. ranked must already be filtered to the
+// variants usable by the session.
+func (s *Server) restoredVariant(req mcp.Request, ranked []ServerVariant) string {
+	if s.tokenKey == nil {
+		return ""
+	}
+	ext, _ := clientExtension(req)
+	id, ok := s.parseVariantToken(ext.VariantToken)
+	if !ok || !slices.ContainsFunc(ranked, func(v ServerVariant) bool { return v.ID == id }) {
+		return ""
+	}
+	return id
+}
+
+// restoreFirst moves the restored variant id, if any, to the front of
+// ranked.
+func restoreFirst(id string, ranked []ServerVariant) []ServerVariant {
+	if id == "" {
+		return ranked
+	}
+	for i, v := range ranked {
+		if v.ID == id {
+			out := make([]ServerVariant, 0, len(ranked))
+			out = append(out, v)
+			out = append(out, ranked[:i]...)
+			return append(out, ranked[i+1:]...)
+		}
+	}
+	return ranked
+}
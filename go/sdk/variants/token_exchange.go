@@ -0,0 +1,262 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// tokenExpirySkew is how long before its expiry an exchanged token is
+// exchanged again.
+const tokenExpirySkew = 10 * time.Second
+
+// TokenExchangeRequest describes a token to obtain for a remote variant's
+// server. See WithTokenExchange.
+type TokenExchangeRequest struct {
+	// VariantID is the variant the token is for.
+	VariantID string
+
+	// Resource is the endpoint of the variant's server, as the RFC 8693
+	// resource (or RFC 8707 resource indicator) of the exchange.
+	Resource string
+
+	// SubjectToken is the client's bearer token, from the Authorization
+	// header of its HTTP request. It is empty for requests the front
+	// server makes on its own behalf, such as capability probes. Client
+	// requests without a bearer token are not exchanged.
+	SubjectToken string
+
+	// TokenInfo describes the client's token, if the HTTP handler is
+	// wrapped with bearer token authentication.
+	TokenInfo *auth.TokenInfo
+}
+
+// ExchangedToken is a token for a remote variant's server.
+type ExchangedToken struct {
+	// AccessToken is sent to the variant's server as a bearer token.
+	AccessToken string
+
+	// Expiry is when the token expires. Zero means it is valid as long as
+	// the subject token is.
+	Expiry time.Time
+}
+
+// TokenExchangeFunc exchanges a client's bearer token for a token scoped
+// to a remote variant's server, typically at an RFC 8693 token exchange
+// endpoint. Without a subject token, it returns the front server's own
+// token for the variant, e.g. from a client credentials grant. It returns
+// nil to send no token.
+type TokenExchangeFunc func(ctx context.Context, req *TokenExchangeRequest) (*ExchangedToken, error)
+
+// WithTokenExchange lets remote variants enforce their own authorization
+// while clients authenticate once, with the front server: the bearer token
+// of each client request dispatched to a remote variant (see
+// WithRemoteVariant) is exchanged with fn for a token scoped to the
+// variant's server, which is sent in the Authorization header of the
+// requests to it. If fn fails, the request fails with its error. Client
+// requests without a bearer token, or whose token fn exchanges for none,
+// are sent without an Authorization header: they never carry the front
+// server's own token. Requests the front server makes on its own behalf,
+// such as capability probes, carry the token fn returns without a subject
+// token.
+//
+// In stateful mode, each session's connection to the variant is
+// initialized with the token exchanged for the client's initialize
+// request, and the session's last exchanged token is reused for its
+// requests until the client's token changes or the exchanged one is about
+// to expire. In stateless mode, connections are shared by all clients, so
+// they are initialized with the front server's own token, and each request
+// is exchanged separately. The front server's own token is reused until it
+// is about to expire. Tokens are not exchanged for in-memory variants.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithTokenExchange(fn TokenExchangeFunc) *Server {
+	s.tokenExchange = fn
+	return s
+}
+
+// backendTokenKey is the context key for the token of a request to a
+// remote variant.
+type backendTokenKey struct{}
+
+// backendCredentials holds the tokens exchanged for a connection to a
+// remote variant.
+type backendCredentials struct {
+	variantID string
+	resource  string
+	exchange  TokenExchangeFunc
+	session   bool // the connection belongs to one front session
+
+	mu      sync.Mutex
+	subject string          // the client token last exchanged, in a session
+	token   *ExchangedToken // exchanged for subject
+	own     *ExchangedToken // the front server's own token
+}
+
+// credentials returns the credentials of a new connection to the variant's
+// server, or nil without token exchange.
+func (b *remoteBackend) credentials(session bool) *backendCredentials {
+	if b.vs.tokenExchange == nil {
+		return nil
+	}
+	return &backendCredentials{variantID: b.variantID, resource: b.endpoint, exchange: b.vs.tokenExchange, session: session}
+}
+
+// tokenFor returns the token exchanged for a client's bearer token, or ""
+// if the client sent none or fn returned none: client requests never carry
+// the front server's own token. Within a session, the last client's token
+// is reused until it is about to expire.
+func (c *backendCredentials) tokenFor(ctx context.Context, subject string, info *auth.TokenInfo) (string, error) {
+	var tok *ExchangedToken
+	if subject != "" {
+		c.mu.Lock()
+		if c.session && c.subject == subject && usableToken(c.token) {
+			tok = c.token
+		}
+		c.mu.Unlock()
+		if tok != nil {
+			return tok.AccessToken, nil
+		}
+		var err error
+		if tok, err = c.exchangeToken(ctx, subject, info); err != nil {
+			return "", err
+		}
+	}
+	if c.session {
+		c.mu.Lock()
+		c.subject, c.token = subject, tok
+		c.mu.Unlock()
+	}
+	if tok == nil {
+		return "", nil
+	}
+	return tok.AccessToken, nil
+}
+
+// ownToken returns the front server's own token, or "" if there is none.
+// It is reused until it is about to expire.
+func (c *backendCredentials) ownToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	tok := c.own
+	c.mu.Unlock()
+	if usableToken(tok) {
+		return tok.AccessToken, nil
+	}
+	tok, err := c.exchangeToken(ctx, "", nil)
+	if err != nil || tok == nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.own = tok
+	c.mu.Unlock()
+	return tok.AccessToken, nil
+}
+
+// exchangeToken calls the exchange function, returning nil if it returns
+// no token.
+func (c *backendCredentials) exchangeToken(ctx context.Context, subject string, info *auth.TokenInfo) (*ExchangedToken, error) {
+	tok, err := c.exchange(ctx, &TokenExchangeRequest{
+		VariantID:    c.variantID,
+		Resource:     c.resource,
+		SubjectToken: subject,
+		TokenInfo:    info,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("variants: exchanging token for variant %q: %w", c.variantID, err)
+	}
+	if tok == nil || tok.AccessToken == "" {
+		return nil, nil
+	}
+	return tok, nil
+}
+
+// usableToken reports whether tok is set and not about to expire.
+func usableToken(tok *ExchangedToken) bool {
+	return tok != nil && (tok.Expiry.IsZero() || time.Until(tok.Expiry) > tokenExpirySkew)
+}
+
+// fallback returns the token of requests to the variant's server that are
+// not dispatched client requests. A session's connection, including its
+// reconnections and warmups, carries the session's last exchanged token, or
+// none: it acts for the client. Other connections, such as capability
+// probes and stateless connections, carry the front server's own token.
+func (c *backendCredentials) fallback(ctx context.Context) (string, error) {
+	if c.session {
+		c.mu.Lock()
+		tok := c.token
+		c.mu.Unlock()
+		if tok == nil {
+			return "", nil
+		}
+		return tok.AccessToken, nil
+	}
+	return c.ownToken(ctx)
+}
+
+// withBackendToken attaches the token exchanged for the client's token of
+// req to ctx, for requests to remote variants when token exchange is
+// enabled. The token is "" if there is none, so that the request carries
+// no Authorization header.
+func (s *Server) withBackendToken(ctx context.Context, conn *innerConnection, req mcp.Request) (context.Context, error) {
+	rs := conn.backendSession.remote
+	if rs == nil || rs.creds == nil {
+		return ctx, nil
+	}
+	var header http.Header
+	var info *auth.TokenInfo
+	if extra := req.GetExtra(); extra != nil {
+		header, info = extra.Header, extra.TokenInfo
+	}
+	tok, err := rs.creds.tokenFor(ctx, bearerToken(header), info)
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, backendTokenKey{}, tok), nil
+}
+
+// bearerToken returns the bearer token of an Authorization header, or "".
+func bearerToken(header http.Header) string {
+	scheme, token, ok := strings.Cut(header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// authTransport sets the Authorization header of HTTP requests to a remote
+// variant to the token of their context, if any, else to the fallback token
+// of the connection. An empty token sends no header.
+type authTransport struct {
+	base  http.RoundTripper // nil means http.DefaultTransport
+	creds *backendCredentials
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	tok, ok := req.Context().Value(backendTokenKey{}).(string)
+	if !ok {
+		var err error
+		if tok, err = t.creds.fallback(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+	if tok == "" {
+		return base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+tok)
+	return base.RoundTrip(req)
+}
@@ -0,0 +1,219 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveAuthorizedRemote serves the coding test server, rejecting requests
+// without an Authorization header, and returns its URL and a function
+// returning the headers it received.
+func serveAuthorizedRemote(t *testing.T) (string, func() []string) {
+	t.Helper()
+	return serveAuthorizedRemoteMethod(t, "")
+}
+
+// serveAuthorizedRemoteMethod is serveAuthorizedRemote, recording only the
+// headers of requests whose body contains method, if set.
+func serveAuthorizedRemoteMethod(t *testing.T, method string) (string, func() []string) {
+	t.Helper()
+	coding, _ := newTestServers()
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return coding }, nil)
+	var mu sync.Mutex
+	var seen []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authz := r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if method == "" || strings.Contains(string(body), method) {
+			mu.Lock()
+			seen = append(seen, authz)
+			mu.Unlock()
+		}
+		if authz == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), seen...)
+	}
+}
+
+func TestWithTokenExchange(t *testing.T) {
+	for _, stateless := range []bool{false, true} {
+		name := "stateful"
+		if stateless {
+			name = "stateless"
+		}
+		t.Run(name, func(t *testing.T) {
+			endpoint, seen := serveAuthorizedRemote(t)
+			var mu sync.Mutex
+			exchanged := make(map[string]int) // subject token -> exchanges
+			vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+				WithRemoteVariant(ServerVariant{ID: "coding", Description: "Remote coding"}, endpoint, 0).
+				WithTokenExchange(func(_ context.Context, req *TokenExchangeRequest) (*ExchangedToken, error) {
+					assert.Equal(t, "coding", req.VariantID)
+					assert.Equal(t, endpoint, req.Resource)
+					mu.Lock()
+					exchanged[req.SubjectToken]++
+					mu.Unlock()
+					if req.SubjectToken == "" {
+						return &ExchangedToken{AccessToken: "own"}, nil
+					}
+					return &ExchangedToken{AccessToken: "backend-" + req.SubjectToken}, nil
+				})
+			t.Cleanup(func() { vs.Close() })
+			session := connectHTTPClient(t, vs, &mcp.StreamableHTTPOptions{Stateless: stateless}, "Authorization", "Bearer client")
+			for range 2 {
+				_, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "analyze_code", Arguments: map[string]any{"code": "x", "language": "go"}})
+				require.NoError(t, err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if stateless {
+				assert.Equal(t, 2, exchanged["client"], "exchanged per request")
+			} else {
+				assert.Equal(t, 1, exchanged["client"], "reused within the session")
+			}
+			assert.Contains(t, seen(), "Bearer backend-client")
+			assert.Contains(t, seen(), "Bearer own", "probes")
+			assert.NotContains(t, seen(), "")
+		})
+	}
+}
+
+func TestWithTokenExchange_Error(t *testing.T) {
+	endpoint, _ := serveAuthorizedRemote(t)
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithRemoteVariant(ServerVariant{ID: "coding", Description: "Remote coding"}, endpoint, 0).
+		WithTokenExchange(func(_ context.Context, req *TokenExchangeRequest) (*ExchangedToken, error) {
+			if req.SubjectToken != "" {
+				return nil, errors.New("invalid_grant")
+			}
+			return &ExchangedToken{AccessToken: "own"}, nil
+		})
+	httpSrv := httptest.NewServer(NewStreamableHTTPHandler(vs, nil))
+	t.Cleanup(httpSrv.Close)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	_, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{
+		Endpoint:   httpSrv.URL,
+		HTTPClient: &http.Client{Transport: headerTransport{"Authorization", "Bearer client"}},
+	}, nil)
+	assert.ErrorContains(t, err, "invalid_grant")
+}
+
+// TestWithTokenExchange_NoClientToken verifies that client requests that
+// have no token to send to a remote variant are sent without one, rather
+// than with the front server's own token.
+func TestWithTokenExchange_NoClientToken(t *testing.T) {
+	for name, header := range map[string][2]string{
+		"no client token":   {"X-Test", "1"},
+		"nothing exchanged": {"Authorization", "Bearer revoked"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			endpoint, seen := serveAuthorizedRemoteMethod(t, `"tools/call"`)
+			vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+				WithRemoteVariant(ServerVariant{ID: "coding", Description: "Remote coding"}, endpoint, 0).
+				WithTokenExchange(func(_ context.Context, req *TokenExchangeRequest) (*ExchangedToken, error) {
+					switch req.SubjectToken {
+					case "":
+						return &ExchangedToken{AccessToken: "own"}, nil
+					case "revoked":
+						return nil, nil
+					}
+					return &ExchangedToken{AccessToken: "backend-" + req.SubjectToken}, nil
+				})
+			t.Cleanup(func() { vs.Close() })
+			session := connectHTTPClient(t, vs, &mcp.StreamableHTTPOptions{Stateless: true}, header[0], header[1])
+
+			_, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "analyze_code", Arguments: map[string]any{"code": "x", "language": "go"}})
+			assert.Error(t, err, "the remote variant rejects the request")
+			assert.Equal(t, []string{""}, seen())
+		})
+	}
+}
+
+func TestBackendCredentials(t *testing.T) {
+	calls := 0
+	expiry := time.Now().Add(time.Hour)
+	c := &backendCredentials{variantID: "coding", session: true, exchange: func(_ context.Context, req *TokenExchangeRequest) (*ExchangedToken, error) {
+		calls++
+		return &ExchangedToken{AccessToken: req.SubjectToken + "-exchanged", Expiry: expiry}, nil
+	}}
+	ctx := context.Background()
+
+	tok, err := c.tokenFor(ctx, "a", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "a-exchanged", tok)
+	_, _ = c.tokenFor(ctx, "a", nil)
+	assert.Equal(t, 1, calls, "cached")
+	_, _ = c.tokenFor(ctx, "b", nil)
+	assert.Equal(t, 2, calls, "client token changed")
+	tok, _ = c.fallback(ctx)
+	assert.Equal(t, "b-exchanged", tok, "session's last token")
+	tok, _ = c.tokenFor(ctx, "", nil)
+	assert.Empty(t, tok, "no client token")
+	assert.Equal(t, 2, calls, "not exchanged")
+	tok, _ = c.fallback(ctx)
+	assert.Empty(t, tok, "never the own token in a session")
+
+	expiry = time.Now().Add(time.Second)
+	_, _ = c.tokenFor(ctx, "c", nil)
+	_, _ = c.tokenFor(ctx, "c", nil)
+	assert.Equal(t, 4, calls, "about to expire")
+
+	shared := &backendCredentials{variantID: "coding", exchange: c.exchange}
+	expiry = time.Now().Add(time.Hour)
+	calls = 0
+	_, _ = shared.tokenFor(ctx, "a", nil)
+	_, _ = shared.tokenFor(ctx, "a", nil)
+	assert.Equal(t, 2, calls, "client tokens are not cached across sessions")
+	tok, _ = shared.fallback(ctx)
+	assert.Equal(t, "-exchanged", tok, "own token")
+	_, _ = shared.fallback(ctx)
+	assert.Equal(t, 3, calls, "own token cached")
+
+	shared.exchange = func(_ context.Context, req *TokenExchangeRequest) (*ExchangedToken, error) {
+		if req.SubjectToken == "" {
+			return &ExchangedToken{AccessToken: "own"}, nil
+		}
+		return nil, nil
+	}
+	tok, err = shared.tokenFor(ctx, "d", nil)
+	require.NoError(t, err)
+	assert.Empty(t, tok, "no token, not the own one")
+}
+
+func TestBearerToken(t *testing.T) {
+	for v, want := range map[string]string{
+		"Bearer abc":  "abc",
+		"bearer  abc": "abc",
+		"Basic abc":   "",
+		"":            "",
+	} {
+		assert.Equal(t, want, bearerToken(http.Header{"Authorization": {v}}), v)
+	}
+	assert.Empty(t, bearerToken(nil))
+}
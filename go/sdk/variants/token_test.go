@@ -0,0 +1,110 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntegration_VariantToken(t *testing.T) {
+	key := []byte("test-key")
+	newServer := func() *Server {
+		return newTestVariantServer().WithRanking(contextSizeRanking).WithVariantTokens(key)
+	}
+	clientOptions := func(contextSize, token string) *mcp.ClientOptions {
+		ext := map[string]any{"variantHints": map[string]any{"hints": map[string]any{HintContextSize: contextSize}}}
+		if token != "" {
+			ext["variantToken"] = token
+		}
+		return &mcp.ClientOptions{Capabilities: &mcp.ClientCapabilities{
+			Experimental: map[string]any{extensionID: ext},
+		}}
+	}
+	type payload struct {
+		DefaultVariant string `json:"defaultVariant"`
+		VariantToken   string `json:"variantToken"`
+	}
+
+	// The first session ranks "compact" first and gets a token for it.
+	var first payload
+	initExtension(t, connectTestClient(t, newServer(), clientOptions("compact", "")), &first)
+	assert.Equal(t, "compact", first.DefaultVariant)
+	require.NotEmpty(t, first.VariantToken)
+
+	t.Run("restores default", func(t *testing.T) {
+		// Different hints would rank "coding" first.
+		session := connectTestClient(t, newServer(), clientOptions("coding", first.VariantToken))
+		var got payload
+		initExtension(t, session, &got)
+		assert.Equal(t, "compact", got.DefaultVariant)
+		assert.Equal(t, first.VariantToken, got.VariantToken)
+
+		res, err := session.ListTools(context.Background(), nil)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"summarize", "lookup"}, toolNames(res.Tools))
+	})
+
+	t.Run("survives hint updates", func(t *testing.T) {
+		vs := newServer()
+		session := connectTestClient(t, vs, clientOptions("coding", first.VariantToken))
+		ctx := context.Background()
+
+		res, err := session.ListTools(ctx, &mcp.ListToolsParams{
+			Meta: mcp.Meta{metaKeyVariantHints: map[string]any{
+				"hints": map[string]any{HintContextSize: "coding"},
+			}},
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"summarize", "lookup"}, toolNames(res.Tools))
+		require.NoError(t, vs.SetVariantAvailability("coding", false, "maintenance"))
+		require.NoError(t, vs.SetVariantAvailability("coding", true, ""))
+
+		info := vs.Sessions()
+		require.Len(t, info, 1)
+		assert.Equal(t, "compact", info[0].DefaultVariant)
+		assert.Equal(t, []string{"compact", "coding"}, info[0].RankedVariants)
+	})
+
+	t.Run("ignores unavailable variant", func(t *testing.T) {
+		vs := newServer()
+		require.NoError(t, vs.SetVariantAvailability("compact", false, "maintenance"))
+		var got payload
+		initExtension(t, connectTestClient(t, vs, clientOptions("coding", first.VariantToken)), &got)
+		assert.Equal(t, "coding", got.DefaultVariant)
+	})
+
+	t.Run("ignores foreign token", func(t *testing.T) {
+		vs := newTestVariantServer().WithRanking(contextSizeRanking).WithVariantTokens([]byte("other-key"))
+		var got payload
+		initExtension(t, connectTestClient(t, vs, clientOptions("coding", first.VariantToken)), &got)
+		assert.Equal(t, "coding", got.DefaultVariant)
+		assert.NotEqual(t, first.VariantToken, got.VariantToken)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		var got payload
+		initExtension(t, connectTestClient(t, newTestVariantServer(), clientOptions("compact", first.VariantToken)), &got)
+		assert.Empty(t, got.VariantToken)
+		assert.Equal(t, "coding", got.DefaultVariant)
+	})
+}
+
+func TestParseVariantToken(t *testing.T) {
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).WithVariantTokens([]byte("k"))
+	id, ok := vs.parseVariantToken(vs.variantToken("a.b/c"))
+	assert.True(t, ok)
+	assert.Equal(t, "a.b/c", id)
+
+	for _, token := range []string{"", "v1", "v1.", "v2.YQ.AA", vs.variantToken("a") + "x", "v1.!!." + "AA"} {
+		_, ok := vs.parseVariantToken(token)
+		assert.False(t, ok, token)
+	}
+	assert.Panics(t, func() { vs.WithVariantTokens(nil) })
+}
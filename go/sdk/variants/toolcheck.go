@@ -0,0 +1,133 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// WithToolCheck enables or disables checking that a tools/call targets a
+// tool the selected variant lists before forwarding it. When enabled, a
+// call for a tool the variant does not list fails with an invalid params
+// error naming the variants that do list it, e.g.
+//
+//	tool "review" not in variant "novice"; found in variants ["expert"]
+//
+// with the tool, activeVariant, foundInVariants and, if any variant lists
+// the tool, the best-ranked of them as suggestedVariant in the error data,
+// so agents can correct their selection instead of interpreting the
+// variant's own error. Only variants in rotation and enabled for the client are
+// suggested. Disabled by default, in which case unknown tools are left to
+// the variant to reject.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithToolCheck(enabled bool) *Server {
+	s.checkTools = enabled
+	return s
+}
+
+// WithToolRedirect enables or disables redirecting tools/call requests
+// that select no variant to a variant listing the tool, when the default
+// variant does not list it. Agents often forget to set the variant _meta;
+// instead of failing, such a call runs on the best-ranked variant in
+// rotation and enabled for the client that lists the tool. Calls that
+// select a variant, explicitly or by tags, are never redirected. Disabled
+// by default.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithToolRedirect(enabled bool) *Server {
+	s.redirectTools = enabled
+	return s
+}
+
+// checkTool looks up the tool called by req in conn's variant under
+// WithToolCheck or WithToolRedirect. If the variant does not list the tool,
+// the call is redirected to the best-ranked variant listing it (see
+// WithToolRedirect), else fails if WithToolCheck is enabled. It returns the
+// connection to forward the call to.
+func (d *dispatcher) checkTool(ctx context.Context, conn *innerConnection, req mcp.Request) (*innerConnection, error) {
+	if !d.server.checkTools && !d.server.redirectTools {
+		return conn, nil
+	}
+	name := toolName(req)
+	if name == "" {
+		return conn, nil
+	}
+	variantID := conn.backendSession.variantID
+	if listed, err := d.listsTool(ctx, conn, name); err != nil || listed {
+		// Listing failures are left to the call to surface.
+		return conn, nil
+	}
+
+	d.mu.RLock()
+	ranked := d.ranked
+	d.mu.RUnlock()
+	if ranked == nil {
+		ranked = d.server.RankedVariants(ctx, VariantHints{})
+	}
+	fc := d.requestFlagContext(req)
+	found := []string{}
+	var suggested *innerConnection
+	for _, v := range ranked {
+		if v.ID == variantID || !d.server.isAvailable(v.ID) || !d.server.flagEnabled(ctx, v.ID, fc) {
+			continue
+		}
+		other, err := d.connection(ctx, v.ID)
+		if err != nil {
+			continue
+		}
+		if listed, _ := d.listsTool(ctx, other, name); listed {
+			found = append(found, v.ID)
+			if suggested == nil {
+				suggested = other
+			}
+		}
+	}
+
+	selected := variantIDFromMeta(req) != "" || len(variantTagsFromMeta(req)) > 0
+	if suggested != nil && d.server.redirectTools && !selected {
+		return suggested, nil
+	}
+	if !d.server.checkTools {
+		return conn, nil
+	}
+
+	msg := fmt.Sprintf("tool %q not in variant %q", name, variantID)
+	data := map[string]any{
+		"tool":            name,
+		"activeVariant":   variantID,
+		"foundInVariants": found,
+	}
+	if len(found) > 0 {
+		msg += fmt.Sprintf("; found in variants %q", found)
+		data["suggestedVariant"] = found[0]
+	}
+	dataJSON, _ := json.Marshal(data)
+	return nil, &jsonrpc.Error{
+		Code:    jsonrpc.CodeInvalidParams,
+		Message: msg,
+		Data:    json.RawMessage(dataJSON),
+	}
+}
+
+// toolName returns the name of the tool called by a tools/call request.
+func toolName(req mcp.Request) string {
+	switch p := req.GetParams().(type) {
+	case *mcp.CallToolParamsRaw:
+		if p != nil {
+			return p.Name
+		}
+	case *mcp.CallToolParams:
+		if p != nil {
+			return p.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToolCheck(t *testing.T) {
+	session := connectTestClient(t, newTestVariantServer().WithToolCheck(true), nil)
+	ctx := context.Background()
+
+	// Listed tools are forwarded.
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "analyze_code", Arguments: map[string]any{"code": "x", "language": "go"}})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+
+	// Tools of other variants are rejected with a suggestion.
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "summarize", Arguments: map[string]any{"text": "x"}})
+	var jErr *jsonrpc.Error
+	require.True(t, errors.As(err, &jErr))
+	assert.Equal(t, int64(jsonrpc.CodeInvalidParams), jErr.Code)
+	assert.Equal(t, `tool "summarize" not in variant "coding"; found in variants ["compact"]`, jErr.Message)
+	assert.JSONEq(t, `{"tool":"summarize","activeVariant":"coding","foundInVariants":["compact"],"suggestedVariant":"compact"}`, string(jErr.Data))
+
+	// Unknown tools are rejected without a suggestion.
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{Meta: mcp.Meta{metaKeyVariant: "compact"}, Name: "nope"})
+	require.True(t, errors.As(err, &jErr))
+	assert.Equal(t, `tool "nope" not in variant "compact"`, jErr.Message)
+	assert.JSONEq(t, `{"tool":"nope","activeVariant":"compact","foundInVariants":[]}`, string(jErr.Data))
+}
+
+func TestToolCheck_Disabled(t *testing.T) {
+	session := connectTestClient(t, newTestVariantServer(), nil)
+
+	// The variant's own error surfaces.
+	_, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "summarize"})
+	var jErr *jsonrpc.Error
+	require.True(t, errors.As(err, &jErr))
+	assert.NotContains(t, jErr.Message, "not in variant")
+}
+
+func TestToolRedirect(t *testing.T) {
+	session := connectTestClient(t, newTestVariantServer().WithToolRedirect(true), nil)
+	ctx := context.Background()
+
+	// Calls without a variant selection run on a variant listing the tool.
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "summarize", Arguments: map[string]any{"text": "x"}})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+
+	// Explicit selections are not redirected; without WithToolCheck the
+	// variant rejects the call itself.
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      mcp.Meta{metaKeyVariant: "coding"},
+		Name:      "summarize",
+		Arguments: map[string]any{"text": "x"},
+	})
+	var jErr *jsonrpc.Error
+	require.True(t, errors.As(err, &jErr))
+	assert.NotContains(t, jErr.Message, "not in variant")
+}
@@ -0,0 +1,186 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// W3C Trace Context headers.
+const (
+	headerTraceParent = "traceparent"
+	headerTraceState  = "tracestate"
+)
+
+// Keys of the attributes passed to TracingOptions.Annotate.
+const (
+	// TraceAttrVariant is the ID of the variant a request is dispatched to.
+	TraceAttrVariant = "mcp.variant.id"
+	// TraceAttrMethod is the MCP method of the request, e.g. "tools/call".
+	TraceAttrMethod = "mcp.method.name"
+	// TraceAttrDispatchID is the DispatchID of the dispatch's Correlation.
+	TraceAttrDispatchID = "mcp.variant.dispatch_id"
+)
+
+// TraceContext is a W3C Trace Context
+// (https://www.w3.org/TR/trace-context/), as carried by the traceparent and
+// tracestate HTTP headers.
+type TraceContext struct {
+	// TraceParent identifies the trace and the calling span, e.g.
+	// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+	TraceParent string
+
+	// TraceState carries vendor-specific trace data. Optional.
+	TraceState string
+}
+
+// traceContextKey is the context key for the TraceContext of a request.
+type traceContextKey struct{}
+
+// ContextWithTraceContext returns a copy of ctx carrying tc, which takes
+// precedence over the headers of the client's request when dispatching
+// (see WithTracing). Tracing libraries use it to propagate the span the
+// front server started for the request, typically from a
+// DispatchInterceptor. tc is ignored if its TraceParent is invalid.
+func ContextWithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+// TraceContextFromContext returns the trace context of the request being
+// dispatched, if any. With WithTracing, it is available to the handlers of
+// in-memory variants, which can start their spans as its children.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	if !ok || !validTraceParent(tc.TraceParent) {
+		return TraceContext{}, false
+	}
+	return tc, true
+}
+
+// TracingOptions configures WithTracing.
+type TracingOptions struct {
+	// Annotate, if set, is called before each dispatch to an in-memory
+	// variant with the attributes TraceAttrVariant, TraceAttrMethod and
+	// TraceAttrDispatchID. In-memory variants handle requests within the
+	// front server's span, so this is where to record which variant served
+	// it, e.g. with OpenTelemetry:
+	//
+	//	Annotate: func(ctx context.Context, attrs map[string]string) {
+	//		span := trace.SpanFromContext(ctx)
+	//		for k, v := range attrs {
+	//			span.SetAttributes(attribute.String(k, v))
+	//		}
+	//	}
+	Annotate func(ctx context.Context, attrs map[string]string)
+}
+
+// WithTracing propagates the W3C trace context of requests to the variants
+// they are dispatched to, so that distributed traces span the front server
+// and the variants' services. The trace context is taken from the request's
+// context (see ContextWithTraceContext), else from the traceparent and
+// tracestate headers of the client's HTTP request; requests over other
+// transports, or without a valid traceparent, are dispatched without one.
+//
+// Requests to remote variants carry the trace context as HTTP headers. The
+// front server does not start spans of its own, so unless a tracing
+// library does so (see ContextWithTraceContext), the variant's spans are
+// children of the client's. In-memory variants see the trace context with
+// TraceContextFromContext, and opts.Annotate annotates the current span.
+// Disabled by default.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithTracing(opts TracingOptions) *Server {
+	s.tracing = &opts
+	return s
+}
+
+// traceDispatch attaches the trace context of req to ctx, if tracing is
+// enabled, and annotates the span of dispatches to in-memory variants.
+func (s *Server) traceDispatch(ctx context.Context, conn *innerConnection, method string, req mcp.Request) context.Context {
+	if s.tracing == nil {
+		return ctx
+	}
+	if _, ok := TraceContextFromContext(ctx); !ok {
+		if extra := req.GetExtra(); extra != nil && extra.Header != nil {
+			tc := TraceContext{
+				TraceParent: extra.Header.Get(headerTraceParent),
+				TraceState:  extra.Header.Get(headerTraceState),
+			}
+			if validTraceParent(tc.TraceParent) {
+				ctx = ContextWithTraceContext(ctx, tc)
+			}
+		}
+	}
+	if conn.backendSession.remote == nil && s.tracing.Annotate != nil {
+		attrs := map[string]string{
+			TraceAttrVariant: conn.backendSession.variantID,
+			TraceAttrMethod:  method,
+		}
+		if c, ok := CorrelationFromContext(ctx); ok && c.DispatchID != "" {
+			attrs[TraceAttrDispatchID] = c.DispatchID
+		}
+		s.tracing.Annotate(ctx, attrs)
+	}
+	return ctx
+}
+
+// traceTransport sets the trace context headers of HTTP requests to remote
+// variants from their context.
+type traceTransport struct {
+	base http.RoundTripper // nil means http.DefaultTransport
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	tc, ok := TraceContextFromContext(req.Context())
+	if !ok {
+		return base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set(headerTraceParent, tc.TraceParent)
+	if tc.TraceState != "" {
+		req.Header.Set(headerTraceState, tc.TraceState)
+	} else {
+		req.Header.Del(headerTraceState)
+	}
+	return base.RoundTrip(req)
+}
+
+// validTraceParent reports whether s is a traceparent header value of a
+// version this package understands: version "00", or a later version whose
+// first fields have the same form.
+func validTraceParent(s string) bool {
+	fields := strings.Split(s, "-")
+	if len(fields) < 4 {
+		return false
+	}
+	version, traceID, parentID, flags := fields[0], fields[1], fields[2], fields[3]
+	if !isLowerHex(version, 2) || version == "ff" || (version == "00" && len(fields) != 4) {
+		return false
+	}
+	return isLowerHex(traceID, 32) && strings.Trim(traceID, "0") != "" &&
+		isLowerHex(parentID, 16) && strings.Trim(parentID, "0") != "" &&
+		isLowerHex(flags, 2)
+}
+
+// isLowerHex reports whether s is n lowercase hexadecimal digits.
+func isLowerHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// headerTransport sets a header on every request.
+type headerTransport struct {
+	key, value string
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(t.key, t.value)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// connectHTTPClient serves vs over streamable HTTP and connects a client
+// sending the given header with every request.
+func connectHTTPClient(t *testing.T, vs *Server, opts *mcp.StreamableHTTPOptions, key, value string) *mcp.ClientSession {
+	t.Helper()
+	httpSrv := httptest.NewServer(NewStreamableHTTPHandler(vs, opts))
+	t.Cleanup(httpSrv.Close)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{
+		Endpoint:   httpSrv.URL,
+		HTTPClient: &http.Client{Transport: headerTransport{key, value}},
+	}, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+func TestWithTracing_Remote(t *testing.T) {
+	coding, _ := newTestServers()
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return coding }, nil)
+	var mu sync.Mutex
+	var traceparents []string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if strings.Contains(string(body), `"tools/call"`) {
+			mu.Lock()
+			traceparents = append(traceparents, r.Header.Get("traceparent"))
+			mu.Unlock()
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(backend.Close)
+
+	for _, tracing := range []bool{false, true} {
+		vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+			WithRemoteVariant(ServerVariant{ID: "coding", Description: "Remote coding"}, backend.URL, 0)
+		if tracing {
+			vs.WithTracing(TracingOptions{Annotate: func(context.Context, map[string]string) {
+				t.Error("remote dispatches are not annotated")
+			}})
+		}
+		session := connectHTTPClient(t, vs, nil, "traceparent", testTraceParent)
+		_, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "analyze_code", Arguments: map[string]any{"code": "x", "language": "go"}})
+		require.NoError(t, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"", testTraceParent}, traceparents)
+}
+
+func TestWithTracing_InMemory(t *testing.T) {
+	srv := mcp.NewServer(&mcp.Implementation{Name: "traced", Version: "v1.0.0"}, nil)
+	var seen TraceContext
+	mcp.AddTool(srv, &mcp.Tool{Name: "trace"}, func(ctx context.Context, req *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+		seen, _ = TraceContextFromContext(ctx)
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	var attrs map[string]string
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "traced", Description: "Traced"}, srv, 0).
+		WithTracing(TracingOptions{Annotate: func(_ context.Context, a map[string]string) { attrs = a }})
+	session := connectHTTPClient(t, vs, nil, "traceparent", testTraceParent)
+	_, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "trace"})
+	require.NoError(t, err)
+
+	assert.Equal(t, TraceContext{TraceParent: testTraceParent}, seen)
+	assert.Equal(t, "traced", attrs[TraceAttrVariant])
+	assert.Equal(t, "tools/call", attrs[TraceAttrMethod])
+	assert.NotEmpty(t, attrs[TraceAttrDispatchID])
+}
+
+func TestTraceContextFromContext(t *testing.T) {
+	_, ok := TraceContextFromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := ContextWithTraceContext(context.Background(), TraceContext{TraceParent: testTraceParent, TraceState: "k=v"})
+	tc, ok := TraceContextFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "k=v", tc.TraceState)
+
+	_, ok = TraceContextFromContext(ContextWithTraceContext(context.Background(), TraceContext{TraceParent: "garbage"}))
+	assert.False(t, ok)
+}
+
+func TestValidTraceParent(t *testing.T) {
+	for s, want := range map[string]bool{
+		testTraceParent: true,
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra": true,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra": false,
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01":       false,
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01":       false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01":       false,
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01":       false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7":          false,
+		"": false,
+	} {
+		assert.Equal(t, want, validTraceParent(s), s)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolTranslator maps calls of a legacy tool onto a tool of a replacement
+// variant. See Server.WithToolTranslation.
+type ToolTranslator struct {
+	// Variant is the ID of the variant that serves translated calls. It
+	// must be registered.
+	Variant string
+
+	// Tool is the name of Variant's tool that serves translated calls.
+	Tool string
+
+	// Arguments maps the legacy call's arguments to Tool's arguments. If
+	// nil, the arguments are passed unchanged.
+	Arguments func(ctx context.Context, args json.RawMessage) (json.RawMessage, error)
+
+	// Result adapts Tool's result to the shape the legacy tool returned.
+	// If nil, the result is passed unchanged.
+	Result func(ctx context.Context, res *mcp.CallToolResult) (*mcp.CallToolResult, error)
+}
+
+// translationKey identifies a legacy tool.
+type translationKey struct {
+	variant, tool string
+}
+
+// WithToolTranslation serves calls of tool toolName on variant fromVariant
+// with a tool of another variant, for migrations between variant API
+// generations (e.g. a v1 "trade" tool replaced by v2's "place_order").
+// A tools/call selecting fromVariant in _meta and naming toolName has its
+// arguments translated, is dispatched to t.Tool on t.Variant like any call
+// to that variant, and has its result adapted back.
+//
+// fromVariant need not be registered, so clients of a removed variant can
+// keep calling its tools; translated calls take precedence over the
+// variant's own tools if it is. Other requests selecting an unregistered
+// fromVariant still fail. Registering a translation for the same legacy
+// tool again replaces it. t.Variant is checked when the front server is
+// built.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithToolTranslation(fromVariant, toolName string, t ToolTranslator) *Server {
+	if t.Variant == "" || t.Tool == "" {
+		panic("variants: tool translation needs a target variant and tool")
+	}
+	if s.translations == nil {
+		s.translations = make(map[translationKey]ToolTranslator)
+	}
+	s.translations[translationKey{fromVariant, toolName}] = t
+	return s
+}
+
+// validateToolTranslations checks that translation targets are registered.
+func (s *Server) validateToolTranslations() error {
+	for k, t := range s.translations {
+		if !s.hasVariant(t.Variant) {
+			return fmt.Errorf("variants: translation of tool %q of variant %q targets unregistered variant %q", k.tool, k.variant, t.Variant)
+		}
+	}
+	return nil
+}
+
+// translation returns the translator for a tools/call, if it calls a
+// translated legacy tool.
+func (s *Server) translation(req *mcp.CallToolRequest) (ToolTranslator, bool) {
+	if len(s.translations) == 0 || req.Params == nil {
+		return ToolTranslator{}, false
+	}
+	t, ok := s.translations[translationKey{variantIDFromMeta(req), req.Params.Name}]
+	return t, ok
+}
+
+// handleTranslated dispatches a call of a legacy tool to its replacement
+// and adapts the result back.
+func (d *dispatcher) handleTranslated(ctx context.Context, req *mcp.CallToolRequest, t ToolTranslator) (mcp.Result, error) {
+	params := *req.Params
+	params.Name = t.Tool
+	params.Meta = maps.Clone(req.Params.Meta)
+	if t.Arguments != nil {
+		args, err := t.Arguments(ctx, req.Params.Arguments)
+		if err != nil {
+			return nil, translationError(req, err)
+		}
+		params.Arguments = args
+	}
+	injectVariantMeta(&params, t.Variant)
+
+	result, err := d.handleDirect(ctx, "tools/call", &mcp.CallToolRequest{
+		Session: req.Session,
+		Params:  &params,
+		Extra:   req.Extra,
+	})
+	if err != nil || t.Result == nil {
+		return result, err
+	}
+	res, _ := result.(*mcp.CallToolResult)
+	if res == nil {
+		return result, nil
+	}
+	adapted, err := t.Result(ctx, res)
+	if err != nil {
+		return nil, translationError(req, err)
+	}
+	d.server.stampActiveVariant(adapted, t.Variant)
+	return adapted, nil
+}
+
+// translationError reports a failed translation of a legacy tool call.
+func translationError(req *mcp.CallToolRequest, err error) error {
+	data, _ := json.Marshal(map[string]any{
+		"requestedVariant": variantIDFromMeta(req),
+		"tool":             req.Params.Name,
+	})
+	return &jsonrpc.Error{
+		Code:    jsonrpc.CodeInternalError,
+		Message: "Tool call translation failed: " + err.Error(),
+		Data:    json.RawMessage(data),
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type placeOrderInput struct {
+	Ticker   string `json:"ticker"`
+	Quantity int    `json:"quantity"`
+}
+
+type placeOrderOutput struct {
+	OrderID string `json:"orderId"`
+}
+
+func placeOrder(_ context.Context, _ *mcp.CallToolRequest, in placeOrderInput) (*mcp.CallToolResult, placeOrderOutput, error) {
+	if in.Ticker == "" {
+		return nil, placeOrderOutput{}, errors.New("missing ticker")
+	}
+	return nil, placeOrderOutput{OrderID: in.Ticker + "-1"}, nil
+}
+
+func TestToolTranslation(t *testing.T) {
+	v2 := mcp.NewServer(&mcp.Implementation{Name: "trading", Version: "v2.0.0"}, nil)
+	mcp.AddTool(v2, &mcp.Tool{Name: "place_order"}, placeOrder)
+
+	translator := ToolTranslator{
+		Variant: "v2",
+		Tool:    "place_order",
+		Arguments: func(_ context.Context, args json.RawMessage) (json.RawMessage, error) {
+			var legacy struct {
+				Symbol string `json:"symbol"`
+				Qty    int    `json:"qty"`
+			}
+			if err := json.Unmarshal(args, &legacy); err != nil {
+				return nil, err
+			}
+			if legacy.Qty <= 0 {
+				return nil, errors.New("qty must be positive")
+			}
+			return json.Marshal(placeOrderInput{Ticker: legacy.Symbol, Quantity: legacy.Qty})
+		},
+		Result: func(_ context.Context, res *mcp.CallToolResult) (*mcp.CallToolResult, error) {
+			data, err := json.Marshal(res.StructuredContent)
+			if err != nil {
+				return nil, err
+			}
+			var out placeOrderOutput
+			if err := json.Unmarshal(data, &out); err != nil {
+				return nil, err
+			}
+			return &mcp.CallToolResult{
+				Content:           []mcp.Content{&mcp.TextContent{Text: "traded"}},
+				StructuredContent: map[string]any{"tradeId": out.OrderID},
+			}, nil
+		},
+	}
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "v2"}, v2, 0).
+		WithToolTranslation("v1", "trade", translator)
+	session := connectTestClient(t, vs, nil)
+	ctx := context.Background()
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "trade",
+		Meta:      mcp.Meta{metaKeyVariant: "v1"},
+		Arguments: map[string]any{"symbol": "ACME", "qty": 3},
+	})
+	require.NoError(t, err)
+	assert.False(t, res.IsError)
+	assert.Equal(t, map[string]any{"tradeId": "ACME-1"}, res.StructuredContent)
+
+	t.Run("translation error", func(t *testing.T) {
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "trade",
+			Meta:      mcp.Meta{metaKeyVariant: "v1"},
+			Arguments: map[string]any{"symbol": "ACME", "qty": 0},
+		})
+		var jErr *jsonrpc.Error
+		require.True(t, errors.As(err, &jErr))
+		assert.Equal(t, int64(jsonrpc.CodeInternalError), jErr.Code)
+		assert.Contains(t, jErr.Message, "qty must be positive")
+		assert.Contains(t, string(jErr.Data), `"requestedVariant":"v1"`)
+	})
+
+	t.Run("other tools of a removed variant", func(t *testing.T) {
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name: "quote",
+			Meta: mcp.Meta{metaKeyVariant: "v1"},
+		})
+		var jErr *jsonrpc.Error
+		require.True(t, errors.As(err, &jErr))
+		assert.Equal(t, "Invalid server variant", jErr.Message)
+	})
+
+	t.Run("replacement tools are unaffected", func(t *testing.T) {
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{
+			Name:      "place_order",
+			Arguments: map[string]any{"ticker": "XYZ", "quantity": 1},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"orderId": "XYZ-1"}, res.StructuredContent)
+	})
+}
+
+func TestWithToolTranslation_Validation(t *testing.T) {
+	coding, _ := newTestServers()
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "coding"}, coding, 0).
+		WithToolTranslation("v1", "trade", ToolTranslator{Variant: "v2", Tool: "place_order"})
+	_, err := vs.NewRouter(nil)
+	assert.ErrorContains(t, err, `unregistered variant "v2"`)
+
+	assert.Panics(t, func() { vs.WithToolTranslation("v1", "trade", ToolTranslator{Variant: "coding"}) })
+}
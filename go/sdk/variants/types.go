@@ -4,7 +4,11 @@
 
 package variants
 
-import "context"
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
 
 const (
 	// Extension ID for variant capability negotiation (plural)
@@ -12,6 +16,22 @@ const (
 
 	// Per-request _meta key for variant selection (singular)
 	metaKeyVariant = "io.modelcontextprotocol/server-variant"
+
+	// Per-request _meta key for updating variantHints after initialize
+	metaKeyVariantHints = "io.modelcontextprotocol/server-variant-hints"
+
+	// Per-request _meta key selecting any variant carrying the listed tags
+	metaKeyVariantTags = "io.modelcontextprotocol/server-variant-tags"
+
+	// Per-request _meta key listing variants to fan a tools/call out to
+	metaKeyFanOut = "io.modelcontextprotocol/server-variant-fanout"
+
+	// Per-request _meta key confirming a destructive tools/call on an
+	// experimental variant
+	metaKeyConfirm = "io.modelcontextprotocol/server-variant-confirm"
+
+	// Per-request _meta key activating a set of variants for the session
+	metaKeyCompose = "io.modelcontextprotocol/server-variant-compose"
 )
 
 // ---------------------------------------------------------------------------
@@ -86,12 +106,54 @@ type ServerVariant struct {
 	// Priority() getter for use in custom RankingFunc implementations.
 	priority int
 
+	// Tags group variants into categories (e.g. "read-only", "beta") that
+	// clients can select by instead of naming a variant ID; see
+	// SelectVariantTags. Unlike Hints, tags carry no value and are not used
+	// for ranking.
+	Tags []string `json:"tags,omitempty"`
+
+	// DocsURL optionally links to documentation for this variant, for client
+	// UIs that let users pick a variant.
+	DocsURL string `json:"docsUrl,omitempty"`
+
+	// Icons optionally provide visual identifiers for this variant, in the
+	// same form as MCP tool and implementation icons.
+	Icons []mcp.Icon `json:"icons,omitempty"`
+
 	// Status is the stability status of this variant.
 	// Defaults to Stable if empty.
 	Status VariantStatus `json:"status,omitempty"`
 
 	// DeprecationInfo provides migration guidance when Status is Deprecated.
 	DeprecationInfo *DeprecationInfo `json:"deprecationInfo,omitempty"`
+
+	// MinimumProtocolVersion is the oldest MCP protocol version (e.g.
+	// "2025-06-18") the variant supports, for variants relying on newer MCP
+	// features. The variant is hidden from clients that negotiated an older
+	// version at initialize, as if a FlagProvider disabled it for them. If
+	// empty, the variant is offered to all clients.
+	MinimumProtocolVersion string `json:"minimumProtocolVersion,omitempty"`
+
+	// Score is an optional relevance score for the client's hints. It is
+	// not set at registration; a RankingFunc may set it on the variants it
+	// returns, and it is then reported alongside the variant in the
+	// initialize response.
+	Score float64 `json:"score,omitempty"`
+
+	// MatchReason is an optional human-readable explanation of why the
+	// variant was ranked where it was (e.g. "modelFamily matched"). Like
+	// Score, it is set by a RankingFunc rather than at registration.
+	MatchReason string `json:"matchReason,omitempty"`
+
+	// Extra holds additional metadata for richer clients to display, such
+	// as a pricing tier or SLA. Keys MUST be namespaced with a "/" (e.g.
+	// "example.com/tier") so they cannot collide with fields defined by the
+	// extension; registering a variant with an unnamespaced key panics.
+	// Values must be JSON-serializable.
+	//
+	// Entries are added as top-level fields of the variant's
+	// availableVariants entry in the initialize response.
+	Extra map[string]any `json:"-"`
 }
 
 // Priority returns the variant's priority value. Lower values indicate
@@ -184,3 +246,18 @@ func HintValue[T any](h VariantHints, key string) (T, bool) {
 // Note: The default (first) variant is also used when the client does not
 // support variants at all.
 type RankingFunc func(ctx context.Context, hints VariantHints, variants []ServerVariant) []ServerVariant
+
+// ---------------------------------------------------------------------------
+// Recommendation function
+// ---------------------------------------------------------------------------
+
+// RecommendFunc is called during initialization, after ranking, to choose the
+// variant the server recommends to the client. It receives the client hints
+// and the ranked variants and returns the ID of the recommended variant. The
+// result is reported as recommendedVariant in the initialize response, which
+// lets a server recommend something other than the first-ranked variant when
+// policy dictates (for example, steering clients towards a replacement while
+// keeping ranking purely hint-driven).
+//
+// Returning an empty or unknown ID falls back to the first-ranked variant.
+type RecommendFunc func(ctx context.Context, hints VariantHints, ranked []ServerVariant) string
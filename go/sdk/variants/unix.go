@@ -0,0 +1,173 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// UnixSocketOptions configures [Server.ServeUnix].
+type UnixSocketOptions struct {
+	// Mode is the permission mode applied to the socket file. Defaults to
+	// 0600 (owner only) if zero.
+	Mode fs.FileMode
+
+	// RemoveExisting removes a stale socket at the socket path before
+	// listening. Without it, listening on an existing path fails, as it
+	// does with it if the path is not a socket.
+	RemoveExisting bool
+}
+
+// ServeUnix serves the variant server on a Unix domain socket at path until
+// ctx is cancelled, which suits local agent integrations that want isolation
+// without opening TCP ports. Each accepted connection is a stateful session
+// speaking newline-delimited JSON-RPC, as over stdio.
+//
+// The socket file is created with opts.Mode permissions, which apply
+// before it accepts connections, and removed on return. Like [Server.Run],
+// ServeUnix closes the server when it returns.
+func (s *Server) ServeUnix(ctx context.Context, path string, opts *UnixSocketOptions) error {
+	var o UnixSocketOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Mode == 0 {
+		o.Mode = 0o600
+	}
+
+	release, err := s.claimRun()
+	if err != nil {
+		return err
+	}
+	srv, err := s.mcpServer(TransportUnix, false)
+	if err != nil {
+		release()
+		return err
+	}
+	defer s.Close()
+
+	if info, err := os.Lstat(path); err == nil {
+		if !o.RemoveExisting {
+			return fmt.Errorf("variants: socket path %s already exists", path)
+		}
+		if info.Mode().Type() != fs.ModeSocket {
+			return fmt.Errorf("variants: not removing %s, which is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("variants: removing existing socket: %w", err)
+		}
+	}
+	ln, err := listenUnix(path, o.Mode)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(path)
+
+	// Stop accepting once the context is done.
+	stop := context.AfterFunc(ctx, func() { ln.Close() })
+	defer stop()
+
+	var (
+		mu       sync.Mutex
+		sessions = make(map[*mcp.ServerSession]bool)
+		wg       sync.WaitGroup
+	)
+	defer func() {
+		mu.Lock()
+		for ss := range sessions {
+			ss.Close()
+		}
+		mu.Unlock()
+		wg.Wait()
+	}()
+
+	return acceptConns(ctx, ln, func(conn net.Conn) {
+		ss, err := srv.Connect(ctx, &mcp.IOTransport{Reader: conn, Writer: conn}, nil)
+		if err != nil {
+			conn.Close()
+			return
+		}
+		mu.Lock()
+		sessions[ss] = true
+		mu.Unlock()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ss.Wait()
+			mu.Lock()
+			delete(sessions, ss)
+			mu.Unlock()
+		}()
+	})
+}
+
+// acceptConns passes the connections accepted by ln to handle until ln
+// fails or ctx is done. Like [net/http.Server], it retries temporary
+// errors, such as running out of file descriptors, after a delay that
+// doubles from 5ms up to 1s.
+func acceptConns(ctx context.Context, ln net.Listener, handle func(net.Conn)) error {
+	var delay time.Duration
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Temporary() {
+				delay = min(max(2*delay, 5*time.Millisecond), time.Second)
+				t := time.NewTimer(delay)
+				select {
+				case <-t.C:
+				case <-ctx.Done():
+					t.Stop()
+					return ctx.Err()
+				}
+				continue
+			}
+			return err
+		}
+		delay = 0
+		handle(conn)
+	}
+}
+
+// listenUnix listens on a Unix domain socket at path with the given
+// permissions. The socket is created in a private directory next to path
+// and linked into place once its permissions are set, so that it is never
+// reachable with broader ones. Linking fails if path exists.
+func listenUnix(path string, mode fs.FileMode) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".sock")
+	if err != nil {
+		return nil, fmt.Errorf("variants: creating socket directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	tmp := filepath.Join(dir, "s")
+	ln, err := net.Listen("unix", tmp)
+	if err != nil {
+		return nil, err
+	}
+	// The listener's path is removed with dir; path is removed by the caller.
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(tmp, mode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("variants: setting socket permissions: %w", err)
+	}
+	if err := os.Link(tmp, path); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeUnix(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("socket permissions are not supported on windows")
+	}
+	// Keep the path short: socket paths are limited to ~100 bytes.
+	dir, err := os.MkdirTemp("", "vs")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "mcp.sock")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- newTestVariantServer().ServeUnix(ctx, path, &UnixSocketOptions{Mode: 0o660})
+	}()
+
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("unix", path)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o660), info.Mode().Perm())
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "unix-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(ctx, &mcp.IOTransport{Reader: conn, Writer: conn}, nil)
+	require.NoError(t, err)
+
+	tools, err := session.ListTools(ctx, &mcp.ListToolsParams{
+		Meta: mcp.Meta{metaKeyVariant: "compact"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, toolNames(tools.Tools), "summarize")
+	session.Close()
+
+	cancel()
+	assert.True(t, errors.Is(<-errCh, context.Canceled))
+	_, err = os.Stat(path)
+	assert.True(t, errors.Is(err, os.ErrNotExist), "socket file should be removed")
+}
+
+func TestServeUnix_Existing(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("socket permissions are not supported on windows")
+	}
+	dir, err := os.MkdirTemp("", "vs")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	ctx := context.Background()
+
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, []byte("keep"), 0o600))
+	err = newTestVariantServer().ServeUnix(ctx, file, &UnixSocketOptions{RemoveExisting: true})
+	assert.ErrorContains(t, err, "not a socket")
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "keep", string(data))
+
+	// A stale socket is removed only with RemoveExisting.
+	stale := filepath.Join(dir, "stale.sock")
+	ln, err := net.Listen("unix", stale)
+	require.NoError(t, err)
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+	err = newTestVariantServer().ServeUnix(ctx, stale, nil)
+	assert.ErrorContains(t, err, "already exists")
+
+	ctx, cancel := context.WithCancel(ctx)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- newTestVariantServer().ServeUnix(ctx, stale, &UnixSocketOptions{RemoveExisting: true})
+	}()
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("unix", stale)
+		if err == nil {
+			conn.Close()
+		}
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	assert.True(t, errors.Is(<-errCh, context.Canceled))
+}
+
+// flakyListener fails its first accepts with a temporary error.
+type flakyListener struct {
+	net.Listener
+	failures int
+}
+
+type temporaryError struct{}
+
+func (temporaryError) Error() string   { return "too many open files" }
+func (temporaryError) Timeout() bool   { return false }
+func (temporaryError) Temporary() bool { return true }
+
+func (l *flakyListener) Accept() (net.Conn, error) {
+	if l.failures > 0 {
+		l.failures--
+		return nil, temporaryError{}
+	}
+	return l.Listener.Accept()
+}
+
+func TestAcceptConns_Temporary(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	flaky := &flakyListener{Listener: ln, failures: 3}
+
+	accepted := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- acceptConns(context.Background(), flaky, func(conn net.Conn) {
+			conn.Close()
+			close(accepted)
+		})
+	}()
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	select {
+	case <-accepted:
+	case err := <-errCh:
+		t.Fatalf("acceptConns returned %v after a temporary error", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection was not accepted")
+	}
+
+	ln.Close()
+	assert.ErrorIs(t, <-errCh, net.ErrClosed)
+}
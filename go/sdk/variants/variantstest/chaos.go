@@ -0,0 +1,146 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variantstest
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ChaosConfig describes the random faults injected into requests
+// dispatched to a variant. Probabilities are between 0 and 1 and drawn
+// independently for each request, so a request may be delayed and then
+// fail. The zero ChaosConfig injects nothing.
+type ChaosConfig struct {
+	// Methods restricts the faults to these MCP methods, e.g.
+	// "tools/call". Empty means all methods.
+	Methods []string
+
+	// DelayProbability is the probability of delaying a request by a
+	// random duration of up to MaxDelay before it is dispatched, or until
+	// it is canceled.
+	DelayProbability float64
+	MaxDelay         time.Duration
+
+	// ErrorProbability is the probability of failing a request with Err,
+	// e.g. a *jsonrpc.Error.
+	ErrorProbability float64
+	Err              error
+
+	// DropProbability is the probability of failing a request with
+	// [mcp.ErrConnectionClosed], as if the connection to the variant's
+	// backend had dropped.
+	DropProbability float64
+}
+
+// Chaos injects random faults into requests dispatched to variants, per
+// variant ID, to validate client retry and fallback behavior against
+// variant-aware servers in tests and staging. Unlike a [FaultInjector],
+// whose faults are deterministic, it draws them with configurable
+// probabilities from a seeded source, so that a failing run can be
+// reproduced. It is safe for concurrent use; configurations can be changed
+// while serving.
+type Chaos struct {
+	mu       sync.Mutex
+	rand     *rand.Rand
+	configs  map[string]ChaosConfig
+	injected map[string]int // requests delayed or failed, by variant ID
+}
+
+// NewChaos returns a Chaos injecting no faults, drawing them from a source
+// seeded with seed.
+func NewChaos(seed uint64) *Chaos {
+	return &Chaos{
+		rand:     rand.New(rand.NewPCG(seed, seed)),
+		configs:  make(map[string]ChaosConfig),
+		injected: make(map[string]int),
+	}
+}
+
+// Set injects random faults described by config into requests dispatched
+// to the variant with the given ID, replacing any previous configuration,
+// and resets the variant's counter.
+func (c *Chaos) Set(variantID string, config ChaosConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	config.Methods = slices.Clone(config.Methods)
+	c.configs[variantID] = config
+	delete(c.injected, variantID)
+}
+
+// Clear stops injecting faults into requests dispatched to the variant with
+// the given ID.
+func (c *Chaos) Clear(variantID string) {
+	c.Set(variantID, ChaosConfig{})
+}
+
+// Injected returns the number of requests dispatched to the variant with
+// the given ID that were delayed or failed since its configuration was set.
+func (c *Chaos) Injected(variantID string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.injected[variantID]
+}
+
+// Interceptor returns the dispatch interceptor injecting the faults, for
+// variants.Server.WithDispatchInterceptor. Register it last to have the
+// faults seen by the other interceptors, as a real backend's would be.
+// Faults are injected per attempt, so retry policies (see
+// variants.Server.WithRetryPolicy) apply to them as to real faults.
+func (c *Chaos) Interceptor() variants.DispatchInterceptor {
+	return func(ctx context.Context, info variants.DispatchInfo, next variants.DispatchHandler) (mcp.Result, error) {
+		delay, err := c.draw(info)
+		if delay > 0 {
+			t := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return nil, ctx.Err()
+			case <-t.C:
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return next(ctx)
+	}
+}
+
+// draw returns the delay and failure, if any, of a dispatch, counting it.
+func (c *Chaos) draw(info variants.DispatchInfo) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	config := c.configs[info.VariantID]
+	if len(config.Methods) > 0 && !slices.Contains(config.Methods, info.Method) {
+		return 0, nil
+	}
+	var (
+		delay time.Duration
+		err   error
+	)
+	if config.MaxDelay > 0 && c.chance(config.DelayProbability) {
+		delay = time.Duration(c.rand.Int64N(int64(config.MaxDelay) + 1))
+	}
+	if c.chance(config.DropProbability) {
+		err = mcp.ErrConnectionClosed
+	} else if config.Err != nil && c.chance(config.ErrorProbability) {
+		err = config.Err
+	}
+	if delay > 0 || err != nil {
+		c.injected[info.VariantID]++
+	}
+	return delay, err
+}
+
+// chance reports true with probability p. c.mu must be held.
+func (c *Chaos) chance(p float64) bool {
+	return p > 0 && c.rand.Float64() < p
+}
@@ -0,0 +1,79 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variantstest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChaos_Error(t *testing.T) {
+	chaos := NewChaos(1)
+	session := connect(t, chaos.Interceptor())
+	ctx := context.Background()
+
+	wantErr := &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: "backend overloaded"}
+	chaos.Set("flaky", ChaosConfig{ErrorProbability: 1, Err: wantErr, Methods: []string{"tools/call"}})
+	err := callEcho(ctx, session, "flaky")
+	var jErr *jsonrpc.Error
+	require.True(t, errors.As(err, &jErr))
+	assert.Equal(t, wantErr.Message, jErr.Message)
+	require.NoError(t, callEcho(ctx, session, "stable"))
+	assert.Equal(t, 1, chaos.Injected("flaky"))
+
+	// Other methods are unaffected.
+	_, err = session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{"io.modelcontextprotocol/server-variant": "flaky"}})
+	require.NoError(t, err)
+
+	chaos.Clear("flaky")
+	require.NoError(t, callEcho(ctx, session, "flaky"))
+	assert.Equal(t, 0, chaos.Injected("flaky"))
+}
+
+func TestChaos_Drop(t *testing.T) {
+	chaos := NewChaos(1)
+	session := connect(t, chaos.Interceptor())
+
+	chaos.Set("flaky", ChaosConfig{DropProbability: 1})
+	require.Error(t, callEcho(context.Background(), session, "flaky"))
+	assert.Equal(t, 1, chaos.Injected("flaky"))
+}
+
+func TestChaos_Delay(t *testing.T) {
+	chaos := NewChaos(1)
+	session := connect(t, chaos.Interceptor())
+
+	chaos.Set("flaky", ChaosConfig{DelayProbability: 1, MaxDelay: 5 * time.Millisecond})
+	require.NoError(t, callEcho(context.Background(), session, "flaky"))
+	assert.Equal(t, 1, chaos.Injected("flaky"))
+}
+
+func TestChaos_Probability(t *testing.T) {
+	// The same seed injects the same faults.
+	run := func() []int {
+		chaos := NewChaos(42)
+		session := connect(t, chaos.Interceptor())
+		chaos.Set("flaky", ChaosConfig{DropProbability: 0.5})
+		var failed []int
+		for i := range 20 {
+			if callEcho(context.Background(), session, "flaky") != nil {
+				failed = append(failed, i)
+			}
+		}
+		assert.Equal(t, len(failed), chaos.Injected("flaky"))
+		return failed
+	}
+	failed := run()
+	assert.NotEmpty(t, failed)
+	assert.Less(t, len(failed), 20)
+	assert.Equal(t, failed, run())
+}
@@ -0,0 +1,233 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+// Package variantstest provides utilities for testing variant servers and
+// their clients.
+//
+// A [FaultInjector] simulates degraded variants (slow responses, dropped
+// connections, malformed cursors and partial failures) without standing up
+// flaky backends. It is installed as a dispatch interceptor:
+//
+//	faults := variantstest.NewFaultInjector()
+//	vs.WithDispatchInterceptor(faults.Interceptor())
+//	faults.Set("compact", variantstest.Fault{Latency: 2 * time.Second})
+//
+// [Chaos] injects random faults with configurable probabilities instead,
+// for chaos testing in tests and staging:
+//
+//	chaos := variantstest.NewChaos(seed)
+//	vs.WithDispatchInterceptor(chaos.Interceptor())
+//	chaos.Set("compact", variantstest.ChaosConfig{DropProbability: 0.01})
+//
+// [Replay] turns a transcript captured with variants.Server.WithCapture
+// into a regression test:
+//
+//	transcript, err := variants.ReadCaptureFiles("testdata/incident.jsonl")
+//	...
+//	variantstest.Replay(t, newServer(), transcript)
+//
+// [AssertRanking] and [RunRankingCases] test ranking functions without a
+// server:
+//
+//	variantstest.AssertRanking(t, rank, hints, catalog, "compact", "standard")
+package variantstest
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MalformedCursor is the cursor returned by list requests of variants with
+// Fault.MalformedCursors. Requests passing it back to the variant fail
+// with an invalid params error, as for a cursor the variant cannot decode.
+const MalformedCursor = "variantstest:malformed-cursor"
+
+// Fault describes the faults injected into requests dispatched to a
+// variant. The zero Fault injects nothing.
+type Fault struct {
+	// Methods restricts the fault to these MCP methods, e.g. "tools/call".
+	// Empty means all methods.
+	Methods []string
+
+	// Latency delays each affected request before it is dispatched, or
+	// until the request is canceled.
+	Latency time.Duration
+
+	// Drop fails affected requests with [mcp.ErrConnectionClosed], as if
+	// the connection to the variant's backend had dropped.
+	Drop bool
+
+	// Err, if non-nil and Drop is false, fails affected requests with Err,
+	// e.g. a *jsonrpc.Error.
+	Err error
+
+	// FailEvery limits failures (Drop or Err) to every FailEvery-th
+	// affected request, simulating partial failures. Zero or one means
+	// every affected request fails.
+	FailEvery int
+
+	// MalformedCursors makes affected list requests return MalformedCursor
+	// as their next cursor, and rejects requests passing it back.
+	MalformedCursors bool
+}
+
+// fails reports whether the fault fails requests.
+func (f Fault) fails() bool {
+	return f.Drop || f.Err != nil
+}
+
+// FaultInjector injects faults into requests dispatched to variants, per
+// variant ID. It is safe for concurrent use; faults can be changed while
+// serving.
+type FaultInjector struct {
+	mu       sync.Mutex
+	faults   map[string]Fault
+	seen     map[string]int // affected requests, by variant ID
+	injected map[string]int // requests failed, by variant ID
+}
+
+// NewFaultInjector returns a FaultInjector injecting no faults.
+func NewFaultInjector() *FaultInjector {
+	return &FaultInjector{
+		faults:   make(map[string]Fault),
+		seen:     make(map[string]int),
+		injected: make(map[string]int),
+	}
+}
+
+// Set injects fault into requests dispatched to the variant with the given
+// ID, replacing any previous fault, and resets the variant's counters.
+func (fi *FaultInjector) Set(variantID string, fault Fault) {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fi.faults[variantID] = fault
+	delete(fi.seen, variantID)
+	delete(fi.injected, variantID)
+}
+
+// Clear stops injecting faults into requests dispatched to the variant with
+// the given ID.
+func (fi *FaultInjector) Clear(variantID string) {
+	fi.Set(variantID, Fault{})
+}
+
+// Injected returns the number of requests dispatched to the variant with
+// the given ID that were failed by its fault since it was set.
+func (fi *FaultInjector) Injected(variantID string) int {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	return fi.injected[variantID]
+}
+
+// Interceptor returns the dispatch interceptor injecting the faults, for
+// variants.Server.WithDispatchInterceptor. Register it last to have the
+// faults seen by the other interceptors, as a real backend's would be.
+func (fi *FaultInjector) Interceptor() variants.DispatchInterceptor {
+	return func(ctx context.Context, info variants.DispatchInfo, next variants.DispatchHandler) (mcp.Result, error) {
+		fault, fail := fi.affect(info)
+		if fault.Latency > 0 {
+			t := time.NewTimer(fault.Latency)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return nil, ctx.Err()
+			case <-t.C:
+			}
+		}
+		if fail {
+			if fault.Drop {
+				return nil, mcp.ErrConnectionClosed
+			}
+			return nil, fault.Err
+		}
+		if !fault.MalformedCursors {
+			return next(ctx)
+		}
+		if cursor, ok := listCursor(info.Params); ok && cursor == MalformedCursor {
+			data, _ := json.Marshal(map[string]any{"cursor": cursor})
+			return nil, &jsonrpc.Error{
+				Code:    jsonrpc.CodeInvalidParams,
+				Message: "invalid cursor",
+				Data:    json.RawMessage(data),
+			}
+		}
+		result, err := next(ctx)
+		if err == nil {
+			setNextCursor(result, MalformedCursor)
+		}
+		return result, err
+	}
+}
+
+// affect returns the fault affecting a dispatch, and whether the dispatch
+// fails, counting it.
+func (fi *FaultInjector) affect(info variants.DispatchInfo) (Fault, bool) {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+	fault := fi.faults[info.VariantID]
+	if len(fault.Methods) > 0 && !slices.Contains(fault.Methods, info.Method) {
+		return Fault{}, false
+	}
+	if !fault.fails() {
+		return fault, false
+	}
+	fi.seen[info.VariantID]++
+	if fault.FailEvery > 1 && fi.seen[info.VariantID]%fault.FailEvery != 0 {
+		return fault, false
+	}
+	fi.injected[info.VariantID]++
+	return fault, true
+}
+
+// listCursor returns the cursor of list request params.
+func listCursor(params mcp.Params) (string, bool) {
+	switch p := params.(type) {
+	case *mcp.ListToolsParams:
+		if p != nil {
+			return p.Cursor, true
+		}
+	case *mcp.ListResourcesParams:
+		if p != nil {
+			return p.Cursor, true
+		}
+	case *mcp.ListPromptsParams:
+		if p != nil {
+			return p.Cursor, true
+		}
+	case *mcp.ListResourceTemplatesParams:
+		if p != nil {
+			return p.Cursor, true
+		}
+	}
+	return "", false
+}
+
+// setNextCursor sets the next cursor of a list result.
+func setNextCursor(result mcp.Result, cursor string) {
+	switch r := result.(type) {
+	case *mcp.ListToolsResult:
+		if r != nil {
+			r.NextCursor = cursor
+		}
+	case *mcp.ListResourcesResult:
+		if r != nil {
+			r.NextCursor = cursor
+		}
+	case *mcp.ListPromptsResult:
+		if r != nil {
+			r.NextCursor = cursor
+		}
+	case *mcp.ListResourceTemplatesResult:
+		if r != nil {
+			r.NextCursor = cursor
+		}
+	}
+}
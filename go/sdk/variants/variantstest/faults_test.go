@@ -0,0 +1,136 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variantstest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type echoInput struct {
+	Text string `json:"text"`
+}
+
+func echo(_ context.Context, _ *mcp.CallToolRequest, in echoInput) (*mcp.CallToolResult, any, error) {
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: in.Text}}}, nil, nil
+}
+
+// connect serves a variant server with variants "stable" and "flaky", both
+// with an echo tool, and the interceptor installed, and connects a client.
+func connect(t *testing.T, interceptor variants.DispatchInterceptor) *mcp.ClientSession {
+	t.Helper()
+	vs := variants.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithDispatchInterceptor(interceptor)
+	for i, id := range []string{"stable", "flaky"} {
+		srv := mcp.NewServer(&mcp.Implementation{Name: id, Version: "1.0.0"}, nil)
+		mcp.AddTool(srv, &mcp.Tool{Name: "echo"}, echo)
+		vs.WithVariant(variants.ServerVariant{ID: id, Status: variants.Stable}, srv, i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	st, ct := mcp.NewInMemoryTransports()
+	go vs.Run(ctx, st)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(ctx, ct, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		session.Close()
+		cancel()
+	})
+	return session
+}
+
+func callEcho(ctx context.Context, session *mcp.ClientSession, variantID string) error {
+	_, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Meta:      mcp.Meta{"io.modelcontextprotocol/server-variant": variantID},
+		Name:      "echo",
+		Arguments: map[string]any{"text": "hi"},
+	})
+	return err
+}
+
+func TestFaultInjector_Drop(t *testing.T) {
+	fi := NewFaultInjector()
+	session := connect(t, fi.Interceptor())
+	ctx := context.Background()
+
+	fi.Set("flaky", Fault{Drop: true, Methods: []string{"tools/call"}})
+	require.Error(t, callEcho(ctx, session, "flaky"))
+	require.NoError(t, callEcho(ctx, session, "stable"))
+	assert.Equal(t, 1, fi.Injected("flaky"))
+
+	// Other methods are unaffected.
+	_, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{"io.modelcontextprotocol/server-variant": "flaky"}})
+	require.NoError(t, err)
+
+	fi.Clear("flaky")
+	require.NoError(t, callEcho(ctx, session, "flaky"))
+	assert.Equal(t, 0, fi.Injected("flaky"))
+}
+
+func TestFaultInjector_PartialFailure(t *testing.T) {
+	fi := NewFaultInjector()
+	session := connect(t, fi.Interceptor())
+	ctx := context.Background()
+
+	wantErr := &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: "backend overloaded"}
+	fi.Set("flaky", Fault{Err: wantErr, FailEvery: 3})
+	var failed []int
+	for i := 1; i <= 6; i++ {
+		if err := callEcho(ctx, session, "flaky"); err != nil {
+			var jErr *jsonrpc.Error
+			require.True(t, errors.As(err, &jErr))
+			assert.Equal(t, wantErr.Message, jErr.Message)
+			failed = append(failed, i)
+		}
+	}
+	assert.Equal(t, []int{3, 6}, failed)
+	assert.Equal(t, 2, fi.Injected("flaky"))
+}
+
+func TestFaultInjector_Latency(t *testing.T) {
+	fi := NewFaultInjector()
+	session := connect(t, fi.Interceptor())
+
+	fi.Set("flaky", Fault{Latency: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.Error(t, callEcho(ctx, session, "flaky"))
+
+	fi.Set("flaky", Fault{Latency: 10 * time.Millisecond})
+	start := time.Now()
+	require.NoError(t, callEcho(context.Background(), session, "flaky"))
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+}
+
+func TestFaultInjector_MalformedCursors(t *testing.T) {
+	fi := NewFaultInjector()
+	session := connect(t, fi.Interceptor())
+	ctx := context.Background()
+
+	fi.Set("flaky", Fault{MalformedCursors: true})
+	meta := mcp.Meta{"io.modelcontextprotocol/server-variant": "flaky"}
+	page, err := session.ListTools(ctx, &mcp.ListToolsParams{Meta: meta})
+	require.NoError(t, err)
+	require.NotEmpty(t, page.NextCursor)
+
+	_, err = session.ListTools(ctx, &mcp.ListToolsParams{Meta: meta, Cursor: page.NextCursor})
+	var jErr *jsonrpc.Error
+	require.True(t, errors.As(err, &jErr))
+	assert.Equal(t, int64(jsonrpc.CodeInvalidParams), jErr.Code)
+
+	// The stable variant pages normally.
+	page, err = session.ListTools(ctx, &mcp.ListToolsParams{Meta: mcp.Meta{"io.modelcontextprotocol/server-variant": "stable"}})
+	require.NoError(t, err)
+	assert.Empty(t, page.NextCursor)
+}
@@ -0,0 +1,170 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variantstest
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+)
+
+// AssertRanking ranks candidates with fn for hints, and reports a test
+// error unless the ranking is valid, as checked by variants.CheckRanking,
+// and lists the variants with the IDs wantOrder, in that order. It returns
+// the ranking, for checks of scores and match reasons.
+//
+// hints are normalized as a server with candidates normalizes them (see
+// variants.NormalizeHints): unknown keys are dropped and values trimmed.
+// fn is called directly, without a variants.Server, so
+// variants.RankingRequestFromContext reports no request.
+func AssertRanking(t testing.TB, fn variants.RankingFunc, hints variants.VariantHints, candidates []variants.ServerVariant, wantOrder ...string) []variants.ServerVariant {
+	t.Helper()
+	ranked, err := checkRanking(fn, hints, candidates)
+	if err != nil {
+		t.Errorf("ranking for %s: %v", describeHints(hints), err)
+	}
+	if got := rankedIDs(ranked); !slices.Equal(got, wantOrder) {
+		t.Errorf("ranking for %s = %q, want %q", describeHints(hints), got, wantOrder)
+	}
+	return ranked
+}
+
+// RankingCase is a case of a table-driven test of a ranking function, run
+// with RunRankingCases.
+type RankingCase struct {
+	// Name names the case's subtest. Empty means a description of Hints.
+	Name string
+
+	// Hints are the client hints to rank for.
+	Hints variants.VariantHints
+
+	// Want, if non-empty, lists the IDs of the ranked variants in order.
+	Want []string
+
+	// First, if non-empty, is the ID of the variant that must rank first.
+	First string
+}
+
+// RunRankingCases runs each case as a subtest, ranking candidates with fn
+// for the case's hints, normalized as for AssertRanking. Every ranking
+// must be valid, as checked by variants.CheckRanking, and match the case's
+// Want and First. Cases
+// without expectations only check validity, as for those generated by
+// HintCombinationCases.
+func RunRankingCases(t *testing.T, fn variants.RankingFunc, candidates []variants.ServerVariant, cases []RankingCase) {
+	t.Helper()
+	for _, c := range cases {
+		name := c.Name
+		if name == "" {
+			name = describeHints(c.Hints)
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+			ranked, err := checkRanking(fn, c.Hints, candidates)
+			if err != nil {
+				t.Error(err)
+			}
+			got := rankedIDs(ranked)
+			if len(c.Want) > 0 && !slices.Equal(got, c.Want) {
+				t.Errorf("ranking = %q, want %q", got, c.Want)
+			}
+			if c.First != "" && (len(got) == 0 || got[0] != c.First) {
+				t.Errorf("ranking = %q, want %q first", got, c.First)
+			}
+		})
+	}
+}
+
+// checkRanking checks the ranking of candidates by fn for hints,
+// normalized as a server normalizes them.
+func checkRanking(fn variants.RankingFunc, hints variants.VariantHints, candidates []variants.ServerVariant) ([]variants.ServerVariant, error) {
+	normalized, _ := variants.NormalizeHints(hints, candidates)
+	return variants.CheckRanking(context.Background(), fn, normalized, candidates)
+}
+
+// HintMatchCases returns a case for each candidate with hints that no
+// other candidate has, requiring that a client sending exactly those hints
+// is ranked that candidate first.
+func HintMatchCases(candidates []variants.ServerVariant) []RankingCase {
+	var out []RankingCase
+	for _, v := range candidates {
+		if len(v.Hints) == 0 || slices.ContainsFunc(candidates, func(o variants.ServerVariant) bool {
+			return o.ID != v.ID && maps.Equal(o.Hints, v.Hints)
+		}) {
+			continue
+		}
+		hints := make(map[string]any, len(v.Hints))
+		for k, value := range v.Hints {
+			hints[k] = value
+		}
+		out = append(out, RankingCase{
+			Name:  "hints of " + v.ID,
+			Hints: variants.VariantHints{Hints: hints},
+			First: v.ID,
+		})
+	}
+	return out
+}
+
+// HintCombinationCases returns a case without expectations for every
+// combination of the given hint values, each key being either absent or
+// set to one of its values, starting with no hints. Run with
+// RunRankingCases, they check that a ranking function returns a valid
+// ranking for every client in the space of hints, e.g.
+//
+//	variantstest.RunRankingCases(t, rank, catalog, variantstest.HintCombinationCases(map[string][]string{
+//		variants.HintContextSize: {"compact", "standard", "verbose"},
+//		variants.HintModelFamily: {"anthropic", "openai"},
+//	}))
+//
+// The number of cases is the product, over the keys, of the number of
+// values plus one.
+func HintCombinationCases(values map[string][]string) []RankingCase {
+	keys := slices.Sorted(maps.Keys(values))
+	combos := []map[string]any{{}}
+	for _, k := range keys {
+		next := slices.Clone(combos)
+		for _, value := range values[k] {
+			for _, c := range combos {
+				h := maps.Clone(c)
+				h[k] = value
+				next = append(next, h)
+			}
+		}
+		combos = next
+	}
+	out := make([]RankingCase, len(combos))
+	for i, h := range combos {
+		out[i] = RankingCase{Hints: variants.VariantHints{Hints: h}}
+	}
+	return out
+}
+
+// describeHints describes hints for test names and messages, as JSON, or
+// "no hints".
+func describeHints(hints variants.VariantHints) string {
+	if hints.Description == "" && len(hints.Hints) == 0 {
+		return "no hints"
+	}
+	data, err := json.Marshal(hints)
+	if err != nil {
+		return strings.TrimSpace(hints.Description)
+	}
+	return string(data)
+}
+
+// rankedIDs returns the IDs of ranked variants.
+func rankedIDs(ranked []variants.ServerVariant) []string {
+	ids := make([]string, len(ranked))
+	for i, v := range ranked {
+		ids[i] = v.ID
+	}
+	return ids
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variantstest
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var rankingCatalog = []variants.ServerVariant{
+	{ID: "standard", Description: "Standard tools", Hints: map[string]string{variants.HintContextSize: "standard"}},
+	{ID: "compact", Description: "Compact tools", Hints: map[string]string{variants.HintContextSize: "compact"}},
+	{ID: "compact-preview", Description: "Compact preview", Hints: map[string]string{variants.HintContextSize: "compact"}, Status: variants.Experimental},
+	{ID: "plain", Description: "Plain tools"},
+}
+
+// contextSizeRanking ranks variants whose contextSize hint matches the
+// client's first, keeping the given order otherwise.
+func contextSizeRanking(_ context.Context, hints variants.VariantHints, vs []variants.ServerVariant) []variants.ServerVariant {
+	want, _ := variants.HintValue[string](hints, variants.HintContextSize)
+	slices.SortStableFunc(vs, func(a, b variants.ServerVariant) int {
+		am, bm := want != "" && a.Hints[variants.HintContextSize] == want, want != "" && b.Hints[variants.HintContextSize] == want
+		switch {
+		case am && !bm:
+			return -1
+		case bm && !am:
+			return 1
+		}
+		return 0
+	})
+	return vs
+}
+
+func TestAssertRanking(t *testing.T) {
+	hints := variants.VariantHints{Hints: map[string]any{variants.HintContextSize: "compact"}}
+	ranked := AssertRanking(t, contextSizeRanking, hints, rankingCatalog, "compact", "compact-preview", "standard", "plain")
+	assert.Len(t, ranked, 4)
+
+	// Hints are normalized, as by a server.
+	padded := variants.VariantHints{Hints: map[string]any{variants.HintContextSize: []any{" compact "}}}
+	AssertRanking(t, contextSizeRanking, padded, rankingCatalog, "compact", "compact-preview", "standard", "plain")
+
+	rec := &errorRecorder{TB: t}
+	AssertRanking(rec, contextSizeRanking, hints, rankingCatalog, "standard", "compact", "compact-preview", "plain")
+	require.Len(t, rec.errors, 1)
+	assert.Equal(t, `ranking for {"hints":{"contextSize":"compact"}} = ["compact" "compact-preview" "standard" "plain"], want ["standard" "compact" "compact-preview" "plain"]`, rec.errors[0])
+
+	rec = &errorRecorder{TB: t}
+	dropping := func(_ context.Context, _ variants.VariantHints, vs []variants.ServerVariant) []variants.ServerVariant {
+		return vs[:1]
+	}
+	AssertRanking(rec, dropping, variants.VariantHints{}, rankingCatalog, "standard")
+	require.Len(t, rec.errors, 1)
+	assert.Contains(t, rec.errors[0], `ranking for no hints: variants: invalid ranking: ranking leaves out variant "compact"`)
+}
+
+func TestRunRankingCases(t *testing.T) {
+	cases := append(HintMatchCases(rankingCatalog), RankingCase{
+		Name:  "no hints",
+		Want:  []string{"standard", "compact", "compact-preview", "plain"},
+		First: "standard",
+	})
+	RunRankingCases(t, contextSizeRanking, rankingCatalog, cases)
+	RunRankingCases(t, contextSizeRanking, rankingCatalog, HintCombinationCases(map[string][]string{
+		variants.HintContextSize: {"compact", "standard"},
+		variants.HintModelFamily: {"anthropic"},
+	}))
+}
+
+func TestHintMatchCases(t *testing.T) {
+	cases := HintMatchCases(rankingCatalog)
+	require.Len(t, cases, 1, "variants sharing hints and variants without hints have no case")
+	assert.Equal(t, RankingCase{
+		Name:  "hints of standard",
+		Hints: variants.VariantHints{Hints: map[string]any{variants.HintContextSize: "standard"}},
+		First: "standard",
+	}, cases[0])
+}
+
+func TestHintCombinationCases(t *testing.T) {
+	cases := HintCombinationCases(map[string][]string{
+		"b": {"1", "2"},
+		"a": {"x"},
+	})
+	var got []map[string]any
+	for _, c := range cases {
+		got = append(got, c.Hints.Hints)
+	}
+	assert.Equal(t, []map[string]any{
+		{},
+		{"a": "x"},
+		{"b": "1"},
+		{"a": "x", "b": "1"},
+		{"b": "2"},
+		{"a": "x", "b": "2"},
+	}, got)
+}
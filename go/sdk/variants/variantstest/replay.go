@@ -0,0 +1,211 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variantstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// replayProtocolVersion is the protocol version of the initialize request
+// sent for recorded sessions that were captured without one.
+const replayProtocolVersion = "2025-06-18"
+
+// Replay feeds the client requests and notifications of a transcript
+// captured with variants.Server.WithCapture (see variants.ReadCapture) into
+// vs, and reports a test error for each response that differs from the
+// recorded one: results must be equal as JSON, errors must have the same
+// code, message and data. Messages the server sent are skipped.
+//
+// Each recorded session is replayed in its own session of vs, in
+// transcript order, over the streamable HTTP transport. Sessions captured
+// without their initialize request (e.g. from stateless servers) are
+// initialized without client capabilities. Redacted values are replayed as
+// recorded, so requests whose behavior depends on them should be edited or
+// removed from the transcript first. Live results and error data are
+// redacted as captures are (see variants.RedactJSON) before they are
+// compared; edits made by CaptureOptions.Sanitize are not reapplied.
+//
+// Replay serves vs and closes it when the test ends.
+func Replay(t testing.TB, vs *variants.Server, transcript []variants.CaptureRecord) {
+	t.Helper()
+	handler := variants.NewStreamableHTTPHandler(vs, &mcp.StreamableHTTPOptions{JSONResponse: true})
+	t.Cleanup(func() { vs.Close() })
+
+	sessions := make(map[string]*replaySession)
+	for i, rec := range transcript {
+		if rec.Outgoing || rec.Method == "notifications/initialized" {
+			// Sent by the server, or by initialize.
+			continue
+		}
+		s, ok := sessions[rec.SessionID]
+		if !ok {
+			s = &replaySession{handler: handler}
+			sessions[rec.SessionID] = s
+			if rec.Method != "initialize" {
+				params, _ := json.Marshal(&mcp.InitializeParams{
+					ProtocolVersion: replayProtocolVersion,
+					ClientInfo:      &mcp.Implementation{Name: "variantstest", Version: "v0.0.1"},
+				})
+				if _, err := s.initialize(params); err != nil {
+					t.Errorf("record %d: initializing session %q: %v", i, rec.SessionID, err)
+					continue
+				}
+			}
+		}
+
+		if strings.HasPrefix(rec.Method, "notifications/") {
+			if _, err := s.post(&jsonrpc.Request{Method: rec.Method, Params: rec.Params}); err != nil {
+				t.Errorf("record %d (%s): %v", i, rec.Method, err)
+			}
+			continue
+		}
+		var (
+			resp *jsonrpc.Response
+			err  error
+		)
+		if rec.Method == "initialize" {
+			resp, err = s.initialize(rec.Params)
+		} else {
+			resp, err = s.call(rec.Method, rec.Params)
+		}
+		if err != nil {
+			t.Errorf("record %d (%s): %v", i, rec.Method, err)
+			continue
+		}
+		if diff := replayDiff(rec, resp); diff != "" {
+			t.Errorf("record %d (%s) of session %q: %s", i, rec.Method, rec.SessionID, diff)
+		}
+	}
+}
+
+// replaySession is a live session replaying a recorded one.
+type replaySession struct {
+	handler  http.Handler
+	id       string // Mcp-Session-Id
+	protocol string // negotiated protocol version
+	nextID   int64
+}
+
+// initialize sends an initialize request with params and, if it succeeds,
+// the initialized notification.
+func (s *replaySession) initialize(params json.RawMessage) (*jsonrpc.Response, error) {
+	resp, err := s.call("initialize", params)
+	if err != nil || resp.Error != nil {
+		return resp, err
+	}
+	var res mcp.InitializeResult
+	if err := json.Unmarshal(resp.Result, &res); err == nil {
+		s.protocol = res.ProtocolVersion
+	}
+	if _, err := s.post(&jsonrpc.Request{Method: "notifications/initialized"}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// call sends a request and returns its response.
+func (s *replaySession) call(method string, params json.RawMessage) (*jsonrpc.Response, error) {
+	s.nextID++
+	id, err := jsonrpc.MakeID(float64(s.nextID))
+	if err != nil {
+		return nil, err
+	}
+	msg, err := s.post(&jsonrpc.Request{ID: id, Method: method, Params: params})
+	if err != nil {
+		return nil, err
+	}
+	resp, ok := msg.(*jsonrpc.Response)
+	if !ok {
+		return nil, fmt.Errorf("got %T, want a response", msg)
+	}
+	return resp, nil
+}
+
+// post sends msg to the handler and returns the message it responds with,
+// or nil for a notification.
+func (s *replaySession) post(msg *jsonrpc.Request) (jsonrpc.Message, error) {
+	data, err := jsonrpc.EncodeMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if s.id != "" {
+		req.Header.Set("Mcp-Session-Id", s.id)
+	}
+	if s.protocol != "" {
+		req.Header.Set("Mcp-Protocol-Version", s.protocol)
+	}
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	if rec.Code >= 300 {
+		return nil, fmt.Errorf("HTTP %d: %s", rec.Code, bytes.TrimSpace(rec.Body.Bytes()))
+	}
+	if id := rec.Header().Get("Mcp-Session-Id"); id != "" {
+		s.id = id
+	}
+	if !msg.ID.IsValid() {
+		return nil, nil
+	}
+	return jsonrpc.DecodeMessage(rec.Body.Bytes())
+}
+
+// replayDiff describes how resp differs from the response recorded in rec,
+// or returns "" if it does not.
+func replayDiff(rec variants.CaptureRecord, resp *jsonrpc.Response) string {
+	if rec.Error != nil || resp.Error != nil {
+		var got *jsonrpc.Error
+		if resp.Error != nil && !errors.As(resp.Error, &got) {
+			got = &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: resp.Error.Error()}
+		}
+		if got != nil && len(got.Data) > 0 {
+			e := *got
+			e.Data = variants.RedactJSON(got.Data)
+			got = &e
+		}
+		if rec.Error == nil || got == nil || got.Code != rec.Error.Code || got.Message != rec.Error.Message || !jsonEqual(got.Data, rec.Error.Data) {
+			return fmt.Sprintf("got %s, want %s", describeResponse(got, resp.Result), describeResponse(rec.Error, rec.Result))
+		}
+		return ""
+	}
+	if result := variants.RedactJSON(resp.Result); !jsonEqual(result, rec.Result) {
+		return fmt.Sprintf("got result %s, want %s", result, rec.Result)
+	}
+	return ""
+}
+
+func describeResponse(err *jsonrpc.Error, result json.RawMessage) string {
+	if err == nil {
+		return "result " + string(result)
+	}
+	data, _ := json.Marshal(err)
+	return "error " + string(data)
+}
+
+// jsonEqual reports whether a and b encode equal JSON values. Empty
+// encodings equal each other only.
+func jsonEqual(a, b json.RawMessage) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	var x, y any
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(x, y)
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variantstest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/experimental-ext-variants/go/sdk/variants"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newEchoServer returns a variant server with variants "stable" and
+// "loud", whose echo tools prefix their output with prefix and its upper
+// case.
+func newEchoServer(prefix string) *variants.Server {
+	vs := variants.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"})
+	for i, id := range []string{"stable", "loud"} {
+		p := prefix
+		if id == "loud" {
+			p = strings.ToUpper(prefix)
+		}
+		srv := mcp.NewServer(&mcp.Implementation{Name: id, Version: "1.0.0"}, nil)
+		mcp.AddTool(srv, &mcp.Tool{Name: "echo"}, func(_ context.Context, _ *mcp.CallToolRequest, in echoInput) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: p + in.Text}}}, nil, nil
+		})
+		vs.WithVariant(variants.ServerVariant{ID: id, Description: id, Status: variants.Stable}, srv, i)
+	}
+	return vs
+}
+
+// captureTranscript records a session of a client against vs.
+func captureTranscript(t *testing.T, vs *variants.Server) []variants.CaptureRecord {
+	t.Helper()
+	transcript := captureSession(t, vs, func(ctx context.Context, session *mcp.ClientSession) {
+		_, err := session.ListTools(ctx, nil)
+		require.NoError(t, err)
+		require.NoError(t, callEcho(ctx, session, "stable"))
+		require.NoError(t, callEcho(ctx, session, "loud"))
+		require.Error(t, callEcho(ctx, session, "missing"))
+	})
+	require.Len(t, transcript, 6)
+	return transcript
+}
+
+// captureSession records the session of a client against vs that fn runs.
+func captureSession(t *testing.T, vs *variants.Server, fn func(context.Context, *mcp.ClientSession)) []variants.CaptureRecord {
+	t.Helper()
+	var buf bytes.Buffer
+	vs.WithCapture(&buf, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	st, ct := mcp.NewInMemoryTransports()
+	done := make(chan struct{})
+	go func() {
+		vs.Run(ctx, st)
+		close(done)
+	}()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(ctx, ct, nil)
+	require.NoError(t, err)
+	fn(ctx, session)
+	session.Close()
+	cancel()
+	<-done
+
+	transcript, err := variants.ReadCapture(&buf)
+	require.NoError(t, err)
+	return transcript
+}
+
+// errorRecorder records the errors a test reports.
+type errorRecorder struct {
+	testing.TB
+	mu     sync.Mutex
+	errors []string
+}
+
+func (r *errorRecorder) Errorf(format string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestReplay(t *testing.T) {
+	transcript := captureTranscript(t, newEchoServer("echo: "))
+	Replay(t, newEchoServer("echo: "), transcript)
+}
+
+func TestReplay_Regression(t *testing.T) {
+	transcript := captureTranscript(t, newEchoServer("echo: "))
+
+	rec := &errorRecorder{TB: t}
+	Replay(rec, newEchoServer("reply: "), transcript)
+	require.Len(t, rec.errors, 2, "the two echo calls differ")
+	assert.Contains(t, rec.errors[0], "tools/call")
+	assert.Contains(t, rec.errors[0], "reply: hi")
+	assert.Contains(t, rec.errors[1], "REPLY: hi")
+}
+
+func TestReplay_WithoutInitialize(t *testing.T) {
+	transcript := captureTranscript(t, newEchoServer("echo: "))
+	Replay(t, newEchoServer("echo: "), transcript[1:])
+}
+
+func TestReplay_Redacted(t *testing.T) {
+	newServer := func() *variants.Server {
+		srv := mcp.NewServer(&mcp.Implementation{Name: "auth", Version: "1.0.0"}, nil)
+		srv.AddTool(&mcp.Tool{Name: "login", InputSchema: map[string]any{"type": "object"}}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{
+				Content:           []mcp.Content{&mcp.TextContent{Text: "logged in"}},
+				StructuredContent: map[string]any{"user": "ada", "token": "s3cret"},
+			}, nil
+		})
+		return variants.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+			WithVariant(variants.ServerVariant{ID: "auth", Description: "auth"}, srv, 0)
+	}
+	transcript := captureSession(t, newServer(), func(ctx context.Context, session *mcp.ClientSession) {
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "login"})
+		require.NoError(t, err)
+	})
+	require.Contains(t, string(transcript[len(transcript)-1].Result), "[REDACTED]")
+
+	Replay(t, newServer(), transcript)
+}
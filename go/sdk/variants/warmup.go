@@ -0,0 +1,225 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultWarmupTimeout bounds the warmup of a connection when
+// Warmup.Timeout is zero.
+const defaultWarmupTimeout = 10 * time.Second
+
+// Warmup configures the warmup of a variant's new connections, for
+// backends whose first calls are expensive, e.g. to fill JIT or model
+// caches or to authenticate with a remote service. See WithWarmup.
+type Warmup struct {
+	// Func, if set, is called first, for warmup outside MCP such as
+	// obtaining credentials or priming the backend's caches.
+	Func func(ctx context.Context, variantID string) error
+
+	// Methods are list methods sent over the connection, in order:
+	// "tools/list", "prompts/list", "resources/list" or
+	// "resources/templates/list".
+	Methods []string
+
+	// Tool, if set, is called over the connection with Arguments, after
+	// Methods. A result with IsError set fails the warmup.
+	Tool      string
+	Arguments map[string]any
+
+	// Timeout bounds the warmup of each connection. Zero means 10 seconds.
+	Timeout time.Duration
+}
+
+// WarmupStatus reports the warmup of a variant's connections.
+type WarmupStatus struct {
+	Warmed int `json:"warmed"` // connections warmed up successfully
+	Failed int `json:"failed"` // connections whose warmup failed
+
+	// LastDuration is how long the last warmup took, and LastError is its
+	// error, if it failed.
+	LastDuration time.Duration `json:"lastDuration"`
+	LastError    string        `json:"lastError,omitempty"`
+}
+
+// warmupMethods are the list methods a Warmup may send.
+var warmupMethods = map[string]func() mcp.Request{
+	"tools/list": func() mcp.Request {
+		return &mcp.ListToolsRequest{Params: &mcp.ListToolsParams{}}
+	},
+	"prompts/list": func() mcp.Request {
+		return &mcp.ListPromptsRequest{Params: &mcp.ListPromptsParams{}}
+	},
+	"resources/list": func() mcp.Request {
+		return &mcp.ListResourcesRequest{Params: &mcp.ListResourcesParams{}}
+	},
+	"resources/templates/list": func() mcp.Request {
+		return &mcp.ListResourceTemplatesRequest{Params: &mcp.ListResourceTemplatesParams{}}
+	},
+}
+
+// warmup is a variant's Warmup and the status of its runs.
+type warmup struct {
+	Warmup
+	args json.RawMessage // Arguments, marshaled
+
+	mu     sync.Mutex
+	status WarmupStatus
+}
+
+// WithWarmup warms up each new connection to a variant's backend before it
+// serves requests, so that the first real client request is not penalized
+// by a cold backend: w.Func is called, then w.Methods are sent and w.Tool
+// is called over the connection. Connections are created when sessions
+// start (in stateless mode, when serving starts), or on first use for
+// variants connected lazily (see WithStartupPolicy and SessionLimits);
+// the connections of a session are warmed up concurrently. Reconnections
+// of remote variants after a dropped connection are not warmed up.
+//
+// Warmup calls bypass the dispatcher: they are not intercepted, retried or
+// counted in statistics, and their notifications are not forwarded to the
+// client. A failed warmup does not fail the connection, which serves
+// requests anyway; it is reported by WarmupStatus, in the admin API (see
+// NewAdminHandler) and with an EventBackendWarmedUp event. Later calls for
+// the same variant replace its warmup.
+//
+// The variant must be registered by the time serving starts. It panics if
+// w does nothing, sends a method other than the list methods above, has
+// Arguments that do not marshal to JSON, or has a negative Timeout.
+//
+// Returns the receiver for chaining.
+func (s *Server) WithWarmup(variantID string, w Warmup) *Server {
+	if w.Func == nil && len(w.Methods) == 0 && w.Tool == "" {
+		panic("variants: warmup for variant " + variantID + " does nothing")
+	}
+	for _, m := range w.Methods {
+		if _, ok := warmupMethods[m]; !ok {
+			panic(fmt.Sprintf("variants: warmup for variant %s sends unsupported method %q", variantID, m))
+		}
+	}
+	if w.Timeout < 0 {
+		panic("variants: negative warmup timeout for variant " + variantID)
+	}
+	if w.Timeout == 0 {
+		w.Timeout = defaultWarmupTimeout
+	}
+	wu := &warmup{Warmup: w}
+	if w.Arguments != nil {
+		args, err := json.Marshal(w.Arguments)
+		if err != nil {
+			panic(fmt.Sprintf("variants: warmup arguments for variant %s: %v", variantID, err))
+		}
+		wu.args = args
+	}
+	if s.warmups == nil {
+		s.warmups = make(map[string]*warmup)
+	}
+	s.warmups[variantID] = wu
+	return s
+}
+
+// WarmupStatus returns the status of the warmup of a variant's
+// connections. It reports false if the variant has no warmup configured
+// with WithWarmup.
+func (s *Server) WarmupStatus(variantID string) (WarmupStatus, bool) {
+	w := s.warmups[variantID]
+	if w == nil {
+		return WarmupStatus{}, false
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status, true
+}
+
+// validateWarmups checks that warmups name registered variants.
+func (s *Server) validateWarmups() error {
+	for id := range s.warmups {
+		if !s.hasVariant(id) {
+			return fmt.Errorf("variants: warmup for unregistered variant %q", id)
+		}
+	}
+	return nil
+}
+
+// warmUp warms up new connections on behalf of frontSession, which may be
+// nil, concurrently if there are several.
+func (s *Server) warmUp(ctx context.Context, frontSession *mcp.ServerSession, conns ...*innerConnection) {
+	if len(s.warmups) == 0 {
+		return
+	}
+	// Keep the warmup's notifications from the client (see
+	// sendingRedirectMiddleware).
+	ctx = context.WithValue(ctx, frontSessionKeyType{}, (*mcp.ServerSession)(nil))
+	var wg sync.WaitGroup
+	for _, conn := range conns {
+		w := s.warmups[conn.backendSession.variantID]
+		if w == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := w.run(ctx, conn.backendSession)
+			took := time.Since(start)
+			w.record(took, err)
+			e := Event{Kind: EventBackendWarmedUp, VariantID: conn.backendSession.variantID, Duration: took, Err: err}
+			if frontSession != nil {
+				e.SessionID = frontSession.ID()
+			}
+			s.emit(ctx, e)
+		}()
+	}
+	wg.Wait()
+}
+
+// run warms up the connection of bs.
+func (w *warmup) run(ctx context.Context, bs *backendSession) error {
+	ctx, cancel := context.WithTimeout(ctx, w.Timeout)
+	defer cancel()
+	if w.Func != nil {
+		if err := w.Func(ctx, bs.variantID); err != nil {
+			return fmt.Errorf("variants: warming up variant %q: %w", bs.variantID, err)
+		}
+	}
+	for _, m := range w.Methods {
+		if _, err := bs.handleReceive(ctx, m, warmupMethods[m]()); err != nil {
+			return fmt.Errorf("variants: warming up variant %q: %s: %w", bs.variantID, m, err)
+		}
+	}
+	if w.Tool == "" {
+		return nil
+	}
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: w.Tool, Arguments: w.args}}
+	res, err := bs.handleReceive(ctx, "tools/call", req)
+	if err != nil {
+		return fmt.Errorf("variants: warming up variant %q: calling tool %q: %w", bs.variantID, w.Tool, err)
+	}
+	if r, ok := res.(*mcp.CallToolResult); ok && r.IsError {
+		return fmt.Errorf("variants: warming up variant %q: tool %q returned an error", bs.variantID, w.Tool)
+	}
+	return nil
+}
+
+// record records the outcome of a run.
+func (w *warmup) record(took time.Duration, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.status.LastDuration = took
+	w.status.LastError = ""
+	if err != nil {
+		w.status.Failed++
+		w.status.LastError = err.Error()
+	} else {
+		w.status.Warmed++
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2025 The MCP Variants Authors. All rights reserved.
+// Use of this source code is governed by a Apache-2.0
+// license that can be found in the LICENSE file.
+
+package variants
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWarmupServer creates a variant server whose coding variant has a
+// "warm" tool counting its calls and recording its last argument.
+func newWarmupServer(calls *atomic.Int32, arg *atomic.Value) *Server {
+	coding, compact := newTestServers()
+	type warmInput struct {
+		Level string `json:"level"`
+	}
+	mcp.AddTool(coding, &mcp.Tool{Name: "warm"}, func(_ context.Context, _ *mcp.CallToolRequest, in warmInput) (*mcp.CallToolResult, any, error) {
+		calls.Add(1)
+		arg.Store(in.Level)
+		return nil, nil, nil
+	})
+	return NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithVariant(ServerVariant{ID: "coding", Status: Stable}, coding, 0).
+		WithVariant(ServerVariant{ID: "compact", Status: Experimental}, compact, 1)
+}
+
+func TestWithWarmup(t *testing.T) {
+	ctx := context.Background()
+	var calls atomic.Int32
+	var arg atomic.Value
+	var mu sync.Mutex
+	var events []Event
+	var funcIDs []string
+	vs := newWarmupServer(&calls, &arg).
+		WithWarmup("coding", Warmup{
+			Func: func(_ context.Context, variantID string) error {
+				mu.Lock()
+				funcIDs = append(funcIDs, variantID)
+				mu.Unlock()
+				return nil
+			},
+			Methods:   []string{"tools/list"},
+			Tool:      "warm",
+			Arguments: map[string]any{"level": "full"},
+		}).
+		WithEventHandler(func(_ context.Context, e Event) {
+			if e.Kind == EventBackendWarmedUp {
+				mu.Lock()
+				events = append(events, e)
+				mu.Unlock()
+			}
+		})
+
+	session := connectTestClient(t, vs, nil)
+
+	// The connection was warmed up before the first client request.
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, "full", arg.Load())
+	mu.Lock()
+	assert.Equal(t, []string{"coding"}, funcIDs)
+	require.Len(t, events, 1)
+	assert.Equal(t, "coding", events[0].VariantID)
+	assert.NoError(t, events[0].Err)
+	mu.Unlock()
+
+	st, ok := vs.WarmupStatus("coding")
+	require.True(t, ok)
+	assert.Equal(t, 1, st.Warmed)
+	assert.Zero(t, st.Failed)
+	assert.Empty(t, st.LastError)
+	_, ok = vs.WarmupStatus("compact")
+	assert.False(t, ok)
+
+	admin := vs.adminVariants()
+	require.NotNil(t, admin[0].Warmup)
+	assert.Equal(t, 1, admin[0].Warmup.Warmed)
+	assert.Nil(t, admin[1].Warmup)
+
+	_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "warm", Arguments: map[string]any{"level": "real"}})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestWithWarmup_Failure(t *testing.T) {
+	ctx := context.Background()
+	t.Run("tool", func(t *testing.T) {
+		vs := newTestVariantServer().WithWarmup("coding", Warmup{Tool: "missing"})
+		session := connectTestClient(t, vs, nil)
+
+		st, ok := vs.WarmupStatus("coding")
+		require.True(t, ok)
+		assert.Zero(t, st.Warmed)
+		assert.Equal(t, 1, st.Failed)
+		assert.Contains(t, st.LastError, `calling tool "missing"`)
+
+		// The connection serves requests anyway.
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "analyze_code", Arguments: map[string]any{"code": "x", "language": "go"}})
+		assert.NoError(t, err)
+	})
+
+	t.Run("func", func(t *testing.T) {
+		vs := newTestVariantServer().WithWarmup("compact", Warmup{
+			Func: func(context.Context, string) error { return errors.New("no credentials") },
+		})
+		connectTestClient(t, vs, nil)
+
+		st, _ := vs.WarmupStatus("compact")
+		assert.Equal(t, 1, st.Failed)
+		assert.Contains(t, st.LastError, "no credentials")
+	})
+}
+
+// TestWithWarmup_Lazy verifies that warming up a lazily connected variant
+// only holds up requests for that variant.
+func TestWithWarmup_Lazy(t *testing.T) {
+	gate := make(chan struct{})
+	var warmups atomic.Int32
+	vs := newTestVariantServer().WithWarmup("compact", Warmup{
+		Func: func(context.Context, string) error {
+			warmups.Add(1)
+			<-gate
+			return nil
+		},
+	})
+	d := &dispatcher{server: vs, shared: true, connections: make(map[string]*innerConnection)}
+	t.Cleanup(func() { (&sessionState{dispatcher: d}).close() })
+	ctx := context.Background()
+
+	conns := make(chan *innerConnection, 2)
+	for range 2 {
+		go func() {
+			conn, err := d.connection(ctx, "compact")
+			assert.NoError(t, err)
+			conns <- conn
+		}()
+	}
+	require.Eventually(t, func() bool { return warmups.Load() == 1 }, time.Second, time.Millisecond)
+
+	_, err := d.connection(ctx, "coding")
+	require.NoError(t, err, "warming up compact must not block coding")
+	select {
+	case <-conns:
+		t.Fatal("compact connection published before its warmup finished")
+	default:
+	}
+
+	close(gate)
+	first, second := <-conns, <-conns
+	assert.Same(t, first, second)
+	assert.Equal(t, int32(1), warmups.Load())
+}
+
+func TestWithWarmup_Invalid(t *testing.T) {
+	assert.Panics(t, func() { newTestVariantServer().WithWarmup("coding", Warmup{}) })
+	assert.Panics(t, func() { newTestVariantServer().WithWarmup("coding", Warmup{Methods: []string{"tools/call"}}) })
+	assert.Panics(t, func() { newTestVariantServer().WithWarmup("coding", Warmup{Tool: "warm", Timeout: -1}) })
+	assert.Panics(t, func() {
+		newTestVariantServer().WithWarmup("coding", Warmup{Tool: "warm", Arguments: map[string]any{"ch": make(chan int)}})
+	})
+
+	_, err := newTestVariantServer().WithWarmup("nope", Warmup{Methods: []string{"tools/list"}}).NewRouter(nil)
+	assert.ErrorContains(t, err, `warmup for unregistered variant "nope"`)
+}
+
+func TestWithWarmup_StatelessRemotePool(t *testing.T) {
+	var calls atomic.Int32
+	backend := mcp.NewServer(&mcp.Implementation{Name: "remote", Version: "1.0.0"}, nil)
+	mcp.AddTool(backend, &mcp.Tool{Name: "warm"}, func(context.Context, *mcp.CallToolRequest, any) (*mcp.CallToolResult, any, error) {
+		calls.Add(1)
+		return nil, nil, nil
+	})
+	endpoint, _ := serveRemote(t, backend)
+
+	vs := NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}).
+		WithRemoteVariant(ServerVariant{ID: "remote"}, endpoint, 0).
+		WithStatelessPool(PoolOptions{Size: 3}).
+		WithWarmup("remote", Warmup{Methods: []string{"tools/list"}, Tool: "warm"})
+	t.Cleanup(func() { vs.Close() })
+	_, err := vs.NewRouter(&RouterOptions{Stateless: true})
+	require.NoError(t, err)
+
+	// Each pooled connection was warmed up when serving started.
+	assert.Equal(t, int32(3), calls.Load())
+	st, _ := vs.WarmupStatus("remote")
+	assert.Equal(t, 3, st.Warmed)
+}